@@ -1,8 +1,18 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/storage"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/i18n"
 	"github.com/SurgeDM/Surge/internal/utils"
 	"github.com/spf13/cobra"
 )
@@ -11,7 +21,14 @@ var addCmd = &cobra.Command{
 	Use:     "add [url]...",
 	Aliases: []string{"get"},
 	Short:   "Add a new download to the running Surge instance",
-	Long:    `Add one or more URLs to the download queue of a running Surge instance.`,
+	Long: `Add one or more URLs to the download queue of a running Surge instance.
+
+Invoked as 'surge get' with no instance running, the URLs are downloaded
+right away in this process instead, sharing one worker pool, and the
+command exits non-zero if any of them fails.
+
+With --dry-run nothing is added or written: each URL is probed and its
+destination, size and chunk layout are printed instead.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		//initializeGlobally is required to ensure that the config and logger are set up before we attempt to resolve the API connection or read the batch file.
 		if err := initializeGlobalState(); err != nil {
@@ -21,6 +38,22 @@ var addCmd = &cobra.Command{
 		batchFile, _ := cmd.Flags().GetString("batch")
 		output, _ := cmd.Flags().GetString("output")
 		confirm, _ := cmd.Flags().GetBool("confirm")
+		sums, _ := cmd.Flags().GetBool("sums")
+		streamed := output == "-" || storage.IsDestination(output)
+		if sums && (confirm || streamed) {
+			return fmt.Errorf("--sums cannot be combined with --confirm or --output %s", output)
+		}
+		if err := config.ValidatePathTemplate(output); err != nil {
+			return err
+		}
+		tlsOpts, err := downloadTLSFlags(cmd)
+		if err != nil {
+			return err
+		}
+		request, err := downloadRequestFlags(cmd)
+		if err != nil {
+			return err
+		}
 
 		var urls []string
 		urls = append(urls, args...)
@@ -38,39 +71,129 @@ var addCmd = &cobra.Command{
 			_ = cmd.Help()
 			return nil
 		}
+		if request.Alias != "" && len(urls) > 1 {
+			return fmt.Errorf("--name can only be used when adding a single URL")
+		}
+		if request.SignatureURL != "" && request.SignatureURL != types.SignatureAuto && len(urls) > 1 {
+			return fmt.Errorf("--sig-url can only name the signature of a single URL; use --sig-url auto for several")
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if dryRun && streamed {
+			return fmt.Errorf("--dry-run cannot be combined with --output %s", output)
+		}
+		// WebDAV directories are mirrored file by file
+		dirs, urls := splitWebDAVDirectories(urls)
+		if len(dirs) > 0 && (streamed || dryRun || sums || confirm || request.Alias != "") {
+			return fmt.Errorf("a WebDAV directory cannot be streamed or combined with --dry-run, --sums, --confirm or --name")
+		}
 
-		baseURL, token, err := resolveAPIConnection(true)
+		// A patch base turns the URL into a patch, applied here
+		if base, _ := cmd.Flags().GetString("patch-base"); base != "" {
+			if len(urls) != 1 || len(dirs) > 0 {
+				return fmt.Errorf("--patch-base applies a single patch URL")
+			}
+			if seeds, _ := cmd.Flags().GetStringArray("seed"); len(seeds) > 0 || streamed || dryRun || sums || confirm || !request.IsGet() || request.Follow || len(request.Copies) > 0 || request.SignatureURL != "" || request.Alias != "" {
+				return fmt.Errorf("--patch-base writes a file, so it cannot stream to --output or be combined with --seed, --dry-run, --sums, --confirm, --method, --follow, --copy, --sig-url or --name")
+			}
+			url, _ := ParseURLArg(urls[0])
+			noProgress, _ := cmd.Flags().GetBool("no-progress")
+			return runPatchGet(url, output, base, tlsOpts, request, !noProgress)
+		}
+
+		// A seed turns the URL into a zsync control file, rebuilt here
+		if seeds, _ := cmd.Flags().GetStringArray("seed"); len(seeds) > 0 {
+			if len(urls) != 1 || len(dirs) > 0 {
+				return fmt.Errorf("--seed rebuilds a single .zsync URL")
+			}
+			if streamed || dryRun || sums || confirm || !request.IsGet() || request.Follow || len(request.Copies) > 0 || request.SignatureURL != "" || request.Checksum != "" || request.Alias != "" {
+				return fmt.Errorf("--seed writes a file, so it cannot stream to --output or be combined with --dry-run, --sums, --confirm, --method, --follow, --copy, --sig-url, --checksum or --name")
+			}
+			url, _ := ParseURLArg(urls[0])
+			noProgress, _ := cmd.Flags().GetBool("no-progress")
+			return runZsyncGet(url, output, seeds, tlsOpts, request, !noProgress)
+		}
+
+		if streamed {
+			if len(urls) != 1 {
+				return fmt.Errorf("--output %s streams a single URL", output)
+			}
+			if request.Follow || len(request.Copies) > 0 || request.SignatureURL != "" {
+				return fmt.Errorf("--output %s cannot be combined with --follow, --copy or --sig-url", output)
+			}
+			url, _ := ParseURLArg(urls[0])
+			noProgress, _ := cmd.Flags().GetBool("no-progress")
+			return runStreamGet(url, output, tlsOpts, request, !noProgress)
+		}
+
+		// A dry run can be planned here when no instance is running
+		standalone := cmd.CalledAs() == "get" || dryRun
+		baseURL, token, err := resolveAPIConnection(!standalone)
 		if err != nil {
 			return err
 		}
+		if dryRun {
+			return runDryRun(urls, output, baseURL, token, tlsOpts, request)
+		}
+		if baseURL == "" && len(dirs) > 0 {
+			return fmt.Errorf("mirroring a WebDAV directory needs a running instance")
+		}
+		if baseURL == "" {
+			noProgress, _ := cmd.Flags().GetBool("no-progress")
+			return runStandaloneGet(urls, resolveClientOutputPath(output), tlsOpts, request, nil, !noProgress, sums)
+		}
 		resolvedOutput := resolveClientOutputPath(output)
 
 		if batchFile != "" && confirm {
-			if err := sendBatchToServer(urls, resolvedOutput, baseURL, token, false); err != nil {
+			if err := sendBatchToServer(urls, resolvedOutput, baseURL, token, false, tlsOpts, request, false); err != nil {
 				return err
 			}
-			fmt.Printf("Batch confirmation requested for %d downloads.\n", len(urls))
+			fmt.Println(i18n.T("cli.batch_requested", len(urls)))
+			return nil
+		}
+
+		// The server tracks the batch as a whole to write its manifest
+		if sums {
+			if err := sendBatchToServer(urls, resolvedOutput, baseURL, token, true, tlsOpts, request, true); err != nil {
+				return err
+			}
+			fmt.Println(i18n.T("cli.added", len(urls)))
 			return nil
 		}
 
 		// Send downloads to server
 		count := 0
 		attempted := 0
+		for _, dir := range dirs {
+			attempted++
+			queued, existing, failed, err := mirrorWebDAV(cmd.Context(), dir, resolvedOutput, baseURL, token, tlsOpts, request)
+			if err != nil {
+				fmt.Println(addFailedMessage(dir, err))
+				continue
+			}
+			if existing > 0 {
+				fmt.Printf("%s: %d file(s) already queued or downloaded\n", dir, existing)
+			}
+			if failed > 0 {
+				fmt.Printf("%s: failed to add %d file(s)\n", dir, failed)
+			}
+			count += queued
+		}
 		for _, arg := range urls {
 			url, mirrors := ParseURLArg(arg)
 			if url == "" {
 				continue
 			}
 			attempted++
-			if err := sendToServerWithApproval(url, mirrors, resolvedOutput, baseURL, token, !confirm); err != nil {
-				fmt.Printf("Error adding %s: %v\n", url, err)
+			if err := sendToServerWithApproval(url, mirrors, resolvedOutput, baseURL, token, !confirm, tlsOpts, request); err != nil {
+				fmt.Println(addFailedMessage(url, err))
 				continue
 			}
 			count++
 		}
 
 		if count > 0 {
-			fmt.Printf("Successfully added %d downloads.\n", count)
+			fmt.Println(i18n.T("cli.added", count))
 			return nil
 		}
 
@@ -85,6 +208,140 @@ var addCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(addCmd)
 	addCmd.Flags().StringP("batch", "b", "", "File containing URLs to download (one per line)")
-	addCmd.Flags().StringP("output", "o", "", "Output directory (defaults to current working directory)")
+	addCmd.Flags().StringP("output", "o", "", "Output directory, may use templates like {domain} (defaults to current working directory); - streams the file to stdout, a URI such as s3://bucket/key to storage")
 	addCmd.Flags().Bool("confirm", false, "Show confirmation prompt before starting downloads")
+	addCmd.Flags().BoolP("insecure", "k", false, "Skip TLS certificate verification for these downloads")
+	addCmd.Flags().String("cacert", "", "PEM file of extra CAs to trust for these downloads")
+	addCmd.Flags().String("cert", "", "PEM client certificate for servers that require mutual TLS")
+	addCmd.Flags().String("key", "", "PEM private key for --cert")
+	addCmd.Flags().StringP("method", "X", "", "HTTP method for endpoints that only serve the file to e.g. POST")
+	addCmd.Flags().StringP("data", "d", "", "Request body to send, or @file to read it from a file (@- for stdin)")
+	addCmd.Flags().String("content-type", "", "Content type of --data (default: JSON if it looks like JSON, form data otherwise)")
+	addCmd.Flags().BoolP("follow", "f", false, "Keep appending while the remote file grows; finish once its size is stable for follow_stable_window")
+	addCmd.Flags().StringP("name", "n", "", "Alias to refer to this download by instead of its ID, e.g. nightly-build")
+	addCmd.Flags().StringSliceP("tag", "t", nil, "Tag these downloads, e.g. --tag work; repeat or comma-separate for several")
+	addCmd.Flags().Bool("no-progress", false, "With 'surge get' and no running instance, only log events instead of drawing progress bars")
+	addCmd.Flags().Bool("low-priority", false, "Run these downloads with lowered disk and CPU priority (ionice on Linux, background mode on Windows)")
+	addCmd.Flags().String("checksum", "", "Digest the finished file must match, e.g. sha256:<hex>; a mismatch fails the download")
+	addCmd.Flags().Int("connections", 0, "Open at most this many connections per download (default: max_connections_per_download)")
+	addCmd.Flags().Bool("sums", false, "Write a SHA256SUMS file next to the downloads once all of them have finished")
+	addCmd.Flags().StringArray("copy", nil, "Also write the file to this directory as it downloads, e.g. a NAS mount; repeat for several")
+	addCmd.Flags().String("sig-url", "", "Minisign or OpenPGP signature the file must verify against with signature_keys, or auto to look for one next to it")
+	addCmd.Flags().Duration("connect-timeout", 0, "How long connecting to the server may take, e.g. 30s (default 10s)")
+	addCmd.Flags().Duration("header-timeout", 0, "How long to wait for the server to answer a request, e.g. 1m (default 15s)")
+	addCmd.Flags().Duration("stall-timeout", 0, "Restart a connection that received no data for this long (default: stall_timeout)")
+	addCmd.Flags().Duration("max-time", 0, "Fail a download that runs longer than this, e.g. 2h, counted from when it starts or resumes")
+	addCmd.Flags().BoolP("yes", "y", false, "Start downloads larger than confirm_size_threshold without asking")
+	addCmd.Flags().String("interface", "", "Connect from this interface or local IP, e.g. tun0, or spread connections across several, e.g. wlan0+eth0 (default: domain rules and bind_interface)")
+	addCmd.Flags().Bool("allow-html", false, "Save a web page served for a URL that names a binary file, e.g. a .zip, instead of pausing to ask")
+	addCmd.Flags().StringArray("seed", nil, "Treat the URL as a .zsync control file and rebuild its file from this older copy, fetching only the changed blocks; repeat for several")
+	addCmd.Flags().String("patch-base", "", "Treat the URL as an xdelta3 (VCDIFF) or bsdiff patch and apply it to this file; --checksum then checks the patched file")
+	addCmd.Flags().Bool("dry-run", false, "Probe the URLs and show where they would be saved and how they would be split, without adding them")
+}
+
+// downloadRequestFlags reads the method, body, follow, priority, name, tag,
+// checksum, connection, copy, signature, timeout, interface, allow-html and yes flags. A body without an explicit method is
+// sent as a POST, like curl does.
+func downloadRequestFlags(cmd *cobra.Command) (types.RequestOptions, error) {
+	method, _ := cmd.Flags().GetString("method")
+	data, _ := cmd.Flags().GetString("data")
+	contentType, _ := cmd.Flags().GetString("content-type")
+	follow, _ := cmd.Flags().GetBool("follow")
+	lowPriority, _ := cmd.Flags().GetBool("low-priority")
+	alias, _ := cmd.Flags().GetString("name")
+	rawTags, _ := cmd.Flags().GetStringSlice("tag")
+	checksum, _ := cmd.Flags().GetString("checksum")
+	connections, _ := cmd.Flags().GetInt("connections")
+	rawCopies, _ := cmd.Flags().GetStringArray("copy")
+	signatureURL, _ := cmd.Flags().GetString("sig-url")
+	connectTimeout, _ := cmd.Flags().GetDuration("connect-timeout")
+	headerTimeout, _ := cmd.Flags().GetDuration("header-timeout")
+	stallTimeout, _ := cmd.Flags().GetDuration("stall-timeout")
+	maxTime, _ := cmd.Flags().GetDuration("max-time")
+	iface, _ := cmd.Flags().GetString("interface")
+	allowHTML, _ := cmd.Flags().GetBool("allow-html")
+	confirmed, _ := cmd.Flags().GetBool("yes")
+
+	if name, ok := strings.CutPrefix(data, "@"); ok {
+		var raw []byte
+		var err error
+		if name == "-" {
+			raw, err = io.ReadAll(cmd.InOrStdin())
+		} else {
+			raw, err = os.ReadFile(name)
+		}
+		if err != nil {
+			return types.RequestOptions{}, fmt.Errorf("failed to read --data: %w", err)
+		}
+		data = string(raw)
+	}
+	if method == "" && data != "" {
+		method = http.MethodPost
+	}
+
+	tags, err := types.NormalizeTags(rawTags)
+	if err != nil {
+		return types.RequestOptions{}, err
+	}
+
+	// Copy directories are made absolute because the server resolves them
+	var copies []string
+	for _, dir := range rawCopies {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return types.RequestOptions{}, fmt.Errorf("invalid copy directory %q: %w", dir, err)
+		}
+		copies = append(copies, abs)
+	}
+
+	opts := types.RequestOptions{Method: method, Body: data, ContentType: contentType, Follow: follow, LowPriority: lowPriority, Alias: alias, Tags: tags, Checksum: checksum, Connections: connections, Copies: copies, SignatureURL: signatureURL, AllowHTML: allowHTML, Confirmed: confirmed, Interface: strings.TrimSpace(iface)}
+	opts.Timeouts = types.Timeouts{
+		Connect:        types.Duration(connectTimeout),
+		ResponseHeader: types.Duration(headerTimeout),
+		Stall:          types.Duration(stallTimeout),
+		Max:            types.Duration(maxTime),
+	}
+	if err := opts.Validate(); err != nil {
+		return types.RequestOptions{}, err
+	}
+	return opts, nil
+}
+
+// addFailedMessage says why adding url failed, and how to add it anyway when
+// it only needs confirming.
+func addFailedMessage(url string, err error) string {
+	var large *types.LargeDownloadError
+	if errors.As(err, &large) {
+		return i18n.T("cli.add_needs_confirm", url, utils.ConvertBytesToHumanReadable(large.Size))
+	}
+	return i18n.T("cli.add_failed", url, err)
+}
+
+// downloadTLSFlags reads the per-download TLS flags. File paths are made
+// absolute because the server resolves them, not this process.
+func downloadTLSFlags(cmd *cobra.Command) (types.TLSOptions, error) {
+	insecure, _ := cmd.Flags().GetBool("insecure")
+	caFile, _ := cmd.Flags().GetString("cacert")
+	certFile, _ := cmd.Flags().GetString("cert")
+	keyFile, _ := cmd.Flags().GetString("key")
+
+	if (certFile == "") != (keyFile == "") {
+		return types.TLSOptions{}, fmt.Errorf("--cert and --key must be used together")
+	}
+
+	opts := types.TLSOptions{Insecure: insecure}
+	for _, f := range []struct {
+		path string
+		dst  *string
+	}{{caFile, &opts.CAFile}, {certFile, &opts.ClientCertFile}, {keyFile, &opts.ClientKeyFile}} {
+		if f.path == "" {
+			continue
+		}
+		abs, err := filepath.Abs(f.path)
+		if err != nil {
+			return types.TLSOptions{}, fmt.Errorf("invalid path %q: %w", f.path, err)
+		}
+		*f.dst = abs
+	}
+	return opts, nil
 }
@@ -0,0 +1,327 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/core"
+	"github.com/SurgeDM/Surge/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// auditBodyLimit caps how much of a request body is inspected for the audit
+// log; download requests are far smaller than this.
+const auditBodyLimit = 1 << 20
+
+// auditBodyFields are the request body fields worth recording. Everything
+// else, notably headers, TLS options and request bodies, may carry
+// credentials and is left out.
+var auditBodyFields = []string{"url", "filename", "path", "mirrors", "tags"}
+
+func auditLogPath() string {
+	return filepath.Join(config.GetStateDir(), "audit.log")
+}
+
+// auditMiddleware records every state-changing request in log, including
+// ones the auth middleware rejects, so a shared daemon keeps a trail of who
+// changed what.
+func auditMiddleware(log *core.AuditLog, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		entry := core.AuditEntry{
+			Time:   time.Now(),
+			Client: auditClient(r),
+			Token:  core.TokenFingerprint(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")),
+			Method: r.Method,
+			Action: auditAction(r.URL.Path),
+			ID:     r.URL.Query().Get("id"),
+			Params: auditParams(r),
+		}
+
+		rec := &auditRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		entry.Status = rec.status
+		if entry.ID == "" {
+			entry.ID = auditResponseID(rec.body.Bytes())
+		}
+		if err := log.Append(entry); err != nil {
+			utils.Debug("Failed to write audit log: %v", err)
+		}
+	})
+}
+
+// auditRecorder remembers the status and the start of the response body so
+// the ID of a newly added download can be logged.
+type auditRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *auditRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *auditRecorder) Write(p []byte) (int, error) {
+	if room := 4096 - r.body.Len(); room > 0 {
+		r.body.Write(p[:min(room, len(p))])
+	}
+	return r.ResponseWriter.Write(p)
+}
+
+func auditClient(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// auditAction names a request after its endpoint, with adding a download
+// called "add" as on the command line.
+func auditAction(path string) string {
+	action := strings.Trim(path, "/")
+	switch action {
+	case "download":
+		return "add"
+	case "download/batch":
+		return "add-batch"
+	}
+	return action
+}
+
+// auditParams collects the query parameters and the safe body fields of r,
+// putting the body back for the handler.
+func auditParams(r *http.Request) map[string]string {
+	params := make(map[string]string)
+	for key, values := range r.URL.Query() {
+		if key != "id" && len(values) > 0 {
+			params[key] = values[0]
+		}
+	}
+
+	if r.Body != nil && r.Body != http.NoBody {
+		raw, err := io.ReadAll(io.LimitReader(r.Body, auditBodyLimit))
+		if err == nil {
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(raw), r.Body), r.Body}
+			addAuditBodyParams(params, raw)
+		}
+	}
+
+	if len(params) == 0 {
+		return nil
+	}
+	return params
+}
+
+func addAuditBodyParams(params map[string]string, raw []byte) {
+	var body map[string]any
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return
+	}
+	for _, field := range auditBodyFields {
+		if value := auditValue(body[field]); value != "" {
+			params[field] = value
+		}
+	}
+
+	// A batch is recorded by its URLs; the per-download options may hold
+	// the same secrets as a single request.
+	if downloads, ok := body["downloads"].([]any); ok {
+		urls := make([]string, 0, len(downloads))
+		for _, d := range downloads {
+			if item, ok := d.(map[string]any); ok {
+				if u := auditValue(item["url"]); u != "" {
+					urls = append(urls, u)
+				}
+			}
+		}
+		params["downloads"] = strconv.Itoa(len(downloads))
+		if len(urls) > 0 {
+			params["urls"] = strings.Join(urls, " ")
+		}
+	}
+}
+
+func auditValue(v any) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case []any:
+		parts := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok && s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, ",")
+	}
+	return ""
+}
+
+func auditResponseID(body []byte) string {
+	var resp struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return ""
+	}
+	return resp.ID
+}
+
+// handleAuditQuery serves GET /audit, filtered by the since (RFC 3339),
+// action, id and limit query parameters.
+func handleAuditQuery(log *core.AuditLog) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		filter := core.AuditFilter{
+			Action: query.Get("action"),
+			ID:     query.Get("id"),
+		}
+		if raw := query.Get("since"); raw != "" {
+			since, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				http.Error(w, "Invalid since parameter (expected RFC 3339 time)", http.StatusBadRequest)
+				return
+			}
+			filter.Since = since
+		}
+		if raw := query.Get("limit"); raw != "" {
+			limit, err := strconv.Atoi(raw)
+			if err != nil || limit < 0 {
+				http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+				return
+			}
+			filter.Limit = limit
+		}
+
+		entries, err := log.Query(filter)
+		if err != nil {
+			http.Error(w, "Failed to read audit log: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, entries)
+	}
+}
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Show the audit log of API changes",
+	Long:  `Show the requests that changed the daemon's state (adds, pauses, resumes, deletes, URL updates and setting changes) with the time, client address, token fingerprint and parameters of each.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		since, _ := cmd.Flags().GetDuration("since")
+		action, _ := cmd.Flags().GetString("action")
+		id, _ := cmd.Flags().GetString("id")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		if err := initializeGlobalState(); err != nil {
+			return err
+		}
+
+		baseURL, token, err := resolveAPIConnection(true)
+		if err != nil {
+			return err
+		}
+
+		query := url.Values{}
+		if since > 0 {
+			query.Set("since", time.Now().Add(-since).Format(time.RFC3339))
+		}
+		if action != "" {
+			query.Set("action", action)
+		}
+		if id != "" {
+			query.Set("id", id)
+		}
+		if limit > 0 {
+			query.Set("limit", strconv.Itoa(limit))
+		}
+
+		resp, err := doAPIRequest(http.MethodGet, baseURL, token, "/audit?"+query.Encode(), nil)
+		if err != nil {
+			return fmt.Errorf("error connecting to server: %w", err)
+		}
+		defer func() {
+			if err := resp.Body.Close(); err != nil {
+				utils.Debug("Error closing response body: %v", err)
+			}
+		}()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("server returned %s", resp.Status)
+		}
+		var entries []core.AuditEntry
+		if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+			return core.ExplainVersionMismatch(resp, err)
+		}
+
+		if jsonOutput {
+			data, _ := json.MarshalIndent(entries, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+		printAuditEntries(entries)
+		return nil
+	},
+}
+
+func printAuditEntries(entries []core.AuditEntry) {
+	if len(entries) == 0 {
+		fmt.Println("No audit entries.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "TIME\tCLIENT\tTOKEN\tACTION\tID\tSTATUS\tPARAMS")
+	for _, e := range entries {
+		id := e.ID
+		if len(id) > 8 {
+			id = id[:8]
+		}
+		keys := make([]string, 0, len(e.Params))
+		for key := range e.Params {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		params := make([]string, 0, len(keys))
+		for _, key := range keys {
+			params = append(params, key+"="+e.Params[key])
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			e.Time.Local().Format("2006-01-02 15:04:05"), e.Client, e.Token, e.Action, id, e.Status, strings.Join(params, " "))
+	}
+	_ = w.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.Flags().Bool("json", false, "Output in JSON format")
+	auditCmd.Flags().Duration("since", 0, "Only show entries from this long ago (e.g. 24h)")
+	auditCmd.Flags().String("action", "", "Only show this action (add, pause, resume, delete, update-url, ...)")
+	auditCmd.Flags().String("id", "", "Only show entries for this download ID or ID prefix")
+	auditCmd.Flags().Int("limit", 50, "Show at most this many of the newest entries (0 for all)")
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SurgeDM/Surge/internal/core"
+)
+
+func TestAuditMiddleware_RecordsMutations(t *testing.T) {
+	log := core.NewAuditLog(filepath.Join(t.TempDir(), "audit.log"))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/download", func(w http.ResponseWriter, r *http.Request) {
+		var req DownloadRequest
+		if err := decodeJSONBody(r, &req); err != nil || req.URL == "" {
+			http.Error(w, "handler did not get the body", http.StatusBadRequest)
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, map[string]string{"status": "queued", "id": "new-download-id"})
+	})
+	mux.HandleFunc("/pause", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSONResponse(w, http.StatusOK, map[string]string{"status": "paused"})
+	})
+	mux.HandleFunc("/list", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSONResponse(w, http.StatusOK, []string{})
+	})
+	handler := auditMiddleware(log, authMiddleware("secret", mux))
+
+	send := func(method, target, token, body string) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	send(http.MethodPost, "/download", "secret", `{"url":"https://example.com/a.zip","path":"/tmp","headers":{"Cookie":"session=hunter2"}}`)
+	send(http.MethodPost, "/pause?id=abc", "wrong", "")
+	send(http.MethodGet, "/list", "secret", "")
+
+	entries, err := log.Query(core.AuditFilter{})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want the add and the rejected pause: %+v", len(entries), entries)
+	}
+
+	add := entries[0]
+	if add.Action != "add" || add.Status != http.StatusOK || add.ID != "new-download-id" {
+		t.Errorf("add entry = %+v", add)
+	}
+	if add.Params["url"] != "https://example.com/a.zip" || add.Params["path"] != "/tmp" {
+		t.Errorf("add params = %v", add.Params)
+	}
+	if add.Token != core.TokenFingerprint("secret") {
+		t.Errorf("add token = %q, want the fingerprint of the token", add.Token)
+	}
+	for key, value := range add.Params {
+		if strings.Contains(value, "hunter2") {
+			t.Errorf("param %s leaked request headers: %q", key, value)
+		}
+	}
+
+	pause := entries[1]
+	if pause.Action != "pause" || pause.ID != "abc" || pause.Status != http.StatusUnauthorized {
+		t.Errorf("pause entry = %+v, want a rejected pause of abc", pause)
+	}
+}
@@ -3,12 +3,15 @@ package cmd
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync/atomic"
 	"testing"
@@ -20,6 +23,7 @@ import (
 	"github.com/SurgeDM/Surge/internal/engine/types"
 	"github.com/SurgeDM/Surge/internal/testutil"
 	"github.com/SurgeDM/Surge/internal/utils"
+	"github.com/spf13/cobra"
 )
 
 // TestResolveDownloadID_Remote verifies that resolveDownloadID queries the server
@@ -178,6 +182,32 @@ func TestResolveDownloadID_LocalModeFallsBackToDBWhenRemoteListFails(t *testing.
 	}
 }
 
+func TestResolveDownloadID_Alias(t *testing.T) {
+	setupIsolatedCmdState(t)
+
+	entries := []types.DownloadEntry{
+		{ID: "abcdef01-1234-5678-90ab-cdef12345678", Filename: "nightly.iso", Alias: "nightly-build"},
+		{ID: "abcdef02-1234-5678-90ab-cdef12345678", Filename: "other.iso"},
+	}
+	for _, entry := range entries {
+		if err := state.AddToMasterList(entry); err != nil {
+			t.Fatalf("failed to seed db entry: %v", err)
+		}
+	}
+
+	full, err := resolveDownloadID("nightly-build")
+	if err != nil {
+		t.Fatalf("resolveDownloadID failed: %v", err)
+	}
+	if full != entries[0].ID {
+		t.Fatalf("expected alias to resolve to %s, got %s", entries[0].ID, full)
+	}
+
+	if _, err := resolveDownloadID("abcdef0"); err == nil {
+		t.Fatal("ID prefixes should still be resolved, and this one is ambiguous")
+	}
+}
+
 // TestLsCmd_Alias verify 'l' alias exists
 func TestLsCmd_Alias(t *testing.T) {
 	found := false
@@ -417,7 +447,7 @@ func TestPrintDownloadDetail_TextAndJSON(t *testing.T) {
 	if !strings.Contains(textOut, "ID:         "+status.ID) {
 		t.Fatalf("expected text output to contain ID, got: %s", textOut)
 	}
-	if !strings.Contains(textOut, "Speed:      2.5 MB/s") {
+	if !strings.Contains(textOut, "Speed:      2.6 MB/s") {
 		t.Fatalf("expected text output to contain speed, got: %s", textOut)
 	}
 	if !strings.Contains(textOut, "Error:      sample error") {
@@ -436,6 +466,23 @@ func TestPrintDownloadDetail_TextAndJSON(t *testing.T) {
 	}
 }
 
+func TestStatusWithPhase(t *testing.T) {
+	tests := []struct {
+		status string
+		phase  types.Phase
+		want   string
+	}{
+		{"downloading", types.PhaseDownloading, "downloading"},
+		{"downloading", types.PhaseVerifying, "downloading (verifying)"},
+		{"paused", "", "paused"},
+	}
+	for _, tt := range tests {
+		if got := statusWithPhase(tt.status, tt.phase); got != tt.want {
+			t.Errorf("statusWithPhase(%q, %q) = %q, want %q", tt.status, tt.phase, got, tt.want)
+		}
+	}
+}
+
 func TestRmClean_Offline_Works(t *testing.T) {
 	setupIsolatedCmdState(t)
 	removeActivePort() // Ensure offline mode
@@ -733,7 +780,7 @@ func TestPrintDownloads_FromDatabase_TableAndJSON(t *testing.T) {
 	}
 
 	tableOut := captureStdout(t, func() {
-		if err := printDownloads(false, "", "", false); err != nil {
+		if err := printDownloads(false, "", "", "", false); err != nil {
 			t.Fatalf("printDownloads table failed: %v", err)
 		}
 	})
@@ -751,7 +798,7 @@ func TestPrintDownloads_FromDatabase_TableAndJSON(t *testing.T) {
 	}
 
 	jsonOut := captureStdout(t, func() {
-		if err := printDownloads(true, "", "", false); err != nil {
+		if err := printDownloads(true, "", "", "", false); err != nil {
 			t.Fatalf("printDownloads json failed: %v", err)
 		}
 	})
@@ -773,7 +820,7 @@ func TestPrintDownloads_JSONEmpty(t *testing.T) {
 	removeActivePort()
 
 	out := captureStdout(t, func() {
-		if err := printDownloads(true, "", "", false); err != nil {
+		if err := printDownloads(true, "", "", "", false); err != nil {
 			t.Fatalf("printDownloads empty json failed: %v", err)
 		}
 	})
@@ -809,7 +856,7 @@ func TestPrintDownloads_StrictRemoteEmpty_DoesNotFallbackToDB(t *testing.T) {
 	defer server.Close()
 
 	out := captureStdout(t, func() {
-		if err := printDownloads(true, server.URL, "", true); err != nil {
+		if err := printDownloads(true, "", server.URL, "", true); err != nil {
 			t.Fatalf("printDownloads strict remote failed: %v", err)
 		}
 	})
@@ -932,6 +979,27 @@ func TestSendToServer_UsesBearerTokenFromEnv(t *testing.T) {
 	}
 }
 
+func TestSendToServer_ConfirmationRequiredIsTyped(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		core.SetVersionHeaders(w.Header(), "test")
+		writeJSONResponse(w, http.StatusConflict, map[string]interface{}{
+			"status":    "confirmation_required",
+			"size":      3 * types.GB,
+			"threshold": types.GB,
+		})
+	}))
+	defer server.Close()
+
+	err := sendToServer("https://example.com/huge.iso", nil, "", server.URL, "")
+	var large *types.LargeDownloadError
+	if !errors.As(err, &large) || large.Size != 3*types.GB || large.Threshold != types.GB {
+		t.Fatalf("err = %v, want a LargeDownloadError", err)
+	}
+	if msg := addFailedMessage("https://example.com/huge.iso", err); !strings.Contains(msg, "--yes") {
+		t.Errorf("message = %q, want it to point at --yes", msg)
+	}
+}
+
 func TestGetRemoteDownloads_UsesBearerTokenFromEnv(t *testing.T) {
 	t.Setenv("SURGE_TOKEN", "env-token-123")
 
@@ -1041,6 +1109,12 @@ func TestProcessDownloads_RemoteAndLocal(t *testing.T) {
 		GlobalProgressCh = make(chan any, 10)
 		GlobalPool = download.NewWorkerPool(GlobalProgressCh, 2)
 		GlobalService = core.NewLocalDownloadService(GlobalPool)
+		t.Cleanup(func() {
+			if cleanup := takeLifecycleCleanup(); cleanup != nil {
+				cleanup()
+			}
+			GlobalLifecycle = nil
+		})
 
 		probeServer := testutil.NewHTTPServerT(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Content-Length", "5")
@@ -1118,3 +1192,116 @@ func captureStdout(t *testing.T, fn func()) string {
 	_ = r.Close()
 	return string(data)
 }
+
+func TestCompleteDownloadIDs_QueriesRunningInstance(t *testing.T) {
+	setupIsolatedCmdState(t)
+	resetCommandConnectionState(t)
+
+	downloads := []types.DownloadStatus{
+		{ID: "11aa0000-1234-5678-90ab-cdef12345678", Filename: "nightly.tar.gz", Status: "downloading", Alias: "nightly-build"},
+		{ID: "22bb0000-1234-5678-90ab-cdef12345678", Filename: "old.iso", Status: "paused", Alias: "nightly-old"},
+	}
+	server := testutil.NewHTTPServerT(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/list" {
+			_ = json.NewEncoder(w).Encode(downloads)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	_, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	var port int
+	_, _ = fmt.Sscanf(portStr, "%d", &port)
+	saveActivePort(port)
+	t.Cleanup(removeActivePort)
+
+	got, directive := pauseCmd.ValidArgsFunction(pauseCmd, nil, "ni")
+	if directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Fatalf("directive = %v, want NoFileComp", directive)
+	}
+	if want := []cobra.Completion{"nightly-build\tnightly.tar.gz (downloading)"}; !slices.Equal(got, want) {
+		t.Fatalf("pause completions = %q, want %q (paused downloads are left out)", got, want)
+	}
+
+	got, _ = resumeCmd.ValidArgsFunction(resumeCmd, nil, "22")
+	if len(got) != 1 || !strings.HasPrefix(got[0], downloads[1].ID+"\t") {
+		t.Fatalf("resume completions = %q, want the paused download's ID", got)
+	}
+
+	if got, _ := rmCmd.ValidArgsFunction(rmCmd, []string{"nightly-build"}, ""); len(got) != 0 {
+		t.Fatalf("completions after the first argument = %q, want none", got)
+	}
+}
+
+func TestPrintProvenance_TextAndJSON(t *testing.T) {
+	report := newProvenanceReport(&types.DownloadEntry{
+		ID:              "aabbccdd-1234-5678-90ab-cdef12345678",
+		URL:             "https://example.com/file.zip",
+		Redirects:       []string{"https://mirror.example.com/file.zip", "https://cdn.example.com/file.zip"},
+		DestPath:        "/tmp/file.zip",
+		Filename:        "file.zip",
+		Status:          "completed",
+		TotalSize:       2 * 1024 * 1024,
+		TimeTaken:       4000,
+		AvgSpeed:        512 * 1024,
+		Checksum:        "sha256:abcd",
+		ResponseHeaders: map[string]string{"Server": "nginx"},
+	})
+
+	textOut := captureStdout(t, func() {
+		printProvenance(report, false)
+	})
+	for _, want := range []string{
+		"Redirects: → https://mirror.example.com/file.zip\n           → https://cdn.example.com/file.zip",
+		"Checksum:  sha256:abcd",
+		"Duration:  4s",
+		"Avg speed: 524 kB/s",
+		"Headers:   Server: nginx",
+	} {
+		if !strings.Contains(textOut, want) {
+			t.Fatalf("expected text output to contain %q, got: %s", want, textOut)
+		}
+	}
+
+	jsonOut := captureStdout(t, func() {
+		printProvenance(report, true)
+	})
+	var decoded provenanceReport
+	if err := json.Unmarshal([]byte(jsonOut), &decoded); err != nil {
+		t.Fatalf("failed to decode JSON output: %v (out=%q)", err, jsonOut)
+	}
+	if decoded.Duration != 4 || len(decoded.Redirects) != 2 {
+		t.Fatalf("decoded = %+v, want a 4s duration and 2 redirects", decoded)
+	}
+}
+
+func TestPrintTraffic(t *testing.T) {
+	report := trafficReport{
+		Month: "2026-10",
+		Used:  750 * 1000 * 1000,
+		Quota: 1000 * 1000 * 1000,
+		Months: []state.MonthTraffic{
+			{Month: "2026-10", Bytes: 750 * 1000 * 1000},
+			{Month: "2026-09", Bytes: 2000 * 1000 * 1000},
+		},
+	}
+
+	var text bytes.Buffer
+	printTraffic(&text, report, false)
+	for _, want := range []string{"This month: 750 MB of the 1.0 GB quota (75%)", "2026-10  750 MB", "2026-09  2.0 GB"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("text output missing %q:\n%s", want, text.String())
+		}
+	}
+
+	var jsonOut bytes.Buffer
+	printTraffic(&jsonOut, trafficReport{Month: "2026-10"}, true)
+	var decoded trafficReport
+	if err := json.Unmarshal(jsonOut.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Months == nil || !strings.Contains(jsonOut.String(), `"months": []`) {
+		t.Fatalf("JSON output = %s, want an empty months list", jsonOut.String())
+	}
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -490,6 +491,81 @@ func TestHandleDownload_PathTraversal(t *testing.T) {
 	}
 }
 
+func TestHandleDownload_TLSClientCertNeedsKey(t *testing.T) {
+	body := `{"url": "https://x.com/f", "tls": {"client_cert": "/etc/me.crt"}}`
+	req := httptest.NewRequest(http.MethodPost, "/download", bytes.NewBufferString(body))
+	rec := httptest.NewRecorder()
+
+	svc := core.NewLocalDownloadService(nil)
+	handleDownload(rec, req, "", svc)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400, got %d", rec.Code)
+	}
+	if !bytes.Contains(rec.Body.Bytes(), []byte("client_key")) {
+		t.Error("Expected the missing client_key to be reported")
+	}
+}
+
+func TestHandleDownload_BodyNeedsMethod(t *testing.T) {
+	body := `{"url": "https://x.com/export", "body": "format=csv"}`
+	req := httptest.NewRequest(http.MethodPost, "/download", bytes.NewBufferString(body))
+	rec := httptest.NewRecorder()
+
+	svc := core.NewLocalDownloadService(nil)
+	handleDownload(rec, req, "", svc)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400, got %d", rec.Code)
+	}
+}
+
+func TestDownloadRequest_DecodesMethodAndBody(t *testing.T) {
+	var req DownloadRequest
+	if err := json.Unmarshal([]byte(`{"url":"https://x.com/export","method":"POST","body":"{}"}`), &req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != http.MethodPost || req.Body != "{}" {
+		t.Errorf("decoded request = %+v", req.RequestOptions)
+	}
+	if _, err := validateDownloadRequest(req); err != nil {
+		t.Errorf("validateDownloadRequest = %v", err)
+	}
+}
+
+func TestDownloadRequestFlags(t *testing.T) {
+	bodyFile := filepath.Join(t.TempDir(), "body.json")
+	if err := os.WriteFile(bodyFile, []byte(`{"format":"csv"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringP("method", "X", "", "")
+	cmd.Flags().StringP("data", "d", "", "")
+	cmd.Flags().String("content-type", "", "")
+	if err := cmd.Flags().Parse([]string{"--data", "@" + bodyFile}); err != nil {
+		t.Fatal(err)
+	}
+
+	opts, err := downloadRequestFlags(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Method != http.MethodPost {
+		t.Errorf("method = %q, want POST for a request with --data", opts.Method)
+	}
+	if opts.Body != `{"format":"csv"}` {
+		t.Errorf("body = %q, want the file contents", opts.Body)
+	}
+
+	if err := cmd.Flags().Set("method", "GET"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := downloadRequestFlags(cmd); err == nil {
+		t.Error("GET with --data should be rejected")
+	}
+}
+
 // func TestHandleDownload_StatusQuery(t *testing.T) {
 // 	// Setup mock download
 // 	id := "test-status-id"
@@ -931,6 +1007,34 @@ func TestStartHTTPServer_HealthEndpoint(t *testing.T) {
 	if int(result["port"].(float64)) != port {
 		t.Errorf("Expected port %d, got %v", port, result["port"])
 	}
+	if int(result["api_version"].(float64)) != core.APIVersion {
+		t.Errorf("Expected api_version %d, got %v", core.APIVersion, result["api_version"])
+	}
+	if got := core.PeerAPIVersion(resp.Header); got != core.APIVersion {
+		t.Errorf("Expected %s header %d, got %d", core.APIVersionHeader, core.APIVersion, got)
+	}
+	if resp.Header.Get(core.VersionHeader) != Version {
+		t.Errorf("Expected %s header %q, got %q", core.VersionHeader, Version, resp.Header.Get(core.VersionHeader))
+	}
+}
+
+func TestDoAPIRequest_ExplainsOlderDaemon(t *testing.T) {
+	// A daemon from before the handshake sends no version headers.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(core.APIVersionHeader) == "" {
+			t.Error("client did not announce its API version")
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	_, err := doAPIRequest(http.MethodPost, server.URL, "", "/new-endpoint", nil)
+	if !errors.Is(err, core.ErrVersionMismatch) {
+		t.Fatalf("doAPIRequest() error = %v, want ErrVersionMismatch", err)
+	}
+	if !strings.Contains(err.Error(), "update the daemon") {
+		t.Errorf("error %q does not say what to update", err)
+	}
 }
 
 func TestStartHTTPServer_HasCORSHeaders(t *testing.T) {
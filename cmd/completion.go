@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/spf13/cobra"
+)
+
+// completeDownloadIDs returns a cobra completion function for commands whose
+// first argument is a download. It asks the running instance for its
+// downloads and offers their aliases and IDs, limited to the given statuses
+// when any are passed. Without a running instance nothing is offered.
+func completeDownloadIDs(statuses ...string) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		baseURL, token, err := resolveAPIConnection(false)
+		if err != nil || baseURL == "" {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		downloads, err := GetRemoteDownloads(baseURL, token)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return downloadCompletions(downloads, statuses, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func downloadCompletions(downloads []types.DownloadStatus, statuses []string, toComplete string) []cobra.Completion {
+	var out []cobra.Completion
+	for _, d := range downloads {
+		if len(statuses) > 0 && !slices.Contains(statuses, d.Status) {
+			continue
+		}
+		desc := fmt.Sprintf("%s (%s)", d.Filename, d.Status)
+		if d.Alias != "" && strings.HasPrefix(d.Alias, toComplete) {
+			out = append(out, cobra.CompletionWithDesc(d.Alias, desc))
+		}
+		if strings.HasPrefix(d.ID, toComplete) {
+			out = append(out, cobra.CompletionWithDesc(d.ID, desc))
+		}
+	}
+	return out
+}
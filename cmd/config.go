@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// globalSettingOverrides holds the --set name=value pairs for this run.
+var globalSettingOverrides []string
+
+// globalAccessible is --accessible, a shorthand for
+// --set general.accessible=true.
+var globalAccessible bool
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show and change settings",
+	Long: `Show and change settings stored in config.toml in the Surge config directory.
+
+Settings are layered, each winning over the ones before it: built-in
+defaults, values saved from the TUI, config.toml, environment variables
+named SURGE_<SECTION>_<KEY> (e.g. SURGE_NETWORK_MAX_CONCURRENT_DOWNLOADS)
+and --set section.key=value for a single run.`,
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List every setting with its value and where it came from",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		settings, err := loadSettingsForConfigCmd()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "NAME\tVALUE\tSOURCE")
+		_, _ = fmt.Fprintln(w, "----\t-----\t------")
+		for _, cat := range settings.CategoriesList {
+			for _, set := range cat.Settings {
+				name := config.SettingName(cat.Name, set.Key)
+				value := strings.ReplaceAll(set.FormatValue(), "\n", `\n`)
+				if set.Type == "auth_token" && value != "" {
+					value = "(hidden)"
+				} else if len(value) > 50 {
+					value = value[:47] + "..."
+				}
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", name, value, settings.Source(name))
+			}
+		}
+		return w.Flush()
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:               "get <name>",
+	Short:             "Print the current value of a setting",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSettingNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		settings, err := loadSettingsForConfigCmd()
+		if err != nil {
+			return err
+		}
+		set, _, err := settings.LookupSetting(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(set.FormatValue())
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:               "set <name> <value>",
+	Short:             "Write a setting to config.toml and reload the running instance",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeSettingNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		set, name, err := config.DefaultSettings().LookupSetting(args[0])
+		if err != nil {
+			return err
+		}
+		value, err := set.ParseValue(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %w", name, err)
+		}
+		if err := config.SetConfigFileValue(name, value); err != nil {
+			return fmt.Errorf("failed to write %s: %w", config.GetConfigFilePath(), err)
+		}
+		fmt.Printf("Set %s = %s in %s\n", name, args[1], config.GetConfigFilePath())
+
+		if settings, err := config.LoadSettings(); err == nil {
+			if source := settings.Source(name); source == config.SourceEnv || source == config.SourceFlag {
+				fmt.Printf("Note: %s is overridden by %s for this shell.\n", name, settingOverrideOrigin(name, source))
+			}
+		}
+		if set.NeedsRestart {
+			fmt.Printf("%s takes effect after Surge restarts.\n", name)
+		}
+		reloadRunningInstance()
+		return nil
+	},
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open config.toml in $EDITOR, then reload the running instance",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := config.GetConfigFilePath()
+		if err := config.WriteConfigFileTemplate(); err != nil {
+			return fmt.Errorf("failed to create %s: %w", path, err)
+		}
+
+		editor := configEditor()
+		editCmd := editorCommand(editor, path)
+		editCmd.Stdin, editCmd.Stdout, editCmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := editCmd.Run(); err != nil {
+			return fmt.Errorf("editor %q failed: %w", editor, err)
+		}
+
+		settings, err := config.LoadSettings()
+		if err != nil {
+			return err
+		}
+		printSettingsWarnings(settings.StartupWarnings)
+		reloadRunningInstance()
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configListCmd, configGetCmd, configSetCmd, configEditCmd)
+}
+
+// loadSettingsForConfigCmd loads the layered settings and reports entries
+// that were skipped as invalid.
+func loadSettingsForConfigCmd() (*config.Settings, error) {
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load settings: %w", err)
+	}
+	printSettingsWarnings(settings.StartupWarnings)
+	return settings, nil
+}
+
+func printSettingsWarnings(warnings []string) {
+	for _, warning := range warnings {
+		fmt.Fprintln(os.Stderr, "Warning:", warning)
+	}
+}
+
+func settingOverrideOrigin(name, source string) string {
+	if source == config.SourceEnv {
+		return config.SettingEnvVar(name)
+	}
+	return "--set"
+}
+
+// configEditor picks the editor the way git does: $VISUAL, then $EDITOR,
+// then a platform default.
+func configEditor() string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(env)); editor != "" {
+			return editor
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
+
+// editorCommand runs editor on path. Elsewhere than Windows the editor goes
+// through the shell, so values like "code --wait" and quoting work.
+func editorCommand(editor, path string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		fields := strings.Fields(editor)
+		return exec.Command(fields[0], append(fields[1:], path)...)
+	}
+	return exec.Command("sh", "-c", editor+` "$@"`, editor, path)
+}
+
+// reloadRunningInstance asks a Surge instance running on this machine to
+// reload its settings. --host is ignored: a remote instance reads its own
+// config file, not this one.
+func reloadRunningInstance() {
+	details, ok := getActiveConnectionDetails()
+	if !ok {
+		return
+	}
+	baseURL := fmt.Sprintf("http://127.0.0.1:%d", details.port)
+	resp, err := doAPIRequest(http.MethodPost, baseURL, resolveLocalTokenForDetails(details), "/reload", nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not reach the running Surge instance: %v\nRestart it to apply the change.\n", err)
+		return
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			utils.Debug("Error closing response body: %v", err)
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		fmt.Fprintf(os.Stderr, "The running Surge instance did not reload its settings (%s). Restart it to apply the change.\n", resp.Status)
+		return
+	}
+	var result struct {
+		Warnings []string `json:"warnings"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&result)
+	fmt.Println("Reloaded settings in the running Surge instance.")
+	printSettingsWarnings(result.Warnings)
+}
+
+func completeSettingNames(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var out []cobra.Completion
+	for _, cat := range config.DefaultSettings().CategoriesList {
+		for _, set := range cat.Settings {
+			if name := config.SettingName(cat.Name, set.Key); strings.HasPrefix(name, toComplete) {
+				out = append(out, cobra.CompletionWithDesc(name, set.Label))
+			}
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
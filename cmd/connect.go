@@ -86,12 +86,8 @@ func connectAndRunTUI(_ *cobra.Command, target string) error {
 
 	m := newRemoteRootModel(parsed.BaseURL, service)
 
-	p := tea.NewProgram(m)
-	go func() {
-		for msg := range stream {
-			p.Send(msg)
-		}
-	}()
+	p := tea.NewProgram(m, m.ProgramOptions()...)
+	go m.Pacer.Forward(stream, p.Send)
 
 	if _, err := p.Run(); err != nil {
 		return fmt.Errorf("error running TUI: %w", err)
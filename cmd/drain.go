@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// drainPollInterval is how often drain --wait checks for running downloads.
+const drainPollInterval = time.Second
+
+var drainCmd = &cobra.Command{
+	Use:   "drain",
+	Short: "Let running downloads finish without starting queued ones",
+	Long: `Turn on drain mode: downloads that are running finish, but queued ones,
+and any resumed meanwhile, wait until drain mode is turned off with --off.
+Use --wait to return once nothing is running, for example before maintenance
+or a shutdown.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := initializeGlobalState(); err != nil {
+			return err
+		}
+
+		off, _ := cmd.Flags().GetBool("off")
+		wait, _ := cmd.Flags().GetBool("wait")
+		if off && wait {
+			return fmt.Errorf("--off and --wait cannot be used together")
+		}
+
+		baseURL, token, err := resolveAPIConnection(true)
+		if err != nil {
+			return fmt.Errorf("failed to connect to Surge server: %w", err)
+		}
+
+		method := http.MethodPost
+		if off {
+			method = http.MethodDelete
+		}
+		resp, err := doAPIRequest(method, baseURL, token, "/drain", nil)
+		if err != nil {
+			return fmt.Errorf("failed to send request to server: %w", err)
+		}
+		defer func() {
+			if err := resp.Body.Close(); err != nil {
+				utils.Debug("Error closing response body: %v", err)
+			}
+		}()
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			return fmt.Errorf("server error: %s - %s", resp.Status, string(body))
+		}
+
+		if off {
+			fmt.Println("Drain mode off; queued downloads start again.")
+			return nil
+		}
+		fmt.Println("Draining: running downloads finish, queued ones wait. Run surge drain --off to undo.")
+		if !wait {
+			return nil
+		}
+		return waitForRunningDownloads(baseURL, token)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(drainCmd)
+	drainCmd.Flags().Bool("off", false, "Turn drain mode off and start queued downloads again")
+	drainCmd.Flags().Bool("wait", false, "Return once no download is running")
+}
+
+// waitForRunningDownloads polls the server until no download is running.
+func waitForRunningDownloads(baseURL, token string) error {
+	reported := -1
+	for {
+		downloads, err := GetRemoteDownloads(baseURL, token)
+		if err != nil {
+			return err
+		}
+		running := 0
+		for _, d := range downloads {
+			if d.Status == "downloading" || d.Status == "pausing" {
+				running++
+			}
+		}
+		if running == 0 {
+			fmt.Println("No downloads running.")
+			return nil
+		}
+		if running != reported {
+			fmt.Printf("Waiting for %d running download(s)...\n", running)
+			reported = running
+		}
+		time.Sleep(drainPollInterval)
+	}
+}
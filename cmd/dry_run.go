@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"text/tabwriter"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/i18n"
+	"github.com/SurgeDM/Surge/internal/processing"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// runDryRun prints what adding urls would do without adding them. A running
+// instance plans them against its own settings and queue; with none running
+// they are planned in this process.
+func runDryRun(urls []string, output string, baseURL string, token string, tlsOpts types.TLSOptions, request types.RequestOptions) error {
+	outPath := resolveClientOutputPath(output)
+	var lifecycle *processing.LifecycleManager
+	isExplicit := false
+	if baseURL == "" {
+		settings := getSettings()
+		outPath = utils.EnsureAbsPath(resolveOutputDir(outPath, false, "", settings))
+		isExplicit = isExplicitOutputPath(outPath, config.Resolve[string](settings.General.DefaultDownloadDir))
+		lifecycle = processing.NewLifecycleManager(nil, nil)
+	}
+
+	planned, failed := 0, 0
+	for _, arg := range urls {
+		url, mirrors := ParseURLArg(arg)
+		if url == "" {
+			continue
+		}
+
+		var plan *processing.DownloadPlan
+		var err error
+		if lifecycle != nil {
+			plan, err = lifecycle.Plan(context.Background(), &processing.DownloadRequest{
+				URL:                url,
+				Path:               outPath,
+				Mirrors:            mirrors,
+				IsExplicitCategory: isExplicit,
+				TLS:                tlsOpts,
+				Request:            request,
+			})
+		} else {
+			plan, err = requestDownloadPlan(baseURL, token, DownloadRequest{
+				URL:            url,
+				Mirrors:        mirrors,
+				Path:           outPath,
+				TLS:            tlsOpts,
+				DryRun:         true,
+				RequestOptions: request,
+			})
+		}
+		if err != nil {
+			fmt.Println(i18n.T("cli.dry_run_failed", url, err))
+			failed++
+			continue
+		}
+
+		if planned > 0 {
+			fmt.Println()
+		}
+		printDownloadPlan(os.Stdout, plan)
+		planned++
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("could not plan %d of %d downloads", failed, planned+failed)
+	}
+	if planned == 0 {
+		return fmt.Errorf("no valid URLs to plan")
+	}
+	return nil
+}
+
+// requestDownloadPlan asks the server at baseURL what adding req would do.
+func requestDownloadPlan(baseURL string, token string, req DownloadRequest) (*processing.DownloadPlan, error) {
+	jsonData, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := doAPIRequest(http.MethodPost, baseURL, token, "/download", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			utils.Debug("Error closing response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("server error: %s - %s", resp.Status, string(body))
+	}
+
+	var result struct {
+		Status string                   `json:"status"`
+		Plan   *processing.DownloadPlan `json:"plan"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode plan: %w", err)
+	}
+	if result.Status != "dry_run" || result.Plan == nil {
+		// An older server ignores dry_run and queues the download instead
+		return nil, fmt.Errorf("server does not support dry runs (status %q)", result.Status)
+	}
+	return result.Plan, nil
+}
+
+func printDownloadPlan(out io.Writer, plan *processing.DownloadPlan) {
+	w := tabwriter.NewWriter(out, 0, 0, 1, ' ', 0)
+	field := func(id, value string) {
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", i18n.T("cli.field_"+id), value)
+	}
+	list := func(id string, values []string) {
+		for i, v := range values {
+			if i == 0 {
+				field(id, v)
+			} else {
+				_, _ = fmt.Fprintf(w, "\t%s\n", v)
+			}
+		}
+	}
+
+	field("url", plan.URL)
+	hops := make([]string, len(plan.Redirects))
+	for i, hop := range plan.Redirects {
+		hops[i] = "\u2192 " + hop
+	}
+	list("redirects", hops)
+	field("filename", plan.Filename)
+	field("path", plan.DestPath)
+	if plan.Size > 0 {
+		field("size", utils.ConvertBytesToHumanReadable(plan.Size))
+	} else {
+		field("size", i18n.T("cli.size_unknown"))
+	}
+	if plan.SupportsRange {
+		field("ranges", i18n.T("cli.ranges_supported"))
+	} else {
+		field("ranges", i18n.T("cli.ranges_unsupported"))
+	}
+	list("mirrors", plan.Mirrors)
+	field("connections", fmt.Sprint(plan.Connections))
+	if plan.Chunks > 1 {
+		field("chunks", i18n.T("cli.chunks_of", plan.Chunks, utils.ConvertBytesToHumanReadable(plan.ChunkSize)))
+	} else {
+		field("chunks", fmt.Sprint(plan.Chunks))
+	}
+
+	var notes []string
+	if plan.ProbeError != "" {
+		notes = append(notes, i18n.T("cli.dry_run_probe_failed", plan.ProbeError))
+	}
+	if plan.NeedsConfirmation {
+		notes = append(notes, i18n.T("cli.dry_run_needs_confirm"))
+	}
+	if plan.UnexpectedHTML {
+		notes = append(notes, i18n.T("cli.dry_run_html"))
+	}
+	list("notes", notes)
+	_ = w.Flush()
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/processing"
+)
+
+func TestHandleDownload_DryRunPlansWithoutQueueing(t *testing.T) {
+	previousLifecycle := GlobalLifecycle
+	t.Cleanup(func() { GlobalLifecycle = previousLifecycle })
+	GlobalLifecycle = processing.NewLifecycleManager(nil, nil)
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes 0-0/%d", 64*types.MB))
+		w.Header().Set("Content-Length", "1")
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write([]byte("x"))
+	}))
+	defer origin.Close()
+
+	dir := t.TempDir()
+	service := &batchAddRecordingService{httpAPITestService: &httpAPITestService{}}
+	body := fmt.Sprintf(`{"url": %q, "path": %q, "filename": "image.iso", "is_explicit_category": true, "dry_run": true}`, origin.URL+"/image.iso", dir)
+	recorder := httptest.NewRecorder()
+	handleDownload(recorder, httptest.NewRequest(http.MethodPost, "/download", strings.NewReader(body)), "", service)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d (%s), want 200", recorder.Code, recorder.Body.String())
+	}
+	var resp struct {
+		Status string                  `json:"status"`
+		Plan   processing.DownloadPlan `json:"plan"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != "dry_run" || resp.Plan.DestPath != filepath.Join(dir, "image.iso") || resp.Plan.Size != 64*types.MB {
+		t.Fatalf("response = %+v, want a dry run plan for image.iso", resp)
+	}
+	if resp.Plan.Chunks < 2 {
+		t.Fatalf("chunks = %d, want a 64MB file with ranges split up", resp.Plan.Chunks)
+	}
+	if len(service.added) != 0 {
+		t.Fatalf("dry run queued %v", service.added)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("dry run wrote %d files, want none", len(entries))
+	}
+}
+
+func TestPrintDownloadPlan(t *testing.T) {
+	var out bytes.Buffer
+	printDownloadPlan(&out, &processing.DownloadPlan{
+		URL:               "https://example.com/image.iso",
+		Redirects:         []string{"https://cdn.example.com/image.iso"},
+		Filename:          "image.iso",
+		DestPath:          "/downloads/image.iso",
+		Size:              64 * types.MB,
+		SupportsRange:     true,
+		Connections:       8,
+		Chunks:            8,
+		ChunkSize:         8 * types.MB,
+		NeedsConfirmation: true,
+	})
+
+	for _, want := range []string{
+		"Redirects:   \u2192 https://cdn.example.com/image.iso",
+		"Path:        /downloads/image.iso",
+		"Ranges:      supported",
+		"Connections: 8",
+		"Chunks:      8 of 8.4 MB",
+		"Notes:       over confirm_size_threshold",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
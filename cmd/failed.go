@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var failedCmd = &cobra.Command{
+	Use:   "failed [ID...]",
+	Short: "List or retry downloads that failed for good",
+	Long: `List the downloads that failed more than max_download_retries times in a
+row, with the error each last failed with. Use --retry to resume the given
+downloads, or all of them without IDs, each with a fresh set of retries.`,
+	ValidArgsFunction: completeDownloadIDs("failed"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := initializeGlobalState(); err != nil {
+			return err
+		}
+
+		retry, _ := cmd.Flags().GetBool("retry")
+		if !retry {
+			if len(args) > 0 {
+				return fmt.Errorf("download IDs can only be given with --retry")
+			}
+			return printFailedDownloads()
+		}
+
+		query := url.Values{}
+		for _, arg := range args {
+			id, err := resolveDownloadID(arg)
+			if err != nil {
+				return err
+			}
+			query.Add("id", id)
+		}
+		endpoint := "/retry-failed"
+		if len(query) > 0 {
+			endpoint += "?" + query.Encode()
+		}
+		ids, err := executeGlobalPause(endpoint)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Retrying %d download(s).\n", len(ids))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(failedCmd)
+	failedCmd.Flags().Bool("retry", false, "Retry the given failed downloads, or all of them")
+}
+
+func printFailedDownloads() error {
+	baseURL, token, err := resolveAPIConnection(true)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Surge server: %w", err)
+	}
+	downloads, err := GetRemoteDownloads(baseURL, token)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	count := 0
+	for _, d := range downloads {
+		if d.Status != "failed" {
+			continue
+		}
+		id := d.ID
+		if d.Alias != "" {
+			id = d.Alias
+		} else if len(id) > 8 {
+			id = id[:8]
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%d attempts\t%s\t%s\n", id, d.Filename, d.Attempts, d.ErrorCode, d.Error)
+		count++
+	}
+	if count == 0 {
+		fmt.Println("No failed downloads.")
+		return nil
+	}
+	return w.Flush()
+}
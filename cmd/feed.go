@@ -0,0 +1,440 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/core"
+	"github.com/SurgeDM/Surge/internal/engine"
+	"github.com/SurgeDM/Surge/internal/feed"
+	"github.com/SurgeDM/Surge/internal/utils"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// feedPollTick is how often the daemon looks for feeds that are due.
+	feedPollTick = time.Minute
+	// minFeedInterval keeps a feed from being polled more often than this.
+	minFeedInterval  = 5 * time.Minute
+	feedFetchTimeout = 30 * time.Second
+)
+
+// feedsMu guards the feed list on disk against the poller and the API
+// changing it at the same time.
+var feedsMu sync.Mutex
+
+func feedsPath() string {
+	return filepath.Join(config.GetStateDir(), "feeds.json")
+}
+
+// FeedRequest is the body of POST /feeds.
+type FeedRequest struct {
+	URL          string `json:"url"`
+	Include      string `json:"include,omitempty"`
+	Exclude      string `json:"exclude,omitempty"`
+	Interval     string `json:"interval,omitempty"`
+	Path         string `json:"path,omitempty"`
+	SkipExisting bool   `json:"skip_existing,omitempty"` // Treat the items already in the feed as seen
+}
+
+func fetchFeed(ctx context.Context, rawURL string) ([]feed.Item, error) {
+	settings := getSettings()
+	transport := engine.DefaultNetworkPool.AcquireTransport(
+		config.Resolve[string](settings.Network.ProxyURL),
+		config.Resolve[string](settings.Network.CustomDNS),
+		1,
+	)
+	defer engine.DefaultNetworkPool.ReleaseTransport(transport)
+
+	ctx, cancel := context.WithTimeout(ctx, feedFetchTimeout)
+	defer cancel()
+	return feed.Fetch(ctx, &http.Client{Transport: transport}, rawURL)
+}
+
+// watchFeeds polls every due feed and queues its new matching items until
+// ctx is done.
+func watchFeeds(ctx context.Context, service core.DownloadService) {
+	ticker := time.NewTicker(feedPollTick)
+	defer ticker.Stop()
+	for {
+		pollDueFeeds(ctx, service, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func pollDueFeeds(ctx context.Context, service core.DownloadService, now time.Time) {
+	feedsMu.Lock()
+	feeds, err := feed.Load(feedsPath())
+	feedsMu.Unlock()
+	if err != nil {
+		utils.Debug("Feeds: %v", err)
+		return
+	}
+
+	for _, f := range feeds {
+		if ctx.Err() != nil {
+			return
+		}
+		if !f.Due(now) {
+			continue
+		}
+		seen, pollErr := pollFeed(ctx, service, f)
+		if err := updateFeed(f.ID, func(stored *feed.Feed) {
+			stored.LastChecked = now
+			stored.LastError = ""
+			if pollErr != nil {
+				stored.LastError = pollErr.Error()
+			}
+			stored.MarkSeen(seen...)
+		}); err != nil {
+			utils.Debug("Feeds: saving %s: %v", f.URL, err)
+		}
+	}
+}
+
+// pollFeed queues the items of f that match its filters and were not seen
+// before, returning the keys of every item it handled. Items whose URL is
+// already queued or in the history count as handled without queuing again.
+func pollFeed(ctx context.Context, service core.DownloadService, f feed.Feed) ([]string, error) {
+	items, err := fetchFeed(ctx, f.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	known := knownDownloadURLs(service)
+	var seen []string
+	for _, item := range items {
+		if f.HasSeen(item.Key) || !f.Matches(item) {
+			continue
+		}
+		if known[item.URL] {
+			seen = append(seen, item.Key)
+			continue
+		}
+		if processDownloads([]string{item.URL}, f.OutputDir, 0) == 0 {
+			// Left unseen so the next poll tries again
+			continue
+		}
+		known[item.URL] = true
+		seen = append(seen, item.Key)
+		publishSystemLog(fmt.Sprintf("Feed %s: queued %s", f.ID[:8], item.URL))
+	}
+	return seen, nil
+}
+
+func knownDownloadURLs(service core.DownloadService) map[string]bool {
+	known := make(map[string]bool)
+	if service == nil {
+		return known
+	}
+	if statuses, err := service.List(); err == nil {
+		for _, st := range statuses {
+			known[st.URL] = true
+		}
+	}
+	if history, err := service.History(); err == nil {
+		for _, entry := range history {
+			known[entry.URL] = true
+		}
+	}
+	return known
+}
+
+// updateFeed applies change to the stored feed with id, if it still exists.
+func updateFeed(id string, change func(*feed.Feed)) error {
+	feedsMu.Lock()
+	defer feedsMu.Unlock()
+
+	feeds, err := feed.Load(feedsPath())
+	if err != nil {
+		return err
+	}
+	for i := range feeds {
+		if feeds[i].ID == id {
+			change(&feeds[i])
+			return feed.Save(feedsPath(), feeds)
+		}
+	}
+	return nil
+}
+
+// handleFeeds serves GET (list), POST (add) and DELETE ?id= (remove) on
+// /feeds.
+func handleFeeds(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		feedsMu.Lock()
+		feeds, err := feed.Load(feedsPath())
+		feedsMu.Unlock()
+		if err != nil {
+			http.Error(w, "Failed to read feeds: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if feeds == nil {
+			feeds = []feed.Feed{}
+		}
+		writeJSONResponse(w, http.StatusOK, feeds)
+
+	case http.MethodPost:
+		var req FeedRequest
+		if err := decodeJSONBody(r, &req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		f, status, err := addFeed(r.Context(), req)
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, f)
+
+	case http.MethodDelete:
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			http.Error(w, "Missing id parameter", http.StatusBadRequest)
+			return
+		}
+		removed, err := removeFeed(id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if removed == "" {
+			http.Error(w, "feed not found", http.StatusNotFound)
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, map[string]string{"status": "deleted", "id": removed})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// addFeed checks that req points at a readable feed and stores it. The
+// feed is polled by the next tick of the poller.
+func addFeed(ctx context.Context, req FeedRequest) (feed.Feed, int, error) {
+	f := feed.Feed{
+		ID:        uuid.New().String(),
+		URL:       strings.TrimSpace(req.URL),
+		Include:   req.Include,
+		Exclude:   req.Exclude,
+		Interval:  req.Interval,
+		OutputDir: req.Path,
+	}
+	if f.Interval == "" {
+		f.Interval = "1h"
+	}
+	if parsed, err := url.Parse(f.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return f, http.StatusBadRequest, fmt.Errorf("feed URL must be http or https")
+	}
+	if err := f.Validate(minFeedInterval); err != nil {
+		return f, http.StatusBadRequest, err
+	}
+
+	items, err := fetchFeed(ctx, f.URL)
+	if err != nil {
+		return f, http.StatusBadGateway, fmt.Errorf("reading feed: %w", err)
+	}
+	if req.SkipExisting {
+		for _, item := range items {
+			f.MarkSeen(item.Key)
+		}
+		f.LastChecked = time.Now()
+	}
+
+	feedsMu.Lock()
+	defer feedsMu.Unlock()
+	feeds, err := feed.Load(feedsPath())
+	if err != nil {
+		return f, http.StatusInternalServerError, err
+	}
+	for _, existing := range feeds {
+		if existing.URL == f.URL {
+			return f, http.StatusConflict, fmt.Errorf("feed already added as %s", existing.ID[:8])
+		}
+	}
+	if err := feed.Save(feedsPath(), append(feeds, f)); err != nil {
+		return f, http.StatusInternalServerError, err
+	}
+	return f, http.StatusOK, nil
+}
+
+// removeFeed deletes the feed whose ID starts with prefix and returns its
+// full ID, or "" when none matches.
+func removeFeed(prefix string) (string, error) {
+	feedsMu.Lock()
+	defer feedsMu.Unlock()
+
+	feeds, err := feed.Load(feedsPath())
+	if err != nil {
+		return "", err
+	}
+	for i, f := range feeds {
+		if strings.HasPrefix(f.ID, prefix) {
+			if err := feed.Save(feedsPath(), append(feeds[:i], feeds[i+1:]...)); err != nil {
+				return "", err
+			}
+			return f.ID, nil
+		}
+	}
+	return "", nil
+}
+
+var feedCmd = &cobra.Command{
+	Use:   "feed",
+	Short: "Queue new downloads from RSS and Atom feeds",
+	Long: `Feeds are polled by the running instance. Each new item whose enclosure or
+link matches the feed's filters is queued once, and items whose URL is
+already queued or in the history are skipped.`,
+}
+
+var feedAddCmd = &cobra.Command{
+	Use:   "add <url>",
+	Short: "Start polling a feed",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		include, _ := cmd.Flags().GetString("include")
+		exclude, _ := cmd.Flags().GetString("exclude")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		output, _ := cmd.Flags().GetString("output")
+		skipExisting, _ := cmd.Flags().GetBool("skip-existing")
+
+		if output != "" {
+			output = utils.EnsureAbsPath(output)
+		}
+		body, err := json.Marshal(FeedRequest{
+			URL:          args[0],
+			Include:      include,
+			Exclude:      exclude,
+			Interval:     interval.String(),
+			Path:         output,
+			SkipExisting: skipExisting,
+		})
+		if err != nil {
+			return fmt.Errorf("error creating request: %w", err)
+		}
+
+		var added feed.Feed
+		if err := feedAPIRequest(http.MethodPost, "/feeds", bytes.NewReader(body), &added); err != nil {
+			return err
+		}
+		fmt.Printf("Added feed %s, checked every %s\n", added.ID[:8], added.Interval)
+		return nil
+	},
+}
+
+var feedLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List polled feeds",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+
+		var feeds []feed.Feed
+		if err := feedAPIRequest(http.MethodGet, "/feeds", nil, &feeds); err != nil {
+			return err
+		}
+		if jsonOutput {
+			data, _ := json.MarshalIndent(feeds, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+		if len(feeds) == 0 {
+			fmt.Println("No feeds. Add one with 'surge feed add <url>'.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "ID\tURL\tINCLUDE\tINTERVAL\tLAST CHECKED\tSTATUS")
+		for _, f := range feeds {
+			checked := "never"
+			if !f.LastChecked.IsZero() {
+				checked = f.LastChecked.Local().Format("2006-01-02 15:04")
+			}
+			status := "ok"
+			if f.LastError != "" {
+				status = f.LastError
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", f.ID[:8], f.URL, f.Include, f.Interval, checked, status)
+		}
+		return w.Flush()
+	},
+}
+
+var feedRmCmd = &cobra.Command{
+	Use:   "rm <id>",
+	Short: "Stop polling a feed",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var resp map[string]string
+		if err := feedAPIRequest(http.MethodDelete, "/feeds?id="+url.QueryEscape(args[0]), nil, &resp); err != nil {
+			return err
+		}
+		fmt.Printf("Removed feed %s\n", resp["id"][:8])
+		return nil
+	},
+}
+
+// feedAPIRequest sends a /feeds request to the running instance and decodes
+// the answer into out.
+func feedAPIRequest(method, path string, body io.Reader, out any) error {
+	if err := initializeGlobalState(); err != nil {
+		return err
+	}
+	baseURL, token, err := resolveAPIConnection(true)
+	if err != nil {
+		return err
+	}
+
+	resp, err := doAPIRequest(method, baseURL, token, path, body)
+	if err != nil {
+		return fmt.Errorf("error connecting to server: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			utils.Debug("Error closing response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		var msg bytes.Buffer
+		_, _ = msg.ReadFrom(resp.Body)
+		if text := strings.TrimSpace(msg.String()); text != "" {
+			return fmt.Errorf("server returned %s: %s", resp.Status, text)
+		}
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return core.ExplainVersionMismatch(resp, err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(feedCmd)
+	feedCmd.AddCommand(feedAddCmd, feedLsCmd, feedRmCmd)
+
+	feedAddCmd.Flags().String("include", "", "Only queue items whose URL or title matches this regular expression")
+	feedAddCmd.Flags().String("exclude", "", "Skip items whose URL or title matches this regular expression")
+	feedAddCmd.Flags().Duration("interval", time.Hour, "How often to check the feed (at least 5m)")
+	feedAddCmd.Flags().StringP("output", "o", "", "Download directory for queued items (default: default download directory)")
+	feedAddCmd.Flags().Bool("skip-existing", false, "Only queue items published after the feed is added")
+	feedLsCmd.Flags().Bool("json", false, "Output in JSON format")
+}
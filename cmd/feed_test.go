@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/feed"
+)
+
+const testFeedXML = `<rss version="2.0"><channel>
+<item><title>Distro 1.0</title><guid>rel-1.0</guid><enclosure url="https://example.com/distro-1.0.iso"/></item>
+<item><title>Distro 1.1</title><guid>rel-1.1</guid><enclosure url="https://example.com/distro-1.1.iso"/></item>
+<item><title>Changelog</title><guid>log</guid><link>https://example.com/changelog.txt</link></item>
+</channel></rss>`
+
+func newTestFeedServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(testFeedXML))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHandleFeeds_AddListRemove(t *testing.T) {
+	setupXDGEnvIsolation(t)
+	server := newTestFeedServer(t)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handleFeeds(rec, httptest.NewRequest(http.MethodPost, "/feeds", strings.NewReader(body)))
+		return rec
+	}
+
+	rec := post(`{"url": "` + server.URL + `", "include": "\\.iso$", "interval": "2h", "skip_existing": true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("add status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var added feed.Feed
+	if err := json.Unmarshal(rec.Body.Bytes(), &added); err != nil {
+		t.Fatal(err)
+	}
+	if added.Interval != "2h" || !added.HasSeen("rel-1.0") || added.LastChecked.IsZero() {
+		t.Errorf("added feed = %+v, want the existing items marked seen", added)
+	}
+
+	if got := post(`{"url": "` + server.URL + `"}`).Code; got != http.StatusConflict {
+		t.Errorf("adding the same feed again = %d, want 409", got)
+	}
+	if got := post(`{"url": "` + server.URL + `/other", "interval": "1m"}`).Code; got != http.StatusBadRequest {
+		t.Errorf("interval below the minimum = %d, want 400", got)
+	}
+	if got := post(`{"url": "file:///etc/passwd"}`).Code; got != http.StatusBadRequest {
+		t.Errorf("non-HTTP feed URL = %d, want 400", got)
+	}
+
+	rec = httptest.NewRecorder()
+	handleFeeds(rec, httptest.NewRequest(http.MethodGet, "/feeds", nil))
+	var listed []feed.Feed
+	if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil || len(listed) != 1 {
+		t.Fatalf("list = %s (%v), want one feed", rec.Body.String(), err)
+	}
+
+	rec = httptest.NewRecorder()
+	handleFeeds(rec, httptest.NewRequest(http.MethodDelete, "/feeds?id="+added.ID[:8], nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("delete status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if feeds, _ := feed.Load(feedsPath()); len(feeds) != 0 {
+		t.Errorf("feeds after delete = %+v", feeds)
+	}
+}
+
+func TestPollDueFeeds_SkipsKnownURLs(t *testing.T) {
+	setupXDGEnvIsolation(t)
+	server := newTestFeedServer(t)
+
+	stored := feed.Feed{ID: "feed-0001-test", URL: server.URL, Include: `\.iso$`, Interval: "1h"}
+	if err := feed.Save(feedsPath(), []feed.Feed{stored}); err != nil {
+		t.Fatal(err)
+	}
+	service := &httpAPITestService{
+		statuses: []types.DownloadStatus{{ID: "a", URL: "https://example.com/distro-1.0.iso"}},
+		history:  []types.DownloadEntry{{ID: "b", URL: "https://example.com/distro-1.1.iso"}},
+	}
+
+	now := time.Now()
+	pollDueFeeds(context.Background(), service, now)
+
+	feeds, err := feed.Load(feedsPath())
+	if err != nil || len(feeds) != 1 {
+		t.Fatalf("Load = %+v, %v", feeds, err)
+	}
+	polled := feeds[0]
+	if !polled.LastChecked.Equal(now) || polled.LastError != "" {
+		t.Errorf("feed after poll = %+v", polled)
+	}
+	if !polled.HasSeen("rel-1.0") || !polled.HasSeen("rel-1.1") {
+		t.Errorf("items already downloaded were not marked seen: %v", polled.Seen)
+	}
+	if polled.HasSeen("log") {
+		t.Error("an item the include filter rejects was marked seen")
+	}
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/core"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// filesPrefix is where serve_files shares the download directory.
+const filesPrefix = "/files/"
+
+// handleServedFiles serves the download directory under /files/ when
+// serve_files is on: files with range requests, directories as listings.
+// Unfinished downloads are hidden, nothing outside the directory can be
+// reached, even through a symlink, and every file served is recorded in log.
+func handleServedFiles(defaultOutputDir string, log *core.AuditLog) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		settings := getSettings()
+		if !config.Resolve[bool](settings.Extension.ServeFiles) {
+			http.NotFound(w, r)
+			return
+		}
+
+		dir := servedFilesDir(settings, defaultOutputDir)
+		root, err := os.OpenRoot(dir)
+		if err != nil {
+			http.Error(w, "Download directory unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		defer func() { _ = root.Close() }()
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		http.StripPrefix(strings.TrimSuffix(filesPrefix, "/"), http.FileServerFS(finishedFilesFS{root.FS()})).ServeHTTP(rec, r)
+
+		name := strings.TrimPrefix(path.Clean("/"+strings.TrimPrefix(r.URL.Path, filesPrefix)), "/")
+		if name == "" || strings.HasSuffix(r.URL.Path, "/") {
+			return // Listings are not file accesses
+		}
+		entry := core.AuditEntry{
+			Time:   time.Now(),
+			Client: auditClient(r),
+			Token:  core.TokenFingerprint(requestToken(r)),
+			Method: r.Method,
+			Action: "files",
+			Params: map[string]string{"path": name},
+			Status: rec.status,
+		}
+		if rng := r.Header.Get("Range"); rng != "" {
+			entry.Params["range"] = rng
+		}
+		if err := log.Append(entry); err != nil {
+			utils.Debug("Failed to write file access log: %v", err)
+		}
+	}
+}
+
+// servedFilesDir is the directory shared at /files/: the one downloads go
+// to by default, or the fixed part of it when it is a path template.
+func servedFilesDir(settings *config.Settings, defaultOutputDir string) string {
+	dir := config.PathTemplateBase(resolveOutputDir("", false, defaultOutputDir, settings))
+	if dir == "" {
+		dir = "."
+	}
+	return utils.EnsureAbsPath(dir)
+}
+
+// requestToken returns the token r authenticated with, as a bearer token or
+// as the password of HTTP basic auth.
+func requestToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return token
+	}
+	if _, password, ok := r.BasicAuth(); ok {
+		return password
+	}
+	return ""
+}
+
+// finishedFilesFS hides the partial files of running downloads.
+type finishedFilesFS struct {
+	fs.FS
+}
+
+func (f finishedFilesFS) Open(name string) (fs.File, error) {
+	if strings.HasSuffix(name, types.IncompleteSuffix) {
+		return nil, fs.ErrNotExist
+	}
+	file, err := f.FS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	if dir, ok := file.(fs.ReadDirFile); ok && info.IsDir() {
+		return finishedDir{dir}, nil
+	}
+	return file, nil
+}
+
+type finishedDir struct {
+	fs.ReadDirFile
+}
+
+func (d finishedDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	entries, err := d.ReadDirFile.ReadDir(n)
+	return slices.DeleteFunc(entries, func(e fs.DirEntry) bool {
+		return strings.HasSuffix(e.Name(), types.IncompleteSuffix)
+	}), err
+}
+
+// statusRecorder remembers the status a handler answered with.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/core"
+)
+
+func TestServedFiles_SharesFinishedFilesWhenEnabled(t *testing.T) {
+	tempDir := setupXDGEnvIsolation(t)
+	original := globalSettings
+	t.Cleanup(func() {
+		globalSettings = original
+	})
+
+	dir := filepath.Join(tempDir, "downloads")
+	for name, body := range map[string]string{"done.iso": "0123456789", "running.bin.surge": "partial"} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(tempDir, filepath.Join(dir, "escape")); err != nil {
+		t.Fatal(err)
+	}
+
+	const token = "files-token"
+	mux := http.NewServeMux()
+	registerHTTPRoutes(mux, 0, dir, &httpAPITestService{})
+	server := httptest.NewServer(authMiddleware(token, mux))
+	t.Cleanup(server.Close)
+
+	get := func(path, rng string) (int, string) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		req.SetBasicAuth("surge", token)
+		if rng != "" {
+			req.Header.Set("Range", rng)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	globalSettings = config.DefaultSettings()
+	if code, _ := get("/files/done.iso", ""); code != http.StatusNotFound {
+		t.Fatalf("with serve_files off: status %d, want 404", code)
+	}
+
+	globalSettings.Extension.ServeFiles.Value = true
+	if code, body := get("/files/done.iso", "bytes=3-5"); code != http.StatusPartialContent || body != "345" {
+		t.Fatalf("range request = %d %q, want 206 \"345\"", code, body)
+	}
+	if code, body := get("/files/", ""); code != http.StatusOK || !strings.Contains(body, "done.iso") || strings.Contains(body, "running.bin") {
+		t.Fatalf("listing = %d %q, want done.iso without the partial file", code, body)
+	}
+	if code, _ := get("/files/running.bin.surge", ""); code != http.StatusNotFound {
+		t.Fatalf("partial file: status %d, want 404", code)
+	}
+	if code, _ := get("/files/escape/", ""); code == http.StatusOK {
+		t.Fatal("a symlink out of the download directory was followed")
+	}
+
+	entries, err := core.NewAuditLog(auditLogPath()).Query(core.AuditFilter{Action: "files"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 || entries[0].Params["path"] != "done.iso" || entries[0].Status != http.StatusPartialContent {
+		t.Fatalf("access log = %+v, want the range request to done.iso first", entries)
+	}
+
+	resp, err := http.Get(server.URL + "/files/done.iso")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized || resp.Header.Get("WWW-Authenticate") == "" {
+		t.Fatalf("without auth: status %d, want 401 with a basic auth challenge", resp.StatusCode)
+	}
+}
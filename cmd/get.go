@@ -0,0 +1,361 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine"
+	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/storage"
+	"github.com/SurgeDM/Surge/internal/engine/stream"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/processing"
+	"github.com/SurgeDM/Surge/internal/utils"
+	"github.com/SurgeDM/Surge/internal/webdav"
+	"github.com/google/uuid"
+)
+
+// runStandaloneGet downloads urls in this process when no Surge instance is
+// running, sharing one worker pool between them, and returns an error if any
+// of them fails. headers are sent with every request.
+func runStandaloneGet(urls []string, outputDir string, tlsOpts types.TLSOptions, request types.RequestOptions, headers map[string]string, showProgress, sums bool) error {
+	releaseLock, err := acquireRootInstanceLock()
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
+	resetGlobalEnqueueContext()
+	if err := ensureGlobalLocalServiceAndLifecycle(); err != nil {
+		return fmt.Errorf("error creating lifecycle event stream: %w", err)
+	}
+	defer func() { _ = executeGlobalShutdown("get: finished") }()
+
+	lifecycle := currentLifecycle()
+	if lifecycle == nil {
+		return fmt.Errorf("lifecycle manager unavailable")
+	}
+
+	type getTarget struct {
+		url     string
+		mirrors []string
+	}
+	var targets []getTarget
+	for _, arg := range urls {
+		if url, mirrors := ParseURLArg(arg); url != "" {
+			targets = append(targets, getTarget{url, mirrors})
+		}
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("no valid URLs to add")
+	}
+
+	stream, cleanup, err := GlobalService.StreamEvents(context.Background())
+	if err != nil {
+		return fmt.Errorf("error starting event stream: %w", err)
+	}
+	tracker := newGetTracker()
+	tracker.expect(len(targets))
+	// The total line counts the whole batch, finished files included
+	progress := newHeadlessProgressIfTerminal(showProgress)
+	progress.expect(len(targets))
+	consumerDone := make(chan struct{})
+	go func() {
+		defer close(consumerDone)
+		runHeadlessConsumer(stream, progress, tracker.observe)
+	}()
+	defer func() {
+		cleanup()
+		<-consumerDone
+	}()
+
+	settings := getSettings()
+	outPath := utils.EnsureAbsPath(resolveOutputDir(outputDir, false, "", settings))
+	isExplicit := isExplicitOutputPath(outPath, config.Resolve[string](settings.General.DefaultDownloadDir))
+
+	// With sums, IDs are chosen up front so the manifest knows the batch
+	// before any member can finish.
+	ids := make([]string, len(targets))
+	sumsDone := (<-chan struct{})(nil)
+	if sums {
+		for i := range ids {
+			ids[i] = uuid.New().String()
+		}
+		sumsDone = lifecycle.TrackSums(ids)
+	}
+
+	// Probing runs concurrently so slow servers do not hold up the rest;
+	// the pool then caps how many transfer at once.
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		url, mirrors := target.url, target.mirrors
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := &processing.DownloadRequest{
+				URL:                url,
+				Path:               outPath,
+				Mirrors:            mirrors,
+				Headers:            headers,
+				IsExplicitCategory: isExplicit,
+				SkipApproval:       true,
+				TLS:                tlsOpts,
+				Request:            request,
+			}
+			var id string
+			var err error
+			if sums {
+				id, _, err = lifecycle.EnqueueWithID(currentEnqueueContext(), req, ids[i])
+			} else {
+				id, _, err = lifecycle.Enqueue(currentEnqueueContext(), req)
+			}
+			if err != nil {
+				fmt.Println(addFailedMessage(url, err))
+				if sums {
+					lifecycle.UntrackSums(ids[i])
+				}
+				tracker.rejected()
+				return
+			}
+			atomic.AddInt32(&activeDownloads, 1)
+			tracker.accepted(id)
+		}()
+	}
+	wg.Wait()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	select {
+	case <-tracker.done:
+	case sig := <-sigChan:
+		fmt.Printf("\nReceived %s. Pausing downloads...\n", sig)
+		_ = executeGlobalShutdown(fmt.Sprintf("get signal: %s", sig))
+		return fmt.Errorf("interrupted; resume with 'surge'")
+	}
+
+	if sumsDone != nil {
+		<-sumsDone
+	}
+
+	failed, total := tracker.failures(), tracker.total()
+	if failed > 0 {
+		return fmt.Errorf("%d of %d downloads failed", failed, total)
+	}
+	fmt.Printf("All %d downloads completed.\n", total)
+	return nil
+}
+
+// getTracker waits for a known set of downloads to finish. Completion events
+// can arrive before Enqueue hands back the ID, so outcomes are remembered for
+// IDs that are not accepted yet.
+type getTracker struct {
+	mu       sync.Mutex
+	expected int
+	waiting  map[string]bool
+	outcomes map[string]bool // id -> succeeded
+	finished int
+	failed   int
+	done     chan struct{}
+	closed   bool
+}
+
+func newGetTracker() *getTracker {
+	return &getTracker{
+		waiting:  make(map[string]bool),
+		outcomes: make(map[string]bool),
+		done:     make(chan struct{}),
+	}
+}
+
+// expect sets how many downloads are about to be enqueued.
+func (t *getTracker) expect(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.expected = n
+}
+
+// rejected records a download that could not be enqueued.
+func (t *getTracker) rejected() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.finishLocked(false)
+}
+
+// accepted records the ID of an enqueued download.
+func (t *getTracker) accepted(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if ok, seen := t.outcomes[id]; seen {
+		t.finishLocked(ok)
+		return
+	}
+	t.waiting[id] = true
+}
+
+// observe is fed every event from the service.
+func (t *getTracker) observe(msg interface{}) {
+	var id string
+	var ok bool
+	switch m := msg.(type) {
+	case events.DownloadCompleteMsg:
+		id, ok = m.DownloadID, true
+	case events.DownloadErrorMsg:
+		id = m.DownloadID
+	case events.DownloadRemovedMsg:
+		id = m.DownloadID
+	default:
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.waiting[id] {
+		delete(t.waiting, id)
+		t.finishLocked(ok)
+		return
+	}
+	if _, seen := t.outcomes[id]; !seen {
+		t.outcomes[id] = ok
+	}
+}
+
+func (t *getTracker) finishLocked(ok bool) {
+	t.finished++
+	if !ok {
+		t.failed++
+	}
+	if t.finished == t.expected && !t.closed {
+		t.closed = true
+		close(t.done)
+	}
+}
+
+func (t *getTracker) total() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.expected
+}
+
+func (t *getTracker) failures() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.failed
+}
+
+// runStreamGet writes rawurl in order to dest, while fetching the ranges
+// ahead over several connections. A dest of "-" is stdout, for piping into
+// another program; progress goes to stderr, so stdout carries nothing but
+// the file. Any other dest is a storage URI such as s3://bucket/key, which
+// gets the file without it touching the local disk.
+func runStreamGet(rawurl, dest string, tlsOpts types.TLSOptions, request types.RequestOptions, showProgress bool) error {
+	var headers map[string]string
+	if webdav.IsURL(rawurl) {
+		var err error
+		if rawurl, headers, err = webdav.HTTPURL(rawurl); err != nil {
+			return err
+		}
+	}
+	runtime := getSettings().ToRuntimeConfig()
+	runtime.TLS = runtime.TLS.Merge(tlsOpts)
+	runtime = request.Timeouts.Apply(runtime)
+	runtime = types.BindRuntime(runtime, request.Interface, rawurl)
+	transport, err := engine.DefaultNetworkPool.AcquireTransportFor(runtime, types.PoolMaxConnsPerHost)
+	if err != nil {
+		return fmt.Errorf("failed to configure TLS: %w", err)
+	}
+	defer engine.DefaultNetworkPool.ReleaseTransport(transport)
+
+	conns := runtime.GetMaxConnectionsPerDownload()
+	if request.Connections > 0 {
+		conns = min(conns, request.Connections)
+	}
+	d := &stream.Downloader{
+		Client:      &http.Client{Transport: transport, CheckRedirect: engine.RedirectPolicy(runtime, nil)},
+		Request:     request,
+		Headers:     headers,
+		UserAgent:   runtime.GetUserAgent(),
+		Connections: conns,
+		MaxRetries:  runtime.GetMaxTaskRetries(),
+	}
+
+	name := path.Base(rawurl)
+	if u, err := url.Parse(rawurl); err == nil && path.Base(u.Path) != "/" && path.Base(u.Path) != "." {
+		name = path.Base(u.Path)
+	}
+	progress := newHeadlessProgressIfTerminal(showProgress)
+	progress.start(name, name, 0)
+	start := time.Now()
+	d.Progress = func(written, total int64) {
+		progress.update(events.ProgressMsg{
+			DownloadID:        name,
+			Downloaded:        written,
+			Total:             total,
+			Speed:             float64(written) / max(time.Since(start).Seconds(), 0.001),
+			ActiveConnections: conns,
+		})
+	}
+
+	var sum *engine.ChecksumWriter
+	if request.Checksum != "" {
+		if sum, err = engine.NewChecksumWriter(request.Checksum); err != nil {
+			return err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if maxDuration := runtime.GetMaxDuration(); maxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, maxDuration, types.ErrMaxDuration)
+		defer cancel()
+	}
+
+	var obj storage.Object
+	var out io.Writer = os.Stdout
+	if dest != "-" {
+		// The upload shares the download's transport, so TLS and interface
+		// settings apply to both ends.
+		if obj, err = storage.Create(ctx, dest, &http.Client{Transport: transport}); err != nil {
+			progress.clear()
+			return fmt.Errorf("failed to open %s: %w", dest, err)
+		}
+		out = obj
+	}
+	if sum != nil {
+		out = io.MultiWriter(out, sum)
+	}
+
+	_, err = d.Download(ctx, rawurl, out)
+	progress.clear()
+	if err == nil && sum != nil {
+		err = sum.Verify()
+	}
+	if obj != nil {
+		// Storage only ever holds a complete, verified file
+		if err != nil {
+			_ = obj.Abort()
+		} else if err = obj.Commit(); err != nil {
+			return fmt.Errorf("failed to store %s: %w", dest, err)
+		}
+	}
+	if err != nil && errors.Is(context.Cause(ctx), types.ErrMaxDuration) {
+		return types.ErrMaxDuration
+	}
+	// On stdout the bytes are already out, but the exit status tells the
+	// pipeline they were not what was expected.
+	return err
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/SurgeDM/Surge/internal/core"
 	"github.com/SurgeDM/Surge/internal/download"
+	"github.com/SurgeDM/Surge/internal/engine/events"
 	"github.com/SurgeDM/Surge/internal/engine/state"
 	"github.com/SurgeDM/Surge/internal/engine/types"
 	"github.com/SurgeDM/Surge/internal/processing"
@@ -160,3 +162,30 @@ func TestCLI_DeleteEndpoint_CleansPausedStateAndPartialFile(t *testing.T) {
 		}
 	}
 }
+func TestGetTracker_CountsFailuresAndEarlyEvents(t *testing.T) {
+	tracker := newGetTracker()
+	tracker.expect(3)
+
+	// "a" finishes before Enqueue has handed back its ID.
+	tracker.observe(events.DownloadCompleteMsg{DownloadID: "a"})
+	tracker.accepted("a")
+	tracker.accepted("b")
+	tracker.rejected()
+	tracker.observe(events.DownloadCompleteMsg{DownloadID: "unrelated"})
+
+	select {
+	case <-tracker.done:
+		t.Fatal("tracker finished while b is still running")
+	default:
+	}
+
+	tracker.observe(events.DownloadErrorMsg{DownloadID: "b", Err: errors.New("boom")})
+	select {
+	case <-tracker.done:
+	default:
+		t.Fatal("tracker did not finish after every download ended")
+	}
+	if got := tracker.failures(); got != 2 {
+		t.Fatalf("failures() = %d, want 2", got)
+	}
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/ghrelease"
+	"github.com/SurgeDM/Surge/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var ghCmd = &cobra.Command{
+	Use:   "gh <owner/repo[@tag]>",
+	Short: "Queue assets of a GitHub release",
+	Long: `Look up a GitHub release, the latest one or the one tagged @tag, and queue
+the assets whose names match --asset. When the release publishes a
+checksums.txt, SHA256SUMS or <asset>.sha256 file, each download is checked
+against it.
+
+The token, from --token, GITHUB_TOKEN or GH_TOKEN, raises the API rate
+limit; it is not sent with the downloads.`,
+	Example: `  surge gh cli/cli --asset '*linux_amd64.tar.gz'
+  surge gh SurgeDM/Surge@v1.2.0 --asset '*windows*' --asset checksums.txt`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		patterns, _ := cmd.Flags().GetStringArray("asset")
+		list, _ := cmd.Flags().GetBool("list")
+		output, _ := cmd.Flags().GetString("output")
+		token, _ := cmd.Flags().GetString("token")
+		if err := config.ValidatePathTemplate(output); err != nil {
+			return err
+		}
+
+		owner, repo, tag, err := ghrelease.ParseRepoRef(args[0])
+		if err != nil {
+			return err
+		}
+		if token == "" {
+			token = os.Getenv("GITHUB_TOKEN")
+		}
+		if token == "" {
+			token = os.Getenv("GH_TOKEN")
+		}
+
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		client := &ghrelease.Client{Token: token}
+		release, err := client.Release(ctx, owner, repo, tag)
+		if err != nil {
+			return fmt.Errorf("%s/%s: %w", owner, repo, err)
+		}
+
+		if list || len(patterns) == 0 {
+			printReleaseAssets(release)
+			if !list {
+				return fmt.Errorf("pass --asset with a pattern to choose what to download")
+			}
+			return nil
+		}
+
+		assets, err := release.Match(patterns)
+		if err != nil {
+			return err
+		}
+		if len(assets) == 0 {
+			printReleaseAssets(release)
+			return fmt.Errorf("no asset of %s matches %s", release.TagName, strings.Join(patterns, ", "))
+		}
+
+		if err := initializeGlobalState(); err != nil {
+			return err
+		}
+		baseURL, apiToken, err := resolveAPIConnection(true)
+		if err != nil {
+			return err
+		}
+		resolvedOutput := resolveClientOutputPath(output)
+
+		count := 0
+		for _, asset := range assets {
+			request := types.RequestOptions{}
+			if sumFile, ok := release.ChecksumAsset(asset); ok && sumFile.Name != asset.Name {
+				sum, err := client.Checksum(ctx, sumFile, asset.Name)
+				if err != nil {
+					fmt.Printf("Queuing %s without a checksum: %v\n", asset.Name, err)
+				} else {
+					request.Checksum = sum
+				}
+			}
+			if err := sendToServerWithApproval(asset.URL, nil, resolvedOutput, baseURL, apiToken, true, types.TLSOptions{}, request); err != nil {
+				fmt.Printf("Failed to add %s: %v\n", asset.Name, err)
+				continue
+			}
+			verified := ""
+			if request.Checksum != "" {
+				verified = " (checksum verified)"
+			}
+			fmt.Printf("Queued %s %s%s\n", release.TagName, asset.Name, verified)
+			count++
+		}
+		if count == 0 {
+			return fmt.Errorf("failed to add any downloads")
+		}
+		return nil
+	},
+}
+
+func printReleaseAssets(release *ghrelease.Release) {
+	fmt.Printf("%s (%s)\n", release.TagName, release.Name)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, a := range release.Assets {
+		_, _ = fmt.Fprintf(w, "  %s\t%s\n", a.Name, utils.ConvertBytesToHumanReadable(a.Size))
+	}
+	_ = w.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(ghCmd)
+	ghCmd.Flags().StringArray("asset", nil, "Glob pattern of asset names to queue, e.g. '*linux_amd64*'; repeat for several")
+	ghCmd.Flags().Bool("list", false, "Only list the release's assets")
+	ghCmd.Flags().StringP("output", "o", "", "Output directory, may use templates like {domain} (defaults to current working directory)")
+	ghCmd.Flags().String("token", "", "GitHub token for the API (default: $GITHUB_TOKEN or $GH_TOKEN)")
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/huggingface"
+	"github.com/SurgeDM/Surge/internal/utils"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+var hfCmd = &cobra.Command{
+	Use:   "hf <owner/name[@revision]>",
+	Short: "Queue the files of a Hugging Face repository",
+	Long: `Queue every file of a Hugging Face Hub model, or the ones matching
+--include, into <output>/<name>/ with the repository's folder layout. Use a
+datasets/ or spaces/ prefix for other repository kinds.
+
+The revision (main by default) is resolved to a commit first, so all shards
+come from the same snapshot. Files stored in LFS are checked against the
+SHA-256 the Hub lists for them. Running the command again for the same
+commit does not queue anything twice; it resumes what is paused, which
+makes it safe to repeat after a restart.
+
+The token, from --token, HF_TOKEN or HUGGING_FACE_HUB_TOKEN, is needed for
+private and gated repositories and is sent with every download.`,
+	Example: `  surge hf Qwen/Qwen2.5-7B-Instruct --include '*.safetensors' --include '*.json'
+  surge hf meta-llama/Llama-3.1-8B@main --exclude 'original/*'
+  surge hf datasets/openai/gsm8k --list`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		include, _ := cmd.Flags().GetStringArray("include")
+		exclude, _ := cmd.Flags().GetStringArray("exclude")
+		list, _ := cmd.Flags().GetBool("list")
+		output, _ := cmd.Flags().GetString("output")
+		token, _ := cmd.Flags().GetString("token")
+
+		repo, err := huggingface.ParseRepoRef(args[0])
+		if err != nil {
+			return err
+		}
+		for _, name := range []string{"HF_TOKEN", "HUGGING_FACE_HUB_TOKEN"} {
+			if token == "" {
+				token = os.Getenv(name)
+			}
+		}
+
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		client := &huggingface.Client{Token: token}
+		snapshot, err := client.Snapshot(ctx, repo)
+		if err != nil {
+			return fmt.Errorf("%s@%s: %w", repo.ID, repo.Revision, err)
+		}
+		files, err := huggingface.Filter(snapshot.Files, include, exclude)
+		if err != nil {
+			return err
+		}
+
+		if list {
+			printRepoFiles(repo, snapshot.Commit, files)
+			return nil
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("no file of %s matches the filters", repo.ID)
+		}
+
+		if err := initializeGlobalState(); err != nil {
+			return err
+		}
+		baseURL, apiToken, err := resolveAPIConnection(true)
+		if err != nil {
+			return err
+		}
+		root := filepath.Join(resolveClientOutputPath(output), repo.Name())
+
+		var queued, existing, failed int
+		for _, f := range files {
+			if f.Path == "" || strings.Contains(f.Path, "..") {
+				fmt.Printf("Skipping %q: unsafe path\n", f.Path)
+				continue
+			}
+
+			fileURL := client.FileURL(repo, snapshot.Commit, f.Path)
+			req := DownloadRequest{
+				// The same file of the same commit always gets the same ID,
+				// so a repeated run finds the download it queued before.
+				ID:           uuid.NewSHA1(uuid.NameSpaceURL, []byte(fileURL)).String(),
+				URL:          fileURL,
+				Filename:     path.Base(f.Path),
+				Path:         filepath.Join(root, filepath.FromSlash(path.Dir(f.Path))),
+				SkipApproval: true,
+				Headers:      client.AuthHeaders(),
+			}
+			if f.SHA256 != "" {
+				req.RequestOptions = types.RequestOptions{Checksum: "sha256:" + f.SHA256}
+			}
+
+			replayed, err := postDownloadRequest(baseURL, apiToken, req)
+			if err != nil {
+				fmt.Printf("Failed to add %s: %v\n", f.Path, err)
+				failed++
+				continue
+			}
+			if replayed {
+				resumeExistingDownload(baseURL, apiToken, req.ID)
+				existing++
+				continue
+			}
+			queued++
+		}
+
+		fmt.Printf("%s@%s: queued %d file(s)", repo.ID, snapshot.Commit[:min(len(snapshot.Commit), 12)], queued)
+		if existing > 0 {
+			fmt.Printf(", %d already queued or downloaded", existing)
+		}
+		fmt.Println()
+		if failed > 0 {
+			return fmt.Errorf("failed to add %d file(s)", failed)
+		}
+		return nil
+	},
+}
+
+// resumeExistingDownload asks the server to resume id, which is a no-op for
+// downloads that are running or finished.
+func resumeExistingDownload(baseURL, token, id string) {
+	resp, err := doAPIRequest(http.MethodPost, baseURL, token, "/resume?id="+url.QueryEscape(id), nil)
+	if err != nil {
+		utils.Debug("Resume %s: %v", id, err)
+		return
+	}
+	_ = resp.Body.Close()
+}
+
+func printRepoFiles(repo huggingface.Repo, commit string, files []huggingface.File) {
+	fmt.Printf("%s@%s (%s)\n", repo.ID, repo.Revision, commit)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	var total int64
+	for _, f := range files {
+		verified := ""
+		if f.SHA256 != "" {
+			verified = "sha256"
+		}
+		_, _ = fmt.Fprintf(w, "  %s\t%s\t%s\n", f.Path, utils.ConvertBytesToHumanReadable(f.Size), verified)
+		total += f.Size
+	}
+	_ = w.Flush()
+	fmt.Printf("%d file(s), %s\n", len(files), utils.ConvertBytesToHumanReadable(total))
+}
+
+func init() {
+	rootCmd.AddCommand(hfCmd)
+	hfCmd.Flags().StringArray("include", nil, "Glob of files to queue, e.g. '*.safetensors'; repeat for several (default: all)")
+	hfCmd.Flags().StringArray("exclude", nil, "Glob of files to skip; repeat for several")
+	hfCmd.Flags().Bool("list", false, "Only list the matching files")
+	hfCmd.Flags().StringP("output", "o", "", "Directory to create the repository folder in (defaults to current working directory)")
+	hfCmd.Flags().String("token", "", "Hugging Face access token (default: $HF_TOKEN or $HUGGING_FACE_HUB_TOKEN)")
+}
@@ -1,19 +1,25 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/SurgeDM/Surge/internal/config"
 	"github.com/SurgeDM/Surge/internal/core"
 	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/state"
 	"github.com/SurgeDM/Surge/internal/engine/types"
 	"github.com/SurgeDM/Surge/internal/utils"
 )
@@ -29,15 +35,64 @@ type rateLimitSettingsService interface {
 	SetDefaultRateLimit(rate int64) error
 }
 
+type concurrencySettingsService interface {
+	SetConcurrencyLimits(global, perHost, perCategory int) error
+}
+
+type settingsReloader interface {
+	ReloadSettings() error
+}
+
+type tagService interface {
+	SetTags(id string, tags []string) error
+}
+
+type headerService interface {
+	SetHeaders(id string, headers map[string]string) error
+}
+
+type logService interface {
+	Logs(id string) ([]utils.LogLine, error)
+}
+
+type snoozeService interface {
+	Snooze(id string, until time.Time) error
+}
+
+type drainService interface {
+	SetDraining(on bool) error
+	Draining() bool
+}
+
+type globalPauseService interface {
+	PauseAll() ([]string, error)
+	ResumeAll() ([]string, error)
+}
+
+type speedHistoryService interface {
+	SpeedHistory() ([]state.SpeedSample, error)
+}
+
+type failedService interface {
+	Failed() ([]types.DownloadStatus, error)
+	RetryFailed(ids []string) ([]string, error)
+}
+
 func registerHTTPRoutes(mux *http.ServeMux, port int, defaultOutputDir string, service core.DownloadService) {
 	mux.HandleFunc("/health", func(w http.ResponseWriter, _ *http.Request) {
-		writeJSONResponse(w, http.StatusOK, map[string]interface{}{
-			"status": "ok",
-			"port":   port,
-		})
+		health := map[string]interface{}{
+			"status":      "ok",
+			"port":        port,
+			"version":     Version,
+			"api_version": core.APIVersion,
+		}
+		if drainer, ok := service.(drainService); ok {
+			health["draining"] = drainer.Draining()
+		}
+		writeJSONResponse(w, http.StatusOK, health)
 	})
 
-	mux.HandleFunc("/events", eventsHandler(service))
+	mux.HandleFunc("/events", eventsHandler(newEventFeed(service)))
 
 	mux.HandleFunc("/download", func(w http.ResponseWriter, r *http.Request) {
 		handleDownload(w, r, defaultOutputDir, service)
@@ -63,6 +118,114 @@ func registerHTTPRoutes(mux *http.ServeMux, port int, defaultOutputDir string, s
 		writeJSONResponse(w, http.StatusOK, map[string]string{"status": "resumed", "id": id})
 	})))
 
+	mux.HandleFunc("/snooze", requireMethod(http.MethodPost, withRequiredID(func(w http.ResponseWriter, r *http.Request, id string) {
+		snoozer, ok := service.(snoozeService)
+		if !ok {
+			http.Error(w, "Service does not support snoozing downloads", http.StatusNotImplemented)
+			return
+		}
+		until, err := utils.ParseWakeTime(r.URL.Query().Get("until"), time.Now())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := snoozer.Snooze(id, until); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, map[string]string{"status": "snoozed", "id": id, "until": until.Format(time.RFC3339)})
+	})))
+
+	mux.HandleFunc("/pause-all", requireMethod(http.MethodPost, func(w http.ResponseWriter, _ *http.Request) {
+		pauser, ok := service.(globalPauseService)
+		if !ok {
+			http.Error(w, "Service does not support pausing all downloads", http.StatusNotImplemented)
+			return
+		}
+		ids, err := pauser.PauseAll()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, map[string]interface{}{"status": "paused", "ids": nonNilIDs(ids)})
+	}))
+
+	mux.HandleFunc("/resume-all", requireMethod(http.MethodPost, func(w http.ResponseWriter, _ *http.Request) {
+		pauser, ok := service.(globalPauseService)
+		if !ok {
+			http.Error(w, "Service does not support resuming all downloads", http.StatusNotImplemented)
+			return
+		}
+		ids, err := pauser.ResumeAll()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, map[string]interface{}{"status": "resumed", "ids": nonNilIDs(ids)})
+	}))
+
+	mux.HandleFunc("/failed", requireMethod(http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		failer, ok := service.(failedService)
+		if !ok {
+			http.Error(w, "Service does not support listing failed downloads", http.StatusNotImplemented)
+			return
+		}
+		failed, err := failer.Failed()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if failed == nil {
+			failed = []types.DownloadStatus{}
+		}
+		writeJSONResponse(w, http.StatusOK, failed)
+	}))
+
+	mux.HandleFunc("/speed-history", requireMethod(http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		historian, ok := service.(speedHistoryService)
+		if !ok {
+			http.Error(w, "Service does not support speed history", http.StatusNotImplemented)
+			return
+		}
+		samples, err := historian.SpeedHistory()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if samples == nil {
+			samples = []state.SpeedSample{}
+		}
+		writeJSONResponse(w, http.StatusOK, samples)
+	}))
+
+	mux.HandleFunc("/retry-failed", requireMethod(http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
+		failer, ok := service.(failedService)
+		if !ok {
+			http.Error(w, "Service does not support retrying failed downloads", http.StatusNotImplemented)
+			return
+		}
+		// Without an id, every failed download is retried
+		ids, err := failer.RetryFailed(r.URL.Query()["id"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, map[string]interface{}{"status": "retried", "ids": nonNilIDs(ids)})
+	}))
+
+	mux.HandleFunc("/drain", requireMethods(func(w http.ResponseWriter, r *http.Request) {
+		drainer, ok := service.(drainService)
+		if !ok {
+			http.Error(w, "Service does not support draining", http.StatusNotImplemented)
+			return
+		}
+		if err := drainer.SetDraining(r.Method == http.MethodPost); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, map[string]bool{"draining": drainer.Draining()})
+	}, http.MethodPost, http.MethodDelete))
+
 	mux.HandleFunc("/delete", requireMethods(withRequiredID(func(w http.ResponseWriter, _ *http.Request, id string) {
 		if err := service.Delete(id); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -79,15 +242,57 @@ func registerHTTPRoutes(mux *http.ServeMux, port int, defaultOutputDir string, s
 		writeJSONResponse(w, http.StatusOK, map[string]string{"status": "purged", "id": id})
 	}), http.MethodDelete, http.MethodPost))
 
-	mux.HandleFunc("/list", requireMethod(http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+	mux.HandleFunc("/list", requireMethod(http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
 		statuses, err := service.List()
 		if err != nil {
 			http.Error(w, "Failed to list downloads: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
+		if tag := r.URL.Query().Get("tag"); tag != "" {
+			statuses = slices.DeleteFunc(statuses, func(st types.DownloadStatus) bool {
+				return !types.HasTag(st.Tags, tag)
+			})
+		}
+		if code := r.URL.Query().Get("error_code"); code != "" {
+			statuses = slices.DeleteFunc(statuses, func(st types.DownloadStatus) bool {
+				return st.ErrorCode != types.ErrorCode(code)
+			})
+		}
+		// Response headers are for debugging a server, so only sent on request
+		if verbose, _ := strconv.ParseBool(r.URL.Query().Get("verbose")); !verbose {
+			statuses = slices.Clone(statuses)
+			for i := range statuses {
+				statuses[i].ResponseHeaders = nil
+			}
+		}
 		writeJSONResponse(w, http.StatusOK, statuses)
 	}))
 
+	mux.HandleFunc("/file", requireMethods(handleDownloadFile(service), http.MethodGet, http.MethodHead))
+
+	mux.HandleFunc(filesPrefix, requireMethods(handleServedFiles(defaultOutputDir, core.NewAuditLog(auditLogPath())), http.MethodGet, http.MethodHead))
+
+	mux.HandleFunc("/logs", requireMethod(http.MethodGet, withRequiredID(func(w http.ResponseWriter, _ *http.Request, id string) {
+		logger, ok := service.(logService)
+		if !ok {
+			http.Error(w, "Service does not support download logs", http.StatusNotImplemented)
+			return
+		}
+		lines, err := logger.Logs(id)
+		if err != nil {
+			if errors.Is(err, types.ErrNotFound) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if lines == nil {
+			lines = []utils.LogLine{}
+		}
+		writeJSONResponse(w, http.StatusOK, lines)
+	})))
+
 	mux.HandleFunc("/history", requireMethod(http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
 		history, err := service.History()
 		if err != nil {
@@ -103,6 +308,24 @@ func registerHTTPRoutes(mux *http.ServeMux, port int, defaultOutputDir string, s
 		writeJSONResponse(w, http.StatusOK, history)
 	}))
 
+	mux.HandleFunc("/search", requireMethod(http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		if query == "" {
+			http.Error(w, "Missing search query", http.StatusBadRequest)
+			return
+		}
+		statuses, err := service.List()
+		if err != nil {
+			http.Error(w, "Failed to list downloads: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var categories []config.Category
+		if settings := getSettings(); config.Resolve[bool](settings.Categories.CategoryEnabled) {
+			categories = settings.Categories.Categories
+		}
+		writeJSONResponse(w, http.StatusOK, core.Search(statuses, query, categories))
+	}))
+
 	mux.HandleFunc("/open-file", requireMethod(http.MethodPost, withRequiredID(func(w http.ResponseWriter, r *http.Request, id string) {
 		if err := ensureOpenActionRequestAllowed(r); err != nil {
 			http.Error(w, err.Error(), http.StatusForbidden)
@@ -164,6 +387,66 @@ func registerHTTPRoutes(mux *http.ServeMux, port int, defaultOutputDir string, s
 		writeJSONResponse(w, http.StatusOK, map[string]string{"status": "updated", "id": id, "url": newURL})
 	})))
 
+	mux.HandleFunc("/tags", requireMethod(http.MethodPut, withRequiredID(func(w http.ResponseWriter, r *http.Request, id string) {
+		tagger, ok := service.(tagService)
+		if !ok {
+			http.Error(w, "Service does not support tags", http.StatusNotImplemented)
+			return
+		}
+		var req struct {
+			Tags []string `json:"tags"`
+		}
+		if err := decodeJSONBody(r, &req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		tags, err := types.NormalizeTags(req.Tags)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := tagger.SetTags(id, tags); err != nil {
+			if errors.Is(err, types.ErrNotFound) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, map[string]any{"status": "updated", "id": id, "tags": tags})
+	})))
+
+	mux.HandleFunc("/headers", requireMethod(http.MethodPut, withRequiredID(func(w http.ResponseWriter, r *http.Request, id string) {
+		setter, ok := service.(headerService)
+		if !ok {
+			http.Error(w, "Service does not support updating headers", http.StatusNotImplemented)
+			return
+		}
+		var req struct {
+			Headers map[string]string `json:"headers"`
+		}
+		if err := decodeJSONBody(r, &req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if len(req.Headers) == 0 {
+			http.Error(w, "Missing headers in body", http.StatusBadRequest)
+			return
+		}
+		if err := setter.SetHeaders(id, req.Headers); err != nil {
+			switch {
+			case errors.Is(err, types.ErrNotFound):
+				http.Error(w, err.Error(), http.StatusNotFound)
+			case errors.Is(err, types.ErrActiveHeaderUpdate):
+				http.Error(w, err.Error(), http.StatusConflict)
+			default:
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, map[string]string{"status": "updated", "id": id})
+	})))
+
 	mux.HandleFunc("/rate-limit", requireMethod(http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
 		id := r.URL.Query().Get("id")
 		if id == "" {
@@ -235,6 +518,49 @@ func registerHTTPRoutes(mux *http.ServeMux, port int, defaultOutputDir string, s
 		}
 		writeJSONResponse(w, http.StatusOK, map[string]string{"status": status, "rate": rateStr})
 	}))
+
+	mux.HandleFunc("/concurrency", requireMethod(http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
+		limiter, ok := service.(concurrencySettingsService)
+		if !ok {
+			http.Error(w, "Service does not support concurrency limits", http.StatusNotImplemented)
+			return
+		}
+		caps := map[string]int{"global": -1, "per_host": -1, "per_category": -1}
+		for key := range caps {
+			raw := r.URL.Query().Get(key)
+			if raw == "" {
+				continue
+			}
+			value, err := strconv.Atoi(raw)
+			if err != nil || value < 0 {
+				http.Error(w, fmt.Sprintf("Invalid %s: must be a non-negative integer", key), http.StatusBadRequest)
+				return
+			}
+			caps[key] = value
+		}
+		if err := limiter.SetConcurrencyLimits(caps["global"], caps["per_host"], caps["per_category"]); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, map[string]string{"status": "updated"})
+	}))
+
+	mux.HandleFunc("/feeds", handleFeeds)
+
+	mux.HandleFunc("/audit", requireMethod(http.MethodGet, handleAuditQuery(core.NewAuditLog(auditLogPath()))))
+
+	mux.HandleFunc("/reload", requireMethod(http.MethodPost, func(w http.ResponseWriter, _ *http.Request) {
+		if _, ok := service.(settingsReloader); !ok {
+			http.Error(w, "Service does not support reloading settings", http.StatusNotImplemented)
+			return
+		}
+		settings, err := reloadSettings(service)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, map[string]any{"status": "reloaded", "warnings": settings.StartupWarnings})
+	}))
 }
 
 func statusCodeForRateLimitError(err error) int {
@@ -274,49 +600,145 @@ func parseRateLimitQuery(w http.ResponseWriter, r *http.Request) (int64, string,
 	return rate, rateStr, true
 }
 
-func eventsHandler(service core.DownloadService) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "text/event-stream")
-		w.Header().Set("Cache-Control", "no-cache")
-		w.Header().Set("Connection", "keep-alive")
-		w.Header().Set("Access-Control-Allow-Origin", "*")
+// eventFeed fans one subscription to the service's events out to every SSE
+// client through a journal, so a client that reconnects with Last-Event-ID is
+// sent what it missed instead of starting from scratch.
+type eventFeed struct {
+	service core.DownloadService
+	journal *events.Journal
 
-		stream, cleanup, err := service.StreamEvents(r.Context())
-		if err != nil {
-			http.Error(w, "Failed to subscribe to events", http.StatusInternalServerError)
-			return
-		}
+	mu      sync.Mutex
+	running bool
+}
+
+func newEventFeed(service core.DownloadService) *eventFeed {
+	return &eventFeed{service: service, journal: events.NewJournal(events.DefaultJournalSize)}
+}
+
+// ensureRunning subscribes to the service unless the feed already is. The
+// subscription outlives the clients, so events keep being journaled while
+// nobody is connected.
+func (f *eventFeed) ensureRunning() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.running {
+		return nil
+	}
+
+	stream, cleanup, err := f.service.StreamEvents(context.Background())
+	if err != nil {
+		return err
+	}
+	f.running = true
+	go func() {
 		defer cleanup()
+		for msg := range stream {
+			frames, err := events.EncodeSSEMessages(msg)
+			if err != nil {
+				utils.Debug("Error encoding SSE event: %v", err)
+				continue
+			}
+			f.journal.Append(frames...)
+		}
+		f.mu.Lock()
+		f.running = false
+		f.mu.Unlock()
+		f.journal.CloseSubscribers()
+	}()
+	return nil
+}
 
+func eventsHandler(feed *eventFeed) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
 		flusher, ok := w.(http.Flusher)
 		if !ok {
 			http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
 			return
 		}
+
+		query := r.URL.Query()
+		filter, err := events.ParseEventFilter(query.Get("types"), query.Get("id"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		interval, err := parseProgressInterval(query.Get("progress_interval"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Subscribe before starting the feed so the first events reach us.
+		sub, backlog, complete := feed.journal.Subscribe(r.Header.Get("Last-Event-ID"), filter)
+		defer sub.Close()
+		if err := feed.ensureRunning(); err != nil {
+			http.Error(w, "Failed to subscribe to events", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if !complete {
+			_, _ = fmt.Fprintf(w, "event: %s\ndata: {}\n\n", events.EventTypeResync)
+		}
+		for _, frame := range backlog {
+			writeSSEFrame(w, frame)
+		}
 		flusher.Flush()
 
+		// A client may ask for progress at most once per interval; state
+		// changes still go out as they happen.
+		var coalescer *events.ProgressCoalescer
+		var tick <-chan time.Time
+		if interval > 0 {
+			coalescer = events.NewProgressCoalescer()
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+		send := func(frame events.SSEMessage) {
+			if coalescer == nil {
+				writeSSEFrame(w, frame)
+				return
+			}
+			for _, f := range coalescer.Add(frame) {
+				writeSSEFrame(w, f)
+			}
+		}
+
 		done := r.Context().Done()
 		for {
 			select {
 			case <-done:
 				return
-			case msg, ok := <-stream:
-				if !ok {
-					return
+			case <-tick:
+				frames := coalescer.Flush()
+				for _, frame := range frames {
+					writeSSEFrame(w, frame)
 				}
-
-				frames, err := events.EncodeSSEMessages(msg)
-				if err != nil {
-					utils.Debug("Error encoding SSE event: %v", err)
-					continue
+				if len(frames) > 0 {
+					flusher.Flush()
 				}
-				if len(frames) == 0 {
-					continue
+			case frame, ok := <-sub.C:
+				if !ok {
+					return
 				}
-
-				for _, frame := range frames {
-					_, _ = fmt.Fprintf(w, "event: %s\n", frame.Event)
-					_, _ = fmt.Fprintf(w, "data: %s\n\n", frame.Data)
+				send(frame)
+				// Send whatever else is already waiting in one flush.
+				for drained := false; !drained; {
+					select {
+					case frame, ok := <-sub.C:
+						if !ok {
+							flusher.Flush()
+							return
+						}
+						send(frame)
+					default:
+						drained = true
+					}
 				}
 				flusher.Flush()
 			}
@@ -324,6 +746,33 @@ func eventsHandler(service core.DownloadService) http.HandlerFunc {
 	}
 }
 
+// parseProgressInterval reads the progress_interval query parameter, either
+// a Go duration ("1s") or whole milliseconds ("250"). Zero means every
+// progress update is sent.
+func parseProgressInterval(raw string) (time.Duration, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+	value := raw
+	if _, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		value += "ms"
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval < 0 {
+		return 0, fmt.Errorf("invalid progress_interval %q", raw)
+	}
+	return interval, nil
+}
+
+func writeSSEFrame(w io.Writer, frame events.SSEMessage) {
+	if frame.ID != 0 {
+		_, _ = fmt.Fprintf(w, "id: %d\n", frame.ID)
+	}
+	_, _ = fmt.Fprintf(w, "event: %s\n", frame.Event)
+	_, _ = fmt.Fprintf(w, "data: %s\n\n", frame.Data)
+}
+
 func requireMethod(method string, next http.HandlerFunc) http.HandlerFunc {
 	return requireMethods(next, method)
 }
@@ -404,19 +853,26 @@ func statusCodeForResolveDownloadError(err error) int {
 	}
 }
 
-func ensureOpenActionRequestAllowed(r *http.Request) error {
+// isLocalRequest reports whether r comes straight from this machine, and not
+// through a proxy that forwards someone else's request.
+func isLocalRequest(r *http.Request) bool {
 	host, _, err := net.SplitHostPort(r.RemoteAddr)
 	if err != nil {
 		host = r.RemoteAddr
 	}
 
 	ip := net.ParseIP(host)
-	if ip != nil && ip.IsLoopback() {
-		xff := strings.TrimSpace(r.Header.Get("X-Forwarded-For"))
-		xri := strings.TrimSpace(r.Header.Get("X-Real-IP"))
-		if xff == "" && xri == "" {
-			return nil
-		}
+	if ip == nil || !ip.IsLoopback() {
+		return false
+	}
+	xff := strings.TrimSpace(r.Header.Get("X-Forwarded-For"))
+	xri := strings.TrimSpace(r.Header.Get("X-Real-IP"))
+	return xff == "" && xri == ""
+}
+
+func ensureOpenActionRequestAllowed(r *http.Request) error {
+	if isLocalRequest(r) {
+		return nil
 	}
 
 	settings := getSettings()
@@ -433,3 +889,11 @@ func decodeJSONBody(r *http.Request, dst interface{}) error {
 	}()
 	return json.NewDecoder(r.Body).Decode(dst)
 }
+
+// nonNilIDs keeps an empty ID list from being encoded as null.
+func nonNilIDs(ids []string) []string {
+	if ids == nil {
+		return []string{}
+	}
+	return ids
+}
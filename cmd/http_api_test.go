@@ -4,20 +4,28 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/SurgeDM/Surge/internal/config"
 	"github.com/SurgeDM/Surge/internal/core"
 	"github.com/SurgeDM/Surge/internal/engine/events"
 	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
 )
 
 type httpAPITestService struct {
+	statuses          []types.DownloadStatus
 	history           []types.DownloadEntry
 	historyErr        error
 	statusByID        map[string]*types.DownloadStatus
@@ -38,7 +46,7 @@ func newRateLimitTestService() *httpAPITestService {
 }
 
 func (s *httpAPITestService) List() ([]types.DownloadStatus, error) {
-	return nil, nil
+	return s.statuses, nil
 }
 
 func (s *httpAPITestService) History() ([]types.DownloadEntry, error) {
@@ -189,6 +197,42 @@ func TestEnsureOpenActionRequestAllowed_RemoteToggle(t *testing.T) {
 	}
 }
 
+func TestCheckTLSFiles_RemoteCallersLimitedToConfigDir(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := os.MkdirAll(config.GetSurgeDir(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	inside := filepath.Join(config.GetSurgeDir(), "ca.pem")
+	outside := filepath.Join(t.TempDir(), "id_rsa")
+	for _, path := range []string{inside, outside} {
+		if err := os.WriteFile(path, []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	remote := httptest.NewRequest(http.MethodPost, "/download", nil)
+	remote.RemoteAddr = "203.0.113.8:12345"
+	if err := checkTLSFiles(remote, types.TLSOptions{CAFile: inside}); err != nil {
+		t.Errorf("file in the config dir refused: %v", err)
+	}
+	if err := checkTLSFiles(remote, types.TLSOptions{ClientCertFile: inside, ClientKeyFile: outside}); err == nil {
+		t.Error("remote caller allowed to name a file outside the config dir")
+	}
+
+	proxied := httptest.NewRequest(http.MethodPost, "/download", nil)
+	proxied.RemoteAddr = "127.0.0.1:12345"
+	proxied.Header.Set("X-Forwarded-For", "203.0.113.8")
+	if err := checkTLSFiles(proxied, types.TLSOptions{CAFile: outside}); err == nil {
+		t.Error("proxied caller allowed to name a file outside the config dir")
+	}
+
+	local := httptest.NewRequest(http.MethodPost, "/download", nil)
+	local.RemoteAddr = "127.0.0.1:12345"
+	if err := checkTLSFiles(local, types.TLSOptions{CAFile: outside}); err != nil {
+		t.Errorf("local caller refused: %v", err)
+	}
+}
+
 func TestHistoryEndpoint_SortsMostRecentFirst(t *testing.T) {
 	service := &httpAPITestService{
 		history: []types.DownloadEntry{
@@ -223,6 +267,216 @@ func TestHistoryEndpoint_SortsMostRecentFirst(t *testing.T) {
 	}
 }
 
+func TestSearchEndpoint_RanksActiveAndHistory(t *testing.T) {
+	service := &httpAPITestService{
+		statuses: []types.DownloadStatus{
+			{ID: "done", Filename: "ubuntu-24.04.iso", URL: "https://example.com/ubuntu-24.04.iso", Status: "completed"},
+			{ID: "other", Filename: "debian.iso", URL: "https://example.com/debian.iso", Status: "downloading"},
+			{ID: "active", Filename: "ubuntu-24.04.iso", URL: "https://mirror.example.com/ubuntu-24.04.iso", Status: "downloading"},
+			{ID: "url-only", Filename: "notes.txt", URL: "https://example.com/ubuntu/notes.txt", Status: "paused"},
+		},
+	}
+
+	mux := http.NewServeMux()
+	registerHTTPRoutes(mux, 0, "", service)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/search?q=ubuntu", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+
+	var got []core.SearchResult
+	if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	var ids []string
+	for _, result := range got {
+		ids = append(ids, result.ID)
+	}
+	if want := []string{"active", "done", "url-only"}; !slices.Equal(ids, want) {
+		t.Fatalf("results = %v, want %v", ids, want)
+	}
+
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/search?q=", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for an empty query, got %d", recorder.Code)
+	}
+}
+
+type tagTestService struct {
+	*httpAPITestService
+	tagged map[string][]string
+}
+
+func (s *tagTestService) SetTags(id string, tags []string) error {
+	if id == "missing" {
+		return types.ErrNotFound
+	}
+	s.tagged[id] = tags
+	return nil
+}
+
+func TestListEndpoint_FiltersByTag(t *testing.T) {
+	service := &httpAPITestService{
+		statuses: []types.DownloadStatus{
+			{ID: "a", Tags: []string{"iso", "work"}},
+			{ID: "b", Tags: []string{"home"}},
+			{ID: "c"},
+		},
+	}
+	mux := http.NewServeMux()
+	registerHTTPRoutes(mux, 0, "", service)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/list?tag=Work", nil))
+	var got []types.DownloadStatus
+	if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != "a" {
+		t.Fatalf("/list?tag=Work = %+v, want only a", got)
+	}
+}
+
+func TestListEndpoint_ResponseHeadersOnlyWhenVerbose(t *testing.T) {
+	service := &httpAPITestService{
+		statuses: []types.DownloadStatus{
+			{ID: "a", ResponseHeaders: map[string]string{"Server": "nginx"}},
+		},
+	}
+	mux := http.NewServeMux()
+	registerHTTPRoutes(mux, 0, "", service)
+
+	for target, want := range map[string]string{"/list": "", "/list?verbose=1": "nginx"} {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		var got []types.DownloadStatus
+		if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+			t.Fatalf("failed to parse %s response: %v", target, err)
+		}
+		if len(got) != 1 || got[0].ResponseHeaders["Server"] != want {
+			t.Errorf("%s = %+v, want Server header %q", target, got, want)
+		}
+	}
+}
+
+func TestTagsEndpoint(t *testing.T) {
+	service := &tagTestService{httpAPITestService: &httpAPITestService{}, tagged: map[string][]string{}}
+	mux := http.NewServeMux()
+	registerHTTPRoutes(mux, 0, "", service)
+
+	put := func(id, body string) int {
+		req := httptest.NewRequest(http.MethodPut, "/tags?id="+id, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := put("a", `{"tags":["Work","iso,work"]}`); code != http.StatusOK {
+		t.Fatalf("PUT /tags status = %d, want 200", code)
+	}
+	if got := service.tagged["a"]; !slices.Equal(got, []string{"iso", "work"}) {
+		t.Fatalf("tags = %v, want normalized [iso work]", got)
+	}
+	if code := put("a", `{"tags":["two words"]}`); code != http.StatusBadRequest {
+		t.Fatalf("invalid tag status = %d, want 400", code)
+	}
+	if code := put("missing", `{"tags":[]}`); code != http.StatusNotFound {
+		t.Fatalf("missing download status = %d, want 404", code)
+	}
+}
+
+type headerTestService struct {
+	*httpAPITestService
+	headers map[string]map[string]string
+}
+
+func (s *headerTestService) SetHeaders(id string, headers map[string]string) error {
+	switch id {
+	case "missing":
+		return types.ErrNotFound
+	case "running":
+		return types.ErrActiveHeaderUpdate
+	}
+	s.headers[id] = headers
+	return nil
+}
+
+func TestHeadersEndpoint(t *testing.T) {
+	service := &headerTestService{httpAPITestService: &httpAPITestService{}, headers: map[string]map[string]string{}}
+	mux := http.NewServeMux()
+	registerHTTPRoutes(mux, 0, "", service)
+
+	put := func(id, body string) int {
+		req := httptest.NewRequest(http.MethodPut, "/headers?id="+id, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := put("a", `{"headers":{"Cookie":"session=new"}}`); code != http.StatusOK {
+		t.Fatalf("PUT /headers status = %d, want 200", code)
+	}
+	if got := service.headers["a"]["Cookie"]; got != "session=new" {
+		t.Fatalf("Cookie = %q, want session=new", got)
+	}
+	if code := put("a", `{"headers":{}}`); code != http.StatusBadRequest {
+		t.Fatalf("no headers status = %d, want 400", code)
+	}
+	if code := put("running", `{"headers":{"Cookie":"x"}}`); code != http.StatusConflict {
+		t.Fatalf("running download status = %d, want 409", code)
+	}
+	if code := put("missing", `{"headers":{"Cookie":"x"}}`); code != http.StatusNotFound {
+		t.Fatalf("missing download status = %d, want 404", code)
+	}
+}
+
+type logTestService struct {
+	*httpAPITestService
+}
+
+func (s *logTestService) Logs(id string) ([]utils.LogLine, error) {
+	switch id {
+	case "missing":
+		return nil, types.ErrNotFound
+	case "quiet":
+		return nil, nil
+	}
+	return []utils.LogLine{{Time: time.Unix(100, 0), Message: "Worker 0 started"}}, nil
+}
+
+func TestLogsEndpoint(t *testing.T) {
+	mux := http.NewServeMux()
+	registerHTTPRoutes(mux, 0, "", &logTestService{httpAPITestService: &httpAPITestService{}})
+
+	get := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/logs?id="+id, nil)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("a")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /logs status = %d, want 200", rec.Code)
+	}
+	var lines []utils.LogLine
+	if err := json.NewDecoder(rec.Body).Decode(&lines); err != nil {
+		t.Fatalf("failed to decode lines: %v", err)
+	}
+	if len(lines) != 1 || lines[0].Message != "Worker 0 started" {
+		t.Fatalf("lines = %+v, want the one logged line", lines)
+	}
+	if rec := get("quiet"); rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Fatalf("no lines = %d %q, want 200 []", rec.Code, rec.Body.String())
+	}
+	if rec := get("missing"); rec.Code != http.StatusNotFound {
+		t.Fatalf("missing download status = %d, want 404", rec.Code)
+	}
+}
+
 func TestEventsEndpoint_RequiresAuthAndStreamsSSE(t *testing.T) {
 	service := &httpAPITestService{
 		streamMsgs: []interface{}{
@@ -282,6 +536,38 @@ func TestEventsEndpoint_RequiresAuthAndStreamsSSE(t *testing.T) {
 	}
 }
 
+func TestEventsEndpoint_RejectsUnknownEventType(t *testing.T) {
+	mux := http.NewServeMux()
+	registerHTTPRoutes(mux, 0, "", &httpAPITestService{})
+
+	req := httptest.NewRequest(http.MethodGet, "/events?types=complete,finished", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestParseProgressInterval(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{raw: "", want: 0},
+		{raw: "1s", want: time.Second},
+		{raw: "250", want: 250 * time.Millisecond},
+		{raw: "-1s", wantErr: true},
+		{raw: "soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseProgressInterval(tt.raw)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseProgressInterval(%q) = %v, %v; want %v, error %v", tt.raw, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
 func TestHandleBatchDownload_ConfirmPublishesSingleBatchRequest(t *testing.T) {
 	previousProgram := serverProgram
 	serverProgram = &tea.Program{}
@@ -881,3 +1167,277 @@ func TestRateLimitDefaultEndpoint(t *testing.T) {
 		t.Fatalf("default rate = %d, want %d", got, 2097152)
 	}
 }
+
+// concurrencyTestService records the caps passed to SetConcurrencyLimits.
+type concurrencyTestService struct {
+	*httpAPITestService
+	caps []int
+}
+
+func (s *concurrencyTestService) SetConcurrencyLimits(global, perHost, perCategory int) error {
+	s.caps = []int{global, perHost, perCategory}
+	return nil
+}
+
+func TestConcurrencyEndpoint(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		path     string
+		wantCode int
+		wantCaps []int
+	}{
+		{
+			name:     "sets given caps and leaves the rest",
+			path:     "/concurrency?global=5&per_host=2",
+			wantCode: http.StatusOK,
+			wantCaps: []int{5, 2, -1},
+		},
+		{
+			name:     "zero clears a cap",
+			path:     "/concurrency?per_category=0",
+			wantCode: http.StatusOK,
+			wantCaps: []int{-1, -1, 0},
+		},
+		{
+			name:     "negative cap returns 400",
+			path:     "/concurrency?per_host=-1",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "non-numeric cap returns 400",
+			path:     "/concurrency?global=many",
+			wantCode: http.StatusBadRequest,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &concurrencyTestService{httpAPITestService: &httpAPITestService{}}
+			mux := http.NewServeMux()
+			registerHTTPRoutes(mux, 0, "", svc)
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			req.RemoteAddr = "127.0.0.1:12345"
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantCode, rec.Code, rec.Body.String())
+			}
+			if !slices.Equal(svc.caps, tt.wantCaps) {
+				t.Fatalf("caps = %v, want %v", svc.caps, tt.wantCaps)
+			}
+		})
+	}
+}
+
+// globalPauseTestService pauses a fixed set and resumes what it paused.
+type globalPauseTestService struct {
+	*httpAPITestService
+	paused []string
+}
+
+func (s *globalPauseTestService) PauseAll() ([]string, error) {
+	s.paused = []string{"a", "b"}
+	return s.paused, nil
+}
+
+func (s *globalPauseTestService) ResumeAll() ([]string, error) {
+	resumed := s.paused
+	s.paused = nil
+	return resumed, nil
+}
+
+func TestGlobalPauseEndpoints(t *testing.T) {
+	svc := &globalPauseTestService{httpAPITestService: &httpAPITestService{}}
+	mux := http.NewServeMux()
+	registerHTTPRoutes(mux, 0, "", svc)
+
+	post := func(path string) map[string]any {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.RemoteAddr = "127.0.0.1:12345"
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", path, rec.Code, rec.Body.String())
+		}
+		var body map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+
+	if body := post("/pause-all"); body["status"] != "paused" || fmt.Sprint(body["ids"]) != "[a b]" {
+		t.Fatalf("/pause-all = %v", body)
+	}
+	if body := post("/resume-all"); body["status"] != "resumed" || fmt.Sprint(body["ids"]) != "[a b]" {
+		t.Fatalf("/resume-all = %v", body)
+	}
+	if body := post("/resume-all"); fmt.Sprint(body["ids"]) != "[]" {
+		t.Fatalf("second /resume-all = %v, want no ids", body)
+	}
+}
+
+func TestGlobalPauseEndpoints_Unsupported(t *testing.T) {
+	mux := http.NewServeMux()
+	registerHTTPRoutes(mux, 0, "", &httpAPITestService{})
+
+	req := httptest.NewRequest(http.MethodPost, "/pause-all", nil)
+	req.RemoteAddr = "127.0.0.1:12345"
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotImplemented {
+		t.Fatalf("expected 501, got %d", rec.Code)
+	}
+}
+
+// drainTestService keeps drain mode in memory.
+type drainTestService struct {
+	*httpAPITestService
+	draining bool
+}
+
+func (s *drainTestService) SetDraining(on bool) error {
+	s.draining = on
+	return nil
+}
+
+func (s *drainTestService) Draining() bool {
+	return s.draining
+}
+
+func TestDrainEndpoint(t *testing.T) {
+	svc := &drainTestService{httpAPITestService: &httpAPITestService{}}
+	mux := http.NewServeMux()
+	registerHTTPRoutes(mux, 0, "", svc)
+
+	do := func(method, path string) map[string]any {
+		t.Helper()
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = "127.0.0.1:12345"
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s %s: expected 200, got %d: %s", method, path, rec.Code, rec.Body.String())
+		}
+		var body map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+
+	if body := do(http.MethodGet, "/health"); body["draining"] != false {
+		t.Fatalf("/health before drain = %v", body)
+	}
+	if body := do(http.MethodPost, "/drain"); body["draining"] != true || !svc.draining {
+		t.Fatalf("POST /drain = %v", body)
+	}
+	if body := do(http.MethodGet, "/health"); body["draining"] != true {
+		t.Fatalf("/health while draining = %v", body)
+	}
+	if body := do(http.MethodDelete, "/drain"); body["draining"] != false || svc.draining {
+		t.Fatalf("DELETE /drain = %v", body)
+	}
+}
+
+// snoozeTestService records Snooze calls.
+type snoozeTestService struct {
+	*httpAPITestService
+	id    string
+	until time.Time
+}
+
+func (s *snoozeTestService) Snooze(id string, until time.Time) error {
+	s.id, s.until = id, until
+	return nil
+}
+
+func TestSnoozeEndpoint(t *testing.T) {
+	svc := &snoozeTestService{httpAPITestService: &httpAPITestService{}}
+	mux := http.NewServeMux()
+	registerHTTPRoutes(mux, 0, "", svc)
+
+	post := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/snooze?"+query, nil)
+		req.RemoteAddr = "127.0.0.1:12345"
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	until := time.Now().Add(time.Hour).Truncate(time.Second)
+	rec := post("id=abc&until=" + url.QueryEscape(until.Format(time.RFC3339)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if svc.id != "abc" || !svc.until.Equal(until) {
+		t.Fatalf("Snooze(%q, %v), want abc until %v", svc.id, svc.until, until)
+	}
+
+	before := time.Now()
+	if rec := post("id=abc&until=2h"); rec.Code != http.StatusOK {
+		t.Fatalf("duration: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if d := svc.until.Sub(before); d < 2*time.Hour || d > 2*time.Hour+time.Minute {
+		t.Fatalf("until=2h snoozed for %v", d)
+	}
+
+	if rec := post("id=abc&until=whenever"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid time: expected 400, got %d", rec.Code)
+	}
+}
+
+// reloadTestService counts ReloadSettings calls.
+type reloadTestService struct {
+	*httpAPITestService
+	reloads int
+}
+
+func (s *reloadTestService) ReloadSettings() error {
+	s.reloads++
+	return nil
+}
+
+func TestSettingsReloadEndpoint(t *testing.T) {
+	setupXDGEnvIsolation(t)
+	if err := os.MkdirAll(config.GetSurgeDir(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(config.GetConfigFilePath(), []byte("[network]\nmax_redirects = \"x\"\nuser_agent = \"reloaded\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	original := globalSettings
+	t.Cleanup(func() { globalSettings = original })
+	globalSettings = config.DefaultSettings()
+
+	svc := &reloadTestService{httpAPITestService: &httpAPITestService{}}
+	mux := http.NewServeMux()
+	registerHTTPRoutes(mux, 0, "", svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/reload", nil)
+	req.RemoteAddr = "127.0.0.1:12345"
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || svc.reloads != 1 {
+		t.Fatalf("status %d, reloads %d; want 200 and one reload: %s", rec.Code, svc.reloads, rec.Body.String())
+	}
+	var body struct {
+		Warnings []string `json:"warnings"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || len(body.Warnings) != 1 {
+		t.Fatalf("response %s: want the invalid max_redirects reported", rec.Body.String())
+	}
+	if got := config.Resolve[string](getSettings().Network.UserAgent); got != "reloaded" {
+		t.Fatalf("user_agent after reload = %q, want the config.toml value", got)
+	}
+
+	mux = http.NewServeMux()
+	registerHTTPRoutes(mux, 0, "", &httpAPITestService{})
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/reload", nil))
+	if rec.Code != http.StatusNotImplemented {
+		t.Fatalf("service without ReloadSettings: status %d, want 501", rec.Code)
+	}
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/events"
+)
+
+// idempotencyWindow is how long a key keeps answering with the download it
+// created. Browser retries arrive within seconds; the window only has to
+// outlast them.
+const idempotencyWindow = 10 * time.Minute
+
+// errIdempotencyKeyReused is returned when a key comes back with another URL.
+var errIdempotencyKeyReused = errors.New("idempotency key was already used for a different URL")
+
+// idempotencyEntry is one key's download. done is closed once the first
+// request has an outcome, so a retry that arrives mid-probe waits for it
+// instead of queuing the URL again.
+type idempotencyEntry struct {
+	url      string
+	at       time.Time
+	done     chan struct{}
+	id       string
+	filename string
+	err      error
+}
+
+// idempotencyCache remembers recent POST /download keys.
+type idempotencyCache struct {
+	mu      sync.Mutex
+	entries map[string]*idempotencyEntry
+}
+
+func newIdempotencyCache() *idempotencyCache {
+	return &idempotencyCache{entries: make(map[string]*idempotencyEntry)}
+}
+
+var downloadIdempotency = newIdempotencyCache()
+
+// begin claims key for url. When owner is true the caller queues the download
+// and must report the outcome with finish; otherwise entry belongs to an
+// earlier request with the same key.
+func (c *idempotencyCache) begin(key, url string, now time.Time) (entry *idempotencyEntry, owner bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, e := range c.entries {
+		if now.Sub(e.at) > idempotencyWindow {
+			delete(c.entries, k)
+		}
+	}
+
+	if e, ok := c.entries[key]; ok {
+		if e.url != url {
+			return nil, false, errIdempotencyKeyReused
+		}
+		return e, false, nil
+	}
+	e := &idempotencyEntry{url: url, at: now, done: make(chan struct{})}
+	c.entries[key] = e
+	return e, true, nil
+}
+
+// finish records the outcome of the request that owns key. A failure frees
+// the key so the client's retry gets another attempt; a request waiting for
+// approval keeps it.
+func (c *idempotencyCache) finish(key string, e *idempotencyEntry, id, filename string, err error) {
+	c.mu.Lock()
+	e.id, e.filename, e.err = id, filename, err
+	if err != nil && !errors.Is(err, errDownloadPendingApproval) && c.entries[key] == e {
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+	close(e.done)
+}
+
+// decline frees the key of a request the TUI turned down, so its retry is
+// prompted for again.
+func (c *idempotencyCache) decline(approvalID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, e := range c.entries {
+		if e.id == approvalID && errors.Is(e.err, errDownloadPendingApproval) {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// watchDeclines frees the keys of requests declined on ch until it closes.
+func (c *idempotencyCache) watchDeclines(ch <-chan interface{}) {
+	for msg := range ch {
+		if m, ok := msg.(events.DownloadRequestDeclinedMsg); ok {
+			c.decline(m.ID)
+		}
+	}
+}
+
+// wait blocks until the owner of e has finished, then returns its outcome.
+func (e *idempotencyEntry) wait(ctx context.Context) (id, filename string, err error) {
+	select {
+	case <-e.done:
+		return e.id, e.filename, e.err
+	case <-ctx.Done():
+		return "", "", ctx.Err()
+	}
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/download"
+	"github.com/SurgeDM/Surge/internal/engine/events"
+)
+
+func TestIdempotencyCache(t *testing.T) {
+	c := newIdempotencyCache()
+	now := time.Now()
+
+	first, owner, err := c.begin("key", "https://example.com/a.zip", now)
+	if err != nil || !owner {
+		t.Fatalf("first begin = owner %v, err %v; want owner", owner, err)
+	}
+	if _, _, err := c.begin("key", "https://example.com/b.zip", now); !errors.Is(err, errIdempotencyKeyReused) {
+		t.Fatalf("begin with another URL = %v, want errIdempotencyKeyReused", err)
+	}
+
+	second, owner, err := c.begin("key", "https://example.com/a.zip", now)
+	if err != nil || owner || second != first {
+		t.Fatalf("second begin = owner %v, err %v; want the first entry", owner, err)
+	}
+	c.finish("key", first, "id-1", "a.zip", nil)
+	if id, filename, err := second.wait(context.Background()); id != "id-1" || filename != "a.zip" || err != nil {
+		t.Errorf("wait() = (%q, %q, %v), want the first download", id, filename, err)
+	}
+
+	if _, owner, _ := c.begin("key", "https://example.com/a.zip", now.Add(idempotencyWindow+time.Second)); !owner {
+		t.Error("key was still taken after the window")
+	}
+
+	failed, _, _ := c.begin("failing", "https://example.com/c.zip", now)
+	c.finish("failing", failed, "", "", errors.New("probe failed"))
+	if _, owner, _ := c.begin("failing", "https://example.com/c.zip", now); !owner {
+		t.Error("a failed request kept its key")
+	}
+}
+
+func TestHandleDownload_IdempotencyKey(t *testing.T) {
+	previousLifecycle := GlobalLifecycle
+	previousCleanup := GlobalLifecycleCleanup
+	previousCache := downloadIdempotency
+	previousPool := GlobalPool
+	t.Cleanup(func() {
+		GlobalPool = previousPool
+		GlobalLifecycle = previousLifecycle
+		GlobalLifecycleCleanup = previousCleanup
+		downloadIdempotency = previousCache
+	})
+	GlobalLifecycle = nil
+	GlobalLifecycleCleanup = nil
+	downloadIdempotency = newIdempotencyCache()
+	GlobalPool = download.NewWorkerPool(make(chan any, 16), 1)
+
+	service := &batchAddRecordingService{httpAPITestService: &httpAPITestService{}}
+	post := func(key, url string) *httptest.ResponseRecorder {
+		body := `{"url": "` + url + `", "path": "/tmp/downloads", "skip_approval": true}`
+		request := httptest.NewRequest(http.MethodPost, "/download", strings.NewReader(body))
+		request.Header.Set("Idempotency-Key", key)
+		recorder := httptest.NewRecorder()
+		handleDownload(recorder, request, "", service)
+		return recorder
+	}
+
+	first := post("retry-1", "https://example.com/one.zip")
+	second := post("retry-1", "https://example.com/one.zip")
+	if first.Code != http.StatusOK || second.Code != http.StatusOK {
+		t.Fatalf("status = %d, %d; want 200 for both", first.Code, second.Code)
+	}
+	if len(service.added) != 1 {
+		t.Fatalf("queued %d downloads, want 1: %v", len(service.added), service.added)
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("replayed response is not marked Idempotent-Replayed")
+	}
+
+	var a, b map[string]interface{}
+	_ = json.Unmarshal(first.Body.Bytes(), &a)
+	_ = json.Unmarshal(second.Body.Bytes(), &b)
+	if a["id"] != b["id"] {
+		t.Errorf("replayed id = %v, want %v", b["id"], a["id"])
+	}
+
+	if got := post("retry-1", "https://example.com/other.zip").Code; got != http.StatusUnprocessableEntity {
+		t.Errorf("reused key with another URL = %d, want 422", got)
+	}
+	post("retry-2", "https://example.com/one.zip")
+	if len(service.added) != 2 {
+		t.Errorf("a new key queued %d downloads in total, want 2", len(service.added))
+	}
+}
+
+func TestHandleDownload_IdempotencyKeyRetriesAfterFailedOwner(t *testing.T) {
+	previousLifecycle := GlobalLifecycle
+	previousCleanup := GlobalLifecycleCleanup
+	previousCache := downloadIdempotency
+	previousPool := GlobalPool
+	t.Cleanup(func() {
+		GlobalPool = previousPool
+		GlobalLifecycle = previousLifecycle
+		GlobalLifecycleCleanup = previousCleanup
+		downloadIdempotency = previousCache
+	})
+	GlobalLifecycle = nil
+	GlobalLifecycleCleanup = nil
+	downloadIdempotency = newIdempotencyCache()
+	GlobalPool = download.NewWorkerPool(make(chan any, 16), 1)
+
+	const url = "https://example.com/one.zip"
+	owner, _, _ := downloadIdempotency.begin("retry-1", url, time.Now())
+
+	service := &batchAddRecordingService{httpAPITestService: &httpAPITestService{}}
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		body := `{"url": "` + url + `", "path": "/tmp/downloads", "skip_approval": true}`
+		request := httptest.NewRequest(http.MethodPost, "/download", strings.NewReader(body))
+		request.Header.Set("Idempotency-Key", "retry-1")
+		recorder := httptest.NewRecorder()
+		handleDownload(recorder, request, "", service)
+		done <- recorder
+	}()
+
+	// Let the second request find the key taken and wait on it
+	time.Sleep(50 * time.Millisecond)
+	downloadIdempotency.finish("retry-1", owner, "", "", errors.New("probe failed"))
+
+	recorder := <-done
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 once the waiting request queued the download itself", recorder.Code)
+	}
+	if recorder.Header().Get("Idempotent-Replayed") != "" {
+		t.Error("a request that queued the download is marked as replayed")
+	}
+	if len(service.added) != 1 {
+		t.Errorf("queued %d downloads, want 1", len(service.added))
+	}
+}
+
+func TestHandleDownload_IdempotencyKeyHeldWhileAwaitingApproval(t *testing.T) {
+	previousProgram := serverProgram
+	previousSettings := globalSettings
+	previousCache := downloadIdempotency
+	previousPool := GlobalPool
+	t.Cleanup(func() {
+		GlobalPool = previousPool
+		serverProgram = previousProgram
+		globalSettings = previousSettings
+		downloadIdempotency = previousCache
+	})
+	serverProgram = &tea.Program{}
+	globalSettings = config.DefaultSettings()
+	downloadIdempotency = newIdempotencyCache()
+	GlobalPool = download.NewWorkerPool(make(chan any, 16), 1)
+
+	service := &publishRecordingHTTPService{httpAPITestService: &httpAPITestService{}}
+	post := func() (*httptest.ResponseRecorder, string) {
+		body := `{"url": "https://example.com/one.zip", "path": "/tmp/downloads"}`
+		request := httptest.NewRequest(http.MethodPost, "/download", strings.NewReader(body))
+		request.Header.Set("Idempotency-Key", "approve-1")
+		recorder := httptest.NewRecorder()
+		handleDownload(recorder, request, "", service)
+		var resp struct {
+			ID string `json:"id"`
+		}
+		_ = json.Unmarshal(recorder.Body.Bytes(), &resp)
+		return recorder, resp.ID
+	}
+
+	first, approvalID := post()
+	if first.Code != http.StatusAccepted || approvalID == "" {
+		t.Fatalf("first status = %d, id %q; want 202 with an id", first.Code, approvalID)
+	}
+	retry, retryID := post()
+	if retry.Code != http.StatusAccepted || retryID != approvalID {
+		t.Fatalf("retry = %d, id %q; want 202 with %q", retry.Code, retryID, approvalID)
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("retry while awaiting approval is not marked as replayed")
+	}
+	if len(service.published) != 1 {
+		t.Fatalf("published %d approval prompts, want 1", len(service.published))
+	}
+
+	declines := make(chan interface{}, 1)
+	declines <- events.DownloadRequestDeclinedMsg{ID: approvalID}
+	close(declines)
+	downloadIdempotency.watchDeclines(declines)
+
+	if again, _ := post(); again.Code != http.StatusAccepted {
+		t.Fatalf("status after decline = %d, want 202", again.Code)
+	}
+	if len(service.published) != 2 {
+		t.Errorf("published %d approval prompts, want a second one after the decline", len(service.published))
+	}
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/i18n"
+	"github.com/SurgeDM/Surge/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var infoCmd = &cobra.Command{
+	Use:   "info <ID|PATH>",
+	Short: "Show where a downloaded file came from",
+	Long: `Show the provenance of a download, given by its ID or by the path of its file:
+the URL it was added with, the redirects the server sent it through, its
+mirrors, the checksum and signature it was verified against, how long it took
+and how fast it went, and the headers the server answered with.
+
+Everything shown comes from the download history, so a download removed from
+it can no longer be described.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDownloadIDs(),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+
+		if err := initializeGlobalState(); err != nil {
+			return err
+		}
+
+		entry, err := findVerifyTarget(args[0])
+		if err != nil {
+			return err
+		}
+		printProvenance(newProvenanceReport(entry), jsonOutput)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(infoCmd)
+	infoCmd.Flags().Bool("json", false, "Output in JSON format")
+}
+
+// provenanceReport is what surge info knows about where a file came from.
+type provenanceReport struct {
+	ID              string            `json:"id"`
+	Filename        string            `json:"filename"`
+	Path            string            `json:"path"`
+	Status          string            `json:"status"`
+	URL             string            `json:"url"`
+	Redirects       []string          `json:"redirects,omitempty"`
+	Mirrors         []string          `json:"mirrors,omitempty"`
+	Size            int64             `json:"size"`
+	Checksum        string            `json:"checksum,omitempty"`
+	SignatureValid  bool              `json:"signature_verified,omitempty"`
+	CompletedAt     int64             `json:"completed_at,omitempty"`
+	Duration        float64           `json:"duration_seconds,omitempty"`
+	AvgSpeed        float64           `json:"avg_speed,omitempty"` // bytes per second
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+}
+
+func newProvenanceReport(e *types.DownloadEntry) provenanceReport {
+	r := provenanceReport{
+		ID:              e.ID,
+		Filename:        e.Filename,
+		Path:            e.DestPath,
+		Status:          e.Status,
+		URL:             e.URL,
+		Redirects:       e.Redirects,
+		Mirrors:         e.Mirrors,
+		Size:            e.TotalSize,
+		Checksum:        e.Checksum,
+		SignatureValid:  e.Verified,
+		ResponseHeaders: e.ResponseHeaders,
+	}
+	if e.Status == "completed" {
+		r.CompletedAt = e.CompletedAt
+		r.Duration = (time.Duration(e.TimeTaken) * time.Millisecond).Seconds()
+		r.AvgSpeed = e.AvgSpeed
+	}
+	return r
+}
+
+func printProvenance(r provenanceReport, jsonOutput bool) {
+	if jsonOutput {
+		data, _ := json.MarshalIndent(r, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	field := func(id, value string) {
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", i18n.T("cli.field_"+id), value)
+	}
+	// list puts each value on its own line under a single label
+	list := func(id string, values []string) {
+		for i, v := range values {
+			if i == 0 {
+				field(id, v)
+			} else {
+				_, _ = fmt.Fprintf(w, "\t%s\n", v)
+			}
+		}
+	}
+
+	field("id", r.ID)
+	field("filename", r.Filename)
+	field("path", r.Path)
+	field("status", r.Status)
+	field("url", r.URL)
+	hops := make([]string, len(r.Redirects))
+	for i, hop := range r.Redirects {
+		hops[i] = "\u2192 " + hop
+	}
+	list("redirects", hops)
+	list("mirrors", r.Mirrors)
+	if r.Size > 0 {
+		field("size", utils.ConvertBytesToHumanReadable(r.Size))
+	}
+	if r.Checksum != "" {
+		field("checksum", r.Checksum)
+	}
+	if r.SignatureValid {
+		field("signature", i18n.T("cli.signature_trusted"))
+	}
+	if r.CompletedAt > 0 {
+		field("completed", time.Unix(r.CompletedAt, 0).Format(time.DateTime))
+	}
+	if r.Duration > 0 {
+		field("duration", time.Duration(r.Duration*float64(time.Second)).Round(time.Second).String())
+	}
+	if r.AvgSpeed > 0 {
+		field("avg_speed", utils.ConvertBytesToHumanReadable(int64(r.AvgSpeed))+"/s")
+	}
+	keys := make([]string, 0, len(r.ResponseHeaders))
+	for k := range r.ResponseHeaders {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	headers := make([]string, len(keys))
+	for i, k := range keys {
+		headers[i] = k + ": " + r.ResponseHeaders[k]
+	}
+	list("headers", headers)
+	_ = w.Flush()
+}
@@ -6,21 +6,26 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
+	"strings"
 	"text/tabwriter"
 	"time"
+	"unicode/utf8"
 
 	"github.com/SurgeDM/Surge/internal/engine/state"
 	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/i18n"
 	"github.com/SurgeDM/Surge/internal/utils"
 	"github.com/spf13/cobra"
 )
 
 var lsCmd = &cobra.Command{
-	Use:     "ls [id]",
-	Aliases: []string{"l"},
-	Short:   "List downloads",
-	Long:    `List all downloads from the running server or database. Optionally show details for a specific download by ID.`,
-	Args:    cobra.MaximumNArgs(1),
+	Use:               "ls [id]",
+	Aliases:           []string{"l"},
+	Short:             "List downloads",
+	Long:              `List all downloads from the running server or database. Optionally show details for a specific download by ID.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeDownloadIDs(),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := initializeGlobalState(); err != nil {
 			return err
@@ -28,6 +33,7 @@ var lsCmd = &cobra.Command{
 
 		jsonOutput, _ := cmd.Flags().GetBool("json")
 		watch, _ := cmd.Flags().GetBool("watch")
+		tag, _ := cmd.Flags().GetString("tag")
 
 		baseURL, token, err := resolveAPIConnection(false)
 		if err != nil {
@@ -45,29 +51,33 @@ var lsCmd = &cobra.Command{
 			for {
 				// Clear screen first for watch mode
 				fmt.Print("\033[H\033[2J")
-				if err := printDownloads(jsonOutput, baseURL, token, strictRemote); err != nil {
+				if err := printDownloads(jsonOutput, tag, baseURL, token, strictRemote); err != nil {
 					return err
 				}
 				time.Sleep(1 * time.Second)
 			}
 		}
-		return printDownloads(jsonOutput, baseURL, token, strictRemote)
+		return printDownloads(jsonOutput, tag, baseURL, token, strictRemote)
 	},
 }
 
 // downloadInfo is a unified structure for display
 type downloadInfo struct {
-	ID         string  `json:"id"`
-	URL        string  `json:"url,omitempty"`
-	Filename   string  `json:"filename"`
-	Status     string  `json:"status"`
-	Progress   float64 `json:"progress"`
-	TotalSize  int64   `json:"total_size"`
-	Downloaded int64   `json:"downloaded"`
-	Speed      float64 `json:"speed,omitempty"`
+	ID         string      `json:"id"`
+	Alias      string      `json:"alias,omitempty"`
+	Tags       []string    `json:"tags,omitempty"`
+	URL        string      `json:"url,omitempty"`
+	Filename   string      `json:"filename"`
+	Status     string      `json:"status"`
+	Phase      types.Phase `json:"phase,omitempty"`
+	Progress   float64     `json:"progress"`
+	TotalSize  int64       `json:"total_size"`
+	Downloaded int64       `json:"downloaded"`
+	Speed      float64     `json:"speed,omitempty"`
 }
 
-func printDownloads(jsonOutput bool, baseURL string, token string, strictRemote bool) error {
+// printDownloads lists downloads, only those tagged tag when it is set.
+func printDownloads(jsonOutput bool, tag string, baseURL string, token string, strictRemote bool) error {
 	var downloads []downloadInfo
 
 	// Try to get from running server first
@@ -81,8 +91,11 @@ func printDownloads(jsonOutput bool, baseURL string, token string, strictRemote
 			for _, s := range serverDownloads {
 				downloads = append(downloads, downloadInfo{
 					ID:         s.ID,
+					Alias:      s.Alias,
+					Tags:       s.Tags,
 					Filename:   s.Filename,
 					Status:     s.Status,
+					Phase:      s.Phase,
 					Progress:   s.Progress,
 					TotalSize:  s.TotalSize,
 					Downloaded: s.Downloaded,
@@ -106,6 +119,8 @@ func printDownloads(jsonOutput bool, baseURL string, token string, strictRemote
 			}
 			downloads = append(downloads, downloadInfo{
 				ID:         d.ID,
+				Alias:      d.Alias,
+				Tags:       d.Tags,
 				Filename:   d.Filename,
 				Status:     d.Status,
 				Progress:   progress,
@@ -115,9 +130,15 @@ func printDownloads(jsonOutput bool, baseURL string, token string, strictRemote
 		}
 	}
 
+	if tag != "" {
+		downloads = slices.DeleteFunc(downloads, func(d downloadInfo) bool {
+			return !types.HasTag(d.Tags, tag)
+		})
+	}
+
 	if len(downloads) == 0 {
 		if !jsonOutput {
-			fmt.Println("No downloads found.")
+			fmt.Println(i18n.T("cli.no_downloads"))
 		} else {
 			fmt.Println("[]")
 		}
@@ -132,8 +153,9 @@ func printDownloads(jsonOutput bool, baseURL string, token string, strictRemote
 
 	// Table output
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	_, _ = fmt.Fprintln(w, "ID\tFILENAME\tSTATUS\tPROGRESS\tSPEED\tSIZE")
-	_, _ = fmt.Fprintln(w, "--\t--------\t------\t--------\t-----\t----")
+	header := i18n.T("cli.ls_columns")
+	_, _ = fmt.Fprintln(w, header)
+	_, _ = fmt.Fprintln(w, underline(header))
 
 	for _, d := range downloads {
 		progress := fmt.Sprintf("%.1f%%", d.Progress)
@@ -142,14 +164,16 @@ func printDownloads(jsonOutput bool, baseURL string, token string, strictRemote
 		// Speed display
 		var speed string
 		if d.Speed > 0 {
-			speed = fmt.Sprintf("%.1f MB/s", d.Speed)
+			speed = utils.FormatSpeed(d.Speed * float64(types.MB))
 		} else {
 			speed = "-"
 		}
 
-		// Truncate ID for display
+		// Show the alias when there is one, otherwise the truncated ID
 		id := d.ID
-		if len(id) > 8 {
+		if d.Alias != "" {
+			id = d.Alias
+		} else if len(id) > 8 {
 			id = id[:8]
 		}
 
@@ -159,7 +183,7 @@ func printDownloads(jsonOutput bool, baseURL string, token string, strictRemote
 			filename = filename[:22] + "..."
 		}
 
-		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", id, filename, d.Status, progress, speed, size)
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", id, filename, statusWithPhase(d.Status, d.Phase), progress, speed, size)
 	}
 	_ = w.Flush()
 	return nil
@@ -230,6 +254,8 @@ func showDownloadDetails(partialID string, jsonOutput bool, baseURL string, toke
 
 	status := types.DownloadStatus{
 		ID:         found.ID,
+		Alias:      found.Alias,
+		Tags:       found.Tags,
 		URL:        found.URL,
 		Filename:   found.Filename,
 		Status:     found.Status,
@@ -248,22 +274,59 @@ func printDownloadDetail(d types.DownloadStatus, jsonOutput bool) {
 		return
 	}
 
-	fmt.Printf("ID:         %s\n", d.ID)
-	fmt.Printf("URL:        %s\n", d.URL)
-	fmt.Printf("Filename:   %s\n", d.Filename)
-	fmt.Printf("Status:     %s\n", d.Status)
-	fmt.Printf("Progress:   %.1f%%\n", d.Progress)
-	fmt.Printf("Downloaded: %s / %s\n", utils.ConvertBytesToHumanReadable(d.Downloaded), utils.ConvertBytesToHumanReadable(d.TotalSize))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	field := func(id, value string) {
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", i18n.T("cli.field_"+id), value)
+	}
+	field("id", d.ID)
+	if d.Alias != "" {
+		field("alias", d.Alias)
+	}
+	if len(d.Tags) > 0 {
+		field("tags", strings.Join(d.Tags, ", "))
+	}
+	field("url", d.URL)
+	field("filename", d.Filename)
+	field("status", statusWithPhase(d.Status, d.Phase))
+	field("progress", fmt.Sprintf("%.1f%%", d.Progress))
+	field("downloaded", fmt.Sprintf("%s / %s", utils.ConvertBytesToHumanReadable(d.Downloaded), utils.ConvertBytesToHumanReadable(d.TotalSize)))
 	if d.Speed > 0 {
-		fmt.Printf("Speed:      %.1f MB/s\n", d.Speed)
+		field("speed", utils.FormatSpeed(d.Speed*float64(types.MB)))
+	}
+	if d.FinishesAt > 0 {
+		field("finishes", "~"+utils.FormatFinishTime(time.Unix(d.FinishesAt, 0), time.Now()))
 	}
 	if d.Error != "" {
-		fmt.Printf("Error:      %s\n", d.Error)
+		field("error", d.Error)
+	}
+	if d.ErrorCode != "" {
+		field("error code", string(d.ErrorCode))
+	}
+	_ = w.Flush()
+}
+
+// statusWithPhase shows what a running download is busy with next to its
+// status, e.g. "downloading (verifying)", when it is not transferring.
+func statusWithPhase(status string, phase types.Phase) string {
+	if phase == "" || phase == types.PhaseDownloading {
+		return status
+	}
+	return fmt.Sprintf("%s (%s)", status, phase)
+}
+
+// underline turns a tab-separated table header into the matching row of
+// dashes.
+func underline(header string) string {
+	cols := strings.Split(header, "\t")
+	for i, col := range cols {
+		cols[i] = strings.Repeat("-", utf8.RuneCountInString(col))
 	}
+	return strings.Join(cols, "\t")
 }
 
 func init() {
 	rootCmd.AddCommand(lsCmd)
 	lsCmd.Flags().Bool("json", false, "Output in JSON format")
 	lsCmd.Flags().Bool("watch", false, "Watch mode: refresh every second")
+	lsCmd.Flags().String("tag", "", "Only list downloads with this tag")
 }
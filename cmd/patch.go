@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine"
+	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/patch"
+	"github.com/SurgeDM/Surge/internal/engine/stream"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// patchExtensions are stripped from the name of a patch to name the file it
+// produces.
+var patchExtensions = []string{".xdelta3", ".xdelta", ".xd3", ".vcdiff", ".bsdiff", ".bspatch", ".patch", ".diff", ".delta"}
+
+// runPatchGet downloads the patch at patchURL and applies it to base,
+// writing the result to outputDir. The patched file is assembled beside its
+// final name and only takes it once complete and, when request has one,
+// matching its checksum; so base itself can be the file being updated.
+func runPatchGet(patchURL, outputDir, base string, tlsOpts types.TLSOptions, request types.RequestOptions, showProgress bool) error {
+	baseFile, err := os.Open(base)
+	if err != nil {
+		return fmt.Errorf("failed to open patch base: %w", err)
+	}
+	defer func() { _ = baseFile.Close() }()
+
+	name := patchTargetName(patchURL, base)
+	dir := resolveClientOutputPath(outputDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	dest := filepath.Join(dir, name)
+
+	runtime := getSettings().ToRuntimeConfig()
+	runtime.TLS = runtime.TLS.Merge(tlsOpts)
+	runtime = request.Timeouts.Apply(runtime)
+	runtime = types.BindRuntime(runtime, request.Interface, patchURL)
+	transport, err := engine.DefaultNetworkPool.AcquireTransportFor(runtime, types.PoolMaxConnsPerHost)
+	if err != nil {
+		return fmt.Errorf("failed to configure TLS: %w", err)
+	}
+	defer engine.DefaultNetworkPool.ReleaseTransport(transport)
+
+	conns := runtime.GetMaxConnectionsPerDownload()
+	if request.Connections > 0 {
+		conns = min(conns, request.Connections)
+	}
+	d := &stream.Downloader{
+		Client:      &http.Client{Transport: transport, CheckRedirect: engine.RedirectPolicy(runtime, nil)},
+		UserAgent:   runtime.GetUserAgent(),
+		Connections: conns,
+		MaxRetries:  runtime.GetMaxTaskRetries(),
+	}
+	progress := newHeadlessProgressIfTerminal(showProgress)
+	progress.start(name, name, 0)
+	start := time.Now()
+	d.Progress = func(written, total int64) {
+		progress.update(events.ProgressMsg{
+			DownloadID:        name,
+			Downloaded:        written,
+			Total:             total,
+			Speed:             float64(written) / max(time.Since(start).Seconds(), 0.001),
+			ActiveConnections: conns,
+		})
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if maxDuration := runtime.GetMaxDuration(); maxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, maxDuration, types.ErrMaxDuration)
+		defer cancel()
+	}
+
+	// The patch is only needed until it is applied
+	patchFile, err := os.CreateTemp(dir, ".surge-patch-*")
+	if err != nil {
+		progress.clear()
+		return err
+	}
+	defer func() {
+		_ = patchFile.Close()
+		_ = os.Remove(patchFile.Name())
+	}()
+	patchSize, err := d.Download(ctx, patchURL, patchFile)
+	progress.clear()
+	if err != nil {
+		if errors.Is(context.Cause(ctx), types.ErrMaxDuration) {
+			return types.ErrMaxDuration
+		}
+		return fmt.Errorf("failed to download patch: %w", err)
+	}
+
+	format, err := applyPatchFile(ctx, dest, baseFile, patchFile, patchSize, request.Checksum)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s: applied %s %s patch to %s\n", dest, utils.ConvertBytesToHumanReadable(patchSize), format, base)
+	return nil
+}
+
+// applyPatchFile writes what patchFile turns base into to dest, by way of a
+// working file, checking it against checksum when there is one.
+func applyPatchFile(ctx context.Context, dest string, base, patchFile *os.File, patchSize int64, checksum string) (patch.Format, error) {
+	working := dest + types.IncompleteSuffix
+	out, err := os.OpenFile(working, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0o644)
+	if err != nil {
+		return "", err
+	}
+	format, err := patch.Apply(ctx, out, base, patchFile, patchSize)
+	if err == nil {
+		err = out.Sync()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && checksum != "" {
+		err = engine.VerifyChecksum(ctx, working, checksum, nil)
+	}
+	if err == nil {
+		err = os.Rename(working, dest)
+	}
+	if err != nil {
+		_ = os.Remove(working)
+		return format, fmt.Errorf("failed to apply patch: %w", err)
+	}
+	return format, nil
+}
+
+// patchTargetName names the patched file after the patch without its
+// extension, or after base when the patch has none of patchExtensions.
+func patchTargetName(patchURL, base string) string {
+	name := path.Base(patchURL)
+	if u, err := url.Parse(patchURL); err == nil {
+		name = path.Base(u.Path)
+	}
+	for _, ext := range patchExtensions {
+		if trimmed, ok := strings.CutSuffix(strings.ToLower(name), ext); ok && trimmed != "" {
+			return name[:len(trimmed)]
+		}
+	}
+	return filepath.Base(base)
+}
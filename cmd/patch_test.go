@@ -0,0 +1,16 @@
+package cmd
+
+import "testing"
+
+func TestPatchTargetName(t *testing.T) {
+	for _, tc := range []struct{ url, base, want string }{
+		{"https://cdn.example.com/game/data.pak.xdelta?sig=1", "/games/data.pak", "data.pak"},
+		{"https://cdn.example.com/app-1.2-to-1.3.BSDIFF", "app-1.2", "app-1.2-to-1.3"},
+		{"https://cdn.example.com/update.bin", "/opt/app/app.bin", "app.bin"},
+		{"https://cdn.example.com/.vcdiff", "/opt/app/app.bin", "app.bin"},
+	} {
+		if got := patchTargetName(tc.url, tc.base); got != tc.want {
+			t.Errorf("patchTargetName(%q, %q) = %q, want %q", tc.url, tc.base, got, tc.want)
+		}
+	}
+}
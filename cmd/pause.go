@@ -1,17 +1,23 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 
+	"github.com/SurgeDM/Surge/internal/utils"
 	"github.com/spf13/cobra"
 )
 
 var pauseCmd = &cobra.Command{
 	Use:   "pause <ID>",
 	Short: "Pause a download",
-	Long:  `Pause a download by its ID. Use --all to pause all downloads.`,
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Pause a download by its ID. Use --all to pause every running and queued
+download; surge resume --all later resumes exactly those, leaving downloads
+that were already paused as they are.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeDownloadIDs("downloading", "queued"),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := initializeGlobalState(); err != nil {
 			return err
@@ -24,8 +30,11 @@ var pauseCmd = &cobra.Command{
 		}
 
 		if all {
-			// TODO: Implement /pause-all endpoint or iterate
-			fmt.Println("Pausing all downloads is not yet implemented for running server.")
+			ids, err := executeGlobalPause("/pause-all")
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Paused %d download(s). Run surge resume --all to resume them.\n", len(ids))
 			return nil
 		}
 
@@ -35,5 +44,35 @@ var pauseCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(pauseCmd)
-	pauseCmd.Flags().Bool("all", false, "Pause all downloads")
+	pauseCmd.Flags().Bool("all", false, "Pause all running and queued downloads")
+}
+
+// executeGlobalPause posts to /pause-all, /resume-all or /retry-failed and
+// returns the IDs of the downloads it paused, resumed or retried.
+func executeGlobalPause(endpoint string) ([]string, error) {
+	baseURL, token, err := resolveAPIConnection(true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Surge server: %w", err)
+	}
+	resp, err := doAPIRequest(http.MethodPost, baseURL, token, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to server: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			utils.Debug("Error closing response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("server error: %s - %s", resp.Status, string(body))
+	}
+	var result struct {
+		IDs []string `json:"ids"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return result.IDs, nil
 }
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/core"
+	"github.com/SurgeDM/Surge/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// profileWatchInterval is how often a running instance checks whether the
+// network changed enough to pick a different profile.
+const profileWatchInterval = 30 * time.Second
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "List and switch settings profiles",
+	Long: `Profiles are named sets of settings defined in config.toml, for example
+a proxy and speed limit for work and a different download folder at home:
+
+  [profiles.work]
+  match_ssid = ["CorpWiFi"]
+
+  [profiles.work.network]
+  proxy_url = "http://proxy.corp:3128"
+
+With general.profile set to auto (the default), the first profile whose
+match_ssid or match_interface fits the current network is used.`,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles and show which one is active",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		settings, err := loadSettingsForConfigCmd()
+		if err != nil {
+			return err
+		}
+		profiles, err := config.LoadProfiles()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", config.GetConfigFilePath(), err)
+		}
+		if len(profiles) == 0 {
+			fmt.Printf("No profiles defined. Add [profiles.<name>] tables to %s.\n", config.GetConfigFilePath())
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "\tNAME\tMATCH\tSETTINGS")
+		for _, p := range profiles {
+			marker := ""
+			if p.Name == settings.ActiveProfile() {
+				marker = "*"
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", marker, p.Name, profileMatchSummary(p), len(p.Values))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Printf("\nSelection: %s\n", config.Resolve[string](settings.General.Profile))
+		return nil
+	},
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:               "use <name|auto|none>",
+	Short:             "Switch to a profile and reload the running instance",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProfileNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := strings.TrimSpace(args[0])
+		profiles, err := config.LoadProfiles()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", config.GetConfigFilePath(), err)
+		}
+		if _, err := config.SelectProfile(profiles, name); err != nil {
+			return err
+		}
+
+		settings, err := config.LoadSettings()
+		if err != nil {
+			return fmt.Errorf("failed to load settings: %w", err)
+		}
+		settings.General.Profile.Value = name
+		if err := config.SaveSettings(settings); err != nil {
+			return fmt.Errorf("failed to save settings: %w", err)
+		}
+
+		settings, err = config.LoadSettings()
+		if err != nil {
+			return fmt.Errorf("failed to load settings: %w", err)
+		}
+		switch source := settings.Source("general.profile"); source {
+		case config.SourceFile:
+			fmt.Printf("Note: general.profile is set in %s, which takes precedence. Run 'surge config set general.profile %s' instead.\n", config.GetConfigFilePath(), name)
+		case config.SourceEnv, config.SourceFlag:
+			fmt.Printf("Note: general.profile is overridden by %s for this shell.\n", settingOverrideOrigin("general.profile", source))
+		}
+		if active := settings.ActiveProfile(); active != "" {
+			fmt.Printf("Active profile: %s\n", active)
+		} else {
+			fmt.Println("No profile active.")
+		}
+		reloadRunningInstance()
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileListCmd, profileUseCmd)
+}
+
+func profileMatchSummary(p config.Profile) string {
+	var parts []string
+	for _, ssid := range p.MatchSSID {
+		parts = append(parts, "ssid="+ssid)
+	}
+	for _, iface := range p.MatchInterface {
+		parts = append(parts, "interface="+iface)
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func completeProfileNames(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	out := []cobra.Completion{
+		cobra.CompletionWithDesc(config.ProfileAuto, "Pick by network"),
+		cobra.CompletionWithDesc("none", "No profile"),
+	}
+	profiles, _ := config.LoadProfiles()
+	for _, p := range profiles {
+		out = append(out, cobra.CompletionWithDesc(p.Name, profileMatchSummary(p)))
+	}
+	filtered := out[:0]
+	for _, c := range out {
+		if strings.HasPrefix(c, toComplete) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered, cobra.ShellCompDirectiveNoFileComp
+}
+
+// watchProfileNetwork reloads settings when a different profile becomes
+// active, typically because general.profile is auto and the network
+// changed, until ctx is done.
+func watchProfileNetwork(ctx context.Context, service core.DownloadService) {
+	ticker := time.NewTicker(profileWatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		settings, err := config.LoadSettings()
+		if err != nil {
+			continue
+		}
+		previous := getSettings().ActiveProfile()
+		if settings.ActiveProfile() == previous {
+			continue
+		}
+		if _, err := reloadSettings(service); err != nil {
+			utils.Debug("Profile switch: reload failed: %v", err)
+			continue
+		}
+		if name := settings.ActiveProfile(); name != "" {
+			publishSystemLog(fmt.Sprintf("Switched to settings profile %q", name))
+		} else {
+			publishSystemLog(fmt.Sprintf("Left settings profile %q", previous))
+		}
+	}
+}
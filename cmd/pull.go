@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/core"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// ErrDownloadNotCompleted is returned for a file asked for before its
+// download has finished.
+var ErrDownloadNotCompleted = errors.New("download has not completed")
+
+var pullCmd = &cobra.Command{
+	Use:   "pull <id>...",
+	Short: "Download finished files from a remote Surge daemon",
+	Long: `Fetch the files of downloads that have finished on a remote daemon, named
+with --remote or given with --host, to this machine. The daemon serves each
+file over its authenticated API and it is downloaded here like any other,
+over several connections and resumable.
+
+With a Surge instance running locally the files are added to its queue;
+otherwise they are downloaded in this process, as 'surge get' does.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := initializeGlobalState(); err != nil {
+			return err
+		}
+
+		remoteName, _ := cmd.Flags().GetString("remote")
+		output, _ := cmd.Flags().GetString("output")
+		noProgress, _ := cmd.Flags().GetBool("no-progress")
+
+		var baseURL, token string
+		var err error
+		switch {
+		case remoteName != "":
+			remote, err := config.FindRemote(remoteName)
+			if err != nil {
+				return err
+			}
+			if baseURL, token, err = resolveRemoteConnection(remote); err != nil {
+				return err
+			}
+		case resolveHostTarget() != "":
+			if baseURL, token, err = resolveAPIConnection(true); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("pull needs a daemon to fetch from: use --remote or --host")
+		}
+
+		downloads, err := GetRemoteDownloads(baseURL, token)
+		if err != nil {
+			return fmt.Errorf("failed to list remote downloads: %w", err)
+		}
+		var candidates []downloadRef
+		appendCandidates(&candidates, downloads)
+
+		var urls []string
+		for _, arg := range args {
+			id := arg
+			if len(id) < 32 || types.ValidateAlias(id) == nil {
+				if id, err = resolveIDFromCandidates(arg, candidates); err != nil {
+					return err
+				}
+			}
+			urls = append(urls, remoteFileURL(baseURL, token, id))
+		}
+
+		outPath := utils.EnsureAbsPath(output)
+		if strings.TrimSpace(output) == "" {
+			if outPath, err = os.Getwd(); err != nil {
+				outPath = "."
+			}
+		}
+		tlsOpts := types.TLSOptions{Insecure: globalInsecureTLS}
+		if ca := strings.TrimSpace(globalTLSCAFile); ca != "" {
+			if tlsOpts.CAFile, err = filepath.Abs(ca); err != nil {
+				return fmt.Errorf("invalid path %q: %w", ca, err)
+			}
+		}
+
+		// The files go to this machine, so a local instance takes them
+		// rather than the daemon they come from.
+		details, ok := getActiveConnectionDetails()
+		if !ok {
+			return runStandaloneGet(urls, outPath, tlsOpts, types.RequestOptions{}, nil, !noProgress, false)
+		}
+		localURL := fmt.Sprintf("http://127.0.0.1:%d", details.port)
+		localToken := resolveLocalTokenForDetails(details)
+		for _, u := range urls {
+			if _, err := postDownloadRequest(localURL, localToken, DownloadRequest{
+				URL:                u,
+				Path:               outPath,
+				SkipApproval:       true,
+				IsExplicitCategory: output != "",
+				TLS:                tlsOpts,
+			}); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("Queued %d file(s) to pull.\n", len(urls))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pullCmd)
+	pullCmd.Flags().StringP("remote", "r", "", "Remote daemon to fetch from, as named in config.toml (default: --host)")
+	pullCmd.Flags().StringP("output", "o", "", "Directory to save into (defaults to current working directory)")
+	pullCmd.Flags().Bool("no-progress", false, "Only log events instead of drawing progress bars when downloading in this process")
+}
+
+// remoteFileURL is where the daemon at baseURL serves the file of download
+// id. The link carries a signature made with the daemon's token instead of
+// the token itself, so the queued download stores nothing that opens the
+// rest of the API.
+func remoteFileURL(baseURL, token, id string) string {
+	return strings.TrimRight(baseURL, "/") + "/file?id=" + url.QueryEscape(id) + "&sig=" + fileSignature(token, id)
+}
+
+// fileSignature signs the /file link of download id with token.
+func fileSignature(token, id string) string {
+	mac := hmac.New(sha256.New, []byte(token))
+	mac.Write([]byte("file:" + id))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// validFileSignature reports whether r is a GET or HEAD of /file signed
+// for its id with token.
+func validFileSignature(r *http.Request, token string) bool {
+	if r.URL.Path != "/file" || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+		return false
+	}
+	q := r.URL.Query()
+	id, sig := q.Get("id"), q.Get("sig")
+	return id != "" && token != "" && hmac.Equal([]byte(sig), []byte(fileSignature(token, id)))
+}
+
+// handleDownloadFile serves the file of a completed download, with range
+// requests, so another Surge can pull it.
+func handleDownloadFile(service core.DownloadService) http.HandlerFunc {
+	return withRequiredID(func(w http.ResponseWriter, r *http.Request, id string) {
+		destPath, err := completedFilePath(service, id)
+		if err != nil {
+			code := statusCodeForResolveDownloadError(err)
+			if errors.Is(err, ErrDownloadNotCompleted) {
+				code = http.StatusConflict
+			}
+			http.Error(w, err.Error(), code)
+			return
+		}
+
+		f, err := os.Open(destPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				http.Error(w, "file no longer exists", http.StatusGone)
+				return
+			}
+			http.Error(w, "Failed to open file: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer func() { _ = f.Close() }()
+		info, err := f.Stat()
+		if err != nil || info.IsDir() {
+			http.Error(w, "not a file", http.StatusInternalServerError)
+			return
+		}
+
+		name := filepath.Base(destPath)
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+		http.ServeContent(w, r, name, info.ModTime(), f)
+	})
+}
+
+// completedFilePath returns where the file of download id was saved, once it
+// has completed.
+func completedFilePath(service core.DownloadService, id string) (string, error) {
+	if service == nil {
+		return "", ErrServiceUnavailable
+	}
+	if status, err := service.GetStatus(id); err == nil && status != nil && status.Status != "completed" {
+		return "", fmt.Errorf("%w: %s is %s", ErrDownloadNotCompleted, id, status.Status)
+	}
+	return resolveDownloadDestPath(service, id)
+}
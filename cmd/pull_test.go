@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func TestFileEndpoint_ServesCompletedDownloadsWithRanges(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "movie.mkv")
+	if err := os.WriteFile(path, []byte("0123456789"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	service := &httpAPITestService{
+		statusByID: map[string]*types.DownloadStatus{
+			"done":    {ID: "done", Status: "completed", DestPath: path},
+			"running": {ID: "running", Status: "downloading", DestPath: path},
+		},
+	}
+	mux := http.NewServeMux()
+	registerHTTPRoutes(mux, 0, "", service)
+
+	request := httptest.NewRequest(http.MethodGet, "/file?id=done", nil)
+	request.Header.Set("Range", "bytes=2-5")
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, request)
+	body, _ := io.ReadAll(recorder.Body)
+	if recorder.Code != http.StatusPartialContent || string(body) != "2345" {
+		t.Fatalf("range request = %d %q, want 206 \"2345\"", recorder.Code, body)
+	}
+	if got := recorder.Header().Get("Content-Disposition"); got != `attachment; filename=movie.mkv` {
+		t.Fatalf("Content-Disposition = %q", got)
+	}
+
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/file?id=running", nil))
+	if recorder.Code != http.StatusConflict {
+		t.Fatalf("unfinished download: status %d, want 409", recorder.Code)
+	}
+}
+
+func TestFileEndpoint_SignedLinkOpensOnlyItsFile(t *testing.T) {
+	const token = "daemon-token"
+	handler := authMiddleware(token, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	get := func(target string) int {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		return recorder.Code
+	}
+
+	link := remoteFileURL("http://seedbox:1700", token, "done")
+	if strings.Contains(link, token) {
+		t.Fatalf("link %q carries the token", link)
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := get(u.RequestURI()); got != http.StatusOK {
+		t.Errorf("signed link = %d, want 200", got)
+	}
+	if got := get("/file?id=other&sig=" + u.Query().Get("sig")); got != http.StatusUnauthorized {
+		t.Errorf("signature reused for another download = %d, want 401", got)
+	}
+	if got := get("/list?sig=" + u.Query().Get("sig")); got != http.StatusUnauthorized {
+		t.Errorf("signature used on another endpoint = %d, want 401", got)
+	}
+	if got := get(remoteFileURL("", "other-token", "done")); got != http.StatusUnauthorized {
+		t.Errorf("link signed with another token = %d, want 401", got)
+	}
+}
@@ -0,0 +1,340 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+var pushCmd = &cobra.Command{
+	Use:   "push --remote <name> <url>...",
+	Short: "Hand downloads to a remote Surge daemon",
+	Long: `Send URLs to a Surge daemon named in a [remotes.<name>] table of
+config.toml, so they download there instead of on this machine, e.g. to a
+seedbox. Headers and cookies given here travel with the request, so links
+that only work from a logged-in browser still download remotely.
+
+With --watch the command stays attached and shows the remote progress until
+every pushed download has finished; the downloads keep running on the daemon
+if it is interrupted.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := initializeGlobalState(); err != nil {
+			return err
+		}
+
+		remoteName, _ := cmd.Flags().GetString("remote")
+		output, _ := cmd.Flags().GetString("output")
+		rawHeaders, _ := cmd.Flags().GetStringArray("header")
+		cookie, _ := cmd.Flags().GetString("cookie")
+		watch, _ := cmd.Flags().GetBool("watch")
+
+		request, err := pushRequestFlags(cmd)
+		if err != nil {
+			return err
+		}
+		if request.Alias != "" && len(args) > 1 {
+			return fmt.Errorf("--name can only be used when pushing a single URL")
+		}
+		headers, err := parseHeaderFlags(rawHeaders)
+		if err != nil {
+			return err
+		}
+
+		remote, err := config.FindRemote(remoteName)
+		if err != nil {
+			return err
+		}
+		baseURL, token, err := resolveRemoteConnection(remote)
+		if err != nil {
+			return err
+		}
+
+		var reqs []DownloadRequest
+		for _, arg := range args {
+			rawURL, mirrors := ParseURLArg(arg)
+			if rawURL == "" {
+				continue
+			}
+			reqHeaders, err := withCookies(headers, cookie, rawURL)
+			if err != nil {
+				return err
+			}
+			reqs = append(reqs, DownloadRequest{
+				ID:             uuid.New().String(),
+				URL:            rawURL,
+				Mirrors:        mirrors,
+				Path:           output,
+				SkipApproval:   true,
+				Headers:        reqHeaders,
+				RequestOptions: request,
+			})
+		}
+		if len(reqs) == 0 {
+			return fmt.Errorf("no valid URLs to push")
+		}
+
+		if !watch {
+			return pushDownloads(baseURL, token, remote.Name, reqs, nil)
+		}
+		return pushAndWatch(baseURL, token, remote.Name, reqs)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pushCmd)
+	pushCmd.Flags().StringP("remote", "r", "", "Remote daemon to send the downloads to, as named in config.toml")
+	pushCmd.Flags().StringP("output", "o", "", "Directory on the remote to save into (default: its default download directory)")
+	pushCmd.Flags().StringArrayP("header", "H", nil, `Header to send with the download, e.g. -H "Authorization: Bearer abc" (repeatable)`)
+	pushCmd.Flags().StringP("cookie", "b", "", "Cookies to send, as 'name=value; name2=value2' or @cookies.txt for a browser-exported Netscape cookie file")
+	pushCmd.Flags().StringP("name", "n", "", "Alias to refer to this download by instead of its ID")
+	pushCmd.Flags().StringSliceP("tag", "t", nil, "Tag these downloads; repeat or comma-separate for several")
+	pushCmd.Flags().String("checksum", "", "Digest the finished file must match, e.g. sha256:<hex>")
+	pushCmd.Flags().Int("connections", 0, "Open at most this many connections per download")
+	pushCmd.Flags().BoolP("yes", "y", false, "Start downloads larger than the remote's confirm_size_threshold without asking")
+	pushCmd.Flags().BoolP("watch", "w", false, "Show the remote progress until the pushed downloads finish")
+	_ = pushCmd.MarkFlagRequired("remote")
+}
+
+// pushRequestFlags reads the download options push forwards. Unlike add,
+// nothing is resolved against the local file system, since the remote
+// daemon is the one that uses them.
+func pushRequestFlags(cmd *cobra.Command) (types.RequestOptions, error) {
+	alias, _ := cmd.Flags().GetString("name")
+	rawTags, _ := cmd.Flags().GetStringSlice("tag")
+	checksum, _ := cmd.Flags().GetString("checksum")
+	connections, _ := cmd.Flags().GetInt("connections")
+	confirmed, _ := cmd.Flags().GetBool("yes")
+
+	tags, err := types.NormalizeTags(rawTags)
+	if err != nil {
+		return types.RequestOptions{}, err
+	}
+	opts := types.RequestOptions{Alias: alias, Tags: tags, Checksum: checksum, Connections: connections, Confirmed: confirmed}
+	if err := opts.Validate(); err != nil {
+		return types.RequestOptions{}, err
+	}
+	return opts, nil
+}
+
+// resolveRemoteConnection returns the API address and token of remote. A
+// remote without its own token uses --token or SURGE_TOKEN.
+func resolveRemoteConnection(remote config.Remote) (string, string, error) {
+	target, err := parseConnectTarget(remote.Host, currentRemoteClientConfig().AllowInsecureHTTP)
+	if err != nil {
+		return "", "", fmt.Errorf("remote %q: %w", remote.Name, err)
+	}
+	if remote.Token != "" {
+		return target.BaseURL, remote.Token, nil
+	}
+	token, err := resolveTokenForConnectTarget(target)
+	if err != nil {
+		return "", "", fmt.Errorf("remote %q: %w", remote.Name, err)
+	}
+	return target.BaseURL, token, nil
+}
+
+// pushDownloads sends reqs to the daemon, telling result, when set, whether
+// it took each one. It fails if any of them was refused.
+func pushDownloads(baseURL, token, remoteName string, reqs []DownloadRequest, result func(id string, ok bool)) error {
+	failed := 0
+	for _, req := range reqs {
+		_, err := postDownloadRequest(baseURL, token, req)
+		if err != nil {
+			fmt.Println(addFailedMessage(req.URL, err))
+			failed++
+		} else {
+			fmt.Printf("Pushed %s to %s [%s]\n", req.URL, remoteName, truncateID(req.ID))
+		}
+		if result != nil {
+			result(req.ID, err == nil)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d downloads could not be pushed to %s", failed, len(reqs), remoteName)
+	}
+	return nil
+}
+
+// pushAndWatch pushes reqs and follows their progress over the daemon's
+// event stream until all of them have finished. The stream is opened first
+// so that no event about a pushed download is missed.
+func pushAndWatch(baseURL, token, remoteName string, reqs []DownloadRequest) error {
+	service, err := newRemoteDownloadService(baseURL, token)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, cleanup, err := service.StreamEvents(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting event stream: %w", err)
+	}
+
+	ids := make(map[string]bool, len(reqs))
+	for _, req := range reqs {
+		ids[req.ID] = true
+	}
+	tracker := newGetTracker()
+	tracker.expect(len(reqs))
+	consumerDone := make(chan struct{})
+	go func() {
+		defer close(consumerDone)
+		runHeadlessConsumer(filterEvents(stream, ids), newHeadlessProgressIfTerminal(true), tracker.observe)
+	}()
+	defer func() {
+		cleanup()
+		<-consumerDone
+	}()
+
+	pushErr := pushDownloads(baseURL, token, remoteName, reqs, func(id string, ok bool) {
+		if ok {
+			tracker.accepted(id)
+		} else {
+			tracker.rejected()
+		}
+	})
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	select {
+	case <-tracker.done:
+	case <-sigChan:
+		fmt.Printf("\nStopped watching; the downloads keep running on %s.\n", remoteName)
+		return pushErr
+	}
+
+	if pushErr != nil {
+		return pushErr
+	}
+	if failed, total := tracker.failures(), tracker.total(); failed > 0 {
+		return fmt.Errorf("%d of %d downloads failed on %s", failed, total, remoteName)
+	}
+	fmt.Printf("All %d downloads completed on %s.\n", tracker.total(), remoteName)
+	return nil
+}
+
+// filterEvents passes on the events of stream that are about one of ids,
+// since a daemon reports on every download it runs.
+func filterEvents(stream <-chan interface{}, ids map[string]bool) <-chan interface{} {
+	out := make(chan interface{}, 16)
+	go func() {
+		defer close(out)
+		for msg := range stream {
+			if batch, ok := msg.(events.BatchProgressMsg); ok {
+				var mine events.BatchProgressMsg
+				for _, p := range batch {
+					if ids[p.DownloadID] {
+						mine = append(mine, p)
+					}
+				}
+				if len(mine) > 0 {
+					out <- mine
+				}
+				continue
+			}
+			if ids[events.DownloadIDForMessage(msg)] {
+				out <- msg
+			}
+		}
+	}()
+	return out
+}
+
+// withCookies returns headers with a Cookie header for rawURL added from
+// cookie, which is either the header value itself or @file naming a Netscape
+// cookie file. Cookies from --header come first.
+func withCookies(headers map[string]string, cookie, rawURL string) (map[string]string, error) {
+	if cookie == "" {
+		return headers, nil
+	}
+	value := cookie
+	if path, ok := strings.CutPrefix(cookie, "@"); ok {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL %q: %w", rawURL, err)
+		}
+		if value, err = readCookieFile(path, u, time.Now()); err != nil {
+			return nil, err
+		}
+		if value == "" {
+			return headers, nil
+		}
+	}
+
+	out := make(map[string]string, len(headers)+1)
+	for k, v := range headers {
+		out[k] = v
+	}
+	if prev := out["Cookie"]; prev != "" {
+		value = prev + "; " + value
+	}
+	out["Cookie"] = value
+	return out, nil
+}
+
+// readCookieFile returns the cookies from a Netscape cookie file, as browser
+// extensions and curl -c write it, that a request to u would send at now.
+func readCookieFile(path string, u *url.URL, now time.Time) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read cookie file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	host := strings.ToLower(u.Hostname())
+	reqPath := u.EscapedPath()
+	if reqPath == "" {
+		reqPath = "/"
+	}
+
+	var cookies []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		// HttpOnly cookies are written as comments by some exporters
+		line = strings.TrimPrefix(line, "#HttpOnly_")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			continue
+		}
+		domain := strings.ToLower(fields[0])
+		subdomains := strings.EqualFold(fields[1], "TRUE")
+		if expires, err := strconv.ParseInt(fields[4], 10, 64); err == nil && expires > 0 && now.Unix() > expires {
+			continue
+		}
+		if strings.EqualFold(fields[3], "TRUE") && u.Scheme != "https" {
+			continue
+		}
+		if !strings.HasPrefix(reqPath, fields[2]) {
+			continue
+		}
+		bare := strings.TrimPrefix(domain, ".")
+		if host != bare && !((subdomains || strings.HasPrefix(domain, ".")) && strings.HasSuffix(host, "."+bare)) {
+			continue
+		}
+		cookies = append(cookies, fields[5]+"="+fields[6])
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read cookie file: %w", err)
+	}
+	return strings.Join(cookies, "; "), nil
+}
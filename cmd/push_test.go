@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/events"
+)
+
+func TestReadCookieFile_MatchesHostPathAndExpiry(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	file := "# Netscape HTTP Cookie File\n" +
+		".example.com\tTRUE\t/\tFALSE\t0\tsession\tabc\n" +
+		"#HttpOnly_cdn.example.com\tFALSE\t/files\tTRUE\t1800000000\tauth\txyz\n" +
+		"cdn.example.com\tFALSE\t/\tFALSE\t1600000000\texpired\t1\n" +
+		"other.org\tFALSE\t/\tFALSE\t0\tforeign\t2\n" +
+		"cdn.example.com\tFALSE\t/private\tFALSE\t0\tprivate\t3\n"
+	path := filepath.Join(t.TempDir(), "cookies.txt")
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	u, _ := url.Parse("https://cdn.example.com/files/big.iso")
+	got, err := readCookieFile(path, u, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "session=abc; auth=xyz"; got != want {
+		t.Fatalf("cookies = %q, want %q", got, want)
+	}
+
+	// Secure cookies are not sent over plain HTTP
+	u.Scheme = "http"
+	if got, _ = readCookieFile(path, u, now); got != "session=abc" {
+		t.Fatalf("cookies over http = %q, want session=abc", got)
+	}
+}
+
+func TestWithCookies_AppendsToHeaderCookie(t *testing.T) {
+	headers := map[string]string{"Cookie": "a=1"}
+	got, err := withCookies(headers, "b=2", "https://example.com/f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["Cookie"] != "a=1; b=2" {
+		t.Fatalf("Cookie = %q, want a=1; b=2", got["Cookie"])
+	}
+	if headers["Cookie"] != "a=1" {
+		t.Fatal("withCookies modified the headers it was given")
+	}
+}
+
+func TestFilterEvents_KeepsOnlyPushedDownloads(t *testing.T) {
+	stream := make(chan interface{}, 4)
+	stream <- events.DownloadStartedMsg{DownloadID: "other"}
+	stream <- events.BatchProgressMsg{{DownloadID: "other"}, {DownloadID: "mine"}}
+	stream <- events.DownloadCompleteMsg{DownloadID: "mine"}
+	close(stream)
+
+	var got []interface{}
+	for msg := range filterEvents(stream, map[string]bool{"mine": true}) {
+		got = append(got, msg)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d events, want 2: %+v", len(got), got)
+	}
+	if batch, ok := got[0].(events.BatchProgressMsg); !ok || len(batch) != 1 || batch[0].DownloadID != "mine" {
+		t.Fatalf("first event = %+v, want the batch narrowed to mine", got[0])
+	}
+}
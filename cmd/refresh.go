@@ -6,23 +6,34 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/SurgeDM/Surge/internal/utils"
 	"github.com/spf13/cobra"
 )
 
 var refreshCmd = &cobra.Command{
-	Use:   "refresh <ID> <NEW_URL>",
-	Short: "Update the URL of a paused or errored download",
-	Long:  `Update the source URL of a download by its ID. It must be paused or in an error state to be refreshed.`,
-	Args:  cobra.ExactArgs(2),
+	Use:   "refresh <ID> [NEW_URL]",
+	Short: "Update the URL or headers of a paused or errored download",
+	Long: `Update the source URL of a download by its ID, or with --header the request headers it is sent with,
+such as a cookie or token whose session expired. It must be paused or in an error state to be refreshed.`,
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeDownloadIDs("paused", "error", "failed"),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		headerArgs, _ := cmd.Flags().GetStringArray("header")
+		headers, err := parseHeaderFlags(headerArgs)
+		if err != nil {
+			return err
+		}
+		if len(args) < 2 && len(headers) == 0 {
+			return fmt.Errorf("give a new URL, --header, or both")
+		}
+
 		if err := initializeGlobalState(); err != nil {
 			return err
 		}
 
 		id := args[0]
-		newURL := args[1]
 
 		baseURL, token, err := resolveAPIConnection(true)
 		if err != nil {
@@ -35,35 +46,67 @@ var refreshCmd = &cobra.Command{
 			return err
 		}
 
-		reqBody := map[string]string{
-			"url": newURL,
+		if len(headers) > 0 {
+			path := fmt.Sprintf("/headers?id=%s", url.QueryEscape(id))
+			if err := putJSON(baseURL, token, path, map[string]any{"headers": headers}); err != nil {
+				return err
+			}
+			fmt.Printf("Successfully updated headers for download %s\n", id[:8])
 		}
-
-		jsonData, err := json.Marshal(reqBody)
-		if err != nil {
-			return fmt.Errorf("error creating request: %w", err)
+		if len(args) == 2 {
+			path := fmt.Sprintf("/update-url?id=%s", url.QueryEscape(id))
+			if err := putJSON(baseURL, token, path, map[string]string{"url": args[1]}); err != nil {
+				return err
+			}
+			fmt.Printf("Successfully updated URL for download %s\n", id[:8])
 		}
+		return nil
+	},
+}
 
-		// Send to running server
-		path := fmt.Sprintf("/update-url?id=%s", url.QueryEscape(id))
-		resp, err := doAPIRequest(http.MethodPut, baseURL, token, path, bytes.NewBuffer(jsonData))
-		if err != nil {
-			return fmt.Errorf("error connecting to server: %w", err)
+// putJSON sends body to the running server with PUT.
+func putJSON(baseURL, token, path string, body any) error {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+
+	// Send to running server
+	resp, err := doAPIRequest(http.MethodPut, baseURL, token, path, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("error connecting to server: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			utils.Debug("Error closing response body: %v", err)
 		}
-		defer func() {
-			if err := resp.Body.Close(); err != nil {
-				utils.Debug("Error closing response body: %v", err)
-			}
-		}()
+	}()
 
-		if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("server returned %s", resp.Status)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+	return nil
+}
+
+// parseHeaderFlags reads --header values given as "Name: value". An empty
+// value removes the header.
+func parseHeaderFlags(values []string) (map[string]string, error) {
+	var headers map[string]string
+	for _, v := range values {
+		name, value, ok := strings.Cut(v, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("header %q: expected Name: value", v)
 		}
-		fmt.Printf("Successfully updated URL for download %s\n", id[:8])
-		return nil
-	},
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		headers[http.CanonicalHeaderKey(name)] = strings.TrimSpace(value)
+	}
+	return headers, nil
 }
 
 func init() {
+	refreshCmd.Flags().StringArrayP("header", "H", nil, `Set a request header, e.g. -H "Cookie: session=abc"; an empty value removes it (repeatable)`)
 	rootCmd.AddCommand(refreshCmd)
 }
@@ -39,7 +39,12 @@ func currentRemoteClientConfig() remoteClientConfig {
 
 func newRemoteDownloadService(baseURL, token string) (*core.RemoteDownloadService, error) {
 	cfg := currentRemoteClientConfig()
-	return core.NewRemoteDownloadService(baseURL, token, cfg.HTTPOptions)
+	service, err := core.NewRemoteDownloadService(baseURL, token, cfg.HTTPOptions)
+	if err != nil {
+		return nil, err
+	}
+	service.Version = Version
+	return service, nil
 }
 
 func newRemoteAPIHTTPClient() (*http.Client, error) {
@@ -10,8 +10,10 @@ import (
 var resumeCmd = &cobra.Command{
 	Use:   "resume <ID>",
 	Short: "Resume a paused download",
-	Long:  `Resume a paused download by its ID. Use --all to resume all paused downloads.`,
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Resume a paused download by its ID. Use --all to resume the downloads
+surge pause --all paused; downloads paused on their own stay paused.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeDownloadIDs("paused", "error", "failed"),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := initializeGlobalState(); err != nil {
 			return err
@@ -24,7 +26,11 @@ var resumeCmd = &cobra.Command{
 		}
 
 		if all {
-			fmt.Println("Resuming all downloads is not yet implemented for running server.")
+			ids, err := executeGlobalPause("/resume-all")
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Resumed %d download(s).\n", len(ids))
 			return nil
 		}
 
@@ -34,5 +40,5 @@ var resumeCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(resumeCmd)
-	resumeCmd.Flags().Bool("all", false, "Resume all paused downloads")
+	resumeCmd.Flags().Bool("all", false, "Resume the downloads paused by pause --all")
 }
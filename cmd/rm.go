@@ -5,15 +5,17 @@ import (
 	"net/http"
 
 	"github.com/SurgeDM/Surge/internal/engine/state"
+	"github.com/SurgeDM/Surge/internal/i18n"
 	"github.com/spf13/cobra"
 )
 
 var rmCmd = &cobra.Command{
-	Use:     "rm <ID>",
-	Aliases: []string{"kill"},
-	Short:   "Remove a download",
-	Long:    `Remove a download by its ID. Use --clean to remove all completed downloads. Use --purge to also delete the file(s) from disk.`,
-	Args:    cobra.MaximumNArgs(1),
+	Use:               "rm <ID>",
+	Aliases:           []string{"kill"},
+	Short:             "Remove a download",
+	Long:              `Remove a download by its ID. Use --clean to remove all completed downloads. Use --purge to also delete the file(s) from disk.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeDownloadIDs(),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := initializeGlobalState(); err != nil {
 			return err
@@ -36,7 +38,7 @@ var rmCmd = &cobra.Command{
 			if err != nil {
 				return fmt.Errorf("error cleaning downloads: %w", err)
 			}
-			fmt.Printf("Removed %d completed downloads.\n", count)
+			fmt.Println(i18n.T("cli.removed_completed", count))
 			return nil
 		}
 
@@ -2,12 +2,14 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"runtime"
 	"runtime/debug"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -17,9 +19,11 @@ import (
 	"github.com/SurgeDM/Surge/internal/config"
 	"github.com/SurgeDM/Surge/internal/core"
 	"github.com/SurgeDM/Surge/internal/download"
+	"github.com/SurgeDM/Surge/internal/engine"
 	"github.com/SurgeDM/Surge/internal/engine/events"
 	"github.com/SurgeDM/Surge/internal/engine/state"
 	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/i18n"
 	"github.com/SurgeDM/Surge/internal/processing"
 	"github.com/SurgeDM/Surge/internal/tui"
 	"github.com/SurgeDM/Surge/internal/utils"
@@ -80,6 +84,7 @@ var (
 	serverProgram           *tea.Program
 	startupIntegrityMessage string
 	globalSettings          *config.Settings
+	globalSettingsMu        sync.RWMutex
 	GlobalLifecycle         *processing.LifecycleManager
 	globalLifecycleMu       sync.Mutex
 	globalEnqueueCtx        context.Context
@@ -136,9 +141,17 @@ func buildActiveDownloadChecker(getAll func() []types.DownloadConfig) processing
 func newLocalLifecycleManager(service core.DownloadService, getAll func() []types.DownloadConfig) *processing.LifecycleManager {
 	var addFunc processing.AddDownloadFunc
 	var addWithIDFunc processing.AddDownloadWithIDFunc
-	if service != nil {
-		addFunc = service.Add
-		addWithIDFunc = service.AddWithID
+	if local, ok := service.(*core.LocalDownloadService); ok {
+		addFunc = local.AddProbed
+		addWithIDFunc = local.AddProbedWithID
+	} else if service != nil {
+		// Only a local service can take over what the probe did
+		addFunc = func(url, path, filename string, mirrors []string, headers map[string]string, isExplicitCategory bool, totalSize int64, supportsRange bool, _ types.ProbeHandoff) (string, error) {
+			return service.Add(url, path, filename, mirrors, headers, isExplicitCategory, totalSize, supportsRange)
+		}
+		addWithIDFunc = func(url, path, filename string, mirrors []string, headers map[string]string, id string, totalSize int64, supportsRange bool, _ types.ProbeHandoff) (string, error) {
+			return service.AddWithID(url, path, filename, mirrors, headers, id, totalSize, supportsRange)
+		}
 	}
 
 	return processing.NewLifecycleManager(addFunc, addWithIDFunc, buildActiveDownloadChecker(getAll))
@@ -153,8 +166,53 @@ func startLifecycleEventWorker(service core.DownloadService, mgr *processing.Lif
 	if err != nil {
 		return nil, err
 	}
-	go mgr.StartEventWorker(managerStream)
-	return managerCleanup, nil
+	workerStream := make(chan interface{})
+	// Notifiers get buffered copies of the stream and miss events rather than
+	// hold up persistence when a broker or mail server is slow.
+	// The hooks script is looked up once, not from each event
+	scriptPath := config.GetScriptPath()
+	notifiers := []func(<-chan interface{}){
+		mgr.StartMQTTPublisher,
+		mgr.StartEmailNotifier,
+		func(ch <-chan interface{}) { mgr.StartScriptHooks(ch, scriptPath) },
+		downloadIdempotency.watchDeclines,
+	}
+	notifyStreams := make([]chan interface{}, len(notifiers))
+	var notifiersDone sync.WaitGroup
+	for i, run := range notifiers {
+		notifyStreams[i] = make(chan interface{}, 64)
+		notifiersDone.Go(func() { run(notifyStreams[i]) })
+	}
+	go func() {
+		defer close(workerStream)
+		defer func() {
+			for _, ch := range notifyStreams {
+				close(ch)
+			}
+		}()
+		for msg := range managerStream {
+			workerStream <- msg
+			for _, ch := range notifyStreams {
+				select {
+				case ch <- msg:
+				default:
+				}
+			}
+		}
+	}()
+	workerDone := make(chan struct{})
+	go func() {
+		defer close(workerDone)
+		mgr.StartEventWorker(workerStream)
+	}()
+	// Wait for the worker to drain what it already received, so a download
+	// reported complete has also been renamed and persisted, and for the
+	// notifiers to stop.
+	return func() {
+		managerCleanup()
+		<-workerDone
+		notifiersDone.Wait()
+	}, nil
 }
 
 func currentLifecycle() *processing.LifecycleManager {
@@ -248,6 +306,7 @@ func ensureGlobalLocalServiceAndLifecycle() error {
 			Cancel:              GlobalPool.Cancel,
 			UpdateURL:           GlobalPool.UpdateURL,
 			PublishEvent:        localService.Publish,
+			TakeTraffic:         GlobalPool.TakeTraffic,
 		})
 
 		localService.SetLifecycleHooks(core.LifecycleHooks{
@@ -256,7 +315,9 @@ func ensureGlobalLocalServiceAndLifecycle() error {
 			ResumeBatch: lifecycle.ResumeBatch,
 			Cancel:      lifecycle.Cancel,
 			UpdateURL:   lifecycle.UpdateURL,
+			Waiting:     lifecycle.Waiting,
 		})
+		engine.DefaultProxyFailovers.SetNotify(publishSystemLog)
 	} else {
 		_, err := ensureLocalLifecycle(GlobalService, currentPoolConfigs)
 		return err
@@ -272,8 +333,10 @@ func publishSystemLog(message string) {
 	fmt.Fprintln(os.Stderr, message)
 }
 
+// recordPreflightDownloadError lists a download that failed before it was
+// queued as errored. One waiting to be confirmed has not failed.
 func recordPreflightDownloadError(url, outPath string, err error) {
-	if err == nil || strings.TrimSpace(url) == "" {
+	if err == nil || errors.Is(err, types.ErrNeedsConfirmation) || strings.TrimSpace(url) == "" {
 		return
 	}
 
@@ -423,6 +486,9 @@ func maybeStartRootHTTPServer(opts rootRunOptions) (int, func(), error) {
 		}
 		return 0, func() {}, nil
 	}
+	if !opts.portSet && !config.Resolve[bool](getSettings().Extension.APIServer) {
+		return 0, func() {}, nil
+	}
 	return startRootHTTPServer(opts)
 }
 
@@ -458,7 +524,14 @@ var rootCmd = &cobra.Command{
 	Args:          cobra.ArbitraryArgs,
 	SilenceErrors: true, //errors are printed in main.go this prevents double printing
 	SilenceUsage:  true, // prevent usage text from being printed on every error
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		overrides := globalSettingOverrides
+		if globalAccessible {
+			overrides = append(slices.Clone(overrides), "general.accessible=true")
+		}
+		if err := config.SetFlagOverrides(overrides); err != nil {
+			return err
+		}
 		if reset, _ := cmd.Flags().GetBool("reset-settings"); reset {
 			err1 := utils.RemoveFile(config.GetSettingsPath())
 			err2 := utils.RemoveFile(config.GetKeyMapConfigPath())
@@ -469,8 +542,14 @@ var rootCmd = &cobra.Command{
 			}
 		}
 		GlobalProgressCh = make(chan any, 100)
-		globalSettings = getSettings()
-		GlobalPool = download.NewWorkerPool(GlobalProgressCh, config.Resolve[int](globalSettings.Network.MaxConcurrentDownloads))
+		settings := getSettings()
+		i18n.SetLocale(config.Resolve[string](settings.General.Language))
+		config.ApplyByteUnits(settings)
+		globalSettingsMu.Lock()
+		globalSettings = settings
+		globalSettingsMu.Unlock()
+		GlobalPool = download.NewWorkerPool(GlobalProgressCh, config.Resolve[int](settings.Network.MaxConcurrentDownloads))
+		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if ranRemote, err := maybeRunRemoteTUI(cmd, args); err != nil {
@@ -512,6 +591,9 @@ func startTUI(port int, exitWhenDone bool, noResume bool) error {
 
 	m := tui.InitialRootModel(port, Version, GlobalService, currentLifecycle(), noResume, Commit)
 	m = m.WithEnqueueContext(currentEnqueueContext(), currentEnqueueCancel())
+	if config.IsFirstRun() {
+		m = m.WithOnboarding()
+	}
 
 	configureServiceUI(&m)
 
@@ -521,7 +603,7 @@ func startTUI(port int, exitWhenDone bool, noResume bool) error {
 	}
 	m.IsRemote = false
 
-	p := tea.NewProgram(m)
+	p := tea.NewProgram(m, m.ProgramOptions()...)
 	serverProgram = p // Save reference for HTTP handler
 
 	// Get event stream from service
@@ -532,12 +614,19 @@ func startTUI(port int, exitWhenDone bool, noResume bool) error {
 	}
 	defer cleanup()
 
-	// Background listener for progress events
-	go func() {
-		for msg := range stream {
-			p.Send(msg)
-		}
-	}()
+	// Background listener for progress events, paced to what is on screen
+	go m.Pacer.Forward(stream, p.Send)
+
+	profileCtx, stopProfileWatch := context.WithCancel(context.Background())
+	defer stopProfileWatch()
+	go watchProfileNetwork(profileCtx, GlobalService)
+	go watchSystemProxy(profileCtx)
+	go watchFeeds(profileCtx, GlobalService)
+	go watchSnoozes(profileCtx, GlobalService)
+	// Downloads that failed last session pick up their retries where they left off
+	if lifecycle := currentLifecycle(); lifecycle != nil {
+		lifecycle.RescheduleRetries()
+	}
 
 	if startupIntegrityMessage != "" && GlobalService != nil {
 		_ = GlobalService.Publish(events.SystemLogMsg{
@@ -620,6 +709,8 @@ func init() {
 	rootCmd.PersistentFlags().BoolVar(&globalInsecureHTTP, "insecure-http", false, "Allow plain HTTP for non-loopback remote targets")
 	rootCmd.PersistentFlags().BoolVar(&globalInsecureTLS, "insecure-tls", false, "Skip TLS certificate verification for remote targets")
 	rootCmd.PersistentFlags().StringVar(&globalTLSCAFile, "tls-ca-file", "", "PEM bundle to trust for remote HTTPS targets")
+	rootCmd.PersistentFlags().BoolVar(&globalAccessible, "accessible", false, "Plain-text interface for screen readers: no icons, boxes or colors (same as --set general.accessible=true)")
+	rootCmd.PersistentFlags().StringArrayVar(&globalSettingOverrides, "set", nil, "Override a setting for this run, e.g. --set network.max_concurrent_downloads=8; repeat for several")
 	rootCmd.Flags().StringP("batch", "b", "", "File containing URLs to download (one per line)")
 	rootCmd.Flags().IntP("port", "p", 0, "Port to listen on (default: 8080 or first available)")
 	rootCmd.Flags().StringP("output", "o", "", "Output directory (defaults to current working directory)")
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -8,6 +9,7 @@ import (
 	"runtime"
 	"strings"
 	"sync/atomic"
+	"time"
 
 	"github.com/SurgeDM/Surge/internal/config"
 	"github.com/SurgeDM/Surge/internal/core"
@@ -20,6 +22,7 @@ import (
 
 // DownloadRequest represents a download request from the browser extension
 type DownloadRequest struct {
+	ID                   string            `json:"id,omitempty"` // Client-chosen download ID; a repeat returns the existing download
 	URL                  string            `json:"url"`
 	Filename             string            `json:"filename,omitempty"`
 	Path                 string            `json:"path,omitempty"`
@@ -28,12 +31,20 @@ type DownloadRequest struct {
 	SkipApproval         bool              `json:"skip_approval,omitempty"` // Extension validated request, skip TUI prompt
 	Headers              map[string]string `json:"headers,omitempty"`       // Custom HTTP headers from browser (cookies, auth, etc.)
 	IsExplicitCategory   bool              `json:"is_explicit_category,omitempty"`
+	TLS                  types.TLSOptions  `json:"tls,omitzero"`      // Overrides the global TLS settings for this download
+	DryRun               bool              `json:"dry_run,omitempty"` // Probe and report the plan without adding the download
+
+	// Method, body and content type for endpoints that need e.g. a POST
+	types.RequestOptions
 }
 
 type BatchDownloadRequest struct {
 	Downloads    []DownloadRequest `json:"downloads"`
 	Path         string            `json:"path,omitempty"`
 	SkipApproval bool              `json:"skip_approval,omitempty"`
+	// Sums writes a SHA256SUMS manifest next to the files once the whole
+	// batch has finished.
+	Sums bool `json:"sums,omitempty"`
 }
 
 type resolvedDownloadRequest struct {
@@ -67,15 +78,88 @@ func handleDownload(w http.ResponseWriter, r *http.Request, defaultOutputDir str
 		return
 	}
 
-	if maybeRequireDownloadApproval(w, service, resolved) {
+	if resolved.request.DryRun {
+		planDownloadRequest(w, r, service, resolved)
+		return
+	}
+
+	// A retried request with the same key gets the download the first one
+	// created instead of a second queue entry.
+	key := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
+	if key == "" {
+		key = resolved.request.ID
+	}
+	var entry *idempotencyEntry
+	for key != "" && entry == nil {
+		e, owner, err := downloadIdempotency.begin(key, resolved.urlForAdd, time.Now())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		if owner {
+			entry = e
+			break
+		}
+		id, filename, err := e.wait(r.Context())
+		if r.Context().Err() != nil {
+			return
+		}
+		if errors.Is(err, errDownloadPendingApproval) {
+			writePendingApproval(w, service, id)
+			return
+		}
+		if err == nil {
+			writeReplayedDownload(w, id, filename)
+			return
+		}
+		// The first request failed, which freed the key; this one tries again.
+	}
+
+	// The key is finished with whatever this request ends with. Anything but
+	// a queued download or one waiting for approval frees it for a retry.
+	var doneID, doneFilename string
+	doneErr := errDownloadNotQueued
+	if entry != nil {
+		defer func() { downloadIdempotency.finish(key, entry, doneID, doneFilename, doneErr) }()
+	}
+
+	approvalID, handled := maybeRequireDownloadApproval(w, service, resolved)
+	if approvalID != "" {
+		doneID, doneErr = approvalID, errDownloadPendingApproval
+	}
+	if handled {
 		return
 	}
 
 	newID, filename, err := enqueueDownloadRequest(r, service, resolved)
+	if errors.Is(err, types.ErrIDExists) {
+		// The client's ID outlived the window, e.g. across a daemon restart.
+		if status, statusErr := service.GetStatus(resolved.request.ID); statusErr == nil && status != nil && status.URL == resolved.urlForAdd {
+			doneID, doneFilename, doneErr = status.ID, status.Filename, nil
+			writeReplayedDownload(w, status.ID, status.Filename)
+			return
+		}
+	}
+	doneID, doneFilename, doneErr = newID, filename, err
+	var large *types.LargeDownloadError
+	if errors.As(err, &large) {
+		// Nothing went wrong, so nothing is recorded as a failed download
+		writeJSONResponse(w, http.StatusConflict, map[string]interface{}{
+			"status":    "confirmation_required",
+			"message":   fmt.Sprintf("%s is %s; send it again with \"confirm\": true to download it", resolved.urlForAdd, utils.ConvertBytesToHumanReadable(large.Size)),
+			"size":      large.Size,
+			"threshold": large.Threshold,
+		})
+		return
+	}
 	if err != nil {
 		recordPreflightDownloadError(resolved.urlForAdd, resolved.outPath, err)
 		publishSystemLog(fmt.Sprintf("Error adding %s: %v", resolved.urlForAdd, err))
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		if errors.Is(err, types.ErrIDExists) {
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
 		return
 	}
 
@@ -88,6 +172,43 @@ func handleDownload(w http.ResponseWriter, r *http.Request, defaultOutputDir str
 	})
 }
 
+// errDownloadNotQueued frees an idempotency key whose request ended without
+// queuing a download.
+var errDownloadNotQueued = errors.New("download was not queued")
+
+// errDownloadPendingApproval keeps the key of a request sent to the TUI for
+// approval, so a retry does not prompt a second time. The key is freed when
+// the TUI declines the request or the idempotency window runs out.
+var errDownloadPendingApproval = errors.New("download is waiting for approval")
+
+// writeReplayedDownload answers a repeated request with the download the
+// first one queued.
+func writeReplayedDownload(w http.ResponseWriter, id, filename string) {
+	w.Header().Set("Idempotent-Replayed", "true")
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"status":   "queued",
+		"message":  "Download already queued",
+		"id":       id,
+		"filename": filename,
+	})
+}
+
+// writePendingApproval answers a repeated request whose first one went to the
+// TUI for approval: with the download once it was approved, otherwise with
+// the same pending approval.
+func writePendingApproval(w http.ResponseWriter, service core.DownloadService, id string) {
+	if status, err := service.GetStatus(id); err == nil && status != nil {
+		writeReplayedDownload(w, status.ID, status.Filename)
+		return
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	writeJSONResponse(w, http.StatusAccepted, map[string]string{
+		"status":  "pending_approval",
+		"message": "Download request is waiting for confirmation in the TUI",
+		"id":      id,
+	})
+}
+
 func handleDownloadStatusRequest(w http.ResponseWriter, r *http.Request, service core.DownloadService) bool {
 	if r.Method != http.MethodGet {
 		return false
@@ -119,13 +240,48 @@ func decodeAndValidateDownloadRequest(r *http.Request) (DownloadRequest, error)
 	if err := decodeJSONBody(r, &req); err != nil {
 		return req, fmt.Errorf("invalid json: %w", err)
 	}
-	return validateDownloadRequest(req)
+	req, err := validateDownloadRequest(req)
+	if err != nil {
+		return req, err
+	}
+	return req, checkTLSFiles(r, req.TLS)
+}
+
+// checkTLSFiles keeps remote API callers from having the server read files
+// of their choosing as a CA or client certificate. The CLI on this machine
+// may name any file; others only files in the config directory.
+func checkTLSFiles(r *http.Request, opts types.TLSOptions) error {
+	if isLocalRequest(r) {
+		return nil
+	}
+	dir, err := filepath.EvalSymlinks(config.GetSurgeDir())
+	if err != nil {
+		dir = filepath.Clean(config.GetSurgeDir())
+	}
+	for _, path := range []string{opts.CAFile, opts.ClientCertFile, opts.ClientKeyFile} {
+		if path == "" {
+			continue
+		}
+		resolved, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return fmt.Errorf("tls file %q: %w", path, err)
+		}
+		rel, err := filepath.Rel(dir, resolved)
+		if err != nil || !filepath.IsLocal(rel) {
+			return fmt.Errorf("tls file %q must be in %s for requests from other hosts", path, config.GetSurgeDir())
+		}
+	}
+	return nil
 }
 
 func validateDownloadRequest(req DownloadRequest) (DownloadRequest, error) {
 	if req.URL == "" {
 		return req, fmt.Errorf("url is required")
 	}
+	req.ID = strings.TrimSpace(req.ID)
+	if len(req.ID) > 64 || strings.ContainsAny(req.ID, "/\\?#& ") {
+		return req, fmt.Errorf("invalid id")
+	}
 	if strings.Contains(req.Filename, "..") {
 		return req, fmt.Errorf("invalid filename")
 	}
@@ -147,6 +303,12 @@ func validateDownloadRequest(req DownloadRequest) (DownloadRequest, error) {
 		}
 		req.Path = cleanPath
 	}
+	if (req.TLS.ClientCertFile == "") != (req.TLS.ClientKeyFile == "") {
+		return req, fmt.Errorf("tls client_cert and client_key must be set together")
+	}
+	if err := req.RequestOptions.Validate(); err != nil {
+		return req, err
+	}
 	return req, nil
 }
 
@@ -169,6 +331,10 @@ func handleBatchDownload(w http.ResponseWriter, r *http.Request, defaultOutputDi
 		http.Error(w, "downloads are required", http.StatusBadRequest)
 		return
 	}
+	if req.Sums && !req.SkipApproval {
+		http.Error(w, "sums needs skip_approval", http.StatusBadRequest)
+		return
+	}
 
 	settings := getSettings()
 	sharedPath := utils.EnsureAbsPath(resolveOutputDir(req.Path, false, defaultOutputDir, settings))
@@ -180,6 +346,9 @@ func handleBatchDownload(w http.ResponseWriter, r *http.Request, defaultOutputDi
 		}
 		item.SkipApproval = req.SkipApproval
 		validated, err := validateDownloadRequest(item)
+		if err == nil {
+			err = checkTLSFiles(r, validated.TLS)
+		}
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
@@ -193,6 +362,8 @@ func handleBatchDownload(w http.ResponseWriter, r *http.Request, defaultOutputDi
 			Path:     itemPath,
 			Mirrors:  mirrorsForAdd,
 			Headers:  validated.Headers,
+			TLS:      validated.TLS,
+			Request:  validated.RequestOptions,
 		})
 	}
 
@@ -221,26 +392,49 @@ func handleBatchDownload(w http.ResponseWriter, r *http.Request, defaultOutputDi
 		return
 	}
 
+	var lifecycle *processing.LifecycleManager
+	if req.Sums {
+		var err error
+		if lifecycle, err = lifecycleForLocalService(service); err != nil || lifecycle == nil {
+			http.Error(w, "sums needs a local download service", http.StatusServiceUnavailable)
+			return
+		}
+		ids := make([]string, len(requests))
+		for i, item := range requests {
+			ids[i] = item.ID
+		}
+		lifecycle.TrackSums(ids)
+	}
+
 	queued := 0
 	var failures []map[string]string
 	for _, item := range requests {
 		resolved := &resolvedDownloadRequest{
 			request: DownloadRequest{
-				URL:          item.URL,
-				Filename:     item.Filename,
-				Path:         item.Path,
-				Mirrors:      item.Mirrors,
-				SkipApproval: true,
-				Headers:      item.Headers,
+				URL:            item.URL,
+				Filename:       item.Filename,
+				Path:           item.Path,
+				Mirrors:        item.Mirrors,
+				SkipApproval:   true,
+				Headers:        item.Headers,
+				TLS:            item.TLS,
+				RequestOptions: item.Request,
 			},
 			settings:      settings,
 			outPath:       item.Path,
 			urlForAdd:     item.URL,
 			mirrorsForAdd: item.Mirrors,
 		}
+		if lifecycle != nil {
+			// The manifest waits for the IDs it was given
+			resolved.request.ID = item.ID
+		}
 		if _, _, err := enqueueDownloadRequest(r, service, resolved); err != nil {
 			recordPreflightDownloadError(item.URL, item.Path, err)
 			publishSystemLog(fmt.Sprintf("Error adding %s: %v", item.URL, err))
+			if lifecycle != nil {
+				lifecycle.UntrackSums(item.ID)
+			}
 			failures = append(failures, map[string]string{
 				"url":   item.URL,
 				"error": err.Error(),
@@ -326,7 +520,10 @@ func resolveDuplicateState(urlForAdd string, settings *config.Settings) (bool, b
 	return dupResult.Exists, dupResult.IsActive
 }
 
-func maybeRequireDownloadApproval(w http.ResponseWriter, service core.DownloadService, resolved *resolvedDownloadRequest) bool {
+// maybeRequireDownloadApproval answers requests that must not be queued
+// straight away, reporting whether it did. approvalID names a request it sent
+// to the TUI for approval.
+func maybeRequireDownloadApproval(w http.ResponseWriter, service core.DownloadService, resolved *resolvedDownloadRequest) (approvalID string, handled bool) {
 	req := resolved.request
 
 	// EXTENSION VETTING SHORTCUT:
@@ -334,12 +531,12 @@ func maybeRequireDownloadApproval(w http.ResponseWriter, service core.DownloadSe
 	// The backend will auto-rename duplicate files, so no need to reject.
 	if req.SkipApproval {
 		utils.Debug("Extension request: skipping all prompts, proceeding with download")
-		return false
+		return "", false
 	}
 
 	shouldPrompt := config.Resolve[bool](resolved.settings.Extension.ExtensionPrompt) || (config.Resolve[bool](resolved.settings.General.WarnOnDuplicate) && resolved.isDuplicate)
 	if !shouldPrompt {
-		return false
+		return "", false
 	}
 
 	if serverProgram != nil {
@@ -353,11 +550,13 @@ func maybeRequireDownloadApproval(w http.ResponseWriter, service core.DownloadSe
 			Path:     resolved.outPath,
 			Mirrors:  resolved.mirrorsForAdd,
 			Headers:  req.Headers,
+			TLS:      req.TLS,
+			Request:  req.RequestOptions,
 		}); err != nil {
 			recordPreflightDownloadError(resolved.urlForAdd, resolved.outPath, err)
 			publishSystemLog(fmt.Sprintf("Error adding %s: %v", resolved.urlForAdd, err))
 			http.Error(w, "Failed to notify TUI: "+err.Error(), http.StatusInternalServerError)
-			return true
+			return "", true
 		}
 
 		writeJSONResponse(w, http.StatusAccepted, map[string]string{
@@ -365,7 +564,7 @@ func maybeRequireDownloadApproval(w http.ResponseWriter, service core.DownloadSe
 			"message": "Download request sent to TUI for confirmation",
 			"id":      downloadID,
 		})
-		return true
+		return downloadID, true
 	}
 
 	// HEADLESS/SERVER MODE:
@@ -374,14 +573,14 @@ func maybeRequireDownloadApproval(w http.ResponseWriter, service core.DownloadSe
 	// no way to display a confirmation prompt in headless mode.
 	if !resolved.isDuplicate {
 		utils.Debug("Headless mode: auto-approving extension request (bypass ExtensionPrompt)")
-		return false
+		return "", false
 	}
 
 	writeJSONResponse(w, http.StatusConflict, map[string]string{
 		"status":  "error",
 		"message": "Download rejected: Duplicate download detected (Headless mode)",
 	})
-	return true
+	return "", true
 }
 
 func enqueueDownloadRequest(r *http.Request, service core.DownloadService, resolved *resolvedDownloadRequest) (string, string, error) {
@@ -392,21 +591,60 @@ func enqueueDownloadRequest(r *http.Request, service core.DownloadService, resol
 
 	req := resolved.request
 	if lifecycle != nil {
-		return lifecycle.Enqueue(r.Context(), &processing.DownloadRequest{
-			URL:                resolved.urlForAdd,
-			Filename:           req.Filename,
-			Path:               resolved.outPath,
-			Mirrors:            resolved.mirrorsForAdd,
-			Headers:            req.Headers,
-			IsExplicitCategory: req.IsExplicitCategory,
-			SkipApproval:       req.SkipApproval,
-		})
+		dr := lifecycleRequest(resolved)
+		if req.ID != "" {
+			return lifecycle.EnqueueWithID(r.Context(), dr, req.ID)
+		}
+		return lifecycle.Enqueue(r.Context(), dr)
 	}
 
+	if req.ID != "" {
+		id, err := service.AddWithID(resolved.urlForAdd, resolved.outPath, req.Filename, resolved.mirrorsForAdd, req.Headers, req.ID, 0, false)
+		return id, req.Filename, err
+	}
 	id, err := service.Add(resolved.urlForAdd, resolved.outPath, req.Filename, resolved.mirrorsForAdd, req.Headers, req.IsExplicitCategory, 0, false)
 	return id, req.Filename, err
 }
 
+// lifecycleRequest is the lifecycle's form of a resolved API request.
+func lifecycleRequest(resolved *resolvedDownloadRequest) *processing.DownloadRequest {
+	req := resolved.request
+	return &processing.DownloadRequest{
+		URL:                resolved.urlForAdd,
+		Filename:           req.Filename,
+		Path:               resolved.outPath,
+		Mirrors:            resolved.mirrorsForAdd,
+		Headers:            req.Headers,
+		IsExplicitCategory: req.IsExplicitCategory,
+		SkipApproval:       req.SkipApproval,
+		TLS:                req.TLS,
+		Request:            req.RequestOptions,
+	}
+}
+
+// planDownloadRequest answers a dry run: the download is probed and its plan
+// returned, but nothing is queued or written.
+func planDownloadRequest(w http.ResponseWriter, r *http.Request, service core.DownloadService, resolved *resolvedDownloadRequest) {
+	lifecycle, err := lifecycleForLocalService(service)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to initialize lifecycle manager: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if lifecycle == nil {
+		http.Error(w, "Service does not support dry runs", http.StatusNotImplemented)
+		return
+	}
+	plan, err := lifecycle.Plan(r.Context(), lifecycleRequest(resolved))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"status": "dry_run",
+		"plan":   plan,
+	})
+}
+
 // processDownloads handles the logic of adding downloads either to local pool or remote server
 // Returns the number of successfully added downloads
 func processDownloads(urls []string, outputDir string, port int) int {
@@ -3,47 +3,96 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"sync/atomic"
+	"time"
 
+	"github.com/SurgeDM/Surge/internal/config"
 	"github.com/SurgeDM/Surge/internal/core"
 	"github.com/SurgeDM/Surge/internal/engine/events"
 	"github.com/SurgeDM/Surge/internal/utils"
 )
 
-// StartHeadlessConsumer starts a goroutine to consume progress messages and log to stdout
-func StartHeadlessConsumer(service core.DownloadService) {
+// StartHeadlessConsumer starts a goroutine to consume progress messages and log to stdout.
+// With showProgress, running downloads also get a live progress bar on stderr
+// when it is a terminal.
+func StartHeadlessConsumer(service core.DownloadService, showProgress bool) {
+	if service == nil {
+		return
+	}
+	stream, cleanup, err := service.StreamEvents(context.Background())
+	if err != nil {
+		utils.Debug("Failed to start event stream: %v", err)
+		return
+	}
+	progress := newHeadlessProgressIfTerminal(showProgress)
 	go func() {
-		if service == nil {
-			return
-		}
-		stream, cleanup, err := service.StreamEvents(context.Background())
-		if err != nil {
-			utils.Debug("Failed to start event stream: %v", err)
-			return
-		}
 		defer cleanup()
+		runHeadlessConsumer(stream, progress, nil)
+	}()
+}
 
-		for msg := range stream {
-			switch m := msg.(type) {
-			case events.DownloadStartedMsg:
-				fmt.Printf("Started: %s [%s]\n", m.Filename, truncateID(m.DownloadID))
-			case events.DownloadCompleteMsg:
-				atomic.AddInt32(&activeDownloads, -1)
-				fmt.Printf("Completed: %s [%s] (in %s)\n", m.Filename, truncateID(m.DownloadID), m.Elapsed)
-			case events.DownloadErrorMsg:
-				atomic.AddInt32(&activeDownloads, -1)
-				fmt.Printf("Error: %s [%s]: %v\n", m.Filename, truncateID(m.DownloadID), m.Err)
-			case events.DownloadQueuedMsg:
-				fmt.Printf("Queued: %s [%s]\n", m.Filename, truncateID(m.DownloadID))
-			case events.DownloadPausedMsg:
-				fmt.Printf("Paused: %s [%s]\n", m.Filename, truncateID(m.DownloadID))
-			case events.DownloadResumedMsg:
-				fmt.Printf("Resumed: %s [%s]\n", m.Filename, truncateID(m.DownloadID))
-			case events.DownloadRemovedMsg:
-				fmt.Printf("Removed: %s [%s]\n", m.Filename, truncateID(m.DownloadID))
+func newHeadlessProgressIfTerminal(showProgress bool) *headlessProgress {
+	// Redrawn bars are noise to a screen reader; the log lines are enough
+	if showProgress && stderrIsTerminal() && !config.Resolve[bool](getSettings().General.Accessible) {
+		return newHeadlessProgress(os.Stderr)
+	}
+	return nil
+}
+
+// runHeadlessConsumer logs the events on stream until it closes. handle, when
+// set, is called with each event after it has been logged.
+func runHeadlessConsumer(stream <-chan interface{}, progress *headlessProgress, handle func(msg interface{})) {
+	// logf prints a log line above the progress bars.
+	logf := func(format string, args ...any) {
+		progress.clear()
+		fmt.Printf(format, args...)
+		progress.draw()
+	}
+
+	for msg := range stream {
+		switch m := msg.(type) {
+		case events.ProgressMsg:
+			progress.update(m)
+		case events.BatchProgressMsg:
+			for _, p := range m {
+				progress.update(p)
+			}
+		case events.DownloadVerifyingMsg:
+			progress.verify(m)
+		case events.DownloadStartedMsg:
+			progress.start(m.DownloadID, m.Filename, m.Total)
+			logf("Started: %s [%s]\n", m.Filename, truncateID(m.DownloadID))
+		case events.DownloadCompleteMsg:
+			atomic.AddInt32(&activeDownloads, -1)
+			progress.finish(m.DownloadID, true)
+			logf("Completed: %s [%s] (in %s)\n", m.Filename, truncateID(m.DownloadID), m.Elapsed)
+		case events.DownloadErrorMsg:
+			atomic.AddInt32(&activeDownloads, -1)
+			progress.finish(m.DownloadID, false)
+			logf("Error: %s [%s]: %v\n", m.Filename, truncateID(m.DownloadID), m.Err)
+		case events.DownloadQueuedMsg:
+			if m.WaitingForNetwork {
+				logf("Waiting for network: %s [%s]\n", m.Filename, truncateID(m.DownloadID))
+				break
 			}
+			logf("Queued: %s [%s]\n", m.Filename, truncateID(m.DownloadID))
+		case events.DownloadPausedMsg:
+			progress.remove(m.DownloadID)
+			logf("Paused: %s [%s]\n", m.Filename, truncateID(m.DownloadID))
+		case events.DownloadResumedMsg:
+			logf("Resumed: %s [%s]\n", m.Filename, truncateID(m.DownloadID))
+		case events.DownloadRemovedMsg:
+			progress.remove(m.DownloadID)
+			logf("Removed: %s [%s]\n", m.Filename, truncateID(m.DownloadID))
 		}
-	}()
+		if handle != nil {
+			handle(msg)
+		}
+	}
+	progress.clear()
 }
 
 // truncateID shortens a UUID to its first 8 characters for display
@@ -53,3 +102,216 @@ func truncateID(id string) string {
 	}
 	return id
 }
+
+func stderrIsTerminal() bool {
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := os.Stderr.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// headlessRedrawInterval limits how often the progress bars are repainted.
+const headlessRedrawInterval = 200 * time.Millisecond
+
+// headlessProgress keeps one wget-style progress line per running download
+// and repaints them in place at the bottom of the terminal. A nil
+// *headlessProgress ignores every call, so callers need not check whether
+// progress display is on.
+type headlessProgress struct {
+	out      io.Writer
+	bars     map[string]*headlessBar
+	order    []string
+	drawn    int
+	lastDraw time.Time
+
+	// A batch of known size also counts its finished downloads in the total
+	batch         int
+	finished      int
+	finishedBytes int64
+}
+
+type headlessBar struct {
+	name       string
+	downloaded int64
+	total      int64
+	speed      float64
+	conns      int
+	verifying  bool // downloaded counts the bytes checked instead
+}
+
+func newHeadlessProgress(out io.Writer) *headlessProgress {
+	return &headlessProgress{out: out, bars: make(map[string]*headlessBar)}
+}
+
+func (p *headlessProgress) bar(id string) *headlessBar {
+	b, ok := p.bars[id]
+	if !ok {
+		b = &headlessBar{name: truncateID(id)}
+		p.bars[id] = b
+		p.order = append(p.order, id)
+	}
+	return b
+}
+
+func (p *headlessProgress) start(id, name string, total int64) {
+	if p == nil {
+		return
+	}
+	b := p.bar(id)
+	if name != "" {
+		b.name = name
+	}
+	b.total = total
+}
+
+func (p *headlessProgress) update(m events.ProgressMsg) {
+	if p == nil {
+		return
+	}
+	b := p.bar(m.DownloadID)
+	b.downloaded, b.speed, b.conns = m.Downloaded, m.Speed, m.ActiveConnections
+	if m.Total > 0 {
+		b.total = m.Total
+	}
+	if time.Since(p.lastDraw) >= headlessRedrawInterval {
+		p.clear()
+		p.draw()
+	}
+}
+
+// verify shows a fetched download being checked; its bar restarts and
+// fills as the file is read back.
+func (p *headlessProgress) verify(m events.DownloadVerifyingMsg) {
+	if p == nil {
+		return
+	}
+	b := p.bar(m.DownloadID)
+	b.verifying = true
+	b.downloaded, b.speed, b.conns = m.Checked, 0, 0
+	if m.Total > 0 {
+		b.total = m.Total
+	}
+	if time.Since(p.lastDraw) >= headlessRedrawInterval {
+		p.clear()
+		p.draw()
+	}
+}
+
+// expect sets how many downloads the batch being watched holds.
+func (p *headlessProgress) expect(n int) {
+	if p == nil {
+		return
+	}
+	p.batch = n
+}
+
+// finish removes the bar of a download that completed or failed, keeping
+// what it fetched in the total when it completed.
+func (p *headlessProgress) finish(id string, ok bool) {
+	if p == nil {
+		return
+	}
+	p.finished++
+	if b, seen := p.bars[id]; seen && ok {
+		p.finishedBytes += max(b.total, b.downloaded)
+	}
+	p.remove(id)
+}
+
+func (p *headlessProgress) remove(id string) {
+	if p == nil {
+		return
+	}
+	if _, ok := p.bars[id]; !ok {
+		return
+	}
+	delete(p.bars, id)
+	for i, other := range p.order {
+		if other == id {
+			p.order = append(p.order[:i], p.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// clear erases the bars drawn last, leaving the cursor where they started.
+func (p *headlessProgress) clear() {
+	if p == nil || p.drawn == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(p.out, "\x1b[%dA\r\x1b[J", p.drawn)
+	p.drawn = 0
+}
+
+func (p *headlessProgress) draw() {
+	if p == nil {
+		return
+	}
+	var sb strings.Builder
+	total := headlessBar{name: fmt.Sprintf("Total (%d)", len(p.order))}
+	if p.batch > 0 {
+		total.name = fmt.Sprintf("Total (%d/%d done)", p.finished, p.batch)
+		total.downloaded, total.total = p.finishedBytes, p.finishedBytes
+	}
+	for _, id := range p.order {
+		b := p.bars[id]
+		sb.WriteString(b.render())
+		sb.WriteString("\n")
+		total.downloaded += b.downloaded
+		total.total += b.total
+		total.speed += b.speed
+		total.conns += b.conns
+	}
+	p.drawn = len(p.order)
+	// Several downloads also get a line summing them up.
+	if len(p.order) > 1 || p.batch > 1 && len(p.order) > 0 {
+		sb.WriteString(total.render())
+		sb.WriteString("\n")
+		p.drawn++
+	}
+	_, _ = io.WriteString(p.out, sb.String())
+	p.lastDraw = time.Now()
+}
+
+const (
+	headlessNameWidth = 24
+	headlessBarWidth  = 25
+)
+
+// render formats the bar as one terminal line, e.g.
+// "ubuntu.iso   42% [==========>              ] 1.2 GB/3.0 GB 12 MB/s eta 2m31s 8 conns".
+func (b *headlessBar) render() string {
+	name := []rune(b.name)
+	if len(name) > headlessNameWidth {
+		name = append(name[:headlessNameWidth-1], '~')
+	}
+
+	var line strings.Builder
+	fmt.Fprintf(&line, "%-*s ", headlessNameWidth, string(name))
+	if b.total > 0 {
+		fraction := min(float64(b.downloaded)/float64(b.total), 1)
+		filled := int(fraction * headlessBarWidth)
+		bar := strings.Repeat("=", filled)
+		if filled < headlessBarWidth {
+			bar += ">" + strings.Repeat(" ", headlessBarWidth-filled-1)
+		}
+		fmt.Fprintf(&line, "%3.0f%% [%s] %s/%s", fraction*100, bar,
+			utils.ConvertBytesToHumanReadable(b.downloaded), utils.ConvertBytesToHumanReadable(b.total))
+	} else {
+		line.WriteString(utils.ConvertBytesToHumanReadable(b.downloaded))
+	}
+	if b.verifying {
+		line.WriteString(" verifying")
+		return line.String()
+	}
+	fmt.Fprintf(&line, " %s", utils.FormatSpeed(b.speed))
+	if b.total > 0 && b.speed > 0 && b.downloaded < b.total {
+		eta := time.Duration(float64(b.total-b.downloaded) / b.speed * float64(time.Second))
+		fmt.Fprintf(&line, " eta %s", eta.Truncate(time.Second))
+	}
+	if b.conns > 0 {
+		fmt.Fprintf(&line, " %d conns", b.conns)
+	}
+	return line.String()
+}
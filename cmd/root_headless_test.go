@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/SurgeDM/Surge/internal/engine/events"
+)
+
+func TestHeadlessBar_Render(t *testing.T) {
+	b := &headlessBar{name: "ubuntu-24.04-desktop-amd64.iso", downloaded: 50 << 20, total: 200 << 20, speed: 10 << 20, conns: 8}
+	got := b.render()
+	for _, want := range []string{"ubuntu-24.04-desktop-am~ ", " 25% [======>", "/210 MB", "eta 15s", "8 conns"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("render() = %q, missing %q", got, want)
+		}
+	}
+
+	unknown := (&headlessBar{name: "stream", downloaded: 1000}).render()
+	if strings.Contains(unknown, "%") || strings.Contains(unknown, "eta") {
+		t.Errorf("render() without a total = %q, want no percentage or ETA", unknown)
+	}
+}
+
+func TestHeadlessProgress_RedrawsInPlace(t *testing.T) {
+	var out bytes.Buffer
+	p := newHeadlessProgress(&out)
+	p.start("a", "a.bin", 100)
+	p.update(events.ProgressMsg{DownloadID: "a", Downloaded: 10})
+	p.update(events.ProgressMsg{DownloadID: "b", Downloaded: 5, Total: 10})
+	if p.drawn != 1 {
+		t.Fatalf("drawn = %d, want 1 (second update falls inside the redraw interval)", p.drawn)
+	}
+
+	p.clear()
+	p.draw()
+	if !strings.Contains(out.String(), "\x1b[1A\r\x1b[J") || p.drawn != 3 {
+		t.Fatalf("output %q, drawn %d; want the old bar erased and two bars plus a total drawn", out.String(), p.drawn)
+	}
+	if !strings.Contains(out.String(), "Total (2)                 14% [===>") {
+		t.Errorf("output %q is missing the aggregate line", out.String())
+	}
+
+	var nilProgress *headlessProgress
+	nilProgress.update(events.ProgressMsg{DownloadID: "a"})
+	nilProgress.clear()
+}
+
+func TestHeadlessProgress_TotalCountsTheBatch(t *testing.T) {
+	var out bytes.Buffer
+	p := newHeadlessProgress(&out)
+	p.expect(3)
+	p.start("a", "a.bin", 100)
+	p.start("b", "b.bin", 100)
+	p.update(events.ProgressMsg{DownloadID: "a", Downloaded: 100})
+	p.finish("a", true)
+	p.update(events.ProgressMsg{DownloadID: "b", Downloaded: 50})
+
+	p.clear()
+	p.draw()
+	if p.drawn != 2 {
+		t.Fatalf("drawn = %d, want the running bar and a total", p.drawn)
+	}
+	if !strings.Contains(out.String(), "Total (1/3 done)          75% [==================>") {
+		t.Errorf("output %q is missing the batch total", out.String())
+	}
+}
@@ -79,8 +79,10 @@ func startHTTPServer(ln net.Listener, port int, defaultOutputDir string, service
 	mux := http.NewServeMux()
 	registerHTTPRoutes(mux, port, defaultOutputDir, service)
 
-	// Wrap mux with Auth and CORS (CORS outermost to ensure 401/403 include headers)
-	handler := corsMiddleware(authMiddleware(authToken, mux))
+	// Wrap mux with Auth and CORS (CORS outermost to ensure 401/403 include headers).
+	// Auditing sits outside auth so rejected changes are logged too.
+	audit := core.NewAuditLog(auditLogPath())
+	handler := versionMiddleware(corsMiddleware(auditMiddleware(audit, authMiddleware(authToken, mux))))
 
 	server := &http.Server{Handler: handler}
 	if err := server.Serve(ln); err != nil && err != http.ErrServerClosed {
@@ -88,6 +90,15 @@ func startHTTPServer(ln net.Listener, port int, defaultOutputDir string, service
 	}
 }
 
+// versionMiddleware tells clients which Surge and API version answered, so a
+// mismatched CLI can say so instead of failing on an unexpected response.
+func versionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		core.SetVersionHeaders(w.Header(), Version)
+		next.ServeHTTP(w, r)
+	})
+}
+
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Set CORS headers
@@ -95,6 +106,7 @@ func corsMiddleware(next http.Handler) http.Handler {
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS, PUT, PATCH")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, Access-Control-Allow-Private-Network")
 		w.Header().Set("Access-Control-Allow-Private-Network", "true")
+		w.Header().Set("Access-Control-Expose-Headers", core.VersionHeader+", "+core.APIVersionHeader)
 
 		// Handle preflight requests
 		if r.Method == "OPTIONS" {
@@ -132,6 +144,22 @@ func authMiddleware(token string, next http.Handler) http.Handler {
 			}
 		}
 
+		// Pulled files are fetched with a link signed for that one file
+		if validFileSignature(r, token) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Shared files are also fetched by browsers and tools like wget,
+		// which send the token as the password of basic auth.
+		if strings.HasPrefix(r.URL.Path, filesPrefix) {
+			if _, password, ok := r.BasicAuth(); ok && len(password) == len(token) && subtle.ConstantTimeCompare([]byte(password), []byte(token)) == 1 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("WWW-Authenticate", `Basic realm="Surge", charset="UTF-8"`)
+		}
+
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 	})
 }
@@ -473,7 +473,7 @@ func TestProcessDownloads_UsesSharedEnqueueContext(t *testing.T) {
 
 	dispatchCalled := false
 	GlobalLifecycle = processing.NewLifecycleManager(
-		func(string, string, string, []string, map[string]string, bool, int64, bool) (string, error) {
+		func(string, string, string, []string, map[string]string, bool, int64, bool, types.ProbeHandoff) (string, error) {
 			dispatchCalled = true
 			return "", nil
 		},
@@ -3,9 +3,12 @@ package cmd
 import (
 	"fmt"
 	"path/filepath"
+	"slices"
 	"sync/atomic"
 
 	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/core"
+	"github.com/SurgeDM/Surge/internal/engine"
 	"github.com/SurgeDM/Surge/internal/engine/state"
 	"github.com/SurgeDM/Surge/internal/utils"
 )
@@ -62,8 +65,11 @@ func initializeGlobalState() error {
 }
 
 func getSettings() *config.Settings {
-	if globalSettings != nil {
-		return globalSettings
+	globalSettingsMu.RLock()
+	cached := globalSettings
+	globalSettingsMu.RUnlock()
+	if cached != nil {
+		return cached
 	}
 	settings, err := config.LoadSettings()
 	if err != nil {
@@ -72,6 +78,37 @@ func getSettings() *config.Settings {
 	return settings
 }
 
+// reloadSettings re-reads the layered settings and applies them to the
+// running instance without touching active downloads: the pool picks up new
+// rate and concurrency limits, the lifecycle manager new routing and notifier
+// settings, and idle transports are dropped so the next download dials with
+// the current proxy, DNS and TLS settings.
+func reloadSettings(service core.DownloadService) (*config.Settings, error) {
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+	if reloader, ok := service.(settingsReloader); ok {
+		if err := reloader.ReloadSettings(); err != nil {
+			return nil, err
+		}
+	}
+	if lifecycle := currentLifecycle(); lifecycle != nil {
+		lifecycle.ApplySettings(settings)
+	}
+	config.ApplyByteUnits(settings)
+	globalSettingsMu.Lock()
+	globalSettings = settings
+	globalSettingsMu.Unlock()
+	engine.DefaultNetworkPool.EvictIdle()
+
+	for _, warning := range settings.StartupWarnings {
+		utils.Debug("Settings reload: %s", warning)
+	}
+	utils.Debug("Settings reloaded")
+	return settings, nil
+}
+
 func resumePausedDownloads() {
 	settings := getSettings()
 
@@ -79,8 +116,15 @@ func resumePausedDownloads() {
 	if err != nil {
 		return
 	}
+	// Downloads paused by pause --all wait for resume --all, and snoozed
+	// ones for watchSnoozes
+	globalPaused, _ := state.GlobalPaused()
+	snoozes, _ := state.Snoozes()
 
 	for _, entry := range pausedEntries {
+		if _, snoozed := snoozes[entry.ID]; snoozed || slices.Contains(globalPaused, entry.ID) {
+			continue
+		}
 		// If entry is explicitly queued, we should start it regardless of AutoResume setting
 		// If entry is paused, we only start it if AutoResume is enabled
 		if entry.Status == "paused" && !config.Resolve[bool](settings.General.AutoResume) {
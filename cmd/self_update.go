@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine"
+	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/stream"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+	"github.com/SurgeDM/Surge/internal/version"
+	"github.com/spf13/cobra"
+)
+
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Update Surge to the latest release",
+	Long: `Download the latest Surge release for this platform and replace the
+running binary with it.
+
+The release archive is fetched by Surge's own engine, without the plugins,
+hooks or routing rules other downloads go through, and must match the
+SHA-256 listed in the release's checksums file, whose minisign signature
+must verify against the release key built into this binary, before
+anything is replaced.
+The nightly channel also considers pre-releases. Surge must not be running.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		channel, _ := cmd.Flags().GetString("channel")
+		force, _ := cmd.Flags().GetBool("force")
+
+		if err := initializeGlobalState(); err != nil {
+			return err
+		}
+
+		// A running instance would go on running the old binary, and on
+		// Windows keeps it from being replaced at all.
+		isMaster, err := AcquireLock()
+		if err != nil {
+			return fmt.Errorf("error acquiring lock: %w", err)
+		}
+		if !isMaster {
+			return fmt.Errorf("surge is running; quit it before updating")
+		}
+		defer func() {
+			if err := ReleaseLock(); err != nil {
+				utils.Debug("Error releasing lock: %v", err)
+			}
+		}()
+
+		release, err := version.FetchRelease(channel)
+		if err != nil {
+			return err
+		}
+		if !force && !version.IsUpdate(Version, release, channel) {
+			fmt.Printf("Surge %s is up to date.\n", Version)
+			return nil
+		}
+
+		name := version.ArchiveName(release.TagName, runtime.GOOS, runtime.GOARCH)
+		archive, ok := release.Asset(name)
+		if !ok {
+			return fmt.Errorf("release %s has no build for %s/%s", release.TagName, runtime.GOOS, runtime.GOARCH)
+		}
+		sums, ok := release.ChecksumsAsset()
+		if !ok {
+			return fmt.Errorf("release %s has no checksums file, refusing to install it", release.TagName)
+		}
+		sig, ok := release.ChecksumsSignatureAsset()
+		if !ok {
+			return fmt.Errorf("release %s has no signed checksums file, refusing to install it", release.TagName)
+		}
+		checksum, err := fetchVerifiedChecksum(sums.URL, sig.URL, name)
+		if err != nil {
+			return err
+		}
+
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("cannot locate the running binary: %w", err)
+		}
+		if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+			exe = resolved
+		}
+
+		dir, err := os.MkdirTemp("", "surge-update-*")
+		if err != nil {
+			return err
+		}
+		defer func() { _ = os.RemoveAll(dir) }()
+
+		fmt.Printf("Downloading Surge %s (%s)...\n", release.TagName, channel)
+		downloaded := filepath.Join(dir, name)
+		if err := fetchReleaseArchive(archive.URL, downloaded, checksum); err != nil {
+			return fmt.Errorf("update download failed: %w", err)
+		}
+
+		if err := version.InstallFromArchive(downloaded, exe); err != nil {
+			return fmt.Errorf("failed to replace %s: %w", exe, err)
+		}
+		fmt.Printf("Updated Surge %s -> %s\n", Version, release.TagName)
+		return nil
+	},
+}
+
+// fetchVerifiedChecksum downloads a release's checksums file and its
+// signature through the engine's transport, so proxy and TLS settings apply,
+// and returns the checksum listed for name once the signature verifies
+// against the release key.
+func fetchVerifiedChecksum(sumsURL, sigURL, name string) (string, error) {
+	runtime := getSettings().ToRuntimeConfig()
+	transport, err := engine.DefaultNetworkPool.AcquireTransportFor(runtime, types.PoolMaxConnsPerHost)
+	if err != nil {
+		return "", fmt.Errorf("failed to configure TLS: %w", err)
+	}
+	defer engine.DefaultNetworkPool.ReleaseTransport(transport)
+	client := &http.Client{Transport: transport, CheckRedirect: engine.RedirectPolicy(runtime, nil), Timeout: version.RequestTimeout}
+
+	sums, err := fetchReleaseFile(client, sumsURL, runtime.GetUserAgent())
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch checksums: %w", err)
+	}
+	sig, err := fetchReleaseFile(client, sigURL, runtime.GetUserAgent())
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch checksums signature: %w", err)
+	}
+	if err := version.VerifyChecksums(sums, sig); err != nil {
+		return "", err
+	}
+	return version.ChecksumFor(sums, name)
+}
+
+func fetchReleaseFile(client *http.Client, rawurl, userAgent string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, rawurl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// fetchReleaseArchive downloads the release archive to path and checks it
+// against checksum. Proxy and TLS settings apply, but not the plugins, hooks,
+// copies or routing rules of a queued download, any of which could move or
+// rename the archive before it is installed.
+func fetchReleaseArchive(rawurl, path, checksum string) error {
+	runtime := getSettings().ToRuntimeConfig()
+	transport, err := engine.DefaultNetworkPool.AcquireTransportFor(runtime, types.PoolMaxConnsPerHost)
+	if err != nil {
+		return fmt.Errorf("failed to configure TLS: %w", err)
+	}
+	defer engine.DefaultNetworkPool.ReleaseTransport(transport)
+
+	conns := runtime.GetMaxConnectionsPerDownload()
+	d := &stream.Downloader{
+		Client:      &http.Client{Transport: transport, CheckRedirect: engine.RedirectPolicy(runtime, nil)},
+		UserAgent:   runtime.GetUserAgent(),
+		Connections: conns,
+		MaxRetries:  runtime.GetMaxTaskRetries(),
+	}
+	name := filepath.Base(path)
+	progress := newHeadlessProgressIfTerminal(true)
+	progress.start(name, name, 0)
+	start := time.Now()
+	d.Progress = func(written, total int64) {
+		progress.update(events.ProgressMsg{
+			DownloadID:        name,
+			Downloaded:        written,
+			Total:             total,
+			Speed:             float64(written) / max(time.Since(start).Seconds(), 0.001),
+			ActiveConnections: conns,
+		})
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	f, err := os.Create(path)
+	if err != nil {
+		progress.clear()
+		return err
+	}
+	_, err = d.Download(ctx, rawurl, f)
+	progress.clear()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return engine.VerifyChecksum(ctx, path, checksum, nil)
+}
+
+func init() {
+	rootCmd.AddCommand(selfUpdateCmd)
+	selfUpdateCmd.Flags().String("channel", version.ChannelStable, "Release channel: stable or nightly")
+	selfUpdateCmd.Flags().Bool("force", false, "Install the release even if it is not newer")
+}
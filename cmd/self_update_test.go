@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFetchReleaseArchive(t *testing.T) {
+	body := []byte("release archive bytes")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	sum := sha256.Sum256(body)
+	path := filepath.Join(t.TempDir(), "surge_1.2.0_linux_amd64.tar.gz")
+	if err := fetchReleaseArchive(server.URL+"/archive", path, "sha256:"+hex.EncodeToString(sum[:])); err != nil {
+		t.Fatalf("fetchReleaseArchive: %v", err)
+	}
+	if got, err := os.ReadFile(path); err != nil || string(got) != string(body) {
+		t.Errorf("archive = %q, %v; want %q", got, err, body)
+	}
+
+	if err := fetchReleaseArchive(server.URL+"/archive", path, "sha256:"+hex.EncodeToString(make([]byte, sha256.Size))); err == nil {
+		t.Error("archive with the wrong checksum was accepted")
+	}
+}
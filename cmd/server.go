@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
 	"os"
@@ -60,6 +61,7 @@ var serverStartCmd = &cobra.Command{
 		outputDir, _ := cmd.Flags().GetString("output")
 		exitWhenDone, _ := cmd.Flags().GetBool("exit-when-done")
 		noResume, _ := cmd.Flags().GetBool("no-resume")
+		noProgress, _ := cmd.Flags().GetBool("no-progress")
 
 		// Save current PID to file
 		savePID()
@@ -67,7 +69,7 @@ var serverStartCmd = &cobra.Command{
 
 		// Get token flag
 		tokenFlag := resolveServerToken(cmd)
-		return startServerLogic(cmd, args, portFlag, batchFile, outputDir, exitWhenDone, noResume, tokenFlag)
+		return startServerLogic(cmd, args, portFlag, batchFile, outputDir, exitWhenDone, noResume, noProgress, tokenFlag)
 	},
 }
 
@@ -139,6 +141,7 @@ func init() {
 	serverCmd.PersistentFlags().StringP("output", "o", "", "Output directory (defaults to current working directory)")
 	serverCmd.PersistentFlags().Bool("exit-when-done", false, "Exit when all downloads complete")
 	serverCmd.PersistentFlags().Bool("no-resume", false, "Do not auto-resume paused downloads on startup")
+	serverCmd.PersistentFlags().Bool("no-progress", false, "Do not draw progress bars, only log lines (bars are off anyway when stderr is not a terminal)")
 	serverCmd.PersistentFlags().String("token", "", "Auth token for API clients (or set SURGE_TOKEN)")
 }
 
@@ -171,7 +174,7 @@ func readPID() int {
 	return pid
 }
 
-func startServerLogic(cmd *cobra.Command, args []string, portFlag int, batchFile string, outputDir string, exitWhenDone bool, noResume bool, tokenOverride string) error {
+func startServerLogic(cmd *cobra.Command, args []string, portFlag int, batchFile string, outputDir string, exitWhenDone bool, noResume bool, noProgress bool, tokenOverride string) error {
 	port, listener, err := bindServerListener(portFlag)
 	if err != nil {
 		return err
@@ -197,15 +200,28 @@ func startServerLogic(cmd *cobra.Command, args []string, portFlag int, batchFile
 	fmt.Printf("Serving on %s:%d\n", host, port)
 	fmt.Println("Press Ctrl+C to exit.")
 
-	StartHeadlessConsumer(GlobalService)
+	StartHeadlessConsumer(GlobalService, !noProgress)
+
+	profileCtx, stopProfileWatch := context.WithCancel(context.Background())
+	defer stopProfileWatch()
+	go watchProfileNetwork(profileCtx, GlobalService)
+	go watchSystemProxy(profileCtx)
+	go watchFeeds(profileCtx, GlobalService)
+	go watchSnoozes(profileCtx, GlobalService)
+	// Downloads that failed last session pick up their retries where they left off
+	if lifecycle := currentLifecycle(); lifecycle != nil {
+		lifecycle.RescheduleRetries()
+	}
 
 	// Auto-resume paused downloads (unless --no-resume)
 	if !noResume {
 		resumePausedDownloads()
 	}
 
+	// Stays nil without --exit-when-done, so it never fires.
+	var exitWhenDoneCh chan struct{}
 	if exitWhenDone {
-		exitWhenDoneCh := make(chan struct{}, 1)
+		exitWhenDoneCh = make(chan struct{}, 1)
 		go func() {
 			time.Sleep(2 * time.Second)
 			ticker := time.NewTicker(2 * time.Second)
@@ -222,13 +238,19 @@ func startServerLogic(cmd *cobra.Command, args []string, portFlag int, batchFile
 				}
 			}
 		}()
+	}
 
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
-		defer signal.Stop(sigChan)
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(sigChan)
 
+	for {
 		select {
 		case sig := <-sigChan:
+			if sig == syscall.SIGHUP {
+				reloadServerSettings()
+				continue
+			}
 			fmt.Printf("\nReceived %s. Shutting down...\n", sig)
 			_ = executeGlobalShutdown(fmt.Sprintf("server signal: %s", sig))
 		case <-cmd.Context().Done():
@@ -240,20 +262,18 @@ func startServerLogic(cmd *cobra.Command, args []string, portFlag int, batchFile
 		}
 		return nil
 	}
+}
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
-	defer signal.Stop(sigChan)
-
-	select {
-	case sig := <-sigChan:
-		fmt.Printf("\nReceived %s. Shutting down...\n", sig)
-		_ = executeGlobalShutdown(fmt.Sprintf("server signal: %s", sig))
-	case <-cmd.Context().Done():
-		fmt.Printf("\nService stop requested. Shutting down...\n")
-		_ = executeGlobalShutdown("server: service context cancelled")
+// reloadServerSettings handles SIGHUP the way daemons usually do: settings
+// are re-read and applied while downloads keep running.
+func reloadServerSettings() {
+	settings, err := reloadSettings(GlobalService)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to reload settings: %v\n", err)
+		return
 	}
-	return nil
+	fmt.Println("Reloaded settings.")
+	printSettingsWarnings(settings.StartupWarnings)
 }
 
 func resolveServerToken(cmd *cobra.Command) string {
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/core"
+	"github.com/SurgeDM/Surge/internal/engine/state"
+	"github.com/SurgeDM/Surge/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// snoozeCheckInterval is how often the daemon looks for snoozes that are due.
+const snoozeCheckInterval = 15 * time.Second
+
+var snoozeCmd = &cobra.Command{
+	Use:   "snooze <ID> <time|duration>",
+	Short: "Pause a download and resume it later",
+	Long: `Pause a download and resume it automatically at a given time: a duration
+such as 2h, a clock time such as 23:30 meaning its next occurrence, or a date
+and time such as 2026-03-01 02:00. The snooze survives restarts; resuming the
+download by hand cancels it.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeDownloadIDs("downloading", "queued", "paused"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := initializeGlobalState(); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		until, err := utils.ParseWakeTime(args[1], now)
+		if err != nil {
+			return err
+		}
+
+		baseURL, token, err := resolveAPIConnection(true)
+		if err != nil {
+			return fmt.Errorf("failed to connect to Surge server: %w", err)
+		}
+		id, err := resolveDownloadID(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to resolve download ID: %w", err)
+		}
+
+		query := url.Values{"id": {id}, "until": {until.Format(time.RFC3339)}}
+		resp, err := doAPIRequest(http.MethodPost, baseURL, token, "/snooze?"+query.Encode(), nil)
+		if err != nil {
+			return fmt.Errorf("failed to send request to server: %w", err)
+		}
+		defer func() {
+			if err := resp.Body.Close(); err != nil {
+				utils.Debug("Error closing response body: %v", err)
+			}
+		}()
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			return fmt.Errorf("server error: %s - %s", resp.Status, string(body))
+		}
+
+		fmt.Printf("Snoozed download until %s\n", utils.FormatFinishTime(until, now))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(snoozeCmd)
+}
+
+// watchSnoozes resumes snoozed downloads once their time comes, until ctx
+// is done. Snoozes that came due while Surge was not running are resumed at
+// once.
+func watchSnoozes(ctx context.Context, service core.DownloadService) {
+	ticker := time.NewTicker(snoozeCheckInterval)
+	defer ticker.Stop()
+	for {
+		resumeDueSnoozes(service, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func resumeDueSnoozes(service core.DownloadService, now time.Time) {
+	snoozes, err := state.Snoozes()
+	if err != nil {
+		utils.Debug("Snoozes: %v", err)
+		return
+	}
+	for id, until := range snoozes {
+		if until.After(now) {
+			continue
+		}
+		if err := service.Resume(id); err != nil {
+			// Most likely removed meanwhile; retrying would fail the same way
+			publishSystemLog(fmt.Sprintf("Snooze of %s ended but it could not be resumed: %v", id, err))
+		} else {
+			publishSystemLog(fmt.Sprintf("Snooze of %s ended, resuming", id))
+		}
+		if err := state.RemoveSnooze(id); err != nil {
+			utils.Debug("Snoozes: clearing %s: %v", id, err)
+		}
+	}
+}
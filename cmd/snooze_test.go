@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/state"
+)
+
+// resumeRecordingService records Resume calls and fails those for missing.
+type resumeRecordingService struct {
+	*httpAPITestService
+	resumed []string
+	missing string
+}
+
+func (s *resumeRecordingService) Resume(id string) error {
+	if id == s.missing {
+		return errors.New("download not found")
+	}
+	s.resumed = append(s.resumed, id)
+	return nil
+}
+
+func TestResumeDueSnoozes(t *testing.T) {
+	if err := resetSharedStateDB(); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for id, until := range map[string]time.Time{
+		"due":     now.Add(-time.Minute),
+		"later":   now.Add(time.Hour),
+		"removed": now.Add(-time.Second),
+	} {
+		if err := state.SetSnooze(id, until); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() {
+		for _, id := range []string{"due", "later", "removed"} {
+			_ = state.RemoveSnooze(id)
+		}
+	})
+
+	svc := &resumeRecordingService{httpAPITestService: &httpAPITestService{}, missing: "removed"}
+	resumeDueSnoozes(svc, now)
+
+	if !slices.Equal(svc.resumed, []string{"due"}) {
+		t.Fatalf("resumed = %v, want [due]", svc.resumed)
+	}
+	snoozes, err := state.Snoozes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snoozes) != 1 || snoozes["later"].IsZero() {
+		t.Fatalf("snoozes left = %v, want only later", snoozes)
+	}
+}
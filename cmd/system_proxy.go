@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// systemProxyWatchInterval is how often a running instance checks whether
+// the network changed, which may come with a different system proxy.
+const systemProxyWatchInterval = 30 * time.Second
+
+// watchSystemProxy detects the system proxy again whenever the machine's
+// interfaces or addresses change, until ctx is done.
+func watchSystemProxy(ctx context.Context) {
+	ticker := time.NewTicker(systemProxyWatchInterval)
+	defer ticker.Stop()
+	fingerprint := utils.NetworkFingerprint()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		current := utils.NetworkFingerprint()
+		if current == fingerprint {
+			continue
+		}
+		fingerprint = current
+		if settings, changed := engine.DefaultSystemProxy.Refresh(); changed {
+			publishSystemLog("System proxy changed to " + settings.String())
+		}
+	}
+}
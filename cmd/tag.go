@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var tagCmd = &cobra.Command{
+	Use:               "tag <ID> [TAG]...",
+	Short:             "Set the tags of a download",
+	Long:              `Replace the tags of a download with the given ones, or remove them all with --clear. Tags organize downloads beyond categories and can be listed with 'surge ls --tag'.`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeDownloadIDs(),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clearTags, _ := cmd.Flags().GetBool("clear")
+		if clearTags == (len(args) > 1) {
+			return fmt.Errorf("give the tags to set, or --clear to remove them all")
+		}
+
+		tags, err := types.NormalizeTags(args[1:])
+		if err != nil {
+			return err
+		}
+
+		if err := initializeGlobalState(); err != nil {
+			return err
+		}
+
+		baseURL, token, err := resolveAPIConnection(true)
+		if err != nil {
+			return err
+		}
+
+		id, err := resolveDownloadID(args[0])
+		if err != nil {
+			return err
+		}
+
+		jsonData, err := json.Marshal(map[string][]string{"tags": tags})
+		if err != nil {
+			return fmt.Errorf("error creating request: %w", err)
+		}
+
+		path := fmt.Sprintf("/tags?id=%s", url.QueryEscape(id))
+		resp, err := doAPIRequest(http.MethodPut, baseURL, token, path, bytes.NewBuffer(jsonData))
+		if err != nil {
+			return fmt.Errorf("error connecting to server: %w", err)
+		}
+		defer func() {
+			if err := resp.Body.Close(); err != nil {
+				utils.Debug("Error closing response body: %v", err)
+			}
+		}()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("server returned %s", resp.Status)
+		}
+		if len(tags) == 0 {
+			fmt.Printf("Cleared tags of download %s\n", id[:8])
+		} else {
+			fmt.Printf("Tagged download %s: %s\n", id[:8], strings.Join(tags, ", "))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+	tagCmd.Flags().Bool("clear", false, "Remove all tags from the download")
+}
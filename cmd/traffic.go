@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/state"
+	"github.com/SurgeDM/Surge/internal/i18n"
+	"github.com/SurgeDM/Surge/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var trafficCmd = &cobra.Command{
+	Use:   "traffic",
+	Short: "Show how much has been downloaded each month",
+	Long: `Show how many bytes were downloaded in each calendar month, newest first,
+and how much of monthly_quota this month has used when one is set.
+
+A running instance adds what it downloads to the totals every 30 seconds and
+whenever a download pauses, finishes or fails.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+
+		if err := initializeGlobalState(); err != nil {
+			return err
+		}
+
+		months, err := state.TrafficHistory()
+		if err != nil {
+			return err
+		}
+		report := trafficReport{
+			Month:  state.TrafficMonth(time.Now()),
+			Quota:  config.Resolve[int64](getSettings().Network.MonthlyQuota),
+			Months: months,
+		}
+		for _, m := range months {
+			if m.Month == report.Month {
+				report.Used = m.Bytes
+			}
+		}
+		printTraffic(os.Stdout, report, jsonOutput)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(trafficCmd)
+	trafficCmd.Flags().Bool("json", false, "Output in JSON format")
+}
+
+// trafficReport is what surge traffic shows.
+type trafficReport struct {
+	Month  string               `json:"month"`
+	Used   int64                `json:"used"`
+	Quota  int64                `json:"quota,omitempty"`
+	Months []state.MonthTraffic `json:"months"`
+}
+
+func printTraffic(out io.Writer, r trafficReport, jsonOutput bool) {
+	if jsonOutput {
+		if r.Months == nil {
+			r.Months = []state.MonthTraffic{}
+		}
+		data, _ := json.MarshalIndent(r, "", "  ")
+		_, _ = fmt.Fprintln(out, string(data))
+		return
+	}
+
+	if r.Quota > 0 {
+		_, _ = fmt.Fprintln(out, i18n.T("cli.traffic_quota", utils.ConvertBytesToHumanReadable(r.Used),
+			utils.ConvertBytesToHumanReadable(r.Quota), r.Used*100/r.Quota))
+	}
+	if len(r.Months) == 0 {
+		_, _ = fmt.Fprintln(out, i18n.T("cli.no_traffic"))
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	header := i18n.T("cli.traffic_columns")
+	_, _ = fmt.Fprintln(w, header)
+	_, _ = fmt.Fprintln(w, underline(header))
+	for _, m := range r.Months {
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", m.Month, utils.ConvertBytesToHumanReadable(m.Bytes))
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine"
+	"github.com/SurgeDM/Surge/internal/engine/delta"
+	"github.com/SurgeDM/Surge/internal/engine/state"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var updateCmd = &cobra.Command{
+	Use:   "update <ID|PATH>...",
+	Short: "Bring completed downloads up to date with their URLs",
+	Long: `Check whether the remote file of a completed download changed since it was
+downloaded, given by its ID or by the path of its file, and update it if so.
+
+When the server supports ranges and sends a strong ETag or a Last-Modified
+date, and the old file is still the start of the new one, as with logs and
+growing archives, only the new tail is downloaded and appended. Otherwise,
+when the server has a .zsync control file next to the file, the blocks of
+the old file that are still in the new one are reused and only the rest is
+downloaded. Failing both, the file is downloaded again beside the old one,
+which is replaced once the new version is complete.
+
+The saved checksum is of the old contents, so it is dropped when a file is
+updated.`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeDownloadIDs(),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		check, _ := cmd.Flags().GetBool("check")
+		full, _ := cmd.Flags().GetBool("full")
+
+		if err := initializeGlobalState(); err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		var failed int
+		for _, arg := range args {
+			if err := updateDownload(ctx, arg, check, full); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", arg, err)
+				failed++
+			}
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d downloads could not be updated", failed, len(args))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(updateCmd)
+	updateCmd.Flags().Bool("check", false, "Only report which files changed")
+	updateCmd.Flags().Bool("full", false, "Download changed files again in full")
+}
+
+func updateDownload(ctx context.Context, arg string, check, full bool) error {
+	entry, err := findVerifyTarget(arg)
+	if err != nil {
+		return err
+	}
+	if entry.Status != "completed" {
+		return fmt.Errorf("download %s is %s; only completed downloads can be updated", entry.ID[:8], entry.Status)
+	}
+	headers, err := state.GetHeaders(entry.ID)
+	if err != nil {
+		return err
+	}
+
+	runtime := getSettings().ToRuntimeConfig()
+	runtime = types.BindRuntime(runtime, "", entry.URL)
+	transport, err := engine.DefaultNetworkPool.AcquireTransportFor(runtime, types.PoolMaxConnsPerHost)
+	if err != nil {
+		return fmt.Errorf("failed to configure TLS: %w", err)
+	}
+	defer engine.DefaultNetworkPool.ReleaseTransport(transport)
+
+	u := &delta.Updater{
+		Client:      &http.Client{Transport: transport, CheckRedirect: engine.RedirectPolicy(runtime, nil)},
+		Headers:     headers,
+		UserAgent:   runtime.GetUserAgent(),
+		Connections: runtime.GetMaxConnectionsPerDownload(),
+		MaxRetries:  runtime.GetMaxTaskRetries(),
+		Full:        full,
+	}
+
+	if check {
+		remote, changed, err := u.Check(ctx, entry.URL, entry.DestPath, entry.ResponseHeaders)
+		if err != nil {
+			return err
+		}
+		if changed {
+			fmt.Printf("%s changed (now %s)\n", entry.DestPath, utils.ConvertBytesToHumanReadable(remote.Size))
+		} else {
+			fmt.Printf("%s is up to date\n", entry.DestPath)
+		}
+		return nil
+	}
+
+	result, err := u.Update(ctx, entry.URL, entry.DestPath, entry.ResponseHeaders)
+	if err != nil {
+		return err
+	}
+	fetched := utils.ConvertBytesToHumanReadable(result.Fetched)
+	switch result.Mode {
+	case delta.Unchanged:
+		fmt.Printf("%s is up to date\n", entry.DestPath)
+		return nil
+	case delta.Appended:
+		fmt.Printf("%s: appended the new tail, %s fetched\n", entry.DestPath, fetched)
+	case delta.Synced:
+		fmt.Printf("%s: rebuilt with its .zsync file, %s fetched\n", entry.DestPath, fetched)
+	case delta.Replaced:
+		fmt.Printf("%s: downloaded again, %s fetched\n", entry.DestPath, fetched)
+	}
+
+	info, err := os.Stat(entry.DestPath)
+	if err != nil {
+		return err
+	}
+	return state.UpdateCompletedFile(entry.ID, info.Size(), time.Now().Unix(), updatedResponseHeaders(entry.ResponseHeaders, result.Remote))
+}
+
+// updatedResponseHeaders are the saved response headers with the validators
+// of the new version, so the next update compares against it.
+func updatedResponseHeaders(saved map[string]string, remote delta.Remote) map[string]string {
+	headers := make(map[string]string, len(saved)+2)
+	for key, value := range saved {
+		headers[key] = value
+	}
+	delete(headers, "ETag")
+	delete(headers, "Last-Modified")
+	if remote.ETag != "" {
+		headers["ETag"] = remote.ETag
+	}
+	if remote.LastModified != "" {
+		headers["Last-Modified"] = remote.LastModified
+	}
+	return headers
+}
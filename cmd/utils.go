@@ -13,6 +13,7 @@ import (
 	"strings"
 
 	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/core"
 	"github.com/SurgeDM/Surge/internal/engine/state"
 	"github.com/SurgeDM/Surge/internal/engine/types"
 	"github.com/SurgeDM/Surge/internal/utils"
@@ -194,6 +195,7 @@ func doAPIRequest(method string, baseURL string, token string, path string, body
 	if strings.TrimSpace(token) != "" {
 		req.Header.Set("Authorization", "Bearer "+token)
 	}
+	core.SetVersionHeaders(req.Header, Version)
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
@@ -202,28 +204,48 @@ func doAPIRequest(method string, baseURL string, token string, path string, body
 	if err != nil {
 		return nil, err
 	}
-	return client.Do(req)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// A failure from a daemon on another API revision is reported as such,
+	// rather than as whatever the older or newer handler made of the request.
+	if resp.StatusCode >= 400 && core.PeerAPIVersion(resp.Header) != core.APIVersion {
+		defer func() { _ = resp.Body.Close() }()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, types.KB))
+		return nil, core.ExplainVersionMismatch(resp, fmt.Errorf("server error: %s - %s", resp.Status, strings.TrimSpace(string(msg))))
+	}
+	return resp, nil
 }
 
 func sendToServer(url string, mirrors []string, outPath string, baseURL string, token string) error {
-	return sendToServerWithApproval(url, mirrors, outPath, baseURL, token, true)
+	return sendToServerWithApproval(url, mirrors, outPath, baseURL, token, true, types.TLSOptions{}, types.RequestOptions{})
 }
 
-func sendToServerWithApproval(url string, mirrors []string, outPath string, baseURL string, token string, skipApproval bool) error {
-	reqBody := DownloadRequest{
-		URL:          url,
-		Mirrors:      mirrors,
-		Path:         outPath,
-		SkipApproval: skipApproval,
-	}
-	jsonData, err := json.Marshal(reqBody)
+func sendToServerWithApproval(url string, mirrors []string, outPath string, baseURL string, token string, skipApproval bool, tlsOpts types.TLSOptions, request types.RequestOptions) error {
+	_, err := postDownloadRequest(baseURL, token, DownloadRequest{
+		URL:            url,
+		Mirrors:        mirrors,
+		Path:           outPath,
+		SkipApproval:   skipApproval,
+		TLS:            tlsOpts,
+		RequestOptions: request,
+	})
+	return err
+}
+
+// postDownloadRequest sends req to POST /download. It reports whether the
+// server answered with a download it had already queued under req.ID.
+func postDownloadRequest(baseURL string, token string, req DownloadRequest) (bool, error) {
+	jsonData, err := json.Marshal(req)
 	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+		return false, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	resp, err := doAPIRequest(http.MethodPost, baseURL, token, "/download", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return fmt.Errorf("failed to connect to server: %w", err)
+		return false, fmt.Errorf("failed to connect to server: %w", err)
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
@@ -233,16 +255,25 @@ func sendToServerWithApproval(url string, mirrors []string, outPath string, base
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("server error: %s - %s", resp.Status, string(body))
+		var large struct {
+			Status    string `json:"status"`
+			Size      int64  `json:"size"`
+			Threshold int64  `json:"threshold"`
+		}
+		if resp.StatusCode == http.StatusConflict && json.Unmarshal(body, &large) == nil && large.Status == "confirmation_required" {
+			return false, &types.LargeDownloadError{Size: large.Size, Threshold: large.Threshold}
+		}
+		return false, fmt.Errorf("server error: %s - %s", resp.Status, string(body))
 	}
 
-	return nil
+	return resp.Header.Get("Idempotent-Replayed") == "true", nil
 }
 
-func sendBatchToServer(urls []string, outPath string, baseURL string, token string, skipApproval bool) error {
+func sendBatchToServer(urls []string, outPath string, baseURL string, token string, skipApproval bool, tlsOpts types.TLSOptions, request types.RequestOptions, sums bool) error {
 	reqBody := BatchDownloadRequest{
 		Path:         outPath,
 		SkipApproval: skipApproval,
+		Sums:         sums,
 	}
 	for _, arg := range urls {
 		url, mirrors := ParseURLArg(arg)
@@ -250,9 +281,11 @@ func sendBatchToServer(urls []string, outPath string, baseURL string, token stri
 			continue
 		}
 		reqBody.Downloads = append(reqBody.Downloads, DownloadRequest{
-			URL:     url,
-			Mirrors: mirrors,
-			Path:    outPath,
+			URL:            url,
+			Mirrors:        mirrors,
+			Path:           outPath,
+			TLS:            tlsOpts,
+			RequestOptions: request,
 		})
 	}
 	if len(reqBody.Downloads) == 0 {
@@ -300,7 +333,7 @@ func GetRemoteDownloads(baseURL string, token string) ([]types.DownloadStatus, e
 
 	var statuses []types.DownloadStatus
 	if err := json.NewDecoder(resp.Body).Decode(&statuses); err != nil {
-		return nil, err
+		return nil, core.ExplainVersionMismatch(resp, err)
 	}
 
 	return statuses, nil
@@ -341,16 +374,22 @@ func ExecuteAPIAction(rawID, endpoint, method, successMsg string) error {
 	return nil
 }
 
-// resolveDownloadID resolves a partial ID (prefix) to a full download ID.
-// If the input is at least 8 characters and matches a single download, returns the full ID.
-// Returns the original ID if no match found or if it's already a full ID.
+// downloadRef is a download ID together with the alias it was added under.
+type downloadRef struct {
+	id    string
+	alias string
+}
+
+// resolveDownloadID resolves an alias or a partial ID (prefix) to a full
+// download ID. An exact alias wins over ID prefixes. Returns the original ID
+// if no match found or if it's already a full ID.
 func resolveDownloadID(partialID string) (string, error) {
-	if len(partialID) >= 32 {
+	if len(partialID) >= 32 && types.ValidateAlias(partialID) != nil {
 		return partialID, nil // Already a full UUID
 	}
 
 	strictRemote := resolveHostTarget() != ""
-	var candidates []string
+	var candidates []downloadRef
 
 	// 1. Try to get candidates from running server
 	baseURL, token, err := resolveAPIConnection(false)
@@ -364,7 +403,7 @@ func resolveDownloadID(partialID string) (string, error) {
 				return "", fmt.Errorf("failed to list remote downloads: %w", err)
 			}
 		} else {
-			appendCandidateIDs(&candidates, remoteDownloads)
+			appendCandidates(&candidates, remoteDownloads)
 		}
 	}
 
@@ -376,7 +415,7 @@ func resolveDownloadID(partialID string) (string, error) {
 	downloads, err := state.ListAllDownloads()
 	if err == nil {
 		for _, d := range downloads {
-			candidates = append(candidates, d.ID)
+			candidates = append(candidates, downloadRef{id: d.ID, alias: d.Alias})
 		}
 	} else if len(candidates) == 0 {
 		// Only short-circuit when both remote and DB are unavailable.
@@ -386,22 +425,28 @@ func resolveDownloadID(partialID string) (string, error) {
 	return resolveIDFromCandidates(partialID, candidates)
 }
 
-func appendCandidateIDs(candidates *[]string, downloads []types.DownloadStatus) {
+func appendCandidates(candidates *[]downloadRef, downloads []types.DownloadStatus) {
 	for _, d := range downloads {
-		*candidates = append(*candidates, d.ID)
+		*candidates = append(*candidates, downloadRef{id: d.ID, alias: d.Alias})
 	}
 }
 
-func resolveIDFromCandidates(partialID string, candidates []string) (string, error) {
+func resolveIDFromCandidates(partialID string, candidates []downloadRef) (string, error) {
+	for _, c := range candidates {
+		if c.alias != "" && c.alias == partialID {
+			return c.id, nil
+		}
+	}
+
 	// Find matches among all candidates
 	var matches []string
 	seen := make(map[string]bool)
 
-	for _, id := range candidates {
-		if strings.HasPrefix(id, partialID) {
-			if !seen[id] {
-				matches = append(matches, id)
-				seen[id] = true
+	for _, c := range candidates {
+		if strings.HasPrefix(c.id, partialID) {
+			if !seen[c.id] {
+				matches = append(matches, c.id)
+				seen[c.id] = true
 			}
 		}
 	}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/SurgeDM/Surge/internal/engine"
+	"github.com/SurgeDM/Surge/internal/engine/state"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify <ID|PATH>",
+	Short: "Check a download's file for corruption",
+	Long: `Re-validate the file of a download, given by its ID or by the path of its file.
+
+An unfinished download has its .surge file checked against its saved state:
+every finished range must be in the file, the file must match the hash taken
+when it was paused, the chunk map must agree, and finished data must not read
+back as zeros. With --repair, the suspect ranges are queued to be downloaded
+again on the next resume.
+
+A completed download has its file checked against the size and checksum it
+was saved with.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDownloadIDs(),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repair, _ := cmd.Flags().GetBool("repair")
+
+		if err := initializeGlobalState(); err != nil {
+			return err
+		}
+
+		entry, err := findVerifyTarget(args[0])
+		if err != nil {
+			return err
+		}
+
+		if entry.Status == "completed" {
+			if repair {
+				return fmt.Errorf("--repair only applies to unfinished downloads; add the URL of %s again to download it anew", entry.Filename)
+			}
+			return verifyCompleted(cmd, entry)
+		}
+		return verifyPartial(entry, repair)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+	verifyCmd.Flags().Bool("repair", false, "Queue suspect ranges to be downloaded again")
+}
+
+// findVerifyTarget returns the download arg names, by the path of its file
+// or partial file, or else by its ID or alias.
+func findVerifyTarget(arg string) (*types.DownloadEntry, error) {
+	if _, err := os.Stat(arg); err == nil {
+		abs, err := filepath.Abs(arg)
+		if err != nil {
+			return nil, err
+		}
+		downloads, err := state.ListAllDownloads()
+		if err != nil {
+			return nil, err
+		}
+		for i, d := range downloads {
+			if d.DestPath == abs || d.DestPath+types.IncompleteSuffix == abs {
+				return &downloads[i], nil
+			}
+		}
+		return nil, fmt.Errorf("no download is saved to %s", abs)
+	}
+
+	id, err := resolveDownloadID(arg)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := state.GetDownload(id)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("download %s not found", arg)
+	}
+	return entry, nil
+}
+
+func verifyCompleted(cmd *cobra.Command, entry *types.DownloadEntry) error {
+	info, err := os.Stat(entry.DestPath)
+	if err != nil {
+		return fmt.Errorf("cannot check %s: %w", entry.DestPath, err)
+	}
+	if entry.TotalSize > 0 && info.Size() != entry.TotalSize {
+		return fmt.Errorf("%s is %s, want %s", entry.DestPath,
+			utils.ConvertBytesToHumanReadable(info.Size()), utils.ConvertBytesToHumanReadable(entry.TotalSize))
+	}
+	if entry.Checksum == "" {
+		fmt.Printf("%s has the expected size; no checksum was saved to check its contents against\n", entry.DestPath)
+		return nil
+	}
+	if err := engine.VerifyChecksum(cmd.Context(), entry.DestPath, entry.Checksum, nil); err != nil {
+		return fmt.Errorf("%s: %w", entry.DestPath, err)
+	}
+	fmt.Printf("%s matches %s\n", entry.DestPath, entry.Checksum)
+	return nil
+}
+
+func verifyPartial(entry *types.DownloadEntry, repair bool) error {
+	if repair && entry.Status != "paused" && entry.Status != "error" && entry.Status != "failed" {
+		return fmt.Errorf("download %s is %s; pause it before repairing", entry.ID[:8], entry.Status)
+	}
+
+	check, err := state.CheckPartial(entry.URL, entry.DestPath)
+	if err != nil {
+		return fmt.Errorf("cannot check %s: %w", entry.DestPath, err)
+	}
+	fmt.Printf("Checked %s of %s finished in %s\n",
+		utils.ConvertBytesToHumanReadable(check.Finished), utils.ConvertBytesToHumanReadable(check.State.TotalSize), entry.DestPath+types.IncompleteSuffix)
+	if len(check.Suspect) == 0 {
+		fmt.Println("No corrupt ranges found")
+		return nil
+	}
+
+	ranges := make([]types.Task, 0, len(check.Suspect))
+	for _, s := range check.Suspect {
+		fmt.Printf("  %d-%d (%s): %s\n", s.Offset, s.Offset+s.Length-1, utils.ConvertBytesToHumanReadable(s.Length), s.Reason)
+		ranges = append(ranges, s.Task)
+	}
+	if !repair {
+		return fmt.Errorf("found %d suspect ranges; run with --repair to download them again", len(check.Suspect))
+	}
+
+	before := check.State.Downloaded
+	if err := state.RequeueRanges(check.State, ranges); err != nil {
+		return fmt.Errorf("failed to re-queue ranges: %w", err)
+	}
+	fmt.Printf("Re-queued %s; resume the download to fetch it again\n",
+		utils.ConvertBytesToHumanReadable(max(before-check.State.Downloaded, 0)))
+	return nil
+}
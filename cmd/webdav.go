@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/webdav"
+	"github.com/google/uuid"
+)
+
+// webdavRequestTimeout bounds each listing request of a mirror.
+const webdavRequestTimeout = 30 * time.Second
+
+// splitWebDAVDirectories separates the webdav:// directories among urls,
+// which are mirrored, from everything else, which is added as it is.
+func splitWebDAVDirectories(urls []string) (dirs, rest []string) {
+	for _, u := range urls {
+		if webdav.IsDirectory(u) {
+			dirs = append(dirs, strings.TrimSpace(u))
+		} else {
+			rest = append(rest, u)
+		}
+	}
+	return dirs, rest
+}
+
+// mirrorWebDAV queues every file under the WebDAV directory at rawurl into
+// <output>/<directory name>/ with the directory's layout. output may be a
+// path template; the layout goes below the part of it that names folders.
+// Like surge hf, running it again does not queue a file twice.
+func mirrorWebDAV(ctx context.Context, rawurl, output, baseURL, token string, tlsOpts types.TLSOptions, request types.RequestOptions) (queued, existing, failed int, err error) {
+	dirURL, headers, err := webdav.HTTPURL(rawurl)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	runtime := getSettings().ToRuntimeConfig()
+	runtime.TLS = runtime.TLS.Merge(tlsOpts)
+	runtime = types.BindRuntime(runtime, request.Interface, dirURL)
+	transport, err := engine.DefaultNetworkPool.AcquireTransportFor(runtime, 1)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to configure TLS: %w", err)
+	}
+	defer engine.DefaultNetworkPool.ReleaseTransport(transport)
+
+	client := &webdav.Client{HTTP: &http.Client{Transport: transport, Timeout: webdavRequestTimeout}, Headers: headers}
+	files, err := client.Walk(ctx, dirURL)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("%s: %w", rawurl, err)
+	}
+	if len(files) == 0 {
+		return 0, 0, 0, fmt.Errorf("%s: directory is empty", rawurl)
+	}
+
+	root := mirrorRoot(dirURL)
+	for _, f := range files {
+		req := DownloadRequest{
+			// The same file always gets the same ID, so a repeated run finds
+			// the download it queued before.
+			ID:             uuid.NewSHA1(uuid.NameSpaceURL, []byte(f.URL)).String(),
+			URL:            f.URL,
+			Filename:       path.Base(f.Path),
+			Path:           joinTemplateDir(output, path.Join(root, path.Dir(f.Path))),
+			SkipApproval:   true,
+			Headers:        headers,
+			TLS:            tlsOpts,
+			RequestOptions: request,
+		}
+		replayed, err := postDownloadRequest(baseURL, token, req)
+		if err != nil {
+			fmt.Printf("Failed to add %s: %v\n", f.Path, err)
+			failed++
+			continue
+		}
+		if replayed {
+			resumeExistingDownload(baseURL, token, req.ID)
+			existing++
+			continue
+		}
+		queued++
+	}
+	return queued, existing, failed, nil
+}
+
+// mirrorRoot names the folder a mirrored directory goes in: its own name, or
+// the server's for the top of the share.
+func mirrorRoot(dirURL string) string {
+	u, err := url.Parse(dirURL)
+	if err != nil {
+		return ""
+	}
+	if name := path.Base(strings.TrimRight(u.Path, "/")); name != "." && name != ".." && name != "/" {
+		return name
+	}
+	return u.Hostname()
+}
+
+// joinTemplateDir adds sub, a slash-separated relative directory, to output.
+// When the last element of output names the file with {filename}, sub goes
+// before it.
+func joinTemplateDir(output, sub string) string {
+	sub = filepath.FromSlash(sub)
+	if i := strings.LastIndexAny(output, `/\`); i >= 0 && strings.Contains(output[i+1:], "{filename}") {
+		return filepath.Join(output[:i], sub, output[i+1:])
+	}
+	return filepath.Join(output, sub)
+}
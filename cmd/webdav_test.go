@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMirrorPaths(t *testing.T) {
+	if got := mirrorRoot("https://dav.example.com/share/photos/"); got != "photos" {
+		t.Errorf("mirrorRoot() = %q, want photos", got)
+	}
+	if got := mirrorRoot("http://dav.example.com:8080/"); got != "dav.example.com" {
+		t.Errorf("mirrorRoot() = %q, want the host", got)
+	}
+
+	for output, want := range map[string]string{
+		"/dl":                        filepath.FromSlash("/dl/photos/2024"),
+		"/dl/{domain}":               filepath.FromSlash("/dl/{domain}/photos/2024"),
+		"/dl/{domain}/{name}.{ext}":  filepath.FromSlash("/dl/{domain}/{name}.{ext}/photos/2024"),
+		"/dl/{yyyy}/{filename}-copy": filepath.FromSlash("/dl/{yyyy}/photos/2024/{filename}-copy"),
+	} {
+		if got := joinTemplateDir(output, "photos/2024"); got != want {
+			t.Errorf("joinTemplateDir(%q) = %q, want %q", output, got, want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine"
+	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/engine/zsync"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// runZsyncGet rebuilds the file a .zsync control file describes in
+// outputDir, copying what it can from seeds and fetching only the rest.
+// control is the URL of the control file, or the path of a local one that
+// names its file by an absolute URL.
+func runZsyncGet(control, outputDir string, seeds []string, tlsOpts types.TLSOptions, request types.RequestOptions, showProgress bool) error {
+	for i, seed := range seeds {
+		if _, err := os.Stat(seed); err != nil {
+			return fmt.Errorf("seed %w", err)
+		}
+		seeds[i] = utils.EnsureAbsPath(seed)
+	}
+
+	runtime := getSettings().ToRuntimeConfig()
+	runtime.TLS = runtime.TLS.Merge(tlsOpts)
+	runtime = request.Timeouts.Apply(runtime)
+	runtime = types.BindRuntime(runtime, request.Interface, control)
+	transport, err := engine.DefaultNetworkPool.AcquireTransportFor(runtime, types.PoolMaxConnsPerHost)
+	if err != nil {
+		return fmt.Errorf("failed to configure TLS: %w", err)
+	}
+	defer engine.DefaultNetworkPool.ReleaseTransport(transport)
+
+	conns := runtime.GetMaxConnectionsPerDownload()
+	if request.Connections > 0 {
+		conns = min(conns, request.Connections)
+	}
+	client := &zsync.Client{
+		HTTP:        &http.Client{Transport: transport, CheckRedirect: engine.RedirectPolicy(runtime, nil)},
+		UserAgent:   runtime.GetUserAgent(),
+		Connections: conns,
+		MaxRetries:  runtime.GetMaxTaskRetries(),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if maxDuration := runtime.GetMaxDuration(); maxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, maxDuration, types.ErrMaxDuration)
+		defer cancel()
+	}
+
+	var ctl *zsync.Control
+	controlURL := control
+	if f, err := os.Open(control); err == nil {
+		ctl, err = zsync.Parse(f)
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+		controlURL = ""
+	} else if ctl, err = client.Control(ctx, control); err != nil {
+		return err
+	}
+
+	name := zsyncFilename(ctl, control)
+	dest := filepath.Join(resolveClientOutputPath(outputDir), name)
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+
+	progress := newHeadlessProgressIfTerminal(showProgress)
+	progress.start(name, name, 0)
+	start := time.Now()
+	var mu sync.Mutex
+	client.Progress = func(fetched, total int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		progress.update(events.ProgressMsg{
+			DownloadID:        name,
+			Downloaded:        fetched,
+			Total:             total,
+			Speed:             float64(fetched) / max(time.Since(start).Seconds(), 0.001),
+			ActiveConnections: conns,
+		})
+	}
+
+	result, err := client.Sync(ctx, controlURL, ctl, dest, seeds)
+	progress.clear()
+	if err != nil {
+		if errors.Is(context.Cause(ctx), types.ErrMaxDuration) {
+			return types.ErrMaxDuration
+		}
+		return err
+	}
+	fmt.Printf("%s: reused %s from seeds, fetched %s\n", dest,
+		utils.ConvertBytesToHumanReadable(result.Reused), utils.ConvertBytesToHumanReadable(result.Fetched))
+	return nil
+}
+
+// zsyncFilename is the name the rebuilt file is saved as: the one the
+// control file gives, or else the control file's own without .zsync.
+func zsyncFilename(ctl *zsync.Control, control string) string {
+	for _, name := range []string{ctl.Filename, strings.TrimSuffix(path.Base(filepath.ToSlash(control)), ".zsync")} {
+		// Only the last element, so a control file can not write elsewhere
+		name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+		if name != "" && name != "." && name != ".." && name != "/" {
+			return name
+		}
+	}
+	return "download"
+}
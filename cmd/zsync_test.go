@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/SurgeDM/Surge/internal/engine/zsync"
+)
+
+func TestZsyncFilename(t *testing.T) {
+	for _, tc := range []struct{ filename, control, want string }{
+		{"noble.iso", "https://example.com/daily/current.iso.zsync", "noble.iso"},
+		{"../../etc/passwd", "https://example.com/a.zsync", "passwd"},
+		{`..\..\boot.ini`, "https://example.com/a.zsync", "boot.ini"},
+		{"", "https://example.com/daily/noble.iso.zsync", "noble.iso"},
+		{"..", "/tmp/local.img.zsync", "local.img"},
+	} {
+		if got := zsyncFilename(&zsync.Control{Filename: tc.filename}, tc.control); got != tc.want {
+			t.Errorf("zsyncFilename(%q, %q) = %q, want %q", tc.filename, tc.control, got, tc.want)
+		}
+	}
+}
@@ -34,7 +34,7 @@ func (c *Category) Validate() error {
 	if strings.TrimSpace(c.Path) == "" {
 		return errors.New("category path cannot be empty")
 	}
-	return nil
+	return ValidatePathTemplate(c.Path)
 }
 
 func existingDirOrFallback(dir, fallback string) string {
@@ -0,0 +1,554 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Where a setting's value came from, lowest precedence first. Values saved
+// from the TUI (settings.json) sit between the defaults and the config file,
+// and the active profile between the config file and the environment.
+const (
+	SourceDefault  = "default"
+	SourceSettings = "settings"
+	SourceFile     = "file"
+	SourceProfile  = "profile"
+	SourceEnv      = "env"
+	SourceFlag     = "flag"
+)
+
+// settingOverride remembers a value layered on top of settings.json so that
+// saving from the TUI does not bake it into that file.
+type settingOverride struct {
+	source string
+	value  any
+	base   any
+}
+
+var (
+	flagOverridesMu sync.RWMutex
+	flagOverrides   map[string]any
+)
+
+// GetConfigFilePath returns the path to the hand-edited config.toml.
+func GetConfigFilePath() string {
+	return filepath.Join(GetSurgeDir(), "config.toml")
+}
+
+// SettingName returns the "section.key" name used by config.toml, the
+// SURGE_* environment variables and `surge config`.
+func SettingName(category, key string) string {
+	return strings.ToLower(category) + "." + key
+}
+
+// SettingEnvVar returns the environment variable that overrides the named
+// setting, e.g. SURGE_NETWORK_MAX_CONCURRENT_DOWNLOADS.
+func SettingEnvVar(name string) string {
+	return "SURGE_" + strings.ToUpper(strings.ReplaceAll(name, ".", "_"))
+}
+
+// LookupSetting finds a setting by "section.key", or by its bare key when
+// that is unambiguous, and returns it with its full name.
+func (s *Settings) LookupSetting(name string) (*Setting, string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	section, key, qualified := strings.Cut(name, ".")
+	var found *Setting
+	var foundName string
+	for _, cat := range s.CategoriesList {
+		if qualified && !strings.EqualFold(cat.Name, section) {
+			continue
+		}
+		for _, set := range cat.Settings {
+			if (qualified && set.Key == key) || (!qualified && set.Key == name) {
+				if found != nil {
+					return nil, "", fmt.Errorf("setting %q is ambiguous; use %s or %s", name, foundName, SettingName(cat.Name, set.Key))
+				}
+				found, foundName = set, SettingName(cat.Name, set.Key)
+			}
+		}
+	}
+	if found == nil {
+		return nil, "", fmt.Errorf("unknown setting %q", name)
+	}
+	return found, foundName, nil
+}
+
+// Source reports where the named setting's value came from.
+func (s *Settings) Source(name string) string {
+	if o, ok := s.overrides[name]; ok {
+		return o.source
+	}
+	set, _, err := s.LookupSetting(name)
+	if err != nil || reflect.DeepEqual(set.Resolve(), (&Setting{Type: set.Type, Value: set.DefaultValue}).Resolve()) {
+		return SourceDefault
+	}
+	return SourceSettings
+}
+
+// ParseValue converts a value typed on the command line or in an environment
+// variable to the setting's type and validates it. Durations take Go syntax
+// (30s, 5m) and sizes are plain byte counts.
+func (s *Setting) ParseValue(raw string) (any, error) {
+	raw = strings.TrimSpace(raw)
+	var val any
+	var err error
+	switch s.Type {
+	case "bool":
+		val, err = strconv.ParseBool(raw)
+	case "int":
+		val, err = strconv.Atoi(raw)
+	case "int64":
+		val, err = strconv.ParseInt(raw, 10, 64)
+	case "float64":
+		val, err = strconv.ParseFloat(raw, 64)
+	case "duration":
+		val, err = time.ParseDuration(raw)
+	default:
+		val = raw
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q", s.Type, raw)
+	}
+	if err := s.Validate(val); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+// normalizeValue converts a value decoded from TOML to the setting's type
+// and validates it.
+func (s *Setting) normalizeValue(v any) (any, error) {
+	if str, ok := v.(string); ok && s.Type != "string" && s.Type != "auth_token" && s.Type != "link" {
+		return s.ParseValue(str)
+	}
+	var val any
+	switch s.Type {
+	case "bool":
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("must be true or false")
+		}
+		val = b
+	case "int", "int64":
+		n, ok := v.(int64)
+		if !ok {
+			return nil, fmt.Errorf("must be a whole number")
+		}
+		val = n
+		if s.Type == "int" {
+			val = int(n)
+		}
+	case "float64":
+		switch n := v.(type) {
+		case float64:
+			val = n
+		case int64:
+			val = float64(n)
+		default:
+			return nil, fmt.Errorf("must be a number")
+		}
+	case "duration":
+		return nil, fmt.Errorf(`must be a duration string such as "30s"`)
+	default:
+		str, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be a string")
+		}
+		val = str
+	}
+	if err := s.Validate(val); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+// FormatValue renders the setting's current value the way ParseValue and
+// config.toml accept it.
+func (s *Setting) FormatValue() string {
+	switch v := s.Resolve().(type) {
+	case time.Duration:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// SetFlagOverrides records name=value pairs given with --set. They win over
+// the config file and the environment for the rest of this process.
+func SetFlagOverrides(pairs []string) error {
+	defaults := DefaultSettings()
+	overrides := make(map[string]any, len(pairs))
+	for _, pair := range pairs {
+		name, raw, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("--set %q: expected section.key=value", pair)
+		}
+		set, fullName, err := defaults.LookupSetting(name)
+		if err != nil {
+			return fmt.Errorf("--set: %w", err)
+		}
+		val, err := set.ParseValue(raw)
+		if err != nil {
+			return fmt.Errorf("--set %s: %w", fullName, err)
+		}
+		overrides[fullName] = val
+	}
+	flagOverridesMu.Lock()
+	flagOverrides = overrides
+	flagOverridesMu.Unlock()
+	return nil
+}
+
+// applyOverrides layers config.toml, the active profile, SURGE_* environment
+// variables and --set flags, in that order, over the values loaded from
+// settings.json. Invalid entries are skipped with a startup warning.
+func (s *Settings) applyOverrides() {
+	values, profiles, domains, warnings, err := readConfigFile(GetConfigFilePath())
+	if err != nil {
+		s.StartupWarnings = append(s.StartupWarnings, fmt.Sprintf("Config: ignoring %s: %v", GetConfigFilePath(), err))
+	}
+	s.StartupWarnings = append(s.StartupWarnings, warnings...)
+	s.domains = domains
+	for _, name := range sortedKeys(values) {
+		set, fullName, err := s.LookupSetting(name)
+		if err == nil && !strings.Contains(name, ".") {
+			err = fmt.Errorf("%q must be inside its [section]", name)
+		}
+		if err != nil {
+			s.StartupWarnings = append(s.StartupWarnings, fmt.Sprintf("Config: %v in %s", err, filepath.Base(GetConfigFilePath())))
+			continue
+		}
+		val, err := set.normalizeValue(values[name])
+		if err != nil {
+			s.StartupWarnings = append(s.StartupWarnings, fmt.Sprintf("Config: ignoring %s in %s: %v", fullName, filepath.Base(GetConfigFilePath()), err))
+			continue
+		}
+		s.override(fullName, set, SourceFile, val)
+	}
+
+	s.applyProfile(profiles)
+
+	for _, cat := range s.CategoriesList {
+		for _, set := range cat.Settings {
+			name := SettingName(cat.Name, set.Key)
+			raw, ok := os.LookupEnv(SettingEnvVar(name))
+			if !ok {
+				continue
+			}
+			val, err := set.ParseValue(raw)
+			if err != nil {
+				s.StartupWarnings = append(s.StartupWarnings, fmt.Sprintf("Config: ignoring %s: %v", SettingEnvVar(name), err))
+				continue
+			}
+			s.override(name, set, SourceEnv, val)
+		}
+	}
+
+	flagOverridesMu.RLock()
+	defer flagOverridesMu.RUnlock()
+	for name, val := range flagOverrides {
+		if set, _, err := s.LookupSetting(name); err == nil {
+			s.override(name, set, SourceFlag, val)
+		}
+	}
+}
+
+func (s *Settings) override(name string, set *Setting, source string, val any) {
+	if s.overrides == nil {
+		s.overrides = make(map[string]settingOverride)
+	}
+	base := set.Value
+	if prev, ok := s.overrides[name]; ok {
+		base = prev.base
+	}
+	s.overrides[name] = settingOverride{source: source, value: val, base: base}
+	set.Value = val
+}
+
+// withoutOverrides returns a copy for writing to settings.json, with layered
+// values swapped back for what settings.json held. A value the user has
+// changed since loading is kept.
+func (s *Settings) withoutOverrides() *Settings {
+	if len(s.overrides) == 0 {
+		return s
+	}
+	out := s.Clone()
+	if out == nil {
+		return s
+	}
+	for name, o := range s.overrides {
+		set, _, err := out.LookupSetting(name)
+		if err != nil || !reflect.DeepEqual(set.Resolve(), (&Setting{Type: set.Type, Value: o.value}).Resolve()) {
+			continue
+		}
+		set.Value = o.base
+	}
+	return out
+}
+
+// readConfigFile flattens config.toml into "section.key" names and reads
+// its [profiles.*] and [domains.*] tables, with warnings for profile, domain
+// and remote entries it cannot use. A missing file yields no values.
+func readConfigFile(path string) (map[string]any, []Profile, []DomainRule, []string, error) {
+	var raw map[string]any
+	md, err := toml.DecodeFile(path, &raw)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil, nil, nil
+		}
+		return nil, nil, nil, nil, err
+	}
+	values := make(map[string]any)
+	for section, v := range raw {
+		if section == "profiles" || section == "remotes" || section == "domains" {
+			continue
+		}
+		table, ok := v.(map[string]any)
+		if !ok {
+			values[section] = v
+			continue
+		}
+		for key, val := range table {
+			values[strings.ToLower(section)+"."+key] = val
+		}
+	}
+	profiles, warnings := parseProfiles(raw, md)
+	domains, domainWarnings := parseDomainRules(raw, md)
+	_, remoteWarnings := parseRemotes(raw, md)
+	warnings = append(warnings, domainWarnings...)
+	return values, profiles, domains, append(warnings, remoteWarnings...), nil
+}
+
+// applyProfile layers the profile chosen by general.profile. The choice
+// itself may come from any layer, so --set and the environment are checked
+// before they are applied.
+func (s *Settings) applyProfile(profiles []Profile) {
+	s.profile = ""
+	selection := Resolve[string](s.General.Profile)
+	if raw, ok := os.LookupEnv(SettingEnvVar("general.profile")); ok {
+		selection = raw
+	}
+	flagOverridesMu.RLock()
+	if v, ok := flagOverrides["general.profile"].(string); ok {
+		selection = v
+	}
+	flagOverridesMu.RUnlock()
+
+	profile, err := SelectProfile(profiles, selection)
+	if err != nil {
+		s.StartupWarnings = append(s.StartupWarnings, fmt.Sprintf("Config: %v", err))
+		return
+	}
+	if profile == nil {
+		return
+	}
+	s.profile = profile.Name
+	for _, name := range sortedKeys(profile.Values) {
+		set, fullName, err := s.LookupSetting(name)
+		if err == nil && fullName == "general.profile" {
+			err = fmt.Errorf("a profile cannot select another profile")
+		}
+		if err != nil {
+			s.StartupWarnings = append(s.StartupWarnings, fmt.Sprintf("Config: %v in profile %q", err, profile.Name))
+			continue
+		}
+		val, err := set.normalizeValue(profile.Values[name])
+		if err != nil {
+			s.StartupWarnings = append(s.StartupWarnings, fmt.Sprintf("Config: ignoring %s in profile %q: %v", fullName, profile.Name, err))
+			continue
+		}
+		s.override(fullName, set, SourceProfile, val)
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SetConfigFileValue writes name = value into config.toml, replacing an
+// existing or commented-out entry for it and keeping everything else as is.
+func SetConfigFileValue(name string, value any) error {
+	section, key, ok := strings.Cut(name, ".")
+	if !ok {
+		return fmt.Errorf("setting name %q must be section.key", name)
+	}
+	if d, ok := value.(time.Duration); ok {
+		value = d.String()
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(map[string]any{key: value}); err != nil {
+		return err
+	}
+	line := strings.TrimSpace(buf.String())
+
+	path := GetConfigFilePath()
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	updated := setConfigLine(string(data), section, key, line)
+	return writeFileAtomic(path, []byte(updated), 0o600)
+}
+
+var (
+	tomlSectionRe = regexp.MustCompile(`^\s*\[\s*([^\]\s]+)\s*\]\s*(#.*)?$`)
+	tomlKeyRe     = regexp.MustCompile(`^\s*(#\s*)?([A-Za-z0-9_-]+)\s*=`)
+)
+
+func setConfigLine(content, section, key, line string) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if content == "" {
+		lines = nil
+	}
+	current, active, commented, sectionEnd := "", -1, -1, -1
+	for i, l := range lines {
+		if m := tomlSectionRe.FindStringSubmatch(l); m != nil {
+			current = strings.ToLower(m[1])
+			continue
+		}
+		if current != section {
+			continue
+		}
+		if strings.TrimSpace(l) != "" {
+			sectionEnd = i
+		}
+		if m := tomlKeyRe.FindStringSubmatch(l); m != nil && m[2] == key {
+			if m[1] == "" {
+				active = i
+			} else if commented < 0 {
+				commented = i
+			}
+		}
+	}
+
+	switch {
+	case active >= 0:
+		lines[active] = line
+	case commented >= 0:
+		lines[commented] = line
+	case sectionEnd >= 0:
+		lines = append(lines[:sectionEnd+1], append([]string{line}, lines[sectionEnd+1:]...)...)
+	default:
+		header := "[" + section + "]"
+		if idx := indexOfSection(lines, section); idx >= 0 {
+			lines = append(lines[:idx+1], append([]string{line}, lines[idx+1:]...)...)
+			break
+		}
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, header, line)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func indexOfSection(lines []string, section string) int {
+	for i, l := range lines {
+		if m := tomlSectionRe.FindStringSubmatch(l); m != nil && strings.ToLower(m[1]) == section {
+			return i
+		}
+	}
+	return -1
+}
+
+// WriteConfigFileTemplate creates config.toml listing every setting, commented
+// out with its description and default, unless the file already exists.
+func WriteConfigFileTemplate() error {
+	path := GetConfigFilePath()
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	var b strings.Builder
+	b.WriteString(`# Surge configuration file.
+#
+# Settings here override the ones saved from the TUI. Each can also be set
+# with an environment variable named SURGE_<SECTION>_<KEY>, or for a single
+# run with --set section.key=value. Flags win over the environment, which
+# wins over this file.
+#
+# Uncomment a line to change it. Durations are written as strings ("30s"),
+# sizes in bytes. 'surge config set' edits this file and tells a running
+# Surge instance to reload it.
+#
+# Profiles are named sets of settings layered over this file, switched with
+# 'surge profile use <name>'. With general.profile = "auto" the first profile
+# whose match_ssid or match_interface fits the current network is used:
+#
+# [profiles.work]
+# match_ssid = ["CorpWiFi"]
+# match_interface = ["tun0"]
+#
+# [profiles.work.network]
+# proxy_url = "http://proxy.corp.example:3128"
+# global_rate_limit = "2MB"
+#
+# [profiles.work.general]
+# default_download_dir = "/data/work"
+#
+# Domain rules hold settings for one host, or for every subdomain with
+# "*.example.com". dir is the download directory for the host, and pins lists
+# SPKI public key pins, one of which the host's certificate chain must carry,
+# even with tls_insecure:
+#
+# [domains."downloads.example.com"]
+# dir = "~/Downloads/{domain}"
+# pins = ["sha256/AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="]
+#
+# Remotes name other Surge daemons that 'surge push --remote <name>' sends
+# downloads to:
+#
+# [remotes.seedbox]
+# host = "https://seedbox.example:1700"
+# token = "..."
+`)
+	s := DefaultSettings()
+	for _, cat := range s.CategoriesList {
+		fmt.Fprintf(&b, "\n[%s]\n", strings.ToLower(cat.Name))
+		for _, set := range cat.Settings {
+			var buf bytes.Buffer
+			value := set.DefaultValue
+			if d, ok := value.(time.Duration); ok {
+				value = d.String()
+			}
+			if err := toml.NewEncoder(&buf).Encode(map[string]any{set.Key: value}); err != nil {
+				continue
+			}
+			fmt.Fprintf(&b, "\n# %s\n# %s\n", set.Description, strings.TrimSpace(buf.String()))
+		}
+	}
+	return writeFileAtomic(path, []byte(b.String()), 0o600)
+}
+
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tempPath := path + ".tmp"
+	if err := os.WriteFile(tempPath, data, perm); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, path)
+}
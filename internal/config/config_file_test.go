@@ -0,0 +1,180 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func setupConfigDir(t *testing.T) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tmpDir)
+	t.Setenv("APPDATA", tmpDir)
+	if err := os.MkdirAll(GetSurgeDir(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = SetFlagOverrides(nil) })
+}
+
+func TestLoadSettings_LayersFileEnvAndFlags(t *testing.T) {
+	setupConfigDir(t)
+
+	saved := DefaultSettings()
+	saved.Network.MaxConcurrentDownloads.Value = 2
+	saved.Network.MaxRedirects.Value = 3
+	if err := SaveSettings(saved); err != nil {
+		t.Fatalf("SaveSettings: %v", err)
+	}
+	file := "[network]\nmax_concurrent_downloads = 4\nmax_redirects = 5\nuser_agent = \"from-file\"\n\n[performance]\nstall_timeout = \"9s\"\n"
+	if err := os.WriteFile(GetConfigFilePath(), []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SURGE_NETWORK_MAX_REDIRECTS", "6")
+	t.Setenv("SURGE_NETWORK_USER_AGENT", "from-env")
+	if err := SetFlagOverrides([]string{"network.user_agent=from-flag"}); err != nil {
+		t.Fatalf("SetFlagOverrides: %v", err)
+	}
+
+	s, err := LoadSettings()
+	if err != nil {
+		t.Fatalf("LoadSettings: %v", err)
+	}
+	if len(s.StartupWarnings) != 0 {
+		t.Fatalf("unexpected warnings: %v", s.StartupWarnings)
+	}
+	checks := []struct {
+		name, source string
+		got, want    any
+	}{
+		{"network.max_concurrent_downloads", SourceFile, Resolve[int](s.Network.MaxConcurrentDownloads), 4},
+		{"network.max_redirects", SourceEnv, Resolve[int](s.Network.MaxRedirects), 6},
+		{"network.user_agent", SourceFlag, Resolve[string](s.Network.UserAgent), "from-flag"},
+		{"performance.stall_timeout", SourceFile, Resolve[time.Duration](s.Performance.StallTimeout), 9 * time.Second},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+		if got := s.Source(c.name); got != c.source {
+			t.Errorf("Source(%s) = %q, want %q", c.name, got, c.source)
+		}
+	}
+	if got := s.Source("general.auto_resume"); got != SourceDefault {
+		t.Errorf("Source(general.auto_resume) = %q, want default", got)
+	}
+}
+
+func TestSaveSettings_KeepsLayeredValuesOutOfSettingsJSON(t *testing.T) {
+	setupConfigDir(t)
+	if err := os.WriteFile(GetConfigFilePath(), []byte("[network]\nmax_redirects = 7\nuser_agent = \"file-agent\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadSettings()
+	if err != nil {
+		t.Fatalf("LoadSettings: %v", err)
+	}
+	// A value changed after loading, e.g. in the TUI, is saved as usual.
+	s.Network.UserAgent.Value = "tui-agent"
+	if err := SaveSettings(s); err != nil {
+		t.Fatalf("SaveSettings: %v", err)
+	}
+
+	data, err := os.ReadFile(GetSettingsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved struct {
+		Network map[string]any `json:"network"`
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if got := saved.Network["max_redirects"]; got == float64(7) {
+		t.Errorf("settings.json got the config.toml value for max_redirects")
+	}
+	if got := saved.Network["user_agent"]; got != "tui-agent" {
+		t.Errorf("settings.json user_agent = %v, want the value changed after loading", got)
+	}
+}
+
+func TestLoadSettings_WarnsAboutBadConfigEntries(t *testing.T) {
+	setupConfigDir(t)
+	file := "[network]\nmax_redirects = \"lots\"\nno_such_key = 1\n"
+	if err := os.WriteFile(GetConfigFilePath(), []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SURGE_NETWORK_MAX_CONCURRENT_DOWNLOADS", "many")
+
+	s, err := LoadSettings()
+	if err != nil {
+		t.Fatalf("LoadSettings: %v", err)
+	}
+	if len(s.StartupWarnings) != 3 {
+		t.Fatalf("warnings = %q, want one per bad entry", s.StartupWarnings)
+	}
+	if s.Source("network.max_redirects") != SourceDefault {
+		t.Errorf("invalid max_redirects was applied")
+	}
+}
+
+func TestSetConfigFileValue_EditsInPlace(t *testing.T) {
+	setupConfigDir(t)
+	if err := WriteConfigFileTemplate(); err != nil {
+		t.Fatalf("WriteConfigFileTemplate: %v", err)
+	}
+	if s, _ := LoadSettings(); len(s.StartupWarnings) != 0 {
+		t.Fatalf("template produced warnings: %v", s.StartupWarnings)
+	}
+
+	if err := SetConfigFileValue("network.max_redirects", 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetConfigFileValue("network.max_redirects", 8); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetConfigFileValue("performance.stall_timeout", 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(GetConfigFilePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	if strings.Count(content, "max_redirects") != 1 || !strings.Contains(content, "\nmax_redirects = 8\n") {
+		t.Errorf("max_redirects should replace its commented default once:\n%s", content)
+	}
+	if !strings.Contains(content, "# Surge configuration file.") {
+		t.Errorf("header comment was lost")
+	}
+
+	s, err := LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Resolve[time.Duration](s.Performance.StallTimeout); got != 10*time.Second {
+		t.Errorf("stall_timeout = %v, want 10s", got)
+	}
+}
+
+func TestSetConfigLine_AddsMissingSection(t *testing.T) {
+	got := setConfigLine("[general]\nauto_resume = true\n", "network", "max_redirects", "max_redirects = 3")
+	want := "[general]\nauto_resume = true\n\n[network]\nmax_redirects = 3\n"
+	if got != want {
+		t.Fatalf("setConfigLine() = %q, want %q", got, want)
+	}
+}
+
+func TestLookupSetting(t *testing.T) {
+	s := DefaultSettings()
+	if _, name, err := s.LookupSetting("max_redirects"); err != nil || name != "network.max_redirects" {
+		t.Fatalf("LookupSetting(bare key) = %q, %v", name, err)
+	}
+	if _, _, err := s.LookupSetting("general.max_redirects"); err == nil {
+		t.Fatal("LookupSetting should reject a key in the wrong section")
+	}
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+// DomainRule holds what config.toml sets for one host in a
+// [domains."<host>"] table. Host is an exact name, or "*.example.com" for
+// every subdomain of example.com.
+type DomainRule struct {
+	Host string
+	// Pins are SPKI pins, as sha256/BASE64, of which the host's certificate
+	// chain must carry one. More than one allows for a backup key.
+	Pins []string
+	// Dir is the download directory, which may be a template, for downloads
+	// from the host on the default path.
+	Dir string
+	// Interface is the interface name or local IP downloads from the host
+	// connect from, overriding bind_interface.
+	Interface string
+}
+
+// parseDomainRules reads the [domains.*] tables decoded into raw. Entries it
+// cannot use are returned as warnings.
+func parseDomainRules(raw map[string]any, md toml.MetaData) ([]DomainRule, []string) {
+	tables, _ := raw["domains"].(map[string]any)
+	if len(tables) == 0 {
+		return nil, nil
+	}
+
+	var order []string
+	for _, key := range md.Keys() {
+		if len(key) >= 2 && key[0] == "domains" && !slices.Contains(order, key[1]) {
+			order = append(order, key[1])
+		}
+	}
+
+	var rules []DomainRule
+	var warnings []string
+	for _, host := range order {
+		table, ok := tables[host].(map[string]any)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("Config: domains.%q must be a table", host))
+			continue
+		}
+		rule, err := types.ParseHostRule(host)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("Config: ignoring domains.%q: %v", host, err))
+			continue
+		}
+		r := DomainRule{Host: rule}
+		for key, v := range table {
+			switch key {
+			case "pins":
+				list, err := stringList(v)
+				if err == nil {
+					_, err = types.ParseTLSPins(pinSpec([]DomainRule{{Host: r.Host, Pins: list}}))
+				}
+				if err != nil {
+					warnings = append(warnings, fmt.Sprintf("Config: ignoring domains.%q.pins: %v", host, err))
+					continue
+				}
+				r.Pins = list
+			case "dir":
+				dir, ok := v.(string)
+				var err error
+				if !ok || strings.TrimSpace(dir) == "" {
+					err = fmt.Errorf("must be a directory")
+				} else {
+					err = ValidatePathTemplate(dir)
+				}
+				if err != nil {
+					warnings = append(warnings, fmt.Sprintf("Config: ignoring domains.%q.dir: %v", host, err))
+					continue
+				}
+				r.Dir = strings.TrimSpace(dir)
+			case "interface":
+				iface, ok := v.(string)
+				var err error
+				if !ok || strings.TrimSpace(iface) == "" {
+					err = fmt.Errorf("must be an interface name or local IP")
+				} else {
+					err = types.ValidateBindInterface(strings.TrimSpace(iface))
+				}
+				if err != nil {
+					warnings = append(warnings, fmt.Sprintf("Config: ignoring domains.%q.interface: %v", host, err))
+					continue
+				}
+				r.Interface = strings.TrimSpace(iface)
+			default:
+				warnings = append(warnings, fmt.Sprintf("Config: ignoring unknown key domains.%q.%s", host, key))
+			}
+		}
+		rules = append(rules, r)
+	}
+	return rules, warnings
+}
+
+// pinSpec returns the pins of rules in the form types.ParseTLSPins accepts.
+func pinSpec(rules []DomainRule) string {
+	var entries []string
+	for _, r := range rules {
+		for _, pin := range r.Pins {
+			entries = append(entries, r.Host+"="+strings.TrimSpace(pin))
+		}
+	}
+	return strings.Join(entries, ",")
+}
+
+// DomainDirs returns the download directory of each domain rule that sets one.
+func (s *Settings) DomainDirs() types.HostRules[string] {
+	dirs := types.HostRules[string]{}
+	for _, r := range s.domains {
+		if r.Dir != "" {
+			dirs[r.Host] = r.Dir
+		}
+	}
+	return dirs
+}
+
+// domainInterfaces returns the interface of each domain rule that sets one.
+func (s *Settings) domainInterfaces() types.HostRules[string] {
+	ifaces := types.HostRules[string]{}
+	for _, r := range s.domains {
+		if r.Interface != "" {
+			ifaces[r.Host] = r.Interface
+		}
+	}
+	return ifaces
+}
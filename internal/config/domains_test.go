@@ -0,0 +1,122 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+const (
+	testPin   = "sha256/47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="
+	backupPin = "sha256/LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ="
+)
+
+func TestLoadSettings_DomainRulePins(t *testing.T) {
+	setupConfigDir(t)
+	file := `[domains."downloads.example.com"]
+pins = ["` + testPin + `", "` + backupPin + `"]
+
+[domains."*.cdn.example.org"]
+pins = "` + testPin + `"
+
+[domains."bad.example.com"]
+pins = ["sha256/not-base64"]
+`
+	if err := os.WriteFile(GetConfigFilePath(), []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.StartupWarnings) != 1 || !strings.Contains(s.StartupWarnings[0], "bad.example.com") {
+		t.Fatalf("warnings = %q, want one for the invalid pin", s.StartupWarnings)
+	}
+
+	pins, err := types.ParseTLSPins(s.ToRuntimeConfig().TLS.Pins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := pins.ForHost("downloads.example.com"); len(got) != 2 || got[0] != testPin || got[1] != backupPin {
+		t.Errorf("downloads.example.com pins = %v, want both", got)
+	}
+	if got := pins.ForHost("a.cdn.example.org"); len(got) != 1 {
+		t.Errorf("a.cdn.example.org pins = %v, want the wildcard rule's", got)
+	}
+	if got := pins.ForHost("bad.example.com"); got != nil {
+		t.Errorf("bad.example.com pins = %v, want none", got)
+	}
+	if got := s.Clone().ToRuntimeConfig().TLS.Pins; got != s.ToRuntimeConfig().TLS.Pins {
+		t.Errorf("cloned settings pins = %q, want the same", got)
+	}
+}
+
+func TestLoadSettings_DomainRuleDirs(t *testing.T) {
+	setupConfigDir(t)
+	file := `[domains."example.com"]
+dir = "/a"
+
+[domains."*.example.org"]
+dir = "/b/{yyyy}"
+
+[domains."a.*.net"]
+dir = "/c"
+
+[domains."bad.example.com"]
+dir = "/{nope}"
+`
+	if err := os.WriteFile(GetConfigFilePath(), []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.StartupWarnings) != 2 {
+		t.Fatalf("warnings = %q, want one for the bad host and one for the bad template", s.StartupWarnings)
+	}
+	dirs := s.Clone().DomainDirs()
+	for host, want := range map[string]string{"Example.com": "/a", "dl.example.org": "/b/{yyyy}", "example.org": "", "bad.example.com": ""} {
+		if got, _ := dirs.ForHost(host); got != want {
+			t.Errorf("dir for %s = %q, want %q", host, got, want)
+		}
+	}
+}
+
+func TestLoadSettings_DomainRuleInterfaces(t *testing.T) {
+	setupConfigDir(t)
+	file := `[domains."tracker.example.org"]
+interface = "tun0"
+
+[domains."*.corp.net"]
+interface = "10.8.0.2"
+
+[domains."bad.example.com"]
+interface = "tun 0"
+`
+	if err := os.WriteFile(GetConfigFilePath(), []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.StartupWarnings) != 1 || !strings.Contains(s.StartupWarnings[0], "bad.example.com") {
+		t.Fatalf("warnings = %q, want one for the invalid interface", s.StartupWarnings)
+	}
+	runtime := s.ToRuntimeConfig()
+	for rawurl, want := range map[string]string{
+		"https://tracker.example.org/a.torrent": "tun0",
+		"https://git.corp.net/repo.tar":         "10.8.0.2",
+		"https://bad.example.com/a.iso":         "",
+	} {
+		if got := types.BindRuntime(runtime, "", rawurl).BindInterface; got != want {
+			t.Errorf("interface for %s = %q, want %q", rawurl, got, want)
+		}
+	}
+}
@@ -28,6 +28,7 @@ type KeyMap struct {
 	CategoryMgr    CategoryManagerKeyMap `json:"category_mgr"`
 	SpeedLimits    SpeedLimitsKeyMap     `json:"speed_limits"`
 	QuitConfirm    QuitConfirmKeyMap     `json:"quit_confirm"`
+	Onboarding     OnboardingKeyMap      `json:"onboarding"`
 
 	// StartupWarnings holds validation messages from the most recent LoadKeyMap call.
 	// It is ignored during JSON serialization.
@@ -45,6 +46,7 @@ type DashboardKeyMap struct {
 	BatchImport    key.Binding
 	Search         key.Binding
 	Pause          key.Binding
+	PauseAll       key.Binding
 	Refresh        key.Binding
 	Delete         key.Binding
 	PurgeFile      key.Binding
@@ -55,10 +57,17 @@ type DashboardKeyMap struct {
 	ReportBug      key.Binding
 	OpenFile       key.Binding
 	OpenFolder     key.Binding
+	CopyURL        key.Binding
+	CopyPath       key.Binding
+	QRCode         key.Binding
+	SpeedHistory   key.Binding
 	Quit           key.Binding
 	ForceQuit      key.Binding
 	CategoryFilter key.Binding
 	PinTab         key.Binding
+	ToggleLayout   key.Binding
+	DetailPane     key.Binding
+	DownloadLog    key.Binding
 	// Navigation
 	Up   key.Binding
 	Down key.Binding
@@ -78,6 +87,8 @@ type InputKeyMap struct {
 	Up     key.Binding
 	Down   key.Binding
 	Cancel key.Binding
+	// RecentDir fills the path with a recently used directory
+	RecentDir key.Binding
 }
 
 // FilePickerKeyMap defines keybindings for the file picker
@@ -88,6 +99,8 @@ type FilePickerKeyMap struct {
 	Forward  key.Binding
 	Open     key.Binding
 	Cancel   key.Binding
+	// RecentDir picks a recently used directory
+	RecentDir key.Binding
 }
 
 // DuplicateKeyMap defines keybindings for duplicate warning
@@ -172,6 +185,14 @@ type CategoryManagerKeyMap struct {
 	Close  key.Binding
 }
 
+// OnboardingKeyMap defines keybindings for the first-run setup wizard
+type OnboardingKeyMap struct {
+	Next   key.Binding
+	Back   key.Binding
+	Change key.Binding
+	Skip   key.Binding
+}
+
 // SpeedLimitsKeyMap defines keybindings for speed limits
 type SpeedLimitsKeyMap struct {
 	Up    key.Binding
@@ -202,6 +223,7 @@ type KeyMapConfig struct {
 	CategoryMgr    map[string]KeyBindingConfig `json:"category_mgr"`
 	SpeedLimits    map[string]KeyBindingConfig `json:"speed_limits"`
 	QuitConfirm    map[string]KeyBindingConfig `json:"quit_confirm"`
+	Onboarding     map[string]KeyBindingConfig `json:"onboarding"`
 }
 
 // GetKeyMapConfigPath returns the path to the Keymaps JSON file.
@@ -305,6 +327,7 @@ func (k *KeyMap) ApplyConfig(cfg *KeyMapConfig) {
 	applyToStruct(&k.CategoryMgr, cfg.CategoryMgr)
 	applyToStruct(&k.SpeedLimits, cfg.SpeedLimits)
 	applyToStruct(&k.QuitConfirm, cfg.QuitConfirm)
+	applyToStruct(&k.Onboarding, cfg.Onboarding)
 }
 
 // ToConfig converts KeyMap to KeyMapConfig for serialization.
@@ -340,6 +363,7 @@ func (k *KeyMap) ToConfig() *KeyMapConfig {
 		CategoryMgr:    structToMap(k.CategoryMgr),
 		SpeedLimits:    structToMap(k.SpeedLimits),
 		QuitConfirm:    structToMap(k.QuitConfirm),
+		Onboarding:     structToMap(k.Onboarding),
 	}
 }
 
@@ -422,13 +446,17 @@ func DefaultKeyMap() *KeyMap {
 				key.WithHelp("b", "batch import"),
 			),
 			Search: key.NewBinding(
-				key.WithKeys("f"),
-				key.WithHelp("f", "search"),
+				key.WithKeys("/", "f"),
+				key.WithHelp("/", "search"),
 			),
 			Pause: key.NewBinding(
 				key.WithKeys("p"),
 				key.WithHelp("p", "pause/resume"),
 			),
+			PauseAll: key.NewBinding(
+				key.WithKeys("P"),
+				key.WithHelp("P", "pause/resume all"),
+			),
 			Refresh: key.NewBinding(
 				key.WithKeys("r"),
 				key.WithHelp("r", "refresh url"),
@@ -469,6 +497,22 @@ func DefaultKeyMap() *KeyMap {
 				key.WithKeys("O"),
 				key.WithHelp("O", "open folder"),
 			),
+			CopyURL: key.NewBinding(
+				key.WithKeys("y"),
+				key.WithHelp("y", "copy url"),
+			),
+			CopyPath: key.NewBinding(
+				key.WithKeys("Y"),
+				key.WithHelp("Y", "copy path"),
+			),
+			QRCode: key.NewBinding(
+				key.WithKeys("Q"),
+				key.WithHelp("Q", "qr code"),
+			),
+			SpeedHistory: key.NewBinding(
+				key.WithKeys("S"),
+				key.WithHelp("S", "speed history"),
+			),
 			Quit: key.NewBinding(
 				key.WithKeys("ctrl+c", "ctrl+q"),
 				key.WithHelp("ctrl+q", "quit"),
@@ -485,6 +529,18 @@ func DefaultKeyMap() *KeyMap {
 				key.WithKeys("t"),
 				key.WithHelp("t", "pin tab"),
 			),
+			ToggleLayout: key.NewBinding(
+				key.WithKeys("v"),
+				key.WithHelp("v", "compact/detailed"),
+			),
+			DetailPane: key.NewBinding(
+				key.WithKeys("d"),
+				key.WithHelp("d", "detail pane"),
+			),
+			DownloadLog: key.NewBinding(
+				key.WithKeys("L"),
+				key.WithHelp("L", "download log"),
+			),
 			Up: key.NewBinding(
 				key.WithKeys("up", "k"),
 				key.WithHelp("\u2191/k", "up"),
@@ -539,6 +595,10 @@ func DefaultKeyMap() *KeyMap {
 				key.WithKeys("esc"),
 				key.WithHelp("esc", "cancel"),
 			),
+			RecentDir: key.NewBinding(
+				key.WithKeys("alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6", "alt+7", "alt+8", "alt+9"),
+				key.WithHelp("alt+1-9", "recent dir"),
+			),
 		},
 		FilePicker: FilePickerKeyMap{
 			UseDir: key.NewBinding(
@@ -565,6 +625,10 @@ func DefaultKeyMap() *KeyMap {
 				key.WithKeys("esc"),
 				key.WithHelp("esc", "cancel"),
 			),
+			RecentDir: key.NewBinding(
+				key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"),
+				key.WithHelp("1-9", "recent dir"),
+			),
 		},
 		Duplicate: DuplicateKeyMap{
 			Continue: key.NewBinding(
@@ -747,6 +811,12 @@ func DefaultKeyMap() *KeyMap {
 				key.WithHelp("n/esc", "cancel"),
 			),
 		},
+		Onboarding: OnboardingKeyMap{
+			Next:   key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "next")),
+			Back:   key.NewBinding(key.WithKeys("shift+tab"), key.WithHelp("shift+tab", "back")),
+			Change: key.NewBinding(key.WithKeys("left", "right", "space"), key.WithHelp("\u2190/\u2192", "change")),
+			Skip:   key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "skip setup")),
+		},
 		StartupWarnings: nil,
 	}
 }
@@ -760,25 +830,25 @@ func (k DashboardKeyMap) ShortHelp() []key.Binding {
 func (k DashboardKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.TabQueued, k.TabActive, k.TabDone, k.NextTab, k.PrevTab},
-		{k.Add, k.BatchImport, k.Search, k.CategoryFilter, k.Pause, k.Refresh, k.Delete, k.PurgeFile, k.Settings, k.SpeedLimits, k.PinTab},
-		{k.Log, k.OpenFile, k.OpenFolder, k.ReportBug, k.Quit},
+		{k.Add, k.BatchImport, k.Search, k.CategoryFilter, k.Pause, k.PauseAll, k.Refresh, k.Delete, k.PurgeFile, k.Settings, k.SpeedLimits, k.PinTab, k.ToggleLayout, k.DetailPane, k.DownloadLog},
+		{k.Log, k.OpenFile, k.OpenFolder, k.CopyURL, k.CopyPath, k.QRCode, k.SpeedHistory, k.ReportBug, k.Quit},
 	}
 }
 
 func (k InputKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Tab, k.Enter, k.Esc}
+	return []key.Binding{k.Tab, k.Enter, k.Esc, k.RecentDir}
 }
 
 func (k InputKeyMap) FullHelp() [][]key.Binding {
-	return [][]key.Binding{{k.Tab, k.Enter, k.Esc}}
+	return [][]key.Binding{{k.Tab, k.Enter, k.Esc, k.RecentDir}}
 }
 
 func (k FilePickerKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Back, k.Forward, k.UseDir, k.GotoHome, k.Open, k.Cancel}
+	return []key.Binding{k.Back, k.Forward, k.UseDir, k.GotoHome, k.Open, k.RecentDir, k.Cancel}
 }
 
 func (k FilePickerKeyMap) FullHelp() [][]key.Binding {
-	return [][]key.Binding{{k.Back, k.Forward, k.UseDir, k.GotoHome, k.Open, k.Cancel}}
+	return [][]key.Binding{{k.Back, k.Forward, k.UseDir, k.GotoHome, k.Open, k.RecentDir, k.Cancel}}
 }
 
 func (k DuplicateKeyMap) ShortHelp() []key.Binding {
@@ -868,3 +938,11 @@ func (k QuitConfirmKeyMap) ShortHelp() []key.Binding {
 func (k QuitConfirmKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{{k.Select, k.Cancel}}
 }
+
+func (k OnboardingKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Next, k.Back, k.Change, k.Skip}
+}
+
+func (k OnboardingKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{{k.Next, k.Back, k.Change, k.Skip}}
+}
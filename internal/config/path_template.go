@@ -0,0 +1,155 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// Download directories may be templates, filled in when a download is queued:
+//
+//	{category}  category the file falls in, or "Other"
+//	{domain}    host of the URL, without "www."
+//	{filename}  file name; in the last path element it names the file itself
+//	{name}      file name without its extension
+//	{ext}       extension without the dot, or "none"
+//	{yyyy} {mm} {dd}  date the download was queued
+//
+// For example "~/Downloads/{category}/{yyyy}/{mm}" or "~/mirror/{domain}/{filename}".
+var pathPlaceholder = regexp.MustCompile(`\{([a-z]+)\}`)
+
+var pathPlaceholders = map[string]bool{
+	"category": true,
+	"domain":   true,
+	"filename": true,
+	"name":     true,
+	"ext":      true,
+	"yyyy":     true,
+	"mm":       true,
+	"dd":       true,
+}
+
+// PathTemplateVars are the values a path template is filled in with.
+type PathTemplateVars struct {
+	URL      string
+	Filename string
+	Category string
+	Time     time.Time
+}
+
+// IsPathTemplate reports whether path contains placeholders.
+func IsPathTemplate(path string) bool {
+	for _, m := range pathPlaceholder.FindAllStringSubmatch(path, -1) {
+		if pathPlaceholders[m[1]] {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePathTemplate rejects unknown placeholders, so a typo does not end up
+// as a literal directory name.
+func ValidatePathTemplate(path string) error {
+	for _, m := range pathPlaceholder.FindAllStringSubmatch(path, -1) {
+		if !pathPlaceholders[m[1]] {
+			return fmt.Errorf("unknown placeholder %s in %q", m[0], path)
+		}
+	}
+	return nil
+}
+
+// PathTemplateBase returns the part of path before its first placeholder,
+// the directory that has to exist before the download is queued. It is empty
+// when the template starts with a placeholder.
+func PathTemplateBase(path string) string {
+	path = utils.ExpandHome(strings.TrimSpace(path))
+	i := strings.Index(path, "{")
+	if i < 0 || !IsPathTemplate(path) {
+		return path
+	}
+	if j := strings.LastIndexAny(path[:i], `/\`); j >= 0 {
+		return path[:max(j, 1)]
+	}
+	return ""
+}
+
+// ExpandPathTemplate fills in the placeholders of a download directory. When
+// the last element uses {filename}, it names the file and is returned as
+// filename; otherwise filename is vars.Filename.
+func ExpandPathTemplate(path string, vars PathTemplateVars) (dir, filename string) {
+	path = utils.ExpandHome(strings.TrimSpace(path))
+	filename = vars.Filename
+
+	dirTemplate, last := path, ""
+	if i := strings.LastIndexAny(path, `/\`); i >= 0 && strings.Contains(path[i+1:], "{filename}") {
+		dirTemplate, last = path[:i], path[i+1:]
+	}
+
+	values := pathTemplateValues(vars)
+	expand := func(s string) string {
+		return pathPlaceholder.ReplaceAllStringFunc(s, func(m string) string {
+			if v, ok := values[m[1:len(m)-1]]; ok {
+				return v
+			}
+			return m
+		})
+	}
+
+	dir = expand(dirTemplate)
+	if last != "" && vars.Filename != "" {
+		filename = expand(last)
+	}
+	return dir, filename
+}
+
+// pathTemplateValues returns each placeholder's value, made safe to use as a
+// single path element.
+func pathTemplateValues(vars PathTemplateVars) map[string]string {
+	when := vars.Time
+	if when.IsZero() {
+		when = time.Now()
+	}
+
+	domain := ""
+	if u, err := url.Parse(strings.TrimSpace(vars.URL)); err == nil {
+		domain = strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	}
+	category := vars.Category
+	if category == "" {
+		category = "Other"
+	}
+	ext := strings.TrimPrefix(filepath.Ext(vars.Filename), ".")
+	name := strings.TrimSuffix(vars.Filename, filepath.Ext(vars.Filename))
+	if ext == "" {
+		ext = "none"
+	}
+
+	values := map[string]string{
+		"category": category,
+		"domain":   domain,
+		"filename": vars.Filename,
+		"name":     name,
+		"ext":      strings.ToLower(ext),
+		"yyyy":     when.Format("2006"),
+		"mm":       when.Format("01"),
+		"dd":       when.Format("02"),
+	}
+	for k, v := range values {
+		values[k] = pathElement(v)
+	}
+	return values
+}
+
+// pathElement keeps a value from adding or escaping directories.
+func pathElement(s string) string {
+	s = strings.NewReplacer("/", "_", `\`, "_", ":", "_").Replace(strings.TrimSpace(s))
+	if s == "" || s == "." || s == ".." {
+		return "_"
+	}
+	return s
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExpandPathTemplate(t *testing.T) {
+	when := time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC)
+	vars := PathTemplateVars{URL: "https://www.example.com/f", Filename: "Report.PDF", Time: when}
+
+	tests := []struct {
+		tmpl, dir, name string
+	}{
+		{filepath.Join("dl", "{category}", "{yyyy}", "{mm}"), filepath.Join("dl", "Other", "2026", "03"), "Report.PDF"},
+		{filepath.Join("dl", "{ext}", "{dd}"), filepath.Join("dl", "pdf", "07"), "Report.PDF"},
+		{filepath.Join("{domain}", "{filename}"), "example.com", "Report.PDF"},
+		{filepath.Join("dl", "{yyyy}-{name}.{ext}-{filename}"), "dl", "2026-Report.pdf-Report.PDF"},
+		{filepath.Join("dl", "{unknown}"), filepath.Join("dl", "{unknown}"), "Report.PDF"},
+	}
+	for _, tt := range tests {
+		dir, name := ExpandPathTemplate(tt.tmpl, vars)
+		if dir != tt.dir || name != tt.name {
+			t.Errorf("ExpandPathTemplate(%q) = %q, %q; want %q, %q", tt.tmpl, dir, name, tt.dir, tt.name)
+		}
+	}
+
+	// Values never add or escape directories
+	dir, _ := ExpandPathTemplate(filepath.Join("dl", "{category}"), PathTemplateVars{Category: "../x/y"})
+	if dir != filepath.Join("dl", ".._x_y") {
+		t.Errorf("dir = %q, want the category kept to one element", dir)
+	}
+}
+
+func TestPathTemplateBase(t *testing.T) {
+	tests := map[string]string{
+		"/data/downloads":            "/data/downloads",
+		"/data/{domain}/{yyyy}":      "/data",
+		"/{category}":                "/",
+		"{domain}/x":                 "",
+		"/data/lit{eral}/{category}": "/data",
+	}
+	for in, want := range tests {
+		if got := PathTemplateBase(in); got != filepath.FromSlash(want) && got != want {
+			t.Errorf("PathTemplateBase(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if err := ValidatePathTemplate("/data/{cateogry}"); err == nil {
+		t.Error("expected an unknown placeholder to be rejected")
+	}
+}
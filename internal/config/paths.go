@@ -149,6 +149,16 @@ func GetThemesDir() string {
 	return filepath.Join(GetSurgeDir(), "themes")
 }
 
+// GetPluginsDir returns the directory for URL plugins
+func GetPluginsDir() string {
+	return filepath.Join(GetSurgeDir(), "plugins")
+}
+
+// GetScriptPath returns the path of the scripting hooks file
+func GetScriptPath() string {
+	return filepath.Join(GetSurgeDir(), "hooks.star")
+}
+
 // EnsureDirs creates all required directories
 func EnsureDirs() error {
 	dirs := []string{GetSurgeDir(), GetStateDir(), GetRuntimeDir(), GetLogsDir(), GetThemesDir()}
@@ -0,0 +1,199 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// ProfileAuto selects the first profile whose network rules match the
+// current Wi-Fi network or interfaces.
+const ProfileAuto = "auto"
+
+// Profile is a named set of settings from a [profiles.<name>] table in
+// config.toml, e.g. a proxy and speed limit for work and others for home.
+type Profile struct {
+	Name           string
+	MatchSSID      []string
+	MatchInterface []string
+
+	// Values holds the profile's settings by "section.key", as decoded from
+	// TOML.
+	Values map[string]any
+}
+
+// HasNetworkRules reports whether the profile can be picked automatically.
+func (p Profile) HasNetworkRules() bool {
+	return len(p.MatchSSID) > 0 || len(p.MatchInterface) > 0
+}
+
+// Matches reports whether any of the profile's rules matches the given Wi-Fi
+// networks or active interfaces. SSIDs compare exactly, interface names
+// case-insensitively.
+func (p Profile) Matches(ssids, interfaces []string) bool {
+	for _, want := range p.MatchSSID {
+		if slices.Contains(ssids, want) {
+			return true
+		}
+	}
+	for _, want := range p.MatchInterface {
+		if slices.ContainsFunc(interfaces, func(name string) bool { return strings.EqualFold(name, want) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadProfiles returns the profiles defined in config.toml, in file order.
+func LoadProfiles() ([]Profile, error) {
+	var raw map[string]any
+	md, err := toml.DecodeFile(GetConfigFilePath(), &raw)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	profiles, _ := parseProfiles(raw, md)
+	return profiles, nil
+}
+
+// parseProfiles reads the [profiles.*] tables decoded into raw. Entries it
+// cannot use are returned as warnings.
+func parseProfiles(raw map[string]any, md toml.MetaData) ([]Profile, []string) {
+	tables, _ := raw["profiles"].(map[string]any)
+	if len(tables) == 0 {
+		return nil, nil
+	}
+
+	// Keys come in file order; a profile given only as [profiles.x.network]
+	// first shows up as part of a longer key.
+	var order []string
+	for _, key := range md.Keys() {
+		if len(key) >= 2 && key[0] == "profiles" && !slices.Contains(order, key[1]) {
+			order = append(order, key[1])
+		}
+	}
+
+	var profiles []Profile
+	var warnings []string
+	for _, name := range order {
+		table, ok := tables[name].(map[string]any)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("Config: profiles.%s must be a table", name))
+			continue
+		}
+		p := Profile{Name: name, Values: make(map[string]any)}
+		for key, v := range table {
+			switch key {
+			case "match_ssid", "match_interface":
+				list, err := stringList(v)
+				if err != nil {
+					warnings = append(warnings, fmt.Sprintf("Config: ignoring profiles.%s.%s: %v", name, key, err))
+					continue
+				}
+				if key == "match_ssid" {
+					p.MatchSSID = list
+				} else {
+					p.MatchInterface = list
+				}
+			default:
+				section, ok := v.(map[string]any)
+				if !ok {
+					warnings = append(warnings, fmt.Sprintf("Config: ignoring profiles.%s.%s: settings go in [profiles.%s.<section>]", name, key, name))
+					continue
+				}
+				for k, val := range section {
+					p.Values[strings.ToLower(key)+"."+k] = val
+				}
+			}
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, warnings
+}
+
+func stringList(v any) ([]string, error) {
+	if s, ok := v.(string); ok {
+		return []string{s}, nil
+	}
+	items, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("must be a list of strings")
+	}
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be a list of strings")
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// SelectProfile resolves a general.profile value against the defined
+// profiles. Empty and "none" select nothing; "auto" picks the first profile
+// whose rules match the current network, if any.
+func SelectProfile(profiles []Profile, selection string) (*Profile, error) {
+	selection = strings.TrimSpace(selection)
+	switch strings.ToLower(selection) {
+	case "", "none":
+		return nil, nil
+	case ProfileAuto:
+		if !slices.ContainsFunc(profiles, Profile.HasNetworkRules) {
+			return nil, nil
+		}
+		ssids, interfaces := currentNetwork()
+		for i := range profiles {
+			if profiles[i].Matches(ssids, interfaces) {
+				return &profiles[i], nil
+			}
+		}
+		return nil, nil
+	}
+	for i := range profiles {
+		if profiles[i].Name == selection {
+			return &profiles[i], nil
+		}
+	}
+	return nil, fmt.Errorf("profile %q is not defined in config.toml", selection)
+}
+
+// ActiveProfile returns the name of the profile applied to these settings,
+// or "" when none is.
+func (s *Settings) ActiveProfile() string {
+	return s.profile
+}
+
+// networkCheckTTL bounds how often auto profile selection asks the system
+// for its Wi-Fi network, since settings are reloaded frequently.
+const networkCheckTTL = 15 * time.Second
+
+var (
+	networkMu        sync.Mutex
+	networkCheckedAt time.Time
+	networkSSIDs     []string
+	networkIfaces    []string
+
+	// detectNetwork is replaced in tests.
+	detectNetwork = func() ([]string, []string) {
+		return utils.WiFiSSIDs(), utils.ActiveInterfaces()
+	}
+)
+
+func currentNetwork() ([]string, []string) {
+	networkMu.Lock()
+	defer networkMu.Unlock()
+	if networkCheckedAt.IsZero() || time.Since(networkCheckedAt) >= networkCheckTTL {
+		networkSSIDs, networkIfaces = detectNetwork()
+		networkCheckedAt = time.Now()
+	}
+	return networkSSIDs, networkIfaces
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const profilesFile = `[network]
+max_redirects = 4
+user_agent = "from-file"
+
+[profiles.work]
+match_ssid = ["CorpWiFi"]
+
+[profiles.work.network]
+proxy_url = "http://proxy.corp:3128"
+global_rate_limit = "2MB"
+
+[profiles.work.general]
+default_download_dir = "/data/work"
+
+[profiles.home.network]
+max_redirects = 9
+
+[profiles.home]
+match_interface = ["eth0"]
+`
+
+func fakeNetwork(t *testing.T, ssids, ifaces []string) {
+	t.Helper()
+	orig := detectNetwork
+	detectNetwork = func() ([]string, []string) { return ssids, ifaces }
+	networkMu.Lock()
+	networkCheckedAt = time.Time{}
+	networkMu.Unlock()
+	t.Cleanup(func() {
+		detectNetwork = orig
+		networkMu.Lock()
+		networkCheckedAt = time.Time{}
+		networkMu.Unlock()
+	})
+}
+
+func TestLoadSettings_AppliesNamedProfile(t *testing.T) {
+	setupConfigDir(t)
+	fakeNetwork(t, nil, nil)
+	workDir := t.TempDir()
+	file := strings.ReplaceAll(profilesFile, "/data/work", filepath.ToSlash(workDir))
+	if err := os.WriteFile(GetConfigFilePath(), []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SURGE_NETWORK_GLOBAL_RATE_LIMIT", "5MB")
+	if err := SetFlagOverrides([]string{"general.profile=work"}); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.StartupWarnings) != 0 {
+		t.Fatalf("unexpected warnings: %v", s.StartupWarnings)
+	}
+	if s.ActiveProfile() != "work" {
+		t.Fatalf("ActiveProfile() = %q, want work", s.ActiveProfile())
+	}
+	if got := Resolve[string](s.Network.ProxyURL); got != "http://proxy.corp:3128" || s.Source("network.proxy_url") != SourceProfile {
+		t.Errorf("proxy_url = %q from %s, want the profile's", got, s.Source("network.proxy_url"))
+	}
+	if got := Resolve[string](s.General.DefaultDownloadDir); got != filepath.ToSlash(workDir) {
+		t.Errorf("default_download_dir = %q, want the profile's", got)
+	}
+	if got := Resolve[string](s.Network.GlobalRateLimit); got != "5MB" {
+		t.Errorf("global_rate_limit = %q, want the environment to win over the profile", got)
+	}
+	if got := Resolve[int](s.Network.MaxRedirects); got != 4 {
+		t.Errorf("max_redirects = %d, want the file value outside the profile", got)
+	}
+}
+
+func TestLoadSettings_AutoProfileFollowsNetwork(t *testing.T) {
+	setupConfigDir(t)
+	if err := os.WriteFile(GetConfigFilePath(), []byte(profilesFile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	fakeNetwork(t, []string{"Cafe"}, []string{"ETH0"})
+	s, err := LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ActiveProfile() != "home" || Resolve[int](s.Network.MaxRedirects) != 9 {
+		t.Fatalf("on eth0: profile %q, max_redirects %d; want home and 9", s.ActiveProfile(), Resolve[int](s.Network.MaxRedirects))
+	}
+
+	fakeNetwork(t, []string{"CorpWiFi"}, []string{"eth0"})
+	if s, _ = LoadSettings(); s.ActiveProfile() != "work" {
+		t.Fatalf("on CorpWiFi: profile %q, want work as it comes first", s.ActiveProfile())
+	}
+
+	fakeNetwork(t, nil, nil)
+	if s, _ = LoadSettings(); s.ActiveProfile() != "" || Resolve[int](s.Network.MaxRedirects) != 4 {
+		t.Fatalf("off both networks: profile %q, want none", s.ActiveProfile())
+	}
+}
+
+func TestLoadSettings_WarnsAboutUnknownProfile(t *testing.T) {
+	setupConfigDir(t)
+	fakeNetwork(t, nil, nil)
+	file := profilesFile + "\n[profiles.broken]\nlabel = \"x\"\n\n[profiles.broken.general]\nprofile = \"work\"\n"
+	if err := os.WriteFile(GetConfigFilePath(), []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("SURGE_GENERAL_PROFILE", "travel")
+	s, err := LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ActiveProfile() != "" || len(s.StartupWarnings) != 2 {
+		t.Fatalf("profile %q, warnings %q; want none and two warnings", s.ActiveProfile(), s.StartupWarnings)
+	}
+
+	t.Setenv("SURGE_GENERAL_PROFILE", "broken")
+	if s, _ = LoadSettings(); s.ActiveProfile() != "broken" || len(s.StartupWarnings) != 2 {
+		t.Fatalf("profile %q, warnings %q; want broken with its bad entries reported", s.ActiveProfile(), s.StartupWarnings)
+	}
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// MaxRecentDirs is how many download directories are remembered, one for
+// each number key.
+const MaxRecentDirs = 9
+
+// GetRecentDirsPath returns the file the recent download directories are
+// kept in.
+func GetRecentDirsPath() string {
+	return filepath.Join(GetStateDir(), "recent_dirs.json")
+}
+
+// LoadRecentDirs returns the remembered download directories, most recent
+// first. A missing or unreadable file means none.
+func LoadRecentDirs() []string {
+	data, err := os.ReadFile(GetRecentDirsPath())
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	if err := json.Unmarshal(data, &dirs); err != nil {
+		return nil
+	}
+	if len(dirs) > MaxRecentDirs {
+		dirs = dirs[:MaxRecentDirs]
+	}
+	return dirs
+}
+
+// AddRecentDir returns dirs with dir moved to the front, dropping the
+// oldest beyond MaxRecentDirs. dirs itself is not changed.
+func AddRecentDir(dirs []string, dir string) []string {
+	if dir == "" {
+		return dirs
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	if len(dirs) > 0 && dirs[0] == dir {
+		return dirs
+	}
+
+	updated := make([]string, 0, MaxRecentDirs)
+	updated = append(updated, dir)
+	for _, d := range dirs {
+		if d != dir && len(updated) < MaxRecentDirs {
+			updated = append(updated, d)
+		}
+	}
+	return updated
+}
+
+// SaveRecentDirs writes the recent download directories to disk.
+func SaveRecentDirs(dirs []string) error {
+	return writeJSONAtomic(GetRecentDirsPath(), dirs)
+}
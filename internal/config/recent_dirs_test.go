@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRecentDirs(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	root := t.TempDir()
+	dir := func(n int) string { return filepath.Join(root, fmt.Sprint(n)) }
+
+	if got := LoadRecentDirs(); got != nil {
+		t.Fatalf("LoadRecentDirs() = %v with no file, want nil", got)
+	}
+
+	var dirs []string
+	for i := range MaxRecentDirs + 2 {
+		dirs = AddRecentDir(dirs, dir(i))
+	}
+	if len(dirs) != MaxRecentDirs || dirs[0] != dir(MaxRecentDirs+1) {
+		t.Fatalf("dirs = %v, want the %d newest, newest first", dirs, MaxRecentDirs)
+	}
+
+	// Using a remembered directory again moves it to the front
+	dirs = AddRecentDir(dirs, dir(5))
+	if dirs[0] != dir(5) || slices.Index(dirs[1:], dir(5)) != -1 {
+		t.Fatalf("dirs = %v, want %s once, at the front", dirs, dir(5))
+	}
+
+	if err := SaveRecentDirs(dirs); err != nil {
+		t.Fatal(err)
+	}
+	if got := LoadRecentDirs(); !slices.Equal(got, dirs) {
+		t.Fatalf("LoadRecentDirs() = %v, want %v", got, dirs)
+	}
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Remote is a Surge daemon named in a [remotes.<name>] table of config.toml,
+// which commands like 'surge push --remote <name>' hand downloads to.
+type Remote struct {
+	Name string
+	// Host is the daemon's address, as accepted by --host.
+	Host string
+	// Token authenticates with it; empty falls back to --token and
+	// SURGE_TOKEN.
+	Token string
+}
+
+// LoadRemotes returns the remotes defined in config.toml, in file order.
+func LoadRemotes() ([]Remote, error) {
+	var raw map[string]any
+	md, err := toml.DecodeFile(GetConfigFilePath(), &raw)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	remotes, _ := parseRemotes(raw, md)
+	return remotes, nil
+}
+
+// FindRemote returns the remote called name.
+func FindRemote(name string) (Remote, error) {
+	remotes, err := LoadRemotes()
+	if err != nil {
+		return Remote{}, err
+	}
+	name = strings.TrimSpace(name)
+	for _, r := range remotes {
+		if r.Name == name {
+			return r, nil
+		}
+	}
+	return Remote{}, fmt.Errorf("remote %q is not defined in config.toml; add a [remotes.%s] table with its host", name, name)
+}
+
+// parseRemotes reads the [remotes.*] tables decoded into raw. Entries it
+// cannot use are returned as warnings.
+func parseRemotes(raw map[string]any, md toml.MetaData) ([]Remote, []string) {
+	tables, _ := raw["remotes"].(map[string]any)
+	if len(tables) == 0 {
+		return nil, nil
+	}
+
+	var order []string
+	for _, key := range md.Keys() {
+		if len(key) >= 2 && key[0] == "remotes" && !slices.Contains(order, key[1]) {
+			order = append(order, key[1])
+		}
+	}
+
+	var remotes []Remote
+	var warnings []string
+	for _, name := range order {
+		table, ok := tables[name].(map[string]any)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("Config: remotes.%s must be a table", name))
+			continue
+		}
+		r := Remote{Name: name}
+		for key, v := range table {
+			s, ok := v.(string)
+			if !ok {
+				warnings = append(warnings, fmt.Sprintf("Config: ignoring remotes.%s.%s: must be a string", name, key))
+				continue
+			}
+			switch key {
+			case "host":
+				r.Host = strings.TrimSpace(s)
+			case "token":
+				r.Token = strings.TrimSpace(s)
+			default:
+				warnings = append(warnings, fmt.Sprintf("Config: ignoring unknown key remotes.%s.%s", name, key))
+			}
+		}
+		if r.Host == "" {
+			warnings = append(warnings, fmt.Sprintf("Config: ignoring remotes.%s: it has no host", name))
+			continue
+		}
+		remotes = append(remotes, r)
+	}
+	return remotes, warnings
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFindRemote(t *testing.T) {
+	setupConfigDir(t)
+	fakeNetwork(t, nil, nil)
+	file := profilesFile + `
+[remotes.seedbox]
+host = "https://seedbox.example:1700"
+token = "secret"
+
+[remotes.nas]
+token = "no-host"
+`
+	if err := os.WriteFile(GetConfigFilePath(), []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := FindRemote("seedbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Host != "https://seedbox.example:1700" || r.Token != "secret" {
+		t.Fatalf("FindRemote(seedbox) = %+v", r)
+	}
+	if _, err := FindRemote("nas"); err == nil {
+		t.Fatal("FindRemote(nas) succeeded for a remote without a host")
+	}
+
+	// Remotes are not settings, so only the broken one is warned about
+	s, err := LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.StartupWarnings) != 1 || !strings.Contains(s.StartupWarnings[0], "remotes.nas") {
+		t.Fatalf("warnings = %q, want one about remotes.nas", s.StartupWarnings)
+	}
+}
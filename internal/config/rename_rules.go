@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// RenameRule changes the name a download is saved under. Rules are written
+// as one of:
+//
+//	s/PATTERN/REPLACEMENT/  regular expression replace, $1 for groups
+//	lower, upper            change the case of the whole name
+//	timestamp               prefix the date, as in 2026-03-07_name.zip
+//
+// and are limited to one host with a "host: " prefix, where *.example.com
+// covers every subdomain of example.com.
+type RenameRule struct {
+	Host string
+
+	action  string
+	pattern *regexp.Regexp
+	replace string
+}
+
+// renameTimestampLayout is the prefix the timestamp rule adds.
+const renameTimestampLayout = "2006-01-02_"
+
+// ParseRenameRules parses rules separated by semicolons.
+func ParseRenameRules(spec string) ([]RenameRule, error) {
+	var rules []RenameRule
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		rule, err := parseRenameRule(entry)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func parseRenameRule(entry string) (RenameRule, error) {
+	var rule RenameRule
+	body := entry
+	if !isRenameAction(body) {
+		host, rest, ok := strings.Cut(entry, ":")
+		host = strings.ToLower(strings.TrimSpace(host))
+		if !ok || host == "" || strings.ContainsAny(host, " /") {
+			return rule, fmt.Errorf("invalid rename rule %q: want s/PATTERN/REPLACEMENT/, lower, upper or timestamp", entry)
+		}
+		if strings.Contains(strings.TrimPrefix(host, "*."), "*") {
+			return rule, fmt.Errorf("invalid rename rule host %q: only a leading *. is allowed", host)
+		}
+		rule.Host = host
+		body = strings.TrimSpace(rest)
+	}
+
+	switch {
+	case body == "lower" || body == "upper" || body == "timestamp":
+		rule.action = body
+	case strings.HasPrefix(body, "s/"):
+		parts := strings.Split(body, "/")
+		if len(parts) != 4 || parts[1] == "" || parts[3] != "" {
+			return rule, fmt.Errorf("invalid rename rule %q: want s/PATTERN/REPLACEMENT/", entry)
+		}
+		re, err := regexp.Compile(parts[1])
+		if err != nil {
+			return rule, fmt.Errorf("invalid rename rule %q: %w", entry, err)
+		}
+		rule.action, rule.pattern, rule.replace = "replace", re, parts[2]
+	default:
+		return rule, fmt.Errorf("invalid rename rule %q: want s/PATTERN/REPLACEMENT/, lower, upper or timestamp", entry)
+	}
+	return rule, nil
+}
+
+func isRenameAction(s string) bool {
+	return s == "lower" || s == "upper" || s == "timestamp" || strings.HasPrefix(s, "s/")
+}
+
+// AppliesTo reports whether the rule is used for downloads from host.
+func (r RenameRule) AppliesTo(host string) bool {
+	if r.Host == "" {
+		return true
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if wildcard, ok := strings.CutPrefix(r.Host, "*."); ok {
+		return strings.HasSuffix(host, "."+wildcard)
+	}
+	return host == r.Host
+}
+
+// ApplyRenameRules runs the rules that apply to host over filename, in
+// order. A rule that would leave no usable name is skipped.
+func ApplyRenameRules(rules []RenameRule, host, filename string, now time.Time) string {
+	for _, r := range rules {
+		if !r.AppliesTo(host) {
+			continue
+		}
+		var renamed string
+		switch r.action {
+		case "lower":
+			renamed = strings.ToLower(filename)
+		case "upper":
+			renamed = strings.ToUpper(filename)
+		case "timestamp":
+			renamed = now.Format(renameTimestampLayout) + filename
+		case "replace":
+			renamed = r.pattern.ReplaceAllString(filename, r.replace)
+		}
+		renamed = strings.TrimSpace(renamed)
+		if renamed == "" || renamed == "." || renamed == ".." || filepath.Base(renamed) != renamed || strings.ContainsAny(renamed, `/\`) {
+			continue
+		}
+		filename = renamed
+	}
+	return filename
+}
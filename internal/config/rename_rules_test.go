@@ -0,0 +1,42 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApplyRenameRules(t *testing.T) {
+	now := time.Date(2026, 3, 7, 12, 0, 0, 0, time.UTC)
+	rules, err := ParseRenameRules(`s/%20|\s+/_/; s/^(.*)-v(\d+)/${1}_$2/; *.example.com: lower; cdn.example.com: timestamp`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		host, in, want string
+	}{
+		{"other.org", "My%20Report-v2.PDF", "My_Report_2.PDF"},
+		{"dl.example.com", "My Report.PDF", "my_report.pdf"},
+		{"cdn.example.com", "Setup.EXE", "2026-03-07_setup.exe"},
+		{"example.com", "A B.txt", "A_B.txt"},
+	}
+	for _, tt := range tests {
+		if got := ApplyRenameRules(rules, tt.host, tt.in, now); got != tt.want {
+			t.Errorf("ApplyRenameRules(%s, %q) = %q, want %q", tt.host, tt.in, got, tt.want)
+		}
+	}
+
+	// A rule that would empty the name or add a directory is skipped
+	rules, _ = ParseRenameRules(`s/.*//; s/x/a\\b/`)
+	if got := ApplyRenameRules(rules, "", "x.bin", now); got != "x.bin" {
+		t.Errorf("got %q, want the name unchanged", got)
+	}
+}
+
+func TestParseRenameRules_Invalid(t *testing.T) {
+	for _, bad := range []string{"shout", "s/(/x/", "s/a/b", "a.*.com: lower", "example.com: shout"} {
+		if _, err := ParseRenameRules(bad); err == nil {
+			t.Errorf("ParseRenameRules(%q) succeeded, want an error", bad)
+		}
+	}
+}
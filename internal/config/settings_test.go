@@ -59,6 +59,10 @@ func TestDefaultSettings(t *testing.T) {
 			t.Errorf("MinChunkSize should be positive, got: %d", Resolve[int64](settings.Network.MinChunkSize))
 		}
 
+		if got := Resolve[int64](settings.Network.SmallFileThreshold); got != int64(8*MB) {
+			t.Errorf("SmallFileThreshold should be 8MB by default, got: %d", got)
+		}
+
 		if Resolve[int](settings.Network.WorkerBufferSize) <= 0 {
 			t.Errorf("WorkerBufferSize should be positive, got: %d", Resolve[int](settings.Network.WorkerBufferSize))
 		}
@@ -484,3 +488,23 @@ func TestResolveGeneric(t *testing.T) {
 		t.Errorf("Resolve[time.Duration] got %v, want 15s", val)
 	}
 }
+
+func TestParseListColumns(t *testing.T) {
+	cols, err := ParseListColumns(" Speed, eta,,connections ")
+	if err != nil {
+		t.Fatalf("ParseListColumns: %v", err)
+	}
+	if strings.Join(cols, ",") != "speed,eta,connections" {
+		t.Errorf("got %v", cols)
+	}
+
+	if _, err := ParseListColumns("speed,bogus"); err == nil {
+		t.Error("expected an error for an unknown column")
+	}
+	if _, err := ParseListColumns("size,size"); err == nil {
+		t.Error("expected an error for a repeated column")
+	}
+	if err := DefaultSettings().General.ListLayout.Validate("wide"); err == nil {
+		t.Error("expected list_layout to reject an unknown layout")
+	}
+}
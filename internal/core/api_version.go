@@ -0,0 +1,63 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// APIVersion is the revision of the HTTP API between clients and the daemon.
+// It goes up whenever a change would break a client built for an older one.
+const APIVersion = 1
+
+// Headers both sides send on every request and response. Daemons from before
+// the handshake send neither.
+const (
+	VersionHeader    = "X-Surge-Version"
+	APIVersionHeader = "X-Surge-API-Version"
+)
+
+// ErrVersionMismatch is returned when a request fails and the two sides speak
+// different API revisions, which is the likely cause.
+var ErrVersionMismatch = errors.New("surge version mismatch")
+
+// SetVersionHeaders announces appVersion and APIVersion in h.
+func SetVersionHeaders(h http.Header, appVersion string) {
+	h.Set(VersionHeader, appVersion)
+	h.Set(APIVersionHeader, strconv.Itoa(APIVersion))
+}
+
+// PeerAPIVersion returns the API revision announced in h, or 0 when the peer
+// predates the handshake.
+func PeerAPIVersion(h http.Header) int {
+	v, err := strconv.Atoi(strings.TrimSpace(h.Get(APIVersionHeader)))
+	if err != nil || v < 0 {
+		return 0
+	}
+	return v
+}
+
+// ExplainVersionMismatch turns err, a failure handling resp, into
+// ErrVersionMismatch naming both versions when the daemon speaks another API
+// revision. Otherwise err is returned unchanged; requests that still work
+// across versions are not affected.
+func ExplainVersionMismatch(resp *http.Response, err error) error {
+	if err == nil || resp == nil {
+		return err
+	}
+	peer := PeerAPIVersion(resp.Header)
+	if peer == APIVersion {
+		return err
+	}
+
+	daemon := "an older Surge"
+	if v := resp.Header.Get(VersionHeader); v != "" {
+		daemon = "Surge " + v
+	}
+	if peer < APIVersion {
+		return fmt.Errorf("%w: the daemon runs %s (API %d) but this client needs API %d; update the daemon (%v)", ErrVersionMismatch, daemon, peer, APIVersion, err)
+	}
+	return fmt.Errorf("%w: the daemon runs %s (API %d), newer than this client (API %d); update this client (%v)", ErrVersionMismatch, daemon, peer, APIVersion, err)
+}
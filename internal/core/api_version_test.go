@@ -0,0 +1,50 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestExplainVersionMismatch(t *testing.T) {
+	base := errors.New("API error 404: not found")
+	header := func(app string, api int) http.Header {
+		h := http.Header{}
+		if app != "" {
+			h.Set(VersionHeader, app)
+			h.Set(APIVersionHeader, strconv.Itoa(api))
+		}
+		return h
+	}
+
+	tests := []struct {
+		name     string
+		header   http.Header
+		mismatch bool
+		contains string
+	}{
+		{"same api", header("1.0.0", APIVersion), false, ""},
+		{"pre-handshake daemon", header("", 0), true, "an older Surge"},
+		{"newer daemon", header("9.0.0", APIVersion+1), true, "update this client"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ExplainVersionMismatch(&http.Response{Header: tt.header}, base)
+			if got := errors.Is(err, ErrVersionMismatch); got != tt.mismatch {
+				t.Fatalf("ExplainVersionMismatch() = %v, mismatch %v, want %v", err, got, tt.mismatch)
+			}
+			if !tt.mismatch && err != base {
+				t.Errorf("ExplainVersionMismatch() = %v, want the original error", err)
+			}
+			if !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("ExplainVersionMismatch() = %q, want it to mention %q", err, tt.contains)
+			}
+		})
+	}
+
+	if err := ExplainVersionMismatch(&http.Response{Header: header("", 0)}, nil); err != nil {
+		t.Errorf("ExplainVersionMismatch(nil) = %v, want nil", err)
+	}
+}
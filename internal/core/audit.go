@@ -0,0 +1,131 @@
+package core
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditEntry records one state-changing API request: who sent it, what it
+// asked for and how the daemon answered.
+type AuditEntry struct {
+	Time   time.Time         `json:"time"`
+	Client string            `json:"client"`          // Remote address of the caller
+	Token  string            `json:"token,omitempty"` // Fingerprint of the bearer token, never the token itself
+	Method string            `json:"method"`
+	Action string            `json:"action"`
+	ID     string            `json:"id,omitempty"`
+	Params map[string]string `json:"params,omitempty"`
+	Status int               `json:"status"`
+}
+
+// AuditFilter narrows the entries returned by AuditLog.Query. Zero fields
+// match everything.
+type AuditFilter struct {
+	Since  time.Time
+	Action string
+	ID     string // Matches entries whose ID starts with it
+	Limit  int    // Keep only the newest Limit entries
+}
+
+func (f AuditFilter) match(e AuditEntry) bool {
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if f.Action != "" && !strings.EqualFold(e.Action, f.Action) {
+		return false
+	}
+	if f.ID != "" && !strings.HasPrefix(e.ID, f.ID) {
+		return false
+	}
+	return true
+}
+
+// AuditLog is an append-only JSON Lines file of AuditEntry records.
+type AuditLog struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewAuditLog(path string) *AuditLog {
+	return &AuditLog{path: path}
+}
+
+// Append writes e as a new line at the end of the log.
+func (l *AuditLog) Append(e AuditEntry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Query returns the entries matching filter, oldest first. Lines that do not
+// parse are skipped so a torn write does not hide the rest of the log.
+func (l *AuditLog) Query(filter AuditFilter) ([]AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []AuditEntry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	entries := []AuditEntry{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		if filter.match(e) {
+			entries = append(entries, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading audit log: %w", err)
+	}
+
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[len(entries)-filter.Limit:]
+	}
+	return entries, nil
+}
+
+// TokenFingerprint identifies a bearer token in the audit log without storing
+// it: the first 12 hex digits of its SHA-256.
+func TokenFingerprint(token string) string {
+	if token == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])[:12]
+}
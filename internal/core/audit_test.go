@@ -0,0 +1,82 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestAuditLog_AppendAndQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "audit.log")
+	log := NewAuditLog(path)
+
+	if entries, err := log.Query(AuditFilter{}); err != nil || len(entries) != 0 {
+		t.Fatalf("Query on a missing log = %v, %v; want no entries", entries, err)
+	}
+
+	base := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, action := range []string{"add", "pause", "resume", "pause"} {
+		e := AuditEntry{Time: base.Add(time.Duration(i) * time.Hour), Action: action, ID: "abcd1234-" + action, Status: 200}
+		if err := log.Append(e); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	// A torn write must not hide the entries after it.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString("{\"time\":\n")
+	_ = f.Close()
+	if err := log.Append(AuditEntry{Time: base.Add(5 * time.Hour), Action: "delete", ID: "ffff0000"}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		filter AuditFilter
+		want   []string
+	}{
+		{name: "all", filter: AuditFilter{}, want: []string{"add", "pause", "resume", "pause", "delete"}},
+		{name: "action", filter: AuditFilter{Action: "PAUSE"}, want: []string{"pause", "pause"}},
+		{name: "since", filter: AuditFilter{Since: base.Add(2 * time.Hour)}, want: []string{"resume", "pause", "delete"}},
+		{name: "id prefix", filter: AuditFilter{ID: "abcd1234-a"}, want: []string{"add"}},
+		{name: "limit keeps newest", filter: AuditFilter{Limit: 2}, want: []string{"pause", "delete"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := log.Query(tt.filter)
+			if err != nil {
+				t.Fatalf("Query: %v", err)
+			}
+			if len(entries) != len(tt.want) {
+				t.Fatalf("got %d entries, want %v", len(entries), tt.want)
+			}
+			for i, e := range entries {
+				if e.Action != tt.want[i] {
+					t.Errorf("entry %d action = %q, want %q", i, e.Action, tt.want[i])
+				}
+			}
+		})
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); runtime.GOOS != "windows" && perm != 0o600 {
+		t.Errorf("audit log mode = %o, want 600", perm)
+	}
+}
+
+func TestTokenFingerprint(t *testing.T) {
+	if got := TokenFingerprint(""); got != "" {
+		t.Errorf("TokenFingerprint(\"\") = %q, want empty", got)
+	}
+	a, b := TokenFingerprint("secret-a"), TokenFingerprint("secret-b")
+	if len(a) != 12 || a == b || a != TokenFingerprint("secret-a") {
+		t.Errorf("fingerprints %q and %q are not stable and distinct", a, b)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/SurgeDM/Surge/internal/config"
 	"github.com/SurgeDM/Surge/internal/download"
+	"github.com/SurgeDM/Surge/internal/engine"
 	"github.com/SurgeDM/Surge/internal/engine/events"
 	"github.com/SurgeDM/Surge/internal/engine/state"
 	"github.com/SurgeDM/Surge/internal/engine/types"
@@ -45,6 +47,9 @@ func (s *LocalDownloadService) ReloadSettings() error {
 		runtime := settings.ToRuntimeConfig()
 		s.Pool.SetGlobalRateLimit(runtime.GlobalRateLimitBps)
 		s.Pool.SetDefaultDownloadRateLimit(runtime.DefaultDownloadRateLimitBps)
+		engine.SetUploadRateLimit(runtime.UploadRateLimitBps)
+		s.Pool.SetConcurrencyLimits(download.LimitsFromSettings(settings))
+		s.Pool.SetQueuePolicy(config.Resolve[string](settings.Network.QueuePolicy))
 	}
 	return nil
 }
@@ -84,6 +89,8 @@ type LifecycleHooks struct {
 	ResumeBatch func(ids []string) []error
 	Cancel      func(id string) error
 	UpdateURL   func(id, newURL string) error
+	// Waiting lists the downloads held until the network is back.
+	Waiting func() []types.DownloadStatus
 }
 
 const (
@@ -116,6 +123,12 @@ func NewLocalDownloadServiceWithInput(pool *download.WorkerPool, inputCh chan in
 		runtime := s.settings.ToRuntimeConfig()
 		pool.SetGlobalRateLimit(runtime.GlobalRateLimitBps)
 		pool.SetDefaultDownloadRateLimit(runtime.DefaultDownloadRateLimitBps)
+		engine.SetUploadRateLimit(runtime.UploadRateLimitBps)
+		// The pool was sized by its creator; only layer the finer caps on top.
+		limits := download.LimitsFromSettings(s.settings)
+		limits.Global = 0
+		pool.SetConcurrencyLimits(limits)
+		pool.SetQueuePolicy(config.Resolve[string](s.settings.Network.QueuePolicy))
 	}
 
 	// Lifecycle
@@ -252,6 +265,15 @@ func (s *LocalDownloadService) reportProgressLoop() {
 				Speed:             currentSpeed,
 				Elapsed:           totalElapsed,
 				ActiveConnections: int(connections),
+				ConnectionSpeeds:  cfg.State.GetConnectionSpeeds(),
+				Phase:             cfg.State.GetPhase(),
+			}
+			switch msg.Phase {
+			case types.PhaseVerifying:
+				msg.Checked = cfg.State.Checked.Load()
+				msg.Speed = 0
+			case types.PhasePostProcessing:
+				msg.Speed = 0
 			}
 
 			// Chunk snapshots are expensive due to bitmap/progress copies.
@@ -445,19 +467,19 @@ func (s *LocalDownloadService) List() ([]types.DownloadStatus, error) {
 				} else if cfg.State.Done.Load() {
 					status.Status = "completed"
 				}
+				if status.Status == "downloading" {
+					status.Phase = cfg.State.GetPhase()
+				}
+				if status.Phase == types.PhaseVerifying && status.TotalSize > 0 {
+					status.Progress = float64(cfg.State.Checked.Load()) * 100 / float64(status.TotalSize)
+				}
 
 				// Calculate speed from progress only while actively downloading.
-				if status.Status == "downloading" {
+				if status.Status == "downloading" && status.Phase != types.PhaseVerifying && status.Phase != types.PhasePostProcessing {
 					sessionDownloaded := downloaded - sessionStart
 					if sessionElapsed.Seconds() > 0 && sessionDownloaded > 0 {
 						status.Speed = float64(sessionDownloaded) / sessionElapsed.Seconds() / float64(types.MB)
-
-						// Calculate ETA (seconds remaining)
-						remaining := status.TotalSize - status.Downloaded
-						if remaining > 0 && status.Speed > 0 {
-							speedBytes := status.Speed * float64(types.MB)
-							status.ETA = int64(float64(remaining) / speedBytes)
-						}
+						status.SetETA(time.Now())
 					}
 				}
 			}
@@ -469,15 +491,25 @@ func (s *LocalDownloadService) List() ([]types.DownloadStatus, error) {
 	// 2. Fetch from database for history/paused/completed
 	dbDownloads, err := state.ListAllDownloads()
 	if err == nil {
+		failures := make(map[string]state.Failure)
+		if list, err := state.Failures(); err == nil {
+			for _, f := range list {
+				failures[f.DownloadID] = f
+			}
+		}
+
 		// Create a map of existing IDs to avoid duplicates
-		existingIDs := make(map[string]bool)
-		for _, s := range statuses {
-			existingIDs[s.ID] = true
+		existingIDs := make(map[string]int)
+		for i, s := range statuses {
+			existingIDs[s.ID] = i
 		}
 
 		for _, d := range dbDownloads {
-			// Skip if already present (active)
-			if existingIDs[d.ID] {
+			// Skip if already present (active), but keep its alias and tags
+			if i, ok := existingIDs[d.ID]; ok {
+				statuses[i].Alias = d.Alias
+				statuses[i].Tags = d.Tags
+				statuses[i].ResponseHeaders = d.ResponseHeaders
 				continue
 			}
 
@@ -489,39 +521,64 @@ func (s *LocalDownloadService) List() ([]types.DownloadStatus, error) {
 			}
 
 			statuses = append(statuses, types.DownloadStatus{
-				ID:           d.ID,
-				URL:          d.URL,
-				Filename:     d.Filename,
-				DestPath:     d.DestPath,
-				Status:       d.Status,
-				TotalSize:    d.TotalSize,
-				Downloaded:   d.Downloaded,
-				Progress:     progress,
-				Speed:        completedSpeedMBps(d),
-				Connections:  0,
-				TimeTaken:    d.TimeTaken,
-				AvgSpeed:     d.AvgSpeed,
-				RateLimit:    d.RateLimit,
-				RateLimitSet: d.RateLimitSet,
+				ID:              d.ID,
+				URL:             d.URL,
+				Filename:        d.Filename,
+				DestPath:        d.DestPath,
+				Status:          d.Status,
+				TotalSize:       d.TotalSize,
+				Downloaded:      d.Downloaded,
+				Progress:        progress,
+				Speed:           completedSpeedMBps(d),
+				Connections:     0,
+				TimeTaken:       d.TimeTaken,
+				AvgSpeed:        d.AvgSpeed,
+				RateLimit:       d.RateLimit,
+				RateLimitSet:    d.RateLimitSet,
+				Alias:           d.Alias,
+				Tags:            d.Tags,
+				ResponseHeaders: d.ResponseHeaders,
+				Error:           failures[d.ID].LastError,
+				ErrorCode:       failures[d.ID].Code,
+				Attempts:        failures[d.ID].Attempts,
 			})
 		}
 	}
 
+	// 3. Downloads added while offline are known only to the lifecycle
+	s.lifecycleHooksMu.RLock()
+	waiting := s.lifecycleHooks.Waiting
+	s.lifecycleHooksMu.RUnlock()
+	if waiting != nil {
+		statuses = append(statuses, waiting()...)
+	}
+
 	return statuses, nil
 }
 
 // Add queues a new download on the local pool without TUI confirmation.
 func (s *LocalDownloadService) Add(url string, path string, filename string, mirrors []string, headers map[string]string, isExplicitCategory bool, totalSize int64, supportsRange bool) (string, error) {
-	return s.add(url, path, filename, mirrors, headers, "", isExplicitCategory, totalSize, supportsRange)
+	return s.add(url, path, filename, mirrors, headers, "", isExplicitCategory, totalSize, supportsRange, types.ProbeHandoff{})
 }
 
 // AddWithID queues a new download using a caller-provided id when non-empty.
 func (s *LocalDownloadService) AddWithID(url string, path string, filename string, mirrors []string, headers map[string]string, id string, totalSize int64, supportsRange bool) (string, error) {
 	// Remote or RPC-driven calls use preset IDs and should bypass interactive category routing.
-	return s.add(url, path, filename, mirrors, headers, id, false, totalSize, supportsRange)
+	return s.add(url, path, filename, mirrors, headers, id, false, totalSize, supportsRange, types.ProbeHandoff{})
 }
 
-func (s *LocalDownloadService) add(url string, path string, filename string, mirrors []string, headers map[string]string, requestedID string, isExplicitCategory bool, totalSize int64, supportsRange bool) (string, error) {
+// AddProbed is Add for a download the lifecycle already probed; the engine
+// starts from what the probe left.
+func (s *LocalDownloadService) AddProbed(url string, path string, filename string, mirrors []string, headers map[string]string, isExplicitCategory bool, totalSize int64, supportsRange bool, probe types.ProbeHandoff) (string, error) {
+	return s.add(url, path, filename, mirrors, headers, "", isExplicitCategory, totalSize, supportsRange, probe)
+}
+
+// AddProbedWithID is AddWithID for a download the lifecycle already probed.
+func (s *LocalDownloadService) AddProbedWithID(url string, path string, filename string, mirrors []string, headers map[string]string, id string, totalSize int64, supportsRange bool, probe types.ProbeHandoff) (string, error) {
+	return s.add(url, path, filename, mirrors, headers, id, false, totalSize, supportsRange, probe)
+}
+
+func (s *LocalDownloadService) add(url string, path string, filename string, mirrors []string, headers map[string]string, requestedID string, isExplicitCategory bool, totalSize int64, supportsRange bool, probe types.ProbeHandoff) (string, error) {
 	if s.Pool == nil {
 		return "", types.ErrPoolNotInit
 	}
@@ -572,6 +629,7 @@ func (s *LocalDownloadService) add(url string, path string, filename string, mir
 		TotalSize:          totalSize,
 		SupportsRange:      supportsRange,
 		RateLimitBps:       runtime.DefaultDownloadRateLimitBps,
+		Probe:              probe,
 	}
 
 	s.Pool.Add(cfg)
@@ -590,15 +648,171 @@ func (s *LocalDownloadService) Pause(id string) error {
 	return fmt.Errorf("PauseFunc not initialized")
 }
 
-// Resume resumes a paused download.
+// Resume resumes a paused download. One paused by PauseAll no longer waits
+// for ResumeAll, a snoozed one no longer waits for its time, and a failed
+// one gets max_download_retries afresh.
 func (s *LocalDownloadService) Resume(id string) error {
 	s.lifecycleHooksMu.RLock()
 	fn := s.lifecycleHooks.Resume
 	s.lifecycleHooksMu.RUnlock()
-	if fn != nil {
-		return fn(id)
+	if fn == nil {
+		return fmt.Errorf("ResumeFunc not initialized")
+	}
+	// Cleared first, so a quick failure of the resumed download counts
+	// against its fresh retry budget
+	if err := state.ClearFailures(id); err != nil {
+		utils.Debug("Failed to forget failures of %s: %v", id, err)
+	}
+	if err := fn(id); err != nil {
+		return err
+	}
+	if err := state.RemoveGlobalPause(id); err != nil {
+		utils.Debug("Failed to forget global pause of %s: %v", id, err)
 	}
-	return fmt.Errorf("ResumeFunc not initialized")
+	if err := state.RemoveSnooze(id); err != nil {
+		utils.Debug("Failed to forget snooze of %s: %v", id, err)
+	}
+	return nil
+}
+
+// Failed returns the downloads that used up max_download_retries, each with
+// the error it last failed with.
+func (s *LocalDownloadService) Failed() ([]types.DownloadStatus, error) {
+	statuses, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	var failed []types.DownloadStatus
+	for _, st := range statuses {
+		if st.Status == "failed" {
+			failed = append(failed, st)
+		}
+	}
+	return failed, nil
+}
+
+// SpeedHistory returns the throughput of all downloads over the last
+// state.SpeedHistoryWindow, kept across restarts.
+func (s *LocalDownloadService) SpeedHistory() ([]state.SpeedSample, error) {
+	return state.SpeedHistory(time.Now().Add(-state.SpeedHistoryWindow))
+}
+
+// RetryFailed resumes failed downloads with a fresh retry budget and returns
+// their IDs. With no ids it retries every download in the Failed list.
+func (s *LocalDownloadService) RetryFailed(ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		failed, err := s.Failed()
+		if err != nil {
+			return nil, err
+		}
+		for _, st := range failed {
+			ids = append(ids, st.ID)
+		}
+	}
+	var retried []string
+	var errs []error
+	for _, id := range ids {
+		if err := s.Resume(id); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", id, err))
+			continue
+		}
+		retried = append(retried, id)
+	}
+	return retried, errors.Join(errs...)
+}
+
+// Snooze pauses a download, unless it already is, and records that it
+// resumes on its own at until. The daemon resumes it then, even after a
+// restart; resuming it earlier by hand cancels the snooze.
+func (s *LocalDownloadService) Snooze(id string, until time.Time) error {
+	if !until.After(time.Now()) {
+		return fmt.Errorf("snooze time %s is in the past", until.Format(time.RFC3339))
+	}
+	status, err := s.GetStatus(id)
+	if err != nil {
+		return err
+	}
+	switch status.Status {
+	case "completed":
+		return fmt.Errorf("download %s is already completed", id)
+	case "paused", "pausing":
+	default:
+		if err := s.Pause(id); err != nil {
+			return err
+		}
+	}
+	return state.SetSnooze(id, until)
+}
+
+// PauseAll pauses every running and queued download and returns their IDs.
+// ResumeAll later resumes exactly these, so downloads that were already
+// paused stay paused. The set survives a restart.
+func (s *LocalDownloadService) PauseAll() ([]string, error) {
+	if s.Pool == nil {
+		return nil, types.ErrPoolNotInit
+	}
+	var paused []string
+	var errs []error
+	for _, cfg := range s.Pool.GetAll() {
+		if cfg.State != nil && (cfg.State.IsPaused() || cfg.State.IsPausing() || cfg.State.Done.Load()) {
+			continue
+		}
+		if err := s.Pause(cfg.ID); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", cfg.ID, err))
+			continue
+		}
+		paused = append(paused, cfg.ID)
+	}
+	if err := state.AddGlobalPause(paused); err != nil {
+		errs = append(errs, err)
+	}
+	return paused, errors.Join(errs...)
+}
+
+// ResumeAll resumes the downloads PauseAll paused and returns their IDs.
+// Those since resumed, removed or completed are skipped.
+func (s *LocalDownloadService) ResumeAll() ([]string, error) {
+	ids, err := state.GlobalPaused()
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	paused := make(map[string]bool, len(statuses))
+	for _, st := range statuses {
+		if st.Status == "paused" || st.Status == "pausing" {
+			paused[st.ID] = true
+		}
+	}
+
+	var toResume []string
+	for _, id := range ids {
+		if paused[id] {
+			toResume = append(toResume, id)
+		}
+	}
+	var resumed []string
+	var errs []error
+	for i, err := range s.ResumeBatch(toResume) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", toResume[i], err))
+			continue
+		}
+		resumed = append(resumed, toResume[i])
+	}
+	if len(errs) == 0 {
+		if err := state.RemoveGlobalPause(); err != nil {
+			errs = append(errs, err)
+		}
+	} else if len(resumed) > 0 {
+		// The failed ones wait for the next ResumeAll
+		if err := state.RemoveGlobalPause(resumed...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return resumed, errors.Join(errs...)
 }
 
 // ResumeBatch resumes multiple paused downloads efficiently.
@@ -717,6 +931,10 @@ func (s *LocalDownloadService) GetStatus(id string) (*types.DownloadStatus, erro
 	if s.Pool != nil {
 		status := s.Pool.GetStatus(id)
 		if status != nil {
+			if entry, err := state.GetDownload(id); err == nil && entry != nil {
+				status.Alias = entry.Alias
+				status.Tags = entry.Tags
+			}
 			return status, nil
 		}
 	}
@@ -745,6 +963,8 @@ func (s *LocalDownloadService) GetStatus(id string) (*types.DownloadStatus, erro
 			AvgSpeed:     entry.AvgSpeed,
 			RateLimit:    entry.RateLimit,
 			RateLimitSet: entry.RateLimitSet,
+			Alias:        entry.Alias,
+			Tags:         entry.Tags,
 		}
 		return &status, nil
 	}
@@ -758,6 +978,27 @@ func (s *LocalDownloadService) History() ([]types.DownloadEntry, error) {
 	return state.LoadCompletedDownloads()
 }
 
+// Logs returns the lines the engine logged for a download, oldest first.
+// Logs are kept in memory only, so a download that has not run since Surge
+// started has none.
+func (s *LocalDownloadService) Logs(id string) ([]utils.LogLine, error) {
+	lines := utils.DownloadLog(id)
+	if len(lines) > 0 {
+		return lines, nil
+	}
+	if s.Pool != nil && s.Pool.GetStatus(id) != nil {
+		return lines, nil
+	}
+	entry, err := state.GetDownload(id)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("%w: %s", types.ErrNotFound, id)
+	}
+	return lines, nil
+}
+
 // SetRateLimit sets the speed limit for a specific download
 func (s *LocalDownloadService) SetRateLimit(id string, rate int64) error {
 	if rate < 0 {
@@ -851,6 +1092,114 @@ func (s *LocalDownloadService) SetGlobalRateLimit(rate int64) error {
 	return nil
 }
 
+// SetConcurrencyLimits changes how many downloads may run at once overall,
+// per host and per category, and saves the new caps to settings. A negative
+// value leaves that cap unchanged. Running downloads are never stopped.
+func (s *LocalDownloadService) SetConcurrencyLimits(global, perHost, perCategory int) error {
+	if s.Pool == nil {
+		return types.ErrPoolNotInit
+	}
+
+	s.settingsMu.Lock()
+	if s.settings == nil {
+		s.settings = config.DefaultSettings()
+	}
+	defaults := config.DefaultSettings().Network
+	network := &s.settings.Network
+	caps := []struct {
+		setting **config.Setting
+		def     *config.Setting
+		value   int
+	}{
+		{&network.MaxConcurrentDownloads, defaults.MaxConcurrentDownloads, global},
+		{&network.MaxDownloadsPerHost, defaults.MaxDownloadsPerHost, perHost},
+		{&network.MaxDownloadsPerCategory, defaults.MaxDownloadsPerCategory, perCategory},
+	}
+	oldValues := make([]any, len(caps))
+	for i, c := range caps {
+		if *c.setting == nil {
+			*c.setting = c.def
+		}
+		oldValues[i] = (*c.setting).Value
+		if c.value < 0 {
+			continue
+		}
+		if err := (*c.setting).Validate(c.value); err != nil {
+			s.settingsMu.Unlock()
+			return fmt.Errorf("%s: %w", (*c.setting).Key, err)
+		}
+	}
+	for _, c := range caps {
+		if c.value >= 0 {
+			(*c.setting).Value = c.value
+		}
+	}
+	if err := config.SaveSettings(s.settings); err != nil {
+		for i, c := range caps {
+			(*c.setting).Value = oldValues[i]
+		}
+		s.settingsMu.Unlock()
+		return err
+	}
+	limits := download.LimitsFromSettings(s.settings)
+	s.settingsMu.Unlock()
+
+	s.Pool.SetConcurrencyLimits(limits)
+	return nil
+}
+
+// SetDraining turns drain mode on or off and tells clients about it. While
+// draining, running downloads finish but queued ones, including those
+// resumed meanwhile, wait until drain mode is turned off.
+func (s *LocalDownloadService) SetDraining(on bool) error {
+	if s.Pool == nil {
+		return types.ErrPoolNotInit
+	}
+	s.Pool.SetDraining(on)
+	return s.Publish(events.DrainModeMsg{Draining: on})
+}
+
+// Draining reports whether drain mode is on.
+func (s *LocalDownloadService) Draining() bool {
+	return s.Pool != nil && s.Pool.Draining()
+}
+
+// SetTags replaces the tags of a download and tells clients about the change.
+// No tags clears them.
+func (s *LocalDownloadService) SetTags(id string, tags []string) error {
+	tags, err := types.NormalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	if err := state.SetTags(id, tags); err != nil {
+		return err
+	}
+	if err := s.Publish(events.DownloadTaggedMsg{DownloadID: id, Tags: tags}); err != nil {
+		utils.Debug("Failed to publish tag change for %s: %v", id, err)
+	}
+	return nil
+}
+
+// SetHeaders sets headers over the request headers of a download that is not
+// running, such as a cookie or token whose session expired. A header given an
+// empty value is removed. The host's recent refusals are forgotten, so the
+// download gets a fresh chance once resumed.
+func (s *LocalDownloadService) SetHeaders(id string, headers map[string]string) error {
+	if s.Pool != nil {
+		if err := s.Pool.UpdateHeaders(id, headers); err != nil {
+			return err
+		}
+	}
+	rawurl, err := state.UpdateHeaders(id, headers)
+	if err != nil {
+		return err
+	}
+	if u, err := url.Parse(rawurl); err == nil {
+		engine.DefaultAuthFailures.Forget(u.Host)
+	}
+	return nil
+}
+
 // SetDefaultRateLimit sets the inherited default per-download speed limit.
 func (s *LocalDownloadService) SetDefaultRateLimit(rate int64) error {
 	if rate < 0 {
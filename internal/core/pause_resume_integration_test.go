@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
@@ -852,3 +853,78 @@ func TestIntegration_PauseResumeBatch_ColdPath(t *testing.T) {
 		t.Error("hot downloaded = 0")
 	}
 }
+
+func TestIntegration_PauseAll_ResumesOnlyWhatItPaused(t *testing.T) {
+	rootDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", rootDir)
+
+	state.CloseDB()
+	dbPath := filepath.Join(rootDir, fmt.Sprintf("%s-surge.db", t.Name()))
+	state.Configure(dbPath)
+	defer state.CloseDB()
+
+	progressCh := make(chan any, 256)
+	pool := download.NewWorkerPool(progressCh, 1)
+	svc := NewLocalDownloadServiceWithInput(pool, progressCh)
+	forceSingleConnectionRuntime(svc)
+	defer func() { _ = svc.Shutdown() }()
+	evCleanup := startEventWorkerForTest(t, svc)
+	defer evCleanup()
+
+	const fileSize = int64(96 * 1024 * 1024)
+	server := newDeterministicStreamingServer(t, fileSize)
+	defer server.Close()
+	outputDir := t.TempDir()
+
+	add := func(name string) string {
+		t.Helper()
+		if f, err := os.Create(filepath.Join(outputDir, name) + ".surge"); err == nil {
+			_ = f.Close()
+		}
+		id, err := svc.Add(server.URL(), outputDir, name, nil, nil, false, fileSize, true)
+		if err != nil {
+			t.Fatalf("add %s failed: %v", name, err)
+		}
+		return id
+	}
+	running := add("running.bin")
+	waitForDownloadStatus(t, svc, running, 25*time.Second, func(st *types.DownloadStatus) bool {
+		return st.Status == "downloading" && st.Downloaded > 0
+	})
+	// With one worker these two wait in the queue
+	queued := add("queued.bin")
+	own := add("own.bin")
+	if err := svc.Pause(own); err != nil {
+		t.Fatalf("pause of queued download failed: %v", err)
+	}
+
+	paused, err := svc.PauseAll()
+	if err != nil {
+		t.Fatalf("PauseAll failed: %v", err)
+	}
+	if len(paused) != 2 || !slices.Contains(paused, running) || !slices.Contains(paused, queued) {
+		t.Fatalf("PauseAll paused %v, want the running and queued downloads", paused)
+	}
+	for _, id := range []string{running, queued, own} {
+		waitForDownloadStatus(t, svc, id, 10*time.Second, func(st *types.DownloadStatus) bool {
+			return st.Status == "paused"
+		})
+	}
+
+	resumed, err := svc.ResumeAll()
+	if err != nil {
+		t.Fatalf("ResumeAll failed: %v", err)
+	}
+	if !slices.Equal(resumed, paused) {
+		t.Fatalf("ResumeAll resumed %v, want %v", resumed, paused)
+	}
+	waitForDownloadStatus(t, svc, running, 25*time.Second, func(st *types.DownloadStatus) bool {
+		return st.Status == "downloading"
+	})
+	if st, err := svc.GetStatus(own); err != nil || st.Status != "paused" {
+		t.Fatalf("download paused on its own = %+v, %v; want it still paused", st, err)
+	}
+	if ids, _ := state.GlobalPaused(); len(ids) != 0 {
+		t.Fatalf("global pause set after ResumeAll = %v, want empty", ids)
+	}
+}
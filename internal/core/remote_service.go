@@ -9,10 +9,12 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/state"
 	"github.com/SurgeDM/Surge/internal/engine/types"
 	"github.com/SurgeDM/Surge/internal/utils"
 )
@@ -21,6 +23,7 @@ import (
 type RemoteDownloadService struct {
 	BaseURL   string
 	Token     string
+	Version   string // Surge version of this client, sent to the daemon
 	Client    *http.Client
 	SSEClient *http.Client
 	ctx       context.Context
@@ -66,6 +69,7 @@ func (s *RemoteDownloadService) doRequest(method, path string, body interface{})
 	}
 
 	req.Header.Set("Authorization", "Bearer "+s.Token)
+	SetVersionHeaders(req.Header, s.Version)
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
@@ -79,7 +83,7 @@ func (s *RemoteDownloadService) doRequest(method, path string, body interface{})
 		defer func() { _, _ = io.Copy(io.Discard, resp.Body); _ = resp.Body.Close() }()
 		// Limit error body read to 1KB to prevent DoS
 		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, types.KB))
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, ExplainVersionMismatch(resp, fmt.Errorf("API error %d: %s", resp.StatusCode, string(bodyBytes)))
 	}
 
 	return resp, nil
@@ -87,7 +91,7 @@ func (s *RemoteDownloadService) doRequest(method, path string, body interface{})
 
 // List returns the status of all active and completed downloads.
 func (s *RemoteDownloadService) List() ([]types.DownloadStatus, error) {
-	resp, err := s.doRequest("GET", "/list", nil)
+	resp, err := s.doRequest("GET", "/list?verbose=1", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -95,7 +99,7 @@ func (s *RemoteDownloadService) List() ([]types.DownloadStatus, error) {
 
 	var statuses []types.DownloadStatus
 	if err := json.NewDecoder(resp.Body).Decode(&statuses); err != nil {
-		return nil, err
+		return nil, ExplainVersionMismatch(resp, err)
 	}
 	return statuses, nil
 }
@@ -110,11 +114,26 @@ func (s *RemoteDownloadService) History() ([]types.DownloadEntry, error) {
 
 	var history []types.DownloadEntry
 	if err := json.NewDecoder(resp.Body).Decode(&history); err != nil {
-		return nil, err
+		return nil, ExplainVersionMismatch(resp, err)
 	}
 	return history, nil
 }
 
+// Logs returns the lines the daemon's engine logged for a download.
+func (s *RemoteDownloadService) Logs(id string) ([]utils.LogLine, error) {
+	resp, err := s.doRequest("GET", "/logs?id="+url.QueryEscape(id), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _, _ = io.Copy(io.Discard, resp.Body); _ = resp.Body.Close() }()
+
+	var lines []utils.LogLine
+	if err := json.NewDecoder(resp.Body).Decode(&lines); err != nil {
+		return nil, ExplainVersionMismatch(resp, err)
+	}
+	return lines, nil
+}
+
 // GetStatus returns a status for a single download by id.
 func (s *RemoteDownloadService) GetStatus(id string) (*types.DownloadStatus, error) {
 	resp, err := s.doRequest("GET", "/download?id="+url.QueryEscape(id), nil)
@@ -125,7 +144,7 @@ func (s *RemoteDownloadService) GetStatus(id string) (*types.DownloadStatus, err
 
 	var status types.DownloadStatus
 	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
-		return nil, err
+		return nil, ExplainVersionMismatch(resp, err)
 	}
 	return &status, nil
 }
@@ -152,7 +171,7 @@ func (s *RemoteDownloadService) Add(url string, path string, filename string, mi
 
 	var result map[string]string
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", err
+		return "", ExplainVersionMismatch(resp, err)
 	}
 	return result["id"], nil
 }
@@ -179,7 +198,7 @@ func (s *RemoteDownloadService) AddWithID(url string, path string, filename stri
 
 	var result map[string]string
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", err
+		return "", ExplainVersionMismatch(resp, err)
 	}
 	return result["id"], nil
 }
@@ -204,6 +223,82 @@ func (s *RemoteDownloadService) Resume(id string) error {
 	return nil
 }
 
+// Snooze pauses a download on the remote daemon until until.
+func (s *RemoteDownloadService) Snooze(id string, until time.Time) error {
+	query := url.Values{"id": {id}, "until": {until.Format(time.RFC3339)}}
+	resp, err := s.doRequest("POST", "/snooze?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _, _ = io.Copy(io.Discard, resp.Body); _ = resp.Body.Close() }()
+	return nil
+}
+
+// PauseAll pauses every running and queued download on the remote daemon.
+func (s *RemoteDownloadService) PauseAll() ([]string, error) {
+	return s.postForIDs("/pause-all")
+}
+
+// ResumeAll resumes the downloads PauseAll paused on the remote daemon.
+func (s *RemoteDownloadService) ResumeAll() ([]string, error) {
+	return s.postForIDs("/resume-all")
+}
+
+func (s *RemoteDownloadService) postForIDs(path string) ([]string, error) {
+	resp, err := s.doRequest("POST", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _, _ = io.Copy(io.Discard, resp.Body); _ = resp.Body.Close() }()
+	var result struct {
+		IDs []string `json:"ids"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return result.IDs, nil
+}
+
+// Failed returns the downloads the remote daemon gave up retrying.
+func (s *RemoteDownloadService) Failed() ([]types.DownloadStatus, error) {
+	resp, err := s.doRequest("GET", "/failed", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _, _ = io.Copy(io.Discard, resp.Body); _ = resp.Body.Close() }()
+
+	var failed []types.DownloadStatus
+	if err := json.NewDecoder(resp.Body).Decode(&failed); err != nil {
+		return nil, ExplainVersionMismatch(resp, err)
+	}
+	return failed, nil
+}
+
+// SpeedHistory returns the remote daemon's throughput over the last day.
+func (s *RemoteDownloadService) SpeedHistory() ([]state.SpeedSample, error) {
+	resp, err := s.doRequest("GET", "/speed-history", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _, _ = io.Copy(io.Discard, resp.Body); _ = resp.Body.Close() }()
+
+	var samples []state.SpeedSample
+	if err := json.NewDecoder(resp.Body).Decode(&samples); err != nil {
+		return nil, ExplainVersionMismatch(resp, err)
+	}
+	return samples, nil
+}
+
+// RetryFailed retries failed downloads on the remote daemon, all of them
+// when ids is empty.
+func (s *RemoteDownloadService) RetryFailed(ids []string) ([]string, error) {
+	path := "/retry-failed"
+	if len(ids) > 0 {
+		path += "?" + url.Values{"id": ids}.Encode()
+	}
+	return s.postForIDs(path)
+}
+
 // ResumeBatch resumes multiple paused downloads efficiently.
 func (s *RemoteDownloadService) ResumeBatch(ids []string) []error {
 	errs := make([]error, len(ids))
@@ -303,6 +398,54 @@ func (s *RemoteDownloadService) SetDefaultRateLimit(rate int64) error {
 	return nil
 }
 
+// SetConcurrencyLimits changes the remote daemon's caps on running downloads.
+// A negative value leaves that cap unchanged.
+func (s *RemoteDownloadService) SetConcurrencyLimits(global, perHost, perCategory int) error {
+	query := url.Values{}
+	for key, value := range map[string]int{"global": global, "per_host": perHost, "per_category": perCategory} {
+		if value >= 0 {
+			query.Set(key, strconv.Itoa(value))
+		}
+	}
+	resp, err := s.doRequest("POST", "/concurrency?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _, _ = io.Copy(io.Discard, resp.Body); _ = resp.Body.Close() }()
+	return nil
+}
+
+// SetDraining turns drain mode on or off on the remote daemon.
+func (s *RemoteDownloadService) SetDraining(on bool) error {
+	method := "POST"
+	if !on {
+		method = "DELETE"
+	}
+	resp, err := s.doRequest(method, "/drain", nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _, _ = io.Copy(io.Discard, resp.Body); _ = resp.Body.Close() }()
+	return nil
+}
+
+// Draining reports whether the remote daemon is draining, as its /health
+// says. It is false when the daemon cannot be reached.
+func (s *RemoteDownloadService) Draining() bool {
+	resp, err := s.doRequest("GET", "/health", nil)
+	if err != nil {
+		return false
+	}
+	defer func() { _, _ = io.Copy(io.Discard, resp.Body); _ = resp.Body.Close() }()
+	var health struct {
+		Draining bool `json:"draining"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		return false
+	}
+	return health.Draining
+}
+
 // StreamEvents returns a channel that receives real-time download events via SSE.
 func (s *RemoteDownloadService) StreamEvents(ctx context.Context) (<-chan interface{}, func(), error) {
 	if ctx == nil {
@@ -326,6 +469,8 @@ func (s *RemoteDownloadService) Publish(msg interface{}) error {
 func (s *RemoteDownloadService) streamWithReconnect(ctx context.Context, ch chan interface{}) {
 	defer close(ch)
 	backoff := 1 * time.Second
+	// lastEventID lets the server replay what happened while reconnecting.
+	lastEventID := ""
 	for {
 		select {
 		case <-ctx.Done():
@@ -333,7 +478,7 @@ func (s *RemoteDownloadService) streamWithReconnect(ctx context.Context, ch chan
 		default:
 		}
 
-		err := s.connectSSE(ctx, ch)
+		err := s.connectSSE(ctx, ch, &lastEventID)
 		if err == nil {
 			return // Clean shutdown (e.g. server closed stream cleanly or context canceled during request)
 		}
@@ -368,16 +513,22 @@ func mergeContexts(contexts ...context.Context) (context.Context, context.Cancel
 	}
 }
 
-func (s *RemoteDownloadService) connectSSE(ctx context.Context, ch chan interface{}) error {
+// connectSSE streams events into ch until the connection ends, keeping
+// lastEventID up to date with the newest event received.
+func (s *RemoteDownloadService) connectSSE(ctx context.Context, ch chan interface{}, lastEventID *string) error {
 	req, err := http.NewRequestWithContext(ctx, "GET", s.BaseURL+"/events", nil)
 	if err != nil {
 		return err
 	}
 
 	req.Header.Set("Authorization", "Bearer "+s.Token)
+	SetVersionHeaders(req.Header, s.Version)
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("Cache-Control", "no-cache")
 	req.Header.Set("Connection", "keep-alive")
+	if *lastEventID != "" {
+		req.Header.Set("Last-Event-ID", *lastEventID)
+	}
 
 	resp, err := s.SSEClient.Do(req)
 	if err != nil {
@@ -386,7 +537,7 @@ func (s *RemoteDownloadService) connectSSE(ctx context.Context, ch chan interfac
 	defer func() { _, _ = io.Copy(io.Discard, resp.Body); _ = resp.Body.Close() }()
 
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("failed to connect to event stream: %s", resp.Status)
+		return ExplainVersionMismatch(resp, fmt.Errorf("failed to connect to event stream: %s", resp.Status))
 	}
 
 	reader := bufio.NewReader(resp.Body)
@@ -417,6 +568,10 @@ func (s *RemoteDownloadService) connectSSE(ctx context.Context, ch chan interfac
 				dataLines = append(dataLines, strings.TrimSpace(strings.TrimPrefix(line, "data:")))
 				continue
 			}
+			if strings.HasPrefix(line, "id:") {
+				*lastEventID = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+				continue
+			}
 		}
 
 		if eventType == "" || len(dataLines) == 0 {
@@ -8,8 +8,11 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/events"
 )
 
 func TestRemoteDownloadService_StreamEvents_CleanupClosesChannel(t *testing.T) {
@@ -323,3 +326,54 @@ func TestRemoteDownloadService_SetDefaultRateLimit_RejectsNegativeRate(t *testin
 		t.Fatalf("expected 'non-negative' error, got: %v", err)
 	}
 }
+
+func TestRemoteDownloadService_StreamEvents_ReconnectSendsLastEventID(t *testing.T) {
+	var connections atomic.Int32
+	resumedFrom := make(chan string, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		if connections.Add(1) == 1 {
+			// Send one event, then drop the connection.
+			_, _ = fmt.Fprint(w, "id: 7\nevent: system\ndata: {\"Message\":\"hello\"}\n\n")
+			return
+		}
+		select {
+		case resumedFrom <- r.Header.Get("Last-Event-ID"):
+		default:
+		}
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+
+	svc, err := NewRemoteDownloadService(server.URL, "test-token", HTTPClientOptions{})
+	if err != nil {
+		t.Fatalf("NewRemoteDownloadService returned error: %v", err)
+	}
+	t.Cleanup(func() { _ = svc.Shutdown() })
+
+	stream, cleanup, err := svc.StreamEvents(context.Background())
+	if err != nil {
+		t.Fatalf("StreamEvents returned error: %v", err)
+	}
+	t.Cleanup(cleanup)
+
+	select {
+	case msg := <-stream:
+		if log, ok := msg.(events.SystemLogMsg); !ok || log.Message != "hello" {
+			t.Fatalf("first event = %#v", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the first event")
+	}
+
+	select {
+	case got := <-resumedFrom:
+		if got != "7" {
+			t.Fatalf("Last-Event-ID on reconnect = %q, want 7", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the reconnect")
+	}
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+// SearchFields are the parts of a download a search query is matched against.
+type SearchFields struct {
+	ID       string
+	Alias    string
+	Filename string
+	URL      string
+	Category string
+	Status   string
+	Tags     []string
+}
+
+// SearchScore ranks how well query matches a download, or returns 0 when it
+// does not match. The query is split on whitespace and every term has to
+// match some field; name matches outrank category, status and tag matches,
+// which outrank matches anywhere in the URL.
+func SearchScore(query string, f SearchFields) int {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return 0
+	}
+
+	filename := strings.ToLower(f.Filename)
+	alias := strings.ToLower(f.Alias)
+	category := strings.ToLower(f.Category)
+	status := strings.ToLower(f.Status)
+	rawURL := strings.ToLower(f.URL)
+
+	total := 0
+	for _, term := range terms {
+		best := 0
+		switch {
+		case filename == term || alias == term:
+			best = 100
+		case strings.HasPrefix(filename, term) || strings.HasPrefix(alias, term):
+			best = 60
+		case strings.Contains(filename, term) || strings.Contains(alias, term):
+			best = 40
+		}
+		if best < 30 && (category == term || status == term || types.HasTag(f.Tags, term)) {
+			best = 30
+		}
+		if best < 20 && (strings.Contains(rawURL, term) || strings.HasPrefix(f.ID, term)) {
+			best = 20
+		}
+		if best < 10 && (strings.Contains(category, term) || strings.HasPrefix(status, term)) {
+			best = 10
+		}
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+	return total
+}
+
+// SearchResult is a download that matched a search, with its rank.
+type SearchResult struct {
+	types.DownloadStatus
+	Category string `json:"category,omitempty"`
+	Score    int    `json:"score"`
+}
+
+// Search returns the downloads in statuses that match query, best match
+// first. Downloads are sorted into categories only when categories is
+// non-empty. Ties keep unfinished downloads ahead of history, then newest
+// first.
+func Search(statuses []types.DownloadStatus, query string, categories []config.Category) []SearchResult {
+	results := []SearchResult{}
+	for _, st := range statuses {
+		category := ""
+		if len(categories) > 0 {
+			filename := st.Filename
+			if filename == "" && st.DestPath != "" {
+				filename = filepath.Base(st.DestPath)
+			}
+			if cat, err := config.GetCategoryForFile(filename, categories); err == nil && cat != nil {
+				category = cat.Name
+			}
+		}
+
+		score := SearchScore(query, SearchFields{
+			ID:       st.ID,
+			Alias:    st.Alias,
+			Filename: st.Filename,
+			URL:      st.URL,
+			Category: category,
+			Status:   st.Status,
+			Tags:     st.Tags,
+		})
+		if score > 0 {
+			results = append(results, SearchResult{DownloadStatus: st, Category: category, Score: score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		left, right := results[i], results[j]
+		if left.Score != right.Score {
+			return left.Score > right.Score
+		}
+		if leftDone, rightDone := left.Status == "completed", right.Status == "completed"; leftDone != rightDone {
+			return !leftDone
+		}
+		return left.AddedAt > right.AddedAt
+	})
+	return results
+}
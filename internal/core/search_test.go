@@ -0,0 +1,57 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func TestSearchScore(t *testing.T) {
+	fields := SearchFields{
+		ID:       "3f2a9c10-0000-0000-0000-000000000000",
+		Alias:    "nightly",
+		Filename: "surge-linux-amd64.tar.gz",
+		URL:      "https://github.com/SurgeDM/Surge/releases/download/v1/surge-linux-amd64.tar.gz",
+		Category: "Archives",
+		Status:   "paused",
+		Tags:     []string{"release"},
+	}
+
+	for _, tt := range []struct {
+		query string
+		want  int
+	}{
+		{query: "nightly", want: 100},
+		{query: "surge", want: 60},
+		{query: "AMD64", want: 40},
+		{query: "archives", want: 30},
+		{query: "release", want: 30},
+		{query: "releases", want: 20},
+		{query: "3f2a", want: 20},
+		{query: "arch", want: 10},
+		{query: "linux paused", want: 70},
+		{query: "linux windows", want: 0},
+		{query: "   ", want: 0},
+	} {
+		if got := SearchScore(tt.query, fields); got != tt.want {
+			t.Errorf("SearchScore(%q) = %d, want %d", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearch_MatchesCategories(t *testing.T) {
+	statuses := []types.DownloadStatus{
+		{ID: "video", Filename: "talk.mp4", Status: "completed"},
+		{ID: "archive", Filename: "src.zip", Status: "downloading"},
+	}
+	categories := []config.Category{{Name: "Videos", Pattern: `(?i)\.mp4$`}}
+
+	results := Search(statuses, "videos", categories)
+	if len(results) != 1 || results[0].ID != "video" || results[0].Category != "Videos" {
+		t.Fatalf("Search by category = %+v", results)
+	}
+	if results := Search(statuses, "videos", nil); len(results) != 0 {
+		t.Fatalf("categories off should not match by category, got %+v", results)
+	}
+}
@@ -0,0 +1,59 @@
+package download
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// pauseForHTML pauses a download before it fetches anything because its
+// server answered with a web page where the URL names a binary file, and
+// says so in the log. Nothing is kept, so a resume starts over and saves
+// whatever the server sends.
+func pauseForHTML(cfg *types.DownloadConfig, destPath, contentType string, total int64) {
+	pauseBeforeFetch(cfg, destPath, total, types.ErrUnexpectedHTML,
+		fmt.Sprintf("Paused %s: the server sent a web page (%s) instead of the file, likely a login or error page. Resume it to save the page anyway.", filepath.Base(destPath), contentType))
+	utils.DebugFor(cfg.ID, "Paused %s: server answered %s with %s", destPath, cfg.URL, contentType)
+}
+
+// pauseBeforeFetch pauses a download that has not fetched anything yet with
+// cause, and puts message in the log.
+func pauseBeforeFetch(cfg *types.DownloadConfig, destPath string, total int64, cause error, message string) {
+	if cfg.State == nil {
+		return
+	}
+	cfg.State.PauseFor(cause)
+	rateLimit, rateLimitSet := cfg.State.GetRateLimit()
+	elapsed := cfg.State.FinalizePauseSession(0)
+
+	s := &types.DownloadState{
+		URL:          cfg.URL,
+		ID:           cfg.ID,
+		DestPath:     destPath,
+		TotalSize:    total,
+		Filename:     filepath.Base(destPath),
+		Elapsed:      elapsed.Nanoseconds(),
+		Mirrors:      cfg.Mirrors,
+		RateLimit:    rateLimit,
+		RateLimitSet: rateLimitSet,
+		Headers:      cfg.Headers,
+		Checksum:     cfg.Request.Checksum,
+		Copies:       cfg.Request.Copies,
+		SignatureURL: cfg.Request.SignatureURL,
+		Interface:    cfg.Request.Interface,
+		TLS:          cfg.TLS,
+	}
+	if cfg.ProgressCh != nil {
+		safeSendProgress(cfg.ProgressCh, events.DownloadPausedMsg{
+			DownloadID:   cfg.ID,
+			Filename:     s.Filename,
+			State:        s,
+			RateLimit:    rateLimit,
+			RateLimitSet: rateLimitSet,
+		})
+		safeSendProgress(cfg.ProgressCh, events.SystemLogMsg{Message: message})
+	}
+}
@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/SurgeDM/Surge/internal/engine"
 	"github.com/SurgeDM/Surge/internal/engine/concurrent"
 	"github.com/SurgeDM/Surge/internal/engine/events"
 	"github.com/SurgeDM/Surge/internal/engine/single"
@@ -71,6 +73,20 @@ func uniqueFilePath(path string) string {
 	return path
 }
 
+// isSmallFileDownload reports whether a download is small enough that chunk
+// setup, preallocation and chunk-state tracking cost more than they save.
+// Downloads resuming from saved chunk state keep the concurrent path. The
+// single downloader saves no chunk state, and a fresh small download no
+// started record either: its queued record is enough to restart it after a
+// crash, and its completed record keeps its history.
+func isSmallFileDownload(runtime *types.RuntimeConfig, savedState *types.DownloadState, totalSize int64) bool {
+	threshold := runtime.GetSmallFileThreshold()
+	if threshold <= 0 || totalSize <= 0 || totalSize >= threshold {
+		return false
+	}
+	return savedState == nil || len(savedState.Tasks) == 0
+}
+
 // TUIDownload is the main entry point for downloads executed by the Engine pool
 func TUIDownload(ctx context.Context, cfg *types.DownloadConfig) error {
 	start := time.Now()
@@ -109,7 +125,7 @@ func TUIDownload(ctx context.Context, cfg *types.DownloadConfig) error {
 					existing[m] = true
 				}
 			}
-			utils.Debug("Restored %d mirrors from state", len(savedState.Mirrors))
+			utils.DebugFor(cfg.ID, "Restored %d mirrors from state", len(savedState.Mirrors))
 		}
 	}
 	isResume := cfg.IsResume && savedState != nil && savedState.DestPath != ""
@@ -118,13 +134,67 @@ func TUIDownload(ctx context.Context, cfg *types.DownloadConfig) error {
 		// Resume: use saved destination path directly (don't generate new unique name)
 		finalDestPath = savedState.DestPath
 		finalFilename = filepath.Base(finalDestPath)
-		utils.Debug("Resuming download, using saved destPath: %s", finalDestPath)
+		utils.DebugFor(cfg.ID, "Resuming download, using saved destPath: %s", finalDestPath)
+	}
+	utils.DebugFor(cfg.ID, "Destination path: %s", finalDestPath)
+
+	// What the probe already did for this download: bytes an early-ramp probe
+	// wrote to the start of the working file, and where the URL redirected to.
+	// A resume trusts its saved state.
+	handoff := cfg.Probe
+	earlyBytes := handoff.EarlyBytes
+	finalURL := handoff.FinalURL
+	if isResume {
+		earlyBytes = 0
+		finalURL = savedState.FinalURL
+	}
+
+	// A TLS override from the request applies to this download only. A
+	// resume finds it in the saved state.
+	if !handoff.TLS.IsZero() {
+		cfg.TLS = handoff.TLS
+	}
+	if isResume && cfg.TLS.IsZero() {
+		cfg.TLS = savedState.TLS
+	}
+	if !cfg.TLS.IsZero() {
+		runtime := *cfg.Runtime
+		runtime.TLS = runtime.TLS.Merge(cfg.TLS)
+		cfg.Runtime = &runtime
+	}
+	if !handoff.Request.IsZero() {
+		cfg.Request = handoff.Request
+	}
+	if n := cfg.Request.Connections; n > 0 && n < cfg.Runtime.GetMaxConnectionsPerDownload() {
+		runtime := *cfg.Runtime
+		runtime.MaxConnectionsPerDownload = n
+		cfg.Runtime = &runtime
+	}
+	if !cfg.Request.Timeouts.IsZero() {
+		cfg.Runtime = cfg.Request.Timeouts.Apply(cfg.Runtime)
+	}
+	cfg.Runtime = types.BindRuntime(cfg.Runtime, cfg.Request.Interface, cfg.URL)
+	if maxDuration := cfg.Runtime.GetMaxDuration(); maxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, maxDuration, types.ErrMaxDuration)
+		defer cancel()
+	}
+	if !handoff.S3.IsZero() {
+		cfg.S3 = handoff.S3
+	}
+	if isResume && !savedState.S3.IsZero() {
+		cfg.S3 = savedState.S3
 	}
-	utils.Debug("Destination path: %s", finalDestPath)
 
 	if cfg.State != nil {
 		cfg.State.SetFilename(finalFilename)
 		cfg.State.SetDestPath(finalDestPath)
+		if !handoff.LastModified.IsZero() {
+			cfg.State.SetLastModified(handoff.LastModified)
+		}
+		if finalURL != "" {
+			cfg.State.SetFinalURL(finalURL)
+		}
 	}
 
 	currentRateLimit := func() (int64, bool) {
@@ -140,12 +210,14 @@ func TUIDownload(ctx context.Context, cfg *types.DownloadConfig) error {
 		safeSendProgress(cfg.ProgressCh, events.DownloadStartedMsg{
 			DownloadID:   cfg.ID,
 			URL:          cfg.URL,
+			FinalURL:     finalURL,
 			Filename:     finalFilename,
 			Total:        cfg.TotalSize, // Relies on TotalSize from Config
 			DestPath:     finalDestPath,
 			State:        cfg.State,
 			RateLimit:    rateLimit,
 			RateLimitSet: rateLimitSet,
+			SmallFile:    !cfg.IsResume && isSmallFileDownload(cfg.Runtime, nil, cfg.TotalSize),
 		})
 	}
 
@@ -154,6 +226,20 @@ func TUIDownload(ctx context.Context, cfg *types.DownloadConfig) error {
 		cfg.State.SetTotalSize(cfg.TotalSize)
 	}
 
+	// Once the month's quota is used up new downloads wait; resuming one
+	// downloads it anyway.
+	if !cfg.IsResume && cfg.State != nil && handoff.QuotaReached != nil && handoff.QuotaReached() {
+		pauseForQuota(cfg, finalDestPath, cfg.TotalSize)
+		return nil
+	}
+
+	// A login or error page served in place of an archive would otherwise be
+	// saved under its name. Resuming skips this, to save the page anyway.
+	if !cfg.IsResume && !cfg.Request.AllowHTML && cfg.State != nil && engine.UnexpectedHTML(cfg.URL, handoff.ContentType) {
+		pauseForHTML(cfg, finalDestPath, handoff.ContentType, cfg.TotalSize)
+		return nil
+	}
+
 	effectiveTotalSize := cfg.TotalSize
 	if cfg.State != nil && effectiveTotalSize <= 0 {
 		_, stateTotal, _, _, _, _ := cfg.State.GetProgress()
@@ -164,26 +250,48 @@ func TUIDownload(ctx context.Context, cfg *types.DownloadConfig) error {
 
 	// Choose downloader based on probe results
 	var downloadErr error
-	useConcurrent := cfg.SupportsRange
+	fetchedByProbe := earlyBytes > 0 && effectiveTotalSize > 0 && earlyBytes >= effectiveTotalSize
+	// A download paused while verifying resumes straight into verifying.
+	if isResume && fetchedOnResume(savedState, finalDestPath) {
+		utils.DebugFor(cfg.ID, "Resuming %s after it was fetched, verifying only", finalDestPath)
+		fetchedByProbe = true
+		effectiveTotalSize = savedState.TotalSize
+	}
+	smallFile := !fetchedByProbe && isSmallFileDownload(cfg.Runtime, savedState, effectiveTotalSize)
+	// Requests with a method or body (e.g. POST exports) are sent once over a
+	// single connection; replaying them per chunk could repeat the export.
+	useConcurrent := cfg.SupportsRange && cfg.Request.IsGet() && !fetchedByProbe && !smallFile
+
+	if fetchedByProbe {
+		utils.DebugFor(cfg.ID, "Early ramp probe already fetched all %d bytes", effectiveTotalSize)
+	}
+	if smallFile {
+		utils.DebugFor(cfg.ID, "Small file (%d bytes), skipping chunked setup", effectiveTotalSize)
+	}
 
 	if useConcurrent {
-		utils.Debug("Using concurrent downloader")
+		utils.DebugFor(cfg.ID, "Using concurrent downloader")
 
 		// We probe all candidate mirrors (mirrors) to filter out invalid ones
 		var activeMirrors []string
 		if len(mirrors) > 0 {
-			utils.Debug("Probing %d mirrors", len(mirrors))
+			utils.DebugFor(cfg.ID, "Probing %d mirrors", len(mirrors))
 			// Always check primary + mirrors to ensure we are using the best set
 			allToCheck := append([]string{cfg.URL}, mirrors...)
 			runCfg := &types.RuntimeConfig{
-				ProxyURL:  cfg.Runtime.ProxyURL,
-				CustomDNS: cfg.Runtime.CustomDNS,
+				ProxyURL:          cfg.Runtime.ProxyURL,
+				ProxyFallbacks:    cfg.Runtime.ProxyFallbacks,
+				IgnoreSystemProxy: cfg.Runtime.IgnoreSystemProxy,
+				CustomDNS:         cfg.Runtime.CustomDNS,
+				BindInterface:     cfg.Runtime.BindInterface,
+				SourcePorts:       cfg.Runtime.SourcePorts,
+				TLS:               cfg.Runtime.TLS,
 			}
 			valid, errs := processing.ProbeMirrorsWithProxy(ctx, allToCheck, runCfg)
 
 			// Log errors
 			for u, e := range errs {
-				utils.Debug("Mirror probe failed for %s: %v", u, e)
+				utils.DebugFor(cfg.ID, "Mirror probe failed for %s: %v", u, e)
 			}
 
 			// Filter valid mirrors (excluding primary as it is handled separately)
@@ -192,15 +300,33 @@ func TUIDownload(ctx context.Context, cfg *types.DownloadConfig) error {
 					activeMirrors = append(activeMirrors, v)
 				}
 			}
-			utils.Debug("Found %d active mirrors from %d candidates", len(activeMirrors), len(mirrors))
+			utils.DebugFor(cfg.ID, "Found %d active mirrors from %d candidates", len(activeMirrors), len(mirrors))
+		}
+
+		// On resume, let workers use the endpoint the URL last redirected to
+		// directly; the original URL stays in rotation as the fallback.
+		if isResume && finalURL != "" && finalURL != cfg.URL && !slices.Contains(activeMirrors, finalURL) {
+			activeMirrors = append(activeMirrors, finalURL)
 		}
 
 		d := concurrent.NewConcurrentDownloader(cfg.ID, cfg.ProgressCh, cfg.State, cfg.Runtime)
 		d.Headers = cfg.Headers // Forward custom headers from browser extension
 		d.Limiter = cfg.Limiter
+		d.Budget = cfg.Budget
 		d.RateLimitBps = cfg.RateLimitBps
 		d.RateLimitSet = cfg.RateLimitSet
-		utils.Debug("Calling Download with mirrors: %v", mirrors)
+		d.EarlyBytes = earlyBytes
+		d.S3 = cfg.S3
+		d.LowPriority = cfg.Request.LowPriority
+		d.Checksum = cfg.Request.Checksum
+		d.Copies = cfg.Request.Copies
+		d.SignatureURL = cfg.Request.SignatureURL
+		d.Interface = cfg.Request.Interface
+		d.TLS = cfg.TLS
+		utils.DebugFor(cfg.ID, "Calling Download with mirrors: %v", mirrors)
+		if cfg.State != nil {
+			cfg.State.SetPhase(types.PhaseDownloading)
+		}
 		// Pass effectiveTotalSize to avoid unnecessary bootstrap if state already knows the size
 		downloadErr = d.Download(ctx, cfg.URL, mirrors, activeMirrors, finalDestPath, effectiveTotalSize)
 		if d.TotalSize > 0 {
@@ -209,8 +335,10 @@ func TUIDownload(ctx context.Context, cfg *types.DownloadConfig) error {
 
 		// Determine if we should attempt a fallback to single-threaded mode.
 		// We fallback if concurrent failed, but it wasn't a clean pause or external cancellation.
-		if downloadErr != nil && !errors.Is(downloadErr, types.ErrPaused) && !errors.Is(downloadErr, context.Canceled) && !errors.Is(downloadErr, context.DeadlineExceeded) {
-			utils.Debug("Concurrent download failed: %v - falling back to single-threaded", downloadErr)
+		// A resume that no longer matches the remote file is left to the user
+		// to restart rather than silently starting over.
+		if downloadErr != nil && !errors.Is(downloadErr, types.ErrPaused) && !errors.Is(downloadErr, context.Canceled) && !errors.Is(downloadErr, context.DeadlineExceeded) && !errors.Is(downloadErr, types.ErrResumeMismatch) && !errors.Is(context.Cause(ctx), types.ErrMaxDuration) {
+			utils.DebugFor(cfg.ID, "Concurrent download failed: %v - falling back to single-threaded", downloadErr)
 			useConcurrent = false // Trigger sequential block below
 
 			// Reset progress state cleanly for single-stream restart from byte 0
@@ -222,15 +350,21 @@ func TUIDownload(ctx context.Context, cfg *types.DownloadConfig) error {
 			// from the failed concurrent session.
 			surgePath := finalDestPath + types.IncompleteSuffix
 			_ = os.Truncate(surgePath, 0)
+			engine.TruncateCopies(finalDestPath, cfg.Request.Copies)
 		}
 	}
 
-	if !useConcurrent {
+	if !useConcurrent && !fetchedByProbe {
 		// Fallback to single-threaded downloader
-		utils.Debug("Using single-threaded downloader")
+		utils.DebugFor(cfg.ID, "Using single-threaded downloader")
 		d := single.NewSingleDownloader(cfg.ID, cfg.ProgressCh, cfg.State, cfg.Runtime)
 		d.Headers = cfg.Headers // Forward custom headers from browser extension
 		d.Limiter = cfg.Limiter
+		d.SkipPreallocate = smallFile
+		d.Request = cfg.Request
+		if cfg.State != nil {
+			cfg.State.SetPhase(types.PhaseDownloading)
+		}
 		// Pass effectiveTotalSize here as well
 		downloadErr = d.Download(ctx, cfg.URL, finalDestPath, effectiveTotalSize, finalFilename)
 		if d.TotalSize > 0 {
@@ -238,10 +372,82 @@ func TUIDownload(ctx context.Context, cfg *types.DownloadConfig) error {
 		}
 	}
 
+	// Everything was fetched; what follows checks the finished file. Reading
+	// a large file back takes a while, so the download shows as verifying
+	// and a pause from here on saves it as fetched.
+	fetched := downloadErr == nil
+	progress := verifyProgress(cfg)
+
+	// S3 sent a checksum for the object; a mismatch means the bytes on disk
+	// are not what was uploaded, even if every range arrived.
+	if downloadErr == nil && !cfg.S3.Checksum.IsZero() && (cfg.State == nil || !cfg.State.IsPaused()) {
+		if err := engine.VerifyS3Checksum(ctx, finalDestPath+types.IncompleteSuffix, cfg.S3, progress); err != nil {
+			downloadErr = err
+		} else {
+			utils.DebugFor(cfg.ID, "S3 %s checksum verified for %s", cfg.S3.Checksum.Algorithm, finalDestPath)
+		}
+	}
+
+	// A followed download keeps appending while the remote file grows, and
+	// completes only once its size has been stable for the follow window.
+	if downloadErr == nil && cfg.Request.Follow && (cfg.State == nil || !cfg.State.IsPaused()) {
+		if info, err := os.Stat(finalDestPath + types.IncompleteSuffix); err == nil {
+			effectiveTotalSize = info.Size()
+		}
+		utils.DebugFor(cfg.ID, "Following %s for growth past %d bytes", cfg.URL, effectiveTotalSize)
+		f := single.NewSingleDownloader(cfg.ID, cfg.ProgressCh, cfg.State, cfg.Runtime)
+		f.Headers = cfg.Headers
+		f.Limiter = cfg.Limiter
+		f.Request = cfg.Request
+		effectiveTotalSize, downloadErr = f.Follow(ctx, cfg.URL, finalDestPath, effectiveTotalSize)
+	}
+
+	// The request named the digest the file must have; a mismatch fails the
+	// download rather than handing over a corrupt or substituted file.
+	if downloadErr == nil && cfg.Request.Checksum != "" && (cfg.State == nil || !cfg.State.IsPaused()) {
+		if err := engine.VerifyChecksum(ctx, finalDestPath+types.IncompleteSuffix, cfg.Request.Checksum, progress); err != nil {
+			downloadErr = err
+		} else {
+			utils.DebugFor(cfg.ID, "Checksum %s verified for %s", cfg.Request.Checksum, finalDestPath)
+		}
+	}
+
+	// A signature from a trusted key proves who published the file, which a
+	// checksum from the same server cannot.
+	var verified bool
+	if downloadErr == nil && (cfg.State == nil || !cfg.State.IsPaused()) {
+		verified, downloadErr = verifySignature(ctx, cfg, finalDestPath+types.IncompleteSuffix, progress)
+	}
+	// A delete that lands while the pause is still settling throws the
+	// download away, so nothing is saved for it.
+	deleted := errors.Is(context.Cause(ctx), types.ErrUserDelete)
+	if fetched && !deleted && cfg.State != nil && cfg.State.IsPaused() && (downloadErr == nil || errors.Is(downloadErr, context.Canceled)) {
+		pauseVerifying(cfg, finalDestPath, cfg.State.GetFinalURL(), effectiveTotalSize)
+		return nil
+	}
+
+	if downloadErr == nil && cfg.State != nil && !cfg.State.IsPaused() {
+		cfg.State.SetPhase(types.PhasePostProcessing)
+	}
+
+	// Like wget, the file keeps the server's modification time. Renaming the
+	// working file into place preserves it.
+	if downloadErr == nil && cfg.State != nil && !cfg.State.IsPaused() && cfg.Runtime.ServerModTime {
+		if err := engine.SetModTime(finalDestPath+types.IncompleteSuffix, cfg.State.GetLastModified()); err != nil {
+			utils.DebugFor(cfg.ID, "Failed to set modification time of %s: %v", finalDestPath, err)
+		}
+	}
+
+	// Running past the max duration fails the download, whatever error the
+	// step it was in made of the expired context.
+	if downloadErr != nil && errors.Is(context.Cause(ctx), types.ErrMaxDuration) {
+		downloadErr = types.ErrMaxDuration
+	}
+
 	// Only send completion if NO error AND not paused
 	// Check specifically for ErrPaused to avoid treating it as error
 	if errors.Is(downloadErr, types.ErrPaused) {
-		utils.Debug("Download paused cleanly")
+		utils.DebugFor(cfg.ID, "Download paused cleanly")
 		return nil // Return nil so worker can remove it from active map
 	}
 
@@ -254,6 +460,10 @@ func TUIDownload(ctx context.Context, cfg *types.DownloadConfig) error {
 			elapsed = time.Since(start)
 		}
 
+		// The copies are moved into place first, so the one completion event
+		// covers every destination.
+		copies := engine.FinalizeCopies(finalDestPath, cfg.Request.Copies)
+
 		// Persist to history before sending event
 		// Compute average download speed in bytes/sec
 		var avgSpeed float64
@@ -271,12 +481,15 @@ func TUIDownload(ctx context.Context, cfg *types.DownloadConfig) error {
 				AvgSpeed:     avgSpeed,
 				RateLimit:    rateLimit,
 				RateLimitSet: rateLimitSet,
+				Checksum:     verifiedChecksum(cfg.Request.Checksum),
+				Copies:       len(copies),
+				Verified:     verified,
 			})
 		}
 	} else if downloadErr != nil && !isPaused {
 		// Verify it's not a cancellation error
 		if errors.Is(downloadErr, context.Canceled) || errors.Is(downloadErr, context.DeadlineExceeded) {
-			utils.Debug("Download canceled cleanly")
+			utils.DebugFor(cfg.ID, "Download canceled cleanly")
 			return nil
 		}
 
@@ -294,6 +507,19 @@ func TUIDownload(ctx context.Context, cfg *types.DownloadConfig) error {
 	return downloadErr
 }
 
+// verifiedChecksum returns a checksum the download was verified against as
+// algorithm:hex, or "" when there was none.
+func verifiedChecksum(checksum string) string {
+	if checksum == "" {
+		return ""
+	}
+	algorithm, digest, err := types.ParseChecksum(checksum)
+	if err != nil {
+		return ""
+	}
+	return algorithm + ":" + digest
+}
+
 // Download is the CLI entry point (non-TUI) - convenience wrapper
 func Download(ctx context.Context, url string, outPath string, progressCh chan<- any, id string) error {
 	cfg := types.DownloadConfig{
@@ -1,12 +1,17 @@
 package download
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -257,6 +262,142 @@ func TestTUIDownload_ConcurrentBootstrapWithoutProbeMetadata(t *testing.T) {
 	}
 }
 
+func TestTUIDownload_SetsServerModTime(t *testing.T) {
+	data := bytes.Repeat([]byte("surge"), 64*1024)
+	modTime := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "file.bin", modTime, bytes.NewReader(data))
+	}))
+	defer server.Close()
+
+	for _, concurrent := range []bool{false, true} {
+		t.Run(fmt.Sprintf("concurrent=%v", concurrent), func(t *testing.T) {
+			tmpDir := t.TempDir()
+			surgePath := filepath.Join(tmpDir, "file.bin") + types.IncompleteSuffix
+			if err := os.WriteFile(surgePath, nil, 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg := types.DownloadConfig{
+				URL:           server.URL,
+				OutputPath:    tmpDir,
+				Filename:      "file.bin",
+				ID:            "modtime-test",
+				ProgressCh:    make(chan any, 64),
+				State:         types.NewProgressState("modtime-test", int64(len(data))),
+				Runtime:       types.DefaultRuntimeConfig(),
+				TotalSize:     int64(len(data)),
+				SupportsRange: concurrent,
+			}
+			// Small files skip the concurrent engine
+			cfg.Runtime.SmallFileThreshold = 0
+			if err := TUIDownload(context.Background(), &cfg); err != nil {
+				t.Fatalf("TUIDownload failed: %v", err)
+			}
+
+			info, err := os.Stat(surgePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !info.ModTime().Equal(modTime) {
+				t.Fatalf("mtime = %v, want the server's Last-Modified %v", info.ModTime(), modTime)
+			}
+		})
+	}
+}
+
+func TestTUIDownload_WaitsWhenQuotaReached(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		_, _ = w.Write([]byte("data"))
+	}))
+	defer server.Close()
+
+	state := types.NewProgressState("quota-test", 4)
+	var cause error
+	state.SetCancelFunc(func(err error) { cause = err })
+	cfg := types.DownloadConfig{
+		URL:        server.URL,
+		OutputPath: t.TempDir(),
+		Filename:   "file.bin",
+		ID:         "quota-test",
+		ProgressCh: make(chan any, 8),
+		State:      state,
+		Runtime:    types.DefaultRuntimeConfig(),
+		TotalSize:  4,
+		Probe:      types.ProbeHandoff{QuotaReached: func() bool { return true }},
+	}
+	if err := TUIDownload(context.Background(), &cfg); err != nil {
+		t.Fatalf("TUIDownload failed: %v", err)
+	}
+	if !state.IsPaused() || !errors.Is(cause, types.ErrQuotaReached) {
+		t.Fatalf("paused = %v, cause = %v; want paused for the quota", state.IsPaused(), cause)
+	}
+	if n := hits.Load(); n != 0 {
+		t.Fatalf("server hit %d times, want none", n)
+	}
+}
+
+func TestTUIDownload_MaxDurationFailsDownload(t *testing.T) {
+	const size = 4 * types.MB
+	// The server trickles a byte at a time, so the download never finishes
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Accept-Ranges", "bytes")
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", size))
+		w.WriteHeader(http.StatusOK)
+		for {
+			if _, err := w.Write([]byte{0}); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "slow.bin")+types.IncompleteSuffix, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	progressCh := make(chan any, 256)
+	cfg := types.DownloadConfig{
+		URL:        server.URL,
+		OutputPath: tmpDir,
+		Filename:   "slow.bin",
+		ID:         "max-time-test",
+		ProgressCh: progressCh,
+		State:      types.NewProgressState("max-time-test", size),
+		Runtime:    types.DefaultRuntimeConfig(),
+		TotalSize:  size,
+		Request:    types.RequestOptions{Timeouts: types.Timeouts{Max: types.Duration(300 * time.Millisecond)}},
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- TUIDownload(context.Background(), &cfg) }()
+	select {
+	case err := <-done:
+		if !errors.Is(err, types.ErrMaxDuration) {
+			t.Fatalf("err = %v, want ErrMaxDuration", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("download kept running past its max duration")
+	}
+	for len(progressCh) > 0 {
+		if msg, ok := (<-progressCh).(events.DownloadErrorMsg); ok {
+			if !errors.Is(msg.Err, types.ErrMaxDuration) {
+				t.Errorf("error event = %v, want ErrMaxDuration", msg.Err)
+			}
+			return
+		}
+	}
+	t.Error("expected a DownloadErrorMsg")
+}
+
 func TestTUIDownload_OptimisticConcurrentFallsBackToSingle(t *testing.T) {
 	tmpDir := t.TempDir()
 	content := []byte("fallback download content")
@@ -929,3 +1070,268 @@ func BenchmarkUniqueFilePath_WithConflict(b *testing.B) {
 		uniqueFilePath(path)
 	}
 }
+
+func TestTUIDownload_SmallFileUsesSingleConnection(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := make([]byte, 64*types.KB)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+
+	var requests, rangeRequests atomic.Int32
+	server := testutil.NewHTTPServerT(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("Range") != "" {
+			rangeRequests.Add(1)
+		}
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(content)))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	surgePath := filepath.Join(tmpDir, "small.bin") + types.IncompleteSuffix
+	if err := os.WriteFile(surgePath, nil, 0o644); err != nil {
+		t.Fatalf("failed to pre-create incomplete file: %v", err)
+	}
+
+	cfg := types.DownloadConfig{
+		URL:           server.URL,
+		OutputPath:    tmpDir,
+		Filename:      "small.bin",
+		ID:            "small-file-test",
+		State:         types.NewProgressState("small-file-test", int64(len(content))),
+		Runtime:       &types.RuntimeConfig{SmallFileThreshold: 1 * types.MB},
+		TotalSize:     int64(len(content)),
+		SupportsRange: true,
+	}
+
+	if err := TUIDownload(context.Background(), &cfg); err != nil {
+		t.Fatalf("TUIDownload failed: %v", err)
+	}
+
+	got, err := os.ReadFile(surgePath)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Fatal("downloaded content does not match served data")
+	}
+	if requests.Load() != 1 {
+		t.Errorf("requests = %d, want 1", requests.Load())
+	}
+	if rangeRequests.Load() != 0 {
+		t.Errorf("range requests = %d, want 0 for the small-file path", rangeRequests.Load())
+	}
+}
+
+func TestIsSmallFileDownload(t *testing.T) {
+	runtime := &types.RuntimeConfig{SmallFileThreshold: 8 * types.MB}
+	resumable := &types.DownloadState{Tasks: []types.Task{{Offset: 0, Length: types.MB}}}
+
+	tests := []struct {
+		name    string
+		runtime *types.RuntimeConfig
+		saved   *types.DownloadState
+		size    int64
+		want    bool
+	}{
+		{"below threshold", runtime, nil, 1 * types.MB, true},
+		{"at threshold", runtime, nil, 8 * types.MB, false},
+		{"unknown size", runtime, nil, 0, false},
+		{"disabled", &types.RuntimeConfig{}, nil, 1 * types.MB, false},
+		{"nil runtime", nil, nil, 1 * types.MB, false},
+		{"saved chunk state", runtime, resumable, 1 * types.MB, false},
+		{"saved state without tasks", runtime, &types.DownloadState{}, 1 * types.MB, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSmallFileDownload(tt.runtime, tt.saved, tt.size); got != tt.want {
+				t.Errorf("isSmallFileDownload() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTUIDownload_FollowCompletesWithGrownSize(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := bytes.Repeat([]byte("log line\n"), 1000)
+	var served atomic.Int64
+	served.Store(4500)
+	server := testutil.NewHTTPServerT(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "app.log", time.Time{}, bytes.NewReader(content[:served.Load()]))
+	}))
+	defer server.Close()
+
+	surgePath := filepath.Join(tmpDir, "app.log") + types.IncompleteSuffix
+	if err := os.WriteFile(surgePath, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	progressCh := make(chan any, 16)
+	cfg := types.DownloadConfig{
+		URL:        server.URL,
+		OutputPath: tmpDir,
+		Filename:   "app.log",
+		ID:         "follow-test",
+		ProgressCh: progressCh,
+		State:      types.NewProgressState("follow-test", 4500),
+		Runtime:    &types.RuntimeConfig{FollowStableWindow: 300 * time.Millisecond},
+		Request:    types.RequestOptions{Follow: true},
+		TotalSize:  4500,
+	}
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		served.Store(int64(len(content)))
+	}()
+	if err := TUIDownload(context.Background(), &cfg); err != nil {
+		t.Fatalf("TUIDownload failed: %v", err)
+	}
+
+	if got, _ := os.ReadFile(surgePath); !bytes.Equal(got, content) {
+		t.Fatalf("working file has %d bytes, want the grown %d", len(got), len(content))
+	}
+	for len(progressCh) > 0 {
+		if complete, ok := (<-progressCh).(events.DownloadCompleteMsg); ok {
+			if complete.Total != int64(len(content)) {
+				t.Fatalf("complete total = %d, want %d", complete.Total, len(content))
+			}
+			return
+		}
+	}
+	t.Fatal("expected completion event")
+}
+
+func TestTUIDownload_PausedWhileVerifyingResumesVerifyOnly(t *testing.T) {
+	tmpDir := t.TempDir()
+	data := bytes.Repeat([]byte("surge verify "), 2*1024*1024)
+	sum := sha256.Sum256(data)
+	checksum := "sha256:" + hex.EncodeToString(sum[:])
+	var requests atomic.Int32
+	server := testutil.NewHTTPServerT(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.ServeContent(w, r, "disk.img", time.Time{}, bytes.NewReader(data))
+	}))
+	defer server.Close()
+
+	surgePath := filepath.Join(tmpDir, "disk.img") + types.IncompleteSuffix
+	if err := os.WriteFile(surgePath, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Pause as soon as verifying starts
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	progressCh := make(chan any)
+	state := types.NewProgressState("verify-test", int64(len(data)))
+	paused := make(chan events.DownloadPausedMsg, 1)
+	go func() {
+		for msg := range progressCh {
+			switch m := msg.(type) {
+			case events.DownloadVerifyingMsg:
+				if !state.IsPaused() {
+					state.Pause()
+					cancel()
+				}
+			case events.DownloadPausedMsg:
+				paused <- m
+			}
+		}
+	}()
+	defer close(progressCh)
+
+	cfg := types.DownloadConfig{
+		URL:        server.URL,
+		OutputPath: tmpDir,
+		Filename:   "disk.img",
+		ID:         "verify-test",
+		ProgressCh: progressCh,
+		State:      state,
+		Runtime:    types.DefaultRuntimeConfig(),
+		Request:    types.RequestOptions{Checksum: checksum},
+		TotalSize:  int64(len(data)),
+	}
+	if err := TUIDownload(ctx, &cfg); err != nil {
+		t.Fatalf("TUIDownload failed: %v", err)
+	}
+	var saved *types.DownloadState
+	select {
+	case m := <-paused:
+		saved = m.State
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a pause event")
+	}
+	if saved == nil || !saved.Fetched || saved.Downloaded != int64(len(data)) || saved.Checksum != checksum {
+		t.Fatalf("paused state = %+v, want it fetched with the checksum kept", saved)
+	}
+
+	// The resume checks the file again without fetching it
+	requests.Store(0)
+	complete := make(chan any, 64)
+	resumed := types.DownloadConfig{
+		URL:        server.URL,
+		OutputPath: tmpDir,
+		Filename:   "disk.img",
+		DestPath:   saved.DestPath,
+		ID:         "verify-test",
+		ProgressCh: complete,
+		State:      types.NewProgressState("verify-test", int64(len(data))),
+		Runtime:    types.DefaultRuntimeConfig(),
+		Request:    types.RequestOptions{Checksum: saved.Checksum},
+		TotalSize:  saved.TotalSize,
+		IsResume:   true,
+		SavedState: saved,
+	}
+	if err := TUIDownload(context.Background(), &resumed); err != nil {
+		t.Fatalf("resumed TUIDownload failed: %v", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("resume made %d requests, want none", n)
+	}
+	var sawVerifying bool
+	for len(complete) > 0 {
+		switch m := (<-complete).(type) {
+		case events.DownloadVerifyingMsg:
+			sawVerifying = true
+		case events.DownloadCompleteMsg:
+			if !sawVerifying || m.Checksum != checksum {
+				t.Fatalf("complete = %+v after verifying=%v, want the checksum verified", m, sawVerifying)
+			}
+			return
+		}
+	}
+	t.Fatal("expected completion event")
+}
+
+func TestPauseForHTML_PausesWithNothingKept(t *testing.T) {
+	progressCh := make(chan any, 4)
+	state := types.NewProgressState("html-test", 2048)
+	var cause error
+	state.SetCancelFunc(func(err error) { cause = err })
+	cfg := types.DownloadConfig{
+		URL:        "https://example.com/file.zip",
+		ID:         "html-test",
+		ProgressCh: progressCh,
+		State:      state,
+		Request:    types.RequestOptions{Checksum: "sha256:abc"},
+	}
+
+	destPath := filepath.Join(t.TempDir(), "file.zip")
+	pauseForHTML(&cfg, destPath, "text/html", 2048)
+
+	if !state.IsPaused() || !errors.Is(cause, types.ErrUnexpectedHTML) {
+		t.Fatalf("paused = %v, cause = %v; want paused for unexpected HTML", state.IsPaused(), cause)
+	}
+	paused, ok := (<-progressCh).(events.DownloadPausedMsg)
+	if !ok {
+		t.Fatal("expected a pause event first")
+	}
+	if s := paused.State; s == nil || len(s.Tasks) != 0 || s.Downloaded != 0 || s.Checksum != "sha256:abc" || s.DestPath != destPath {
+		t.Fatalf("paused state = %+v, want nothing downloaded and the request kept", paused.State)
+	}
+	if _, ok := (<-progressCh).(events.SystemLogMsg); !ok {
+		t.Error("expected the pause to be logged")
+	}
+}
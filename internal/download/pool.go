@@ -2,12 +2,16 @@ package download
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/url"
 	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/SurgeDM/Surge/internal/engine"
+	"github.com/SurgeDM/Surge/internal/engine/events"
 	"github.com/SurgeDM/Surge/internal/engine/types"
 	"github.com/SurgeDM/Surge/internal/utils"
 )
@@ -15,7 +19,7 @@ import (
 // activeDownload tracks a download that's currently running
 type activeDownload struct {
 	config types.DownloadConfig
-	cancel context.CancelFunc
+	cancel context.CancelCauseFunc
 	// running is true while the worker goroutine is executing TUIDownload for this config.
 	running atomic.Bool
 }
@@ -36,10 +40,22 @@ type WorkerPool struct {
 	mu           sync.RWMutex
 	wg           sync.WaitGroup // We use this to wait for all active downloads to pause before exiting the program
 	maxDownloads int
+	workers      int                      // worker goroutines started so far
+	limits       ConcurrencyLimits        // per-host and per-category caps
+	held         []string                 // downloads held back by a cap
+	requeue      []string                 // woken downloads waiting to go back on taskChan
+	waking       bool                     // requeueHeld is feeding requeue to taskChan
+	draining     bool                     // running downloads finish, queued ones wait
+	connections  *engine.ConnectionBudget // shared by the connections of running downloads
+	policy       atomic.Value             // string: the config.QueuePolicy* that picks the next download
+	queueSeq     map[string]uint64        // order downloads were queued in
+	nextSeq      uint64
 
 	globalLimiter               *engine.RateLimiter
+	fairShare                   *engine.FairLimiter // splits globalLimiter evenly between downloads
 	downloadLimiters            map[string]*engine.RateLimiter
 	defaultDownloadRateLimitBps int64
+	traffic                     engine.TrafficMeter // bytes read by all downloads, for monthly_quota
 }
 
 var (
@@ -61,15 +77,20 @@ func NewWorkerPool(progressCh chan<- any, maxDownloads int) *WorkerPool {
 	if maxDownloads < 1 {
 		maxDownloads = 3 // Default to 3 if invalid
 	}
+	globalLimiter := engine.NewRateLimiter(0, 0)
 	pool := &WorkerPool{
 		taskChan:         make(chan string, 100), // We make it buffered to avoid blocking add
 		progressCh:       progressCh,
 		progressDone:     make(chan struct{}),
 		downloads:        make(map[string]*activeDownload),
 		queued:           make(map[string]types.DownloadConfig),
+		queueSeq:         make(map[string]uint64),
 		maxDownloads:     maxDownloads,
-		globalLimiter:    engine.NewRateLimiter(0, 0),
+		workers:          maxDownloads,
+		globalLimiter:    globalLimiter,
+		fairShare:        engine.NewFairLimiter(globalLimiter),
 		downloadLimiters: make(map[string]*engine.RateLimiter),
+		connections:      engine.NewConnectionBudget(0),
 	}
 	for i := 0; i < maxDownloads; i++ {
 		go pool.worker()
@@ -124,6 +145,11 @@ func (p *WorkerPool) Add(cfg types.DownloadConfig) {
 	p.mu.Lock()
 	p.ensureLimiterForConfigLocked(&cfg)
 	p.queued[cfg.ID] = cfg
+	if p.queueSeq == nil {
+		p.queueSeq = make(map[string]uint64)
+	}
+	p.nextSeq++
+	p.queueSeq[cfg.ID] = p.nextSeq
 	p.wg.Add(1)
 	p.mu.Unlock()
 
@@ -135,9 +161,7 @@ func (p *WorkerPool) ensureLimiterForConfigLocked(cfg *types.DownloadConfig) {
 		return
 	}
 
-	if p.globalLimiter == nil {
-		p.globalLimiter = engine.NewRateLimiter(0, 0)
-	}
+	p.ensureGlobalLimiterLocked()
 	if p.downloadLimiters == nil {
 		p.downloadLimiters = make(map[string]*engine.RateLimiter)
 	}
@@ -168,7 +192,27 @@ func (p *WorkerPool) ensureLimiterForConfigLocked(cfg *types.DownloadConfig) {
 	}
 
 	if cfg.Limiter == nil {
-		cfg.Limiter = engine.NewMultiLimiter(p.globalLimiter, limiter)
+		// The download's own cap comes first so a capped download never holds
+		// the global queue while it waits on itself.
+		cfg.Limiter = engine.NewMultiLimiter(limiter, p.fairShare.NewWeightedFlow(p.flowWeight(cfg)), &p.traffic)
+	}
+}
+
+// TakeTraffic returns the bytes the pool's downloads have read since the
+// last call.
+func (p *WorkerPool) TakeTraffic() int64 {
+	return p.traffic.Take()
+}
+
+// ensureGlobalLimiterLocked creates the global limiter and its fair-share
+// queue if the pool was built without them. Callers must hold p.mu.
+func (p *WorkerPool) ensureGlobalLimiterLocked() {
+	if p.globalLimiter == nil {
+		p.globalLimiter = engine.NewRateLimiter(0, 0)
+		p.fairShare = nil
+	}
+	if p.fairShare == nil {
+		p.fairShare = engine.NewFairLimiter(p.globalLimiter)
 	}
 }
 
@@ -238,12 +282,18 @@ func (p *WorkerPool) GetAll() []types.DownloadConfig {
 // Pause pauses a specific download by ID. Returns true if found and pause initiated
 // (or already paused), false otherwise. Pure mechanical operation - no events emitted.
 func (p *WorkerPool) Pause(downloadID string) bool {
+	return p.pause(downloadID, types.ErrUserPause)
+}
+
+// pause stops a download with cause, which IsPauseCause accepts, so its
+// progress is saved for a resume.
+func (p *WorkerPool) pause(downloadID string, cause error) bool {
 	p.mu.RLock()
 	ad, exists := p.downloads[downloadID]
 	p.mu.RUnlock()
 
 	if !exists || ad == nil {
-		return false
+		return p.pauseQueued(downloadID)
 	}
 
 	// Set paused flag and cancel context
@@ -255,16 +305,16 @@ func (p *WorkerPool) Pause(downloadID string) bool {
 		// If transition is already in progress, still ensure worker context is canceled.
 		if ad.config.State.IsPausing() {
 			if ad.cancel != nil {
-				ad.cancel()
+				ad.cancel(cause)
 			}
 			return true
 		}
 		ad.config.State.SetPausing(true) // Mark as transitioning to pause
-		ad.config.State.Pause()
+		ad.config.State.PauseFor(cause)
 	}
 	// Always cancel worker context as a safety net (single downloader does not set state cancel itself).
 	if ad.cancel != nil {
-		ad.cancel()
+		ad.cancel(cause)
 	}
 
 	// Send pause message is now exclusively handled by worker return paths
@@ -272,15 +322,42 @@ func (p *WorkerPool) Pause(downloadID string) bool {
 	return true
 }
 
+// pauseQueued takes a download that has not started yet off the queue and
+// keeps it as paused, so it waits for a resume like one stopped
+// mid-transfer and ExtractPausedConfig hands it back unchanged.
+func (p *WorkerPool) pauseQueued(downloadID string) bool {
+	p.mu.Lock()
+	cfg, ok := p.queued[downloadID]
+	if !ok || cfg.State == nil {
+		p.mu.Unlock()
+		return false
+	}
+	delete(p.queued, downloadID)
+	delete(p.queueSeq, downloadID)
+	cfg.State.PauseFor(types.ErrUserPause)
+	p.downloads[downloadID] = &activeDownload{config: cfg}
+	// A worker that takes its id off taskChan now finds it gone and releases
+	// its slot in wg; one held back by a cap is handed over.
+	p.wakeHeldLocked()
+	p.mu.Unlock()
+
+	safeSendProgress(cfg.ProgressCh, events.DownloadPausedMsg{
+		DownloadID:   downloadID,
+		Filename:     cfg.Filename,
+		RateLimit:    cfg.RateLimitBps,
+		RateLimitSet: cfg.RateLimitSet,
+	})
+	return true
+}
+
 // SetGlobalRateLimit updates the global rate limiter (bytes/sec). Use 0 to disable.
 func (p *WorkerPool) SetGlobalRateLimit(rate int64) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	if p.globalLimiter == nil {
-		p.globalLimiter = engine.NewRateLimiter(0, 0)
-	}
-	// All per-download MultiLimiters hold a pointer to this globalLimiter,
-	// so updating the rate here propagates to all active downloads instantly.
+	p.ensureGlobalLimiterLocked()
+	// All per-download MultiLimiters draw from this globalLimiter through the
+	// fair-share queue, so updating the rate here propagates to all active
+	// downloads instantly.
 	p.globalLimiter.SetRate(rate, rateLimiterBurst(rate))
 }
 
@@ -421,8 +498,12 @@ func (p *WorkerPool) ClearDownloadRateLimit(downloadID string) bool {
 	return true
 }
 
-// PauseAll pauses all active downloads (for graceful shutdown)
+// PauseAll pauses all active downloads
 func (p *WorkerPool) PauseAll() {
+	p.pauseAll(types.ErrUserPause)
+}
+
+func (p *WorkerPool) pauseAll(cause error) {
 	p.mu.RLock()
 	ids := make([]string, 0, len(p.downloads)) // This stores the uuids of the downloads to be paused
 	for id, ad := range p.downloads {
@@ -434,7 +515,33 @@ func (p *WorkerPool) PauseAll() {
 	p.mu.RUnlock()
 
 	for _, id := range ids {
-		p.Pause(id)
+		p.pause(id, cause)
+	}
+}
+
+// pauseHost pauses every other running download from the host of cause,
+// whose session has likely expired, and tells the user to refresh their
+// headers. It pauses them with cause, so none fails trying the same
+// credentials.
+func (p *WorkerPool) pauseHost(cause *types.AuthExpiredError, progressCh chan<- any) {
+	p.mu.RLock()
+	var ids []string
+	for id, ad := range p.downloads {
+		if ad == nil || ad.config.State == nil || ad.config.State.Done.Load() || ad.config.State.IsPaused() {
+			continue
+		}
+		if u, err := url.Parse(ad.config.URL); err == nil && u.Host == cause.Host {
+			ids = append(ids, id)
+		}
+	}
+	p.mu.RUnlock()
+
+	for _, id := range ids {
+		p.pause(id, cause)
+	}
+	if progressCh != nil {
+		safeSendProgress(progressCh, events.SystemLogMsg{Message: fmt.Sprintf(
+			"%v; paused %d download(s) from it. Update their headers with surge refresh --header and resume them.", cause, len(ids)+1)})
 	}
 }
 
@@ -450,6 +557,7 @@ func (p *WorkerPool) Cancel(downloadID string) types.CancelResult {
 	}
 	if queuedExists {
 		delete(p.queued, downloadID)
+		delete(p.queueSeq, downloadID)
 	}
 	if activeExists || queuedExists {
 		delete(p.downloadLimiters, downloadID)
@@ -467,9 +575,10 @@ func (p *WorkerPool) Cancel(downloadID string) types.CancelResult {
 		result.DestPath = resolveDestPath(&ad.config)
 		result.Completed = ad.config.State != nil && ad.config.State.Done.Load()
 
-		// Cancel the context to stop workers
+		// Cancel the context to stop workers. The cause tells a download that
+		// was still pausing not to save its progress.
 		if ad.cancel != nil {
-			ad.cancel()
+			ad.cancel(types.ErrUserDelete)
 		}
 
 		// Best effort: wait for worker to exit so delete cleanup doesn't race with
@@ -553,19 +662,48 @@ func (p *WorkerPool) UpdateURL(downloadID string, newURL string) error {
 	return nil
 }
 
+// UpdateHeaders sets headers over the request headers of a paused or queued
+// download, removing those given an empty value. A download the pool does not
+// hold is left to the caller.
+func (p *WorkerPool) UpdateHeaders(downloadID string, headers map[string]string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if cfg, ok := p.queued[downloadID]; ok {
+		cfg.Headers = utils.MergeHeaders(cfg.Headers, headers)
+		p.queued[downloadID] = cfg
+		return nil
+	}
+	ad, exists := p.downloads[downloadID]
+	if !exists || ad == nil {
+		return nil
+	}
+	if ad.running.Load() && (ad.config.State == nil || !ad.config.State.IsPaused()) {
+		return types.ErrActiveHeaderUpdate
+	}
+	ad.config.Headers = utils.MergeHeaders(ad.config.Headers, headers)
+	return nil
+}
+
 func (p *WorkerPool) worker() {
-	for id := range p.taskChan {
-		p.mu.RLock()
-		cfg, stillQueued := p.queued[id]
-		p.mu.RUnlock()
-		if !stillQueued {
+	for token := range p.taskChan {
+		p.mu.Lock()
+		cfg, next := p.nextLocked(token)
+		switch next {
+		case pickDropped:
 			// Canceled while waiting in queue.
+			p.mu.Unlock()
 			p.wg.Done()
 			continue
+		case pickHeld:
+			// Over a concurrency cap; stays queued until a slot frees up.
+			p.holdLocked(token)
+			p.mu.Unlock()
+			continue
 		}
 
 		// Create cancellable context
-		ctx, cancel := context.WithCancel(context.Background())
+		ctx, cancel := context.WithCancelCause(context.Background())
 
 		// Ensure Runtime is initialized before exposing to GetAll
 		if cfg.Runtime == nil {
@@ -581,32 +719,36 @@ func (p *WorkerPool) worker() {
 			ad.config.State.SetCancelFunc(cancel)
 		}
 		ad.running.Store(true)
-
-		p.mu.Lock()
-		cfg, stillQueued = p.queued[id]
-		if !stillQueued {
-			p.mu.Unlock()
-			cancel()
-			p.wg.Done()
-			continue
-		}
-		ad.config = cfg // Ensure ad.config has the latest state from queue
 		delete(p.queued, cfg.ID)
+		delete(p.queueSeq, cfg.ID)
 		p.downloads[cfg.ID] = ad
 
 		// Make a local copy for TUIDownload to mutate safely
 		localCfg := ad.config
+		// What the probe left applies to the first start only
+		ad.config.Probe = types.ProbeHandoff{}
+		var share *engine.ConnectionShare
+		if p.connections != nil {
+			share = p.connections.Join()
+			localCfg.Budget = share
+		}
 		p.mu.Unlock()
 
 		err := TUIDownload(ctx, &localCfg)
 		ad.running.Store(false)
+		if share != nil {
+			share.Leave()
+		}
 
 		// Sync back mutated fields cleanly under lock
 		p.mu.Lock()
 		if _, exists := p.downloads[localCfg.ID]; exists {
 			ad.config.TotalSize = localCfg.TotalSize
 			ad.config.Runtime = localCfg.Runtime
+			ad.config.Request = localCfg.Request
+			ad.config.S3 = localCfg.S3
 		}
+		p.wakeHeldLocked()
 		p.mu.Unlock()
 
 		// Logic:
@@ -621,9 +763,14 @@ func (p *WorkerPool) worker() {
 		}
 
 		if isPaused {
-			utils.Debug("WorkerPool: Download %s paused cleanly", localCfg.ID)
+			utils.DebugFor(localCfg.ID, "WorkerPool: Download %s paused cleanly", localCfg.ID)
 			// If paused, we keep it in downloads map for potential resume via ExtractPausedConfig
+			var authErr *types.AuthExpiredError
+			if errors.As(context.Cause(ctx), &authErr) {
+				p.pauseHost(authErr, localCfg.ProgressCh)
+			}
 		} else if err != nil {
+			utils.DebugFor(localCfg.ID, "WorkerPool: Download %s failed: %v", localCfg.ID, err)
 			if localCfg.State != nil {
 				localCfg.State.SetError(err)
 			}
@@ -707,6 +854,7 @@ func (p *WorkerPool) GetStatus(id string) *types.DownloadStatus {
 	status := &types.DownloadStatus{
 		ID:           id,
 		URL:          adURL,
+		FinalURL:     state.GetFinalURL(),
 		Filename:     filename,
 		TotalSize:    totalSize,
 		Downloaded:   downloaded,
@@ -726,24 +874,32 @@ func (p *WorkerPool) GetStatus(id string) *types.DownloadStatus {
 		status.Status = "paused"
 	} else if state.Done.Load() {
 		status.Status = "completed"
+	} else {
+		status.Phase = state.GetPhase()
 	}
 
 	if err := state.GetError(); err != nil {
 		status.Status = "error"
+		status.Phase = ""
 		status.Error = err.Error()
+		status.ErrorCode = types.ClassifyError(err)
 	}
 
-	// Calculate progress
+	// Calculate progress; while verifying it is how much of the file was checked
 	if status.TotalSize > 0 {
 		status.Progress = float64(status.Downloaded) * 100 / float64(status.TotalSize)
+		if status.Phase == types.PhaseVerifying {
+			status.Progress = float64(state.Checked.Load()) * 100 / float64(status.TotalSize)
+		}
 	}
 
-	// Calculate speed (MB/s) only for active downloads.
-	if status.Status == "downloading" {
+	// Calculate speed (MB/s) only for downloads still transferring.
+	if status.Status == "downloading" && status.Phase != types.PhaseVerifying && status.Phase != types.PhasePostProcessing {
 		sessionDownloaded := downloaded - sessionStart
 		if sessionElapsed.Seconds() > 0 && sessionDownloaded > 0 {
 			bytesPerSec := float64(sessionDownloaded) / sessionElapsed.Seconds()
 			status.Speed = bytesPerSec / float64(types.MB)
+			status.SetETA(time.Now())
 		}
 	}
 
@@ -752,7 +908,7 @@ func (p *WorkerPool) GetStatus(id string) *types.DownloadStatus {
 
 // GracefulShutdown pauses all downloads and waits for them to save state
 func (p *WorkerPool) GracefulShutdown() {
-	p.PauseAll()
+	p.pauseAll(types.ErrShutdown)
 
 	// Discard all queued-but-not-yet-started downloads so that idle workers
 	// do not pick them up and begin downloading after shutdown is initiated.
@@ -761,7 +917,9 @@ func (p *WorkerPool) GracefulShutdown() {
 	p.mu.Lock()
 	for id := range p.queued {
 		delete(p.queued, id)
+		delete(p.queueSeq, id)
 	}
+	p.wakeHeldLocked() // held downloads are discarded when they come back
 	p.mu.Unlock()
 
 	// Drain taskChan to discard any configs that were already written into the
@@ -0,0 +1,192 @@
+package download
+
+import (
+	"cmp"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// ConcurrencyLimits layers caps on how many downloads the pool runs at once.
+// A download starts only when every layer has room for it.
+type ConcurrencyLimits struct {
+	// Global caps all running downloads. Zero keeps the pool's current cap.
+	Global int
+	// PerHost caps running downloads from one host. Zero is unlimited.
+	PerHost int
+	// PerCategory caps running downloads in one category. Zero is unlimited.
+	PerCategory int
+	// Connections caps the connections of all running downloads together,
+	// shared evenly between them. Zero is unlimited.
+	Connections int
+	// Categories sorts downloads into categories by file name. Downloads that
+	// match no category are only held to the global and host caps.
+	Categories []config.Category
+}
+
+// LimitsFromSettings reads the concurrency caps from settings. Category caps
+// apply only while category routing is enabled.
+func LimitsFromSettings(s *config.Settings) ConcurrencyLimits {
+	limits := ConcurrencyLimits{
+		Global:      config.Resolve[int](s.Network.MaxConcurrentDownloads),
+		PerHost:     config.Resolve[int](s.Network.MaxDownloadsPerHost),
+		PerCategory: config.Resolve[int](s.Network.MaxDownloadsPerCategory),
+		Connections: config.Resolve[int](s.Network.MaxTotalConnections),
+	}
+	if config.Resolve[bool](s.Categories.CategoryEnabled) {
+		limits.Categories = s.Categories.Categories
+	}
+	return limits
+}
+
+// SetConcurrencyLimits replaces the pool's caps. Running downloads are never
+// stopped: a lower cap holds back new downloads until enough have finished,
+// and a higher global cap starts more workers.
+func (p *WorkerPool) SetConcurrencyLimits(limits ConcurrencyLimits) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if limits.Global > 0 {
+		p.maxDownloads = limits.Global
+	}
+	for p.workers < p.maxDownloads {
+		p.workers++
+		go p.worker()
+	}
+	if p.connections == nil {
+		p.connections = engine.NewConnectionBudget(0)
+	}
+	p.connections.SetTotal(limits.Connections)
+	p.limits = limits
+	p.wakeHeldLocked()
+	utils.Debug("WorkerPool: limits now global=%d host=%d category=%d connections=%d", p.maxDownloads, limits.PerHost, limits.PerCategory, limits.Connections)
+}
+
+// SetDraining turns drain mode on or off. While draining, running downloads
+// finish but queued ones are held back as if over a cap, until drain mode is
+// turned off again.
+func (p *WorkerPool) SetDraining(on bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.draining = on
+	if !on {
+		p.wakeHeldLocked()
+	}
+	utils.Debug("WorkerPool: draining=%v", on)
+}
+
+// Draining reports whether drain mode is on.
+func (p *WorkerPool) Draining() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.draining
+}
+
+// admitLocked reports whether cfg can start without going over any cap.
+// Callers must hold p.mu.
+func (p *WorkerPool) admitLocked(cfg *types.DownloadConfig) bool {
+	if p.draining {
+		return false
+	}
+	host := downloadHost(cfg.URL)
+	category := p.categoryOf(cfg.Filename)
+
+	running, sameHost, sameCategory := 0, 0, 0
+	for _, ad := range p.downloads {
+		if !ad.running.Load() {
+			continue
+		}
+		running++
+		if host != "" && downloadHost(ad.config.URL) == host {
+			sameHost++
+		}
+		if category != "" && p.categoryOf(ad.config.Filename) == category {
+			sameCategory++
+		}
+	}
+
+	switch {
+	case p.maxDownloads > 0 && running >= p.maxDownloads:
+		return false
+	// Every running download needs at least one connection of the budget
+	case p.limits.Connections > 0 && running >= p.limits.Connections:
+		return false
+	case p.limits.PerHost > 0 && host != "" && sameHost >= p.limits.PerHost:
+		return false
+	case p.limits.PerCategory > 0 && category != "" && sameCategory >= p.limits.PerCategory:
+		return false
+	}
+	return true
+}
+
+// categoryOf returns the name of the category filename belongs to, or empty
+// when category caps are off or nothing matches.
+func (p *WorkerPool) categoryOf(filename string) string {
+	if p.limits.PerCategory <= 0 || len(p.limits.Categories) == 0 || filename == "" {
+		return ""
+	}
+	cat, err := config.GetCategoryForFile(filename, p.limits.Categories)
+	if err != nil || cat == nil {
+		return ""
+	}
+	return cat.Name
+}
+
+// downloadHost returns the lower-cased host of rawurl, or empty if it has none.
+func downloadHost(rawurl string) string {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// holdLocked parks a download that is over a cap. It goes back on the task
+// queue once a running download finishes or the caps change, so downloads
+// behind it that fit can start in the meantime. Callers must hold p.mu.
+func (p *WorkerPool) holdLocked(id string) {
+	p.held = append(p.held, id)
+}
+
+// wakeHeldLocked requeues every held download so each is checked against the
+// caps again. They go back in the order they were first queued, one at a time
+// from a single waker, so a hold never reorders a fifo queue. Callers must
+// hold p.mu.
+func (p *WorkerPool) wakeHeldLocked() {
+	if len(p.held) == 0 {
+		return
+	}
+	held := p.held
+	p.held = nil
+	slices.SortStableFunc(held, func(a, b string) int {
+		return cmp.Compare(p.queueSeq[a], p.queueSeq[b])
+	})
+	p.requeue = append(p.requeue, held...)
+	if !p.waking {
+		p.waking = true
+		go p.requeueHeld()
+	}
+}
+
+// requeueHeld feeds woken downloads back to the task queue in order. It sends
+// without p.mu held, since workers take p.mu after receiving.
+func (p *WorkerPool) requeueHeld() {
+	for {
+		p.mu.Lock()
+		if len(p.requeue) == 0 {
+			p.waking = false
+			p.mu.Unlock()
+			return
+		}
+		id := p.requeue[0]
+		p.requeue = p.requeue[1:]
+		p.mu.Unlock()
+		p.taskChan <- id
+	}
+}
@@ -0,0 +1,214 @@
+package download
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func addRunning(pool *WorkerPool, id, url, filename string) {
+	ad := &activeDownload{config: types.DownloadConfig{ID: id, URL: url, Filename: filename}}
+	ad.running.Store(true)
+	pool.downloads[id] = ad
+}
+
+func TestWorkerPool_AdmitLocked_PerHost(t *testing.T) {
+	pool := NewWorkerPool(make(chan any, 10), 5)
+	pool.SetConcurrencyLimits(ConcurrencyLimits{PerHost: 2})
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	addRunning(pool, "a1", "https://a.example.com/1.iso", "1.iso")
+	addRunning(pool, "a2", "https://A.example.com/2.iso", "2.iso")
+
+	if pool.admitLocked(&types.DownloadConfig{URL: "https://a.example.com/3.iso"}) {
+		t.Error("third download from the same host should be held back")
+	}
+	if !pool.admitLocked(&types.DownloadConfig{URL: "https://b.example.com/3.iso"}) {
+		t.Error("download from another host should start")
+	}
+}
+
+func TestWorkerPool_AdmitLocked_PerCategory(t *testing.T) {
+	pool := NewWorkerPool(make(chan any, 10), 5)
+	pool.SetConcurrencyLimits(ConcurrencyLimits{
+		PerCategory: 1,
+		Categories: []config.Category{
+			{Name: "Videos", Pattern: `(?i)\.mp4$`},
+			{Name: "Archives", Pattern: `(?i)\.zip$`},
+		},
+	})
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	addRunning(pool, "v1", "https://a.example.com/a.mp4", "a.mp4")
+
+	if pool.admitLocked(&types.DownloadConfig{URL: "https://b.example.com/b.mp4", Filename: "b.mp4"}) {
+		t.Error("second video should be held back")
+	}
+	if !pool.admitLocked(&types.DownloadConfig{URL: "https://b.example.com/b.zip", Filename: "b.zip"}) {
+		t.Error("archive should start")
+	}
+	if !pool.admitLocked(&types.DownloadConfig{URL: "https://b.example.com/b.txt", Filename: "b.txt"}) {
+		t.Error("uncategorized download should start")
+	}
+}
+
+func TestWorkerPool_AdmitLocked_Global(t *testing.T) {
+	pool := NewWorkerPool(make(chan any, 10), 1)
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	addRunning(pool, "a1", "https://a.example.com/1.iso", "1.iso")
+	pool.downloads["paused"] = &activeDownload{config: types.DownloadConfig{ID: "paused"}}
+
+	if pool.admitLocked(&types.DownloadConfig{URL: "https://b.example.com/2.iso"}) {
+		t.Error("download over the global cap should be held back")
+	}
+}
+
+func TestWorkerPool_AdmitLocked_Connections(t *testing.T) {
+	pool := NewWorkerPool(make(chan any, 10), 5)
+	pool.SetConcurrencyLimits(ConcurrencyLimits{Connections: 2})
+	if total := pool.connections.Total(); total != 2 {
+		t.Fatalf("connection budget = %d, want 2", total)
+	}
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	addRunning(pool, "a1", "https://a.example.com/1.iso", "1.iso")
+	if !pool.admitLocked(&types.DownloadConfig{URL: "https://b.example.com/2.iso"}) {
+		t.Error("second download should start with a connection to spare")
+	}
+	addRunning(pool, "a2", "https://b.example.com/2.iso", "2.iso")
+	if pool.admitLocked(&types.DownloadConfig{URL: "https://c.example.com/3.iso"}) {
+		t.Error("download with no connection left for it should be held back")
+	}
+}
+
+func TestWorkerPool_SetConcurrencyLimits_RaisesGlobal(t *testing.T) {
+	pool := NewWorkerPool(make(chan any, 10), 2)
+
+	pool.SetConcurrencyLimits(ConcurrencyLimits{Global: 4})
+	pool.mu.RLock()
+	maxDownloads, workers := pool.maxDownloads, pool.workers
+	pool.mu.RUnlock()
+	if maxDownloads != 4 || workers != 4 {
+		t.Errorf("maxDownloads = %d, workers = %d, want 4 and 4", maxDownloads, workers)
+	}
+
+	// Lowering the cap keeps the spare workers; admission holds them back.
+	pool.SetConcurrencyLimits(ConcurrencyLimits{Global: 1})
+	pool.mu.RLock()
+	maxDownloads, workers = pool.maxDownloads, pool.workers
+	pool.mu.RUnlock()
+	if maxDownloads != 1 || workers != 4 {
+		t.Errorf("after lowering: maxDownloads = %d, workers = %d, want 1 and 4", maxDownloads, workers)
+	}
+}
+
+func TestWorkerPool_HeldDownloadRequeuedOnWake(t *testing.T) {
+	pool := &WorkerPool{taskChan: make(chan string, 1)}
+
+	pool.mu.Lock()
+	pool.holdLocked("held")
+	pool.mu.Unlock()
+
+	select {
+	case id := <-pool.taskChan:
+		t.Fatalf("%s requeued before wake", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	pool.mu.Lock()
+	pool.wakeHeldLocked()
+	pool.mu.Unlock()
+
+	select {
+	case id := <-pool.taskChan:
+		if id != "held" {
+			t.Errorf("requeued %q, want held", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("held download was not requeued")
+	}
+}
+
+func TestWorkerPool_HeldDownloadsRequeuedInQueueOrder(t *testing.T) {
+	pool := &WorkerPool{
+		taskChan: make(chan string),
+		queueSeq: map[string]uint64{"first": 1, "second": 2, "third": 3},
+	}
+
+	pool.mu.Lock()
+	for _, id := range []string{"third", "first", "second"} {
+		pool.holdLocked(id)
+	}
+	pool.wakeHeldLocked()
+	pool.mu.Unlock()
+
+	for _, want := range []string{"first", "second", "third"} {
+		select {
+		case id := <-pool.taskChan:
+			if id != want {
+				t.Fatalf("requeued %q, want %q", id, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s was not requeued", want)
+		}
+	}
+}
+
+func TestWorkerPool_SetDraining(t *testing.T) {
+	pool := &WorkerPool{taskChan: make(chan string, 1), maxDownloads: 3}
+
+	pool.SetDraining(true)
+	pool.mu.Lock()
+	if pool.admitLocked(&types.DownloadConfig{URL: "https://a.example.com/1.iso"}) {
+		t.Error("download should be held back while draining")
+	}
+	pool.holdLocked("held")
+	pool.mu.Unlock()
+	if !pool.Draining() {
+		t.Fatal("Draining() = false after SetDraining(true)")
+	}
+
+	pool.SetDraining(false)
+	select {
+	case id := <-pool.taskChan:
+		if id != "held" {
+			t.Errorf("requeued %q, want held", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("held download was not requeued when draining stopped")
+	}
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	if !pool.admitLocked(&types.DownloadConfig{URL: "https://a.example.com/1.iso"}) {
+		t.Error("download should start once draining stopped")
+	}
+}
+
+func TestLimitsFromSettings(t *testing.T) {
+	s := config.DefaultSettings()
+	s.Network.MaxConcurrentDownloads.Value = 4
+	s.Network.MaxDownloadsPerHost.Value = 2
+	s.Network.MaxDownloadsPerCategory.Value = 1
+	s.Network.MaxTotalConnections.Value = 12
+
+	s.Categories.CategoryEnabled.Value = false
+	limits := LimitsFromSettings(s)
+	if limits.Global != 4 || limits.PerHost != 2 || limits.PerCategory != 1 || limits.Connections != 12 {
+		t.Errorf("limits = %+v", limits)
+	}
+	if limits.Categories != nil {
+		t.Error("categories should be ignored while category routing is off")
+	}
+
+	s.Categories.CategoryEnabled.Value = true
+	if limits := LimitsFromSettings(s); len(limits.Categories) == 0 {
+		t.Error("categories should apply while category routing is on")
+	}
+}
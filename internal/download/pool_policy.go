@@ -0,0 +1,169 @@
+package download
+
+import (
+	"slices"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// sjfReferenceSize is the remaining size that gets the lowest bandwidth
+// weight under the shortest-job-first policy; a download with half as much
+// left gets twice the weight.
+const sjfReferenceSize = 64 << 30
+
+// pick is what a worker does with a token it took off taskChan.
+type pick int
+
+const (
+	pickStart   pick = iota // start the download returned with it
+	pickHeld                // park the token until a cap has room
+	pickDropped             // the download left the queue; release its slot
+)
+
+// SetQueuePolicy sets how the pool picks the next queued download to start:
+// config.QueuePolicyFIFO in the order they were queued,
+// config.QueuePolicyPriority with low-priority downloads after the rest, or
+// config.QueuePolicySJF with the least left to fetch first. Running
+// downloads are left alone.
+func (p *WorkerPool) SetQueuePolicy(policy string) {
+	switch policy {
+	case config.QueuePolicyPriority, config.QueuePolicySJF:
+	default:
+		policy = config.QueuePolicyFIFO
+	}
+	p.policy.Store(policy)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.wakeHeldLocked()
+	utils.Debug("WorkerPool: queue policy now %s", policy)
+}
+
+// QueuePolicy returns the policy set with SetQueuePolicy.
+func (p *WorkerPool) QueuePolicy() string {
+	if policy, _ := p.policy.Load().(string); policy != "" {
+		return policy
+	}
+	return config.QueuePolicyFIFO
+}
+
+// nextLocked decides what a worker holding token starts. Under FIFO a token
+// stands for its own download; under the other policies any token stands
+// for the best queued download that fits under the caps, and the download a
+// token was queued for is started by whichever token comes first. Either way
+// each token starts at most one download and is dropped once the queue runs
+// out, so every Add is matched by one release of wg. Callers must hold p.mu.
+func (p *WorkerPool) nextLocked(token string) (types.DownloadConfig, pick) {
+	if p.QueuePolicy() == config.QueuePolicyFIFO {
+		cfg, ok := p.queued[token]
+		switch {
+		case !ok:
+			return cfg, pickDropped
+		case !p.admitLocked(&cfg):
+			return cfg, pickHeld
+		}
+		return cfg, pickStart
+	}
+
+	if len(p.queued) == 0 {
+		return types.DownloadConfig{}, pickDropped
+	}
+	for _, id := range p.queueOrderLocked() {
+		cfg := p.queued[id]
+		if p.admitLocked(&cfg) {
+			return cfg, pickStart
+		}
+	}
+	return types.DownloadConfig{}, pickHeld
+}
+
+// queueOrderLocked returns the IDs of queued downloads in the order the
+// policy starts them. Callers must hold p.mu.
+func (p *WorkerPool) queueOrderLocked() []string {
+	type entry struct {
+		id        string
+		seq       uint64
+		low       bool
+		remaining int64
+	}
+	sjf := p.QueuePolicy() == config.QueuePolicySJF
+	entries := make([]entry, 0, len(p.queued))
+	for id, cfg := range p.queued {
+		e := entry{id: id, seq: p.queueSeq[id], low: cfg.Request.LowPriority}
+		if sjf {
+			e.remaining = remainingBytes(&cfg)
+		}
+		entries = append(entries, e)
+	}
+	slices.SortFunc(entries, func(a, b entry) int {
+		if a.low != b.low && !sjf {
+			if a.low {
+				return 1
+			}
+			return -1
+		}
+		if a.remaining != b.remaining {
+			// Downloads of unknown size go after every known one
+			switch {
+			case a.remaining < 0:
+				return 1
+			case b.remaining < 0:
+				return -1
+			case a.remaining < b.remaining:
+				return -1
+			default:
+				return 1
+			}
+		}
+		switch {
+		case a.seq < b.seq:
+			return -1
+		case a.seq > b.seq:
+			return 1
+		}
+		return 0
+	})
+	ids := make([]string, len(entries))
+	for i, e := range entries {
+		ids[i] = e.id
+	}
+	return ids
+}
+
+// remainingBytes returns how much of cfg is left to fetch, or -1 when its
+// size is not known yet.
+func remainingBytes(cfg *types.DownloadConfig) int64 {
+	total, done := cfg.TotalSize, int64(0)
+	if cfg.State != nil {
+		verified, stateTotal, _, _, _, _ := cfg.State.GetProgress()
+		if stateTotal > 0 {
+			total = stateTotal
+		}
+		done = max(verified, cfg.State.Downloaded.Load())
+	}
+	if total <= 0 {
+		return -1
+	}
+	return max(total-done, 0)
+}
+
+// flowWeight returns the weight cfg's share of the global rate limit is
+// drawn with. Downloads share it evenly, except under the shortest-job-first
+// policy, where the weight grows as what is left shrinks, so a nearly
+// finished download gets most of the bandwidth and is done sooner. It is
+// read before every request, so it only loads the policy and progress.
+func (p *WorkerPool) flowWeight(cfg *types.DownloadConfig) func() float64 {
+	state, totalSize := cfg.State, cfg.TotalSize
+	return func() float64 {
+		if p.QueuePolicy() != config.QueuePolicySJF {
+			return 1
+		}
+		remaining := remainingBytes(&types.DownloadConfig{State: state, TotalSize: totalSize})
+		if remaining < 0 {
+			return 1
+		}
+		return sjfReferenceSize / float64(max(remaining, 64<<10))
+	}
+}
@@ -0,0 +1,95 @@
+package download
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+// queueDirect puts cfg on the pool's queue without handing a token to the
+// workers, so tests can ask what a worker would pick.
+func queueDirect(pool *WorkerPool, cfg types.DownloadConfig) {
+	pool.queued[cfg.ID] = cfg
+	pool.nextSeq++
+	pool.queueSeq[cfg.ID] = pool.nextSeq
+}
+
+func sizedState(id string, total, downloaded int64) *types.ProgressState {
+	state := types.NewProgressState(id, total)
+	state.Downloaded.Store(downloaded)
+	return state
+}
+
+func TestWorkerPool_QueueOrder(t *testing.T) {
+	pool := NewWorkerPool(make(chan any, 10), 1)
+	queueDirect(pool, types.DownloadConfig{ID: "big", URL: "https://a.example.com/big", State: sizedState("big", 1<<30, 0), Request: types.RequestOptions{LowPriority: true}})
+	queueDirect(pool, types.DownloadConfig{ID: "unknown", URL: "https://a.example.com/unknown"})
+	queueDirect(pool, types.DownloadConfig{ID: "nearly", URL: "https://a.example.com/nearly", State: sizedState("nearly", 1<<30, 1<<30-1<<20)})
+	queueDirect(pool, types.DownloadConfig{ID: "small", URL: "https://a.example.com/small", TotalSize: 10 << 20})
+
+	for _, tc := range []struct {
+		policy string
+		want   []string
+	}{
+		{config.QueuePolicyPriority, []string{"unknown", "nearly", "small", "big"}},
+		{config.QueuePolicySJF, []string{"nearly", "small", "big", "unknown"}},
+	} {
+		pool.SetQueuePolicy(tc.policy)
+		pool.mu.Lock()
+		got := pool.queueOrderLocked()
+		pool.mu.Unlock()
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%s order = %v, want %v", tc.policy, got, tc.want)
+		}
+	}
+}
+
+func TestWorkerPool_NextLocked(t *testing.T) {
+	pool := NewWorkerPool(make(chan any, 10), 5)
+	pool.SetConcurrencyLimits(ConcurrencyLimits{PerHost: 1})
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	addRunning(pool, "running", "https://busy.example.com/1.iso", "1.iso")
+	queueDirect(pool, types.DownloadConfig{ID: "first", URL: "https://busy.example.com/2.iso", TotalSize: 1 << 20})
+	queueDirect(pool, types.DownloadConfig{ID: "second", URL: "https://idle.example.com/3.iso", TotalSize: 1 << 30})
+
+	// FIFO starts the download the token was queued for, or holds it
+	if _, next := pool.nextLocked("first"); next != pickHeld {
+		t.Errorf("fifo pick for a capped download = %v, want held", next)
+	}
+	if _, next := pool.nextLocked("gone"); next != pickDropped {
+		t.Errorf("fifo pick for a canceled download = %v, want dropped", next)
+	}
+
+	// SJF passes over the smallest while its host is at its cap, and any
+	// token will do
+	pool.policy.Store(config.QueuePolicySJF)
+	if cfg, next := pool.nextLocked("first"); next != pickStart || cfg.ID != "second" {
+		t.Errorf("sjf pick = %q, %v; want second started", cfg.ID, next)
+	}
+	delete(pool.queued, "second")
+	if _, next := pool.nextLocked("second"); next != pickHeld {
+		t.Errorf("sjf pick with only a capped download left = %v, want held", next)
+	}
+	delete(pool.queued, "first")
+	if _, next := pool.nextLocked("first"); next != pickDropped {
+		t.Errorf("sjf pick with an empty queue = %v, want dropped", next)
+	}
+}
+
+func TestWorkerPool_FlowWeight(t *testing.T) {
+	pool := NewWorkerPool(make(chan any, 10), 1)
+	nearly := pool.flowWeight(&types.DownloadConfig{State: sizedState("nearly", 1<<30, 1<<30-1<<20)})
+	fresh := pool.flowWeight(&types.DownloadConfig{State: sizedState("fresh", 1<<30, 0)})
+	if nearly() != 1 || fresh() != 1 {
+		t.Fatalf("fifo weights = %v, %v; want even shares", nearly(), fresh())
+	}
+
+	pool.SetQueuePolicy(config.QueuePolicySJF)
+	if nearly() <= fresh() {
+		t.Errorf("sjf weights = %v for 1 MiB left, %v for 1 GiB left; want the nearly finished one heavier", nearly(), fresh())
+	}
+}
@@ -112,3 +112,35 @@ func TestWorkerPool_GetStatus_Completed(t *testing.T) {
 		t.Errorf("Expected status 'completed', got '%s'", status.Status)
 	}
 }
+
+func TestWorkerPool_GetStatus_Verifying(t *testing.T) {
+	ch := make(chan any, 10)
+	pool := NewWorkerPool(ch, 3)
+
+	id := "test-id"
+	state := types.NewProgressState(id, 1000)
+	state.Downloaded.Store(1000)
+	state.VerifiedProgress.Store(1000)
+	state.SetPhase(types.PhaseVerifying)
+	state.Checked.Store(250)
+
+	pool.mu.Lock()
+	pool.downloads[id] = &activeDownload{
+		config: types.DownloadConfig{ID: id, URL: "http://example.com/file", State: state},
+	}
+	pool.mu.Unlock()
+
+	status := pool.GetStatus(id)
+	if status == nil {
+		t.Fatal("Expected status to be returned")
+	}
+	if status.Status != "downloading" || status.Phase != types.PhaseVerifying {
+		t.Errorf("Expected downloading in phase 'verifying', got '%s' in '%s'", status.Status, status.Phase)
+	}
+	if status.Progress != 25.0 {
+		t.Errorf("Expected Progress 25.0 (bytes checked), got %.1f", status.Progress)
+	}
+	if status.Speed != 0 {
+		t.Errorf("Expected no speed while verifying, got %f", status.Speed)
+	}
+}
@@ -2,6 +2,7 @@ package download
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/SurgeDM/Surge/internal/engine"
+	"github.com/SurgeDM/Surge/internal/engine/events"
 	"github.com/SurgeDM/Surge/internal/engine/types"
 )
 
@@ -154,7 +156,7 @@ func TestWorkerPool_Pause_NilState(t *testing.T) {
 			ID:    "test-id",
 			State: nil,
 		},
-		cancel: func() {
+		cancel: func(error) {
 			select {
 			case canceled <- struct{}{}:
 			default:
@@ -261,6 +263,67 @@ func TestWorkerPool_PauseAll_SkipsCompletedDownloads(t *testing.T) {
 	pool.PauseAll()
 }
 
+func TestWorkerPool_PauseHost(t *testing.T) {
+	ch := make(chan any, 10)
+	pool := NewWorkerPool(ch, 3)
+
+	states := map[string]*types.ProgressState{}
+	pool.mu.Lock()
+	for id, rawurl := range map[string]string{
+		"same":  "https://files.example.com/a.iso",
+		"other": "https://mirror.example.com/a.iso",
+	} {
+		states[id] = types.NewProgressState(id, 1000)
+		pool.downloads[id] = &activeDownload{config: types.DownloadConfig{ID: id, URL: rawurl, State: states[id]}}
+	}
+	pool.mu.Unlock()
+
+	cause := &types.AuthExpiredError{Host: "files.example.com", Status: 403}
+	pool.pauseHost(cause, ch)
+
+	if !states["same"].IsPaused() {
+		t.Error("download from the same host was not paused")
+	}
+	if states["other"].IsPaused() {
+		t.Error("download from another host was paused")
+	}
+	select {
+	case msg := <-ch:
+		if _, ok := msg.(events.SystemLogMsg); !ok {
+			t.Errorf("got %T, want a system message", msg)
+		}
+	default:
+		t.Error("no system message explaining the pause")
+	}
+}
+
+func TestWorkerPool_UpdateHeaders(t *testing.T) {
+	pool := NewWorkerPool(make(chan any, 10), 3)
+	state := types.NewProgressState("paused", 1000)
+	state.Paused.Store(true)
+	running := &activeDownload{config: types.DownloadConfig{ID: "running", State: types.NewProgressState("running", 1000)}}
+	running.running.Store(true)
+
+	pool.mu.Lock()
+	pool.downloads["paused"] = &activeDownload{config: types.DownloadConfig{
+		ID:      "paused",
+		State:   state,
+		Headers: map[string]string{"Cookie": "old", "Referer": "https://example.com"},
+	}}
+	pool.downloads["running"] = running
+	pool.mu.Unlock()
+
+	if err := pool.UpdateHeaders("paused", map[string]string{"cookie": "new", "Referer": ""}); err != nil {
+		t.Fatalf("UpdateHeaders: %v", err)
+	}
+	if got := pool.downloads["paused"].config.Headers; len(got) != 1 || got["Cookie"] != "new" {
+		t.Errorf("headers = %v, want only Cookie: new", got)
+	}
+	if err := pool.UpdateHeaders("running", map[string]string{"Cookie": "new"}); !errors.Is(err, types.ErrActiveHeaderUpdate) {
+		t.Errorf("UpdateHeaders on a running download = %v, want ErrActiveHeaderUpdate", err)
+	}
+}
+
 func TestWorkerPool_Cancel_NonExistentDownload(t *testing.T) {
 	ch := make(chan any, 10)
 	pool := NewWorkerPool(ch, 3)
@@ -323,7 +386,7 @@ func TestWorkerPool_Cancel_CallsCancelFunc(t *testing.T) {
 	ch := make(chan any, 10)
 	pool := NewWorkerPool(ch, 3)
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancelCause(context.Background())
 	state := types.NewProgressState("test-id", 1000)
 
 	pool.mu.Lock()
@@ -356,6 +419,9 @@ func TestWorkerPool_Cancel_CallsCancelFunc(t *testing.T) {
 	default:
 		t.Error("Expected context to be canceled")
 	}
+	if cause := context.Cause(ctx); !errors.Is(cause, types.ErrUserDelete) {
+		t.Errorf("cause = %v, want ErrUserDelete", cause)
+	}
 }
 
 func TestWorkerPool_Cancel_MarksDone(t *testing.T) {
@@ -457,6 +523,42 @@ func TestWorkerPool_Cancel_QueuedDownload_RemovesFromQueueAndReturnsResult(t *te
 	}
 }
 
+func TestWorkerPool_Pause_QueuedDownload_KeepsItPaused(t *testing.T) {
+	ch := make(chan any, 10)
+	pool := &WorkerPool{
+		progressCh: ch,
+		downloads:  make(map[string]*activeDownload),
+		queued: map[string]types.DownloadConfig{
+			"queued-id": {
+				ID:         "queued-id",
+				Filename:   "queued.bin",
+				State:      types.NewProgressState("queued-id", 0),
+				ProgressCh: ch,
+			},
+		},
+	}
+
+	if !pool.Pause("queued-id") {
+		t.Fatal("Pause of a queued download returned false")
+	}
+	if _, queued := pool.queued["queued-id"]; queued {
+		t.Fatal("paused download is still queued and would start")
+	}
+	select {
+	case msg := <-ch:
+		if paused, ok := msg.(events.DownloadPausedMsg); !ok || paused.DownloadID != "queued-id" {
+			t.Fatalf("got %#v, want DownloadPausedMsg for queued-id", msg)
+		}
+	default:
+		t.Fatal("no DownloadPausedMsg for the paused queued download")
+	}
+
+	cfg := pool.ExtractPausedConfig("queued-id")
+	if cfg == nil || cfg.Filename != "queued.bin" {
+		t.Fatalf("ExtractPausedConfig = %#v, want the queued config back", cfg)
+	}
+}
+
 // Resume orchestration (hot/cold path, DB hydration, event emission) was promoted to
 // LifecycleManager so the pool remains a pure executor with no knowledge of persistence
 // or events. Tests for pool-level extraction live below; LifecycleManager integration
@@ -466,6 +568,7 @@ func TestWorkerPool_GracefulShutdown_PausesAll(t *testing.T) {
 	ch := make(chan any, 10)
 	pool := NewWorkerPool(ch, 3)
 
+	ctx, cancel := context.WithCancelCause(context.Background())
 	state := types.NewProgressState("test-id", 1000)
 
 	pool.mu.Lock()
@@ -474,6 +577,7 @@ func TestWorkerPool_GracefulShutdown_PausesAll(t *testing.T) {
 			ID:    "test-id",
 			State: state,
 		},
+		cancel: cancel,
 	}
 	pool.mu.Unlock()
 
@@ -508,6 +612,10 @@ func TestWorkerPool_GracefulShutdown_PausesAll(t *testing.T) {
 	if !state.IsPaused() {
 		t.Error("Expected state to be paused after GracefulShutdown")
 	}
+	// A shutdown pause is told apart from one the user asked for
+	if cause := context.Cause(ctx); !errors.Is(cause, types.ErrShutdown) {
+		t.Errorf("cause = %v, want ErrShutdown", cause)
+	}
 }
 
 func TestWorkerPool_GracefulShutdown_WaitsPastSoftTimeout(t *testing.T) {
@@ -0,0 +1,17 @@
+package download
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// pauseForQuota pauses a download before it fetches anything because the
+// month's traffic has used up monthly_quota. A resume downloads it anyway.
+func pauseForQuota(cfg *types.DownloadConfig, destPath string, total int64) {
+	pauseBeforeFetch(cfg, destPath, total, types.ErrQuotaReached,
+		fmt.Sprintf("Paused %s: this month's download quota is used up. Resume it to download it anyway.", filepath.Base(destPath)))
+	utils.DebugFor(cfg.ID, "Paused %s: monthly quota reached", destPath)
+}
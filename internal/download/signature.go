@@ -0,0 +1,147 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/SurgeDM/Surge/internal/engine"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// maxSignatureSize bounds a fetched signature file; detached signatures
+// are a few hundred bytes.
+const maxSignatureSize = 64 * types.KB
+
+// verifySignature checks the working file at path against the download's
+// signature and reports whether one from a trusted key matched.
+//
+// A download that asked for a signature fails unless it verifies. One that
+// is only checked because of verify_signatures completes unverified when no
+// signature is found or it is from a key that is not trusted, and fails
+// only when a trusted key's signature does not match.
+func verifySignature(ctx context.Context, cfg *types.DownloadConfig, path string, progress engine.VerifyProgress) (bool, error) {
+	required := cfg.Request.SignatureURL != ""
+	if !required && (cfg.Runtime == nil || !cfg.Runtime.VerifySignatures) {
+		return false, nil
+	}
+
+	var keyPaths string
+	if cfg.Runtime != nil {
+		keyPaths = cfg.Runtime.SignatureKeys
+	}
+	keys, err := engine.LoadSignatureKeys(keyPaths)
+	if err != nil {
+		return false, fmt.Errorf("failed to load signature keys: %w", err)
+	}
+	if keys.Empty() {
+		if required {
+			return false, fmt.Errorf("%w: signature_keys is not set", types.ErrUntrustedSignature)
+		}
+		return false, nil
+	}
+
+	candidates := []string{cfg.Request.SignatureURL}
+	if !required || cfg.Request.SignatureURL == types.SignatureAuto {
+		candidates = signatureCandidates(cfg.URL)
+	}
+	explicit := required && cfg.Request.SignatureURL != types.SignatureAuto
+
+	transport, err := engine.DefaultNetworkPool.AcquireTransportFor(cfg.Runtime, types.PoolMaxConnsPerHost)
+	if err != nil {
+		return false, fmt.Errorf("failed to configure TLS: %w", err)
+	}
+	defer engine.DefaultNetworkPool.ReleaseTransport(transport)
+	client := &http.Client{Transport: transport, CheckRedirect: engine.RedirectPolicy(cfg.Runtime, cfg.Headers)}
+
+	for _, candidate := range candidates {
+		signature, err := fetchSignature(ctx, client, cfg, candidate)
+		if err != nil {
+			if explicit || ctx.Err() != nil {
+				return false, fmt.Errorf("failed to fetch signature: %w", err)
+			}
+			utils.DebugFor(cfg.ID, "Signature: %s: %v", candidate, err)
+			continue
+		}
+
+		signer, err := keys.VerifySignature(ctx, path, signature, progress)
+		if err == nil {
+			utils.DebugFor(cfg.ID, "Signature %s verified for %s, signed by %s", candidate, path, signer)
+			return true, nil
+		}
+		if !required && errors.Is(err, types.ErrUntrustedSignature) {
+			utils.DebugFor(cfg.ID, "Signature: ignoring %s: %v", candidate, err)
+			return false, nil
+		}
+		return false, fmt.Errorf("signature %s: %w", candidate, err)
+	}
+
+	if required {
+		return false, fmt.Errorf("%w: tried %s", types.ErrSignatureNotFound, strings.Join(candidates, ", "))
+	}
+	return false, nil
+}
+
+// signatureCandidates returns where a signature of rawurl is commonly
+// published: the same URL with a signature extension appended to its path.
+func signatureCandidates(rawurl string) []string {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil
+	}
+	candidates := make([]string, 0, len(engine.SignatureExtensions))
+	for _, ext := range engine.SignatureExtensions {
+		c := *u
+		c.Path += ext
+		c.RawPath = ""
+		candidates = append(candidates, c.String())
+	}
+	return candidates
+}
+
+// fetchSignature downloads a signature file. The download's custom headers
+// are only sent along when the signature is on the same host.
+func fetchSignature(ctx context.Context, client *http.Client, cfg *types.DownloadConfig, rawurl string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawurl, nil)
+	if err != nil {
+		return nil, err
+	}
+	if sameHost(rawurl, cfg.URL) {
+		for key, val := range cfg.Headers {
+			req.Header.Set(key, val)
+		}
+	}
+	req.Header.Set("User-Agent", cfg.Runtime.GetUserAgent())
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return nil, types.ErrSignatureNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSignatureSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxSignatureSize {
+		return nil, fmt.Errorf("signature is larger than %d bytes", maxSignatureSize)
+	}
+	return data, nil
+}
+
+func sameHost(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	return errA == nil && errB == nil && strings.EqualFold(ua.Host, ub.Host)
+}
@@ -0,0 +1,104 @@
+package download
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"aead.dev/minisign"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func TestVerifySignature_Semantics(t *testing.T) {
+	dir := t.TempDir()
+	data := []byte("release tarball")
+	path := filepath.Join(dir, "file.tar"+types.IncompleteSuffix)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	pub, priv, err := minisign.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, stranger, err := minisign.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyText, _ := pub.MarshalText()
+	keyPath := filepath.Join(dir, "release.pub")
+	if err := os.WriteFile(keyPath, keyText, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	signatures := map[string][]byte{
+		"/good.tar.minisig":      minisign.Sign(priv, data),
+		"/bad.tar.minisig":       minisign.Sign(priv, []byte("other")),
+		"/untrusted.tar.minisig": minisign.Sign(stranger, data),
+		"/detached.sig":          minisign.Sign(priv, data),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sig, ok := signatures[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(sig)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name         string
+		file         string
+		signatureURL string
+		discover     bool
+		wantVerified bool
+		wantErr      error
+	}{
+		{name: "discovered", file: "good.tar", discover: true, wantVerified: true},
+		{name: "discovered missing", file: "unsigned.tar", discover: true},
+		{name: "discovered untrusted", file: "untrusted.tar", discover: true},
+		{name: "discovered bad", file: "bad.tar", discover: true, wantErr: types.ErrBadSignature},
+		{name: "auto", file: "good.tar", signatureURL: types.SignatureAuto, wantVerified: true},
+		{name: "auto missing", file: "unsigned.tar", signatureURL: types.SignatureAuto, wantErr: types.ErrSignatureNotFound},
+		{name: "auto untrusted", file: "untrusted.tar", signatureURL: types.SignatureAuto, wantErr: types.ErrUntrustedSignature},
+		{name: "explicit", file: "unsigned.tar", signatureURL: srv.URL + "/detached.sig", wantVerified: true},
+		{name: "explicit missing", file: "good.tar", signatureURL: srv.URL + "/missing.sig", wantErr: types.ErrSignatureNotFound},
+		{name: "off", file: "bad.tar"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &types.DownloadConfig{
+				URL:     srv.URL + "/" + tt.file,
+				Runtime: &types.RuntimeConfig{SignatureKeys: keyPath, VerifySignatures: tt.discover},
+				Request: types.RequestOptions{SignatureURL: tt.signatureURL},
+			}
+			verified, err := verifySignature(context.Background(), cfg, path, nil)
+			if verified != tt.wantVerified {
+				t.Errorf("verified = %v, want %v", verified, tt.wantVerified)
+			}
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifySignature_RequiredWithoutKeys(t *testing.T) {
+	cfg := &types.DownloadConfig{
+		URL:     "http://example.invalid/file.tar",
+		Runtime: &types.RuntimeConfig{},
+		Request: types.RequestOptions{SignatureURL: types.SignatureAuto},
+	}
+	if _, err := verifySignature(context.Background(), cfg, "unused", nil); !errors.Is(err, types.ErrUntrustedSignature) {
+		t.Errorf("err = %v, want ErrUntrustedSignature", err)
+	}
+}
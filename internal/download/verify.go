@@ -0,0 +1,85 @@
+package download
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/SurgeDM/Surge/internal/engine"
+	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// verifyProgress reports how far checking the finished file has got: the
+// state shows the download as verifying, and a DownloadVerifyingMsg goes out
+// so a large file being hashed does not look stuck.
+func verifyProgress(cfg *types.DownloadConfig) engine.VerifyProgress {
+	return func(checked, total int64) {
+		if cfg.State != nil {
+			cfg.State.Checked.Store(checked)
+			cfg.State.SetPhase(types.PhaseVerifying)
+		}
+		if cfg.ProgressCh != nil {
+			safeSendProgress(cfg.ProgressCh, events.DownloadVerifyingMsg{
+				DownloadID: cfg.ID,
+				Checked:    checked,
+				Total:      total,
+			})
+		}
+	}
+}
+
+// fetchedOnResume reports whether a resumed download had been fetched in
+// full before it was paused while verifying, so only the checks need to run
+// again.
+func fetchedOnResume(savedState *types.DownloadState, destPath string) bool {
+	if savedState == nil || !savedState.Fetched {
+		return false
+	}
+	info, err := os.Stat(destPath + types.IncompleteSuffix)
+	return err == nil && info.Size() == savedState.TotalSize
+}
+
+// pauseVerifying saves a download paused while its finished file was being
+// checked. Nothing is left to fetch, so the saved state has no tasks and is
+// marked Fetched.
+func pauseVerifying(cfg *types.DownloadConfig, destPath, finalURL string, total int64) {
+	if cfg.State == nil {
+		return
+	}
+	rateLimit, rateLimitSet := cfg.State.GetRateLimit()
+	elapsed := cfg.State.FinalizePauseSession(total)
+
+	s := &types.DownloadState{
+		URL:          cfg.URL,
+		ID:           cfg.ID,
+		DestPath:     destPath,
+		TotalSize:    total,
+		Downloaded:   total,
+		Filename:     filepath.Base(destPath),
+		Elapsed:      elapsed.Nanoseconds(),
+		Mirrors:      cfg.Mirrors,
+		RateLimit:    rateLimit,
+		RateLimitSet: rateLimitSet,
+		FinalURL:     finalURL,
+		S3:           cfg.S3,
+		Headers:      cfg.Headers,
+		Checksum:     cfg.Request.Checksum,
+		Copies:       cfg.Request.Copies,
+		SignatureURL: cfg.Request.SignatureURL,
+		Interface:    cfg.Request.Interface,
+		TLS:          cfg.TLS,
+		Fetched:      true,
+	}
+	if cfg.ProgressCh != nil {
+		safeSendProgress(cfg.ProgressCh, events.DownloadPausedMsg{
+			DownloadID:   cfg.ID,
+			Filename:     s.Filename,
+			Downloaded:   total,
+			State:        s,
+			RateLimit:    rateLimit,
+			RateLimitSet: rateLimitSet,
+		})
+	}
+	utils.DebugFor(cfg.ID, "Download paused while verifying, state saved (Downloaded=%d)", total)
+}
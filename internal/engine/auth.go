@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"sync"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+// AuthFailures counts the 401 and 403 answers each host has given recently,
+// across every download, to tell an expired session from a one-off refusal.
+type AuthFailures struct {
+	mu    sync.Mutex
+	hosts map[string][]time.Time
+}
+
+// DefaultAuthFailures is the global instance shared by all downloads.
+var DefaultAuthFailures = &AuthFailures{}
+
+// Record notes a 401 or 403 from host and reports whether host has now
+// given types.AuthFailureThreshold of them within types.AuthFailureWindow.
+func (a *AuthFailures) Record(host string) bool {
+	return a.record(host, time.Now())
+}
+
+func (a *AuthFailures) record(host string, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.hosts == nil {
+		a.hosts = make(map[string][]time.Time)
+	}
+	recent := a.hosts[host][:0]
+	for _, at := range a.hosts[host] {
+		if now.Sub(at) < types.AuthFailureWindow {
+			recent = append(recent, at)
+		}
+	}
+	recent = append(recent, now)
+	a.hosts[host] = recent
+	return len(recent) >= types.AuthFailureThreshold
+}
+
+// Forget clears the failures of host, once its downloads have been given new
+// headers.
+func (a *AuthFailures) Forget(host string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.hosts, host)
+}
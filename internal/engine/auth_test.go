@@ -0,0 +1,38 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func TestAuthFailures(t *testing.T) {
+	var a AuthFailures
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for i := range types.AuthFailureThreshold - 1 {
+		if a.record("files.example.com", start.Add(time.Duration(i)*time.Second)) {
+			t.Fatalf("tripped after %d failures", i+1)
+		}
+	}
+	if a.record("other.example.com", start) {
+		t.Fatal("failures from another host counted")
+	}
+	if !a.record("files.example.com", start.Add(5*time.Second)) {
+		t.Fatal("did not trip at the threshold")
+	}
+
+	// Failures older than the window no longer count
+	later := start.Add(types.AuthFailureWindow + 10*time.Second)
+	if a.record("files.example.com", later) {
+		t.Fatal("tripped on failures outside the window")
+	}
+
+	a.Forget("files.example.com")
+	for i := range types.AuthFailureThreshold - 1 {
+		if a.record("files.example.com", later.Add(time.Duration(i)*time.Millisecond)) {
+			t.Fatal("tripped on failures from before Forget")
+		}
+	}
+}
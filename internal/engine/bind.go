@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// bindAddr returns the local address a connection to addr is made from when
+// downloads are bound to iface, an interface name or one of this machine's
+// IPs. The interface is looked up on every dial, so a VPN tunnel that comes
+// up later is used once it does. While it is down or has no address of the
+// right family the dial fails instead of leaving over the default route.
+func bindAddr(iface, network, addr string) (*net.TCPAddr, error) {
+	if ip := net.ParseIP(iface); ip != nil {
+		return &net.TCPAddr{IP: ip}, nil
+	}
+
+	ifi, err := net.InterfaceByName(iface)
+	if err != nil {
+		return nil, fmt.Errorf("bind interface %q: %w", iface, err)
+	}
+	if ifi.Flags&net.FlagUp == 0 {
+		return nil, fmt.Errorf("bind interface %q is down", iface)
+	}
+	addrs, err := ifi.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("bind interface %q: %w", iface, err)
+	}
+
+	// A host name may resolve to either family; the dialer only tries the
+	// remote addresses that match the local one, so IPv4 is preferred.
+	want4, want6 := true, true
+	switch network {
+	case "tcp4":
+		want6 = false
+	case "tcp6":
+		want4 = false
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		if ip := net.ParseIP(host); ip != nil {
+			want4, want6 = want4 && ip.To4() != nil, want6 && ip.To4() == nil
+		}
+	}
+
+	var v6 net.IP
+	for _, a := range addrs {
+		ipNet, ok := a.(*net.IPNet)
+		if !ok || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		if ipNet.IP.To4() != nil {
+			if want4 {
+				return &net.TCPAddr{IP: ipNet.IP}, nil
+			}
+		} else if want6 && v6 == nil {
+			v6 = ipNet.IP
+		}
+	}
+	if v6 != nil {
+		return &net.TCPAddr{IP: v6}, nil
+	}
+	return nil, fmt.Errorf("bind interface %q has no %s address", iface, familyName(want4, want6))
+}
+
+func familyName(want4, want6 bool) string {
+	var names []string
+	if want4 {
+		names = append(names, "IPv4")
+	}
+	if want6 {
+		names = append(names, "IPv6")
+	}
+	if len(names) == 0 {
+		return "usable"
+	}
+	return strings.Join(names, " or ")
+}
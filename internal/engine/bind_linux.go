@@ -0,0 +1,23 @@
+//go:build linux
+
+package engine
+
+import (
+	"syscall"
+
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// bindToDevice returns a dialer Control that also ties the socket to iface,
+// so it leaves through it whatever the routing table prefers, like curl's
+// --interface. Without CAP_NET_RAW the kernel refuses and the source address
+// alone picks the route.
+func bindToDevice(iface string) func(network, address string, c syscall.RawConn) error {
+	return func(_, _ string, c syscall.RawConn) error {
+		return c.Control(func(fd uintptr) {
+			if err := syscall.SetsockoptString(int(fd), syscall.SOL_SOCKET, syscall.SO_BINDTODEVICE, iface); err != nil {
+				utils.Debug("NetworkPool: SO_BINDTODEVICE %s: %v", iface, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package engine
+
+import "syscall"
+
+// bindToDevice is only supported on Linux; elsewhere the source address
+// alone picks the route.
+func bindToDevice(string) func(network, address string, c syscall.RawConn) error {
+	return nil
+}
@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func loopbackInterface(t *testing.T) string {
+	t.Helper()
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		t.Skipf("cannot list interfaces: %v", err)
+	}
+	for _, ifi := range ifaces {
+		if ifi.Flags&net.FlagLoopback != 0 && ifi.Flags&net.FlagUp != 0 {
+			return ifi.Name
+		}
+	}
+	t.Skip("no loopback interface")
+	return ""
+}
+
+func TestNetworkPool_BindInterface(t *testing.T) {
+	remote := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remote <- r.RemoteAddr
+	}))
+	defer server.Close()
+
+	pool := &NetworkPool{}
+	transport, err := pool.AcquireTransportFor(&types.RuntimeConfig{BindInterface: loopbackInterface(t)}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.ReleaseTransport(transport)
+
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("request over the loopback interface failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if host, _, _ := net.SplitHostPort(<-remote); host != "127.0.0.1" {
+		t.Errorf("server saw the request from %s, want 127.0.0.1", host)
+	}
+}
+
+func TestNetworkPool_BindInterfaceMissingFailsClosed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached the server without the bound interface")
+	}))
+	defer server.Close()
+
+	pool := &NetworkPool{}
+	transport, err := pool.AcquireTransportFor(&types.RuntimeConfig{BindInterface: "surge-missing0"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.ReleaseTransport(transport)
+
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err == nil {
+		_ = resp.Body.Close()
+		t.Fatal("expected the request to fail while the interface is missing")
+	}
+	if !strings.Contains(err.Error(), "surge-missing0") {
+		t.Errorf("error = %v, want it to name the interface", err)
+	}
+}
@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+// VerifyChecksum hashes the file at path and compares it with checksum, given
+// in the form types.ParseChecksum accepts. It stops once ctx is done.
+func VerifyChecksum(ctx context.Context, path, checksum string, progress VerifyProgress) error {
+	w, err := NewChecksumWriter(checksum)
+	if err != nil {
+		return err
+	}
+
+	f, err := openVerify(ctx, path, progress)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := io.Copy(w, f); err != nil {
+		return err
+	}
+	return w.Verify()
+}
+
+// ChecksumWriter hashes what is written to it, for checking data that is
+// never stored in a file, such as a download piped to another program.
+type ChecksumWriter struct {
+	hash.Hash
+	algorithm, want string
+}
+
+// NewChecksumWriter returns a writer that checks against checksum, given in
+// the form types.ParseChecksum accepts.
+func NewChecksumWriter(checksum string) (*ChecksumWriter, error) {
+	algorithm, want, err := types.ParseChecksum(checksum)
+	if err != nil {
+		return nil, err
+	}
+
+	var h hash.Hash
+	switch algorithm {
+	case "md5":
+		h = md5.New()
+	case "sha1":
+		h = sha1.New()
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	}
+	return &ChecksumWriter{Hash: h, algorithm: algorithm, want: want}, nil
+}
+
+// Verify compares the digest of everything written so far with the
+// expected one.
+func (w *ChecksumWriter) Verify() error {
+	if got := hex.EncodeToString(w.Sum(nil)); got != w.want {
+		return fmt.Errorf("%w: %s %s, got %s", types.ErrExpectedChecksum, w.algorithm, w.want, got)
+	}
+	return nil
+}
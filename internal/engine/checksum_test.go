@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func TestVerifyChecksum(t *testing.T) {
+	data := []byte("surge checksum")
+	path := filepath.Join(t.TempDir(), "file.bin")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+
+	if err := VerifyChecksum(context.Background(), path, "sha256:"+hex.EncodeToString(sum[:]), nil); err != nil {
+		t.Errorf("matching checksum: %v", err)
+	}
+	if err := VerifyChecksum(context.Background(), path, "md5:00000000000000000000000000000000", nil); !errors.Is(err, types.ErrExpectedChecksum) {
+		t.Errorf("wrong checksum: err = %v, want ErrExpectedChecksum", err)
+	}
+	if err := VerifyChecksum(context.Background(), path, "sha256:nope", nil); err == nil || errors.Is(err, types.ErrExpectedChecksum) {
+		t.Errorf("malformed checksum: err = %v, want a parse error", err)
+	}
+}
+
+func TestVerifyChecksum_ProgressAndCancel(t *testing.T) {
+	data := bytes.Repeat([]byte("surge"), 100000)
+	path := filepath.Join(t.TempDir(), "file.bin")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+	checksum := "sha256:" + hex.EncodeToString(sum[:])
+
+	var read, total int64
+	progress := func(r, t int64) { read, total = r, t }
+	if err := VerifyChecksum(context.Background(), path, checksum, progress); err != nil {
+		t.Fatal(err)
+	}
+	if read != int64(len(data)) || total != int64(len(data)) {
+		t.Errorf("last progress = %d/%d, want %d/%d", read, total, len(data), len(data))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := VerifyChecksum(ctx, path, checksum, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled: err = %v, want context.Canceled", err)
+	}
+}
+
+func TestChecksumWriter(t *testing.T) {
+	sum := sha256.Sum256([]byte("streamed bytes"))
+	w, err := NewChecksumWriter("sha256:" + hex.EncodeToString(sum[:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte("streamed "))
+	if err := w.Verify(); !errors.Is(err, types.ErrExpectedChecksum) {
+		t.Errorf("partial data: err = %v, want ErrExpectedChecksum", err)
+	}
+	_, _ = w.Write([]byte("bytes"))
+	if err := w.Verify(); err != nil {
+		t.Errorf("full data: %v", err)
+	}
+}
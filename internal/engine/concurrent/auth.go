@@ -0,0 +1,39 @@
+package concurrent
+
+import (
+	"net/http"
+
+	"github.com/SurgeDM/Surge/internal/engine"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// checkAuthExpired counts a 401 or 403 against the host that sent it. Once
+// that host has refused enough requests to look like an expired session
+// rather than a one-off, it returns the AuthExpiredError to pause with.
+func checkAuthExpired(resp *http.Response) error {
+	if resp.StatusCode != http.StatusUnauthorized && resp.StatusCode != http.StatusForbidden {
+		return nil
+	}
+	if resp.Request == nil || resp.Request.URL == nil {
+		return nil
+	}
+	host := resp.Request.URL.Host
+	if !engine.DefaultAuthFailures.Record(host) {
+		return nil
+	}
+	return &types.AuthExpiredError{Host: host, Status: resp.StatusCode}
+}
+
+// pauseForAuth pauses the download once its host keeps refusing its
+// credentials, keeping every finished range so it resumes once its headers
+// are refreshed. The pool pauses the other downloads from the host. It
+// reports false when the download was already paused.
+func (d *ConcurrentDownloader) pauseForAuth(cause error) bool {
+	if d.State == nil || !d.refreshPause.CompareAndSwap(false, true) {
+		return false
+	}
+	utils.DebugFor(d.ID, "Pausing %s for new credentials: %v", d.ID, cause)
+	d.State.PauseFor(cause)
+	return true
+}
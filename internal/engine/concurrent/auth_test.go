@@ -0,0 +1,64 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/testutil"
+)
+
+func TestConcurrentDownloader_PausesWhenSessionExpires(t *testing.T) {
+	tmpDir, cleanup := initTestState(t)
+	defer cleanup()
+
+	server := testutil.NewHTTPServerT(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	destPath := filepath.Join(tmpDir, "private.bin")
+	if f, err := os.Create(destPath + types.IncompleteSuffix); err == nil {
+		_ = f.Close()
+	}
+
+	fileSize := int64(4 * types.MB)
+	progressCh := make(chan any, 16)
+	state := types.NewProgressState("auth-expired", fileSize)
+	d := NewConcurrentDownloader("auth-expired", progressCh, state, &types.RuntimeConfig{MaxConnectionsPerDownload: 2})
+	d.Headers = map[string]string{"Cookie": "session=stale"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	err := d.Download(ctx, server.URL+"/private.bin", nil, nil, destPath, fileSize)
+	if !errors.Is(err, types.ErrPaused) {
+		t.Fatalf("Download err = %v, want ErrPaused", err)
+	}
+
+	var paused *events.DownloadPausedMsg
+	for len(progressCh) > 0 {
+		if msg, ok := (<-progressCh).(events.DownloadPausedMsg); ok {
+			paused = &msg
+		}
+	}
+	if paused == nil {
+		t.Fatal("expected a DownloadPausedMsg")
+	}
+	if paused.State.Headers["Cookie"] != "session=stale" {
+		t.Errorf("paused state headers = %v, want them kept for the refresh", paused.State.Headers)
+	}
+	var remaining int64
+	for _, task := range paused.State.Tasks {
+		remaining += task.Length
+	}
+	if remaining != fileSize {
+		t.Errorf("paused with %d bytes remaining, want all %d", remaining, fileSize)
+	}
+}
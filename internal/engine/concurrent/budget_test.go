@@ -0,0 +1,55 @@
+package concurrent
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func TestConcurrentDownloader_StaysWithinConnectionBudget(t *testing.T) {
+	data := pipelineTestData(9 * types.MB)
+	url, _, maxActive := newPipelineTestServer(t, data, nil)
+
+	tmpDir, cleanup := initTestState(t)
+	t.Cleanup(cleanup)
+	destPath := filepath.Join(tmpDir, "budget_test.bin")
+	if f, err := os.Create(destPath + types.IncompleteSuffix); err == nil {
+		_ = f.Close()
+	}
+
+	runtime := &types.RuntimeConfig{
+		MaxConnectionsPerDownload: 8,
+		MinChunkSize:              32 * types.KB,
+		WorkerBufferSize:          8 * types.KB,
+	}
+	budget := engine.NewConnectionBudget(2)
+	share := budget.Join()
+	defer share.Leave()
+
+	state := types.NewProgressState("budget-test", int64(len(data)))
+	downloader := NewConcurrentDownloader("budget-id", nil, state, runtime)
+	downloader.Budget = share
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := downloader.Download(ctx, url, nil, nil, destPath, int64(len(data))); err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+
+	got, err := os.ReadFile(destPath + types.IncompleteSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatal("downloaded content does not match served data")
+	}
+	if n := maxActive.Load(); n > 2 {
+		t.Errorf("max concurrent requests = %d, want at most the budget of 2", n)
+	}
+}
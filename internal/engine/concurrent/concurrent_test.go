@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/SurgeDM/Surge/internal/engine/events"
 	"github.com/SurgeDM/Surge/internal/engine/state"
 	"github.com/SurgeDM/Surge/internal/engine/types"
 	"github.com/SurgeDM/Surge/internal/testutil"
@@ -411,6 +412,55 @@ func TestConcurrentDownloader_Cancellation(t *testing.T) {
 	}
 }
 
+func TestConcurrentDownloader_DeleteWhilePausingSavesNothing(t *testing.T) {
+	tmpDir, cleanup := initTestState(t)
+	defer cleanup()
+
+	fileSize := int64(10 * types.MB)
+	server := testutil.NewMockServerT(t,
+		testutil.WithFileSize(fileSize),
+		testutil.WithRangeSupport(true),
+		testutil.WithByteLatency(100*time.Microsecond),
+	)
+	defer server.Close()
+
+	destPath := filepath.Join(tmpDir, "delete_test.bin")
+	state := types.NewProgressState("delete-test", fileSize)
+	progressCh := make(chan any, 100)
+	runtime := &types.RuntimeConfig{MaxConnectionsPerDownload: 4}
+
+	downloader := NewConcurrentDownloader("delete-id", progressCh, state, runtime)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	done := make(chan error)
+	go func() {
+		if f, err := os.Create(destPath + ".surge"); err == nil {
+			_ = f.Close()
+		}
+		done <- downloader.Download(ctx, server.URL(), nil, nil, destPath, fileSize)
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+	// A pause was asked for, but the delete cancels the download first
+	state.Paused.Store(true)
+	cancel(types.ErrUserDelete)
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, types.ErrUserDelete) {
+			t.Fatalf("expected ErrUserDelete, got: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Download didn't respond to cancellation")
+	}
+	for len(progressCh) > 0 {
+		if msg, ok := (<-progressCh).(events.DownloadPausedMsg); ok {
+			t.Fatalf("deleted download saved a paused state: %+v", msg)
+		}
+	}
+}
+
 func TestConcurrentDownloader_PauseAtCompletionFinalizesAsCompleted(t *testing.T) {
 	tmpDir, cleanup := initTestState(t)
 	defer cleanup()
@@ -633,11 +683,8 @@ func TestConcurrentDownloader_ResumePartialDownload(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	// Pre-create incomplete file (simulating processing layer)
-	if f, err := os.Create(destPath + ".surge"); err == nil {
-		_ = f.Close()
-	}
-
+	// The partial file created above is the working file; it must keep the
+	// downloaded bytes, which the resume check compares with the server.
 	err = downloader.Download(ctx, server.URL(), nil, nil, destPath, fileSize)
 	if err != nil {
 		t.Fatalf("Resume download failed: %v", err)
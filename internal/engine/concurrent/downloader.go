@@ -7,11 +7,11 @@ import (
 	"io"
 	"math"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/SurgeDM/Surge/internal/engine"
@@ -32,11 +32,39 @@ type ConcurrentDownloader struct {
 	DestPath     string // For pause/resume
 	Runtime      *types.RuntimeConfig
 	Limiter      types.ByteLimiter
+	Budget       types.ConnectionGate // nil leaves connections unlimited
 	RateLimitBps int64
 	RateLimitSet bool
 	TotalSize    int64
 	bufPool      sync.Pool
 	Headers      map[string]string // Custom HTTP headers from browser (cookies, auth, etc.)
+	pipelineOff  atomic.Bool       // Set once the server rejects a read-ahead request
+	hosts        *hostGate         // Backs off hosts that answer 429/503
+	links        []*link           // One per bound interface; idle connections are dropped after a sleep
+	// EarlyBytes is the length of the file prefix an early-ramp probe already
+	// wrote to the working file; fresh downloads start their tasks after it.
+	EarlyBytes int64
+	// S3 is the multipart layout of an S3 object. Chunks and splits land on
+	// its part boundaries when the part size is known.
+	S3           types.S3Object
+	refreshPause atomic.Bool // Set once an expired URL or session paused the download
+	// LowPriority runs every worker on a thread with lowered disk and CPU
+	// priority.
+	LowPriority bool
+	// Checksum is the digest the finished file must match, kept with the
+	// pause state so a resume still verifies it.
+	Checksum string
+	// Copies are the directories the file is also written to as it downloads.
+	Copies []string
+	// SignatureURL is kept with the pause state so a resume still verifies
+	// the signature.
+	SignatureURL string
+	// Interface is the interface the request asked to connect from, kept
+	// with the pause state so a resume uses it too.
+	Interface string
+	// TLS is the request's override of the TLS settings, kept with the
+	// pause state so a resume connects the same way.
+	TLS types.TLSOptions
 }
 
 // NewConcurrentDownloader creates a new concurrent downloader with all required parameters
@@ -50,6 +78,7 @@ func NewConcurrentDownloader(id string, progressCh chan<- any, progState *types.
 		ProgressChan: progressCh,
 		State:        progState,
 		activeTasks:  make(map[int]*ActiveTask),
+		hosts:        newHostGate(),
 		Runtime:      runtime,
 		bufPool: sync.Pool{
 			New: func() any {
@@ -64,8 +93,14 @@ func NewConcurrentDownloader(id string, progressCh chan<- any, progState *types.
 
 // getInitialConnections returns the starting number of connections based on file size
 func (d *ConcurrentDownloader) getInitialConnections(fileSize int64) int {
-	maxConns := d.Runtime.GetMaxConnectionsPerDownload()
-	minChunkSize := d.Runtime.GetMinChunkSize() // e.g., 1MB or 5MB
+	return InitialConnections(d.Runtime, fileSize)
+}
+
+// InitialConnections returns the number of connections a download of
+// fileSize bytes starts with under runtime.
+func InitialConnections(runtime *types.RuntimeConfig, fileSize int64) int {
+	maxConns := runtime.GetMaxConnectionsPerDownload()
+	minChunkSize := runtime.GetMinChunkSize() // e.g., 1MB or 5MB
 
 	if fileSize <= 0 {
 		return 1
@@ -99,6 +134,47 @@ func (d *ConcurrentDownloader) getInitialConnections(fileSize int64) int {
 	return calculatedWorkers
 }
 
+// recordFinalURL remembers where the primary URL redirected to so pause state
+// and the UI can report the resolved endpoint.
+func (d *ConcurrentDownloader) recordFinalURL(rawurl string, resp *http.Response) {
+	if d.State == nil || rawurl != d.URL || resp.Request == nil || resp.Request.URL == nil {
+		return
+	}
+	if final := resp.Request.URL.String(); final != rawurl && final != d.State.GetFinalURL() {
+		d.State.SetFinalURL(final)
+	}
+}
+
+// recordLastModified keeps the source's Last-Modified when the probe did not
+// pass one on, as after a resume. Mirrors may stamp their copies
+// differently, so only the source URL counts.
+func (d *ConcurrentDownloader) recordLastModified(rawurl string, resp *http.Response) {
+	if d.State == nil || rawurl != d.URL || !d.State.GetLastModified().IsZero() {
+		return
+	}
+	if t := engine.LastModified(resp); !t.IsZero() {
+		d.State.SetLastModified(t)
+	}
+}
+
+// nextMirror returns the index of the mirror to try after idx. Mirrors that
+// have already failed are skipped while a healthy one remains, so a dead
+// member of a large mirror group does not keep costing retries.
+func (d *ConcurrentDownloader) nextMirror(mirrors []string, idx int) int {
+	if d.State != nil {
+		failed := make(map[string]bool)
+		for _, m := range d.State.GetMirrors() {
+			failed[m.URL] = m.Error
+		}
+		for step := 1; step < len(mirrors); step++ {
+			if next := (idx + step) % len(mirrors); !failed[mirrors[next]] {
+				return next
+			}
+		}
+	}
+	return (idx + 1) % len(mirrors)
+}
+
 // ReportMirrorError marks a mirror as having an error in the state
 func (d *ConcurrentDownloader) ReportMirrorError(url string) {
 	if d.State == nil {
@@ -122,15 +198,19 @@ func (d *ConcurrentDownloader) ReportMirrorError(url string) {
 
 // calculateChunkSize determines optimal chunk size
 func (d *ConcurrentDownloader) calculateChunkSize(fileSize int64, numConns int) int64 {
+	return parallelChunkSize(d.Runtime, fileSize, numConns)
+}
+
+func parallelChunkSize(runtime *types.RuntimeConfig, fileSize int64, numConns int) int64 {
 	// Safety check
 	if numConns <= 0 {
-		return d.Runtime.GetMinChunkSize() // Fallback
+		return runtime.GetMinChunkSize() // Fallback
 	}
 
 	chunkSize := fileSize / int64(numConns)
 
 	// Clamp to min from config (but not max - we want large chunks)
-	minChunk := d.Runtime.GetMinChunkSize()
+	minChunk := runtime.GetMinChunkSize()
 
 	if chunkSize < minChunk {
 		chunkSize = minChunk
@@ -147,9 +227,15 @@ func (d *ConcurrentDownloader) calculateChunkSize(fileSize int64, numConns int)
 
 // determineChunkSize decides the strategy (Sequential vs Parallel)
 func (d *ConcurrentDownloader) determineChunkSize(fileSize int64, numConns int) int64 {
-	if d.Runtime.SequentialDownload {
+	return d.alignToParts(ChunkSize(d.Runtime, fileSize, numConns))
+}
+
+// ChunkSize returns the size of the chunks a download of fileSize bytes over
+// numConns connections is first cut into under runtime.
+func ChunkSize(runtime *types.RuntimeConfig, fileSize int64, numConns int) int64 {
+	if runtime.SequentialDownload {
 		// Sequential mode: Use small fixed chunks (MinChunkSize) to ensure strict ordering
-		chunkSize := d.Runtime.GetMinChunkSize()
+		chunkSize := runtime.GetMinChunkSize()
 		if chunkSize <= 0 {
 			chunkSize = 2 * types.MB // Default 2MB if not configured
 		}
@@ -162,7 +248,7 @@ func (d *ConcurrentDownloader) determineChunkSize(fileSize int64, numConns int)
 	}
 
 	// Parallel mode: Use large shards
-	return d.calculateChunkSize(fileSize, numConns)
+	return parallelChunkSize(runtime, fileSize, numConns)
 }
 
 // createTasks generates initial task queue from file size and chunk size
@@ -188,48 +274,37 @@ func createTasks(fileSize, chunkSize int64) []types.Task {
 func (d *ConcurrentDownloader) applyClientSettings(client *http.Client) {
 	// Preserve headers on redirects for authenticated downloads
 	// By default, Go strips sensitive headers (Cookie, Authorization) on cross-domain redirects.
-	// Since these headers were explicitly provided by the browser for this download, we forward them.
-	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
-		if len(via) >= 10 {
-			return types.ErrMaxRedirects
-		}
-		// Copy headers from original request to redirect request
-		if len(via) > 0 {
-			utils.CopyRedirectHeaders(req, via[0])
-		}
-		// Re-apply explicit custom headers down the redirect chain
-		for key, val := range d.Headers {
-			if key != "Range" {
-				req.Header.Set(key, val)
-			}
-		}
-		return nil
-	}
+	// Since these headers were explicitly provided by the browser for this download, we forward them
+	// unless the redirect settings say otherwise.
+	client.CheckRedirect = engine.RedirectPolicy(d.Runtime, d.Headers)
 }
 
 // Download downloads a file using multiple concurrent connections
 // Uses pre-probed metadata (file size already known)
 func (d *ConcurrentDownloader) Download(ctx context.Context, rawurl string, candidateMirrors []string, activeMirrors []string, destPath string, fileSize int64) error {
-	utils.Debug("ConcurrentDownloader.Download: %s -> %s (size: %d, mirrors: %d)", rawurl, destPath, fileSize, len(activeMirrors))
+	utils.DebugFor(d.ID, "ConcurrentDownloader.Download: %s -> %s (size: %d, mirrors: %d)", rawurl, destPath, fileSize, len(activeMirrors))
 
 	d.initMirrorStatus(rawurl, candidateMirrors, activeMirrors, destPath)
 
-	workingPath := destPath + types.IncompleteSuffix
-	downloadCtx, cancel := context.WithCancel(ctx)
+	downloadCtx, cancel := context.WithCancelCause(ctx)
 
 	if d.State != nil {
 		d.State.SetCancelFunc(cancel)
 	}
 
-	client, transport := d.setupNetwork()
-	// Release transport back to the pool ONLY after all helpers and workers are joined (LIFO: runs last)
-	defer engine.DefaultNetworkPool.ReleaseTransport(transport)
+	client, err := d.setupNetwork()
+	if err != nil {
+		cancel(nil)
+		return err
+	}
+	// Release transports back to the pool ONLY after all helpers and workers are joined (LIFO: runs last)
+	defer d.releaseNetwork()
 
 	// Helper synchronization for monitors and balancer
 	var wgHelpers sync.WaitGroup
 	// Ensure we wait for helpers to finish; run wait AFTER cancel (LIFO: Wait runs second, cancel runs first)
 	defer wgHelpers.Wait()
-	defer cancel()
+	defer cancel(nil)
 
 	// Ensure we have the total file size
 	if fileSize <= 0 {
@@ -248,12 +323,12 @@ func (d *ConcurrentDownloader) Download(ctx context.Context, rawurl string, cand
 
 	// Pre-warm connections if configured
 	hedgeCount := d.Runtime.GetDialHedgeCount()
-	if hedgeCount > 0 {
-		d.prewarmConnections(downloadCtx, client, numConns, hedgeCount, workerMirrors)
+	if hedgeCount > 0 && d.EarlyBytes < fileSize {
+		d.prewarmLinks(downloadCtx, client, numConns, hedgeCount, workerMirrors)
 	}
 
 	// Open existing output file with .surge suffix (must be created by processing layer)
-	outFile, err := os.OpenFile(workingPath, os.O_RDWR, 0)
+	outFile, err := engine.OpenWorkingFile(destPath, d.Copies)
 	if err != nil {
 		return fmt.Errorf("failed to open working file: %w", err)
 	}
@@ -268,10 +343,15 @@ func (d *ConcurrentDownloader) Download(ctx context.Context, rawurl string, cand
 		d.State.InitBitmap(fileSize, chunkSize)
 	}
 
-	tasks, err := d.setupTasks(destPath, fileSize, chunkSize, outFile)
+	tasks, resumed, err := d.setupTasks(destPath, fileSize, chunkSize, outFile)
 	if err != nil {
 		return err
 	}
+	if resumed {
+		if err := d.verifyResume(downloadCtx, client, rawurl, tasks, fileSize, outFile); err != nil {
+			return err
+		}
+	}
 
 	queue := NewTaskQueue()
 	queue.PushMultiple(tasks)
@@ -282,6 +362,12 @@ func (d *ConcurrentDownloader) Download(ctx context.Context, rawurl string, cand
 	// Execute download workers
 	downloadErr := d.executeWorkers(downloadCtx, client, outFile, queue, fileSize, workerMirrors, numConns)
 
+	// A delete that lands while the download is still pausing wins: its
+	// progress is thrown away, so nothing is saved for a resume.
+	if cause := context.Cause(ctx); errors.Is(cause, types.ErrUserDelete) {
+		return cause
+	}
+
 	// Handle pause request: must return types.ErrPaused to prevent finalization
 	if d.State != nil && d.State.IsPaused() {
 		pauseErr := d.handlePause(destPath, fileSize, queue, candidateMirrors)
@@ -295,7 +381,7 @@ func (d *ConcurrentDownloader) Download(ctx context.Context, rawurl string, cand
 	// Handle cancel: context was cancelled but not via Pause()
 	// Propagate cancellation so callers don't treat this as a successful completion.
 	if downloadCtx.Err() == context.Canceled {
-		return context.Canceled
+		return context.Cause(downloadCtx)
 	}
 	if downloadErr != nil {
 		return downloadErr
@@ -336,19 +422,6 @@ func (d *ConcurrentDownloader) initMirrorStatus(rawurl string, candidateMirrors
 	d.State.SetMirrors(statuses)
 }
 
-func (d *ConcurrentDownloader) setupNetwork() (*http.Client, *http.Transport) {
-	var proxyURL, customDNS string
-	if d.Runtime != nil {
-		proxyURL = d.Runtime.ProxyURL
-		customDNS = d.Runtime.CustomDNS
-	}
-
-	transport := engine.DefaultNetworkPool.AcquireTransport(proxyURL, customDNS, types.PoolMaxConnsPerHost)
-	client := &http.Client{Transport: transport}
-	d.applyClientSettings(client)
-	return client, transport
-}
-
 func (d *ConcurrentDownloader) getWorkerMirrors(activeMirrors []string) []string {
 	mirrors := make([]string, 0, len(activeMirrors)+1)
 	mirrors = append(mirrors, d.URL)
@@ -360,7 +433,9 @@ func (d *ConcurrentDownloader) getWorkerMirrors(activeMirrors []string) []string
 	return mirrors
 }
 
-func (d *ConcurrentDownloader) setupTasks(destPath string, fileSize, chunkSize int64, outFile *os.File) ([]types.Task, error) {
+// setupTasks returns the ranges left to download and whether they were
+// restored from a saved state.
+func (d *ConcurrentDownloader) setupTasks(destPath string, fileSize, chunkSize int64, outFile engine.WorkingFile) ([]types.Task, bool, error) {
 	savedState, err := state.LoadState(d.URL, destPath)
 	isResume := err == nil && savedState != nil && len(savedState.Tasks) > 0
 
@@ -376,21 +451,41 @@ func (d *ConcurrentDownloader) setupTasks(destPath string, fileSize, chunkSize i
 				d.State.RecalculateProgress(savedState.Tasks)
 				d.State.Downloaded.Store(d.State.VerifiedProgress.Load())
 				d.State.SyncSessionStart()
-				utils.Debug("Restored chunk map: size %d", savedState.ActualChunkSize)
+				utils.DebugFor(d.ID, "Restored chunk map: size %d", savedState.ActualChunkSize)
 			}
 		}
-		utils.Debug("Resuming from saved state: %d tasks, %d bytes downloaded", len(savedState.Tasks), savedState.Downloaded)
-		return savedState.Tasks, nil
+		utils.DebugFor(d.ID, "Resuming from saved state: %d tasks, %d bytes downloaded", len(savedState.Tasks), savedState.Downloaded)
+		return savedState.Tasks, true, nil
 	}
 
 	if err := outFile.Truncate(fileSize); err != nil {
-		return nil, fmt.Errorf("failed to preallocate file: %w", err)
+		return nil, false, fmt.Errorf("failed to preallocate file: %w", err)
+	}
+
+	// Skip the prefix the probe already wrote (Truncate keeps existing bytes)
+	prefix := d.EarlyBytes
+	if prefix < 0 || prefix > fileSize {
+		prefix = 0
 	}
 	if d.State != nil {
 		d.State.Downloaded.Store(0)
+		if prefix > 0 {
+			d.State.UpdateChunkStatus(0, prefix, types.ChunkCompleted)
+			d.State.Downloaded.Store(prefix)
+		}
 		d.State.SyncSessionStart()
 	}
-	return createTasks(fileSize, chunkSize), nil
+
+	// S3 parts are counted from the start of the object, so cut the prefix
+	// out of the first chunk rather than shifting every boundary past it.
+	if d.S3.PartSize > 0 {
+		return skipPrefix(createTasks(fileSize, chunkSize), prefix), false, nil
+	}
+	tasks := createTasks(fileSize-prefix, chunkSize)
+	for i := range tasks {
+		tasks[i].Offset += prefix
+	}
+	return tasks, false, nil
 }
 
 func (d *ConcurrentDownloader) startHelpers(ctx context.Context, wg *sync.WaitGroup, queue *TaskQueue, fileSize int64, numConns int) {
@@ -474,17 +569,23 @@ func (d *ConcurrentDownloader) runHealthMonitor(ctx context.Context) {
 	ticker := time.NewTicker(types.HealthCheckInterval)
 	defer ticker.Stop()
 
+	var wake engine.WakeDetector
+	wake.Observe()
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
+			if slept := wake.Observe(); slept >= types.SleepJumpThreshold {
+				d.resetAfterSleep(slept)
+			}
+			d.publishConnectionSpeeds()
 			d.checkWorkerHealth()
 		}
 	}
 }
 
-func (d *ConcurrentDownloader) executeWorkers(ctx context.Context, client *http.Client, outFile *os.File, queue *TaskQueue, fileSize int64, workerMirrors []string, numConns int) error {
+func (d *ConcurrentDownloader) executeWorkers(ctx context.Context, client *http.Client, outFile engine.WorkingFile, queue *TaskQueue, fileSize int64, workerMirrors []string, numConns int) error {
 	var wg sync.WaitGroup
 	workerErrors := make(chan error, numConns)
 
@@ -493,6 +594,11 @@ func (d *ConcurrentDownloader) executeWorkers(ctx context.Context, client *http.
 		wg.Add(1)
 		go func(workerID int) {
 			defer wg.Done()
+			if d.LowPriority {
+				if err := utils.LowerThreadPriority(); err != nil {
+					utils.DebugFor(d.ID, "Could not lower priority of %s: %v", d.ID, err)
+				}
+			}
 			err := d.worker(ctx, workerID, workerMirrors, outFile, queue, fileSize, client)
 			if err != nil && err != context.Canceled {
 				workerErrors <- err
@@ -543,7 +649,7 @@ func (d *ConcurrentDownloader) handlePause(destPath string, fileSize int64, queu
 		remainingBytes += task.Length
 	}
 	if remainingBytes == 0 {
-		utils.Debug("Download pause requested at completion boundary; finalizing as completed")
+		utils.DebugFor(d.ID, "Download pause requested at completion boundary; finalizing as completed")
 		d.State.Resume()
 		_, _ = d.State.FinalizeSession(fileSize)
 		return nil
@@ -579,6 +685,14 @@ func (d *ConcurrentDownloader) handlePause(destPath string, fileSize int64, queu
 		ActualChunkSize: chunkSize,
 		RateLimit:       rateLimit,
 		RateLimitSet:    rateLimitSet,
+		FinalURL:        d.State.GetFinalURL(),
+		S3:              d.S3,
+		Headers:         d.Headers,
+		Checksum:        d.Checksum,
+		Copies:          d.Copies,
+		SignatureURL:    d.SignatureURL,
+		Interface:       d.Interface,
+		TLS:             d.TLS,
 	}
 	if d.ProgressChan != nil {
 		d.ProgressChan <- events.DownloadPausedMsg{
@@ -591,12 +705,12 @@ func (d *ConcurrentDownloader) handlePause(destPath string, fileSize int64, queu
 		}
 	}
 
-	utils.Debug("Download paused, state saved (Downloaded=%d, RemainingTasks=%d, RemainingBytes=%d)",
+	utils.DebugFor(d.ID, "Download paused, state saved (Downloaded=%d, RemainingTasks=%d, RemainingBytes=%d)",
 		computedDownloaded, len(remainingTasks), remainingBytes)
 	return types.ErrPaused
 }
 
-func (d *ConcurrentDownloader) syncFile(outFile *os.File) error {
+func (d *ConcurrentDownloader) syncFile(outFile engine.WorkingFile) error {
 	if outFile == nil {
 		return nil
 	}
@@ -716,20 +830,20 @@ func (d *ConcurrentDownloader) prewarmConnections(ctx context.Context, client *h
 
 	// Wait until we have enough ready connections OR we hit a timeout
 	completed := 0
-	timeout := time.After(types.DialTimeout) // Use standard dial timeout for the whole batch
+	timeout := time.After(d.Runtime.GetConnectTimeout()) // Use the dial timeout for the whole batch
 
 	for completed < numRequired {
 		select {
 		case <-ready:
 			completed++
 		case <-timeout:
-			utils.Debug("Pre-warming timed out after %d/%d connections", completed, numRequired)
+			utils.DebugFor(d.ID, "Pre-warming timed out after %d/%d connections", completed, numRequired)
 			return
 		case <-ctx.Done():
 			return
 		}
 	}
 
-	utils.Debug("Pre-warming complete: %d connections hot", completed)
+	utils.DebugFor(d.ID, "Pre-warming complete: %d connections hot", completed)
 	// Remaining pings will be cancelled by defer cancelPings()
 }
@@ -119,7 +119,7 @@ func TestSetupTasks_NewDownload(t *testing.T) {
 		Runtime: &types.RuntimeConfig{},
 	}
 
-	tasks, err := downloader.setupTasks(destPath, fileSize, chunkSize, f)
+	tasks, resumed, err := downloader.setupTasks(destPath, fileSize, chunkSize, f)
 	if err != nil {
 		t.Fatalf("setupTasks failed: %v", err)
 	}
@@ -127,6 +127,50 @@ func TestSetupTasks_NewDownload(t *testing.T) {
 	if len(tasks) != 2 {
 		t.Errorf("Expected 2 tasks, got %d", len(tasks))
 	}
+	if resumed {
+		t.Error("setupTasks reported a resume for a new download")
+	}
+}
+
+func TestSetupTasks_SkipsEarlyBytes(t *testing.T) {
+	tmpDir, cleanup := initTestState(t)
+	defer cleanup()
+
+	fileSize := int64(1000)
+	destPath := filepath.Join(tmpDir, "early.bin")
+
+	f, err := os.Create(destPath + types.IncompleteSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	state := types.NewProgressState("early-id", fileSize)
+	state.InitBitmap(fileSize, 100)
+	downloader := &ConcurrentDownloader{
+		ID:         "early-id",
+		State:      state,
+		Runtime:    &types.RuntimeConfig{},
+		EarlyBytes: 300,
+	}
+
+	tasks, _, err := downloader.setupTasks(destPath, fileSize, 500, f)
+	if err != nil {
+		t.Fatalf("setupTasks failed: %v", err)
+	}
+
+	if len(tasks) != 2 {
+		t.Fatalf("Expected 2 tasks, got %d", len(tasks))
+	}
+	if tasks[0].Offset != 300 || tasks[0].Length != 500 {
+		t.Errorf("first task = %+v, want offset 300 length 500", tasks[0])
+	}
+	if tasks[1].Offset != 800 || tasks[1].Length != 200 {
+		t.Errorf("second task = %+v, want offset 800 length 200", tasks[1])
+	}
+	if got := state.Downloaded.Load(); got != 300 {
+		t.Errorf("Downloaded = %d, want 300", got)
+	}
 }
 
 func TestGetWorkerMirrors(t *testing.T) {
@@ -214,10 +258,13 @@ func TestSetupTasks_BitmapRestoration(t *testing.T) {
 	// 1. InitBitmap
 	progState.InitBitmap(fileSize, chunkSize)
 	// 2. setupTasks (which calls RestoreBitmap)
-	_, err := downloader.setupTasks(destPath, fileSize, chunkSize, f)
+	_, resumed, err := downloader.setupTasks(destPath, fileSize, chunkSize, f)
 	if err != nil {
 		t.Fatal(err)
 	}
+	if !resumed {
+		t.Error("setupTasks did not report restoring the saved state")
+	}
 
 	// Verify bitmap is NOT empty (it should have the restored data)
 	bitmap, _, _, _, _ := progState.GetBitmapSnapshot(false)
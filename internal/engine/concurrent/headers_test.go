@@ -320,6 +320,9 @@ func TestConcurrentDownloader_HeadersForwardedOnRedirect(t *testing.T) {
 	if receivedAuth != "Basic dXNlcjpwYXNz" {
 		t.Errorf("Authorization header not forwarded after redirect. Got: %q", receivedAuth)
 	}
+	if got := progState.GetFinalURL(); got != finalServer.URL+"/file.bin" {
+		t.Errorf("FinalURL = %q, want %q", got, finalServer.URL+"/file.bin")
+	}
 
 	// Verify file was created
 	if err := testutil.VerifyFileSize(destPath+types.IncompleteSuffix, fileSize); err != nil {
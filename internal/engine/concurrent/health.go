@@ -1,6 +1,7 @@
 package concurrent
 
 import (
+	"slices"
 	"time"
 
 	"github.com/SurgeDM/Surge/internal/engine/types"
@@ -75,10 +76,10 @@ func (d *ConcurrentDownloader) checkWorkerHealth() {
 		if stallTimeout > 0 && lastActivity > 0 {
 			timeSinceData := now.Sub(time.Unix(0, lastActivity))
 			if timeSinceData >= stallTimeout {
-				utils.Debug("Health: Worker %d stalled (no data for %v), cancelling",
+				utils.DebugFor(d.ID, "Health: Worker %d stalled (no data for %v), cancelling",
 					workerID, timeSinceData.Truncate(time.Millisecond))
 				if active.Cancel != nil {
-					active.Cancel()
+					active.Cancel(types.ErrStallTimeout)
 				}
 				continue // Already cancelled, skip speed check
 			}
@@ -92,12 +93,53 @@ func (d *ConcurrentDownloader) checkWorkerHealth() {
 			isBelowThreshold := threshold > 0 && workerSpeed > 0 && workerSpeed < threshold*meanSpeed
 
 			if isBelowThreshold {
-				utils.Debug("Health: Worker %d slow (%.2f KB/s vs mean %.2f KB/s), cancelling",
+				utils.DebugFor(d.ID, "Health: Worker %d slow (%.2f KB/s vs mean %.2f KB/s), cancelling",
 					workerID, workerSpeed/float64(types.KB), meanSpeed/float64(types.KB))
 				if active.Cancel != nil {
-					active.Cancel()
+					active.Cancel(nil)
 				}
 			}
 		}
 	}
 }
+
+// resetAfterSleep drops every connection once the machine wakes from sleep.
+// They are dead by then, and waiting for them to time out would hold the
+// download up; each worker hands back the rest of its range and carries on
+// over a fresh connection.
+func (d *ConcurrentDownloader) resetAfterSleep(slept time.Duration) {
+	d.activeMu.Lock()
+	defer d.activeMu.Unlock()
+
+	utils.DebugFor(d.ID, "Health: woke after %v asleep, resetting %d connections", slept.Truncate(time.Second), len(d.activeTasks))
+	d.closeIdleConnections()
+	for _, active := range d.activeTasks {
+		if active.Cancel != nil {
+			active.Cancel(types.ErrSystemWake)
+		}
+	}
+}
+
+// publishConnectionSpeeds records each worker's speed on the progress state,
+// in worker order, and each interface's totals when there are several, so
+// the TUI can show how the connections are doing.
+func (d *ConcurrentDownloader) publishConnectionSpeeds() {
+	if d.State == nil {
+		return
+	}
+
+	d.activeMu.Lock()
+	ids := make([]int, 0, len(d.activeTasks))
+	for id := range d.activeTasks {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	speeds := make([]float64, len(ids))
+	for i, id := range ids {
+		speeds[i] = d.activeTasks[id].GetSpeed()
+	}
+	d.publishLinks()
+	d.activeMu.Unlock()
+
+	d.State.SetConnectionSpeeds(speeds)
+}
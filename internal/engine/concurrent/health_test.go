@@ -2,6 +2,7 @@ package concurrent
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -25,8 +26,8 @@ func TestHealth_LastManStanding(t *testing.T) {
 	// Global is 10MB/s (100MB / 10s)
 	// Worker is 1MB/s (should be < 0.5 * 10 = 5MB/s).
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
 
 	now := time.Now()
 
@@ -73,9 +74,9 @@ func TestHealth_MultipleWorkers(t *testing.T) {
 	// Worker 2: 1 MB/s (Slow)
 	// Mean = 7 MB/s. Threshold = 3.5 MB/s. Worker 2 < 3.5 => Cancel.
 
-	w0Ctx, w0Cancel := context.WithCancel(ctx)
-	w1Ctx, w1Cancel := context.WithCancel(ctx)
-	w2Ctx, w2Cancel := context.WithCancel(ctx)
+	w0Ctx, w0Cancel := context.WithCancelCause(ctx)
+	w1Ctx, w1Cancel := context.WithCancelCause(ctx)
+	w2Ctx, w2Cancel := context.WithCancelCause(ctx)
 
 	d.activeTasks[0] = &ActiveTask{StartTime: now.Add(-10 * time.Second), Speed: 10 * 1024 * 1024, Cancel: w0Cancel}
 	d.activeTasks[1] = &ActiveTask{StartTime: now.Add(-10 * time.Second), Speed: 10 * 1024 * 1024, Cancel: w1Cancel}
@@ -121,8 +122,8 @@ func TestHealth_GracePeriod(t *testing.T) {
 	// Worker 0: 10 MB/s (Old)
 	// Worker 1: 0.1 MB/s (New, within grace period) -> Should NOT cancel despite being slow
 
-	w0Ctx, w0Cancel := context.WithCancel(ctx)
-	w1Ctx, w1Cancel := context.WithCancel(ctx)
+	w0Ctx, w0Cancel := context.WithCancelCause(ctx)
+	w1Ctx, w1Cancel := context.WithCancelCause(ctx)
 
 	d.activeTasks[0] = &ActiveTask{StartTime: now.Add(-10 * time.Second), Speed: 10 * 1024 * 1024, Cancel: w0Cancel}
 	d.activeTasks[1] = &ActiveTask{StartTime: now.Add(-1 * time.Second), Speed: 100 * 1024, Cancel: w1Cancel}
@@ -162,7 +163,7 @@ func TestHealth_StallDetection(t *testing.T) {
 	now := time.Now()
 
 	// Worker with last activity 2 seconds ago (exceeds 1s StallTimeout)
-	stalledCtx, stalledCancel := context.WithCancel(ctx)
+	stalledCtx, stalledCancel := context.WithCancelCause(ctx)
 	active := &ActiveTask{
 		StartTime: now.Add(-10 * time.Second),
 		Cancel:    stalledCancel,
@@ -177,6 +178,9 @@ func TestHealth_StallDetection(t *testing.T) {
 	select {
 	case <-stalledCtx.Done():
 		// Success: stall detected and cancelled
+		if cause := context.Cause(stalledCtx); !errors.Is(cause, types.ErrStallTimeout) {
+			t.Errorf("cause = %v, want ErrStallTimeout", cause)
+		}
 	default:
 		t.Error("Stalled worker should have been cancelled")
 	}
@@ -196,7 +200,7 @@ func TestHealth_ZeroStallTimeoutDisablesStallDetection(t *testing.T) {
 
 	now := time.Now()
 
-	stalledCtx, stalledCancel := context.WithCancel(ctx)
+	stalledCtx, stalledCancel := context.WithCancelCause(ctx)
 	active := &ActiveTask{
 		StartTime: now.Add(-10 * time.Second),
 		Cancel:    stalledCancel,
@@ -229,8 +233,8 @@ func TestHealth_ZeroSlowWorkerThresholdDisablesSlowCheck(t *testing.T) {
 
 	now := time.Now()
 
-	_, w0Cancel := context.WithCancel(ctx)
-	w1Ctx, w1Cancel := context.WithCancel(ctx)
+	_, w0Cancel := context.WithCancelCause(ctx)
+	w1Ctx, w1Cancel := context.WithCancelCause(ctx)
 
 	d.activeTasks[0] = &ActiveTask{StartTime: now.Add(-10 * time.Second), Speed: 10 * 1024 * 1024, Cancel: w0Cancel}
 	d.activeTasks[1] = &ActiveTask{StartTime: now.Add(-10 * time.Second), Speed: 1 * 1024 * 1024, Cancel: w1Cancel}
@@ -245,3 +249,45 @@ func TestHealth_ZeroSlowWorkerThresholdDisablesSlowCheck(t *testing.T) {
 		// Success
 	}
 }
+
+func TestHealth_PublishConnectionSpeeds(t *testing.T) {
+	state := types.NewProgressState("test", 1000)
+	d := NewConcurrentDownloader("test", nil, state, &types.RuntimeConfig{})
+
+	d.activeTasks[2] = &ActiveTask{Speed: 300}
+	d.activeTasks[0] = &ActiveTask{Speed: 100}
+	d.activeTasks[1] = &ActiveTask{Speed: 200}
+	d.publishConnectionSpeeds()
+
+	got := state.GetConnectionSpeeds()
+	want := []float64{100, 200, 300}
+	if len(got) != len(want) {
+		t.Fatalf("speeds = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("speeds = %v, want %v in worker order", got, want)
+		}
+	}
+}
+
+func TestHealth_ResetAfterSleepCancelsEveryTask(t *testing.T) {
+	d := NewConcurrentDownloader("test", nil, types.NewProgressState("test", 1000), nil)
+
+	var contexts []context.Context
+	for id := range 3 {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		defer cancel(nil)
+		contexts = append(contexts, ctx)
+		// Busy workers too: after a sleep their connections are just as dead
+		d.activeTasks[id] = &ActiveTask{StartTime: time.Now(), Speed: 10 * 1024 * 1024, Cancel: cancel}
+	}
+
+	d.resetAfterSleep(time.Hour)
+
+	for id, ctx := range contexts {
+		if cause := context.Cause(ctx); !errors.Is(cause, types.ErrSystemWake) {
+			t.Errorf("worker %d: cause = %v, want ErrSystemWake", id, cause)
+		}
+	}
+}
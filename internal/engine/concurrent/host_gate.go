@@ -0,0 +1,131 @@
+package concurrent
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// hostGate limits how many workers talk to a host at once after that host
+// starts answering 429 or 503. Hosts that never throttle are not limited, and
+// a nil gate limits nothing.
+type hostGate struct {
+	mu    sync.Mutex
+	hosts map[string]*hostSlot
+}
+
+type hostSlot struct {
+	active    int
+	limit     int       // 0 means unlimited
+	resumeAt  time.Time // no new requests before this
+	restoreAt time.Time // limit lifts after this
+	changed   chan struct{}
+}
+
+func newHostGate() *hostGate {
+	return &hostGate{hosts: make(map[string]*hostSlot)}
+}
+
+func gateHost(rawurl string) string {
+	if u, err := url.Parse(rawurl); err == nil {
+		return u.Host
+	}
+	return rawurl
+}
+
+func (g *hostGate) slot(host string) *hostSlot {
+	s, ok := g.hosts[host]
+	if !ok {
+		s = &hostSlot{changed: make(chan struct{})}
+		g.hosts[host] = s
+	}
+	return s
+}
+
+// notify wakes every worker waiting on s. Callers hold g.mu.
+func (s *hostSlot) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// acquire blocks until host is out of its Retry-After window and below its
+// connection limit, then takes a slot. Release it with release.
+func (g *hostGate) acquire(ctx context.Context, host string) error {
+	if g == nil {
+		return ctx.Err()
+	}
+	for {
+		g.mu.Lock()
+		s := g.slot(host)
+		now := time.Now()
+		if s.limit > 0 && !now.Before(s.restoreAt) {
+			s.limit = 0
+		}
+
+		var wait time.Duration
+		switch {
+		case now.Before(s.resumeAt):
+			wait = s.resumeAt.Sub(now)
+		case s.limit == 0 || s.active < s.limit:
+			s.active++
+			g.mu.Unlock()
+			return nil
+		default:
+			wait = s.restoreAt.Sub(now)
+		}
+		changed := s.changed
+		g.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-changed:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+func (g *hostGate) release(host string) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	s := g.slot(host)
+	if s.active > 0 {
+		s.active--
+	}
+	s.notify()
+}
+
+// throttled records that host asked for a pause of wait. New requests hold off
+// until it passes, and the host's connection count is halved until
+// types.ThrottleCooldown after that.
+func (g *hostGate) throttled(host string, wait time.Duration) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	s := g.slot(host)
+	now := time.Now()
+
+	if resume := now.Add(wait); resume.After(s.resumeAt) {
+		s.resumeAt = resume
+	}
+	base := s.limit
+	if base == 0 {
+		base = s.active
+	}
+	s.limit = max(base/2, 1)
+	s.restoreAt = s.resumeAt.Add(types.ThrottleCooldown)
+	utils.Debug("Host %s throttled: waiting %v, limit %d connections until %s", host, wait, s.limit, s.restoreAt.Format(time.TimeOnly))
+	s.notify()
+}
@@ -0,0 +1,78 @@
+package concurrent
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHostGate_WaitsOutRetryAfter(t *testing.T) {
+	g := newHostGate()
+	g.throttled("busy.example", 150*time.Millisecond)
+
+	start := time.Now()
+	if err := g.acquire(context.Background(), "busy.example"); err != nil {
+		t.Fatal(err)
+	}
+	g.release("busy.example")
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("acquire returned after %v, before the Retry-After window ended", elapsed)
+	}
+
+	// Other hosts are not held back
+	start = time.Now()
+	if err := g.acquire(context.Background(), "calm.example"); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("unthrottled host waited %v", elapsed)
+	}
+}
+
+func TestHostGate_HalvesConnections(t *testing.T) {
+	g := newHostGate()
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		if err := g.acquire(ctx, "h"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g.throttled("h", 0)
+	for i := 0; i < 4; i++ {
+		g.release("h")
+	}
+
+	// Four connections were open when the host pushed back, so two are allowed now
+	for i := 0; i < 2; i++ {
+		if err := g.acquire(ctx, "h"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	blocked, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if err := g.acquire(blocked, "h"); err == nil {
+		t.Fatal("third connection should wait while the host is throttled")
+	}
+
+	// Freeing a slot lets a waiting worker in
+	done := make(chan error, 1)
+	go func() { done <- g.acquire(ctx, "h") }()
+	g.release("h")
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiting worker was not woken by release")
+	}
+}
+
+func TestHostGate_NilIsUnlimited(t *testing.T) {
+	var g *hostGate
+	g.throttled("h", time.Hour)
+	if err := g.acquire(context.Background(), "h"); err != nil {
+		t.Fatal(err)
+	}
+	g.release("h")
+}
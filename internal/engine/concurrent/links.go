@@ -0,0 +1,120 @@
+package concurrent
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/SurgeDM/Surge/internal/engine"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+// link is one interface a download's connections go out over. A download
+// bound to several interfaces, e.g. wlan0+eth0, gets a link per interface
+// and deals its workers out across them in turn; workers on the faster
+// link take more tasks from the shared queue, so both are kept busy.
+type link struct {
+	iface     string // "" when the download is not spread over interfaces
+	client    *http.Client
+	transport *http.Transport
+	bytes     atomic.Int64 // Read over this link this session
+}
+
+// setupNetwork acquires a transport for each link and returns the client
+// of the first, which also handles requests that are not a worker's.
+func (d *ConcurrentDownloader) setupNetwork() (*http.Client, error) {
+	ifaces := types.SplitBindInterfaces(d.Runtime.BindInterface)
+	if len(ifaces) < 2 {
+		ifaces = []string{""}
+	}
+
+	links := make([]*link, 0, len(ifaces))
+	for _, iface := range ifaces {
+		runtime := d.Runtime
+		if iface != "" {
+			bound := *d.Runtime
+			bound.BindInterface = iface
+			runtime = &bound
+		}
+		transport, err := engine.DefaultNetworkPool.AcquireTransportFor(runtime, types.PoolMaxConnsPerHost)
+		if err != nil {
+			for _, l := range links {
+				engine.DefaultNetworkPool.ReleaseTransport(l.transport)
+			}
+			return nil, fmt.Errorf("failed to configure TLS: %w", err)
+		}
+		client := &http.Client{Transport: transport}
+		d.applyClientSettings(client)
+		links = append(links, &link{iface: iface, client: client, transport: transport})
+	}
+	d.links = links
+	return links[0].client, nil
+}
+
+// releaseNetwork hands every link's transport back to the pool.
+func (d *ConcurrentDownloader) releaseNetwork() {
+	for _, l := range d.links {
+		engine.DefaultNetworkPool.ReleaseTransport(l.transport)
+	}
+}
+
+// linkFor returns the link worker id connects over, or nil before the
+// network is set up.
+func (d *ConcurrentDownloader) linkFor(id int) *link {
+	if len(d.links) == 0 {
+		return nil
+	}
+	return d.links[id%len(d.links)]
+}
+
+// closeIdleConnections drops the idle connections of every link.
+func (d *ConcurrentDownloader) closeIdleConnections() {
+	for _, l := range d.links {
+		l.transport.CloseIdleConnections()
+	}
+}
+
+// publishLinks records per-interface totals on the progress state for a
+// download spread over several interfaces. The caller holds activeMu.
+func (d *ConcurrentDownloader) publishLinks() {
+	if d.State == nil || len(d.links) < 2 {
+		return
+	}
+	stats := make([]types.LinkStatus, len(d.links))
+	for i, l := range d.links {
+		stats[i] = types.LinkStatus{Interface: l.iface, Bytes: l.bytes.Load()}
+	}
+	for id, active := range d.activeTasks {
+		s := &stats[id%len(d.links)]
+		s.Connections++
+		s.Speed += active.GetSpeed()
+	}
+	d.State.SetLinks(stats)
+}
+
+// prewarmLinks pre-warms numConns connections, each link its share of them
+// alongside the others.
+func (d *ConcurrentDownloader) prewarmLinks(ctx context.Context, client *http.Client, numConns, hedgeCount int, mirrors []string) {
+	if len(d.links) < 2 {
+		d.prewarmConnections(ctx, client, numConns, hedgeCount, mirrors)
+		return
+	}
+	var wg sync.WaitGroup
+	for i, l := range d.links {
+		share := numConns / len(d.links)
+		if i < numConns%len(d.links) {
+			share++
+		}
+		if share == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.prewarmConnections(ctx, l.client, share, hedgeCount, mirrors)
+		}()
+	}
+	wg.Wait()
+}
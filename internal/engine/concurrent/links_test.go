@@ -0,0 +1,72 @@
+package concurrent
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func TestConcurrentDownloader_SpreadsConnectionsAcrossInterfaces(t *testing.T) {
+	// Linux routes all of 127/8 to loopback, which gives two local addresses
+	if l, err := net.Listen("tcp", "127.0.0.2:0"); err != nil {
+		t.Skipf("127.0.0.2 is not a local address here: %v", err)
+	} else {
+		_ = l.Close()
+	}
+
+	const size = 4 * types.MB
+	content := bytes.Repeat([]byte("surge"), size/5+1)[:size]
+	var mu sync.Mutex
+	sources := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, _ := net.SplitHostPort(r.RemoteAddr)
+		mu.Lock()
+		sources[host]++
+		mu.Unlock()
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	state := types.NewProgressState("links", size)
+	d := NewConcurrentDownloader("links", nil, state, &types.RuntimeConfig{
+		MaxConnectionsPerDownload: 4,
+		MinChunkSize:              256 * types.KB,
+		BindInterface:             "127.0.0.1+127.0.0.2",
+	})
+
+	destPath := filepath.Join(t.TempDir(), "file.bin")
+	if err := os.WriteFile(destPath+types.IncompleteSuffix, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := d.Download(ctx, server.URL, nil, nil, destPath, size); err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+
+	got, err := os.ReadFile(destPath + types.IncompleteSuffix)
+	if err != nil || !bytes.Equal(got, content) {
+		t.Fatalf("downloaded file does not match (err %v)", err)
+	}
+	if sources["127.0.0.1"] == 0 || sources["127.0.0.2"] == 0 {
+		t.Fatalf("requests came from %v, want both interfaces", sources)
+	}
+
+	d.publishConnectionSpeeds()
+	links := state.GetLinks()
+	if len(links) != 2 || links[0].Interface != "127.0.0.1" || links[1].Interface != "127.0.0.2" {
+		t.Fatalf("links = %+v, want one per interface", links)
+	}
+	if links[0].Bytes == 0 || links[1].Bytes == 0 || links[0].Bytes+links[1].Bytes != size {
+		t.Fatalf("link bytes = %d + %d, want both used and %d in all", links[0].Bytes, links[1].Bytes, size)
+	}
+}
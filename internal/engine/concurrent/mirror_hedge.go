@@ -0,0 +1,97 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// hedgedResult is the outcome of one mirror's leg of a hedged request.
+type hedgedResult struct {
+	idx  int
+	resp *http.Response
+	err  error
+}
+
+// openHedged sends the same range request to up to MirrorHedgeCount mirrors,
+// starting at mirrors[start], and returns the first successful response along
+// with the index of the mirror that served it. The other legs are cancelled
+// and their responses discarded, so one slow mirror cannot hold up a
+// worker's first byte.
+//
+// Every leg runs under ctx, so cancelling the task context also aborts the
+// winning response. The winner's own context is released when its body is
+// closed.
+func (d *ConcurrentDownloader) openHedged(ctx context.Context, mirrors []string, start int, task types.Task, client *http.Client, totalSize int64) (*http.Response, int, error) {
+	n := min(d.Runtime.GetMirrorHedgeCount(), len(mirrors))
+	if n < 2 {
+		resp, err := d.openRange(ctx, mirrors[start], task, client, totalSize)
+		return resp, start, err
+	}
+
+	results := make(chan hedgedResult, n)
+	cancels := make(map[int]context.CancelFunc, n)
+	for i := 0; i < n; i++ {
+		idx := (start + i) % len(mirrors)
+		legCtx, cancel := context.WithCancel(ctx)
+		cancels[idx] = cancel
+		go func() {
+			resp, err := d.openRange(legCtx, mirrors[idx], task, client, totalSize)
+			results <- hedgedResult{idx: idx, resp: resp, err: err}
+		}()
+	}
+
+	var firstErr error
+	for received := 1; received <= n; received++ {
+		r := <-results
+		if r.err != nil {
+			cancels[r.idx]()
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			if ctx.Err() == nil && !errors.Is(r.err, context.Canceled) {
+				utils.DebugFor(d.ID, "Hedged request to %s failed: %v", mirrors[r.idx], r.err)
+				d.ReportMirrorError(mirrors[r.idx])
+			}
+			continue
+		}
+
+		for idx, cancel := range cancels {
+			if idx != r.idx {
+				cancel()
+			}
+		}
+		go drainHedgedLosers(results, n-received)
+		utils.DebugFor(d.ID, "Hedged request won by %s", mirrors[r.idx])
+		r.resp.Body = &cancelOnClose{ReadCloser: r.resp.Body, cancel: cancels[r.idx]}
+		return r.resp, r.idx, nil
+	}
+
+	return nil, start, firstErr
+}
+
+// cancelOnClose releases the context of a hedged leg once its response body
+// is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// drainHedgedLosers closes any responses that arrive after a winner was chosen.
+func drainHedgedLosers(results <-chan hedgedResult, pending int) {
+	for i := 0; i < pending; i++ {
+		if r := <-results; r.resp != nil {
+			_ = r.resp.Body.Close()
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package concurrent
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/testutil"
+)
+
+func TestOpenHedged_FastestMirrorWins(t *testing.T) {
+	fileSize := int64(64 * types.KB)
+	slow := testutil.NewMockServerT(t,
+		testutil.WithFileSize(fileSize),
+		testutil.WithRangeSupport(true),
+		testutil.WithLatency(500*time.Millisecond),
+	)
+	defer slow.Close()
+	fast := testutil.NewMockServerT(t,
+		testutil.WithFileSize(fileSize),
+		testutil.WithRangeSupport(true),
+	)
+	defer fast.Close()
+
+	d := NewConcurrentDownloader("hedge-fast", nil, nil, &types.RuntimeConfig{MirrorHedgeCount: 2})
+	mirrors := []string{slow.URL(), fast.URL()}
+
+	start := time.Now()
+	resp, idx, err := d.openHedged(context.Background(), mirrors, 0, types.Task{Offset: 0, Length: fileSize}, http.DefaultClient, fileSize)
+	if err != nil {
+		t.Fatalf("openHedged failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if idx != 1 {
+		t.Errorf("winner = %d, want the fast mirror (1)", idx)
+	}
+	if elapsed := time.Since(start); elapsed >= 500*time.Millisecond {
+		t.Errorf("openHedged waited %v for the slow mirror", elapsed)
+	}
+}
+
+func TestOpenHedged_ClosingWinnerReleasesItsContext(t *testing.T) {
+	fileSize := int64(64 * types.KB)
+	a := testutil.NewMockServerT(t, testutil.WithFileSize(fileSize), testutil.WithRangeSupport(true))
+	defer a.Close()
+	b := testutil.NewMockServerT(t, testutil.WithFileSize(fileSize), testutil.WithRangeSupport(true))
+	defer b.Close()
+
+	d := NewConcurrentDownloader("hedge-close", nil, nil, &types.RuntimeConfig{MirrorHedgeCount: 2})
+	resp, _, err := d.openHedged(context.Background(), []string{a.URL(), b.URL()}, 0, types.Task{Offset: 0, Length: fileSize}, http.DefaultClient, fileSize)
+	if err != nil {
+		t.Fatalf("openHedged failed: %v", err)
+	}
+	legCtx := resp.Request.Context()
+	if legCtx.Err() != nil {
+		t.Fatal("winning leg was cancelled before its body was closed")
+	}
+	_ = resp.Body.Close()
+	if legCtx.Err() == nil {
+		t.Error("closing the winning body left its context running")
+	}
+}
+
+func TestOpenHedged_FailedMirrorIsReported(t *testing.T) {
+	fileSize := int64(64 * types.KB)
+	bad := testutil.NewHTTPServerT(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer bad.Close()
+	good := testutil.NewMockServerT(t,
+		testutil.WithFileSize(fileSize),
+		testutil.WithRangeSupport(true),
+		testutil.WithLatency(50*time.Millisecond),
+	)
+	defer good.Close()
+
+	state := types.NewProgressState("hedge-report", fileSize)
+	state.SetMirrors([]types.MirrorStatus{
+		{URL: bad.URL, Active: true},
+		{URL: good.URL(), Active: true},
+	})
+	d := NewConcurrentDownloader("hedge-report", nil, state, &types.RuntimeConfig{MirrorHedgeCount: 2})
+	mirrors := []string{bad.URL, good.URL()}
+
+	resp, idx, err := d.openHedged(context.Background(), mirrors, 0, types.Task{Offset: 0, Length: fileSize}, http.DefaultClient, fileSize)
+	if err != nil {
+		t.Fatalf("openHedged failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if idx != 1 {
+		t.Errorf("winner = %d, want the healthy mirror (1)", idx)
+	}
+	for _, m := range state.GetMirrors() {
+		if m.URL == bad.URL && !m.Error {
+			t.Error("failing mirror should be marked with an error")
+		}
+		if m.URL == good.URL() && m.Error {
+			t.Error("winning mirror should not be marked with an error")
+		}
+	}
+}
+
+func TestOpenHedged_AllMirrorsFail(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+	bad1 := testutil.NewHTTPServerT(t, handler)
+	defer bad1.Close()
+	bad2 := testutil.NewHTTPServerT(t, handler)
+	defer bad2.Close()
+
+	d := NewConcurrentDownloader("hedge-fail", nil, nil, &types.RuntimeConfig{MirrorHedgeCount: 2})
+	resp, _, err := d.openHedged(context.Background(), []string{bad1.URL, bad2.URL}, 0, types.Task{Offset: 0, Length: 1024}, http.DefaultClient, 1024)
+	if err == nil {
+		_ = resp.Body.Close()
+		t.Fatal("expected an error when every mirror fails")
+	}
+}
+
+func TestOpenHedged_DisabledUsesStartMirror(t *testing.T) {
+	fileSize := int64(64 * types.KB)
+	first := testutil.NewMockServerT(t, testutil.WithFileSize(fileSize), testutil.WithRangeSupport(true))
+	defer first.Close()
+	second := testutil.NewMockServerT(t, testutil.WithFileSize(fileSize), testutil.WithRangeSupport(true))
+	defer second.Close()
+
+	d := NewConcurrentDownloader("hedge-off", nil, nil, &types.RuntimeConfig{})
+	resp, idx, err := d.openHedged(context.Background(), []string{first.URL(), second.URL()}, 1, types.Task{Offset: 0, Length: fileSize}, http.DefaultClient, fileSize)
+	if err != nil {
+		t.Fatalf("openHedged failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if idx != 1 {
+		t.Errorf("winner = %d, want the start mirror (1)", idx)
+	}
+	if got := first.Stats().TotalRequests; got != 0 {
+		t.Errorf("first mirror requests = %d, want 0 with hedging disabled", got)
+	}
+}
+
+func TestMirrors_HedgedFirstRequestAvoidsSlowMirror(t *testing.T) {
+	tmpDir, cleanup := initTestState(t)
+	defer cleanup()
+
+	fileSize := int64(256 * types.KB)
+	slow := testutil.NewMockServerT(t,
+		testutil.WithFileSize(fileSize),
+		testutil.WithRangeSupport(true),
+		testutil.WithLatency(300*time.Millisecond),
+	)
+	defer slow.Close()
+	fast := testutil.NewMockServerT(t,
+		testutil.WithFileSize(fileSize),
+		testutil.WithRangeSupport(true),
+	)
+	defer fast.Close()
+
+	destPath := filepath.Join(tmpDir, "hedged_mirror.bin")
+	if f, err := os.Create(destPath + types.IncompleteSuffix); err == nil {
+		_ = f.Close()
+	}
+
+	runtime := &types.RuntimeConfig{
+		MaxConnectionsPerDownload: 1,
+		SequentialDownload:        true,
+		MinChunkSize:              64 * types.KB,
+		MirrorHedgeCount:          2,
+	}
+	state := types.NewProgressState("hedged-mirror", fileSize)
+	downloader := NewConcurrentDownloader("hedged-mirror-id", nil, state, runtime)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	mirrors := []string{slow.URL(), fast.URL()}
+	if err := downloader.Download(ctx, slow.URL(), mirrors, mirrors, destPath, fileSize); err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	if err := testutil.VerifyFileSize(destPath+types.IncompleteSuffix, fileSize); err != nil {
+		t.Error(err)
+	}
+
+	// Only the raced first request reaches the slow mirror; the worker then
+	// stays on the mirror that answered first.
+	if got := slow.Stats().TotalRequests; got > 1 {
+		t.Errorf("slow mirror requests = %d, want at most 1", got)
+	}
+	if got := fast.Stats().TotalRequests; got < 4 {
+		t.Errorf("fast mirror requests = %d, want every chunk (>= 4)", got)
+	}
+}
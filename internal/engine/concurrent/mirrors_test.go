@@ -128,3 +128,25 @@ func TestMirrors_Failover(t *testing.T) {
 		t.Error("Expected good server to handle requests after failover")
 	}
 }
+
+func TestNextMirror_SkipsFailedMirrors(t *testing.T) {
+	mirrors := []string{"https://a.example/f", "https://b.example/f", "https://c.example/f"}
+	state := types.NewProgressState("next-mirror", 0)
+	state.SetMirrors([]types.MirrorStatus{
+		{URL: mirrors[0], Active: true},
+		{URL: mirrors[1], Active: true, Error: true},
+		{URL: mirrors[2], Active: true},
+	})
+	d := NewConcurrentDownloader("next-mirror", nil, state, nil)
+
+	if got := d.nextMirror(mirrors, 0); got != 2 {
+		t.Errorf("nextMirror from a = %d, want 2 (skipping failed b)", got)
+	}
+
+	// With every other mirror failed, rotation still moves on.
+	d.ReportMirrorError(mirrors[2])
+	d.ReportMirrorError(mirrors[0])
+	if got := d.nextMirror(mirrors, 0); got != 1 {
+		t.Errorf("nextMirror with all failed = %d, want 1", got)
+	}
+}
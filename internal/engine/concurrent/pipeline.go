@@ -0,0 +1,171 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// pipeline lets a worker issue the range request for its next task while the
+// tail of the current response is still being written. On high-RTT links this
+// hides the request round trip between consecutive chunks.
+//
+// Go's HTTP/1.1 client does not pipeline on a single connection, so the
+// read-ahead request rides a second pooled connection. Any failure on a
+// read-ahead request turns pipelining off for the rest of the download and the
+// worker falls back to a regular request for that task.
+type pipeline struct {
+	d         *ConcurrentDownloader
+	ctx       context.Context
+	queue     *TaskQueue
+	client    *http.Client
+	totalSize int64
+
+	// next is owned by the worker goroutine; it is never shared.
+	next *pipelinedRequest
+}
+
+// pipelinedRequest is a range request issued ahead of the task that will use it.
+type pipelinedRequest struct {
+	task   types.Task
+	url    string
+	cancel context.CancelFunc
+	done   chan struct{}
+	resp   *http.Response
+	err    error
+}
+
+// pipelinedBody releases the read-ahead request context once the worker is
+// done with the response body.
+type pipelinedBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *pipelinedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
+
+func newPipeline(ctx context.Context, d *ConcurrentDownloader, queue *TaskQueue, client *http.Client, totalSize int64) *pipeline {
+	if !d.Runtime.GetPipelineRequests() {
+		return nil
+	}
+	return &pipeline{
+		d:         d,
+		ctx:       ctx,
+		queue:     queue,
+		client:    client,
+		totalSize: totalSize,
+	}
+}
+
+// prefetch issues the next task's request once the current range is within
+// PipelineReadAhead bytes of its end. It never takes work that an idle worker
+// is waiting for.
+func (p *pipeline) prefetch(rawurl string, remaining int64) {
+	if p == nil || p.next != nil || remaining > types.PipelineReadAhead {
+		return
+	}
+	if p.d.pipelineOff.Load() || p.queue.IdleWorkers() > 0 {
+		return
+	}
+
+	task, ok := p.queue.TryPop()
+	if !ok {
+		return
+	}
+
+	reqCtx, cancel := context.WithCancel(p.ctx)
+	req := &pipelinedRequest{
+		task:   task,
+		url:    rawurl,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	p.next = req
+
+	go func() {
+		defer close(req.done)
+		req.resp, req.err = p.d.openRange(reqCtx, rawurl, task, p.client, p.totalSize)
+	}()
+}
+
+// take hands the pending read-ahead request to the worker, if there is one.
+func (p *pipeline) take() *pipelinedRequest {
+	if p == nil {
+		return nil
+	}
+	req := p.next
+	p.next = nil
+	return req
+}
+
+// await waits for a read-ahead request and returns its response bound to ctx,
+// or nil if the worker should issue a fresh request instead.
+func (p *pipeline) await(ctx context.Context, req *pipelinedRequest, rawurl string) *http.Response {
+	if req.url != rawurl {
+		// The worker moved to another mirror since the request went out.
+		req.discard()
+		return nil
+	}
+
+	select {
+	case <-req.done:
+	case <-ctx.Done():
+		req.discard()
+		return nil
+	}
+
+	if req.err != nil {
+		req.cancel()
+		if !errors.Is(req.err, context.Canceled) {
+			p.d.disablePipelining(req.err)
+		}
+		return nil
+	}
+
+	// Health monitor and pause cancel the task context, so it must also
+	// abort the read-ahead request that now backs this task.
+	stop := context.AfterFunc(ctx, req.cancel)
+	req.resp.Body = &pipelinedBody{
+		ReadCloser: req.resp.Body,
+		release: func() {
+			stop()
+			req.cancel()
+		},
+	}
+	return req.resp
+}
+
+// abandon cancels an unused read-ahead request and returns its task to the
+// queue so pause and retry logic still see it.
+func (p *pipeline) abandon() {
+	req := p.take()
+	if req == nil {
+		return
+	}
+	req.discard()
+	p.queue.Push(req.task)
+}
+
+// discard cancels the request and closes any response that already arrived.
+func (req *pipelinedRequest) discard() {
+	req.cancel()
+	<-req.done
+	if req.resp != nil {
+		_ = req.resp.Body.Close()
+	}
+}
+
+// disablePipelining turns off read-ahead requests for the rest of the download.
+func (d *ConcurrentDownloader) disablePipelining(reason error) {
+	if d.pipelineOff.CompareAndSwap(false, true) {
+		utils.DebugFor(d.ID, "Download %s: disabling request pipelining: %v", d.ID, reason)
+	}
+}
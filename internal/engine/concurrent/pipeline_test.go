@@ -0,0 +1,189 @@
+package concurrent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/testutil"
+)
+
+// newPipelineTestServer serves a deterministic payload in small slices so a
+// range response stays open long enough for a read-ahead request to overlap.
+// reject, when set, decides per request number whether to answer 503.
+func newPipelineTestServer(t *testing.T, data []byte, reject func(n int64) bool) (string, *atomic.Int64, *atomic.Int64) {
+	t.Helper()
+
+	var requests, maxActive, active atomic.Int64
+	server := testutil.NewHTTPServerT(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		if reject != nil && reject(n) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		cur := active.Add(1)
+		defer active.Add(-1)
+		for {
+			prev := maxActive.Load()
+			if cur <= prev || maxActive.CompareAndSwap(prev, cur) {
+				break
+			}
+		}
+
+		var start, end int64
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end); err != nil {
+			http.Error(w, "bad range", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", end-start+1))
+		w.WriteHeader(http.StatusPartialContent)
+
+		const slice = 8 * types.KB
+		for off := start; off <= end; off += slice {
+			stop := off + slice
+			if stop > end+1 {
+				stop = end + 1
+			}
+			if _, err := w.Write(data[off:stop]); err != nil {
+				return
+			}
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+			time.Sleep(2 * time.Millisecond)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server.URL, &requests, &maxActive
+}
+
+func pipelineTestData(size int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	return data
+}
+
+func runPipelineDownload(t *testing.T, url string, fileSize int64) (*ConcurrentDownloader, string) {
+	t.Helper()
+
+	tmpDir, cleanup := initTestState(t)
+	t.Cleanup(cleanup)
+
+	destPath := filepath.Join(tmpDir, "pipeline_test.bin")
+	if f, err := os.Create(destPath + types.IncompleteSuffix); err == nil {
+		_ = f.Close()
+	}
+
+	runtime := &types.RuntimeConfig{
+		MaxConnectionsPerDownload: 1,
+		SequentialDownload:        true,
+		MinChunkSize:              32 * types.KB,
+		WorkerBufferSize:          8 * types.KB,
+		DialHedgeCount:            0,
+		PipelineRequests:          true,
+	}
+	state := types.NewProgressState("pipeline-test", fileSize)
+	downloader := NewConcurrentDownloader("pipeline-id", nil, state, runtime)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := downloader.Download(ctx, url, nil, nil, destPath, fileSize); err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	return downloader, destPath + types.IncompleteSuffix
+}
+
+func TestConcurrentDownloader_PipelineRequests(t *testing.T) {
+	data := pipelineTestData(256 * types.KB)
+	url, requests, maxActive := newPipelineTestServer(t, data, nil)
+
+	downloader, path := runPipelineDownload(t, url, int64(len(data)))
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatal("downloaded content does not match served data")
+	}
+
+	// A single worker only overlaps requests when it reads ahead.
+	if maxActive.Load() < 2 {
+		t.Errorf("max concurrent requests = %d, want read-ahead overlap (>= 2)", maxActive.Load())
+	}
+	// 256KB in 32KB sequential chunks: every chunk is requested exactly once.
+	if requests.Load() != 8 {
+		t.Errorf("requests = %d, want 8", requests.Load())
+	}
+	if downloader.pipelineOff.Load() {
+		t.Error("pipelining should stay enabled when the server accepts it")
+	}
+}
+
+func TestConcurrentDownloader_PipelineFallsBackWhenRejected(t *testing.T) {
+	data := pipelineTestData(256 * types.KB)
+	// Request #2 is the first read-ahead request, issued while #1 is streaming.
+	url, requests, _ := newPipelineTestServer(t, data, func(n int64) bool { return n == 2 })
+
+	downloader, path := runPipelineDownload(t, url, int64(len(data)))
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatal("downloaded content does not match served data")
+	}
+
+	if !downloader.pipelineOff.Load() {
+		t.Error("pipelining should be disabled after the server rejects a read-ahead request")
+	}
+	// The rejected chunk is fetched again with a regular request.
+	if requests.Load() != 9 {
+		t.Errorf("requests = %d, want 9", requests.Load())
+	}
+}
+
+func TestPipeline_AbandonRequeuesTask(t *testing.T) {
+	data := pipelineTestData(64 * types.KB)
+	url, _, _ := newPipelineTestServer(t, data, nil)
+
+	d := NewConcurrentDownloader("abandon-id", nil, nil, &types.RuntimeConfig{PipelineRequests: true})
+	queue := NewTaskQueue()
+	queue.Push(types.Task{Offset: 32 * types.KB, Length: 32 * types.KB})
+
+	pipe := newPipeline(context.Background(), d, queue, http.DefaultClient, int64(len(data)))
+	pipe.prefetch(url, 0)
+	if queue.Len() != 0 {
+		t.Fatalf("prefetch should take the queued task, queue len = %d", queue.Len())
+	}
+
+	pipe.abandon()
+
+	task, ok := queue.TryPop()
+	if !ok {
+		t.Fatal("abandoned task was not requeued")
+	}
+	if task.Offset != 32*types.KB || task.Length != 32*types.KB {
+		t.Errorf("requeued task = %+v", task)
+	}
+}
+
+func TestPipeline_DisabledByDefault(t *testing.T) {
+	d := NewConcurrentDownloader("off-id", nil, nil, &types.RuntimeConfig{})
+	if pipe := newPipeline(context.Background(), d, NewTaskQueue(), http.DefaultClient, 0); pipe != nil {
+		t.Error("newPipeline should return nil when PipelineRequests is off")
+	}
+}
@@ -0,0 +1,83 @@
+package concurrent
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/SurgeDM/Surge/internal/engine"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// resumeCheckBytes is how much already-downloaded data a resume fetches again
+// to make sure the remote file is still the one the partial came from.
+const resumeCheckBytes = 64 * types.KB
+
+// completedSample returns up to n bytes at the start of the first range the
+// saved tasks no longer cover, i.e. data that is already on disk. ok is false
+// when nothing has been downloaded yet.
+func completedSample(tasks []types.Task, fileSize, n int64) (offset, length int64, ok bool) {
+	sorted := slices.Clone(tasks)
+	slices.SortFunc(sorted, func(a, b types.Task) int {
+		return cmp.Compare(a.Offset, b.Offset)
+	})
+
+	var pos int64
+	for _, t := range sorted {
+		if t.Offset > pos {
+			return pos, min(t.Offset-pos, n), true
+		}
+		pos = max(pos, t.Offset+t.Length)
+	}
+	if pos < fileSize {
+		return pos, min(fileSize-pos, n), true
+	}
+	return 0, 0, false
+}
+
+// verifyResume fetches a small range the partial file already holds and
+// compares it with the bytes on disk. A difference means the remote file
+// changed since the download was paused (or the partial was damaged), and
+// continuing would stitch two files together, so it returns
+// types.ErrResumeMismatch. Network trouble is left to the workers.
+func (d *ConcurrentDownloader) verifyResume(ctx context.Context, client *http.Client, rawurl string, tasks []types.Task, fileSize int64, outFile engine.WorkingFile) error {
+	offset, length, ok := completedSample(tasks, fileSize, resumeCheckBytes)
+	if !ok {
+		return nil
+	}
+
+	resp, err := d.openRange(ctx, rawurl, types.Task{Offset: offset, Length: length}, client, fileSize)
+	if err != nil {
+		utils.DebugFor(d.ID, "Resume check for %s skipped: %v", rawurl, err)
+		return nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if cr, ok := utils.ParseContentRange(resp.Header.Get("Content-Range")); ok && cr.Total >= 0 && cr.Total != fileSize {
+		return fmt.Errorf("%w: size is now %d bytes, was %d", types.ErrResumeMismatch, cr.Total, fileSize)
+	}
+
+	remote := make([]byte, length)
+	if _, err := io.ReadFull(resp.Body, remote); err != nil {
+		utils.DebugFor(d.ID, "Resume check for %s skipped: %v", rawurl, err)
+		return nil
+	}
+	local := make([]byte, length)
+	if _, err := outFile.ReadAt(local, offset); errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: partial file is shorter than the saved progress", types.ErrResumeMismatch)
+	} else if err != nil {
+		return fmt.Errorf("failed to read partial file: %w", err)
+	}
+	if !bytes.Equal(remote, local) {
+		return fmt.Errorf("%w: bytes %d-%d differ", types.ErrResumeMismatch, offset, offset+length-1)
+	}
+
+	utils.DebugFor(d.ID, "Resume check passed for %s (bytes %d-%d)", rawurl, offset, offset+length-1)
+	return nil
+}
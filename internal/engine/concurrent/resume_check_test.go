@@ -0,0 +1,84 @@
+package concurrent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func TestCompletedSample(t *testing.T) {
+	tests := []struct {
+		name           string
+		tasks          []types.Task
+		size           int64
+		offset, length int64
+		ok             bool
+	}{
+		{"start done", []types.Task{{Offset: 500, Length: 500}}, 1000, 0, 100, true},
+		{"gap between tasks", []types.Task{{Offset: 600, Length: 400}, {Offset: 0, Length: 200}}, 1000, 200, 100, true},
+		{"short gap", []types.Task{{Offset: 0, Length: 200}, {Offset: 230, Length: 770}}, 1000, 200, 30, true},
+		{"tail done", []types.Task{{Offset: 0, Length: 900}}, 1000, 900, 100, true},
+		{"nothing done", []types.Task{{Offset: 0, Length: 1000}}, 1000, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offset, length, ok := completedSample(tt.tasks, tt.size, 100)
+			if offset != tt.offset || length != tt.length || ok != tt.ok {
+				t.Errorf("completedSample() = (%d, %d, %v), want (%d, %d, %v)", offset, length, ok, tt.offset, tt.length, tt.ok)
+			}
+		})
+	}
+}
+
+func TestVerifyResume(t *testing.T) {
+	content := bytes.Repeat([]byte("surge-resume-check"), 10000)
+	size := int64(len(content))
+
+	var remote []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(remote))
+	}))
+	defer srv.Close()
+
+	partial := filepath.Join(t.TempDir(), "file.bin"+types.IncompleteSuffix)
+	if err := os.WriteFile(partial, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(partial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	changed := bytes.Clone(content)
+	changed[10] ^= 0xFF
+
+	tests := []struct {
+		name     string
+		remote   []byte
+		mismatch bool
+	}{
+		{"unchanged", content, false},
+		{"changed bytes", changed, true},
+		{"changed size", content[:size-1], true},
+	}
+	tasks := []types.Task{{Offset: size / 2, Length: size - size/2}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remote = tt.remote
+			d := &ConcurrentDownloader{URL: srv.URL, Runtime: &types.RuntimeConfig{}}
+			err := d.verifyResume(context.Background(), srv.Client(), srv.URL, tasks, size, f)
+			if got := errors.Is(err, types.ErrResumeMismatch); got != tt.mismatch {
+				t.Errorf("verifyResume() = %v, want mismatch %v", err, tt.mismatch)
+			}
+		})
+	}
+}
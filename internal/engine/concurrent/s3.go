@@ -0,0 +1,94 @@
+package concurrent
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// alignToParts rounds chunkSize to a whole number of S3 parts, so every range
+// request covers complete stored parts. Without a known part size it returns
+// chunkSize unchanged.
+func (d *ConcurrentDownloader) alignToParts(chunkSize int64) int64 {
+	partSize := d.S3.PartSize
+	if partSize <= 0 {
+		return chunkSize
+	}
+	parts := max((chunkSize+partSize/2)/partSize, 1)
+	return parts * partSize
+}
+
+// partBoundary moves a split point inside (lo, hi) onto an S3 part boundary,
+// preferring the next one up. It returns offset unchanged when there is no
+// known part size or no boundary inside the range.
+func (d *ConcurrentDownloader) partBoundary(offset, lo, hi int64) int64 {
+	partSize := d.S3.PartSize
+	if partSize <= 0 {
+		return offset
+	}
+	if up := (offset + partSize - 1) / partSize * partSize; up > lo && up < hi {
+		return up
+	}
+	if down := offset / partSize * partSize; down > lo && down < hi {
+		return down
+	}
+	return offset
+}
+
+// skipPrefix drops the first prefix bytes from tasks while leaving the
+// remaining boundaries where they were.
+func skipPrefix(tasks []types.Task, prefix int64) []types.Task {
+	out := tasks[:0]
+	for _, task := range tasks {
+		end := task.Offset + task.Length
+		if end <= prefix {
+			continue
+		}
+		if task.Offset < prefix {
+			task = types.Task{Offset: prefix, Length: end - prefix}
+		}
+		out = append(out, task)
+	}
+	return out
+}
+
+// checkURLExpired turns a 403 for a presigned URL whose expiry has passed into
+// ErrURLExpired, so the download can wait for a fresh link instead of
+// retrying one that will never work again.
+func checkURLExpired(rawurl string, resp *http.Response) error {
+	if resp.StatusCode != http.StatusForbidden {
+		return nil
+	}
+	if resp.Request != nil && resp.Request.URL != nil {
+		rawurl = resp.Request.URL.String()
+	}
+	expiry, ok := types.PresignedExpiry(rawurl)
+	if !ok || time.Now().Before(expiry) {
+		return nil
+	}
+	return fmt.Errorf("%w at %s", types.ErrURLExpired, expiry.Format(time.RFC3339))
+}
+
+// pauseForRefresh pauses the download once its only URL has expired, keeping
+// every finished range, so `surge refresh` with a new link resumes it on the
+// same part boundaries. It reports false when the download was already paused.
+func (d *ConcurrentDownloader) pauseForRefresh(cause error) bool {
+	if d.State == nil || !d.refreshPause.CompareAndSwap(false, true) {
+		return false
+	}
+	utils.DebugFor(d.ID, "Pausing %s for a URL refresh: %v", d.ID, cause)
+	if d.ProgressChan != nil {
+		msg := events.SystemLogMsg{Message: fmt.Sprintf("%s: %v; refresh the link to resume", filepath.Base(d.DestPath), cause)}
+		select {
+		case d.ProgressChan <- msg:
+		default:
+		}
+	}
+	d.State.PauseFor(cause)
+	return true
+}
@@ -0,0 +1,112 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/testutil"
+)
+
+func TestConcurrentDownloader_AlignsChunksToS3Parts(t *testing.T) {
+	d := NewConcurrentDownloader("s3-align", nil, nil, &types.RuntimeConfig{})
+	d.S3 = types.S3Object{PartSize: 5 * types.MB}
+
+	fileSize := int64(100 * types.MB)
+	chunkSize := d.determineChunkSize(fileSize, 3)
+	if chunkSize%d.S3.PartSize != 0 {
+		t.Fatalf("chunk size %d is not a whole number of %d-byte parts", chunkSize, d.S3.PartSize)
+	}
+	if got := d.alignToParts(types.MB); got != d.S3.PartSize {
+		t.Errorf("alignToParts(1 MiB) = %d, want one part", got)
+	}
+
+	// An early-ramp prefix shortens the first chunk instead of shifting the rest.
+	tasks := skipPrefix(createTasks(fileSize, chunkSize), 256*types.KB)
+	if tasks[0].Offset != 256*types.KB || tasks[0].Offset+tasks[0].Length != chunkSize {
+		t.Errorf("first task = %+v, want it to end at %d", tasks[0], chunkSize)
+	}
+	for _, task := range tasks[1:] {
+		if task.Offset%d.S3.PartSize != 0 {
+			t.Errorf("task at %d does not start on a part boundary", task.Offset)
+		}
+	}
+
+	// Splits land on the next part boundary inside the task when there is one.
+	if got := d.partBoundary(7*types.MB, 6*types.MB, 20*types.MB); got != 10*types.MB {
+		t.Errorf("partBoundary = %d, want %d", got, 10*types.MB)
+	}
+	if got := d.partBoundary(7*types.MB, 6*types.MB, 9*types.MB); got != 7*types.MB {
+		t.Errorf("partBoundary without a boundary in range = %d, want it unchanged", got)
+	}
+}
+
+func TestConcurrentDownloader_PausesWhenPresignedURLExpires(t *testing.T) {
+	tmpDir, cleanup := initTestState(t)
+	defer cleanup()
+
+	server := testutil.NewHTTPServerT(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	signedAt := time.Now().Add(-2 * time.Hour).UTC().Format("20060102T150405Z")
+	url := server.URL + "/object.bin?X-Amz-Date=" + signedAt + "&X-Amz-Expires=3600&X-Amz-Signature=abc"
+
+	destPath := filepath.Join(tmpDir, "object.bin")
+	if f, err := os.Create(destPath + types.IncompleteSuffix); err == nil {
+		_ = f.Close()
+	}
+
+	fileSize := int64(4 * types.MB)
+	progressCh := make(chan any, 16)
+	state := types.NewProgressState("s3-expired", fileSize)
+	d := NewConcurrentDownloader("s3-expired", progressCh, state, &types.RuntimeConfig{MaxConnectionsPerDownload: 2})
+	d.S3 = types.S3Object{PartSize: 5 * types.MB}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := d.Download(ctx, url, nil, nil, destPath, fileSize)
+	if !errors.Is(err, types.ErrPaused) {
+		t.Fatalf("Download err = %v, want ErrPaused", err)
+	}
+
+	var logged bool
+	var paused *events.DownloadPausedMsg
+	for len(progressCh) > 0 {
+		switch msg := (<-progressCh).(type) {
+		case events.SystemLogMsg:
+			logged = logged || strings.Contains(msg.Message, types.ErrURLExpired.Error())
+		case events.DownloadPausedMsg:
+			paused = &msg
+		}
+	}
+	if !logged {
+		t.Error("expected a log message asking for a refreshed link")
+	}
+	if paused == nil {
+		t.Fatal("expected a DownloadPausedMsg")
+	}
+	if paused.State.S3 != d.S3 {
+		t.Errorf("paused state S3 = %+v, want %+v", paused.State.S3, d.S3)
+	}
+	var remaining int64
+	for _, task := range paused.State.Tasks {
+		remaining += task.Length
+	}
+	if remaining != fileSize {
+		t.Errorf("paused with %d bytes remaining, want all %d", remaining, fileSize)
+	}
+	if slices.ContainsFunc(state.GetMirrors(), func(m types.MirrorStatus) bool { return m.Error }) {
+		t.Error("an expired link should not mark the mirror as failed")
+	}
+}
@@ -16,11 +16,11 @@ type ActiveTask struct {
 	StopAt        atomic.Int64
 
 	// Health monitoring fields
-	LastActivity atomic.Int64       // Unix nano timestamp of last data received
-	Speed        float64            // EMA-smoothed speed in bytes/sec (protected by mutex)
-	StartTime    time.Time          // When this task started
-	Cancel       context.CancelFunc // Cancel function to abort this task
-	SpeedMu      sync.Mutex         // Protects Speed field
+	LastActivity atomic.Int64            // Unix nano timestamp of last data received
+	Speed        float64                 // EMA-smoothed speed in bytes/sec (protected by mutex)
+	StartTime    time.Time               // When this task started
+	Cancel       context.CancelCauseFunc // Cancel function to abort this task
+	SpeedMu      sync.Mutex              // Protects Speed field
 
 	// Sliding window for recent speed tracking
 	WindowStart time.Time    // When current measurement window started
@@ -34,6 +34,8 @@ type ActiveTask struct {
 	SharedMaxOffset   *atomic.Int64
 	// Set while blocked on rate limiter so health monitor doesn't treat it as stalled
 	WaitingOnLimiter atomic.Bool
+	// link is the interface the task's worker connects over
+	link *link
 }
 
 // RemainingBytes returns the number of bytes left for this task
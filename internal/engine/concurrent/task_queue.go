@@ -57,6 +57,18 @@ func (q *TaskQueue) Pop() (types.Task, bool) {
 		q.idleWorkers.Add(-1)
 	}
 
+	return q.popLocked()
+}
+
+// TryPop returns the next task without waiting; ok is false when the queue is
+// currently empty.
+func (q *TaskQueue) TryPop() (types.Task, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.popLocked()
+}
+
+func (q *TaskQueue) popLocked() (types.Task, bool) {
 	if len(q.tasks) == q.head {
 		return types.Task{}, false
 	}
@@ -65,6 +65,24 @@ func TestTaskQueue_Close(t *testing.T) {
 	}
 }
 
+func TestTaskQueue_TryPop(t *testing.T) {
+	q := NewTaskQueue()
+
+	// Empty queue must not block
+	if _, ok := q.TryPop(); ok {
+		t.Error("TryPop on empty queue should return false")
+	}
+
+	q.Push(types.Task{Offset: 100, Length: 50})
+	got, ok := q.TryPop()
+	if !ok || got.Offset != 100 {
+		t.Errorf("TryPop = %+v, %v; want offset 100", got, ok)
+	}
+	if q.Len() != 0 {
+		t.Errorf("Len = %d, want 0", q.Len())
+	}
+}
+
 func TestTaskQueue_DrainRemaining(t *testing.T) {
 	q := NewTaskQueue()
 
@@ -94,7 +94,7 @@ func TestActiveTask_RemainingBytesWithStolenWork(t *testing.T) {
 }
 
 func TestActiveTask_Cancel(t *testing.T) {
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancelCause(context.Background())
 	at := &ActiveTask{
 		Task:   types.Task{Offset: 0, Length: 1000},
 		Cancel: cancel,
@@ -108,7 +108,7 @@ func TestActiveTask_Cancel(t *testing.T) {
 	}
 
 	// Cancel the task
-	at.Cancel()
+	at.Cancel(nil)
 
 	select {
 	case <-ctx.Done():
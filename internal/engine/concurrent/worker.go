@@ -2,36 +2,57 @@ package concurrent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"sync/atomic"
 	"time"
 
+	"github.com/SurgeDM/Surge/internal/engine"
 	"github.com/SurgeDM/Surge/internal/engine/types"
 	"github.com/SurgeDM/Surge/internal/utils"
 )
 
 // worker downloads tasks from the queue
-func (d *ConcurrentDownloader) worker(ctx context.Context, id int, mirrors []string, file *os.File, queue *TaskQueue, totalSize int64, client *http.Client) error {
+func (d *ConcurrentDownloader) worker(ctx context.Context, id int, mirrors []string, file engine.WorkingFile, queue *TaskQueue, totalSize int64, client *http.Client) error {
 	// Get pooled buffer
 	bufPtr := d.bufPool.Get().(*[]byte)
 	defer d.bufPool.Put(bufPtr)
 	buf := *bufPtr
 
-	utils.Debug("Worker %d started", id)
-	defer utils.Debug("Worker %d finished", id)
+	utils.DebugFor(d.ID, "Worker %d started", id)
+	defer utils.DebugFor(d.ID, "Worker %d finished", id)
+
+	// Workers take turns across the interfaces the download is spread over
+	lnk := d.linkFor(id)
+	if lnk != nil && lnk.iface != "" {
+		client = lnk.client
+	}
 
 	// Initial mirror assignment: Round Robin based on ID
 	currentMirrorIdx := id % len(mirrors)
+	// The first request races several mirrors and the worker sticks with the winner
+	hedgeFirst := len(mirrors) > 1
 
-	for {
-		// Get next task
-		task, ok := queue.Pop()
+	// Read-ahead requests for the next task (nil when pipelining is off)
+	pipe := newPipeline(ctx, d, queue, client, totalSize)
+	if pipe != nil {
+		defer pipe.abandon()
+	}
 
-		if !ok {
-			return nil // Queue closed, no more work
+	for {
+		// Get next task, preferring one whose request is already in flight
+		pending := pipe.take()
+		var task types.Task
+		if pending != nil {
+			task = pending.task
+		} else {
+			var ok bool
+			task, ok = queue.Pop()
+			if !ok {
+				return nil // Queue closed, no more work
+			}
 		}
 
 		// Update active workers
@@ -41,8 +62,19 @@ func (d *ConcurrentDownloader) worker(ctx context.Context, id int, mirrors []str
 
 		var lastErr error
 		maxRetries := d.Runtime.GetMaxTaskRetries()
+		throttleRetries := 0
+		var throttle *engine.ThrottleError
 		for attempt := 0; attempt < maxRetries; attempt++ {
-			if attempt > 0 {
+			if throttle != nil {
+				// The host gate holds this worker back for the server's
+				// Retry-After window; with other mirrors available, move on
+				// right away instead of waiting.
+				if len(mirrors) > 1 {
+					d.ReportMirrorError(mirrors[currentMirrorIdx])
+					currentMirrorIdx = d.nextMirror(mirrors, currentMirrorIdx)
+					utils.DebugFor(d.ID, "Worker %d: mirror busy, switching to %s", id, mirrors[currentMirrorIdx])
+				}
+			} else if attempt > 0 {
 
 				if len(mirrors) == 1 {
 					time.Sleep(time.Duration(1<<attempt) * types.RetryBaseDelay) // Exponential backoff incase of failure
@@ -52,21 +84,44 @@ func (d *ConcurrentDownloader) worker(ctx context.Context, id int, mirrors []str
 				// Report error for the previous mirror
 				d.ReportMirrorError(mirrors[currentMirrorIdx])
 
-				currentMirrorIdx = (currentMirrorIdx + 1) % len(mirrors)
-				utils.Debug("Worker %d: switching to mirror %s (attempt %d)", id, mirrors[currentMirrorIdx], attempt+1)
+				currentMirrorIdx = d.nextMirror(mirrors, currentMirrorIdx)
+				utils.DebugFor(d.ID, "Worker %d: switching to mirror %s (attempt %d)", id, mirrors[currentMirrorIdx], attempt+1)
 			}
 
 			// Use current mirror
 			currentURL := mirrors[currentMirrorIdx]
+			host := gateHost(currentURL)
+			if err := d.hosts.acquire(ctx, host); err != nil {
+				// Paused or cancelled while waiting out a throttled host; the
+				// task has not started, so hand it back for the pause handler.
+				queue.Push(task)
+				if d.State != nil {
+					d.State.ActiveWorkers.Add(-1)
+				}
+				return err
+			}
+			// Past its share of the connection budget, the worker waits for
+			// a connection to free up, here or in another download
+			if d.Budget != nil {
+				if err := d.Budget.Acquire(ctx); err != nil {
+					d.hosts.release(host)
+					queue.Push(task)
+					if d.State != nil {
+						d.State.ActiveWorkers.Add(-1)
+					}
+					return err
+				}
+			}
 
 			// Register active task with per-task cancellable context
-			taskCtx, taskCancel := context.WithCancel(ctx)
+			taskCtx, taskCancel := context.WithCancelCause(ctx)
 			now := time.Now()
 			activeTask := &ActiveTask{
 				Task:        task,
 				StartTime:   now,
 				Cancel:      taskCancel,
 				WindowStart: now, // Initialize sliding window
+				link:        lnk,
 			}
 			// If the incoming Task carried a shared pointer, copy it into the active task
 			if task.SharedMaxOffset != nil {
@@ -86,21 +141,45 @@ func (d *ConcurrentDownloader) worker(ctx context.Context, id int, mirrors []str
 
 			// Update chunk status to Downloading
 			if d.State != nil {
-				utils.Debug("Worker %d: Setting range %d-%d to Downloading", id, task.Offset, task.Offset+task.Length)
+				utils.DebugFor(d.ID, "Worker %d: Setting range %d-%d to Downloading", id, task.Offset, task.Offset+task.Length)
 				d.State.UpdateChunkStatus(task.Offset, task.Length, types.ChunkDownloading)
 			} else {
-				utils.Debug("Worker %d: d.State is nil, cannot update chunk status", id)
+				utils.DebugFor(d.ID, "Worker %d: d.State is nil, cannot update chunk status", id)
+			}
+
+			var resp *http.Response
+			var openErr error
+			if pending != nil {
+				resp = pipe.await(taskCtx, pending, currentURL)
+				pending = nil
+			} else if hedgeFirst {
+				hedgeFirst = false
+				var winner int
+				resp, winner, openErr = d.openHedged(taskCtx, mirrors, currentMirrorIdx, task, client, totalSize)
+				if openErr == nil {
+					currentMirrorIdx = winner
+					currentURL = mirrors[winner]
+				}
 			}
 
 			taskStart := time.Now()
-			lastErr = d.downloadTask(taskCtx, currentURL, file, activeTask, buf, client, totalSize)
+			if openErr != nil {
+				lastErr = openErr
+			} else {
+				lastErr = d.downloadTask(taskCtx, currentURL, file, activeTask, buf, client, totalSize, resp, pipe)
+			}
+			d.hosts.release(host)
+			if d.Budget != nil {
+				d.Budget.Release()
+			}
 
 			// CRITICAL: Capture external cancellation state BEFORE calling taskCancel()
 			// If we call taskCancel() first, taskCtx.Err() will always be non-nil
 			wasExternallyCancelled := taskCtx.Err() != nil
+			taskCause := context.Cause(taskCtx)
 
-			taskCancel() // Clean up context resources
-			utils.Debug("Worker %d: Task offset=%d length=%d took %v", id, task.Offset, task.Length, time.Since(taskStart))
+			taskCancel(nil) // Clean up context resources
+			utils.DebugFor(d.ID, "Worker %d: Task offset=%d length=%d took %v", id, task.Offset, task.Length, time.Since(taskStart))
 
 			// Check for PARENT context cancellation (pause/shutdown)
 			// This preserves active task info for pause handler to collect
@@ -109,7 +188,7 @@ func (d *ConcurrentDownloader) worker(ctx context.Context, id int, mirrors []str
 				if d.State != nil {
 					d.State.ActiveWorkers.Add(-1)
 				}
-				return ctx.Err()
+				return context.Cause(ctx)
 			}
 
 			// Check if TASK context was cancelled by Health Monitor (not by us calling taskCancel)
@@ -117,9 +196,15 @@ func (d *ConcurrentDownloader) worker(ctx context.Context, id int, mirrors []str
 			if wasExternallyCancelled && lastErr != nil {
 				// Health monitor cancelled this task - re-queue REMAINING work only
 
-				// Force rotation to next mirror to avoid getting stuck on the slow one
-				currentMirrorIdx = (currentMirrorIdx + 1) % len(mirrors)
-				utils.Debug("Worker %d: Health check cancelled task, rotating from mirror %s to %s", id, mirrors[(currentMirrorIdx+len(mirrors)-1)%len(mirrors)], mirrors[currentMirrorIdx])
+				// Force rotation to next mirror to avoid getting stuck on the slow one.
+				// A reset after sleep says nothing about the mirror, so it is kept.
+				if errors.Is(taskCause, types.ErrSystemWake) {
+					utils.DebugFor(d.ID, "Worker %d: Health check cancelled task (%v), reconnecting to %s", id, taskCause, mirrors[currentMirrorIdx])
+				} else {
+					slowMirror := mirrors[currentMirrorIdx]
+					currentMirrorIdx = d.nextMirror(mirrors, currentMirrorIdx)
+					utils.DebugFor(d.ID, "Worker %d: Health check cancelled task (%v), rotating from mirror %s to %s", id, taskCause, slowMirror, mirrors[currentMirrorIdx])
+				}
 
 				if remaining := activeTask.RemainingTask(); remaining != nil {
 					// Clamp to original task end (don't go past original boundary)
@@ -129,7 +214,7 @@ func (d *ConcurrentDownloader) worker(ctx context.Context, id int, mirrors []str
 					}
 					if remaining.Length > 0 {
 						queue.Push(*remaining)
-						utils.Debug("Worker %d: health-cancelled task requeued (remaining: %d bytes from offset %d)",
+						utils.DebugFor(d.ID, "Worker %d: health-cancelled task requeued (remaining: %d bytes from offset %d)",
 							id, remaining.Length, remaining.Offset)
 					}
 				}
@@ -154,17 +239,38 @@ func (d *ConcurrentDownloader) worker(ctx context.Context, id int, mirrors []str
 				if current < task.Offset+task.Length && current >= stopAt {
 					// We were stopped early this is expected success for the partial work
 					// The stolen part is already in the queue
-					utils.Debug("Worker stopped early due to stealing")
+					utils.DebugFor(d.ID, "Worker stopped early due to stealing")
 				}
 				break
 			}
 
+			throttle = nil
+			if errors.As(lastErr, &throttle) {
+				d.hosts.throttled(host, throttle.Delay(throttleRetries))
+				if throttleRetries < types.MaxThrottleRetries {
+					throttleRetries++
+					attempt-- // Waiting out a busy server does not use up a retry
+				}
+			}
+
 			// Resume-on-retry: update task to reflect remaining work
 			// This prevents double-counting bytes on retry
 			current := activeTask.CurrentOffset.Load()
 			if current > task.Offset {
 				task = types.Task{Offset: current, Length: task.Offset + task.Length - current}
 			}
+
+			// An expired presigned URL fails every retry; with no other
+			// mirror, pause and keep the progress until the link is refreshed.
+			// An expired session likewise waits for new headers.
+			if errors.Is(lastErr, types.ErrURLExpired) && len(mirrors) == 1 && d.pauseForRefresh(lastErr) ||
+				errors.Is(lastErr, types.ErrAuthExpired) && d.pauseForAuth(lastErr) {
+				queue.Push(task)
+				if d.State != nil {
+					d.State.ActiveWorkers.Add(-1)
+				}
+				return ctx.Err()
+			}
 		}
 
 		// Update active workers
@@ -177,61 +283,29 @@ func (d *ConcurrentDownloader) worker(ctx context.Context, id int, mirrors []str
 			// If we modified StopAt we should probably reset it or push the remaining part?
 			// TODO: Could optimize by pushing only remaining part if we track that.
 			queue.Push(task)
-			utils.Debug("task at offset %d failed after %d retries: %v", task.Offset, maxRetries, lastErr)
+			utils.DebugFor(d.ID, "task at offset %d failed after %d retries: %v", task.Offset, maxRetries, lastErr)
 		}
 	}
 }
 
-// downloadTask downloads a single byte range and writes to file at offset
-func (d *ConcurrentDownloader) downloadTask(ctx context.Context, rawurl string, file *os.File, activeTask *ActiveTask, buf []byte, client *http.Client, totalSize int64) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawurl, nil)
-	if err != nil {
-		return err
-	}
-
+// downloadTask downloads a single byte range and writes to file at offset.
+// resp, when non-nil, is a pipelined response already opened for this task.
+func (d *ConcurrentDownloader) downloadTask(ctx context.Context, rawurl string, file engine.WorkingFile, activeTask *ActiveTask, buf []byte, client *http.Client, totalSize int64, resp *http.Response, pipe *pipeline) error {
 	task := activeTask.Task
 
-	// Apply custom headers first (from browser extension: cookies, auth, referer, etc.)
-	for key, val := range d.Headers {
-		// Skip Range header - we set it ourselves for parallel downloads
-		if key != "Range" {
-			req.Header.Set(key, val)
+	if resp == nil {
+		var err error
+		resp, err = d.openRange(ctx, rawurl, task, client, totalSize)
+		if err != nil {
+			return err
 		}
 	}
-
-	// Set User-Agent from config only if not provided in custom headers
-	if req.Header.Get("User-Agent") == "" {
-		req.Header.Set("User-Agent", d.Runtime.GetUserAgent())
-	}
-	// Range header is always set for partial downloads (overrides any browser Range header)
-	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", task.Offset, task.Offset+task.Length-1))
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return err
-	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			utils.Debug("Error closing response body: %v", err)
+			utils.DebugFor(d.ID, "Error closing response body: %v", err)
 		}
 	}()
 
-	// Handle rate limiting explicitly
-	if resp.StatusCode == http.StatusTooManyRequests {
-		return fmt.Errorf("rate limited (429)")
-	}
-
-	// Validate status code
-	if resp.StatusCode == http.StatusOK {
-		// Valid only if we requested the full file
-		// If we wanted a partial range but got the whole file (200), that's an error because we can't handle the full stream at a non-zero offset
-		if task.Offset != 0 || task.Length != totalSize {
-			return fmt.Errorf("server indicated success (200) but ignored range request (expected 206)")
-		}
-	} else if resp.StatusCode != http.StatusPartialContent {
-		return fmt.Errorf("unexpected status: %d", resp.StatusCode)
-	}
-
 	// Batching State
 	var pendingBytes int64
 	var pendingStart int64 = -1
@@ -247,6 +321,9 @@ func (d *ConcurrentDownloader) downloadTask(ctx context.Context, rawurl string,
 
 			// Update Downloaded Counter (Atomic)
 			d.State.Downloaded.Add(pendingBytes)
+			if activeTask.link != nil {
+				activeTask.link.bytes.Add(pendingBytes)
+			}
 
 			pendingBytes = 0
 			pendingStart = -1
@@ -387,6 +464,9 @@ func (d *ConcurrentDownloader) downloadTask(ctx context.Context, rawurl string,
 				flushUpdates()
 			}
 
+			// Near the end of this range, get the next request in flight
+			pipe.prefetch(rawurl, activeTask.StopAt.Load()-offset)
+
 			// Update EMA speed using sliding window (2 second window)
 			// This relies on WindowBytes which is updated atomically above, so independent of batching
 			windowElapsed := now.Sub(activeTask.WindowStart).Seconds()
@@ -419,6 +499,65 @@ func (d *ConcurrentDownloader) downloadTask(ctx context.Context, rawurl string,
 	return nil
 }
 
+// openRange sends the Range request for task and validates the response status.
+// On success the caller owns the response body.
+func (d *ConcurrentDownloader) openRange(ctx context.Context, rawurl string, task types.Task, client *http.Client, totalSize int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawurl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply custom headers first (from browser extension: cookies, auth, referer, etc.)
+	for key, val := range d.Headers {
+		// Skip Range header - we set it ourselves for parallel downloads
+		if key != "Range" {
+			req.Header.Set(key, val)
+		}
+	}
+
+	// Set User-Agent from config only if not provided in custom headers
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", d.Runtime.GetUserAgent())
+	}
+	// Range header is always set for partial downloads (overrides any browser Range header)
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", task.Offset, task.Offset+task.Length-1))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Handle rate limiting explicitly so the worker can honor Retry-After
+	if throttle := engine.CheckThrottle(resp); throttle != nil {
+		return nil, throttle
+	}
+	if err := checkURLExpired(rawurl, resp); err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	if err := checkAuthExpired(resp); err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+
+	// Validate status code
+	if resp.StatusCode == http.StatusOK {
+		// Valid only if we requested the full file
+		// If we wanted a partial range but got the whole file (200), that's an error because we can't handle the full stream at a non-zero offset
+		if task.Offset != 0 || task.Length != totalSize {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("server indicated success (200) but ignored range request (expected 206)")
+		}
+	} else if resp.StatusCode != http.StatusPartialContent {
+		_ = resp.Body.Close()
+		return nil, &types.HTTPStatusError{StatusCode: resp.StatusCode}
+	}
+
+	d.recordFinalURL(rawurl, resp)
+	d.recordLastModified(rawurl, resp)
+	return resp, nil
+}
+
 // StealWork tries to split an active task from a busy worker
 // It greedily targets the worker with the MOST remaining work.
 func (d *ConcurrentDownloader) StealWork(queue *TaskQueue) bool {
@@ -454,7 +593,7 @@ func (d *ConcurrentDownloader) StealWork(queue *TaskQueue) bool {
 	}
 
 	current := active.CurrentOffset.Load()
-	newStopAt := current + splitSize
+	newStopAt := d.partBoundary(current+splitSize, current, current+remaining)
 
 	// Update the active task stop point
 	active.StopAt.Store(newStopAt)
@@ -470,7 +609,7 @@ func (d *ConcurrentDownloader) StealWork(queue *TaskQueue) bool {
 	// Double check: ensure we didn't race and lose the chunk
 	currentStopAt := active.StopAt.Load()
 	if stolenStart >= currentStopAt && currentStopAt != newStopAt {
-		utils.Debug("StealWork race detected: stolenStart >= currentStopAt")
+		utils.DebugFor(d.ID, "StealWork race detected: stolenStart >= currentStopAt")
 	}
 
 	originalEnd := current + remaining
@@ -485,7 +624,7 @@ func (d *ConcurrentDownloader) StealWork(queue *TaskQueue) bool {
 	}
 
 	queue.Push(stolenTask)
-	utils.Debug("Balancer: stole %s from worker %d (new range: %d-%d)",
+	utils.DebugFor(d.ID, "Balancer: stole %s from worker %d (new range: %d-%d)",
 		utils.ConvertBytesToHumanReadable(stolenTask.Length), bestID, stolenTask.Offset, stolenTask.Offset+stolenTask.Length)
 
 	return true
@@ -552,7 +691,7 @@ func (d *ConcurrentDownloader) HedgeWork(queue *TaskQueue) bool {
 	bestActive.SharedMaxOffsetMu.Unlock()
 
 	queue.Push(hedgedTask)
-	utils.Debug("Balancer: hedged %s (range: %d-%d) - idle worker will race on fresh connection",
+	utils.DebugFor(d.ID, "Balancer: hedged %s (range: %d-%d) - idle worker will race on fresh connection",
 		utils.ConvertBytesToHumanReadable(hedgedTask.Length), hedgedTask.Offset, hedgedTask.Offset+hedgedTask.Length)
 
 	return true
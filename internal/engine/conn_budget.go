@@ -0,0 +1,150 @@
+package engine
+
+import (
+	"context"
+	"sync"
+)
+
+// ConnectionBudget caps the connections all downloads hold at once and
+// shares them evenly: each download that joins is owed an equal share, and
+// may borrow connections other downloads leave unused until one of those
+// wants them back. Connections are held for one chunk at a time, so borrowed
+// ones change hands between chunks. A total of zero limits nothing.
+type ConnectionBudget struct {
+	mu      sync.Mutex
+	total   int
+	used    int
+	shares  map[*ConnectionShare]struct{}
+	changed chan struct{}
+}
+
+// ConnectionShare is one download's claim on a ConnectionBudget.
+type ConnectionShare struct {
+	budget  *ConnectionBudget
+	held    int
+	waiting int
+}
+
+func NewConnectionBudget(total int) *ConnectionBudget {
+	return &ConnectionBudget{
+		total:   max(total, 0),
+		shares:  make(map[*ConnectionShare]struct{}),
+		changed: make(chan struct{}),
+	}
+}
+
+// SetTotal changes the budget. Connections already held are kept; a lower
+// total holds back new ones until enough have been released.
+func (b *ConnectionBudget) SetTotal(total int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.total = max(total, 0)
+	b.notifyLocked()
+}
+
+// Total returns the budget, or zero when it is unlimited.
+func (b *ConnectionBudget) Total() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.total
+}
+
+// Join adds a download to the budget. Call Leave once it stops.
+func (b *ConnectionBudget) Join() *ConnectionShare {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := &ConnectionShare{budget: b}
+	b.shares[s] = struct{}{}
+	b.notifyLocked()
+	return s
+}
+
+// Leave removes the download from the budget, so the others' shares grow.
+func (s *ConnectionShare) Leave() {
+	b := s.budget
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.shares[s]; !ok {
+		return
+	}
+	delete(b.shares, s)
+	b.used -= s.held
+	s.held = 0
+	b.notifyLocked()
+}
+
+// Acquire blocks until the download may open another connection, then takes
+// it. Release it with Release.
+func (s *ConnectionShare) Acquire(ctx context.Context) error {
+	b := s.budget
+	for {
+		b.mu.Lock()
+		if b.grantLocked(s) {
+			s.held++
+			b.used++
+			b.mu.Unlock()
+			return nil
+		}
+		s.waiting++
+		changed := b.changed
+		b.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+		}
+
+		b.mu.Lock()
+		s.waiting--
+		b.mu.Unlock()
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// Release returns a connection taken with Acquire.
+func (s *ConnectionShare) Release() {
+	b := s.budget
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if s.held == 0 {
+		return
+	}
+	s.held--
+	b.used--
+	b.notifyLocked()
+}
+
+// fairLocked returns the share each download is owed. Callers hold b.mu.
+func (b *ConnectionBudget) fairLocked() int {
+	return max(1, b.total/max(len(b.shares), 1))
+}
+
+// grantLocked reports whether s may take another connection: there is one
+// free, and either s is below its share or no download below its share is
+// waiting for it. Callers hold b.mu.
+func (b *ConnectionBudget) grantLocked(s *ConnectionShare) bool {
+	if b.total == 0 {
+		return true
+	}
+	if b.used >= b.total {
+		return false
+	}
+	fair := b.fairLocked()
+	if s.held < fair {
+		return true
+	}
+	for other := range b.shares {
+		if other != s && other.waiting > 0 && other.held < fair {
+			return false
+		}
+	}
+	return true
+}
+
+// notifyLocked wakes every waiting download. Callers hold b.mu.
+func (b *ConnectionBudget) notifyLocked() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
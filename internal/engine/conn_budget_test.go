@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// acquireSoon reports whether s gets a connection within a short wait.
+func acquireSoon(s *ConnectionShare) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	return s.Acquire(ctx) == nil
+}
+
+// waitForWaiter blocks until s is waiting in Acquire.
+func waitForWaiter(t *testing.T, b *ConnectionBudget, s *ConnectionShare) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		b.mu.Lock()
+		waiting := s.waiting
+		b.mu.Unlock()
+		if waiting > 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("share never waited")
+}
+
+func TestConnectionBudget_LoneDownloadUsesItAll(t *testing.T) {
+	b := NewConnectionBudget(3)
+	a := b.Join()
+	for i := range 3 {
+		if !acquireSoon(a) {
+			t.Fatalf("connection %d refused", i+1)
+		}
+	}
+	if acquireSoon(a) {
+		t.Fatal("took a connection past the budget")
+	}
+	a.Release()
+	if !acquireSoon(a) {
+		t.Fatal("released connection not handed out again")
+	}
+}
+
+func TestConnectionBudget_BorrowedConnectionsGoBackToTheirOwner(t *testing.T) {
+	b := NewConnectionBudget(4)
+	a, other := b.Join(), b.Join()
+
+	// other uses one of its two, so a may borrow the spare
+	for range 3 {
+		if !acquireSoon(a) {
+			t.Fatal("a refused while connections were spare")
+		}
+	}
+	if !acquireSoon(other) {
+		t.Fatal("other refused")
+	}
+
+	got := make(chan error, 1)
+	go func() { got <- other.Acquire(context.Background()) }()
+	waitForWaiter(t, b, other)
+
+	// The borrowed connection comes back to other, not to a
+	a.Release()
+	if err := <-got; err != nil {
+		t.Fatal(err)
+	}
+	a.Release()
+	if acquireSoon(a) && acquireSoon(a) {
+		t.Fatal("a took more than its share back while other held its own")
+	}
+}
+
+func TestConnectionBudget_WaiterBelowItsShareGoesFirst(t *testing.T) {
+	b := NewConnectionBudget(2)
+	a, other := b.Join(), b.Join()
+	if !acquireSoon(a) || !acquireSoon(a) {
+		t.Fatal("a refused while alone in using the budget")
+	}
+
+	got := make(chan error, 1)
+	go func() { got <- other.Acquire(context.Background()) }()
+	waitForWaiter(t, b, other)
+
+	a.Release()
+	if acquireSoon(a) {
+		t.Fatal("a took the connection other is owed")
+	}
+	if err := <-got; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConnectionBudget_LeaveAndSetTotal(t *testing.T) {
+	b := NewConnectionBudget(1)
+	a, other := b.Join(), b.Join()
+	if !acquireSoon(a) {
+		t.Fatal("a refused")
+	}
+	if acquireSoon(other) {
+		t.Fatal("took a connection past the budget")
+	}
+
+	a.Leave()
+	if !acquireSoon(other) {
+		t.Fatal("connections held by a download that left were not freed")
+	}
+
+	b.SetTotal(0)
+	for range 10 {
+		if !acquireSoon(other) {
+			t.Fatal("an unlimited budget refused a connection")
+		}
+	}
+}
+
+func TestConnectionBudget_AcquireStopsWithContext(t *testing.T) {
+	b := NewConnectionBudget(1)
+	a := b.Join()
+	if !acquireSoon(a) {
+		t.Fatal("a refused")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := a.Acquire(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Acquire = %v, want context.Canceled", err)
+	}
+}
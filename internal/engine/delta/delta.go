@@ -0,0 +1,358 @@
+// Package delta brings a completed download up to date with a remote file
+// that has changed since. When the old file is still the start of the new
+// one, as with logs, growing archives and appended datasets, only the new
+// tail is fetched; otherwise the file is downloaded again.
+package delta
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/SurgeDM/Surge/internal/engine/stream"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/engine/zsync"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// SampleSize is how much of the start and of the end of the old file is
+// compared with the server before only the tail is fetched.
+const SampleSize = 64 * 1024
+
+// Mode is what an update did to the file.
+type Mode string
+
+const (
+	Unchanged Mode = "unchanged"
+	Appended  Mode = "appended" // Only the new tail was fetched
+	Replaced  Mode = "replaced" // The whole file was fetched again
+	Synced    Mode = "synced"   // Rebuilt from the old file with a .zsync control file
+)
+
+// Remote is what the server says about the current version of the file.
+type Remote struct {
+	Size         int64 // 0 when the server did not say
+	Ranges       bool
+	ETag         string
+	LastModified string
+}
+
+// Validator is the strong validator If-Range can be sent with: a strong
+// ETag, or else the Last-Modified date. It is empty when there is neither.
+func (r Remote) Validator() string {
+	if r.ETag != "" && !strings.HasPrefix(r.ETag, "W/") {
+		return r.ETag
+	}
+	return r.LastModified
+}
+
+// Result is the outcome of an update.
+type Result struct {
+	Mode    Mode
+	Remote  Remote
+	Fetched int64 // Bytes downloaded, including the samples compared
+}
+
+// Updater updates files from their URLs. A file that changed other than by
+// growing is rebuilt with zsync when the server has a control file for it
+// next to it, at the URL with .zsync added. Client, Headers and UserAgent are
+// used for every request; Connections and MaxRetries only when the whole
+// file is fetched again.
+type Updater struct {
+	Client      *http.Client
+	Headers     map[string]string
+	UserAgent   string
+	Connections int
+	MaxRetries  int
+	// Full skips the comparison and always fetches the whole file.
+	Full bool
+}
+
+// Check reports whether the remote file differs from the one at path,
+// without changing it. saved is as for Update.
+func (u *Updater) Check(ctx context.Context, rawurl, path string, saved map[string]string) (Remote, bool, error) {
+	local, _, remote, err := u.probe(ctx, rawurl, path)
+	if err != nil {
+		return remote, false, err
+	}
+	return remote, changed(local, remote, saved), nil
+}
+
+// Update brings the file at path up to date with rawurl. saved is the ETag
+// and Last-Modified the file was downloaded with, by header name; without
+// them, only a change of size shows that the file changed.
+func (u *Updater) Update(ctx context.Context, rawurl, path string, saved map[string]string) (Result, error) {
+	local, head, remote, err := u.probe(ctx, rawurl, path)
+	if err != nil {
+		return Result{}, err
+	}
+	result := Result{Remote: remote, Fetched: int64(len(head))}
+
+	if !u.Full && !changed(local, remote, saved) {
+		result.Mode = Unchanged
+		return result, nil
+	}
+
+	if !u.Full && remote.Ranges && remote.Validator() != "" && remote.Size > local {
+		fetched, err := u.appendTail(ctx, rawurl, path, local, head, remote)
+		result.Fetched += fetched
+		if err == nil {
+			result.Mode = Appended
+			return result, nil
+		}
+		if !errors.Is(err, errDiverged) {
+			return result, err
+		}
+	}
+
+	if !u.Full {
+		synced, ok, err := u.sync(ctx, rawurl, path, remote)
+		result.Fetched += synced
+		if err != nil {
+			return result, err
+		}
+		if ok {
+			result.Mode = Synced
+			return result, nil
+		}
+	}
+
+	fetched, err := u.replace(ctx, rawurl, path)
+	result.Fetched += fetched
+	if err != nil {
+		return result, err
+	}
+	result.Mode = Replaced
+	return result, nil
+}
+
+// probe returns the size of the file at path, and the sample of the start
+// of the remote file along with what the server says about it.
+func (u *Updater) probe(ctx context.Context, rawurl, path string) (int64, []byte, Remote, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, nil, Remote{}, err
+	}
+
+	// The first range doubles as the probe and as the sample of the start
+	resp, err := u.get(ctx, rawurl, fmt.Sprintf("bytes=0-%d", SampleSize-1), "")
+	if err != nil {
+		return 0, nil, Remote{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	head, remote, err := readProbe(resp)
+	return info.Size(), head, remote, err
+}
+
+// changed reports whether the remote file differs from the local one of
+// size local that was downloaded with the saved validators.
+func changed(local int64, remote Remote, saved map[string]string) bool {
+	if remote.Size > 0 && remote.Size != local {
+		return true
+	}
+	if etag := saved["ETag"]; etag != "" && remote.ETag != "" {
+		return etag != remote.ETag
+	}
+	if modified := saved["Last-Modified"]; modified != "" && remote.LastModified != "" {
+		return modified != remote.LastModified
+	}
+	return false
+}
+
+// errDiverged means the old file is not the start of the new one.
+var errDiverged = errors.New("file changed before its old end")
+
+// appendTail checks that the start and end of the old file match the
+// server's, then appends everything after it. On failure the file is cut
+// back to its old size.
+func (u *Updater) appendTail(ctx context.Context, rawurl, path string, local int64, head []byte, remote Remote) (int64, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = f.Close() }()
+
+	if ok, err := matches(f, 0, head[:min(int64(len(head)), local)]); err != nil || !ok {
+		return 0, errors.Join(err, errDiverged)
+	}
+
+	// The tail is requested with the end of the old file in front of it,
+	// so one request also gives the sample of the end.
+	from := max(local-SampleSize, 0)
+	resp, err := u.get(ctx, rawurl, fmt.Sprintf("bytes=%d-", from), remote.Validator())
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusPartialContent {
+		// With If-Range, a whole file means it changed again since the probe
+		return 0, fmt.Errorf("%w: status %d", stream.ErrRangeChanged, resp.StatusCode)
+	}
+	if cr, ok := utils.ParseContentRange(resp.Header.Get("Content-Range")); !ok || cr.Start != from {
+		return 0, fmt.Errorf("%w: got range %q", stream.ErrRangeChanged, resp.Header.Get("Content-Range"))
+	}
+
+	sample := make([]byte, local-from)
+	n, err := io.ReadFull(resp.Body, sample)
+	fetched := int64(n)
+	if err != nil {
+		return fetched, err
+	}
+	if ok, err := matches(f, from, sample); err != nil || !ok {
+		return fetched, errors.Join(err, errDiverged)
+	}
+
+	if _, err := f.Seek(local, io.SeekStart); err != nil {
+		return fetched, err
+	}
+	written, err := io.Copy(f, resp.Body)
+	fetched += written
+	if err == nil && local+written != remote.Size {
+		err = fmt.Errorf("%w: got %d of %d bytes", io.ErrUnexpectedEOF, local+written, remote.Size)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if err != nil {
+		if terr := f.Truncate(local); terr != nil {
+			return fetched, fmt.Errorf("%w (and the file could not be cut back to %d bytes: %v)", err, local, terr)
+		}
+		return fetched, err
+	}
+	return fetched, nil
+}
+
+// matches reports whether f holds want at off.
+func matches(f *os.File, off int64, want []byte) (bool, error) {
+	got := make([]byte, len(want))
+	if _, err := f.ReadAt(got, off); err != nil {
+		return false, err
+	}
+	return bytes.Equal(got, want), nil
+}
+
+// sync rebuilds the file at path with the zsync control file next to
+// rawurl, reporting false when there is none, or none for the current
+// version.
+func (u *Updater) sync(ctx context.Context, rawurl, path string, remote Remote) (int64, bool, error) {
+	controlURL, err := url.Parse(rawurl)
+	if err != nil {
+		return 0, false, nil
+	}
+	controlURL.Path += ".zsync"
+	controlURL.RawPath = ""
+
+	client := &zsync.Client{
+		HTTP:        u.Client,
+		Headers:     u.Headers,
+		UserAgent:   u.UserAgent,
+		Connections: u.Connections,
+		MaxRetries:  u.MaxRetries,
+	}
+	ctl, err := client.Control(ctx, controlURL.String())
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, false, ctx.Err()
+		}
+		return 0, false, nil
+	}
+	if remote.Size > 0 && ctl.Length != remote.Size {
+		// Left behind by an older version
+		return 0, false, nil
+	}
+	result, err := client.Sync(ctx, controlURL.String(), ctl, path, nil)
+	if err != nil {
+		if ctx.Err() != nil {
+			return result.Fetched, false, ctx.Err()
+		}
+		// A failed sync leaves the old file as it was, to be replaced instead
+		utils.Debug("Delta: zsync of %s failed: %v", rawurl, err)
+		return result.Fetched, false, nil
+	}
+	return result.Fetched, true, nil
+}
+
+// replace downloads the whole file beside path and moves it over path once
+// complete, so a failed update leaves the old file as it was.
+func (u *Updater) replace(ctx context.Context, rawurl, path string) (int64, error) {
+	working := path + types.IncompleteSuffix
+	f, err := os.OpenFile(working, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	d := &stream.Downloader{
+		Client:      u.Client,
+		Headers:     u.Headers,
+		UserAgent:   u.UserAgent,
+		Connections: u.Connections,
+		MaxRetries:  u.MaxRetries,
+	}
+	written, err := d.Download(ctx, rawurl, f)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(working, path)
+	}
+	if err != nil {
+		_ = os.Remove(working)
+		return written, err
+	}
+	return written, nil
+}
+
+func (u *Updater) get(ctx context.Context, rawurl, byteRange, ifRange string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawurl, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range u.Headers {
+		req.Header.Set(key, value)
+	}
+	if u.UserAgent != "" {
+		req.Header.Set("User-Agent", u.UserAgent)
+	}
+	req.Header.Set("Range", byteRange)
+	if ifRange != "" {
+		req.Header.Set("If-Range", ifRange)
+	}
+	client := u.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+// readProbe reads the first sample and what the response says about the
+// file.
+func readProbe(resp *http.Response) ([]byte, Remote, error) {
+	remote := Remote{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		remote.Ranges = true
+		cr, ok := utils.ParseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || cr.Total < 0 {
+			return nil, remote, fmt.Errorf("server sent no file size in %q", resp.Header.Get("Content-Range"))
+		}
+		remote.Size = cr.Total
+		head, err := io.ReadAll(io.LimitReader(resp.Body, SampleSize))
+		return head, remote, err
+	case http.StatusOK:
+		remote.Size = max(resp.ContentLength, 0)
+		return nil, remote, nil
+	default:
+		return nil, remote, &types.HTTPStatusError{StatusCode: resp.StatusCode}
+	}
+}
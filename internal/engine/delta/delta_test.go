@@ -0,0 +1,175 @@
+package delta
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/md4"
+)
+
+func testContent(size int) []byte {
+	data := make([]byte, size)
+	rand.New(rand.NewSource(1)).Read(data)
+	return data
+}
+
+// serve answers with content and etag, with ranges unless noRanges is set.
+func serve(t *testing.T, content []byte, etag string, noRanges bool) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etag)
+		if noRanges {
+			_, _ = w.Write(content)
+			return
+		}
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func writeLocal(t *testing.T, data []byte) string {
+	path := filepath.Join(t.TempDir(), "file.bin")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func update(t *testing.T, srv *httptest.Server, path string, saved map[string]string) Result {
+	u := &Updater{Client: srv.Client(), Connections: 2}
+	result, err := u.Update(context.Background(), srv.URL+"/file.bin", path, saved)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	return result
+}
+
+func checkFile(t *testing.T, path string, want []byte) {
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("file has %d bytes, want the %d of the new version", len(got), len(want))
+	}
+	if _, err := os.Stat(path + ".surge"); !os.IsNotExist(err) {
+		t.Errorf("working file left behind: %v", err)
+	}
+}
+
+func TestUpdate_Unchanged(t *testing.T) {
+	content := testContent(300 << 10)
+	srv := serve(t, content, `"v1"`, false)
+	path := writeLocal(t, content)
+
+	result := update(t, srv, path, map[string]string{"ETag": `"v1"`})
+	if result.Mode != Unchanged || result.Fetched != SampleSize {
+		t.Fatalf("result = %+v, want unchanged after one sample", result)
+	}
+}
+
+func TestUpdate_AppendsOnlyTheTail(t *testing.T) {
+	content := testContent(300 << 10)
+	srv := serve(t, content, `"v2"`, false)
+	old := 200 << 10
+	path := writeLocal(t, content[:old])
+
+	result := update(t, srv, path, map[string]string{"ETag": `"v1"`})
+	if result.Mode != Appended {
+		t.Fatalf("mode = %s, want appended", result.Mode)
+	}
+	if want := int64(2*SampleSize + len(content) - old); result.Fetched != want {
+		t.Errorf("fetched %d bytes, want %d: two samples and the tail", result.Fetched, want)
+	}
+	if result.Remote.Size != int64(len(content)) || result.Remote.ETag != `"v2"` {
+		t.Errorf("remote = %+v", result.Remote)
+	}
+	checkFile(t, path, content)
+}
+
+func TestUpdate_ReplacesWhenTheOldEndChanged(t *testing.T) {
+	content := testContent(300 << 10)
+	srv := serve(t, content, `"v2"`, false)
+	local := bytes.Clone(content[:200<<10])
+	local[len(local)-1] ^= 0xff
+	path := writeLocal(t, local)
+
+	if result := update(t, srv, path, nil); result.Mode != Replaced {
+		t.Fatalf("mode = %s, want replaced", result.Mode)
+	}
+	checkFile(t, path, content)
+}
+
+func TestUpdate_ReplacesWithoutRanges(t *testing.T) {
+	content := testContent(100 << 10)
+	srv := serve(t, content, `"v2"`, true)
+	path := writeLocal(t, content[:10<<10])
+
+	if result := update(t, srv, path, map[string]string{"ETag": `"v1"`}); result.Mode != Replaced {
+		t.Fatalf("mode = %s, want replaced", result.Mode)
+	}
+	checkFile(t, path, content)
+}
+
+func TestUpdate_SameSizeNewETagIsReplaced(t *testing.T) {
+	content := testContent(100 << 10)
+	srv := serve(t, content, `"v2"`, false)
+	path := writeLocal(t, make([]byte, len(content)))
+
+	if result := update(t, srv, path, map[string]string{"ETag": `"v1"`}); result.Mode != Replaced {
+		t.Fatalf("mode = %s, want replaced", result.Mode)
+	}
+	checkFile(t, path, content)
+}
+
+func TestUpdate_SyncsWithAZsyncControlFile(t *testing.T) {
+	const bs = 1024
+	old := testContent(200 << 10)
+	content := append(append(bytes.Clone(old[:50<<10]), "inserted"...), old[50<<10:]...)
+
+	// A control file as zsyncmake writes it, with full-length sums
+	control := fmt.Appendf(nil, "zsync: 0.6.2\nBlocksize: %d\nLength: %d\nURL: file.bin\nSHA-1: %x\n\n", bs, len(content), sha1.Sum(content))
+	for off := 0; off < len(content); off += bs {
+		block := make([]byte, bs)
+		copy(block, content[off:])
+		var a, b uint16
+		for i, c := range block {
+			a += uint16(c)
+			b += uint16(bs-i) * uint16(c)
+		}
+		control = binary.BigEndian.AppendUint16(binary.BigEndian.AppendUint16(control, a), b)
+		h := md4.New()
+		h.Write(block)
+		control = h.Sum(control)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/file.bin.zsync" {
+			_, _ = w.Write(control)
+			return
+		}
+		w.Header().Set("ETag", `"v2"`)
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+	path := writeLocal(t, old)
+
+	result := update(t, srv, path, map[string]string{"ETag": `"v1"`})
+	if result.Mode != Synced {
+		t.Fatalf("mode = %s, want synced", result.Mode)
+	}
+	if result.Fetched > SampleSize+4*bs {
+		t.Errorf("fetched %d bytes, want the probe and the changed blocks only", result.Fetched)
+	}
+	checkFile(t, path, content)
+}
@@ -0,0 +1,57 @@
+package events
+
+// ProgressCoalescer holds progress frames back so that a client is sent at
+// most one per download per flush, carrying the newest numbers.
+type ProgressCoalescer struct {
+	pending map[string]SSEMessage
+	order   []string
+}
+
+// NewProgressCoalescer returns an empty coalescer.
+func NewProgressCoalescer() *ProgressCoalescer {
+	return &ProgressCoalescer{pending: make(map[string]SSEMessage)}
+}
+
+// Add takes the next frame. Progress frames are held and nothing is
+// returned; any other frame is returned after the progress held for its
+// download, so each download's updates stay in order.
+func (c *ProgressCoalescer) Add(frame SSEMessage) []SSEMessage {
+	if isProgressEvent(frame.Event) {
+		if _, ok := c.pending[frame.DownloadID]; !ok {
+			c.order = append(c.order, frame.DownloadID)
+		}
+		// Held frames go out behind newer ones, so their IDs would move a
+		// client's Last-Event-ID backwards. Progress is never replayed anyway.
+		frame.ID = 0
+		c.pending[frame.DownloadID] = frame
+		return nil
+	}
+
+	held, ok := c.pending[frame.DownloadID]
+	if !ok || frame.DownloadID == "" {
+		return []SSEMessage{frame}
+	}
+	delete(c.pending, frame.DownloadID)
+	for i, id := range c.order {
+		if id == frame.DownloadID {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+	return []SSEMessage{held, frame}
+}
+
+// Flush returns the held progress frames, oldest download first, and
+// forgets them.
+func (c *ProgressCoalescer) Flush() []SSEMessage {
+	if len(c.order) == 0 {
+		return nil
+	}
+	frames := make([]SSEMessage, 0, len(c.order))
+	for _, id := range c.order {
+		frames = append(frames, c.pending[id])
+		delete(c.pending, id)
+	}
+	c.order = c.order[:0]
+	return frames
+}
@@ -0,0 +1,40 @@
+package events
+
+import "testing"
+
+func TestProgressCoalescer_KeepsNewestProgressPerDownload(t *testing.T) {
+	c := NewProgressCoalescer()
+	for i, id := range []string{"a", "b", "a"} {
+		if out := c.Add(SSEMessage{ID: uint64(i + 1), Event: EventTypeProgress, DownloadID: id, Data: []byte{byte(i)}}); out != nil {
+			t.Fatalf("progress frame was passed through: %+v", out)
+		}
+	}
+
+	frames := c.Flush()
+	if len(frames) != 2 || frames[0].DownloadID != "a" || frames[1].DownloadID != "b" {
+		t.Fatalf("flushed %+v, want one frame each for a and b", frames)
+	}
+	if frames[0].Data[0] != 2 || frames[0].ID != 0 {
+		t.Fatalf("a = %+v, want newest data and no ID", frames[0])
+	}
+	if c.Flush() != nil {
+		t.Fatal("second flush should be empty")
+	}
+}
+
+func TestProgressCoalescer_StateChangeFollowsHeldProgress(t *testing.T) {
+	c := NewProgressCoalescer()
+	c.Add(SSEMessage{Event: EventTypeProgress, DownloadID: "a"})
+	c.Add(SSEMessage{Event: EventTypeProgress, DownloadID: "b"})
+
+	out := c.Add(SSEMessage{ID: 3, Event: EventTypeComplete, DownloadID: "a"})
+	if len(out) != 2 || out[0].Event != EventTypeProgress || out[1].ID != 3 {
+		t.Fatalf("Add(complete) = %+v, want held progress then the completion", out)
+	}
+	if frames := c.Flush(); len(frames) != 1 || frames[0].DownloadID != "b" {
+		t.Fatalf("flushed %+v, want only b", frames)
+	}
+	if out := c.Add(SSEMessage{Event: EventTypeSystem}); len(out) != 1 {
+		t.Fatalf("Add(system) = %+v, want it passed straight through", out)
+	}
+}
@@ -3,7 +3,10 @@ package events
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
 )
 
 func TestEventTypeForMessage(t *testing.T) {
@@ -23,6 +26,11 @@ func TestEventTypeForMessage(t *testing.T) {
 		{name: "removed", msg: DownloadRemovedMsg{}, wantType: EventTypeRemoved, wantFound: true},
 		{name: "request", msg: DownloadRequestMsg{}, wantType: EventTypeRequest, wantFound: true},
 		{name: "system", msg: SystemLogMsg{}, wantType: EventTypeSystem, wantFound: true},
+		{name: "tagged", msg: DownloadTaggedMsg{}, wantType: EventTypeTagged, wantFound: true},
+		{name: "verifying", msg: DownloadVerifyingMsg{}, wantType: EventTypeVerifying, wantFound: true},
+		{name: "drain", msg: DrainModeMsg{}, wantType: EventTypeDrain, wantFound: true},
+		{name: "failed", msg: DownloadFailedMsg{}, wantType: EventTypeFailed, wantFound: true},
+		{name: "resync", msg: ResyncMsg{}, wantType: EventTypeResync, wantFound: true},
 		{name: "unknown", msg: struct{}{}, wantType: "", wantFound: false},
 	}
 
@@ -54,6 +62,9 @@ func TestEncodeSSEMessages_BatchProgress(t *testing.T) {
 		if frame.Event != EventTypeProgress {
 			t.Fatalf("frame[%d] event = %q, want %q", i, frame.Event, EventTypeProgress)
 		}
+		if frame.DownloadID != batch[i].DownloadID {
+			t.Fatalf("frame[%d] DownloadID = %q, want %q", i, frame.DownloadID, batch[i].DownloadID)
+		}
 		var decoded ProgressMsg
 		if err := json.Unmarshal(frame.Data, &decoded); err != nil {
 			t.Fatalf("frame[%d] data failed to decode: %v", i, err)
@@ -100,6 +111,50 @@ func TestEncodeDecodeSSEMessage_RoundTrip(t *testing.T) {
 	}
 }
 
+func TestDownloadErrorMsg_KeepsResumeMismatch(t *testing.T) {
+	original := DownloadErrorMsg{
+		DownloadID: "dl-1",
+		Err:        fmt.Errorf("%w: bytes 0-99 differ", types.ErrResumeMismatch),
+	}
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded DownloadErrorMsg
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(decoded.Err, types.ErrResumeMismatch) || decoded.Err.Error() != original.Err.Error() {
+		t.Fatalf("decoded error = %v, want %v wrapping ErrResumeMismatch", decoded.Err, original.Err)
+	}
+}
+
+func TestDownloadErrorMsg_CarriesErrorCode(t *testing.T) {
+	original := DownloadErrorMsg{
+		DownloadID: "dl-1",
+		Err:        fmt.Errorf("chunk 3: %w", &types.HTTPStatusError{StatusCode: 503}),
+	}
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded DownloadErrorMsg
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Code != types.ErrorCodeHTTP5xx {
+		t.Fatalf("decoded code = %q, want %q", decoded.Code, types.ErrorCodeHTTP5xx)
+	}
+
+	// Older servers send no code, so it comes from the message
+	if err := json.Unmarshal([]byte(`{"DownloadID":"dl-1","Err":"lookup example.invalid: no such host"}`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if got := decoded.ErrorCode(); got != types.ErrorCodeDNS {
+		t.Fatalf("ErrorCode() without a code = %q, want %q", got, types.ErrorCodeDNS)
+	}
+}
+
 func TestDecodeSSEMessage_UnknownType(t *testing.T) {
 	decoded, ok, err := DecodeSSEMessage("not-a-real-event", []byte(`{"x":1}`))
 	if err != nil {
@@ -3,6 +3,8 @@ package events
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/SurgeDM/Surge/internal/engine/types"
@@ -16,10 +18,13 @@ type ProgressMsg struct {
 	Speed             float64 // bytes per second
 	Elapsed           time.Duration
 	ActiveConnections int
+	ConnectionSpeeds  []float64 // bytes per second for each open connection
 	ChunkBitmap       []byte
 	BitmapWidth       int
 	ActualChunkSize   int64
 	ChunkProgress     []int64
+	Phase             types.Phase // What the download is busy with
+	Checked           int64       // Bytes of the finished file checked, while verifying
 }
 
 // DownloadCompleteMsg signals that the download finished successfully
@@ -31,6 +36,18 @@ type DownloadCompleteMsg struct {
 	AvgSpeed     float64 // Average download speed in bytes/sec
 	RateLimit    int64
 	RateLimitSet bool
+	Checksum     string // Verified digest as algorithm:hex, if one was given
+	Copies       int    // Copies written alongside the file and moved into place
+	Verified     bool   // Matched a signature from a trusted key
+}
+
+// DownloadVerifyingMsg reports progress checking a finished download against
+// its checksum or signature. A file can be read more than once, so Checked
+// may start over from zero.
+type DownloadVerifyingMsg struct {
+	DownloadID string
+	Checked    int64
+	Total      int64
 }
 
 // DownloadErrorMsg signals that an error occurred
@@ -39,20 +56,32 @@ type DownloadErrorMsg struct {
 	Filename   string
 	DestPath   string
 	Err        error
+	// Code is the kind of Err. It is filled in from Err when left empty.
+	Code types.ErrorCode
+}
+
+// ErrorCode returns Code, or the code of Err when Code is not set.
+func (m DownloadErrorMsg) ErrorCode() types.ErrorCode {
+	if m.Code != "" {
+		return m.Code
+	}
+	return types.ClassifyError(m.Err)
 }
 
 func (m DownloadErrorMsg) MarshalJSON() ([]byte, error) {
 	type encoded struct {
-		DownloadID string `json:"DownloadID"`
-		Filename   string `json:"Filename,omitempty"`
-		DestPath   string `json:"DestPath,omitempty"`
-		Err        string `json:"Err,omitempty"`
+		DownloadID string          `json:"DownloadID"`
+		Filename   string          `json:"Filename,omitempty"`
+		DestPath   string          `json:"DestPath,omitempty"`
+		Err        string          `json:"Err,omitempty"`
+		Code       types.ErrorCode `json:"Code,omitempty"`
 	}
 
 	out := encoded{
 		DownloadID: m.DownloadID,
 		Filename:   m.Filename,
 		DestPath:   m.DestPath,
+		Code:       m.ErrorCode(),
 	}
 	if m.Err != nil {
 		out.Err = m.Err.Error()
@@ -67,6 +96,7 @@ func (m *DownloadErrorMsg) UnmarshalJSON(data []byte) error {
 		Filename   string          `json:"Filename"`
 		DestPath   string          `json:"DestPath"`
 		Err        json.RawMessage `json:"Err"`
+		Code       types.ErrorCode `json:"Code"`
 	}
 	if err := json.Unmarshal(data, &aux); err != nil {
 		return err
@@ -76,6 +106,8 @@ func (m *DownloadErrorMsg) UnmarshalJSON(data []byte) error {
 	m.Filename = aux.Filename
 	m.DestPath = aux.DestPath
 	m.Err = nil
+	// Left empty by older servers; ErrorCode then classifies the message
+	m.Code = aux.Code
 
 	if len(aux.Err) == 0 {
 		return nil
@@ -85,7 +117,7 @@ func (m *DownloadErrorMsg) UnmarshalJSON(data []byte) error {
 	var errStr string
 	if err := json.Unmarshal(aux.Err, &errStr); err == nil {
 		if errStr != "" {
-			m.Err = errors.New(errStr)
+			m.Err = decodeError(errStr)
 		}
 		return nil
 	}
@@ -98,16 +130,45 @@ func (m *DownloadErrorMsg) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// decodeError restores the sentinel errors clients act on, which JSON only
+// carries as text.
+func decodeError(s string) error {
+	if rest, ok := strings.CutPrefix(s, types.ErrResumeMismatch.Error()); ok {
+		return fmt.Errorf("%w%s", types.ErrResumeMismatch, rest)
+	}
+	return errors.New(s)
+}
+
 // DownloadStartedMsg is sent when a download actually starts (after metadata fetch)
 type DownloadStartedMsg struct {
 	DownloadID   string
 	URL          string
+	FinalURL     string // Resolved URL after redirects, empty if not known yet
 	Filename     string
 	Total        int64
 	DestPath     string               // Full path to the destination file
 	State        *types.ProgressState `json:"-"`
 	RateLimit    int64
 	RateLimitSet bool
+	SmallFile    bool // Fresh download under small_file_threshold
+}
+
+// DownloadFailedMsg follows a DownloadErrorMsg once it is decided what to
+// do about the failure: the download is retried at RetryAt or, when RetryAt
+// is zero, it ran out of retries and is moved to the Failed list.
+type DownloadFailedMsg struct {
+	DownloadID string
+	Filename   string
+	Attempts   int // Failures so far
+	Error      string
+	Code       types.ErrorCode
+	RetryAt    time.Time
+}
+
+// Quarantined reports whether the download is given up on rather than
+// retried.
+func (m DownloadFailedMsg) Quarantined() bool {
+	return m.RetryAt.IsZero()
 }
 
 type DownloadPausedMsg struct {
@@ -132,6 +193,28 @@ type DownloadQueuedMsg struct {
 	Mirrors      []string
 	RateLimit    int64
 	RateLimitSet bool
+	Alias        string
+	Tags         []string
+	// ResponseHeaders holds the notable headers the server answered the
+	// probe with.
+	ResponseHeaders map[string]string
+	// Redirects are the URLs the probe was redirected through.
+	Redirects []string
+	// Method is the request method when the download is not a plain GET.
+	Method string
+	// WaitingForNetwork is set for a download added while the network was
+	// down. It is queued again without the flag once it has been probed.
+	WaitingForNetwork bool
+}
+
+// ResyncMsg tells a client that it missed events that can no longer be
+// replayed, so its view of the downloads should be reloaded.
+type ResyncMsg struct{}
+
+// DownloadTaggedMsg is sent when the tags of a download change.
+type DownloadTaggedMsg struct {
+	DownloadID string
+	Tags       []string
 }
 
 type DownloadRemovedMsg struct {
@@ -146,6 +229,12 @@ type SystemLogMsg struct {
 	Message string
 }
 
+// DrainModeMsg is sent when drain mode is turned on or off. While draining,
+// running downloads finish but queued ones do not start.
+type DrainModeMsg struct {
+	Draining bool
+}
+
 // BatchProgressMsg represents a batch of progress updates to reduce TUI render calls
 type BatchProgressMsg []ProgressMsg
 
@@ -158,6 +247,14 @@ type DownloadRequestMsg struct {
 	Path     string
 	Mirrors  []string
 	Headers  map[string]string
+	TLS      types.TLSOptions
+	Request  types.RequestOptions
+}
+
+// DownloadRequestDeclinedMsg reports that the TUI turned a DownloadRequestMsg
+// down. It stays within the process and is not sent to API clients.
+type DownloadRequestDeclinedMsg struct {
+	ID string
 }
 
 // BatchDownloadRequestMsg signals a batch request that should be confirmed once.
@@ -179,12 +276,24 @@ const (
 	EventTypeRequest      = "request"
 	EventTypeBatchRequest = "batch_request"
 	EventTypeSystem       = "system"
+	EventTypeTagged       = "tagged"
+	EventTypeVerifying    = "verifying"
+	EventTypeDrain        = "drain"
+	EventTypeFailed       = "failed"
+	// EventTypeResync tells a reconnecting client that events it missed can
+	// no longer be replayed, so it should reload the full download list.
+	EventTypeResync = "resync"
 )
 
 // SSEMessage represents one server-sent event frame.
 type SSEMessage struct {
 	Event string
 	Data  []byte
+	// ID orders the message in a Journal; zero when it was not journaled.
+	ID uint64
+	// DownloadID is the download the message is about; empty for messages
+	// that are not about a single download.
+	DownloadID string
 }
 
 // EncodeSSEMessages converts an event payload into one or more SSE messages.
@@ -199,8 +308,9 @@ func EncodeSSEMessages(msg interface{}) ([]SSEMessage, error) {
 				return nil, err
 			}
 			frames = append(frames, SSEMessage{
-				Event: EventTypeProgress,
-				Data:  data,
+				Event:      EventTypeProgress,
+				Data:       data,
+				DownloadID: p.DownloadID,
 			})
 		}
 		return frames, nil
@@ -214,12 +324,44 @@ func EncodeSSEMessages(msg interface{}) ([]SSEMessage, error) {
 			return nil, err
 		}
 		return []SSEMessage{{
-			Event: eventType,
-			Data:  data,
+			Event:      eventType,
+			Data:       data,
+			DownloadID: DownloadIDForMessage(msg),
 		}}, nil
 	}
 }
 
+// DownloadIDForMessage returns the download msg is about, or "" for messages
+// about no single download.
+func DownloadIDForMessage(msg interface{}) string {
+	switch m := msg.(type) {
+	case ProgressMsg:
+		return m.DownloadID
+	case DownloadStartedMsg:
+		return m.DownloadID
+	case DownloadCompleteMsg:
+		return m.DownloadID
+	case DownloadErrorMsg:
+		return m.DownloadID
+	case DownloadPausedMsg:
+		return m.DownloadID
+	case DownloadResumedMsg:
+		return m.DownloadID
+	case DownloadQueuedMsg:
+		return m.DownloadID
+	case DownloadRemovedMsg:
+		return m.DownloadID
+	case DownloadTaggedMsg:
+		return m.DownloadID
+	case DownloadVerifyingMsg:
+		return m.DownloadID
+	case DownloadFailedMsg:
+		return m.DownloadID
+	default:
+		return ""
+	}
+}
+
 // EventTypeForMessage maps message payloads to SSE event type names.
 func EventTypeForMessage(msg interface{}) (string, bool) {
 	switch msg.(type) {
@@ -245,6 +387,16 @@ func EventTypeForMessage(msg interface{}) (string, bool) {
 		return EventTypeBatchRequest, true
 	case SystemLogMsg:
 		return EventTypeSystem, true
+	case DownloadTaggedMsg:
+		return EventTypeTagged, true
+	case DownloadVerifyingMsg:
+		return EventTypeVerifying, true
+	case DrainModeMsg:
+		return EventTypeDrain, true
+	case DownloadFailedMsg:
+		return EventTypeFailed, true
+	case ResyncMsg:
+		return EventTypeResync, true
 	default:
 		return "", false
 	}
@@ -321,6 +473,32 @@ func DecodeSSEMessage(eventType string, data []byte) (interface{}, bool, error)
 			return nil, true, err
 		}
 		msg = m
+	case EventTypeTagged:
+		var m DownloadTaggedMsg
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, true, err
+		}
+		msg = m
+	case EventTypeVerifying:
+		var m DownloadVerifyingMsg
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, true, err
+		}
+		msg = m
+	case EventTypeFailed:
+		var m DownloadFailedMsg
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, true, err
+		}
+		msg = m
+	case EventTypeDrain:
+		var m DrainModeMsg
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, true, err
+		}
+		msg = m
+	case EventTypeResync:
+		msg = ResyncMsg{}
 	default:
 		return nil, false, nil
 	}
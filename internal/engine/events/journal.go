@@ -0,0 +1,203 @@
+package events
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultJournalSize is how many recent events a Journal keeps for replay.
+const DefaultJournalSize = 1024
+
+// journalSubscriberBuffer is how many frames a subscriber may fall behind
+// before it is dropped and has to reconnect and replay.
+const journalSubscriberBuffer = 256
+
+// Journal numbers SSE frames in the order they happen and keeps the most
+// recent ones, so a client that reconnects with a Last-Event-ID is sent
+// exactly what it missed. Progress frames are numbered but not kept: the next
+// tick replaces them anyway.
+type Journal struct {
+	mu     sync.Mutex
+	ring   []SSEMessage
+	start  int
+	count  int
+	lastID uint64
+	// evicted is the ID of the newest frame pushed out of the ring. A client
+	// that last saw an older frame has a gap that cannot be replayed.
+	evicted uint64
+	subs    map[*JournalSubscription]struct{}
+}
+
+// NewJournal returns a Journal that keeps the last size non-progress frames.
+func NewJournal(size int) *Journal {
+	if size <= 0 {
+		size = DefaultJournalSize
+	}
+	return &Journal{
+		ring: make([]SSEMessage, size),
+		subs: make(map[*JournalSubscription]struct{}),
+	}
+}
+
+// JournalSubscription delivers the frames appended after it was created.
+type JournalSubscription struct {
+	// C receives frames in order. It is closed when the subscriber falls too
+	// far behind or the journal is closed; the client should reconnect with
+	// the last ID it saw.
+	C chan SSEMessage
+
+	journal *Journal
+	filter  EventFilter
+}
+
+// EventFilter picks the frames a subscriber is sent. The zero value lets
+// every frame through.
+type EventFilter struct {
+	// Types limits frames to these event types when non-empty.
+	Types map[string]bool
+	// DownloadID limits frames to the ones about this download when set.
+	DownloadID string
+}
+
+// ParseEventFilter builds a filter from a comma-separated list of event
+// types and a download ID, either of which may be empty.
+func ParseEventFilter(types, downloadID string) (EventFilter, error) {
+	filter := EventFilter{DownloadID: strings.TrimSpace(downloadID)}
+	for _, name := range strings.Split(types, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !isEventType(name) {
+			return EventFilter{}, fmt.Errorf("unknown event type %q", name)
+		}
+		if filter.Types == nil {
+			filter.Types = make(map[string]bool)
+		}
+		filter.Types[name] = true
+	}
+	return filter, nil
+}
+
+func isEventType(name string) bool {
+	switch name {
+	case EventTypeProgress, EventTypeStarted, EventTypeComplete, EventTypeError,
+		EventTypePaused, EventTypeResumed, EventTypeQueued, EventTypeRemoved,
+		EventTypeRequest, EventTypeBatchRequest, EventTypeSystem, EventTypeTagged,
+		EventTypeVerifying:
+		return true
+	default:
+		return false
+	}
+}
+
+// isProgressEvent reports whether frames of this type only carry the latest
+// numbers of something in flight: they are coalesced, not replayed, and may
+// be dropped for a slow client.
+func isProgressEvent(name string) bool {
+	return name == EventTypeProgress || name == EventTypeVerifying
+}
+
+// Match reports whether frame passes the filter.
+func (f EventFilter) Match(frame SSEMessage) bool {
+	if len(f.Types) > 0 && !f.Types[frame.Event] {
+		return false
+	}
+	return f.DownloadID == "" || frame.DownloadID == f.DownloadID
+}
+
+// Append numbers frames, keeps the ones worth replaying and hands them to
+// every subscriber.
+func (j *Journal) Append(frames ...SSEMessage) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, frame := range frames {
+		j.lastID++
+		frame.ID = j.lastID
+
+		progress := isProgressEvent(frame.Event)
+		if !progress {
+			j.keepLocked(frame)
+		}
+
+		for sub := range j.subs {
+			if !sub.filter.Match(frame) {
+				continue
+			}
+			select {
+			case sub.C <- frame:
+			default:
+				if !progress {
+					// Dropping a state change would leave the client out of
+					// sync, so cut it off; it replays the gap on reconnect.
+					j.dropLocked(sub)
+				}
+			}
+		}
+	}
+}
+
+func (j *Journal) keepLocked(frame SSEMessage) {
+	if j.count == len(j.ring) {
+		j.evicted = j.ring[j.start].ID
+		j.ring[j.start] = frame
+		j.start = (j.start + 1) % len(j.ring)
+		return
+	}
+	j.ring[(j.start+j.count)%len(j.ring)] = frame
+	j.count++
+}
+
+// Subscribe starts delivering new frames that match filter. When
+// lastEventID is set, the matching kept frames after it are returned as
+// backlog; complete is false when some of the missed frames were already
+// evicted, or the ID is not one this journal handed out, and the client has
+// to resync from a full listing.
+func (j *Journal) Subscribe(lastEventID string, filter EventFilter) (sub *JournalSubscription, backlog []SSEMessage, complete bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	sub = &JournalSubscription{C: make(chan SSEMessage, journalSubscriberBuffer), journal: j, filter: filter}
+	j.subs[sub] = struct{}{}
+
+	if lastEventID == "" {
+		return sub, nil, true
+	}
+	last, err := strconv.ParseUint(lastEventID, 10, 64)
+	if err != nil || last > j.lastID {
+		return sub, nil, false
+	}
+	for i := range j.count {
+		if frame := j.ring[(j.start+i)%len(j.ring)]; frame.ID > last && filter.Match(frame) {
+			backlog = append(backlog, frame)
+		}
+	}
+	return sub, backlog, last >= j.evicted
+}
+
+// Close stops delivering frames to sub. It is safe to call more than once.
+func (s *JournalSubscription) Close() {
+	s.journal.mu.Lock()
+	defer s.journal.mu.Unlock()
+	s.journal.dropLocked(s)
+}
+
+func (j *Journal) dropLocked(sub *JournalSubscription) {
+	if _, ok := j.subs[sub]; ok {
+		delete(j.subs, sub)
+		close(sub.C)
+	}
+}
+
+// CloseSubscribers ends every current subscription, as when the event source
+// the journal is fed from goes away. Kept frames stay available for replay.
+func (j *Journal) CloseSubscribers() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for sub := range j.subs {
+		j.dropLocked(sub)
+	}
+}
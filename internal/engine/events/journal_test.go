@@ -0,0 +1,107 @@
+package events
+
+import (
+	"fmt"
+	"testing"
+)
+
+func journalFrame(event string, n int) SSEMessage {
+	return SSEMessage{Event: event, Data: []byte(fmt.Sprintf(`{"n":%d}`, n))}
+}
+
+func TestJournal_ReplaysAfterLastEventID(t *testing.T) {
+	j := NewJournal(8)
+	j.Append(journalFrame(EventTypeQueued, 1), journalFrame(EventTypeProgress, 2), journalFrame(EventTypeStarted, 3))
+
+	sub, backlog, complete := j.Subscribe("1", EventFilter{})
+	defer sub.Close()
+	if !complete {
+		t.Fatal("replay from a kept ID should be complete")
+	}
+	// Progress frames are numbered but not kept.
+	if len(backlog) != 1 || backlog[0].ID != 3 || backlog[0].Event != EventTypeStarted {
+		t.Fatalf("backlog = %+v, want only frame 3", backlog)
+	}
+
+	j.Append(journalFrame(EventTypeComplete, 4))
+	if frame := <-sub.C; frame.ID != 4 {
+		t.Fatalf("live frame ID = %d, want 4", frame.ID)
+	}
+}
+
+func TestJournal_EvictedOrUnknownIDNeedsResync(t *testing.T) {
+	j := NewJournal(2)
+	for n := range 4 {
+		j.Append(journalFrame(EventTypeQueued, n))
+	}
+
+	if _, backlog, complete := j.Subscribe("1", EventFilter{}); complete || len(backlog) != 2 {
+		t.Fatalf("evicted ID: complete = %v, backlog = %d; want false, 2", complete, len(backlog))
+	}
+	if _, backlog, complete := j.Subscribe("2", EventFilter{}); !complete || len(backlog) != 2 {
+		t.Fatalf("oldest kept boundary: complete = %v, backlog = %d; want true, 2", complete, len(backlog))
+	}
+	if _, _, complete := j.Subscribe("99", EventFilter{}); complete {
+		t.Fatal("an ID from before a restart should need a resync")
+	}
+}
+
+func TestJournal_SlowSubscriberIsCutOff(t *testing.T) {
+	j := NewJournal(DefaultJournalSize)
+	sub, _, _ := j.Subscribe("", EventFilter{})
+
+	for n := range journalSubscriberBuffer {
+		j.Append(journalFrame(EventTypeProgress, n))
+	}
+	// A full buffer drops progress but keeps the subscriber.
+	j.Append(journalFrame(EventTypeProgress, -1))
+	if _, ok := j.subs[sub]; !ok {
+		t.Fatal("dropping progress should not cut the subscriber off")
+	}
+
+	j.Append(journalFrame(EventTypeComplete, -2))
+	for range sub.C {
+	}
+	if _, ok := j.subs[sub]; ok {
+		t.Fatal("a subscriber that cannot take a state change should be cut off")
+	}
+	sub.Close()
+}
+
+func TestJournal_FilterByTypeAndDownload(t *testing.T) {
+	j := NewJournal(8)
+	filter, err := ParseEventFilter("complete, error", "a")
+	if err != nil {
+		t.Fatalf("ParseEventFilter returned error: %v", err)
+	}
+
+	j.Append(SSEMessage{Event: EventTypeComplete, DownloadID: "a"})
+	sub, backlog, _ := j.Subscribe("0", filter)
+	defer sub.Close()
+	if len(backlog) != 1 || backlog[0].ID != 1 {
+		t.Fatalf("backlog = %+v, want only frame 1", backlog)
+	}
+
+	j.Append(
+		SSEMessage{Event: EventTypeProgress, DownloadID: "a"},
+		SSEMessage{Event: EventTypeError, DownloadID: "b"},
+		SSEMessage{Event: EventTypeSystem},
+		SSEMessage{Event: EventTypeError, DownloadID: "a"},
+	)
+	if frame := <-sub.C; frame.ID != 5 {
+		t.Fatalf("first live frame ID = %d, want 5", frame.ID)
+	}
+	if len(sub.C) != 0 {
+		t.Fatalf("%d unexpected frames passed the filter", len(sub.C))
+	}
+}
+
+func TestParseEventFilter_RejectsUnknownType(t *testing.T) {
+	if _, err := ParseEventFilter("complete,finished", ""); err == nil {
+		t.Fatal("expected an error for an unknown event type")
+	}
+	filter, err := ParseEventFilter("", "")
+	if err != nil || !filter.Match(SSEMessage{Event: EventTypeProgress}) {
+		t.Fatalf("empty filter = %+v, %v; want one matching everything", filter, err)
+	}
+}
@@ -0,0 +1,154 @@
+package engine
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+)
+
+// FairLimiter shares a global RateLimiter between downloads by weighted fair
+// queueing instead of first come, first served. Each download draws through
+// its own FairFlow; waiting requests are granted in order of their virtual
+// start time, so a download that just started is served next rather than
+// after every request already queued by busier downloads, and each busy
+// download ends up with bandwidth in proportion to its weight.
+type FairLimiter struct {
+	bucket *RateLimiter
+
+	mu      sync.Mutex
+	vtime   float64 // start tag of the request holding the bucket
+	seq     uint64
+	busy    bool
+	waiting fairQueue
+}
+
+// NewFairLimiter returns a FairLimiter drawing tokens from bucket.
+func NewFairLimiter(bucket *RateLimiter) *FairLimiter {
+	return &FairLimiter{bucket: bucket}
+}
+
+// NewFlow returns a limiter for one download. Weight sets its share relative
+// to the other busy flows; values below 1 are treated as 1.
+func (f *FairLimiter) NewFlow(weight float64) *FairFlow {
+	return &FairFlow{limiter: f, weight: max(weight, 1)}
+}
+
+// NewWeightedFlow returns a limiter for one download whose weight is read
+// from weight before each request, so its share can follow its progress.
+// weight must not wait on the limiter.
+func (f *FairLimiter) NewWeightedFlow(weight func() float64) *FairFlow {
+	return &FairFlow{limiter: f, weight: 1, weightFn: weight}
+}
+
+// FairFlow is one download's view of a FairLimiter.
+type FairFlow struct {
+	limiter  *FairLimiter
+	weight   float64
+	weightFn func() float64 // when set, replaces weight on each request
+	finish   float64        // virtual finish tag of this flow's last request
+}
+
+type fairRequest struct {
+	start float64
+	seq   uint64
+	index int // position in the queue, or -1 once granted
+	ready chan struct{}
+}
+
+// WaitN blocks until n bytes may be transferred for this flow.
+func (fl *FairFlow) WaitN(ctx context.Context, n int64) error {
+	f := fl.limiter
+	if n <= 0 || f.bucket.Rate() <= 0 {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	weight := fl.weight
+	if fl.weightFn != nil {
+		weight = max(fl.weightFn(), 1)
+	}
+
+	f.mu.Lock()
+	req := &fairRequest{start: max(f.vtime, fl.finish), seq: f.seq, index: -1, ready: make(chan struct{})}
+	f.seq++
+	fl.finish = req.start + float64(n)/weight
+	if !f.busy {
+		f.busy = true
+		f.vtime = req.start
+		f.mu.Unlock()
+	} else {
+		heap.Push(&f.waiting, req)
+		f.mu.Unlock()
+
+		select {
+		case <-req.ready:
+		case <-ctx.Done():
+			f.mu.Lock()
+			if req.index >= 0 {
+				heap.Remove(&f.waiting, req.index)
+				f.mu.Unlock()
+				return ctx.Err()
+			}
+			// Granted while being cancelled: pass the turn on.
+			f.mu.Unlock()
+			f.release()
+			return ctx.Err()
+		}
+	}
+
+	err := f.bucket.WaitN(ctx, n)
+	f.release()
+	return err
+}
+
+// Refund returns unused tokens to the shared bucket.
+func (fl *FairFlow) Refund(n int64) {
+	fl.limiter.bucket.Refund(n)
+}
+
+// release hands the bucket to the waiting request with the earliest start tag.
+func (f *FairLimiter) release() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.waiting.Len() == 0 {
+		f.busy = false
+		return
+	}
+	next := heap.Pop(&f.waiting).(*fairRequest)
+	f.vtime = next.start
+	close(next.ready)
+}
+
+// fairQueue is a min-heap of requests by start tag, then arrival order.
+type fairQueue []*fairRequest
+
+func (q fairQueue) Len() int { return len(q) }
+
+func (q fairQueue) Less(i, j int) bool {
+	if q[i].start != q[j].start {
+		return q[i].start < q[j].start
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q fairQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *fairQueue) Push(x any) {
+	req := x.(*fairRequest)
+	req.index = len(*q)
+	*q = append(*q, req)
+}
+
+func (q *fairQueue) Pop() any {
+	old := *q
+	req := old[len(old)-1]
+	old[len(old)-1] = nil
+	req.index = -1
+	*q = old[:len(old)-1]
+	return req
+}
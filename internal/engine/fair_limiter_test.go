@@ -0,0 +1,152 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFairLimiter_NewFlowServedBeforeBacklog(t *testing.T) {
+	limiter := NewFairLimiter(NewRateLimiter(20000, 0))
+	busy := limiter.NewFlow(1)
+	fresh := limiter.NewFlow(1)
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	wait := func(flow *FairFlow, name string) {
+		defer wg.Done()
+		if err := flow.WaitN(context.Background(), 1000); err != nil {
+			t.Errorf("%s: %v", name, err)
+			return
+		}
+		mu.Lock()
+		order = append(order, name)
+		mu.Unlock()
+	}
+
+	for _, name := range []string{"busy1", "busy2", "busy3", "busy4"} {
+		wg.Add(1)
+		go wait(busy, name)
+		time.Sleep(5 * time.Millisecond)
+	}
+	wg.Add(1)
+	go wait(fresh, "fresh")
+	wg.Wait()
+
+	if len(order) != 5 {
+		t.Fatalf("order = %v", order)
+	}
+	if order[1] != "fresh" {
+		t.Errorf("order = %v, want the new flow served right after the request in progress", order)
+	}
+}
+
+func TestFairLimiter_WeightsShareBandwidth(t *testing.T) {
+	limiter := NewFairLimiter(NewRateLimiter(200000, 0))
+	heavy := limiter.NewFlow(3)
+	light := limiter.NewFlow(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	// Several workers per flow, as a concurrent download has, keep both
+	// flows backlogged.
+	var wg sync.WaitGroup
+	var heavyBytes, lightBytes atomic.Int64
+	drain := func(flow *FairFlow, total *atomic.Int64) {
+		defer wg.Done()
+		for flow.WaitN(ctx, 1000) == nil {
+			total.Add(1000)
+		}
+	}
+	for range 4 {
+		wg.Add(2)
+		go drain(heavy, &heavyBytes)
+		go drain(light, &lightBytes)
+	}
+	wg.Wait()
+
+	heavyTotal, lightTotal := heavyBytes.Load(), lightBytes.Load()
+	if lightTotal == 0 {
+		t.Fatal("light flow got no bandwidth")
+	}
+	if ratio := float64(heavyTotal) / float64(lightTotal); ratio < 2 || ratio > 4 {
+		t.Errorf("heavy/light = %d/%d (%.1f), want about 3", heavyTotal, lightTotal, ratio)
+	}
+}
+
+func TestFairLimiter_CancelledWaiterLeavesQueue(t *testing.T) {
+	limiter := NewFairLimiter(NewRateLimiter(1, 0))
+	first := limiter.NewFlow(1)
+	second := limiter.NewFlow(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firstDone := make(chan error, 1)
+	go func() { firstDone <- first.WaitN(ctx, 100) }()
+	time.Sleep(20 * time.Millisecond)
+
+	secondCtx, secondCancel := context.WithCancel(context.Background())
+	secondDone := make(chan error, 1)
+	go func() { secondDone <- second.WaitN(secondCtx, 100) }()
+	time.Sleep(20 * time.Millisecond)
+
+	secondCancel()
+	select {
+	case err := <-secondDone:
+		if err == nil {
+			t.Fatal("cancelled waiter returned nil")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("cancelled waiter did not return")
+	}
+
+	cancel()
+	<-firstDone
+
+	limiter.bucket.SetRate(0, 0)
+	if err := first.WaitN(context.Background(), 100); err != nil {
+		t.Fatalf("WaitN after queue drained: %v", err)
+	}
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	if limiter.busy || limiter.waiting.Len() != 0 {
+		t.Errorf("busy = %v, waiting = %d, want an idle queue", limiter.busy, limiter.waiting.Len())
+	}
+}
+
+func TestFairLimiter_WeightedFlowReadsItsWeight(t *testing.T) {
+	limiter := NewFairLimiter(NewRateLimiter(200000, 0))
+	var weight atomic.Int64
+	weight.Store(3)
+	heavy := limiter.NewWeightedFlow(func() float64 { return float64(weight.Load()) })
+	light := limiter.NewFlow(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var heavyBytes, lightBytes atomic.Int64
+	drain := func(flow *FairFlow, total *atomic.Int64) {
+		defer wg.Done()
+		for flow.WaitN(ctx, 1000) == nil {
+			total.Add(1000)
+		}
+	}
+	for range 4 {
+		wg.Add(2)
+		go drain(heavy, &heavyBytes)
+		go drain(light, &lightBytes)
+	}
+	wg.Wait()
+
+	heavyTotal, lightTotal := heavyBytes.Load(), lightBytes.Load()
+	if lightTotal == 0 {
+		t.Fatal("light flow got no bandwidth")
+	}
+	if ratio := float64(heavyTotal) / float64(lightTotal); ratio < 2 || ratio > 4 {
+		t.Errorf("heavy/light = %d/%d (%.1f), want about 3", heavyTotal, lightTotal, ratio)
+	}
+}
@@ -0,0 +1,34 @@
+package engine
+
+import (
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// binaryExtensions name files a server has no reason to answer with a web
+// page: archives, disk images, installers, packages and media.
+var binaryExtensions = map[string]bool{
+	".zip": true, ".7z": true, ".rar": true, ".tar": true, ".gz": true, ".tgz": true,
+	".bz2": true, ".xz": true, ".zst": true,
+	".iso": true, ".img": true, ".dmg": true, ".vhd": true, ".vmdk": true, ".qcow2": true,
+	".exe": true, ".msi": true, ".pkg": true, ".deb": true, ".rpm": true, ".apk": true,
+	".appimage": true, ".jar": true, ".whl": true, ".bin": true,
+	".pdf": true, ".mp4": true, ".mkv": true, ".mp3": true, ".flac": true,
+}
+
+// UnexpectedHTML reports whether contentType is a web page while rawurl
+// names a binary file, which is what a login or error page served in place
+// of the file looks like.
+func UnexpectedHTML(rawurl, contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || (mediaType != "text/html" && mediaType != "application/xhtml+xml") {
+		return false
+	}
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return false
+	}
+	return binaryExtensions[strings.ToLower(path.Ext(u.Path))]
+}
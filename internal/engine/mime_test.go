@@ -0,0 +1,22 @@
+package engine
+
+import "testing"
+
+func TestUnexpectedHTML(t *testing.T) {
+	tests := []struct {
+		url, contentType string
+		want             bool
+	}{
+		{"https://example.com/release.zip", "text/html; charset=utf-8", true},
+		{"https://example.com/ubuntu.ISO?token=abc", "application/xhtml+xml", true},
+		{"https://example.com/release.zip", "application/zip", false},
+		{"https://example.com/release.zip", "", false},
+		{"https://example.com/index.html", "text/html", false},
+		{"https://example.com/download?id=42", "text/html", false},
+	}
+	for _, tt := range tests {
+		if got := UnexpectedHTML(tt.url, tt.contentType); got != tt.want {
+			t.Errorf("UnexpectedHTML(%q, %q) = %v, want %v", tt.url, tt.contentType, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package engine
+
+import (
+	"net/http"
+	"os"
+	"time"
+)
+
+// LastModified returns the time in resp's Last-Modified header, or the zero
+// time when it is missing or unparseable.
+func LastModified(resp *http.Response) time.Time {
+	if resp == nil {
+		return time.Time{}
+	}
+	t, err := http.ParseTime(resp.Header.Get("Last-Modified"))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// SetModTime sets both the access and modification time of path to t. The
+// zero time leaves the file alone.
+func SetModTime(path string, t time.Time) error {
+	if t.IsZero() {
+		return nil
+	}
+	return os.Chtimes(path, t, t)
+}
@@ -0,0 +1,32 @@
+package engine
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLastModifiedAndSetModTime(t *testing.T) {
+	want := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	resp := &http.Response{Header: http.Header{"Last-Modified": {want.Format(http.TimeFormat)}}}
+	if got := LastModified(resp); !got.Equal(want) {
+		t.Fatalf("LastModified = %v, want %v", got, want)
+	}
+	if got := LastModified(&http.Response{Header: http.Header{"Last-Modified": {"yesterday"}}}); !got.IsZero() {
+		t.Fatalf("LastModified = %v for a bad header, want zero", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "f")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetModTime(path, want); err != nil {
+		t.Fatal(err)
+	}
+	info, _ := os.Stat(path)
+	if !info.ModTime().Equal(want) {
+		t.Fatalf("mtime = %v, want %v", info.ModTime(), want)
+	}
+}
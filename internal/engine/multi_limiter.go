@@ -2,16 +2,26 @@ package engine
 
 import "context"
 
+// Limiter is a byte limiter that can take back tokens it granted.
+type Limiter interface {
+	WaitN(ctx context.Context, n int64) error
+	Refund(n int64)
+}
+
 type MultiLimiter struct {
-	limiters []*RateLimiter
+	limiters []Limiter
 }
 
-func NewMultiLimiter(limiters ...*RateLimiter) *MultiLimiter {
-	filtered := make([]*RateLimiter, 0, len(limiters))
+func NewMultiLimiter(limiters ...Limiter) *MultiLimiter {
+	filtered := make([]Limiter, 0, len(limiters))
 	for _, l := range limiters {
-		if l != nil {
-			filtered = append(filtered, l)
+		if l == nil {
+			continue
+		}
+		if rl, ok := l.(*RateLimiter); ok && rl == nil {
+			continue
 		}
+		filtered = append(filtered, l)
 	}
 	return &MultiLimiter{limiters: filtered}
 }
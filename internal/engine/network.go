@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/SurgeDM/Surge/internal/engine/types"
@@ -14,9 +15,16 @@ import (
 )
 
 type poolKey struct {
-	proxyURL  string
-	customDNS string
-	maxConns  int
+	proxyURL       string
+	proxyFallbacks string
+	noSystemProxy  bool
+	customDNS      string
+	bindInterface  string
+	sourcePorts    string
+	maxConns       int
+	tls            types.TLSOptions
+	connectTimeout time.Duration
+	headerTimeout  time.Duration
 }
 
 // transportLease tracks a specific transport's usage and cleanup lifecycle.
@@ -43,6 +51,46 @@ var DefaultNetworkPool = &NetworkPool{
 
 // AcquireTransport returns a shared transport for the given configuration.
 func (p *NetworkPool) AcquireTransport(proxyURL, customDNS string, maxConns int) *http.Transport {
+	// Default TLS options never fail to build
+	t, _ := p.AcquireTransportWithTLS(proxyURL, customDNS, types.TLSOptions{}, maxConns)
+	return t
+}
+
+// AcquireTransportWithTLS returns a shared transport that also applies
+// tlsOpts. It fails when the CA bundle or client certificate cannot be loaded.
+func (p *NetworkPool) AcquireTransportWithTLS(proxyURL, customDNS string, tlsOpts types.TLSOptions, maxConns int) (*http.Transport, error) {
+	return p.acquire(poolKey{
+		proxyURL:       proxyURL,
+		customDNS:      customDNS,
+		maxConns:       maxConns,
+		tls:            tlsOpts,
+		connectTimeout: types.DialTimeout,
+		headerTimeout:  types.DefaultResponseHeaderTimeout,
+	})
+}
+
+// AcquireTransportFor returns a shared transport for the proxy, DNS, TLS,
+// interface, source port and timeout settings of r, which may be nil for
+// the defaults.
+func (p *NetworkPool) AcquireTransportFor(r *types.RuntimeConfig, maxConns int) (*http.Transport, error) {
+	key := poolKey{
+		maxConns:       maxConns,
+		tls:            r.GetTLSOptions(),
+		connectTimeout: r.GetConnectTimeout(),
+		headerTimeout:  r.GetResponseHeaderTimeout(),
+	}
+	if r != nil {
+		key.proxyURL = r.ProxyURL
+		key.proxyFallbacks = r.ProxyFallbacks
+		key.noSystemProxy = r.IgnoreSystemProxy
+		key.customDNS = r.CustomDNS
+		key.bindInterface = r.BindInterface
+		key.sourcePorts = r.SourcePorts
+	}
+	return p.acquire(key)
+}
+
+func (p *NetworkPool) acquire(key poolKey) (*http.Transport, error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
@@ -53,11 +101,14 @@ func (p *NetworkPool) AcquireTransport(proxyURL, customDNS string, maxConns int)
 		p.transportMap = make(map[*http.Transport]*transportLease)
 	}
 
-	key := poolKey{proxyURL, customDNS, maxConns}
-
 	lease, ok := p.configMap[key]
 	if !ok {
-		t := p.createNewTransport(proxyURL, customDNS, maxConns)
+		tlsConfig, err := buildTLSConfig(key.tls)
+		if err != nil {
+			return nil, err
+		}
+		t := p.createNewTransport(key)
+		t.TLSClientConfig = tlsConfig
 		lease = &transportLease{
 			transport: t,
 			key:       key,
@@ -75,7 +126,7 @@ func (p *NetworkPool) AcquireTransport(proxyURL, customDNS string, maxConns int)
 	lease.refs++
 	utils.Debug("NetworkPool: AcquireTransport (key=%+v, refs=%d)", key, lease.refs)
 
-	return lease.transport
+	return lease.transport, nil
 }
 
 // ReleaseTransport marks a specific transport lease as returned.
@@ -116,33 +167,107 @@ func (p *NetworkPool) ReleaseTransport(t *http.Transport) {
 	}
 }
 
-func (p *NetworkPool) createNewTransport(proxyURL, customDNS string, maxConns int) *http.Transport {
-	utils.Debug("NetworkPool: creating new shared transport (proxy=%s, limit=%d)", proxyURL, maxConns)
+// EvictIdle drops every transport no download is using, so the next
+// download builds a fresh one from current settings. Transports held by
+// active downloads are left alone and age out after they are released.
+func (p *NetworkPool) EvictIdle() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	evicted := 0
+	for key, lease := range p.configMap {
+		if lease.refs > 0 {
+			continue
+		}
+		if lease.idleTimer != nil {
+			lease.idleTimer.Stop()
+			lease.idleTimer = nil
+		}
+		lease.timerGen++
+		lease.transport.CloseIdleConnections()
+		delete(p.configMap, key)
+		delete(p.transportMap, lease.transport)
+		evicted++
+	}
+	utils.Debug("NetworkPool: evicted %d idle transports", evicted)
+	return evicted
+}
+
+func (p *NetworkPool) createNewTransport(key poolKey) *http.Transport {
+	utils.Debug("NetworkPool: creating new shared transport (proxy=%s, limit=%d)", key.proxyURL, key.maxConns)
 
 	dialer := &net.Dialer{
-		Timeout:   types.DialTimeout,
+		Timeout:   key.connectTimeout,
 		KeepAlive: types.KeepAliveDuration,
 	}
-	utils.ConfigureDialer(dialer, customDNS)
+	utils.ConfigureDialer(dialer, key.customDNS)
 
-	proxyFunc := http.ProxyFromEnvironment
-	if proxyURL != "" {
-		if parsed, err := url.Parse(proxyURL); err == nil {
+	proxyFunc := DefaultSystemProxy.Proxy
+	if key.noSystemProxy {
+		proxyFunc = http.ProxyFromEnvironment
+	}
+	failover := DefaultProxyFailovers.For(key.proxyURL, key.proxyFallbacks)
+	if failover != nil {
+		proxyFunc = failover.Proxy
+	} else if key.proxyURL != "" {
+		if parsed, err := url.Parse(key.proxyURL); err == nil {
 			proxyFunc = http.ProxyURL(parsed)
 		} else {
-			utils.Debug("NetworkPool: invalid proxy URL %s: %v", proxyURL, err)
+			utils.Debug("NetworkPool: invalid proxy URL %s: %v", key.proxyURL, err)
 		}
 	}
 
-	finalMaxConns := maxConns
+	finalMaxConns := key.maxConns
 	if finalMaxConns <= 0 {
 		finalMaxConns = types.PoolMaxConnsPerHost
 	}
 
+	// Connections take turns across the interfaces of a + joined list
+	ifaces := types.SplitBindInterfaces(key.bindInterface)
+	var nextIface atomic.Uint32
+	// Settings validate the ranges, so an error leaves ports unrestricted
+	ports, _ := types.ParsePortRanges(key.sourcePorts)
+
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		d := dialer
+		if len(ifaces) > 0 {
+			iface := ifaces[int(nextIface.Add(1)-1)%len(ifaces)]
+			local, err := bindAddr(iface, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			bound := *dialer
+			bound.LocalAddr = local
+			if net.ParseIP(iface) == nil {
+				bound.Control = bindToDevice(iface)
+			}
+			d = &bound
+		}
+		if len(ports) > 0 {
+			return dialFromPorts(ctx, d, ports, network, addr)
+		}
+		return d.DialContext(ctx, network, addr)
+	}
+
 	return &http.Transport{
 		Proxy: proxyFunc,
 		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
-			return dialer.DialContext(ctx, network, addr)
+			conn, err := dial(ctx, network, addr)
+			// With a proxy every connection is to the proxy; one the request
+			// gave up on says nothing about it
+			if err != nil {
+				if failover != nil && ctx.Err() == nil {
+					failover.Fail(addr, dialFailure(err))
+				}
+				return nil, err
+			}
+			return ShapeUpload(conn), nil
+		},
+		OnProxyConnectResponse: func(_ context.Context, proxyURL *url.URL, _ *http.Request, resp *http.Response) error {
+			if failover != nil && proxyGatewayError(resp.StatusCode) {
+				failover.Fail(proxyAddr(proxyURL), resp.Status)
+			}
+			return nil
 		},
 
 		MaxIdleConns:        types.PoolMaxIdleConns,
@@ -151,7 +276,7 @@ func (p *NetworkPool) createNewTransport(proxyURL, customDNS string, maxConns in
 
 		IdleConnTimeout:       types.DefaultIdleConnTimeout,
 		TLSHandshakeTimeout:   types.DefaultTLSHandshakeTimeout,
-		ResponseHeaderTimeout: types.DefaultResponseHeaderTimeout,
+		ResponseHeaderTimeout: key.headerTimeout,
 		ExpectContinueTimeout: types.DefaultExpectContinueTimeout,
 
 		DisableCompression: true,
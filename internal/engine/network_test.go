@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"net/http/httptrace"
 	"testing"
+	"time"
 
 	"github.com/SurgeDM/Surge/internal/engine/types"
 )
@@ -110,3 +111,48 @@ func TestNetworkPool_ConfigChange(t *testing.T) {
 		t.Error("Expected transport reuse for identical config")
 	}
 }
+
+func TestNetworkPool_Timeouts(t *testing.T) {
+	pool := &NetworkPool{}
+
+	defaults, err := pool.AcquireTransportFor(nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.ReleaseTransport(defaults)
+	if defaults.ResponseHeaderTimeout != types.DefaultResponseHeaderTimeout {
+		t.Errorf("ResponseHeaderTimeout = %v, want the default", defaults.ResponseHeaderTimeout)
+	}
+
+	slow, err := pool.AcquireTransportFor(&types.RuntimeConfig{ResponseHeaderTimeout: time.Minute}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.ReleaseTransport(slow)
+	if slow == defaults {
+		t.Fatal("a download with its own timeouts should not share the default transport")
+	}
+	if slow.ResponseHeaderTimeout != time.Minute {
+		t.Errorf("ResponseHeaderTimeout = %v, want 1m", slow.ResponseHeaderTimeout)
+	}
+}
+
+func TestNetworkPool_EvictIdleKeepsActiveTransports(t *testing.T) {
+	pool := &NetworkPool{}
+
+	active := pool.AcquireTransport("http://proxy1", "", 0)
+	idle := pool.AcquireTransport("http://proxy2", "", 0)
+	pool.ReleaseTransport(idle)
+
+	if got := pool.EvictIdle(); got != 1 {
+		t.Fatalf("EvictIdle() = %d, want 1", got)
+	}
+	if _, ok := pool.transportMap[idle]; ok {
+		t.Error("idle transport should have been evicted")
+	}
+	if again := pool.AcquireTransport("http://proxy1", "", 0); again != active {
+		t.Error("transport in use should survive EvictIdle")
+	}
+	pool.ReleaseTransport(active)
+	pool.ReleaseTransport(active)
+}
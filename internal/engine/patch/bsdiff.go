@@ -0,0 +1,115 @@
+package patch
+
+import (
+	"bufio"
+	"compress/bzip2"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// bsdiffChunk is how much of a diff or extra block is handled at a time.
+const bsdiffChunk = 64 << 10
+
+// applyBSDiff applies a BSDIFF40 patch: a header, then bzip2 streams of
+// control triples, of bytes added to the base, and of new bytes.
+func applyBSDiff(ctx context.Context, dst io.Writer, base io.ReaderAt, patch io.ReaderAt, patchSize int64) error {
+	header := make([]byte, 32)
+	if _, err := patch.ReadAt(header, 0); err != nil {
+		return fmt.Errorf("%w: short header", ErrCorrupt)
+	}
+	ctrlLen, diffLen, newSize := offtin(header[8:]), offtin(header[16:]), offtin(header[24:])
+	if ctrlLen < 0 || diffLen < 0 || newSize < 0 || 32+ctrlLen+diffLen > patchSize {
+		return fmt.Errorf("%w: bad header", ErrCorrupt)
+	}
+	section := func(off, n int64) *bufio.Reader {
+		return bufio.NewReader(bzip2.NewReader(io.NewSectionReader(patch, off, n)))
+	}
+	ctrl := section(32, ctrlLen)
+	diff := section(32+ctrlLen, diffLen)
+	extra := section(32+ctrlLen+diffLen, patchSize-32-ctrlLen-diffLen)
+
+	w := bufio.NewWriterSize(dst, bsdiffChunk)
+	buf := make([]byte, bsdiffChunk)
+	old := make([]byte, bsdiffChunk)
+	var triple [24]byte
+	var newPos, oldPos int64
+	for newPos < newSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(ctrl, triple[:]); err != nil {
+			return fmt.Errorf("%w: control block ends early", ErrCorrupt)
+		}
+		add, copyLen, seek := offtin(triple[0:]), offtin(triple[8:]), offtin(triple[16:])
+		if add < 0 || copyLen < 0 || newPos+add+copyLen > newSize {
+			return fmt.Errorf("%w: control block runs past the new size", ErrCorrupt)
+		}
+
+		// Diff bytes are added to the base bytes at the same offset
+		for add > 0 {
+			n := min(add, bsdiffChunk)
+			if _, err := io.ReadFull(diff, buf[:n]); err != nil {
+				return fmt.Errorf("%w: diff block ends early", ErrCorrupt)
+			}
+			if err := readBase(base, old[:n], oldPos); err != nil {
+				return err
+			}
+			for i := range n {
+				buf[i] += old[i]
+			}
+			if _, err := w.Write(buf[:n]); err != nil {
+				return err
+			}
+			add -= n
+			newPos += n
+			oldPos += n
+		}
+
+		if _, err := io.CopyN(w, extra, copyLen); err != nil {
+			if err == io.EOF {
+				return fmt.Errorf("%w: extra block ends early", ErrCorrupt)
+			}
+			return err
+		}
+		newPos += copyLen
+		oldPos += seek
+	}
+	// Reading each stream to its end checks its CRC, catching a truncated
+	// or damaged patch
+	for _, stream := range []io.Reader{ctrl, diff, extra} {
+		if _, err := io.Copy(io.Discard, stream); err != nil {
+			return fmt.Errorf("%w: %v", ErrCorrupt, err)
+		}
+	}
+	return w.Flush()
+}
+
+// readBase fills buf from base at off, with zeros for any part of it before
+// the start or past the end of base, which bsdiff adds nothing to.
+func readBase(base io.ReaderAt, buf []byte, off int64) error {
+	clear(buf)
+	if off < 0 {
+		skip := min(-off, int64(len(buf)))
+		buf = buf[skip:]
+		off += skip
+	}
+	if len(buf) == 0 {
+		return nil
+	}
+	if _, err := base.ReadAt(buf, off); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// offtin reads bsdiff's 64-bit sign-and-magnitude little-endian integer.
+func offtin(b []byte) int64 {
+	v := binary.LittleEndian.Uint64(b)
+	n := int64(v &^ (1 << 63))
+	if v&(1<<63) != 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,70 @@
+// Package patch applies binary patches to a base file to produce a new
+// version of it: VCDIFF (RFC 3284), as written by xdelta3 and open-vcdiff,
+// and bsdiff's BSDIFF40 format.
+package patch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+)
+
+// Format is the format of a patch.
+type Format string
+
+const (
+	VCDIFF Format = "vcdiff"
+	BSDiff Format = "bsdiff"
+)
+
+var (
+	ErrUnknownFormat = errors.New("not a VCDIFF or bsdiff patch")
+	ErrCorrupt       = errors.New("patch is corrupt or does not match the base file")
+	ErrUnsupported   = errors.New("patch uses an unsupported feature")
+)
+
+var (
+	vcdiffMagic = []byte{0xd6, 0xc3, 0xc4}
+	bsdiffMagic = []byte("BSDIFF40")
+)
+
+// Target is where a patched file is written. VCDIFF windows may copy from
+// earlier parts of it, so it is read back as well.
+type Target interface {
+	io.Writer
+	io.ReaderAt
+}
+
+// Detect reports the format of the patch from its first bytes.
+func Detect(patch io.ReaderAt) (Format, error) {
+	head := make([]byte, len(bsdiffMagic))
+	n, err := patch.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	head = head[:n]
+	switch {
+	case bytes.HasPrefix(head, bsdiffMagic):
+		return BSDiff, nil
+	case bytes.HasPrefix(head, vcdiffMagic):
+		return VCDIFF, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+// Apply writes the file patch turns base into to dst and returns the
+// format of the patch. It stops once ctx is done.
+func Apply(ctx context.Context, dst Target, base io.ReaderAt, patch io.ReaderAt, patchSize int64) (Format, error) {
+	format, err := Detect(patch)
+	if err != nil {
+		return "", err
+	}
+	switch format {
+	case BSDiff:
+		err = applyBSDiff(ctx, dst, base, patch, patchSize)
+	default:
+		err = applyVCDIFF(ctx, dst, base, io.NewSectionReader(patch, 0, patchSize))
+	}
+	return format, err
+}
@@ -0,0 +1,188 @@
+package patch
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"hash/adler32"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// window builds one VCDIFF window from raw sections.
+type window struct {
+	indicator      byte
+	sourceSize     int
+	sourcePos      int
+	targetSize     int
+	data, inst, ad []byte
+	adler          bool
+}
+
+func appendInt(b []byte, v int) []byte {
+	var digits []byte
+	for {
+		digits = append([]byte{byte(v & 0x7f)}, digits...)
+		v >>= 7
+		if v == 0 {
+			break
+		}
+	}
+	for i := range len(digits) - 1 {
+		digits[i] |= 0x80
+	}
+	return append(b, digits...)
+}
+
+func (w window) encode(target []byte) []byte {
+	out := []byte{w.indicator}
+	if w.adler {
+		out[0] |= vcdAdler32
+	}
+	if w.indicator&(vcdSource|vcdTarget) != 0 {
+		out = appendInt(out, w.sourceSize)
+		out = appendInt(out, w.sourcePos)
+	}
+	var enc []byte
+	enc = appendInt(enc, w.targetSize)
+	enc = append(enc, 0)
+	enc = appendInt(enc, len(w.data))
+	enc = appendInt(enc, len(w.inst))
+	enc = appendInt(enc, len(w.ad))
+	if w.adler {
+		sum := adler32.Checksum(target)
+		enc = append(enc, byte(sum>>24), byte(sum>>16), byte(sum>>8), byte(sum))
+	}
+	enc = append(append(append(enc, w.data...), w.inst...), w.ad...)
+	out = appendInt(out, len(enc))
+	return append(out, enc...)
+}
+
+func vcdiff(appHeader bool, windows ...[]byte) []byte {
+	out := []byte{0xd6, 0xc3, 0xc4, 0, 0}
+	if appHeader {
+		out[4] = vcdAppHeader
+		out = appendInt(out, 3)
+		out = append(out, "a/b"...)
+	}
+	for _, w := range windows {
+		out = append(out, w...)
+	}
+	return out
+}
+
+func apply(t *testing.T, base, patch []byte) ([]byte, error) {
+	t.Helper()
+	dst, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = dst.Close() }()
+	if _, err := Apply(context.Background(), dst, bytes.NewReader(base), bytes.NewReader(patch), int64(len(patch))); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(dst.Name())
+}
+
+func TestApply_VCDIFF(t *testing.T) {
+	base := []byte("The quick brown fox jumps over the lazy dog.")
+	first := []byte("The quick red fox jumps over the lazy dog!!!!!! ok")
+	second := []byte("red fox ok fox")
+
+	// copyCode is the code of a COPY of size 4 to 18 in mode
+	copyCode := func(size, mode int) byte { return byte(19 + mode*16 + size - 3) }
+	tail := " fox jumps over the lazy dog"
+
+	// Window 1 copies from the base with several code table entries
+	w1 := window{
+		indicator:  vcdSource,
+		sourceSize: len(base),
+		targetSize: len(first),
+		data:       []byte("red! ok"),
+		inst: []byte{
+			copyCode(10, 0),     // "The quick "
+			4,                   // ADD 3: "red"
+			19, byte(len(tail)), // COPY with its size next
+			0, 6, // RUN 6: "!!!!!!"
+			1, 3, // ADD with its size next: " ok"
+		},
+		ad:    appendInt(appendInt(nil, 0), 15),
+		adler: true,
+	}
+	// Window 2 copies from the target written so far, relative to here and
+	// with an ADD+COPY code
+	w2 := window{
+		indicator:  vcdTarget,
+		sourceSize: len(first),
+		targetSize: len(second),
+		data:       []byte("ok"),
+		inst: []byte{
+			copyCode(8, 1),    // "red fox ", from 10
+			163 + 3*(2-1) + 0, // ADD 2: "ok", COPY 4: " fox", from 13
+		},
+		ad: appendInt(appendInt(nil, len(first)-10), 13),
+	}
+
+	patch := vcdiff(true, w1.encode(first), w2.encode(second))
+	got, err := apply(t, base, patch)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if want := string(first) + string(second); string(got) != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	// A base that is not the one the patch was made from fails the checksum
+	other := bytes.ToUpper(base)
+	if _, err := apply(t, other, patch); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("Apply with the wrong base = %v, want ErrCorrupt", err)
+	}
+}
+
+func TestApply_BSDiff(t *testing.T) {
+	// bsdiff's format for "hello old world" -> "hello new World!!"
+	patch, _ := hex.DecodeString("42534449464634302d0000000000000029000000000000001100000000000000425a6839314159265359f090d5c100000e40005908200030cd0090d4222c934a17b78bb9229c284878486ae080425a6839314159265359fe58af93000002c001480040002000212641989c17177245385090fe58af93425a6839314159265359eec26f7300000191802000020100802000219a68334d111e2ee48a70a121dd84dee6")
+	got, err := apply(t, []byte("hello old world"), patch)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if string(got) != "hello new World!!" {
+		t.Fatalf("got %q", got)
+	}
+
+	if _, err := apply(t, []byte("hello old world"), patch[:len(patch)-10]); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("Apply of a truncated patch = %v, want ErrCorrupt", err)
+	}
+}
+
+func TestApply_RejectsOtherFiles(t *testing.T) {
+	if _, err := apply(t, nil, []byte("PK\x03\x04 not a patch")); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("Apply = %v, want ErrUnknownFormat", err)
+	}
+	compressed := []byte{0xd6, 0xc3, 0xc4, 0, vcdDecompress, 2}
+	if _, err := apply(t, nil, compressed); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("Apply = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestCodeTable(t *testing.T) {
+	for code, want := range map[int][2]instruction{
+		0:   {{instRun, 0, 0}},
+		1:   {{instAdd, 0, 0}},
+		18:  {{instAdd, 17, 0}},
+		19:  {{instCopy, 0, 0}},
+		162: {{instCopy, 18, 8}},
+		163: {{instAdd, 1, 0}, {instCopy, 4, 0}},
+		234: {{instAdd, 4, 0}, {instCopy, 6, 5}},
+		235: {{instAdd, 1, 0}, {instCopy, 4, 6}},
+		246: {{instAdd, 4, 0}, {instCopy, 4, 8}},
+		247: {{instCopy, 4, 0}, {instAdd, 1, 0}},
+		255: {{instCopy, 4, 8}, {instAdd, 1, 0}},
+	} {
+		if codeTable[code] != want {
+			t.Errorf("code %d = %v, want %v", code, codeTable[code], want)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package patch
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"hash/adler32"
+	"io"
+)
+
+// maxWindow bounds the target window of a VCDIFF patch, which is held in
+// memory. xdelta3 writes windows of 8 MiB by default.
+const maxWindow = 64 << 20
+
+// VCDIFF header and window indicator bits.
+const (
+	vcdDecompress = 0x01
+	vcdCodeTable  = 0x02
+	vcdAppHeader  = 0x04 // xdelta3's application header
+
+	vcdSource  = 0x01
+	vcdTarget  = 0x02
+	vcdAdler32 = 0x04 // xdelta3's checksum of the target window
+)
+
+// Instruction types of the code table.
+const (
+	instNoop = iota
+	instAdd
+	instRun
+	instCopy
+)
+
+// The address cache sizes of the default code table.
+const (
+	nearSize = 4
+	sameSize = 3
+)
+
+type instruction struct {
+	typ, size, mode byte
+}
+
+// codeTable is RFC 3284's default code table: each of the 256 codes is one
+// or two instructions, with size 0 meaning the size follows.
+var codeTable = buildCodeTable()
+
+func buildCodeTable() (table [256][2]instruction) {
+	i := 0
+	table[i][0] = instruction{instRun, 0, 0}
+	i++
+	for size := 0; size <= 17; size++ {
+		table[i][0] = instruction{instAdd, byte(size), 0}
+		i++
+	}
+	for mode := range byte(9) {
+		table[i][0] = instruction{instCopy, 0, mode}
+		i++
+		for size := 4; size <= 18; size++ {
+			table[i][0] = instruction{instCopy, byte(size), mode}
+			i++
+		}
+	}
+	for mode := range byte(6) {
+		for add := 1; add <= 4; add++ {
+			for size := 4; size <= 6; size++ {
+				table[i] = [2]instruction{{instAdd, byte(add), 0}, {instCopy, byte(size), mode}}
+				i++
+			}
+		}
+	}
+	for mode := byte(6); mode <= 8; mode++ {
+		for add := 1; add <= 4; add++ {
+			table[i] = [2]instruction{{instAdd, byte(add), 0}, {instCopy, 4, mode}}
+			i++
+		}
+	}
+	for mode := range byte(9) {
+		table[i] = [2]instruction{{instCopy, 4, mode}, {instAdd, 1, 0}}
+		i++
+	}
+	return table
+}
+
+// applyVCDIFF decodes a VCDIFF patch window by window. Each window builds
+// part of the target from its own data and from copies out of a segment of
+// the base (or of the target written so far) and of the window itself.
+func applyVCDIFF(ctx context.Context, dst Target, base io.ReaderAt, patch io.Reader) error {
+	r := bufio.NewReader(patch)
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return fmt.Errorf("%w: short header", ErrCorrupt)
+	}
+	if header[3] != 0 {
+		return fmt.Errorf("%w: VCDIFF version %d", ErrUnsupported, header[3])
+	}
+	indicator := header[4]
+	if indicator&vcdDecompress != 0 {
+		return fmt.Errorf("%w: secondary compression; create the patch with xdelta3 -S none", ErrUnsupported)
+	}
+	if indicator&vcdCodeTable != 0 {
+		return fmt.Errorf("%w: custom code table", ErrUnsupported)
+	}
+	if indicator&vcdAppHeader != 0 {
+		n, err := readInt(r)
+		if err != nil {
+			return err
+		}
+		if _, err := r.Discard(int(n)); err != nil {
+			return fmt.Errorf("%w: application header ends early", ErrCorrupt)
+		}
+	}
+
+	var written int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		windowIndicator, err := r.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		window, err := decodeWindow(r, windowIndicator, base, dst, written)
+		if err != nil {
+			return err
+		}
+		if _, err := dst.Write(window); err != nil {
+			return err
+		}
+		written += int64(len(window))
+	}
+}
+
+// decodeWindow reads one window after its indicator and returns the target
+// bytes it decodes to. written is how much of the target came before it.
+func decodeWindow(r *bufio.Reader, indicator byte, base io.ReaderAt, dst io.ReaderAt, written int64) ([]byte, error) {
+	var source []byte
+	if indicator&(vcdSource|vcdTarget) != 0 {
+		size, err := readInt(r)
+		if err != nil {
+			return nil, err
+		}
+		pos, err := readInt(r)
+		if err != nil {
+			return nil, err
+		}
+		if size > maxWindow {
+			return nil, fmt.Errorf("%w: source segment of %d bytes", ErrUnsupported, size)
+		}
+		from, limit := base, int64(-1)
+		if indicator&vcdSource == 0 {
+			from, limit = dst, written
+		}
+		if limit >= 0 && pos+size > limit {
+			return nil, fmt.Errorf("%w: copies from target not yet written", ErrCorrupt)
+		}
+		source = make([]byte, size)
+		if n, err := from.ReadAt(source, int64(pos)); n < len(source) {
+			if err == nil || err == io.EOF {
+				err = fmt.Errorf("%w: base file is shorter than the patch expects", ErrCorrupt)
+			}
+			return nil, err
+		}
+	}
+
+	if _, err := readInt(r); err != nil { // Length of the delta encoding
+		return nil, err
+	}
+	targetSize, err := readInt(r)
+	if err != nil {
+		return nil, err
+	}
+	if targetSize > maxWindow {
+		return nil, fmt.Errorf("%w: target window of %d bytes", ErrUnsupported, targetSize)
+	}
+	deltaIndicator, err := r.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("%w: window ends early", ErrCorrupt)
+	}
+	if deltaIndicator != 0 {
+		return nil, fmt.Errorf("%w: secondary compression; create the patch with xdelta3 -S none", ErrUnsupported)
+	}
+	var sizes [3]int64
+	for i := range sizes {
+		if sizes[i], err = readInt(r); err != nil {
+			return nil, err
+		}
+		if sizes[i] > maxWindow*2 {
+			return nil, fmt.Errorf("%w: section of %d bytes", ErrCorrupt, sizes[i])
+		}
+	}
+	var checksum []byte
+	if indicator&vcdAdler32 != 0 {
+		checksum = make([]byte, 4)
+		if _, err := io.ReadFull(r, checksum); err != nil {
+			return nil, fmt.Errorf("%w: window ends early", ErrCorrupt)
+		}
+	}
+	sections := make([][]byte, 3)
+	for i, size := range sizes {
+		sections[i] = make([]byte, size)
+		if _, err := io.ReadFull(r, sections[i]); err != nil {
+			return nil, fmt.Errorf("%w: window ends early", ErrCorrupt)
+		}
+	}
+
+	d := &windowDecoder{
+		source: source,
+		target: make([]byte, 0, targetSize),
+		data:   sections[0],
+		inst:   sections[1],
+		addr:   sections[2],
+	}
+	if err := d.run(int(targetSize)); err != nil {
+		return nil, err
+	}
+	if checksum != nil {
+		want := uint32(checksum[0])<<24 | uint32(checksum[1])<<16 | uint32(checksum[2])<<8 | uint32(checksum[3])
+		if adler32.Checksum(d.target) != want {
+			return nil, fmt.Errorf("%w: window checksum mismatch", ErrCorrupt)
+		}
+	}
+	return d.target, nil
+}
+
+// windowDecoder runs the instructions of one window.
+type windowDecoder struct {
+	source []byte
+	target []byte
+	data   []byte
+	inst   []byte
+	addr   []byte
+
+	near     [nearSize]int
+	nextNear int
+	same     [sameSize * 256]int
+}
+
+func (d *windowDecoder) run(targetSize int) error {
+	for len(d.inst) > 0 {
+		code := d.inst[0]
+		d.inst = d.inst[1:]
+		for _, in := range codeTable[code] {
+			if in.typ == instNoop {
+				continue
+			}
+			size := int(in.size)
+			if size == 0 {
+				n, rest, err := sectionInt(d.inst)
+				if err != nil {
+					return err
+				}
+				size, d.inst = n, rest
+			}
+			if len(d.target)+size > targetSize {
+				return fmt.Errorf("%w: window overruns its size", ErrCorrupt)
+			}
+			if err := d.execute(in, size); err != nil {
+				return err
+			}
+		}
+	}
+	if len(d.target) != targetSize {
+		return fmt.Errorf("%w: window decodes to %d of %d bytes", ErrCorrupt, len(d.target), targetSize)
+	}
+	return nil
+}
+
+func (d *windowDecoder) execute(in instruction, size int) error {
+	switch in.typ {
+	case instAdd:
+		if len(d.data) < size {
+			return fmt.Errorf("%w: data section ends early", ErrCorrupt)
+		}
+		d.target = append(d.target, d.data[:size]...)
+		d.data = d.data[size:]
+	case instRun:
+		if len(d.data) < 1 {
+			return fmt.Errorf("%w: data section ends early", ErrCorrupt)
+		}
+		b := d.data[0]
+		d.data = d.data[1:]
+		for range size {
+			d.target = append(d.target, b)
+		}
+	case instCopy:
+		here := len(d.source) + len(d.target)
+		addr, err := d.decodeAddress(here, in.mode)
+		if err != nil {
+			return err
+		}
+		if addr < 0 || addr >= here {
+			return fmt.Errorf("%w: copy from address %d at %d", ErrCorrupt, addr, here)
+		}
+		// Byte by byte, since a copy inside the window may overlap what it
+		// is writing, repeating a pattern
+		for i := range size {
+			at := addr + i
+			if at < len(d.source) {
+				d.target = append(d.target, d.source[at])
+			} else {
+				d.target = append(d.target, d.target[at-len(d.source)])
+			}
+		}
+	}
+	return nil
+}
+
+// decodeAddress reads the address of a copy in mode and updates the cache.
+func (d *windowDecoder) decodeAddress(here int, mode byte) (int, error) {
+	var addr int
+	switch {
+	case mode == 0: // Self
+		n, rest, err := sectionInt(d.addr)
+		if err != nil {
+			return 0, err
+		}
+		addr, d.addr = n, rest
+	case mode == 1: // Here
+		n, rest, err := sectionInt(d.addr)
+		if err != nil {
+			return 0, err
+		}
+		addr, d.addr = here-n, rest
+	case int(mode) < 2+nearSize:
+		n, rest, err := sectionInt(d.addr)
+		if err != nil {
+			return 0, err
+		}
+		addr, d.addr = d.near[mode-2]+n, rest
+	default:
+		if len(d.addr) < 1 {
+			return 0, fmt.Errorf("%w: address section ends early", ErrCorrupt)
+		}
+		addr = d.same[(int(mode)-2-nearSize)*256+int(d.addr[0])]
+		d.addr = d.addr[1:]
+	}
+
+	d.near[d.nextNear] = addr
+	d.nextNear = (d.nextNear + 1) % nearSize
+	if addr >= 0 {
+		d.same[addr%(sameSize*256)] = addr
+	}
+	return addr, nil
+}
+
+// readInt reads a VCDIFF integer: base 128, most significant digit first,
+// with the top bit set on every byte but the last.
+func readInt(r io.ByteReader) (int64, error) {
+	var v int64
+	for i := 0; ; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, fmt.Errorf("%w: integer ends early", ErrCorrupt)
+		}
+		if i == 9 {
+			return 0, fmt.Errorf("%w: integer too large", ErrCorrupt)
+		}
+		v = v<<7 | int64(b&0x7f)
+		if b&0x80 == 0 {
+			return v, nil
+		}
+	}
+}
+
+// sectionInt reads an integer from the start of a section.
+func sectionInt(section []byte) (int, []byte, error) {
+	r := &sliceReader{section}
+	v, err := readInt(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	if v > maxWindow*2 {
+		return 0, nil, fmt.Errorf("%w: size %d", ErrCorrupt, v)
+	}
+	return int(v), r.b, nil
+}
+
+type sliceReader struct{ b []byte }
+
+func (r *sliceReader) ReadByte() (byte, error) {
+	if len(r.b) == 0 {
+		return 0, io.EOF
+	}
+	b := r.b[0]
+	r.b = r.b[1:]
+	return b, nil
+}
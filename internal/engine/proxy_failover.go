@@ -0,0 +1,190 @@
+package engine
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// ProxyFailover moves downloads along an ordered list of proxies. When the
+// active one keeps failing they switch to the next, wrapping around after
+// the last; while on a fallback the primary is tried again every
+// types.ProxyRetryPrimary.
+type ProxyFailover struct {
+	mu         sync.Mutex
+	proxies    []*url.URL
+	active     int
+	failures   []time.Time
+	switchedAt time.Time
+	now        func() time.Time
+	notify     func(message string)
+}
+
+// ProxyFailovers keeps one ProxyFailover per proxy list, so every transport
+// using the same list fails over together.
+type ProxyFailovers struct {
+	mu     sync.Mutex
+	lists  map[string]*ProxyFailover
+	notify func(message string)
+}
+
+// DefaultProxyFailovers is the global instance shared by all transports.
+var DefaultProxyFailovers = &ProxyFailovers{}
+
+// SetNotify sets what is told about each switch between proxies.
+func (p *ProxyFailovers) SetNotify(notify func(message string)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.notify = notify
+	for _, f := range p.lists {
+		f.mu.Lock()
+		f.notify = notify
+		f.mu.Unlock()
+	}
+}
+
+// For returns the failover for primary followed by the comma-separated
+// fallbacks, or nil when there is nothing to fail over to.
+func (p *ProxyFailovers) For(primary, fallbacks string) *ProxyFailover {
+	proxies := parseProxyList(primary + "," + fallbacks)
+	if len(proxies) < 2 || strings.TrimSpace(primary) == "" {
+		return nil
+	}
+	key := primary + "," + fallbacks
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.lists == nil {
+		p.lists = make(map[string]*ProxyFailover)
+	}
+	f, ok := p.lists[key]
+	if !ok {
+		f = &ProxyFailover{proxies: proxies, now: time.Now, notify: p.notify}
+		p.lists[key] = f
+	}
+	return f
+}
+
+func parseProxyList(spec string) []*url.URL {
+	var proxies []*url.URL
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		u, err := url.Parse(entry)
+		if err != nil || u.Host == "" {
+			utils.Debug("ProxyFailover: skipping invalid proxy %q", entry)
+			continue
+		}
+		proxies = append(proxies, u)
+	}
+	return proxies
+}
+
+// Proxy returns the proxy requests should go through now, for use as an
+// http.Transport's Proxy.
+func (f *ProxyFailover) Proxy(*http.Request) (*url.URL, error) {
+	return f.Current(), nil
+}
+
+// Current returns the active proxy, going back to the primary once a
+// fallback has been in use for types.ProxyRetryPrimary.
+func (f *ProxyFailover) Current() *url.URL {
+	f.mu.Lock()
+	if f.active == 0 || f.now().Sub(f.switchedAt) < types.ProxyRetryPrimary {
+		defer f.mu.Unlock()
+		return f.proxies[f.active]
+	}
+	from := f.proxies[f.active]
+	f.switchTo(0)
+	primary, notify := f.proxies[0], f.notify
+	f.mu.Unlock()
+
+	if notify != nil {
+		notify("Trying primary proxy " + redactProxy(primary) + " again instead of " + redactProxy(from))
+	}
+	return primary
+}
+
+// Fail records a failed connection to the proxy at addr, as host:port. It
+// is ignored unless addr is the active proxy, so failures still arriving
+// from before a switch do not move downloads on again.
+func (f *ProxyFailover) Fail(addr string, reason string) {
+	f.mu.Lock()
+	from := f.proxies[f.active]
+	if proxyAddr(from) != addr {
+		f.mu.Unlock()
+		return
+	}
+	now := f.now()
+	recent := f.failures[:0]
+	for _, at := range f.failures {
+		if now.Sub(at) < types.ProxyFailureWindow {
+			recent = append(recent, at)
+		}
+	}
+	f.failures = append(recent, now)
+	if len(f.failures) < types.ProxyFailureThreshold {
+		f.mu.Unlock()
+		return
+	}
+	f.switchTo((f.active + 1) % len(f.proxies))
+	to, notify := f.proxies[f.active], f.notify
+	f.mu.Unlock()
+
+	utils.Debug("ProxyFailover: %s failed (%s), switching to %s", redactProxy(from), reason, redactProxy(to))
+	if notify != nil {
+		notify("Proxy " + redactProxy(from) + " is failing (" + reason + "); switched to " + redactProxy(to))
+	}
+}
+
+// switchTo makes proxies[i] active. The caller holds mu.
+func (f *ProxyFailover) switchTo(i int) {
+	f.active = i
+	f.failures = f.failures[:0]
+	f.switchedAt = f.now()
+}
+
+// proxyAddr returns the host:port a transport dials to reach proxy.
+func proxyAddr(proxy *url.URL) string {
+	if proxy.Port() != "" {
+		return proxy.Host
+	}
+	port := "80"
+	switch proxy.Scheme {
+	case "https":
+		port = "443"
+	case "socks5", "socks5h":
+		port = "1080"
+	}
+	return net.JoinHostPort(proxy.Hostname(), port)
+}
+
+// redactProxy drops any credentials from proxy before it is shown.
+func redactProxy(proxy *url.URL) string {
+	return proxy.Redacted()
+}
+
+// proxyGatewayError reports whether a proxy's answer to CONNECT means the
+// proxy, rather than the server behind it, is in trouble.
+func proxyGatewayError(status int) bool {
+	return status == http.StatusBadGateway || status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout
+}
+
+// dialFailure describes a failed dial for the failover notice, without the
+// addresses the notice already names.
+func dialFailure(err error) string {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Err != nil {
+		return opErr.Err.Error()
+	}
+	return err.Error()
+}
@@ -0,0 +1,174 @@
+package engine
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func newTestFailover(t *testing.T, primary, fallbacks string) (*ProxyFailover, *time.Time, *[]string) {
+	t.Helper()
+	var notices []string
+	registry := &ProxyFailovers{}
+	registry.SetNotify(func(message string) { notices = append(notices, message) })
+	f := registry.For(primary, fallbacks)
+	if f == nil {
+		t.Fatal("For returned nil for a list with fallbacks")
+	}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	f.now = func() time.Time { return now }
+	return f, &now, &notices
+}
+
+func TestProxyFailovers_ForNeedsFallbacks(t *testing.T) {
+	registry := &ProxyFailovers{}
+	if registry.For("http://a:3128", "") != nil {
+		t.Error("For without fallbacks should return nil")
+	}
+	if registry.For("", "http://b:3128") != nil {
+		t.Error("For without a primary should return nil")
+	}
+	f := registry.For("http://a:3128", "http://b:3128")
+	if f == nil || registry.For("http://a:3128", "http://b:3128") != f {
+		t.Error("For should return the same failover for the same list")
+	}
+}
+
+func TestProxyFailover_SwitchesAfterRepeatedFailures(t *testing.T) {
+	f, _, notices := newTestFailover(t, "http://user:secret@a:3128", "http://b:3128")
+
+	for i := 0; i < types.ProxyFailureThreshold-1; i++ {
+		f.Fail("a:3128", "connection refused")
+	}
+	if got := f.Current().Host; got != "a:3128" {
+		t.Fatalf("switched after %d failures, active = %s", types.ProxyFailureThreshold-1, got)
+	}
+
+	f.Fail("a:3128", "connection refused")
+	if got := f.Current().Host; got != "b:3128" {
+		t.Fatalf("active = %s, want b:3128", got)
+	}
+	if len(*notices) != 1 || !strings.Contains((*notices)[0], "switched to http://b:3128") {
+		t.Fatalf("notices = %q", *notices)
+	}
+	if strings.Contains((*notices)[0], "secret") {
+		t.Errorf("notice leaks proxy password: %q", (*notices)[0])
+	}
+}
+
+func TestProxyFailover_IgnoresOldFailures(t *testing.T) {
+	f, now, _ := newTestFailover(t, "http://a:3128", "http://b:3128")
+
+	for i := 0; i < types.ProxyFailureThreshold-1; i++ {
+		f.Fail("a:3128", "timeout")
+	}
+	*now = now.Add(types.ProxyFailureWindow)
+	f.Fail("a:3128", "timeout")
+	if got := f.Current().Host; got != "a:3128" {
+		t.Errorf("failures outside the window caused a switch to %s", got)
+	}
+}
+
+func TestProxyFailover_IgnoresInactiveProxy(t *testing.T) {
+	f, _, _ := newTestFailover(t, "http://a:3128", "http://b:3128")
+
+	for i := 0; i < types.ProxyFailureThreshold; i++ {
+		f.Fail("b:3128", "connection refused")
+	}
+	if got := f.Current().Host; got != "a:3128" {
+		t.Errorf("failures of an inactive proxy switched to %s", got)
+	}
+}
+
+func TestProxyFailover_RetriesPrimary(t *testing.T) {
+	f, now, notices := newTestFailover(t, "http://a:3128", "http://b:3128")
+
+	for i := 0; i < types.ProxyFailureThreshold; i++ {
+		f.Fail("a:3128", "connection refused")
+	}
+	*now = now.Add(types.ProxyRetryPrimary - time.Second)
+	if got := f.Current().Host; got != "b:3128" {
+		t.Fatalf("went back to %s too early", got)
+	}
+	*now = now.Add(time.Second)
+	if got := f.Current().Host; got != "a:3128" {
+		t.Fatalf("active = %s, want primary again", got)
+	}
+	if len(*notices) != 2 || !strings.Contains((*notices)[1], "Trying primary proxy") {
+		t.Errorf("notices = %q", *notices)
+	}
+}
+
+func TestProxyAddr_DefaultPorts(t *testing.T) {
+	tests := map[string]string{
+		"http://proxy":         "proxy:80",
+		"https://proxy":        "proxy:443",
+		"socks5://proxy":       "proxy:1080",
+		"http://proxy:3128":    "proxy:3128",
+		"http://[2001:db8::1]": "[2001:db8::1]:80",
+	}
+	for raw, want := range tests {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := proxyAddr(u); got != want {
+			t.Errorf("proxyAddr(%s) = %s, want %s", raw, got, want)
+		}
+	}
+}
+
+func TestNetworkPool_FailsOverToBackupProxy(t *testing.T) {
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer origin.Close()
+
+	var proxied int
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied++
+		resp, err := http.Get(r.URL.String())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer func() { _ = resp.Body.Close() }()
+		_, _ = io.Copy(w, resp.Body)
+	}))
+	defer backup.Close()
+
+	dead := "http://127.0.0.1:" + strconv.Itoa(freePort(t))
+	runtime := &types.RuntimeConfig{ProxyURL: dead, ProxyFallbacks: backup.URL}
+
+	pool := &NetworkPool{}
+	transport, err := pool.AcquireTransportFor(runtime, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.ReleaseTransport(transport)
+	client := &http.Client{Transport: transport}
+
+	for i := 0; i < types.ProxyFailureThreshold; i++ {
+		if resp, err := client.Get(origin.URL); err == nil {
+			_ = resp.Body.Close()
+			t.Fatal("request through a dead proxy succeeded")
+		}
+	}
+
+	resp, err := client.Get(origin.URL)
+	if err != nil {
+		t.Fatalf("request after failover: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "ok" || proxied != 1 {
+		t.Errorf("body = %q, proxied = %d; want ok through the backup", body, proxied)
+	}
+}
@@ -144,6 +144,13 @@ func (r *RateLimiter) WaitN(ctx context.Context, n int64) error {
 	}
 }
 
+// Rate returns the current rate in bytes per second; zero means unlimited.
+func (r *RateLimiter) Rate() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rate
+}
+
 func (r *RateLimiter) SetRate(rate int64, bucketSize int64) {
 	if bucketSize < 0 {
 		bucketSize = 0
@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// credentialHeaders are withheld on cross-host redirects when the runtime
+// config asks for auth not to be forwarded.
+var credentialHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization"}
+
+// RedirectPolicy returns an http.Client CheckRedirect function that applies the
+// redirect settings from runtime. headers are the caller's explicit request
+// headers; they are re-applied at every hop because Go drops sensitive headers
+// on cross-host redirects, and these were provided on purpose for this
+// download.
+func RedirectPolicy(runtime *types.RuntimeConfig, headers map[string]string) func(req *http.Request, via []*http.Request) error {
+	maxRedirects := runtime.GetMaxRedirects()
+	blockCrossHost := runtime.GetBlockCrossHostRedirects()
+	stripAuth := runtime.GetStripAuthOnRedirect()
+
+	return func(req *http.Request, via []*http.Request) error {
+		// via holds every request made so far, so len(via) is the redirect count
+		if len(via) > maxRedirects {
+			return fmt.Errorf("%w (limit %d)", types.ErrMaxRedirects, maxRedirects)
+		}
+		if len(via) == 0 {
+			return nil
+		}
+
+		// A downgrade from https to http counts as leaving the host, since
+		// anything sent after it crosses the network in the clear.
+		downgrade := strings.EqualFold(via[0].URL.Scheme, "https") && !strings.EqualFold(req.URL.Scheme, "https")
+		crossHost := downgrade || !strings.EqualFold(req.URL.Host, via[0].URL.Host)
+		if crossHost && blockCrossHost {
+			return fmt.Errorf("%w: %s://%s -> %s://%s", types.ErrCrossHostRedirect, via[0].URL.Scheme, via[0].URL.Host, req.URL.Scheme, req.URL.Host)
+		}
+
+		utils.CopyRedirectHeaders(req, via[0])
+		for key, val := range headers {
+			if strings.EqualFold(key, "Range") {
+				continue
+			}
+			if crossHost && stripAuth && isCredentialHeader(key) {
+				continue
+			}
+			req.Header.Set(key, val)
+		}
+		return nil
+	}
+}
+
+func isCredentialHeader(key string) bool {
+	for _, h := range credentialHeaders {
+		if strings.EqualFold(key, h) {
+			return true
+		}
+	}
+	return false
+}
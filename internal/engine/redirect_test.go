@@ -0,0 +1,162 @@
+package engine
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+// newRedirectChain returns a server that redirects /hop/N to /hop/N-1 and
+// serves the headers it received at /hop/0.
+func newRedirectChain(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hop/", func(w http.ResponseWriter, r *http.Request) {
+		n := strings.TrimPrefix(r.URL.Path, "/hop/")
+		if n == "0" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		next := map[string]string{"1": "0", "2": "1", "3": "2", "4": "3"}[n]
+		http.Redirect(w, r, "/hop/"+next, http.StatusFound)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRedirectPolicy_MaxRedirects(t *testing.T) {
+	server := newRedirectChain(t)
+
+	client := &http.Client{CheckRedirect: RedirectPolicy(&types.RuntimeConfig{MaxRedirects: 2}, nil)}
+
+	resp, err := client.Get(server.URL + "/hop/2")
+	if err != nil {
+		t.Fatalf("two redirects should be allowed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	_, err = client.Get(server.URL + "/hop/3")
+	if !errors.Is(err, types.ErrMaxRedirects) {
+		t.Fatalf("err = %v, want ErrMaxRedirects", err)
+	}
+}
+
+func TestRedirectPolicy_DefaultLimit(t *testing.T) {
+	if got := (&types.RuntimeConfig{}).GetMaxRedirects(); got != types.MaxRedirects {
+		t.Errorf("GetMaxRedirects() = %d, want %d", got, types.MaxRedirects)
+	}
+}
+
+func TestRedirectPolicy_CrossHost(t *testing.T) {
+	var gotAuth, gotCookie, gotKey string
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotCookie = r.Header.Get("Cookie")
+		gotKey = r.Header.Get("X-API-Key")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusFound)
+	}))
+	defer redirect.Close()
+
+	headers := map[string]string{
+		"Authorization": "Bearer secret",
+		"Cookie":        "session=1",
+		"X-API-Key":     "key",
+	}
+	get := func(runtime *types.RuntimeConfig) error {
+		gotAuth, gotCookie, gotKey = "", "", ""
+		req, err := http.NewRequest(http.MethodGet, redirect.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		client := &http.Client{CheckRedirect: RedirectPolicy(runtime, headers)}
+		resp, err := client.Do(req)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		return err
+	}
+
+	t.Run("forwards auth by default", func(t *testing.T) {
+		if err := get(&types.RuntimeConfig{}); err != nil {
+			t.Fatal(err)
+		}
+		if gotAuth != "Bearer secret" || gotCookie != "session=1" || gotKey != "key" {
+			t.Errorf("headers = %q %q %q, want all forwarded", gotAuth, gotCookie, gotKey)
+		}
+	})
+
+	t.Run("strips auth when asked", func(t *testing.T) {
+		if err := get(&types.RuntimeConfig{StripAuthOnRedirect: true}); err != nil {
+			t.Fatal(err)
+		}
+		if gotAuth != "" || gotCookie != "" {
+			t.Errorf("credentials leaked across hosts: auth=%q cookie=%q", gotAuth, gotCookie)
+		}
+		if gotKey != "key" {
+			t.Errorf("X-API-Key = %q, want non-credential headers still forwarded", gotKey)
+		}
+	})
+
+	t.Run("blocks cross-host redirects", func(t *testing.T) {
+		err := get(&types.RuntimeConfig{BlockCrossHostRedirects: true})
+		if !errors.Is(err, types.ErrCrossHostRedirect) {
+			t.Fatalf("err = %v, want ErrCrossHostRedirect", err)
+		}
+	})
+}
+
+func TestRedirectPolicy_SameHostUnaffectedByCrossHostRules(t *testing.T) {
+	server := newRedirectChain(t)
+
+	runtime := &types.RuntimeConfig{BlockCrossHostRedirects: true, StripAuthOnRedirect: true}
+	client := &http.Client{CheckRedirect: RedirectPolicy(runtime, nil)}
+
+	resp, err := client.Get(server.URL + "/hop/1")
+	if err != nil {
+		t.Fatalf("same-host redirect should be followed: %v", err)
+	}
+	_ = resp.Body.Close()
+}
+
+func TestRedirectPolicy_DowngradeCountsAsCrossHost(t *testing.T) {
+	headers := map[string]string{"Authorization": "Bearer secret", "X-API-Key": "key"}
+	hop := func(runtime *types.RuntimeConfig, to string) (*http.Request, error) {
+		from, _ := http.NewRequest(http.MethodGet, "https://example.com/file", nil)
+		req, _ := http.NewRequest(http.MethodGet, to, nil)
+		return req, RedirectPolicy(runtime, headers)(req, []*http.Request{from})
+	}
+
+	req, err := hop(&types.RuntimeConfig{StripAuthOnRedirect: true}, "http://example.com/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := req.Header.Get("Authorization"); got != "" {
+		t.Errorf("Authorization = %q sent over a downgraded redirect", got)
+	}
+	if got := req.Header.Get("X-API-Key"); got != "key" {
+		t.Errorf("X-API-Key = %q, want non-credential headers still forwarded", got)
+	}
+
+	if _, err := hop(&types.RuntimeConfig{BlockCrossHostRedirects: true}, "http://example.com/file"); !errors.Is(err, types.ErrCrossHostRedirect) {
+		t.Fatalf("err = %v, want ErrCrossHostRedirect", err)
+	}
+	req, err = hop(&types.RuntimeConfig{BlockCrossHostRedirects: true, StripAuthOnRedirect: true}, "https://example.com/other")
+	if err != nil {
+		t.Fatalf("same-scheme redirect should be followed: %v", err)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization = %q, want kept on the same host", got)
+	}
+}
@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func TestVerifyS3Checksum(t *testing.T) {
+	data := bytes.Repeat([]byte("surge"), (20*types.MB+5)/5)
+	path := filepath.Join(t.TempDir(), "object.bin")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	whole := sha256.Sum256(data)
+	full := types.S3Object{Checksum: types.S3Checksum{
+		Algorithm: "sha256",
+		Value:     base64.StdEncoding.EncodeToString(whole[:]),
+	}}
+	if err := VerifyS3Checksum(context.Background(), path, full, nil); err != nil {
+		t.Errorf("full-object checksum: %v", err)
+	}
+
+	// Composite CRC32 over 8 MiB parts, without a known part size.
+	var partSums []byte
+	for off := 0; off < len(data); off += 8 * types.MB {
+		part := crc32.NewIEEE()
+		part.Write(data[off:min(off+8*types.MB, len(data))])
+		partSums = part.Sum(partSums)
+	}
+	composite := crc32.NewIEEE()
+	composite.Write(partSums)
+	sum := base64.StdEncoding.EncodeToString(composite.Sum(nil))
+	obj := types.S3Object{Checksum: types.S3Checksum{Algorithm: "crc32", Value: fmt.Sprintf("%s-3", sum)}}
+	if err := VerifyS3Checksum(context.Background(), path, obj, nil); err != nil {
+		t.Errorf("composite checksum: %v", err)
+	}
+
+	data[len(data)-1] ^= 0xff
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyS3Checksum(context.Background(), path, full, nil); !errors.Is(err, types.ErrChecksumMismatch) {
+		t.Errorf("corrupted file: err = %v, want ErrChecksumMismatch", err)
+	}
+	if err := VerifyS3Checksum(context.Background(), path, obj, nil); !errors.Is(err, types.ErrChecksumMismatch) {
+		t.Errorf("corrupted composite: err = %v, want ErrChecksumMismatch", err)
+	}
+}
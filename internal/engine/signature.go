@@ -0,0 +1,179 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"aead.dev/minisign"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+// SignatureExtensions are the detached signature files looked for next to a
+// download, in the order they are tried.
+var SignatureExtensions = []string{".minisig", ".asc", ".sig"}
+
+// SignatureKeys are the public keys a download's signature is trusted from.
+type SignatureKeys struct {
+	pgp      openpgp.EntityList
+	minisign []minisign.PublicKey
+}
+
+// Empty reports whether no key was loaded.
+func (k *SignatureKeys) Empty() bool {
+	return k == nil || (len(k.pgp) == 0 && len(k.minisign) == 0)
+}
+
+// LoadSignatureKeys reads the comma-separated key files in paths. A
+// directory stands for every file directly in it. Each file holds a
+// minisign public key or an OpenPGP key ring, armored or binary.
+func LoadSignatureKeys(paths string) (*SignatureKeys, error) {
+	keys := &SignatureKeys{}
+	for _, p := range strings.Split(paths, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		files := []string{p}
+		if info.IsDir() {
+			entries, err := os.ReadDir(p)
+			if err != nil {
+				return nil, err
+			}
+			files = files[:0]
+			for _, e := range entries {
+				if e.Type().IsRegular() {
+					files = append(files, filepath.Join(p, e.Name()))
+				}
+			}
+		}
+		for _, f := range files {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				return nil, err
+			}
+			if err := keys.add(data); err != nil {
+				return nil, fmt.Errorf("%s: %w", f, err)
+			}
+		}
+	}
+	return keys, nil
+}
+
+func (k *SignatureKeys) add(data []byte) error {
+	var pub minisign.PublicKey
+	if err := pub.UnmarshalText(bytes.TrimSpace(data)); err == nil {
+		k.minisign = append(k.minisign, pub)
+		return nil
+	}
+	var ring openpgp.EntityList
+	var err error
+	if bytes.Contains(data, []byte("-----BEGIN PGP")) {
+		ring, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	} else {
+		ring, err = openpgp.ReadKeyRing(bytes.NewReader(data))
+	}
+	if err != nil {
+		return fmt.Errorf("not a minisign or OpenPGP public key: %w", err)
+	}
+	k.pgp = append(k.pgp, ring...)
+	return nil
+}
+
+// VerifySignature checks the file at path against a detached minisign or
+// OpenPGP signature and returns who signed it. A signature from a key not in
+// k fails with types.ErrUntrustedSignature, and one that does not match the
+// file with types.ErrBadSignature. Reading the file stops once ctx is done.
+func (k *SignatureKeys) VerifySignature(ctx context.Context, path string, signature []byte, progress VerifyProgress) (string, error) {
+	if k.Empty() {
+		return "", fmt.Errorf("%w: no signature keys are configured", types.ErrUntrustedSignature)
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(signature), []byte("untrusted comment:")) {
+		return k.verifyMinisign(ctx, path, signature, progress)
+	}
+	return k.verifyPGP(ctx, path, signature, progress)
+}
+
+func (k *SignatureKeys) verifyMinisign(ctx context.Context, path string, signature []byte, progress VerifyProgress) (string, error) {
+	var sig minisign.Signature
+	if err := sig.UnmarshalText(signature); err != nil {
+		return "", fmt.Errorf("%w: %v", types.ErrBadSignature, err)
+	}
+	var key *minisign.PublicKey
+	for i := range k.minisign {
+		if k.minisign[i].ID() == sig.KeyID {
+			key = &k.minisign[i]
+			break
+		}
+	}
+	signer := fmt.Sprintf("minisign key %X", sig.KeyID)
+	if key == nil {
+		return "", fmt.Errorf("%w: %s", types.ErrUntrustedSignature, signer)
+	}
+
+	f, err := openVerify(ctx, path, progress)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	var ok bool
+	if sig.Algorithm == minisign.HashEdDSA {
+		r := minisign.NewReader(f)
+		if _, err := io.Copy(io.Discard, r); err != nil {
+			return "", err
+		}
+		ok = r.Verify(*key, signature)
+	} else {
+		// Legacy signatures cover the whole message, not its hash
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return "", err
+		}
+		ok = minisign.Verify(*key, data, signature)
+	}
+	if !ok {
+		return "", fmt.Errorf("%w: %s", types.ErrBadSignature, signer)
+	}
+	return signer, nil
+}
+
+func (k *SignatureKeys) verifyPGP(ctx context.Context, path string, signature []byte, progress VerifyProgress) (string, error) {
+	f, err := openVerify(ctx, path, progress)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	var signer *openpgp.Entity
+	if bytes.Contains(signature, []byte("-----BEGIN PGP")) {
+		signer, err = openpgp.CheckArmoredDetachedSignature(k.pgp, f, bytes.NewReader(signature), nil)
+	} else {
+		signer, err = openpgp.CheckDetachedSignature(k.pgp, f, bytes.NewReader(signature), nil)
+	}
+	if ctx.Err() != nil {
+		return "", context.Cause(ctx)
+	}
+	if errors.Is(err, pgperrors.ErrUnknownIssuer) {
+		return "", fmt.Errorf("%w: OpenPGP signer is not a configured key", types.ErrUntrustedSignature)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", types.ErrBadSignature, err)
+	}
+	if id := signer.PrimaryIdentity(); id != nil {
+		return id.Name, nil
+	}
+	return fmt.Sprintf("OpenPGP key %X", signer.PrimaryKey.Fingerprint), nil
+}
@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"aead.dev/minisign"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func writeTestFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newMinisignKey(t *testing.T, dir, name string) minisign.PrivateKey {
+	t.Helper()
+	pub, priv, err := minisign.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, _ := pub.MarshalText()
+	writeTestFile(t, dir, name, text)
+	return priv
+}
+
+func TestVerifySignature_Minisign(t *testing.T) {
+	dir := t.TempDir()
+	keyDir := filepath.Join(dir, "keys")
+	if err := os.Mkdir(keyDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	priv := newMinisignKey(t, keyDir, "release.pub")
+	other := newMinisignKey(t, dir, "other.pub")
+
+	data := []byte("release tarball")
+	path := writeTestFile(t, dir, "file.tar", data)
+	keys, err := LoadSignatureKeys(keyDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hashed := minisign.NewReader(bytes.NewReader(data))
+	_, _ = hashed.Read(make([]byte, len(data)))
+	for name, sig := range map[string][]byte{
+		"prehashed": hashed.Sign(priv),
+		"legacy":    minisign.Sign(priv, data),
+	} {
+		if _, err := keys.VerifySignature(context.Background(), path, sig, nil); err != nil {
+			t.Errorf("%s signature: %v", name, err)
+		}
+	}
+
+	if _, err := keys.VerifySignature(context.Background(), path, minisign.Sign(priv, []byte("something else")), nil); !errors.Is(err, types.ErrBadSignature) {
+		t.Errorf("signature of other data: err = %v, want ErrBadSignature", err)
+	}
+	if _, err := keys.VerifySignature(context.Background(), path, minisign.Sign(other, data), nil); !errors.Is(err, types.ErrUntrustedSignature) {
+		t.Errorf("signature from another key: err = %v, want ErrUntrustedSignature", err)
+	}
+}
+
+func TestVerifySignature_OpenPGP(t *testing.T) {
+	dir := t.TempDir()
+	entity, err := openpgp.NewEntity("Release Signer", "", "release@example.com", &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pub bytes.Buffer
+	w, err := armor.Encode(&pub, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+	keyPath := writeTestFile(t, dir, "release.asc", pub.Bytes())
+	// A minisign key in the same list does not get in the way
+	newMinisignKey(t, dir, "other.pub")
+
+	data := []byte("release tarball")
+	path := writeTestFile(t, dir, "file.tar", data)
+	keys, err := LoadSignatureKeys(keyPath + ", " + filepath.Join(dir, "other.pub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var armored, binary bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&armored, entity, bytes.NewReader(data), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := openpgp.DetachSign(&binary, entity, bytes.NewReader(data), nil); err != nil {
+		t.Fatal(err)
+	}
+	for name, sig := range map[string][]byte{"armored": armored.Bytes(), "binary": binary.Bytes()} {
+		signer, err := keys.VerifySignature(context.Background(), path, sig, nil)
+		if err != nil {
+			t.Errorf("%s signature: %v", name, err)
+		} else if !strings.Contains(signer, "Release Signer") {
+			t.Errorf("%s signer = %q, want the key's identity", name, signer)
+		}
+	}
+
+	tampered := writeTestFile(t, dir, "tampered.tar", []byte("release tarbalL"))
+	if _, err := keys.VerifySignature(context.Background(), tampered, armored.Bytes(), nil); !errors.Is(err, types.ErrBadSignature) {
+		t.Errorf("tampered file: err = %v, want ErrBadSignature", err)
+	}
+
+	stranger, err := openpgp.NewEntity("Stranger", "", "", &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var foreign bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&foreign, stranger, bytes.NewReader(data), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.VerifySignature(context.Background(), path, foreign.Bytes(), nil); !errors.Is(err, types.ErrUntrustedSignature) {
+		t.Errorf("unknown signer: err = %v, want ErrUntrustedSignature", err)
+	}
+}
+
+func TestVerifySignature_NoKeys(t *testing.T) {
+	keys, err := LoadSignatureKeys("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.VerifySignature(context.Background(), "unused", []byte("sig"), nil); !errors.Is(err, types.ErrUntrustedSignature) {
+		t.Errorf("err = %v, want ErrUntrustedSignature", err)
+	}
+	if _, err := LoadSignatureKeys(writeTestFile(t, t.TempDir(), "junk", []byte("not a key"))); err == nil {
+		t.Error("expected an error for a file that is no key")
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"sync"
 	"time"
 
@@ -25,6 +24,14 @@ type SingleDownloader struct {
 	Limiter      types.ByteLimiter
 	TotalSize    int64
 	Headers      map[string]string // Custom HTTP headers (cookies, auth, etc.)
+
+	// Request sets the method and body for endpoints that do not serve the
+	// file to a plain GET. The zero value sends a GET.
+	Request types.RequestOptions
+
+	// SkipPreallocate streams straight into the working file without reserving
+	// its final size first. Used by the small-file fast path.
+	SkipPreallocate bool
 }
 
 var bufPool = sync.Pool{
@@ -49,29 +56,17 @@ func NewSingleDownloader(id string, progressCh chan<- any, state *types.Progress
 }
 
 func (d *SingleDownloader) applyClientSettings(client *http.Client) {
-	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
-		if len(via) >= 10 {
-			return types.ErrMaxRedirects
-		}
-		if len(via) > 0 {
-			utils.CopyRedirectHeaders(req, via[0])
-		}
-		if d.Headers != nil {
-			for key, val := range d.Headers {
-				if key != "Range" {
-					req.Header.Set(key, val)
-				}
-			}
-		}
-		return nil
-	}
+	client.CheckRedirect = engine.RedirectPolicy(d.Runtime, d.Headers)
 }
 
 // Download downloads a file using a single connection.
 // This is used for servers that don't support Range requests.
 // If interrupted, the download cannot be resumed and must restart from the beginning.
 func (d *SingleDownloader) Download(ctx context.Context, rawurl, destPath string, fileSize int64, filename string) (err error) {
-	transport := engine.DefaultNetworkPool.AcquireTransport(d.Runtime.ProxyURL, d.Runtime.CustomDNS, types.PoolMaxConnsPerHost)
+	transport, err := engine.DefaultNetworkPool.AcquireTransportFor(d.Runtime, types.PoolMaxConnsPerHost)
+	if err != nil {
+		return fmt.Errorf("failed to configure TLS: %w", err)
+	}
 	defer engine.DefaultNetworkPool.ReleaseTransport(transport)
 
 	client := &http.Client{Transport: transport}
@@ -82,28 +77,54 @@ func (d *SingleDownloader) Download(ctx context.Context, rawurl, destPath string
 		d.State.SetDestPath(destPath)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawurl, nil)
-	if err != nil {
-		return err
-	}
+	// A busy server (429/503) is waited out per its Retry-After before the
+	// download is given up on.
+	var resp *http.Response
+	for throttleRetries := 0; ; throttleRetries++ {
+		req, err := d.Request.NewRequest(ctx, rawurl)
+		if err != nil {
+			return err
+		}
 
-	for key, val := range d.Headers {
-		req.Header.Set(key, val)
-	}
-	req.Header.Set("User-Agent", d.Runtime.GetUserAgent())
+		for key, val := range d.Headers {
+			req.Header.Set(key, val)
+		}
+		req.Header.Set("User-Agent", d.Runtime.GetUserAgent())
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return err
+		resp, err = client.Do(req)
+		if err != nil {
+			return err
+		}
+		throttle := engine.CheckThrottle(resp)
+		if throttle == nil {
+			break
+		}
+		if throttleRetries >= types.MaxThrottleRetries {
+			return throttle
+		}
+		wait := throttle.Delay(throttleRetries)
+		utils.DebugFor(d.ID, "Single download: %v, waiting %v", throttle, wait)
+		if err := engine.SleepContext(ctx, wait); err != nil {
+			return err
+		}
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			utils.Debug("Error closing response body: %v", err)
+			utils.DebugFor(d.ID, "Error closing response body: %v", err)
 		}
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return &types.HTTPStatusError{StatusCode: resp.StatusCode}
+	}
+
+	if d.State != nil && resp.Request != nil && resp.Request.URL != nil {
+		if final := resp.Request.URL.String(); final != rawurl {
+			d.State.SetFinalURL(final)
+		}
+	}
+	if t := engine.LastModified(resp); d.State != nil && !t.IsZero() {
+		d.State.SetLastModified(t)
 	}
 
 	if fileSize <= 0 && resp.ContentLength > 0 {
@@ -115,8 +136,7 @@ func (d *SingleDownloader) Download(ctx context.Context, rawurl, destPath string
 	}
 
 	// Use .surge extension for incomplete file (must be pre-created by processing layer)
-	workingPath := destPath + types.IncompleteSuffix
-	outFile, err := os.OpenFile(workingPath, os.O_RDWR, 0)
+	outFile, err := engine.OpenWorkingFile(destPath, d.Request.Copies)
 	if err != nil {
 		return err
 	}
@@ -126,8 +146,15 @@ func (d *SingleDownloader) Download(ctx context.Context, rawurl, destPath string
 		}
 	}()
 
+	if d.SkipPreallocate {
+		// The working file may still hold bytes from an earlier attempt.
+		if err := outFile.Truncate(0); err != nil {
+			return fmt.Errorf("truncate error: %w", err)
+		}
+	}
+
 	preallocated := false
-	if fileSize > 0 {
+	if fileSize > 0 && !d.SkipPreallocate {
 		if err := preallocateFile(outFile, fileSize); err != nil {
 			return fmt.Errorf("failed to preallocate file: %w", err)
 		}
@@ -147,15 +174,15 @@ func (d *SingleDownloader) Download(ctx context.Context, rawurl, destPath string
 	}
 
 	if d.State == nil {
-		written, err = io.CopyBuffer(outFile, reader, buf)
+		written, err = d.copyBuffer(outFile, reader, buf)
 	} else {
 		progressReader := newProgressReader(reader, d.State, types.WorkerBatchSize, types.WorkerBatchInterval)
-		written, err = io.CopyBuffer(outFile, progressReader, buf)
+		written, err = d.copyBuffer(outFile, progressReader, buf)
 		progressReader.Flush()
 	}
 	if err != nil {
-		if ctxErr := ctx.Err(); ctxErr != nil {
-			return ctxErr
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
 		}
 		return fmt.Errorf("copy error: %w", err)
 	}
@@ -180,7 +207,7 @@ func (d *SingleDownloader) Download(ctx context.Context, rawurl, destPath string
 	if elapsed > 0 {
 		speed = float64(written) / elapsed.Seconds()
 	}
-	utils.Debug("\nDownloaded %s in %s (%s/s)\n",
+	utils.DebugFor(d.ID, "\nDownloaded %s in %s (%s/s)\n",
 		destPath,
 		elapsed.Round(time.Second),
 		utils.ConvertBytesToHumanReadable(int64(speed)),
@@ -189,6 +216,26 @@ func (d *SingleDownloader) Download(ctx context.Context, rawurl, destPath string
 	return nil
 }
 
+// copyBuffer copies src to dst, on a thread with lowered disk and CPU priority
+// when the download asked to run in the background.
+func (d *SingleDownloader) copyBuffer(dst io.Writer, src io.Reader, buf []byte) (int64, error) {
+	if !d.Request.LowPriority {
+		return io.CopyBuffer(dst, src, buf)
+	}
+	var n int64
+	var err error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if perr := utils.LowerThreadPriority(); perr != nil {
+			utils.DebugFor(d.ID, "Could not lower priority of %s: %v", d.ID, perr)
+		}
+		n, err = io.CopyBuffer(dst, src, buf)
+	}()
+	<-done
+	return n, err
+}
+
 type throttledReader struct {
 	reader  io.Reader
 	limiter types.ByteLimiter
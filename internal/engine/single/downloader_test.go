@@ -653,6 +653,42 @@ func TestSingleDownloader_PreallocateFailure_ReleasesFileHandle(t *testing.T) {
 	}
 }
 
+// =============================================================================
+// SkipPreallocate - small-file fast path
+// =============================================================================
+
+func TestSingleDownloader_SkipPreallocateReplacesStaleBytes(t *testing.T) {
+	tmpDir, cleanup, _ := testutil.TempDir("surge-skip-prealloc")
+	defer cleanup()
+
+	fileSize := int64(16 * types.KB)
+	server := testutil.NewMockServerT(t,
+		testutil.WithFileSize(fileSize),
+		testutil.WithRangeSupport(true),
+	)
+	defer server.Close()
+
+	destPath := filepath.Join(tmpDir, "skip_prealloc.bin")
+	surgePath := destPath + types.IncompleteSuffix
+	// A longer leftover from an earlier attempt must not survive the rewrite.
+	if err := os.WriteFile(surgePath, make([]byte, 2*fileSize), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	downloader := NewSingleDownloader("skip-prealloc-id", nil, nil, &types.RuntimeConfig{})
+	downloader.SkipPreallocate = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := downloader.Download(ctx, server.URL(), destPath, fileSize, "skip_prealloc.bin"); err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	if err := testutil.VerifyFileSize(surgePath, fileSize); err != nil {
+		t.Error(err)
+	}
+}
+
 // =============================================================================
 // Benchmarks
 // =============================================================================
@@ -790,3 +826,45 @@ func TestThrottledReader_UsesLimiterErrorForCleanRead(t *testing.T) {
 		t.Fatalf("Read error = %v, want %v", err, waitErr)
 	}
 }
+
+func TestSingleDownloader_Download_PostBody(t *testing.T) {
+	tmpDir, cleanup, _ := testutil.TempDir("surge-post")
+	defer cleanup()
+
+	payload := []byte("id,name\n1,surge\n")
+	server := testutil.NewHTTPServerT(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost || string(body) != "format=csv" {
+			http.Error(w, "export needs a POST", http.StatusMethodNotAllowed)
+			return
+		}
+		if ct := r.Header.Get("Content-Type"); ct != types.ContentTypeForm {
+			http.Error(w, "bad content type "+ct, http.StatusUnsupportedMediaType)
+			return
+		}
+		_, _ = w.Write(payload)
+	}))
+	defer server.Close()
+
+	destPath := filepath.Join(tmpDir, "export.csv")
+	if f, err := os.Create(destPath + types.IncompleteSuffix); err == nil {
+		_ = f.Close()
+	}
+
+	downloader := NewSingleDownloader("post-id", nil, types.NewProgressState("post", 0), &types.RuntimeConfig{})
+	downloader.Request = types.RequestOptions{Method: http.MethodPost, Body: "format=csv"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := downloader.Download(ctx, server.URL, destPath, 0, "export.csv"); err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	got, err := os.ReadFile(destPath + types.IncompleteSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(payload) {
+		t.Errorf("downloaded %q, want %q", got, payload)
+	}
+}
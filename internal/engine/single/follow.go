@@ -0,0 +1,167 @@
+package single
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// Follow keeps appending to the working file of destPath while the remote
+// file grows past size, for logs and uploads that are still being written.
+// It returns the final size once the remote size has not changed for the
+// runtime's follow window.
+func (d *SingleDownloader) Follow(ctx context.Context, rawurl, destPath string, size int64) (int64, error) {
+	transport, err := engine.DefaultNetworkPool.AcquireTransportFor(d.Runtime, types.PoolMaxConnsPerHost)
+	if err != nil {
+		return size, fmt.Errorf("failed to configure TLS: %w", err)
+	}
+	defer engine.DefaultNetworkPool.ReleaseTransport(transport)
+
+	client := &http.Client{Transport: transport}
+	d.applyClientSettings(client)
+
+	outFile, err := engine.OpenWorkingFile(destPath, d.Request.Copies)
+	if err != nil {
+		return size, err
+	}
+	defer func() {
+		if cerr := outFile.Close(); cerr != nil {
+			utils.DebugFor(d.ID, "Follow: error closing working file: %v", cerr)
+		}
+	}()
+
+	window := d.Runtime.GetFollowStableWindow()
+	interval := min(window/2, types.FollowPollInterval)
+	utils.DebugFor(d.ID, "Follow: watching %s for growth past %d bytes (stable window %v)", rawurl, size, window)
+
+	failures := 0
+	lastGrowth := time.Now()
+	for time.Since(lastGrowth) < window {
+		if err := engine.SleepContext(ctx, interval); err != nil {
+			return size, err
+		}
+
+		n, err := d.fetchTail(ctx, client, rawurl, outFile, size)
+		if n > 0 {
+			size += n
+			lastGrowth = time.Now()
+			failures = 0
+			if d.State != nil {
+				d.State.SetTotalSize(size)
+				d.State.Downloaded.Store(size)
+				d.State.VerifiedProgress.Store(size)
+			}
+			utils.DebugFor(d.ID, "Follow: %s grew by %d bytes to %d", rawurl, n, size)
+		}
+		if err == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return size, context.Cause(ctx)
+		}
+		var throttle *engine.ThrottleError
+		if errors.As(err, &throttle) {
+			// A busy server is not a failure; wait as asked and check again.
+			if err := engine.SleepContext(ctx, throttle.Wait); err != nil {
+				return size, err
+			}
+			continue
+		}
+		if errors.Is(err, errRemoteShrank) || errors.Is(err, errRangeIgnored) {
+			return size, err
+		}
+		failures++
+		if failures > d.Runtime.GetMaxTaskRetries() {
+			return size, err
+		}
+		utils.DebugFor(d.ID, "Follow: size check failed (%d/%d): %v", failures, d.Runtime.GetMaxTaskRetries(), err)
+	}
+
+	if err := outFile.Truncate(size); err != nil {
+		return size, fmt.Errorf("truncate error: %w", err)
+	}
+	if err := outFile.Sync(); err != nil {
+		return size, fmt.Errorf("sync error: %w", err)
+	}
+	return size, nil
+}
+
+// errRemoteShrank means the followed file got smaller, so it was replaced or
+// rotated rather than appended to and the bytes on disk no longer match it.
+var errRemoteShrank = errors.New("remote file shrank while being followed")
+
+// errRangeIgnored means the server does not answer a range past the end of
+// what is on disk, so every poll would send the whole file again.
+var errRangeIgnored = errors.New("server does not support ranges for following")
+
+// fetchTail requests everything past size and writes it at that offset. It
+// returns how many bytes it wrote, which may be non-zero alongside an error.
+func (d *SingleDownloader) fetchTail(ctx context.Context, client *http.Client, rawurl string, out engine.WorkingFile, size int64) (int64, error) {
+	req, err := d.Request.NewRequest(ctx, rawurl)
+	if err != nil {
+		return 0, err
+	}
+	for key, val := range d.Headers {
+		req.Header.Set(key, val)
+	}
+	req.Header.Set("User-Agent", d.Runtime.GetUserAgent())
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", size))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	if throttle := engine.CheckThrottle(resp); throttle != nil {
+		return 0, throttle
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			utils.DebugFor(d.ID, "Error closing response body: %v", err)
+		}
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusRequestedRangeNotSatisfiable:
+		// Nothing past size yet, unless the file is now smaller than that.
+		if cr, ok := utils.ParseContentRange(resp.Header.Get("Content-Range")); ok && cr.Total >= 0 && cr.Total < size {
+			return 0, fmt.Errorf("%w: %d -> %d bytes", errRemoteShrank, size, cr.Total)
+		}
+		return 0, nil
+	case http.StatusPartialContent:
+		if cr, ok := utils.ParseContentRange(resp.Header.Get("Content-Range")); !ok || cr.Start != size {
+			return 0, fmt.Errorf("%w: answered range %q, want start %d", errRangeIgnored, resp.Header.Get("Content-Range"), size)
+		}
+	case http.StatusOK:
+		if resp.ContentLength >= 0 && resp.ContentLength < size {
+			return 0, fmt.Errorf("%w: %d -> %d bytes", errRemoteShrank, size, resp.ContentLength)
+		}
+		return 0, fmt.Errorf("%w: answered status 200", errRangeIgnored)
+	default:
+		return 0, &types.HTTPStatusError{StatusCode: resp.StatusCode}
+	}
+	// The file grew, so it carries a newer Last-Modified
+	if t := engine.LastModified(resp); d.State != nil && !t.IsZero() {
+		d.State.SetLastModified(t)
+	}
+
+	reader := io.Reader(resp.Body)
+	if d.Limiter != nil {
+		reader = &throttledReader{reader: resp.Body, limiter: d.Limiter, ctx: ctx}
+	}
+
+	bufPtr := bufPool.Get().(*[]byte)
+	defer bufPool.Put(bufPtr)
+
+	n, err := d.copyBuffer(io.NewOffsetWriter(out, size), reader, *bufPtr)
+	if err != nil {
+		return n, fmt.Errorf("copy error: %w", err)
+	}
+	return n, nil
+}
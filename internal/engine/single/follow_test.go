@@ -0,0 +1,125 @@
+package single
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/testutil"
+)
+
+// growingFile is a remote file that tests append to or replace while it is
+// being followed.
+type growingFile struct {
+	mu          sync.Mutex
+	data        []byte
+	ignoreRange bool
+}
+
+func (g *growingFile) set(data []byte) {
+	g.mu.Lock()
+	g.data = data
+	g.mu.Unlock()
+}
+
+func (g *growingFile) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	data := bytes.Clone(g.data)
+	g.mu.Unlock()
+	if g.ignoreRange {
+		r.Header.Del("Range")
+	}
+	http.ServeContent(w, r, "app.log", time.Time{}, bytes.NewReader(data))
+}
+
+// startFollowTest serves remote and writes onDisk as the working file, as if
+// the first pass of the download had just finished.
+func startFollowTest(t *testing.T, remote *growingFile, onDisk []byte) (string, string, *SingleDownloader) {
+	t.Helper()
+	server := testutil.NewHTTPServerT(t, remote)
+	t.Cleanup(server.Close)
+
+	destPath := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(destPath+types.IncompleteSuffix, onDisk, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	runtime := &types.RuntimeConfig{FollowStableWindow: 300 * time.Millisecond}
+	d := NewSingleDownloader("follow-id", nil, types.NewProgressState("follow", int64(len(onDisk))), runtime)
+	d.Request = types.RequestOptions{Follow: true}
+	return server.URL, destPath, d
+}
+
+func TestSingleDownloader_Follow_AppendsUntilStable(t *testing.T) {
+	full := []byte("line 1\nline 2\nline 3\n")
+	remote := &growingFile{data: full[:7]}
+	url, destPath, d := startFollowTest(t, remote, full[:7])
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		remote.set(full[:14])
+		time.Sleep(200 * time.Millisecond)
+		remote.set(full)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	start := time.Now()
+	size, err := d.Follow(ctx, url, destPath, 7)
+	if err != nil {
+		t.Fatalf("Follow failed: %v", err)
+	}
+	if size != int64(len(full)) {
+		t.Errorf("size = %d, want %d", size, len(full))
+	}
+	if elapsed := time.Since(start); elapsed < 500*time.Millisecond {
+		t.Errorf("Follow returned after %v, before the size was stable for the window", elapsed)
+	}
+	if got, _ := os.ReadFile(destPath + types.IncompleteSuffix); !bytes.Equal(got, full) {
+		t.Errorf("file = %q, want %q", got, full)
+	}
+	if _, total, _, _, _, _ := d.State.GetProgress(); total != int64(len(full)) {
+		t.Errorf("state total = %d, want %d", total, len(full))
+	}
+}
+
+func TestSingleDownloader_Follow_FailsWhenRemoteShrinks(t *testing.T) {
+	remote := &growingFile{data: []byte("old log contents\n")}
+	url, destPath, d := startFollowTest(t, remote, []byte("old log contents\n"))
+	remote.set([]byte("rotated\n"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := d.Follow(ctx, url, destPath, 17); !errors.Is(err, errRemoteShrank) {
+		t.Fatalf("Follow error = %v, want errRemoteShrank", err)
+	}
+}
+
+func TestSingleDownloader_Follow_StopsWhenRangesAreIgnored(t *testing.T) {
+	remote := &growingFile{data: []byte("line 1\nline 2\n"), ignoreRange: true}
+	url, destPath, d := startFollowTest(t, remote, []byte("line 1\n"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := d.Follow(ctx, url, destPath, 7); !errors.Is(err, errRangeIgnored) {
+		t.Fatalf("Follow error = %v, want errRangeIgnored", err)
+	}
+}
+
+func TestSingleDownloader_Follow_Cancellation(t *testing.T) {
+	remote := &growingFile{data: []byte("x")}
+	url, destPath, d := startFollowTest(t, remote, []byte("x"))
+	d.Runtime.FollowStableWindow = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := d.Follow(ctx, url, destPath, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Follow error = %v, want the context error", err)
+	}
+}
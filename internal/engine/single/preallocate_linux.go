@@ -5,16 +5,21 @@ package single
 import (
 	"os"
 	"syscall"
+
+	"github.com/SurgeDM/Surge/internal/engine"
 )
 
 // preallocateFile attempts physical allocation first and falls back to logical truncation.
-func preallocateFile(file *os.File, size int64) error {
+func preallocateFile(file engine.WorkingFile, size int64) error {
 	if size <= 0 {
 		return nil
 	}
 
-	if err := syscall.Fallocate(int(file.Fd()), 0, 0, size); err == nil {
-		return nil
+	// Copies written alongside are only extended logically.
+	if f, ok := file.(*os.File); ok {
+		if err := syscall.Fallocate(int(f.Fd()), 0, 0, size); err == nil {
+			return nil
+		}
 	}
 
 	return file.Truncate(size)
@@ -2,9 +2,9 @@
 
 package single
 
-import "os"
+import "github.com/SurgeDM/Surge/internal/engine"
 
-func preallocateFile(file *os.File, size int64) error {
+func preallocateFile(file engine.WorkingFile, size int64) error {
 	if size <= 0 {
 		return nil
 	}
@@ -0,0 +1,47 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"syscall"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+// maxSourcePortAttempts bounds how many ports one dial tries before giving
+// up, so a large range that is nearly used up does not stall it.
+const maxSourcePortAttempts = 1024
+
+// dialFromPorts dials addr from a local port in ports, keeping the local IP
+// dialer is bound to. It starts at a random port, so connections opened
+// together do not all race for the same one, and moves on while the port
+// it tried is taken.
+func dialFromPorts(ctx context.Context, dialer *net.Dialer, ports types.PortRanges, network, addr string) (net.Conn, error) {
+	var ip net.IP
+	if local, ok := dialer.LocalAddr.(*net.TCPAddr); ok {
+		ip = local.IP
+	}
+
+	n := ports.Len()
+	start := rand.IntN(n)
+	var lastErr error
+	for i := range min(n, maxSourcePortAttempts) {
+		d := *dialer
+		d.LocalAddr = &net.TCPAddr{IP: ip, Port: ports.At((start + i) % n)}
+		conn, err := d.DialContext(ctx, network, addr)
+		if err == nil || !portTaken(err) || ctx.Err() != nil {
+			return conn, err
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("no free source port in %s: %w", ports, lastErr)
+}
+
+// portTaken reports whether err means the local port is in use, by a
+// listener or a connection to the same address still in TIME_WAIT.
+func portTaken(err error) bool {
+	return errors.Is(err, syscall.EADDRINUSE) || errors.Is(err, syscall.EADDRNOTAVAIL)
+}
@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+// takenPort returns a local port held by a listener until the test ends.
+func takenPort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// freePort returns a local port nothing is using right now.
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	_ = l.Close()
+	return port
+}
+
+func TestNetworkPool_SourcePortsSkipsTakenPorts(t *testing.T) {
+	remote := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remote <- r.RemoteAddr
+	}))
+	defer server.Close()
+
+	taken, free := takenPort(t), freePort(t)
+	pool := &NetworkPool{}
+	transport, err := pool.AcquireTransportFor(&types.RuntimeConfig{SourcePorts: fmt.Sprintf("%d,%d", taken, free)}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.ReleaseTransport(transport)
+
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if _, port, _ := net.SplitHostPort(<-remote); port != strconv.Itoa(free) {
+		t.Errorf("request came from port %s, want %d", port, free)
+	}
+}
+
+func TestNetworkPool_SourcePortsExhausted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	pool := &NetworkPool{}
+	transport, err := pool.AcquireTransportFor(&types.RuntimeConfig{SourcePorts: strconv.Itoa(takenPort(t))}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.ReleaseTransport(transport)
+
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err == nil {
+		_ = resp.Body.Close()
+		t.Fatal("expected the request to fail with every source port taken")
+	}
+	if !strings.Contains(err.Error(), "no free source port") {
+		t.Errorf("error = %v, want it to say no source port was free", err)
+	}
+}
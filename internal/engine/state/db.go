@@ -71,7 +71,23 @@ func initDBLocked() error {
 		avg_speed REAL,
 		file_hash TEXT,
 		rate_limit INTEGER,
-		rate_limit_set INTEGER
+		rate_limit_set INTEGER,
+		final_url TEXT,
+		s3_part_size INTEGER,
+		s3_checksum TEXT,
+		alias TEXT,
+		tags TEXT,
+		headers TEXT,
+		checksum TEXT,
+		copies TEXT,
+		signature_url TEXT,
+		verified INTEGER,
+		fetched INTEGER,
+		response_headers TEXT,
+		redirects TEXT,
+		bind_interface TEXT,
+		tls TEXT,
+		method TEXT
 	);
 
 	CREATE TABLE IF NOT EXISTS tasks (
@@ -83,6 +99,33 @@ func initDBLocked() error {
 	);
 
 	CREATE INDEX IF NOT EXISTS idx_tasks_download_id ON tasks(download_id);
+
+	CREATE TABLE IF NOT EXISTS traffic (
+		month TEXT PRIMARY KEY,
+		bytes INTEGER NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE IF NOT EXISTS speed_history (
+		start INTEGER PRIMARY KEY,
+		bytes INTEGER NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE IF NOT EXISTS global_pause (
+		download_id TEXT PRIMARY KEY
+	);
+
+	CREATE TABLE IF NOT EXISTS snoozes (
+		download_id TEXT PRIMARY KEY,
+		until INTEGER NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS failures (
+		download_id TEXT PRIMARY KEY,
+		attempts INTEGER NOT NULL,
+		last_error TEXT,
+		error_code TEXT,
+		failed_at INTEGER
+	);
 	`
 
 	if _, err := db.Exec(query); err != nil {
@@ -134,6 +177,22 @@ func ensureDownloadsSchema() error {
 		{"file_hash", "TEXT"},
 		{"rate_limit", "INTEGER"},
 		{"rate_limit_set", "INTEGER"},
+		{"final_url", "TEXT"},
+		{"s3_part_size", "INTEGER"},
+		{"s3_checksum", "TEXT"},
+		{"alias", "TEXT"},
+		{"tags", "TEXT"},
+		{"headers", "TEXT"},
+		{"checksum", "TEXT"},
+		{"copies", "TEXT"},
+		{"signature_url", "TEXT"},
+		{"verified", "INTEGER"},
+		{"fetched", "INTEGER"},
+		{"response_headers", "TEXT"},
+		{"redirects", "TEXT"},
+		{"bind_interface", "TEXT"},
+		{"tls", "TEXT"},
+		{"method", "TEXT"},
 	}
 
 	for _, col := range columnsToAdd {
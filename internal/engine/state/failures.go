@@ -0,0 +1,103 @@
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+// Failure is how often a download has failed since it last succeeded or
+// was retried by hand, and how it failed last.
+type Failure struct {
+	DownloadID string
+	Attempts   int
+	LastError  string
+	Code       types.ErrorCode // Kind of LastError
+	FailedAt   time.Time
+}
+
+// RecordFailure counts another failure of id and returns how many it has
+// had.
+func RecordFailure(id, lastError string, code types.ErrorCode, at time.Time) (int, error) {
+	db := getDBHelper()
+	if db == nil {
+		return 0, fmt.Errorf("database not initialized")
+	}
+
+	if _, err := db.Exec(`INSERT INTO failures (download_id, attempts, last_error, error_code, failed_at) VALUES (?, 1, ?, ?, ?)
+		ON CONFLICT(download_id) DO UPDATE SET attempts = attempts + 1, last_error = excluded.last_error,
+		error_code = excluded.error_code, failed_at = excluded.failed_at`,
+		id, lastError, string(code), at.Unix()); err != nil {
+		return 0, fmt.Errorf("failed to record failure: %w", err)
+	}
+	var attempts int
+	if err := db.QueryRow("SELECT attempts FROM failures WHERE download_id = ?", id).Scan(&attempts); err != nil {
+		return 0, fmt.Errorf("failed to read failures: %w", err)
+	}
+	return attempts, nil
+}
+
+// GetFailure returns the failures of id, or nil if it has none.
+func GetFailure(id string) (*Failure, error) {
+	failures, err := queryFailures("SELECT download_id, attempts, last_error, error_code, failed_at FROM failures WHERE download_id = ?", id)
+	if err != nil || len(failures) == 0 {
+		return nil, err
+	}
+	return &failures[0], nil
+}
+
+// Failures returns every download with failures, most recent first.
+func Failures() ([]Failure, error) {
+	return queryFailures("SELECT download_id, attempts, last_error, error_code, failed_at FROM failures ORDER BY failed_at DESC")
+}
+
+func queryFailures(query string, args ...any) ([]Failure, error) {
+	db := getDBHelper()
+	if db == nil {
+		return nil, nil
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query failures: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var failures []Failure
+	for rows.Next() {
+		var f Failure
+		var lastError, code *string
+		var failedAt *int64
+		if err := rows.Scan(&f.DownloadID, &f.Attempts, &lastError, &code, &failedAt); err != nil {
+			return nil, err
+		}
+		if lastError != nil {
+			f.LastError = *lastError
+		}
+		if code != nil {
+			f.Code = types.ErrorCode(*code)
+		}
+		if failedAt != nil {
+			f.FailedAt = time.Unix(*failedAt, 0)
+		}
+		failures = append(failures, f)
+	}
+	return failures, rows.Err()
+}
+
+// ClearFailures forgets the failures of ids, typically because they
+// finished, were removed or are being retried by hand.
+func ClearFailures(ids ...string) error {
+	db := getDBHelper()
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	for _, id := range ids {
+		if _, err := db.Exec("DELETE FROM failures WHERE download_id = ?", id); err != nil {
+			return fmt.Errorf("failed to clear failures: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func TestFailures_CountsAndClears(t *testing.T) {
+	setupTestDB(t)
+
+	first := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if n, err := RecordFailure("a", "connection reset", types.ErrorCodeNetwork, first); err != nil || n != 1 {
+		t.Fatalf("RecordFailure = %d, %v; want 1", n, err)
+	}
+	if n, err := RecordFailure("a", "404 Not Found", types.ErrorCodeHTTP4xx, first.Add(time.Minute)); err != nil || n != 2 {
+		t.Fatalf("second RecordFailure = %d, %v; want 2", n, err)
+	}
+	if _, err := RecordFailure("b", "timeout", types.ErrorCodeNetwork, first.Add(2*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := GetFailure("a")
+	if err != nil || f == nil {
+		t.Fatalf("GetFailure = %v, %v", f, err)
+	}
+	if f.Attempts != 2 || f.LastError != "404 Not Found" || f.Code != types.ErrorCodeHTTP4xx || !f.FailedAt.Equal(first.Add(time.Minute)) {
+		t.Fatalf("GetFailure = %+v", *f)
+	}
+
+	failures, err := Failures()
+	if err != nil || len(failures) != 2 || failures[0].DownloadID != "b" {
+		t.Fatalf("Failures = %+v, %v; want b first", failures, err)
+	}
+
+	if err := ClearFailures("a"); err != nil {
+		t.Fatal(err)
+	}
+	if f, _ := GetFailure("a"); f != nil {
+		t.Fatalf("GetFailure after clearing = %+v", *f)
+	}
+	if n, _ := RecordFailure("a", "again", types.ErrorCodeUnknown, first); n != 1 {
+		t.Fatalf("RecordFailure after clearing = %d, want 1", n)
+	}
+}
@@ -0,0 +1,64 @@
+package state
+
+import "fmt"
+
+// AddGlobalPause records ids as paused by a pause-all, so a later resume-all
+// resumes them and nothing else.
+func AddGlobalPause(ids []string) error {
+	db := getDBHelper()
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	for _, id := range ids {
+		if _, err := db.Exec("INSERT OR IGNORE INTO global_pause (download_id) VALUES (?)", id); err != nil {
+			return fmt.Errorf("failed to record global pause: %w", err)
+		}
+	}
+	return nil
+}
+
+// GlobalPaused returns the downloads paused by a pause-all that has not been
+// undone, in the order they were paused.
+func GlobalPaused() ([]string, error) {
+	db := getDBHelper()
+	if db == nil {
+		return nil, nil
+	}
+
+	rows, err := db.Query("SELECT download_id FROM global_pause ORDER BY rowid")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query global pause: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// RemoveGlobalPause forgets ids, typically because they were resumed on
+// their own. With no ids it forgets every one.
+func RemoveGlobalPause(ids ...string) error {
+	db := getDBHelper()
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	if len(ids) == 0 {
+		_, err := db.Exec("DELETE FROM global_pause")
+		return err
+	}
+	for _, id := range ids {
+		if _, err := db.Exec("DELETE FROM global_pause WHERE download_id = ?", id); err != nil {
+			return fmt.Errorf("failed to clear global pause: %w", err)
+		}
+	}
+	return nil
+}
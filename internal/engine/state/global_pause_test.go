@@ -0,0 +1,34 @@
+package state
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGlobalPause_RecordsAndForgets(t *testing.T) {
+	setupTestDB(t)
+
+	if err := AddGlobalPause([]string{"b", "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddGlobalPause([]string{"a", "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if ids, err := GlobalPaused(); err != nil || !slices.Equal(ids, []string{"b", "a", "c"}) {
+		t.Fatalf("GlobalPaused = %v, %v; want [b a c]", ids, err)
+	}
+
+	if err := RemoveGlobalPause("a"); err != nil {
+		t.Fatal(err)
+	}
+	if ids, _ := GlobalPaused(); !slices.Equal(ids, []string{"b", "c"}) {
+		t.Fatalf("GlobalPaused after removing a = %v", ids)
+	}
+
+	if err := RemoveGlobalPause(); err != nil {
+		t.Fatal(err)
+	}
+	if ids, _ := GlobalPaused(); len(ids) != 0 {
+		t.Fatalf("GlobalPaused after clearing = %v", ids)
+	}
+}
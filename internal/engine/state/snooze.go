@@ -0,0 +1,58 @@
+package state
+
+import (
+	"fmt"
+	"time"
+)
+
+// SetSnooze records that the paused download id resumes on its own at
+// until, replacing any earlier snooze.
+func SetSnooze(id string, until time.Time) error {
+	db := getDBHelper()
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	if _, err := db.Exec("INSERT OR REPLACE INTO snoozes (download_id, until) VALUES (?, ?)", id, until.Unix()); err != nil {
+		return fmt.Errorf("failed to record snooze: %w", err)
+	}
+	return nil
+}
+
+// Snoozes returns when each snoozed download is due to resume.
+func Snoozes() (map[string]time.Time, error) {
+	db := getDBHelper()
+	if db == nil {
+		return nil, nil
+	}
+
+	rows, err := db.Query("SELECT download_id, until FROM snoozes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query snoozes: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	snoozes := make(map[string]time.Time)
+	for rows.Next() {
+		var id string
+		var until int64
+		if err := rows.Scan(&id, &until); err != nil {
+			return nil, err
+		}
+		snoozes[id] = time.Unix(until, 0)
+	}
+	return snoozes, rows.Err()
+}
+
+// RemoveSnooze forgets the snooze of id, if any.
+func RemoveSnooze(id string) error {
+	db := getDBHelper()
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	if _, err := db.Exec("DELETE FROM snoozes WHERE download_id = ?", id); err != nil {
+		return fmt.Errorf("failed to clear snooze: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnooze_RecordsAndForgets(t *testing.T) {
+	setupTestDB(t)
+
+	first := time.Date(2026, 3, 1, 22, 0, 0, 0, time.UTC)
+	if err := SetSnooze("a", first); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetSnooze("a", first.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetSnooze("b", first); err != nil {
+		t.Fatal(err)
+	}
+
+	snoozes, err := Snoozes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snoozes) != 2 || !snoozes["a"].Equal(first.Add(time.Hour)) || !snoozes["b"].Equal(first) {
+		t.Fatalf("Snoozes = %v", snoozes)
+	}
+
+	if err := RemoveSnooze("a"); err != nil {
+		t.Fatal(err)
+	}
+	if snoozes, _ := Snoozes(); len(snoozes) != 1 || snoozes["a"] != (time.Time{}) {
+		t.Fatalf("Snoozes after removing a = %v", snoozes)
+	}
+}
@@ -0,0 +1,69 @@
+package state
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// SpeedBucket is the span of time each speed history sample covers.
+	SpeedBucket = time.Minute
+	// SpeedHistoryWindow is how far back the speed history is kept.
+	SpeedHistoryWindow = 24 * time.Hour
+)
+
+// SpeedSample is how many bytes were downloaded, over all downloads, in the
+// SpeedBucket starting at Start.
+type SpeedSample struct {
+	Start int64 `json:"start"` // Unix seconds
+	Bytes int64 `json:"bytes"`
+}
+
+// Speed is the sample's average throughput in bytes per second.
+func (s SpeedSample) Speed() float64 {
+	return float64(s.Bytes) / SpeedBucket.Seconds()
+}
+
+// AddSpeedSample adds n bytes downloaded at at to the speed history and drops
+// samples older than SpeedHistoryWindow.
+func AddSpeedSample(at time.Time, n int64) error {
+	db := getDBHelper()
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	start := at.Truncate(SpeedBucket).Unix()
+	if _, err := db.Exec(`INSERT INTO speed_history (start, bytes) VALUES (?, ?)
+		ON CONFLICT(start) DO UPDATE SET bytes = bytes + excluded.bytes`, start, n); err != nil {
+		return fmt.Errorf("failed to record speed history: %w", err)
+	}
+	if _, err := db.Exec("DELETE FROM speed_history WHERE start < ?", at.Add(-SpeedHistoryWindow).Unix()); err != nil {
+		return fmt.Errorf("failed to prune speed history: %w", err)
+	}
+	return nil
+}
+
+// SpeedHistory returns the samples since since, oldest first. Buckets in
+// which nothing was downloaded have no sample.
+func SpeedHistory(since time.Time) ([]SpeedSample, error) {
+	db := getDBHelper()
+	if db == nil {
+		return nil, nil
+	}
+
+	rows, err := db.Query("SELECT start, bytes FROM speed_history WHERE start >= ? ORDER BY start", since.Truncate(SpeedBucket).Unix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query speed history: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var samples []SpeedSample
+	for rows.Next() {
+		var s SpeedSample
+		if err := rows.Scan(&s.Start, &s.Bytes); err != nil {
+			return nil, err
+		}
+		samples = append(samples, s)
+	}
+	return samples, rows.Err()
+}
@@ -0,0 +1,40 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpeedHistory_BucketsAndPrunes(t *testing.T) {
+	setupTestDB(t)
+
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	for _, s := range []struct {
+		at time.Time
+		n  int64
+	}{
+		{now.Add(-25 * time.Hour), 999},
+		{now.Add(-2 * time.Minute), 600},
+		{now.Add(-90 * time.Second), 600},
+		{now.Add(10 * time.Second), 60},
+	} {
+		if err := AddSpeedSample(s.at, s.n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	samples, err := SpeedHistory(now.Add(-SpeedHistoryWindow - time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SpeedSample{
+		{Start: now.Add(-2 * time.Minute).Unix(), Bytes: 1200},
+		{Start: now.Unix(), Bytes: 60},
+	}
+	if len(samples) != len(want) || samples[0] != want[0] || samples[1] != want[1] {
+		t.Fatalf("SpeedHistory = %v, want %v without the day-old sample", samples, want)
+	}
+	if got := samples[0].Speed(); got != 20 {
+		t.Fatalf("Speed = %v, want 20 bytes/s", got)
+	}
+}
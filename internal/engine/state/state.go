@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"database/sql"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -35,6 +36,72 @@ type SaveStateOptions struct {
 	InlineHashTimeout time.Duration
 }
 
+// encodeHeaders stores request headers as a JSON object, or NULL when there
+// are none.
+func encodeHeaders(headers map[string]string) any {
+	if len(headers) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(headers)
+	if err != nil {
+		return nil
+	}
+	return string(data)
+}
+
+func decodeHeaders(raw string) map[string]string {
+	if raw == "" {
+		return nil
+	}
+	var headers map[string]string
+	if err := json.Unmarshal([]byte(raw), &headers); err != nil {
+		utils.Debug("Ignoring unreadable saved headers: %v", err)
+		return nil
+	}
+	return headers
+}
+
+// encodeTLS stores a download's TLS override as a JSON object, or NULL when
+// it has none.
+func encodeTLS(opts types.TLSOptions) any {
+	if opts.IsZero() {
+		return nil
+	}
+	data, err := json.Marshal(opts)
+	if err != nil {
+		return nil
+	}
+	return string(data)
+}
+
+func decodeTLS(raw string) types.TLSOptions {
+	var opts types.TLSOptions
+	if raw == "" {
+		return opts
+	}
+	if err := json.Unmarshal([]byte(raw), &opts); err != nil {
+		utils.Debug("Ignoring unreadable saved TLS options: %v", err)
+		return types.TLSOptions{}
+	}
+	return opts
+}
+
+// encodeCopies stores copy directories one per line, or NULL when there are
+// none. Unlike mirror URLs, paths may contain commas.
+func encodeCopies(copies []string) any {
+	if len(copies) == 0 {
+		return nil
+	}
+	return strings.Join(copies, "\n")
+}
+
+func decodeCopies(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	return strings.Split(raw, "\n")
+}
+
 // URLHash returns a short hash of the URL for master list keying
 // This is used for tracking completed downloads by URL
 func URLHash(url string) string {
@@ -81,8 +148,8 @@ func SaveStateWithOptions(url string, destPath string, state *types.DownloadStat
 		// 1. Upsert into downloads table
 		_, err := tx.Exec(`
 				INSERT INTO downloads (
-					id, url, dest_path, filename, status, total_size, downloaded, url_hash, created_at, paused_at, time_taken, mirrors, chunk_bitmap, actual_chunk_size, file_hash, rate_limit, rate_limit_set
-			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+					id, url, dest_path, filename, status, total_size, downloaded, url_hash, created_at, paused_at, time_taken, mirrors, chunk_bitmap, actual_chunk_size, file_hash, rate_limit, rate_limit_set, final_url, s3_part_size, s3_checksum, headers, checksum, copies, signature_url, fetched, bind_interface, tls
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 			ON CONFLICT(id) DO UPDATE SET
 				url=excluded.url,
 				dest_path=excluded.dest_path,
@@ -98,8 +165,18 @@ func SaveStateWithOptions(url string, destPath string, state *types.DownloadStat
 				actual_chunk_size=excluded.actual_chunk_size,
 				file_hash=excluded.file_hash,
 				rate_limit=excluded.rate_limit,
-				rate_limit_set=excluded.rate_limit_set
-		`, state.ID, state.URL, state.DestPath, state.Filename, "paused", state.TotalSize, state.Downloaded, state.URLHash, state.CreatedAt, state.PausedAt, state.Elapsed/1e6, strings.Join(state.Mirrors, ","), state.ChunkBitmap, state.ActualChunkSize, state.FileHash, state.RateLimit, state.RateLimitSet)
+				rate_limit_set=excluded.rate_limit_set,
+				final_url=excluded.final_url,
+				s3_part_size=excluded.s3_part_size,
+				s3_checksum=excluded.s3_checksum,
+				headers=excluded.headers,
+				checksum=excluded.checksum,
+				copies=excluded.copies,
+				signature_url=excluded.signature_url,
+				fetched=excluded.fetched,
+				bind_interface=excluded.bind_interface,
+				tls=excluded.tls
+		`, state.ID, state.URL, state.DestPath, state.Filename, "paused", state.TotalSize, state.Downloaded, state.URLHash, state.CreatedAt, state.PausedAt, state.Elapsed/1e6, strings.Join(state.Mirrors, ","), state.ChunkBitmap, state.ActualChunkSize, state.FileHash, state.RateLimit, state.RateLimitSet, state.FinalURL, state.S3.PartSize, state.S3.Checksum.String(), encodeHeaders(state.Headers), state.Checksum, encodeCopies(state.Copies), state.SignatureURL, state.Fetched, state.Interface, encodeTLS(state.TLS))
 		if err != nil {
 			return fmt.Errorf("failed to upsert download: %w", err)
 		}
@@ -205,11 +282,11 @@ func computeFileHashMD5WithTimeout(path string, timeout time.Duration) (string,
 	return hashPrefixMD5 + hex.EncodeToString(h.Sum(nil)), false, nil
 }
 
-func compareAgainstStoredFileHash(path string, storedHash string) (bool, error) {
+func compareAgainstStoredFileHash(path string, storedHash string, timeout time.Duration) (bool, error) {
 	algo, expected := parseStoredHash(storedHash)
 	switch algo {
 	case "md5":
-		current, timedOut, err := computeFileHashMD5WithTimeout(path, DefaultInlineHashTimeout)
+		current, timedOut, err := computeFileHashMD5WithTimeout(path, timeout)
 		if err != nil {
 			return false, err
 		}
@@ -255,12 +332,12 @@ func LoadState(url string, destPath string) (*types.DownloadState, error) {
 	}
 
 	var state types.DownloadState
-	var timeTaken, createdAt, pausedAt, actualChunkSize, rateLimit, rateLimitSet sql.NullInt64 // handle null
-	var mirrors, fileHash sql.NullString                                                       // handle null mirrors/hash
+	var timeTaken, createdAt, pausedAt, actualChunkSize, rateLimit, rateLimitSet, s3PartSize, fetched sql.NullInt64             // handle null
+	var mirrors, fileHash, finalURL, s3Checksum, headers, checksum, copies, signatureURL, bindInterface, tlsOpts sql.NullString // handle null mirrors/hash/final url
 	var chunkBitmap []byte
 
 	row := db.QueryRow(`
-		SELECT id, url, dest_path, filename, total_size, downloaded, url_hash, created_at, paused_at, time_taken, mirrors, chunk_bitmap, actual_chunk_size, file_hash, rate_limit, rate_limit_set
+		SELECT id, url, dest_path, filename, total_size, downloaded, url_hash, created_at, paused_at, time_taken, mirrors, chunk_bitmap, actual_chunk_size, file_hash, rate_limit, rate_limit_set, final_url, s3_part_size, s3_checksum, headers, checksum, copies, signature_url, fetched, bind_interface, tls
 		FROM downloads 
 		WHERE url = ? AND dest_path = ? AND status != 'completed'
 		ORDER BY paused_at DESC LIMIT 1
@@ -269,7 +346,7 @@ func LoadState(url string, destPath string) (*types.DownloadState, error) {
 	err := row.Scan(
 		&state.ID, &state.URL, &state.DestPath, &state.Filename,
 		&state.TotalSize, &state.Downloaded, &state.URLHash,
-		&createdAt, &pausedAt, &timeTaken, &mirrors, &chunkBitmap, &actualChunkSize, &fileHash, &rateLimit, &rateLimitSet,
+		&createdAt, &pausedAt, &timeTaken, &mirrors, &chunkBitmap, &actualChunkSize, &fileHash, &rateLimit, &rateLimitSet, &finalURL, &s3PartSize, &s3Checksum, &headers, &checksum, &copies, &signatureURL, &fetched, &bindInterface, &tlsOpts,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -304,6 +381,22 @@ func LoadState(url string, destPath string) (*types.DownloadState, error) {
 	if rateLimitSet.Valid {
 		state.RateLimitSet = rateLimitSet.Int64 != 0
 	}
+	if finalURL.Valid {
+		state.FinalURL = finalURL.String
+	}
+	if s3PartSize.Valid {
+		state.S3.PartSize = s3PartSize.Int64
+	}
+	if s3Checksum.Valid {
+		state.S3.Checksum = types.ParseS3Checksum(s3Checksum.String)
+	}
+	state.Headers = decodeHeaders(headers.String)
+	state.Checksum = checksum.String
+	state.Copies = decodeCopies(copies.String)
+	state.SignatureURL = signatureURL.String
+	state.Interface = bindInterface.String
+	state.TLS = decodeTLS(tlsOpts.String)
+	state.Fetched = fetched.Int64 != 0
 
 	// Load tasks
 	rows, err := db.Query("SELECT offset, length FROM tasks WHERE download_id = ?", state.ID)
@@ -375,7 +468,7 @@ func LoadMasterList() (*types.MasterList, error) {
 	}
 
 	rows, err := db.Query(`
-		SELECT id, url, dest_path, filename, status, total_size, downloaded, completed_at, time_taken, url_hash, mirrors, avg_speed, rate_limit, rate_limit_set 
+		SELECT id, url, dest_path, filename, status, total_size, downloaded, completed_at, time_taken, url_hash, mirrors, avg_speed, rate_limit, rate_limit_set, alias, tags, verified, checksum, response_headers, redirects, method
 		FROM downloads
 	`)
 	if err != nil {
@@ -390,13 +483,13 @@ func LoadMasterList() (*types.MasterList, error) {
 	var list types.MasterList
 	for rows.Next() {
 		var e types.DownloadEntry
-		var completedAt, timeTaken, rateLimit, rateLimitSet sql.NullInt64 // handle nulls
-		var filename, urlHash, mirrors sql.NullString                     // handle nulls
-		var avgSpeed sql.NullFloat64                                      // handle null avg_speed
+		var completedAt, timeTaken, rateLimit, rateLimitSet, verified sql.NullInt64                          // handle nulls
+		var filename, urlHash, mirrors, alias, tags, checksum, respHeaders, redirects, method sql.NullString // handle nulls
+		var avgSpeed sql.NullFloat64                                                                         // handle null avg_speed
 
 		if err := rows.Scan(
 			&e.ID, &e.URL, &e.DestPath, &filename, &e.Status, &e.TotalSize, &e.Downloaded,
-			&completedAt, &timeTaken, &urlHash, &mirrors, &avgSpeed, &rateLimit, &rateLimitSet,
+			&completedAt, &timeTaken, &urlHash, &mirrors, &avgSpeed, &rateLimit, &rateLimitSet, &alias, &tags, &verified, &checksum, &respHeaders, &redirects, &method,
 		); err != nil {
 			return nil, err
 		}
@@ -425,6 +518,13 @@ func LoadMasterList() (*types.MasterList, error) {
 		if rateLimitSet.Valid {
 			e.RateLimitSet = rateLimitSet.Int64 != 0
 		}
+		e.Alias = alias.String
+		e.Tags = types.SplitTags(tags.String)
+		e.Verified = verified.Int64 != 0
+		e.Checksum = checksum.String
+		e.ResponseHeaders = decodeHeaders(respHeaders.String)
+		e.Redirects = decodeCopies(redirects.String)
+		e.Method = method.String
 
 		list.Downloads = append(list.Downloads, e)
 	}
@@ -432,7 +532,11 @@ func LoadMasterList() (*types.MasterList, error) {
 	return &list, nil
 }
 
-// AddToMasterList adds or updates a download entry
+// AddToMasterList adds or updates a download entry. An entry without an alias
+// keeps the one already stored; an entry with an alias takes it over from any
+// other download. An entry without tags likewise keeps the stored ones; use
+// SetTags to change or clear them. An entry without a checksum, response
+// headers, redirects or method keeps the stored ones.
 func AddToMasterList(entry types.DownloadEntry) error {
 	// Ensure ID
 	if entry.ID == "" {
@@ -445,10 +549,15 @@ func AddToMasterList(entry types.DownloadEntry) error {
 	}
 
 	return withTx(func(tx *sql.Tx) error {
+		if entry.Alias != "" {
+			if _, err := tx.Exec("UPDATE downloads SET alias = NULL WHERE alias = ? AND id != ?", entry.Alias, entry.ID); err != nil {
+				return err
+			}
+		}
 		_, err := tx.Exec(`
 			INSERT INTO downloads (
-				id, url, dest_path, filename, status, total_size, downloaded, completed_at, time_taken, url_hash, mirrors, avg_speed, rate_limit, rate_limit_set
-			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+				id, url, dest_path, filename, status, total_size, downloaded, completed_at, time_taken, url_hash, mirrors, avg_speed, rate_limit, rate_limit_set, alias, tags, verified, checksum, response_headers, redirects, method
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), ?, NULLIF(?, ''), ?, ?, NULLIF(?, ''))
 			ON CONFLICT(id) DO UPDATE SET
 				url=excluded.url,
 				dest_path=excluded.dest_path,
@@ -462,10 +571,17 @@ func AddToMasterList(entry types.DownloadEntry) error {
 				mirrors=excluded.mirrors,
 				avg_speed=excluded.avg_speed,
 				rate_limit=excluded.rate_limit,
-				rate_limit_set=excluded.rate_limit_set
+				rate_limit_set=excluded.rate_limit_set,
+				alias=COALESCE(excluded.alias, downloads.alias),
+				tags=COALESCE(excluded.tags, downloads.tags),
+				verified=excluded.verified,
+				checksum=COALESCE(excluded.checksum, downloads.checksum),
+				response_headers=COALESCE(excluded.response_headers, downloads.response_headers),
+				redirects=COALESCE(excluded.redirects, downloads.redirects),
+				method=COALESCE(excluded.method, downloads.method)
 		`,
 			entry.ID, entry.URL, entry.DestPath, entry.Filename, entry.Status, entry.TotalSize, entry.Downloaded,
-			entry.CompletedAt, entry.TimeTaken, entry.URLHash, strings.Join(entry.Mirrors, ","), entry.AvgSpeed, entry.RateLimit, entry.RateLimitSet)
+			entry.CompletedAt, entry.TimeTaken, entry.URLHash, strings.Join(entry.Mirrors, ","), entry.AvgSpeed, entry.RateLimit, entry.RateLimitSet, entry.Alias, types.JoinTags(entry.Tags), entry.Verified, entry.Checksum, encodeHeaders(entry.ResponseHeaders), encodeCopies(entry.Redirects), entry.Method)
 
 		return err
 	})
@@ -491,19 +607,19 @@ func GetDownload(id string) (*types.DownloadEntry, error) {
 
 	var e types.DownloadEntry
 	var completedAt, timeTaken sql.NullInt64
-	var urlHash, filename, mirrors sql.NullString
+	var urlHash, filename, mirrors, alias, tags, checksum, respHeaders, redirects, method sql.NullString
 	var avgSpeed sql.NullFloat64
 
-	var rateLimit, rateLimitSet sql.NullInt64
+	var rateLimit, rateLimitSet, verified sql.NullInt64
 	row := db.QueryRow(`
-		SELECT id, url, dest_path, filename, status, total_size, downloaded, completed_at, time_taken, url_hash, mirrors, avg_speed, rate_limit, rate_limit_set 
+		SELECT id, url, dest_path, filename, status, total_size, downloaded, completed_at, time_taken, url_hash, mirrors, avg_speed, rate_limit, rate_limit_set, alias, tags, verified, checksum, response_headers, redirects, method
 		FROM downloads
 		WHERE id = ?
 	`, id)
 
 	if err := row.Scan(
 		&e.ID, &e.URL, &e.DestPath, &filename, &e.Status, &e.TotalSize, &e.Downloaded,
-		&completedAt, &timeTaken, &urlHash, &mirrors, &avgSpeed, &rateLimit, &rateLimitSet,
+		&completedAt, &timeTaken, &urlHash, &mirrors, &avgSpeed, &rateLimit, &rateLimitSet, &alias, &tags, &verified, &checksum, &respHeaders, &redirects, &method,
 	); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Not found
@@ -535,10 +651,35 @@ func GetDownload(id string) (*types.DownloadEntry, error) {
 	if rateLimitSet.Valid {
 		e.RateLimitSet = rateLimitSet.Int64 != 0
 	}
+	e.Alias = alias.String
+	e.Tags = types.SplitTags(tags.String)
+	e.Verified = verified.Int64 != 0
+	e.Checksum = checksum.String
+	e.ResponseHeaders = decodeHeaders(respHeaders.String)
+	e.Redirects = decodeCopies(redirects.String)
+	e.Method = method.String
 
 	return &e, nil
 }
 
+// GetDownloadByAlias returns the download holding alias, or nil if none does.
+func GetDownloadByAlias(alias string) (*types.DownloadEntry, error) {
+	db := getDBHelper()
+	if db == nil || alias == "" {
+		return nil, nil
+	}
+
+	var id string
+	err := db.QueryRow("SELECT id FROM downloads WHERE alias = ?", alias).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alias: %w", err)
+	}
+	return GetDownload(id)
+}
+
 // LoadPausedDownloads returns all paused downloads
 func LoadPausedDownloads() ([]types.DownloadEntry, error) {
 	// Reuse LoadMasterList logic or optimize with WHERE
@@ -618,7 +759,8 @@ func UpdateURL(id string, newURL string) error {
 
 	newHash := URLHash(newURL)
 
-	result, err := db.Exec("UPDATE downloads SET url = ?, url_hash = ? WHERE id = ?", newURL, newHash, id)
+	// The old redirect target belongs to the old URL, so forget it.
+	result, err := db.Exec("UPDATE downloads SET url = ?, url_hash = ?, final_url = NULL WHERE id = ?", newURL, newHash, id)
 	if err != nil {
 		return fmt.Errorf("failed to update url: %w", err)
 	}
@@ -631,6 +773,96 @@ func UpdateURL(id string, newURL string) error {
 	return nil
 }
 
+// SetTags replaces the tags of a download; no tags clears them.
+func SetTags(id string, tags []string) error {
+	db := getDBHelper()
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	result, err := db.Exec("UPDATE downloads SET tags = NULLIF(?, '') WHERE id = ?", types.JoinTags(tags), id)
+	if err != nil {
+		return fmt.Errorf("failed to update tags: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("%w: %s", types.ErrNotFound, id)
+	}
+
+	return nil
+}
+
+// UpdateHeaders sets headers over the saved request headers of a download,
+// removing those given an empty value, and returns its URL.
+func UpdateHeaders(id string, headers map[string]string) (string, error) {
+	db := getDBHelper()
+	if db == nil {
+		return "", fmt.Errorf("database not initialized")
+	}
+
+	var rawurl string
+	var saved sql.NullString
+	err := db.QueryRow("SELECT url, headers FROM downloads WHERE id = ?", id).Scan(&rawurl, &saved)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("%w: %s", types.ErrNotFound, id)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read headers: %w", err)
+	}
+
+	merged := utils.MergeHeaders(decodeHeaders(saved.String), headers)
+	if _, err := db.Exec("UPDATE downloads SET headers = ? WHERE id = ?", encodeHeaders(merged), id); err != nil {
+		return "", fmt.Errorf("failed to update headers: %w", err)
+	}
+	return rawurl, nil
+}
+
+// GetHeaders returns the saved request headers of a download.
+func GetHeaders(id string) (map[string]string, error) {
+	db := getDBHelper()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	var saved sql.NullString
+	err := db.QueryRow("SELECT headers FROM downloads WHERE id = ?", id).Scan(&saved)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", types.ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read headers: %w", err)
+	}
+	return decodeHeaders(saved.String), nil
+}
+
+// UpdateCompletedFile records that the file of a completed download was
+// brought up to date: its new size and completion time, and the response
+// headers of the new version. The saved checksum was of the old contents, so
+// it is dropped along with the verified mark.
+func UpdateCompletedFile(id string, size, completedAt int64, responseHeaders map[string]string) error {
+	db := getDBHelper()
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	result, err := db.Exec(`
+		UPDATE downloads
+		SET total_size = ?, downloaded = ?, completed_at = ?, checksum = NULL, verified = 0,
+			response_headers = COALESCE(?, response_headers)
+		WHERE id = ?
+	`, size, size, completedAt, encodeHeaders(responseHeaders), id)
+	if err != nil {
+		return fmt.Errorf("failed to update download: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("%w: %s", types.ErrNotFound, id)
+	}
+	return nil
+}
+
 // PauseAllDownloads pauses all non-completed downloads
 func PauseAllDownloads() error {
 	db := getDBHelper()
@@ -700,7 +932,7 @@ func LoadStates(ids []string) (map[string]*types.DownloadState, error) {
 
 	// 1. Load Downloads
 	query := fmt.Sprintf(`
-		SELECT id, url, dest_path, filename, total_size, downloaded, url_hash, created_at, paused_at, time_taken, mirrors, chunk_bitmap, actual_chunk_size, rate_limit, rate_limit_set
+		SELECT id, url, dest_path, filename, total_size, downloaded, url_hash, created_at, paused_at, time_taken, mirrors, chunk_bitmap, actual_chunk_size, rate_limit, rate_limit_set, final_url, s3_part_size, s3_checksum, headers, checksum, copies, signature_url, fetched, bind_interface, tls
 		FROM downloads
 		WHERE id IN (%s) AND status != 'completed'
 	`, inClause)
@@ -720,14 +952,14 @@ func LoadStates(ids []string) (map[string]*types.DownloadState, error) {
 
 	for rows.Next() {
 		var state types.DownloadState
-		var timeTaken, createdAt, pausedAt, actualChunkSize, rateLimit, rateLimitSet sql.NullInt64
-		var mirrors sql.NullString
+		var timeTaken, createdAt, pausedAt, actualChunkSize, rateLimit, rateLimitSet, s3PartSize, fetched sql.NullInt64
+		var mirrors, finalURL, s3Checksum, headers, checksum, copies, signatureURL, bindInterface, tlsOpts sql.NullString
 		var chunkBitmap []byte
 
 		if err := rows.Scan(
 			&state.ID, &state.URL, &state.DestPath, &state.Filename,
 			&state.TotalSize, &state.Downloaded, &state.URLHash,
-			&createdAt, &pausedAt, &timeTaken, &mirrors, &chunkBitmap, &actualChunkSize, &rateLimit, &rateLimitSet,
+			&createdAt, &pausedAt, &timeTaken, &mirrors, &chunkBitmap, &actualChunkSize, &rateLimit, &rateLimitSet, &finalURL, &s3PartSize, &s3Checksum, &headers, &checksum, &copies, &signatureURL, &fetched, &bindInterface, &tlsOpts,
 		); err != nil {
 			return nil, err
 		}
@@ -754,6 +986,22 @@ func LoadStates(ids []string) (map[string]*types.DownloadState, error) {
 		if rateLimitSet.Valid {
 			state.RateLimitSet = rateLimitSet.Int64 != 0
 		}
+		if finalURL.Valid {
+			state.FinalURL = finalURL.String
+		}
+		if s3PartSize.Valid {
+			state.S3.PartSize = s3PartSize.Int64
+		}
+		if s3Checksum.Valid {
+			state.S3.Checksum = types.ParseS3Checksum(s3Checksum.String)
+		}
+		state.Headers = decodeHeaders(headers.String)
+		state.Checksum = checksum.String
+		state.Copies = decodeCopies(copies.String)
+		state.SignatureURL = signatureURL.String
+		state.Interface = bindInterface.String
+		state.TLS = decodeTLS(tlsOpts.String)
+		state.Fetched = fetched.Int64 != 0
 
 		states[state.ID] = &state
 	}
@@ -938,7 +1186,7 @@ func ValidateIntegrity() (int, error) {
 
 		// If we have a stored hash, verify it
 		if e.fileHash != "" {
-			matches, err := compareAgainstStoredFileHash(surgePath, e.fileHash)
+			matches, err := compareAgainstStoredFileHash(surgePath, e.fileHash, DefaultInlineHashTimeout)
 			if err != nil {
 				return removed, fmt.Errorf("failed to verify hash for %s: %w", surgePath, err)
 			}
@@ -2,8 +2,11 @@ package state
 
 import (
 	"database/sql"
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -1130,3 +1133,540 @@ func TestNormalizeStaleDownloads(t *testing.T) {
 		t.Errorf("ok-5 status = %q, want queued", dl5.Status)
 	}
 }
+
+func TestFinalURL_PersistsAndClearsOnURLUpdate(t *testing.T) {
+	tmpDir := setupTestDB(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	defer CloseDB()
+
+	testURL := "https://example.com/redirecting.zip"
+	finalURL := "https://cdn.example.com/signed/redirecting.zip"
+	testDestPath := filepath.Join(tmpDir, "redirecting.zip")
+
+	id := uuid.New().String()
+	if err := SaveState(testURL, testDestPath, &types.DownloadState{
+		ID:        id,
+		URL:       testURL,
+		DestPath:  testDestPath,
+		TotalSize: 1000,
+		Tasks:     []types.Task{{Offset: 0, Length: 1000}},
+		Filename:  "redirecting.zip",
+		FinalURL:  finalURL,
+	}); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	loaded, err := LoadState(testURL, testDestPath)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if loaded.FinalURL != finalURL {
+		t.Errorf("LoadState FinalURL = %q, want %q", loaded.FinalURL, finalURL)
+	}
+
+	batch, err := LoadStates([]string{id})
+	if err != nil {
+		t.Fatalf("LoadStates failed: %v", err)
+	}
+	if got := batch[id].FinalURL; got != finalURL {
+		t.Errorf("LoadStates FinalURL = %q, want %q", got, finalURL)
+	}
+
+	newURL := "https://example.com/refreshed.zip"
+	if err := UpdateURL(id, newURL); err != nil {
+		t.Fatalf("UpdateURL failed: %v", err)
+	}
+	batch, err = LoadStates([]string{id})
+	if err != nil {
+		t.Fatalf("LoadStates failed: %v", err)
+	}
+	if got := batch[id].FinalURL; got != "" {
+		t.Errorf("FinalURL after UpdateURL = %q, want it cleared", got)
+	}
+}
+
+func TestS3Object_PersistsAcrossLoads(t *testing.T) {
+	tmpDir := setupTestDB(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	defer CloseDB()
+
+	testURL := "https://bucket.s3.amazonaws.com/object.bin"
+	testDestPath := filepath.Join(tmpDir, "object.bin")
+	s3 := types.S3Object{
+		PartSize: 8 * types.MB,
+		Checksum: types.S3Checksum{Algorithm: "crc32c", Value: "yZRlqg==-3"},
+	}
+
+	id := uuid.New().String()
+	if err := SaveState(testURL, testDestPath, &types.DownloadState{
+		ID:        id,
+		URL:       testURL,
+		DestPath:  testDestPath,
+		TotalSize: 20 * types.MB,
+		Tasks:     []types.Task{{Offset: 8 * types.MB, Length: 12 * types.MB}},
+		Filename:  "object.bin",
+		S3:        s3,
+	}); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	loaded, err := LoadState(testURL, testDestPath)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if loaded.S3 != s3 {
+		t.Errorf("LoadState S3 = %+v, want %+v", loaded.S3, s3)
+	}
+
+	batch, err := LoadStates([]string{id})
+	if err != nil {
+		t.Fatalf("LoadStates failed: %v", err)
+	}
+	if got := batch[id].S3; got != s3 {
+		t.Errorf("LoadStates S3 = %+v, want %+v", got, s3)
+	}
+}
+
+func TestHeadersAndChecksum_PersistAcrossLoads(t *testing.T) {
+	tmpDir := setupTestDB(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	defer CloseDB()
+
+	testURL := "https://huggingface.co/acme/model/resolve/abc123/model.safetensors"
+	testDestPath := filepath.Join(tmpDir, "model.safetensors")
+	headers := map[string]string{"Authorization": "Bearer hf_token"}
+	checksum := "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+	id := uuid.New().String()
+	if err := SaveState(testURL, testDestPath, &types.DownloadState{
+		ID:        id,
+		URL:       testURL,
+		DestPath:  testDestPath,
+		TotalSize: 10 * types.MB,
+		Tasks:     []types.Task{{Offset: types.MB, Length: 9 * types.MB}},
+		Filename:  "model.safetensors",
+		Headers:   headers,
+		Checksum:  checksum,
+	}); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	loaded, err := LoadState(testURL, testDestPath)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if loaded.Headers["Authorization"] != "Bearer hf_token" || loaded.Checksum != checksum {
+		t.Errorf("LoadState headers = %v, checksum = %q", loaded.Headers, loaded.Checksum)
+	}
+
+	batch, err := LoadStates([]string{id})
+	if err != nil {
+		t.Fatalf("LoadStates failed: %v", err)
+	}
+	if got := batch[id]; got.Headers["Authorization"] != "Bearer hf_token" || got.Checksum != checksum {
+		t.Errorf("LoadStates headers = %v, checksum = %q", got.Headers, got.Checksum)
+	}
+}
+
+func TestAlias_MovesToNewestClaim(t *testing.T) {
+	tmpDir := setupTestDB(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	defer CloseDB()
+
+	first := types.DownloadEntry{ID: uuid.New().String(), URL: "https://example.com/a.iso", Filename: "a.iso", Status: "completed", Alias: "nightly-build"}
+	if err := AddToMasterList(first); err != nil {
+		t.Fatalf("AddToMasterList failed: %v", err)
+	}
+
+	// Updates that do not mention the alias keep it.
+	first.Alias = ""
+	if err := AddToMasterList(first); err != nil {
+		t.Fatalf("AddToMasterList failed: %v", err)
+	}
+	got, err := GetDownloadByAlias("nightly-build")
+	if err != nil || got == nil || got.ID != first.ID {
+		t.Fatalf("GetDownloadByAlias = %+v, %v; want %s", got, err, first.ID)
+	}
+
+	second := types.DownloadEntry{ID: uuid.New().String(), URL: "https://example.com/b.iso", Filename: "b.iso", Status: "queued", Alias: "nightly-build"}
+	if err := AddToMasterList(second); err != nil {
+		t.Fatalf("AddToMasterList failed: %v", err)
+	}
+	got, err = GetDownloadByAlias("nightly-build")
+	if err != nil || got == nil || got.ID != second.ID {
+		t.Fatalf("GetDownloadByAlias = %+v, %v; want %s", got, err, second.ID)
+	}
+	old, err := GetDownload(first.ID)
+	if err != nil {
+		t.Fatalf("GetDownload failed: %v", err)
+	}
+	if old.Alias != "" {
+		t.Errorf("old download kept alias %q", old.Alias)
+	}
+}
+
+func TestTags_SurviveUpdatesUntilSet(t *testing.T) {
+	tmpDir := setupTestDB(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	defer CloseDB()
+
+	entry := types.DownloadEntry{ID: uuid.New().String(), URL: "https://example.com/a.iso", Filename: "a.iso", Status: "queued", Tags: []string{"iso", "work"}}
+	if err := AddToMasterList(entry); err != nil {
+		t.Fatalf("AddToMasterList failed: %v", err)
+	}
+
+	// Status updates do not carry tags and must not drop them.
+	entry.Tags = nil
+	entry.Status = "completed"
+	if err := AddToMasterList(entry); err != nil {
+		t.Fatalf("AddToMasterList failed: %v", err)
+	}
+	got, err := GetDownload(entry.ID)
+	if err != nil {
+		t.Fatalf("GetDownload failed: %v", err)
+	}
+	if strings.Join(got.Tags, ",") != "iso,work" {
+		t.Fatalf("tags after update = %v, want [iso work]", got.Tags)
+	}
+
+	if err := SetTags(entry.ID, nil); err != nil {
+		t.Fatalf("SetTags failed: %v", err)
+	}
+	list, err := ListAllDownloads()
+	if err != nil {
+		t.Fatalf("ListAllDownloads failed: %v", err)
+	}
+	if len(list) != 1 || len(list[0].Tags) != 0 {
+		t.Fatalf("tags after clearing = %+v", list)
+	}
+
+	if err := SetTags("missing", []string{"x"}); !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("SetTags on a missing download = %v, want ErrNotFound", err)
+	}
+}
+
+func TestUpdateHeaders_MergesSavedHeaders(t *testing.T) {
+	tmpDir := setupTestDB(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	defer CloseDB()
+
+	entry := types.DownloadEntry{ID: uuid.New().String(), URL: "https://example.com/a.iso", Filename: "a.iso", Status: "paused"}
+	if err := AddToMasterList(entry); err != nil {
+		t.Fatalf("AddToMasterList failed: %v", err)
+	}
+
+	if _, err := UpdateHeaders(entry.ID, map[string]string{"Cookie": "old", "Referer": "https://example.com"}); err != nil {
+		t.Fatalf("UpdateHeaders failed: %v", err)
+	}
+	rawurl, err := UpdateHeaders(entry.ID, map[string]string{"cookie": "new", "Referer": ""})
+	if err != nil {
+		t.Fatalf("UpdateHeaders failed: %v", err)
+	}
+	if rawurl != entry.URL {
+		t.Errorf("url = %q, want %q", rawurl, entry.URL)
+	}
+
+	var saved string
+	if err := getDBHelper().QueryRow("SELECT headers FROM downloads WHERE id = ?", entry.ID).Scan(&saved); err != nil {
+		t.Fatalf("reading headers: %v", err)
+	}
+	if got := decodeHeaders(saved); len(got) != 1 || got["Cookie"] != "new" {
+		t.Fatalf("headers = %v, want only Cookie: new", got)
+	}
+
+	if _, err := UpdateHeaders("missing", map[string]string{"Cookie": "x"}); !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("UpdateHeaders on a missing download = %v, want ErrNotFound", err)
+	}
+}
+
+func TestUpdateCompletedFile_DropsTheOldChecksum(t *testing.T) {
+	tmpDir := setupTestDB(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	defer CloseDB()
+
+	entry := types.DownloadEntry{
+		ID: uuid.New().String(), URL: "https://example.com/a.log", Filename: "a.log", Status: "completed",
+		TotalSize: 100, Downloaded: 100, Checksum: "sha256:abc", Verified: true,
+		ResponseHeaders: map[string]string{"ETag": `"v1"`},
+	}
+	if err := AddToMasterList(entry); err != nil {
+		t.Fatalf("AddToMasterList failed: %v", err)
+	}
+
+	if err := UpdateCompletedFile(entry.ID, 150, 42, map[string]string{"ETag": `"v2"`}); err != nil {
+		t.Fatalf("UpdateCompletedFile failed: %v", err)
+	}
+	got, err := GetDownload(entry.ID)
+	if err != nil {
+		t.Fatalf("GetDownload failed: %v", err)
+	}
+	if got.TotalSize != 150 || got.Downloaded != 150 || got.CompletedAt != 42 {
+		t.Errorf("size %d/%d completed %d, want 150/150 completed 42", got.Downloaded, got.TotalSize, got.CompletedAt)
+	}
+	if got.Checksum != "" || got.Verified {
+		t.Errorf("checksum %q verified %v, want both cleared", got.Checksum, got.Verified)
+	}
+	if got.ResponseHeaders["ETag"] != `"v2"` {
+		t.Errorf("response headers = %v, want the new ETag", got.ResponseHeaders)
+	}
+
+	if err := UpdateCompletedFile("missing", 1, 1, nil); !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("UpdateCompletedFile on a missing download = %v, want ErrNotFound", err)
+	}
+}
+
+func TestCopies_PersistAcrossLoads(t *testing.T) {
+	tmpDir := setupTestDB(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	defer CloseDB()
+
+	testURL := "https://example.com/distro.iso"
+	testDestPath := filepath.Join(tmpDir, "distro.iso")
+	copies := []string{filepath.Join(tmpDir, "nas"), filepath.Join(tmpDir, "backup, old")}
+
+	id := uuid.New().String()
+	if err := SaveState(testURL, testDestPath, &types.DownloadState{
+		ID:        id,
+		URL:       testURL,
+		DestPath:  testDestPath,
+		TotalSize: 10 * types.MB,
+		Tasks:     []types.Task{{Offset: types.MB, Length: 9 * types.MB}},
+		Filename:  "distro.iso",
+		Copies:    copies,
+	}); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	loaded, err := LoadState(testURL, testDestPath)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Copies, copies) {
+		t.Errorf("LoadState copies = %q, want %q", loaded.Copies, copies)
+	}
+
+	batch, err := LoadStates([]string{id})
+	if err != nil {
+		t.Fatalf("LoadStates failed: %v", err)
+	}
+	if got := batch[id].Copies; !reflect.DeepEqual(got, copies) {
+		t.Errorf("LoadStates copies = %q, want %q", got, copies)
+	}
+}
+
+func TestSignature_PersistsURLAndVerified(t *testing.T) {
+	tmpDir := setupTestDB(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	defer CloseDB()
+
+	testURL := "https://example.com/release.tar.gz"
+	testDestPath := filepath.Join(tmpDir, "release.tar.gz")
+	sigURL := testURL + ".minisig"
+
+	id := uuid.New().String()
+	if err := SaveState(testURL, testDestPath, &types.DownloadState{
+		ID:           id,
+		URL:          testURL,
+		DestPath:     testDestPath,
+		TotalSize:    10 * types.MB,
+		Tasks:        []types.Task{{Offset: types.MB, Length: 9 * types.MB}},
+		Filename:     "release.tar.gz",
+		SignatureURL: sigURL,
+	}); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	loaded, err := LoadState(testURL, testDestPath)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if loaded.SignatureURL != sigURL {
+		t.Errorf("LoadState signature URL = %q, want %q", loaded.SignatureURL, sigURL)
+	}
+	batch, err := LoadStates([]string{id})
+	if err != nil {
+		t.Fatalf("LoadStates failed: %v", err)
+	}
+	if got := batch[id].SignatureURL; got != sigURL {
+		t.Errorf("LoadStates signature URL = %q, want %q", got, sigURL)
+	}
+
+	if err := AddToMasterList(types.DownloadEntry{
+		ID:       id,
+		URL:      testURL,
+		DestPath: testDestPath,
+		Status:   "completed",
+		Verified: true,
+	}); err != nil {
+		t.Fatalf("AddToMasterList failed: %v", err)
+	}
+	entry, err := GetDownload(id)
+	if err != nil || entry == nil {
+		t.Fatalf("GetDownload = %v, %v", entry, err)
+	}
+	if !entry.Verified {
+		t.Error("GetDownload lost verified")
+	}
+	list, err := LoadMasterList()
+	if err != nil {
+		t.Fatalf("LoadMasterList failed: %v", err)
+	}
+	if len(list.Downloads) != 1 || !list.Downloads[0].Verified {
+		t.Errorf("LoadMasterList entries = %+v, want one verified", list.Downloads)
+	}
+}
+
+func TestFetched_Persists(t *testing.T) {
+	tmpDir := setupTestDB(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	defer CloseDB()
+
+	testURL := "https://example.com/disk.img"
+	testDestPath := filepath.Join(tmpDir, "disk.img")
+
+	id := uuid.New().String()
+	if err := SaveState(testURL, testDestPath, &types.DownloadState{
+		ID:         id,
+		URL:        testURL,
+		DestPath:   testDestPath,
+		TotalSize:  10 * types.MB,
+		Downloaded: 10 * types.MB,
+		Filename:   "disk.img",
+		Checksum:   "sha256:" + strings.Repeat("0", 64),
+		Fetched:    true,
+	}); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	loaded, err := LoadState(testURL, testDestPath)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if !loaded.Fetched {
+		t.Error("LoadState lost fetched")
+	}
+	batch, err := LoadStates([]string{id})
+	if err != nil {
+		t.Fatalf("LoadStates failed: %v", err)
+	}
+	if !batch[id].Fetched {
+		t.Error("LoadStates lost fetched")
+	}
+}
+
+func TestResponseHeaders_SurviveUpdates(t *testing.T) {
+	tmpDir := setupTestDB(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	defer CloseDB()
+
+	entry := types.DownloadEntry{
+		ID:              uuid.New().String(),
+		URL:             "https://example.com/a.iso",
+		Filename:        "a.iso",
+		Status:          "queued",
+		ResponseHeaders: map[string]string{"Server": "nginx", "Accept-Ranges": "bytes"},
+		Redirects:       []string{"https://mirror.example.com/a.iso", "https://cdn.example.com/a.iso"},
+	}
+	if err := AddToMasterList(entry); err != nil {
+		t.Fatalf("AddToMasterList failed: %v", err)
+	}
+
+	// Later status updates carry neither headers nor, until completion, a checksum
+	entry.ResponseHeaders = nil
+	entry.Redirects = nil
+	entry.Status = "completed"
+	entry.Checksum = "sha256:" + strings.Repeat("ab", 32)
+	if err := AddToMasterList(entry); err != nil {
+		t.Fatalf("AddToMasterList failed: %v", err)
+	}
+	got, err := GetDownload(entry.ID)
+	if err != nil {
+		t.Fatalf("GetDownload failed: %v", err)
+	}
+	want := map[string]string{"Server": "nginx", "Accept-Ranges": "bytes"}
+	if !reflect.DeepEqual(got.ResponseHeaders, want) {
+		t.Errorf("ResponseHeaders = %v, want %v", got.ResponseHeaders, want)
+	}
+	if got.Checksum != entry.Checksum {
+		t.Errorf("Checksum = %q, want %q", got.Checksum, entry.Checksum)
+	}
+	wantRedirects := []string{"https://mirror.example.com/a.iso", "https://cdn.example.com/a.iso"}
+	if !reflect.DeepEqual(got.Redirects, wantRedirects) {
+		t.Errorf("Redirects = %v, want %v", got.Redirects, wantRedirects)
+	}
+}
+
+func TestBindInterface_PersistsAcrossRestart(t *testing.T) {
+	tmpDir := setupTestDB(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	defer CloseDB()
+
+	testURL := "https://example.com/image.iso"
+	testDestPath := filepath.Join(tmpDir, "image.iso")
+
+	id := uuid.New().String()
+	if err := SaveState(testURL, testDestPath, &types.DownloadState{
+		ID:        id,
+		URL:       testURL,
+		DestPath:  testDestPath,
+		TotalSize: 10 * types.MB,
+		Tasks:     []types.Task{{Offset: types.MB, Length: 9 * types.MB}},
+		Filename:  "image.iso",
+		Interface: "tun0",
+	}); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	loaded, err := LoadState(testURL, testDestPath)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if loaded.Interface != "tun0" {
+		t.Errorf("LoadState interface = %q, want tun0", loaded.Interface)
+	}
+	batch, err := LoadStates([]string{id})
+	if err != nil {
+		t.Fatalf("LoadStates failed: %v", err)
+	}
+	if got := batch[id].Interface; got != "tun0" {
+		t.Errorf("LoadStates interface = %q, want tun0", got)
+	}
+}
+
+func TestTLSOptions_PersistAcrossRestart(t *testing.T) {
+	tmpDir := setupTestDB(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	defer CloseDB()
+
+	testURL := "https://internal.example.com/build.tar"
+	testDestPath := filepath.Join(tmpDir, "build.tar")
+	want := types.TLSOptions{CAFile: "/etc/corp/ca.pem", ClientCertFile: "/etc/corp/me.crt", ClientKeyFile: "/etc/corp/me.key", Insecure: true}
+
+	id := uuid.New().String()
+	if err := SaveState(testURL, testDestPath, &types.DownloadState{
+		ID:        id,
+		URL:       testURL,
+		DestPath:  testDestPath,
+		TotalSize: 10 * types.MB,
+		Tasks:     []types.Task{{Offset: types.MB, Length: 9 * types.MB}},
+		Filename:  "build.tar",
+		TLS:       want,
+	}); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	loaded, err := LoadState(testURL, testDestPath)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if loaded.TLS != want {
+		t.Errorf("LoadState TLS = %+v, want %+v", loaded.TLS, want)
+	}
+	batch, err := LoadStates([]string{id})
+	if err != nil {
+		t.Fatalf("LoadStates failed: %v", err)
+	}
+	if got := batch[id].TLS; got != want {
+		t.Errorf("LoadStates TLS = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,78 @@
+package state
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// MonthTraffic is how many bytes were downloaded in one calendar month.
+type MonthTraffic struct {
+	Month string `json:"month"` // as 2006-01
+	Bytes int64  `json:"bytes"`
+}
+
+// TrafficMonth returns the key traffic is counted under for the month of t,
+// in local time.
+func TrafficMonth(t time.Time) string {
+	return t.Local().Format("2006-01")
+}
+
+// AddTraffic adds n downloaded bytes to month and returns its new total.
+func AddTraffic(month string, n int64) (int64, error) {
+	db := getDBHelper()
+	if db == nil {
+		return 0, fmt.Errorf("database not initialized")
+	}
+
+	var total int64
+	err := db.QueryRow(`INSERT INTO traffic (month, bytes) VALUES (?, ?)
+		ON CONFLICT(month) DO UPDATE SET bytes = bytes + excluded.bytes
+		RETURNING bytes`, month, n).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add traffic: %w", err)
+	}
+	return total, nil
+}
+
+// GetTraffic returns the bytes downloaded in month.
+func GetTraffic(month string) (int64, error) {
+	db := getDBHelper()
+	if db == nil {
+		return 0, nil
+	}
+
+	var total int64
+	err := db.QueryRow("SELECT bytes FROM traffic WHERE month = ?", month).Scan(&total)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to query traffic: %w", err)
+	}
+	return total, nil
+}
+
+// TrafficHistory returns the bytes downloaded in each month, newest first.
+func TrafficHistory() ([]MonthTraffic, error) {
+	db := getDBHelper()
+	if db == nil {
+		return nil, nil
+	}
+
+	rows, err := db.Query("SELECT month, bytes FROM traffic ORDER BY month DESC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query traffic: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var months []MonthTraffic
+	for rows.Next() {
+		var m MonthTraffic
+		if err := rows.Scan(&m.Month, &m.Bytes); err != nil {
+			return nil, err
+		}
+		months = append(months, m)
+	}
+	return months, rows.Err()
+}
@@ -0,0 +1,32 @@
+package state
+
+import "testing"
+
+func TestTraffic_AddsUpPerMonth(t *testing.T) {
+	setupTestDB(t)
+
+	if total, err := AddTraffic("2026-09", 100); err != nil || total != 100 {
+		t.Fatalf("AddTraffic = %d, %v; want 100", total, err)
+	}
+	if total, err := AddTraffic("2026-09", 50); err != nil || total != 150 {
+		t.Fatalf("AddTraffic = %d, %v; want 150", total, err)
+	}
+	if _, err := AddTraffic("2026-10", 7); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := GetTraffic("2026-09"); err != nil || got != 150 {
+		t.Fatalf("GetTraffic = %d, %v; want 150", got, err)
+	}
+	if got, err := GetTraffic("2026-08"); err != nil || got != 0 {
+		t.Fatalf("GetTraffic for an unseen month = %d, %v; want 0", got, err)
+	}
+
+	months, err := TrafficHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(months) != 2 || months[0] != (MonthTraffic{"2026-10", 7}) || months[1] != (MonthTraffic{"2026-09", 150}) {
+		t.Fatalf("TrafficHistory = %v, want October then September", months)
+	}
+}
@@ -0,0 +1,236 @@
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+// verifyHashTimeout bounds rehashing a partial file during CheckPartial. It
+// is far longer than the pause-time bound: the user asked for the check.
+const verifyHashTimeout = time.Hour
+
+// zeroScanBlock is the size of the blocks CheckPartial reads back looking
+// for finished data that was never written.
+const zeroScanBlock = types.MB
+
+// Reasons a finished range of a partial download is suspect.
+const (
+	SuspectMissing  = "missing from the file"
+	SuspectChanged  = "file changed since it was paused"
+	SuspectUnmarked = "chunk map marks it unfinished"
+	SuspectZeros    = "reads back as zeros"
+)
+
+// SuspectRange is a range a partial download counts as finished whose data
+// cannot be trusted.
+type SuspectRange struct {
+	types.Task
+	Reason string
+}
+
+// PartialCheck is what CheckPartial found in a partial download.
+type PartialCheck struct {
+	State *types.DownloadState
+	// Finished is how many bytes the saved tasks leave as downloaded.
+	Finished int64
+	Suspect  []SuspectRange
+}
+
+// CheckPartial re-validates the .surge file of the unfinished download of url
+// to destPath against its saved state: the file must hold every finished
+// range, match the hash taken when it was paused, agree with the chunk map,
+// and not read back as zeros where data was written.
+func CheckPartial(url, destPath string) (*PartialCheck, error) {
+	st, err := LoadState(url, destPath)
+	if err != nil {
+		return nil, err
+	}
+	check := &PartialCheck{State: st}
+	finished := finishedRanges(st.TotalSize, st.Tasks)
+	for _, r := range finished {
+		check.Finished += r.Length
+	}
+	if len(finished) == 0 {
+		return check, nil
+	}
+
+	path := destPath + types.IncompleteSuffix
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		check.suspect(finished, SuspectMissing)
+		return check, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if st.FileHash != "" {
+		matches, err := compareAgainstStoredFileHash(path, st.FileHash, verifyHashTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash %s: %w", path, err)
+		}
+		if !matches {
+			check.suspect(finished, SuspectChanged)
+			return check, nil
+		}
+	}
+
+	var present []types.Task
+	for _, r := range finished {
+		if end := r.Offset + r.Length; end > info.Size() {
+			if r.Offset < info.Size() {
+				present = append(present, types.Task{Offset: r.Offset, Length: info.Size() - r.Offset})
+				r = types.Task{Offset: info.Size(), Length: end - info.Size()}
+			}
+			check.suspect([]types.Task{r}, SuspectMissing)
+			continue
+		}
+		present = append(present, r)
+	}
+
+	check.suspect(unmarkedRanges(st, present), SuspectUnmarked)
+
+	zeros, err := zeroRanges(path, present)
+	if err != nil {
+		return nil, err
+	}
+	check.suspect(zeros, SuspectZeros)
+
+	sort.Slice(check.Suspect, func(i, j int) bool { return check.Suspect[i].Offset < check.Suspect[j].Offset })
+	return check, nil
+}
+
+func (c *PartialCheck) suspect(ranges []types.Task, reason string) {
+	for _, r := range ranges {
+		c.Suspect = append(c.Suspect, SuspectRange{Task: r, Reason: reason})
+	}
+}
+
+// RequeueRanges adds ranges back to the tasks of st and saves it, so the next
+// resume downloads them again.
+func RequeueRanges(st *types.DownloadState, ranges []types.Task) error {
+	tasks := append(append([]types.Task(nil), st.Tasks...), ranges...)
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Offset < tasks[j].Offset })
+	merged := tasks[:0]
+	for _, t := range tasks {
+		if t.Length <= 0 {
+			continue
+		}
+		if n := len(merged); n > 0 && t.Offset < merged[n-1].Offset+merged[n-1].Length {
+			// Overlaps a range already queued: keep only what it adds
+			last := &merged[n-1]
+			if end := t.Offset + t.Length; end > last.Offset+last.Length {
+				last.Length = end - last.Offset
+			}
+			continue
+		}
+		merged = append(merged, t)
+	}
+	st.Tasks = merged
+
+	var remaining int64
+	for _, t := range st.Tasks {
+		remaining += t.Length
+	}
+	st.Downloaded = max(st.TotalSize-remaining, 0)
+
+	if len(st.ChunkBitmap) > 0 && st.ActualChunkSize > 0 {
+		ps := types.NewProgressState(st.ID, st.TotalSize)
+		ps.RestoreBitmap(st.ChunkBitmap, st.ActualChunkSize)
+		ps.RecalculateProgress(st.Tasks)
+		st.ChunkBitmap, _, _, _, _ = ps.GetBitmapSnapshot(false)
+	}
+	return SaveState(st.URL, st.DestPath, st)
+}
+
+// finishedRanges returns the parts of [0, totalSize) no task covers.
+func finishedRanges(totalSize int64, tasks []types.Task) []types.Task {
+	sorted := append([]types.Task(nil), tasks...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Offset < sorted[j].Offset })
+	var finished []types.Task
+	var pos int64
+	for _, t := range sorted {
+		if t.Offset > pos {
+			finished = append(finished, types.Task{Offset: pos, Length: min(t.Offset, totalSize) - pos})
+		}
+		pos = max(pos, t.Offset+t.Length)
+		if pos >= totalSize {
+			return finished
+		}
+	}
+	if pos < totalSize {
+		finished = append(finished, types.Task{Offset: pos, Length: totalSize - pos})
+	}
+	return finished
+}
+
+// unmarkedRanges returns the chunks lying wholly inside a finished range that
+// the chunk map of st still marks pending.
+func unmarkedRanges(st *types.DownloadState, finished []types.Task) []types.Task {
+	if len(st.ChunkBitmap) == 0 || st.ActualChunkSize <= 0 {
+		return nil
+	}
+	ps := types.NewProgressState(st.ID, st.TotalSize)
+	ps.RestoreBitmap(st.ChunkBitmap, st.ActualChunkSize)
+
+	var unmarked []types.Task
+	for _, r := range finished {
+		first := (r.Offset + st.ActualChunkSize - 1) / st.ActualChunkSize
+		for i := first; ; i++ {
+			start := i * st.ActualChunkSize
+			end := min(start+st.ActualChunkSize, st.TotalSize)
+			if start >= end || end > r.Offset+r.Length {
+				break
+			}
+			if ps.GetChunkState(int(i)) == types.ChunkPending {
+				unmarked = appendRange(unmarked, types.Task{Offset: start, Length: end - start})
+			}
+		}
+	}
+	return unmarked
+}
+
+// zeroRanges reads back the finished ranges of the file at path and returns
+// the blocks holding nothing but zeros.
+func zeroRanges(path string, finished []types.Task) ([]types.Task, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	buf := make([]byte, zeroScanBlock)
+	var zeros []types.Task
+	for _, r := range finished {
+		for off := r.Offset; off < r.Offset+r.Length; off += zeroScanBlock {
+			n := min(zeroScanBlock, r.Offset+r.Length-off)
+			if _, err := f.ReadAt(buf[:n], off); err != nil && err != io.EOF {
+				return nil, fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			if isZero(buf[:n]) {
+				zeros = appendRange(zeros, types.Task{Offset: off, Length: n})
+			}
+		}
+	}
+	return zeros, nil
+}
+
+func isZero(b []byte) bool {
+	return len(b) > 0 && b[0] == 0 && bytes.Equal(b[1:], b[:len(b)-1])
+}
+
+// appendRange appends r to ranges, joining it to the last range when they
+// touch.
+func appendRange(ranges []types.Task, r types.Task) []types.Task {
+	if n := len(ranges); n > 0 && ranges[n-1].Offset+ranges[n-1].Length == r.Offset {
+		ranges[n-1].Length += r.Length
+		return ranges
+	}
+	return append(ranges, r)
+}
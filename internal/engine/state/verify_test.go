@@ -0,0 +1,150 @@
+package state
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func TestCheckPartial_FindsAndRequeuesSuspectRanges(t *testing.T) {
+	tmpDir := setupTestDB(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	defer CloseDB()
+
+	const chunk = types.MB
+	testURL := "https://test.example.com/verify.bin"
+	destPath := filepath.Join(tmpDir, "verify.bin")
+	surgePath := destPath + types.IncompleteSuffix
+
+	// Three of four chunks finished, the second never actually written
+	content := bytes.Repeat([]byte{0xAB}, 4*chunk)
+	clear(content[chunk : 2*chunk])
+	clear(content[3*chunk:])
+	if err := os.WriteFile(surgePath, content, 0o644); err != nil {
+		t.Fatalf("failed to write .surge file: %v", err)
+	}
+
+	ps := types.NewProgressState("verify-id", 4*chunk)
+	ps.InitBitmap(4*chunk, chunk)
+	ps.UpdateChunkStatus(0, 3*chunk, types.ChunkCompleted)
+	bitmap, _, _, _, _ := ps.GetBitmapSnapshot(false)
+
+	st := &types.DownloadState{
+		ID:              "verify-id",
+		URL:             testURL,
+		DestPath:        destPath,
+		Filename:        "verify.bin",
+		TotalSize:       4 * chunk,
+		Downloaded:      3 * chunk,
+		Tasks:           []types.Task{{Offset: 3 * chunk, Length: chunk}},
+		ChunkBitmap:     bitmap,
+		ActualChunkSize: chunk,
+	}
+	if err := SaveState(testURL, destPath, st); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	check, err := CheckPartial(testURL, destPath)
+	if err != nil {
+		t.Fatalf("CheckPartial failed: %v", err)
+	}
+	if check.Finished != 3*chunk {
+		t.Errorf("Finished = %d, want %d", check.Finished, 3*chunk)
+	}
+	want := []SuspectRange{{Task: types.Task{Offset: chunk, Length: chunk}, Reason: SuspectZeros}}
+	if !reflect.DeepEqual(check.Suspect, want) {
+		t.Fatalf("Suspect = %+v, want %+v", check.Suspect, want)
+	}
+
+	if err := RequeueRanges(check.State, []types.Task{check.Suspect[0].Task}); err != nil {
+		t.Fatalf("RequeueRanges failed: %v", err)
+	}
+	loaded, err := LoadState(testURL, destPath)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	wantTasks := []types.Task{{Offset: chunk, Length: chunk}, {Offset: 3 * chunk, Length: chunk}}
+	if !reflect.DeepEqual(loaded.Tasks, wantTasks) {
+		t.Errorf("Tasks = %+v, want %+v", loaded.Tasks, wantTasks)
+	}
+	if loaded.Downloaded != 2*chunk {
+		t.Errorf("Downloaded = %d, want %d", loaded.Downloaded, 2*chunk)
+	}
+	restored := types.NewProgressState("verify-id", 4*chunk)
+	restored.RestoreBitmap(loaded.ChunkBitmap, chunk)
+	if got := restored.GetChunkState(1); got != types.ChunkPending {
+		t.Errorf("chunk 1 state = %v, want pending", got)
+	}
+
+	check, err = CheckPartial(testURL, destPath)
+	if err != nil {
+		t.Fatalf("CheckPartial after repair failed: %v", err)
+	}
+	if len(check.Suspect) != 0 {
+		t.Errorf("Suspect after repair = %+v, want none", check.Suspect)
+	}
+
+	// A file written to since the pause can no longer be trusted at all
+	content[0] = 0xCD
+	if err := os.WriteFile(surgePath, content, 0o644); err != nil {
+		t.Fatalf("failed to rewrite .surge file: %v", err)
+	}
+	check, err = CheckPartial(testURL, destPath)
+	if err != nil {
+		t.Fatalf("CheckPartial after change failed: %v", err)
+	}
+	want = []SuspectRange{
+		{Task: types.Task{Offset: 0, Length: chunk}, Reason: SuspectChanged},
+		{Task: types.Task{Offset: 2 * chunk, Length: chunk}, Reason: SuspectChanged},
+	}
+	if !reflect.DeepEqual(check.Suspect, want) {
+		t.Errorf("Suspect after change = %+v, want %+v", check.Suspect, want)
+	}
+
+	if err := os.Remove(surgePath); err != nil {
+		t.Fatalf("failed to remove .surge file: %v", err)
+	}
+	check, err = CheckPartial(testURL, destPath)
+	if err != nil {
+		t.Fatalf("CheckPartial without file failed: %v", err)
+	}
+	if len(check.Suspect) != 2 || check.Suspect[0].Reason != SuspectMissing {
+		t.Errorf("Suspect without file = %+v, want both finished ranges missing", check.Suspect)
+	}
+}
+
+func TestCheckPartial_TruncatedFile(t *testing.T) {
+	tmpDir := setupTestDB(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	defer CloseDB()
+
+	testURL := "https://test.example.com/short.bin"
+	destPath := filepath.Join(tmpDir, "short.bin")
+	if err := os.WriteFile(destPath+types.IncompleteSuffix, bytes.Repeat([]byte{1}, 600), 0o644); err != nil {
+		t.Fatalf("failed to write .surge file: %v", err)
+	}
+	st := &types.DownloadState{
+		ID:         "short-id",
+		URL:        testURL,
+		DestPath:   destPath,
+		TotalSize:  2000,
+		Downloaded: 1000,
+		Tasks:      []types.Task{{Offset: 1000, Length: 1000}},
+	}
+	if err := SaveStateWithOptions(testURL, destPath, st, SaveStateOptions{SkipFileHash: true}); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	check, err := CheckPartial(testURL, destPath)
+	if err != nil {
+		t.Fatalf("CheckPartial failed: %v", err)
+	}
+	want := []SuspectRange{{Task: types.Task{Offset: 600, Length: 400}, Reason: SuspectMissing}}
+	if !reflect.DeepEqual(check.Suspect, want) {
+		t.Errorf("Suspect = %+v, want %+v", check.Suspect, want)
+	}
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+// File writes to a local path given as file:///path. It goes through a
+// working file beside the destination, like a queued download, so a partial
+// file never takes the destination's name.
+type File struct{}
+
+func (File) Create(_ context.Context, dest *url.URL, _ *http.Client) (Object, error) {
+	if dest.Host != "" && dest.Host != "localhost" {
+		return nil, fmt.Errorf("file destination %q is on another host", dest.String())
+	}
+	path := filepath.FromSlash(dest.Path)
+	if path == "" || !filepath.IsAbs(path) {
+		return nil, fmt.Errorf("file destination %q needs an absolute path", dest.String())
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("%w: %s", os.ErrExist, path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	f, err := os.OpenFile(path+types.IncompleteSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &fileObject{File: f, path: path}, nil
+}
+
+type fileObject struct {
+	*os.File
+	path string
+}
+
+func (f *fileObject) Commit() error {
+	if err := f.Sync(); err != nil {
+		_ = f.Abort()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), f.path)
+}
+
+func (f *fileObject) Abort() error {
+	_ = f.Close()
+	return os.Remove(f.Name())
+}
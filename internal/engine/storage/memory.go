@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// DefaultMemory is the backend of mem:// destinations.
+var DefaultMemory = NewMemory()
+
+// Memory keeps objects in memory, named by the host and path of mem://
+// destinations, for programs that process a download without storing it
+// and for tests.
+type Memory struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+// NewMemory returns an empty Memory.
+func NewMemory() *Memory {
+	return &Memory{objects: make(map[string][]byte)}
+}
+
+// Get returns the committed object called name.
+func (m *Memory) Get(name string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[name]
+	return data, ok
+}
+
+// Delete drops the object called name.
+func (m *Memory) Delete(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, name)
+}
+
+func (m *Memory) Create(_ context.Context, dest *url.URL, _ *http.Client) (Object, error) {
+	return &memoryObject{store: m, name: dest.Host + dest.Path}, nil
+}
+
+type memoryObject struct {
+	bytes.Buffer
+	store *Memory
+	name  string
+}
+
+func (o *memoryObject) Commit() error {
+	o.store.mu.Lock()
+	defer o.store.mu.Unlock()
+	o.store.objects[o.name] = o.Bytes()
+	return nil
+}
+
+func (o *memoryObject) Abort() error {
+	o.Reset()
+	return nil
+}
@@ -0,0 +1,304 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+// S3PartSize is how much of an object is held in memory and uploaded as one
+// part. S3 needs every part but the last to be at least 5 MiB.
+const S3PartSize = 8 * types.MB
+
+// S3 uploads to s3://bucket/key with a multipart upload, one part at a time
+// as the download reaches it; an object smaller than a part is sent with a
+// single PUT. Credentials and region come from AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN and AWS_REGION. AWS_ENDPOINT_URL
+// points it at another S3-compatible service, such as MinIO, addressed
+// path-style.
+type S3 struct{}
+
+func (S3) Create(ctx context.Context, dest *url.URL, client *http.Client) (Object, error) {
+	if dest.Host == "" || objectPath(dest) == "" {
+		return nil, fmt.Errorf("S3 destination %q needs a bucket and a key", dest.String())
+	}
+	creds := s3Credentials{
+		accessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		token:     os.Getenv("AWS_SESSION_TOKEN"),
+		region:    firstEnv("AWS_REGION", "AWS_DEFAULT_REGION"),
+	}
+	if creds.accessKey == "" || creds.secretKey == "" {
+		return nil, fmt.Errorf("S3 destination needs AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	if creds.region == "" {
+		creds.region = "us-east-1"
+	}
+
+	var objectURL *url.URL
+	if endpoint := firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"); endpoint != "" {
+		base, err := url.Parse(strings.TrimRight(endpoint, "/"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid AWS_ENDPOINT_URL: %w", err)
+		}
+		objectURL = base.JoinPath(dest.Host, objectPath(dest))
+	} else {
+		objectURL = &url.URL{Scheme: "https", Host: dest.Host + ".s3." + creds.region + ".amazonaws.com", Path: dest.Path}
+	}
+	// The path goes out exactly as it is signed
+	objectURL.RawPath = s3PathEscape(objectURL.Path)
+	return &s3Object{ctx: ctx, client: client, creds: creds, url: objectURL}, nil
+}
+
+func firstEnv(keys ...string) string {
+	for _, key := range keys {
+		if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+type s3Object struct {
+	ctx    context.Context
+	client *http.Client
+	creds  s3Credentials
+	url    *url.URL
+
+	buf      bytes.Buffer
+	uploadID string
+	etags    []string
+}
+
+func (o *s3Object) Write(p []byte) (int, error) {
+	n, _ := o.buf.Write(p)
+	for o.buf.Len() >= S3PartSize {
+		if err := o.uploadPart(o.buf.Next(S3PartSize)); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func (o *s3Object) Commit() error {
+	if o.uploadID == "" {
+		_, err := o.do(http.MethodPut, nil, o.buf.Bytes())
+		return err
+	}
+	if o.buf.Len() > 0 {
+		if err := o.uploadPart(o.buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	var body bytes.Buffer
+	body.WriteString("<CompleteMultipartUpload>")
+	for i, etag := range o.etags {
+		fmt.Fprintf(&body, "<Part><PartNumber>%d</PartNumber><ETag>%s</ETag></Part>", i+1, xmlEscape(etag))
+	}
+	body.WriteString("</CompleteMultipartUpload>")
+	resp, err := o.do(http.MethodPost, url.Values{"uploadId": {o.uploadID}}, body.Bytes())
+	if err != nil {
+		return err
+	}
+	// S3 can fail a completion after answering 200
+	if bytes.Contains(resp, []byte("<Error>")) {
+		return fmt.Errorf("S3 could not complete the upload: %s", resp)
+	}
+	return nil
+}
+
+// Abort drops the parts uploaded so far, which S3 would otherwise keep and
+// bill for.
+func (o *s3Object) Abort() error {
+	o.buf.Reset()
+	if o.uploadID == "" {
+		return nil
+	}
+	// An interrupted download still cleans up after itself
+	o.ctx = context.WithoutCancel(o.ctx)
+	_, err := o.do(http.MethodDelete, url.Values{"uploadId": {o.uploadID}}, nil)
+	return err
+}
+
+func (o *s3Object) uploadPart(part []byte) error {
+	if o.uploadID == "" {
+		resp, err := o.do(http.MethodPost, url.Values{"uploads": {""}}, nil)
+		if err != nil {
+			return err
+		}
+		var result struct {
+			UploadID string `xml:"UploadId"`
+		}
+		if err := xml.Unmarshal(resp, &result); err != nil || result.UploadID == "" {
+			return fmt.Errorf("S3 did not start a multipart upload: %s", resp)
+		}
+		o.uploadID = result.UploadID
+	}
+
+	query := url.Values{"partNumber": {strconv.Itoa(len(o.etags) + 1)}, "uploadId": {o.uploadID}}
+	req, err := o.request(http.MethodPut, query, part)
+	if err != nil {
+		return err
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if err := s3StatusError(resp); err != nil {
+		return err
+	}
+	o.etags = append(o.etags, resp.Header.Get("ETag"))
+	return nil
+}
+
+// do sends a signed request and returns the response body.
+func (o *s3Object) do(method string, query url.Values, body []byte) ([]byte, error) {
+	req, err := o.request(method, query, body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if err := s3StatusError(resp); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (o *s3Object) request(method string, query url.Values, body []byte) (*http.Request, error) {
+	u := *o.url
+	u.RawQuery = canonicalQuery(query)
+	req, err := http.NewRequestWithContext(o.ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	o.creds.sign(req, body, time.Now())
+	return req, nil
+}
+
+func s3StatusError(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return fmt.Errorf("S3 upload failed: %w: %s", &types.HTTPStatusError{StatusCode: resp.StatusCode}, bytes.TrimSpace(msg))
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+type s3Credentials struct {
+	accessKey, secretKey, token, region string
+}
+
+// sign adds an AWS Signature Version 4 to req, whose body is body.
+func (c s3Credentials) sign(req *http.Request, body []byte, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	sum := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(sum[:])
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if c.token != "" {
+		req.Header.Set("X-Amz-Security-Token", c.token)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(req.Header.Get(name))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := day + "/" + c.region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+c.secretKey), day)
+	key = hmacSHA256(key, c.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// canonicalQuery encodes query the way Signature Version 4 expects: sorted,
+// with every value present, even when empty, and spaces as %20.
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, s3Escape(k)+"="+s3Escape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3PathEscape escapes every byte of each segment of path that is not
+// unreserved, as Signature Version 4 does.
+func s3PathEscape(path string) string {
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		segments[i] = s3Escape(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+func s3Escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,96 @@
+// Package storage writes downloads to destinations other than a local
+// directory, chosen by the scheme of a destination URI such as
+// s3://bucket/key or webdav://host/dir/file. The bytes are streamed to the
+// destination as they arrive, in order, without a working file on disk.
+//
+// Backends for file, mem, s3, webdav and webdavs are built in; Register adds
+// more.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Object is a file being written to a backend. Nothing written is visible
+// at the destination until Commit; Abort discards it. Exactly one of them
+// must be called.
+type Object interface {
+	io.Writer
+	Commit() error
+	Abort() error
+}
+
+// Backend creates objects at the destinations of one URI scheme. client is
+// what backends that store over HTTP send their requests with.
+type Backend interface {
+	Create(ctx context.Context, dest *url.URL, client *http.Client) (Object, error)
+}
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]Backend{
+		"file":    File{},
+		"mem":     DefaultMemory,
+		"s3":      S3{},
+		"webdav":  WebDAV{},
+		"webdavs": WebDAV{},
+	}
+)
+
+// Register makes b the backend for destinations with scheme, replacing any
+// before it.
+func Register(scheme string, b Backend) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	backends[strings.ToLower(scheme)] = b
+}
+
+// Lookup returns the backend for dest and dest parsed. ok is false when dest
+// is not a URI with a registered scheme, such as a local path.
+func Lookup(dest string) (b Backend, u *url.URL, ok bool) {
+	scheme, _, found := strings.Cut(dest, "://")
+	if !found {
+		return nil, nil, false
+	}
+	backendsMu.RLock()
+	b, ok = backends[strings.ToLower(scheme)]
+	backendsMu.RUnlock()
+	if !ok {
+		return nil, nil, false
+	}
+	u, err := url.Parse(dest)
+	if err != nil {
+		return nil, nil, false
+	}
+	return b, u, true
+}
+
+// IsDestination reports whether dest names a storage destination rather
+// than a local path.
+func IsDestination(dest string) bool {
+	_, _, ok := Lookup(dest)
+	return ok
+}
+
+// Create starts writing the object at dest.
+func Create(ctx context.Context, dest string, client *http.Client) (Object, error) {
+	b, u, ok := Lookup(dest)
+	if !ok {
+		return nil, fmt.Errorf("no storage backend for %q", dest)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return b.Create(ctx, u, client)
+}
+
+// objectPath is the path of dest without its leading slash.
+func objectPath(dest *url.URL) string {
+	return strings.TrimPrefix(dest.Path, "/")
+}
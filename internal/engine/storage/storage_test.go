@@ -0,0 +1,225 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLookup_OnlyRegisteredSchemes(t *testing.T) {
+	for dest, want := range map[string]bool{
+		"s3://bucket/key.iso":     true,
+		"WebDAV://host/dir/a.iso": true,
+		"mem://a.bin":             true,
+		"/home/me/Downloads":      false,
+		`C:\Users\me\Downloads`:   false,
+		"ftp://host/a.iso":        false,
+		"-":                       false,
+	} {
+		if got := IsDestination(dest); got != want {
+			t.Errorf("IsDestination(%q) = %v, want %v", dest, got, want)
+		}
+	}
+}
+
+func TestFile_CommitsUnderTheFinalNameOnly(t *testing.T) {
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "sub", "a.bin")
+
+	obj, err := Create(context.Background(), "file://"+filepath.ToSlash(dest), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = obj.Write([]byte("hello"))
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Fatal("expected nothing at the destination before commit")
+	}
+	if err := obj.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "hello" {
+		t.Fatalf("file holds %q, want hello", data)
+	}
+
+	if _, err := Create(context.Background(), "file://"+filepath.ToSlash(dest), nil); err == nil {
+		t.Fatal("expected an existing file not to be overwritten")
+	}
+
+	other := filepath.Join(dir, "b.bin")
+	obj, err = Create(context.Background(), "file://"+filepath.ToSlash(other), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = obj.Write([]byte("partial"))
+	if err := obj.Abort(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("expected an aborted file to leave nothing behind, have %d entries", len(entries))
+	}
+}
+
+func TestMemory_KeepsCommittedObjects(t *testing.T) {
+	mem := NewMemory()
+	Register("testmem", mem)
+
+	obj, _ := Create(context.Background(), "testmem://bucket/a.bin", nil)
+	_, _ = obj.Write([]byte("data"))
+	if _, ok := mem.Get("bucket/a.bin"); ok {
+		t.Fatal("expected the object to appear only once committed")
+	}
+	_ = obj.Commit()
+	if data, ok := mem.Get("bucket/a.bin"); !ok || string(data) != "data" {
+		t.Fatalf("Get() = %q, %v", data, ok)
+	}
+}
+
+func TestWebDAV_StreamsOnePut(t *testing.T) {
+	var mu sync.Mutex
+	var got []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		if r.Method != http.MethodPut || r.URL.Path != "/dav/a.bin" || user != "me" || pass != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return // The client gave up on the upload
+		}
+		mu.Lock()
+		got = body
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	obj, err := Create(context.Background(), "webdav://me:secret@"+host+"/dav/a.bin", server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 4 {
+		if _, err := obj.Write([]byte("chunk")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := obj.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if string(got) != strings.Repeat("chunk", 4) {
+		t.Fatalf("server got %q", got)
+	}
+
+	obj, _ = Create(context.Background(), "webdav://me:wrong@"+host+"/dav/a.bin", server.Client())
+	_, _ = obj.Write([]byte("x"))
+	if err := obj.Commit(); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("Commit() = %v, want the server's 403", err)
+	}
+}
+
+// fakeS3 records the object uploaded to it, with or without parts.
+type fakeS3 struct {
+	mu      sync.Mutex
+	parts   map[string][]byte
+	objects map[string][]byte
+	aborted bool
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") || r.Header.Get("X-Amz-Content-Sha256") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	q := r.URL.Query()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		_, _ = io.WriteString(w, "<InitiateMultipartUploadResult><UploadId>up-1</UploadId></InitiateMultipartUploadResult>")
+	case r.Method == http.MethodPut && q.Get("uploadId") == "up-1":
+		f.parts[q.Get("partNumber")] = body
+		w.Header().Set("ETag", `"etag-`+q.Get("partNumber")+`"`)
+	case r.Method == http.MethodPost && q.Get("uploadId") == "up-1":
+		var all []byte
+		for i := 1; i <= len(f.parts); i++ {
+			if !bytes.Contains(body, []byte("etag-"+string(rune('0'+i)))) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			all = append(all, f.parts[string(rune('0'+i))]...)
+		}
+		f.objects[r.URL.Path] = all
+		_, _ = io.WriteString(w, "<CompleteMultipartUploadResult/>")
+	case r.Method == http.MethodDelete:
+		f.aborted = true
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		f.objects[r.URL.Path] = body
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func TestS3_UploadsSmallObjectsWholeAndLargeOnesInParts(t *testing.T) {
+	fake := &fakeS3{parts: map[string][]byte{}, objects: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_ENDPOINT_URL", server.URL)
+
+	obj, err := Create(context.Background(), "s3://bucket/small file.txt", server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = obj.Write([]byte("small"))
+	if err := obj.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(fake.objects["/bucket/small file.txt"]); got != "small" {
+		t.Fatalf("small object = %q", got)
+	}
+
+	large := bytes.Repeat([]byte("0123456789abcdef"), (S3PartSize+1024)/16)
+	obj, _ = Create(context.Background(), "s3://bucket/dir/large.bin", server.Client())
+	for chunk := range slicesOf(large, 64*1024) {
+		if _, err := obj.Write(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := obj.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.parts) != 2 || !bytes.Equal(fake.objects["/bucket/dir/large.bin"], large) {
+		t.Fatalf("uploaded %d parts, object matches: %v", len(fake.parts), bytes.Equal(fake.objects["/bucket/dir/large.bin"], large))
+	}
+
+	obj, _ = Create(context.Background(), "s3://bucket/aborted.bin", server.Client())
+	_, _ = obj.Write(large)
+	if err := obj.Abort(); err != nil || !fake.aborted {
+		t.Fatalf("Abort() = %v, aborted upload: %v", err, fake.aborted)
+	}
+}
+
+func slicesOf(data []byte, size int) func(func([]byte) bool) {
+	return func(yield func([]byte) bool) {
+		for len(data) > 0 {
+			n := min(size, len(data))
+			if !yield(data[:n]) {
+				return
+			}
+			data = data[n:]
+		}
+	}
+}
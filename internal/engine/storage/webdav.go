@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+// errAborted ends the upload of an aborted WebDAV object.
+var errAborted = errors.New("upload aborted")
+
+// WebDAV uploads to webdav://host/path over HTTP, or webdavs:// over HTTPS,
+// as one streamed PUT. Credentials in the URI are sent as basic auth. The
+// directory the file goes in must exist.
+type WebDAV struct{}
+
+func (WebDAV) Create(ctx context.Context, dest *url.URL, client *http.Client) (Object, error) {
+	target := *dest
+	target.Scheme = "http"
+	if dest.Scheme == "webdavs" {
+		target.Scheme = "https"
+	}
+	target.User = nil
+
+	pr, pw := io.Pipe()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target.String(), pr)
+	if err != nil {
+		return nil, err
+	}
+	if dest.User != nil {
+		password, _ := dest.User.Password()
+		req.SetBasicAuth(dest.User.Username(), password)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	obj := &webdavObject{pw: pw, done: make(chan struct{})}
+	go func() {
+		defer close(obj.done)
+		resp, err := client.Do(req)
+		if err == nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+			if resp.StatusCode < 200 || resp.StatusCode > 299 {
+				err = fmt.Errorf("WebDAV upload failed: %w", &types.HTTPStatusError{StatusCode: resp.StatusCode})
+			}
+		}
+		obj.err = err
+		// A server that answers before reading everything gets no more
+		if err != nil {
+			_ = pr.CloseWithError(err)
+		} else {
+			_ = pr.Close()
+		}
+	}()
+	return obj, nil
+}
+
+type webdavObject struct {
+	pw   *io.PipeWriter
+	done chan struct{}
+	err  error
+}
+
+func (o *webdavObject) Write(p []byte) (int, error) {
+	return o.pw.Write(p)
+}
+
+func (o *webdavObject) Commit() error {
+	_ = o.pw.Close()
+	<-o.done
+	return o.err
+}
+
+// Abort fails the request body, so the server never gets a complete file.
+func (o *webdavObject) Abort() error {
+	_ = o.pw.CloseWithError(errAborted)
+	<-o.done
+	return nil
+}
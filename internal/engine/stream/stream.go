@@ -0,0 +1,343 @@
+// Package stream downloads a file in order to a writer, such as a pipe into
+// tar or ffmpeg, while fetching the ranges ahead of it over several
+// connections.
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// DefaultChunkSize is how many bytes each range request fetches.
+const DefaultChunkSize = 4 * types.MB
+
+// ErrRangeChanged means the server stopped answering a range the way it
+// answered the first one, so the file changed or ranges are unreliable.
+var ErrRangeChanged = errors.New("server answered a range request differently than the first")
+
+// Downloader streams one URL to a writer. Ranges are fetched by up to
+// Connections requests at a time and written strictly in order; at most
+// 2 × Connections chunks are held in memory waiting for their turn, so a
+// slow reader stalls the fetching instead of growing the buffer.
+type Downloader struct {
+	Client      *http.Client
+	Request     types.RequestOptions
+	Headers     map[string]string
+	UserAgent   string
+	Connections int   // Ranges fetched at once; 1 when unset
+	ChunkSize   int64 // Bytes per range; DefaultChunkSize when unset
+	MaxRetries  int   // Attempts per range after the first
+
+	// Progress, when set, is called from the writing goroutine after each
+	// write with the bytes written so far and the total, which is 0 when
+	// the server did not say.
+	Progress func(written, total int64)
+}
+
+type chunk struct {
+	index int64
+	data  []byte
+	err   error
+}
+
+// Download writes the file at rawurl to w and returns how many bytes it
+// wrote. A server that ignores ranges, or a request that is not a plain GET,
+// is copied over a single connection.
+func (d *Downloader) Download(ctx context.Context, rawurl string, w io.Writer) (int64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	chunkSize := d.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	// The first chunk doubles as the probe: a 206 with the full size means
+	// the rest can be fetched in parallel.
+	resp, err := d.get(ctx, rawurl, 0, chunkSize-1, "")
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return d.copyFrom(w, resp.Body, 0, max(resp.ContentLength, 0))
+	case http.StatusPartialContent:
+	default:
+		return 0, &types.HTTPStatusError{StatusCode: resp.StatusCode}
+	}
+
+	cr, ok := utils.ParseContentRange(resp.Header.Get("Content-Range"))
+	total := max(cr.Total, 0)
+	if !ok || total == 0 || d.Connections <= 1 {
+		// Without a known size the ranges cannot be planned; read on from
+		// where the first one ends with a single request instead.
+		return d.sequential(ctx, rawurl, w, resp, total)
+	}
+
+	first, err := readChunk(resp.Body, min(chunkSize, total))
+	if err != nil {
+		return 0, err
+	}
+	_ = resp.Body.Close()
+
+	validator := resp.Header.Get("ETag")
+	if validator == "" || strings.HasPrefix(validator, "W/") {
+		validator = resp.Header.Get("Last-Modified")
+	}
+	return d.parallel(ctx, rawurl, w, first, total, chunkSize, validator)
+}
+
+// parallel fetches every chunk after the first with a bounded window of
+// workers and writes them in order.
+func (d *Downloader) parallel(ctx context.Context, rawurl string, w io.Writer, first []byte, total, chunkSize int64, validator string) (int64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	count := (total + chunkSize - 1) / chunkSize
+	window := int64(2 * d.Connections)
+
+	jobs := make(chan int64)
+	results := make(chan chunk, window)
+	// slots caps the chunks fetched but not yet written
+	slots := make(chan struct{}, window)
+
+	var wg sync.WaitGroup
+	for range d.Connections {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				start := index * chunkSize
+				end := min(start+chunkSize, total) - 1
+				data, err := d.fetchRange(ctx, rawurl, start, end, validator)
+				select {
+				case results <- chunk{index: index, data: data, err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	go func() {
+		defer close(jobs)
+		for index := int64(1); index < count; index++ {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- index:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	written, err := d.write(w, first, 0, total)
+	if err != nil {
+		return written, err
+	}
+	pending := make(map[int64][]byte)
+	for next := int64(1); next < count; {
+		data, ok := pending[next]
+		if !ok {
+			select {
+			case c := <-results:
+				if c.err != nil {
+					return written, fmt.Errorf("bytes %d-: %w", c.index*chunkSize, c.err)
+				}
+				pending[c.index] = c.data
+			case <-ctx.Done():
+				return written, ctx.Err()
+			}
+			continue
+		}
+		delete(pending, next)
+		if written, err = d.write(w, data, written, total); err != nil {
+			return written, err
+		}
+		<-slots
+		next++
+	}
+	return written, nil
+}
+
+// fetchRange reads bytes start through end, retrying failed attempts.
+func (d *Downloader) fetchRange(ctx context.Context, rawurl string, start, end int64, validator string) ([]byte, error) {
+	var lastErr error
+	for attempt := 0; attempt <= d.MaxRetries; attempt++ {
+		if attempt > 0 {
+			wait := time.Duration(attempt) * time.Second
+			var throttle *engine.ThrottleError
+			if errors.As(lastErr, &throttle) {
+				wait = throttle.Delay(attempt - 1)
+			}
+			if err := engine.SleepContext(ctx, wait); err != nil {
+				return nil, err
+			}
+		}
+
+		data, err := d.tryRange(ctx, rawurl, start, end, validator)
+		if err == nil {
+			return data, nil
+		}
+		if ctx.Err() != nil || errors.Is(err, ErrRangeChanged) {
+			return nil, err
+		}
+		utils.Debug("Stream: bytes %d-%d attempt %d failed: %v", start, end, attempt+1, err)
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+func (d *Downloader) tryRange(ctx context.Context, rawurl string, start, end int64, validator string) ([]byte, error) {
+	resp, err := d.get(ctx, rawurl, start, end, validator)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if throttle := engine.CheckThrottle(resp); throttle != nil {
+		return nil, throttle
+	}
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		// With If-Range, this is how a changed file comes back.
+		return nil, ErrRangeChanged
+	default:
+		return nil, &types.HTTPStatusError{StatusCode: resp.StatusCode}
+	}
+	if cr, ok := utils.ParseContentRange(resp.Header.Get("Content-Range")); !ok || cr.Start != start {
+		return nil, fmt.Errorf("%w: got range %q", ErrRangeChanged, resp.Header.Get("Content-Range"))
+	}
+	return readChunk(resp.Body, end-start+1)
+}
+
+// sequential writes the first response and then keeps requesting from the
+// end of what was written until total bytes, or the end of the file when
+// total is unknown, have arrived.
+func (d *Downloader) sequential(ctx context.Context, rawurl string, w io.Writer, resp *http.Response, total int64) (int64, error) {
+	var written int64
+	body := io.ReadCloser(resp.Body)
+	for {
+		before := written
+		n, err := d.copyFrom(w, body, written, total)
+		written = n
+		_ = body.Close()
+		if err != nil {
+			return written, err
+		}
+		if total > 0 && written >= total {
+			return written, nil
+		}
+		if written == before {
+			// A response that added nothing is the end when the size is
+			// unknown, and a truncated file otherwise.
+			if total > 0 {
+				return written, fmt.Errorf("%w: got %d of %d bytes", io.ErrUnexpectedEOF, written, total)
+			}
+			return written, nil
+		}
+
+		next, err := d.get(ctx, rawurl, written, -1, "")
+		if err != nil {
+			return written, err
+		}
+		switch next.StatusCode {
+		case http.StatusRequestedRangeNotSatisfiable:
+			_ = next.Body.Close()
+			return written, nil
+		case http.StatusPartialContent:
+		default:
+			_ = next.Body.Close()
+			return written, fmt.Errorf("%w: status %d", ErrRangeChanged, next.StatusCode)
+		}
+		body = next.Body
+	}
+}
+
+func (d *Downloader) copyFrom(w io.Writer, body io.Reader, written, total int64) (int64, error) {
+	buf := make([]byte, types.WorkerBuffer)
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			var werr error
+			if written, werr = d.write(w, buf[:n], written, total); werr != nil {
+				return written, werr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return written, nil
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+}
+
+func (d *Downloader) write(w io.Writer, p []byte, written, total int64) (int64, error) {
+	n, err := w.Write(p)
+	written += int64(n)
+	if d.Progress != nil {
+		d.Progress(written, total)
+	}
+	if err != nil {
+		return written, fmt.Errorf("write error: %w", err)
+	}
+	return written, nil
+}
+
+// get requests bytes start through end, or through the end of the file
+// when end is negative. ifRange, when set, makes the server send the whole
+// file instead if it no longer matches that ETag or date.
+func (d *Downloader) get(ctx context.Context, rawurl string, start, end int64, ifRange string) (*http.Response, error) {
+	req, err := d.Request.NewRequest(ctx, rawurl)
+	if err != nil {
+		return nil, err
+	}
+	for key, val := range d.Headers {
+		req.Header.Set(key, val)
+	}
+	if d.UserAgent != "" {
+		req.Header.Set("User-Agent", d.UserAgent)
+	}
+	if d.Request.IsGet() {
+		if end < 0 {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", start))
+		} else {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+		}
+		if ifRange != "" {
+			req.Header.Set("If-Range", ifRange)
+		}
+	}
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+func readChunk(body io.Reader, size int64) ([]byte, error) {
+	data := make([]byte, size)
+	if _, err := io.ReadFull(body, data); err != nil {
+		return nil, fmt.Errorf("short range: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,134 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testContent(size int) []byte {
+	data := make([]byte, size)
+	rand.New(rand.NewSource(1)).Read(data)
+	return data
+}
+
+func TestDownload_ParallelRangesWrittenInOrder(t *testing.T) {
+	content := testContent(1<<20 + 123)
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		// Later ranges answer first, so they have to wait in the buffer
+		if strings.HasPrefix(r.Header.Get("Range"), "bytes=65536-") {
+			time.Sleep(50 * time.Millisecond)
+		}
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	var lastWritten, lastTotal int64
+	d := &Downloader{
+		Client:      srv.Client(),
+		Connections: 4,
+		ChunkSize:   64 << 10,
+		Progress:    func(written, total int64) { lastWritten, lastTotal = written, total },
+	}
+	n, err := d.Download(context.Background(), srv.URL+"/file.bin", &out)
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if n != int64(len(content)) || !bytes.Equal(out.Bytes(), content) {
+		t.Fatalf("got %d bytes, content equal = %v", n, bytes.Equal(out.Bytes(), content))
+	}
+	if want := int32((len(content) + 64<<10 - 1) / (64 << 10)); requests.Load() != want {
+		t.Errorf("requests = %d, want one per chunk (%d)", requests.Load(), want)
+	}
+	if lastWritten != n || lastTotal != n {
+		t.Errorf("last progress = %d/%d, want %d/%d", lastWritten, lastTotal, n, n)
+	}
+}
+
+func TestDownload_ServerWithoutRanges(t *testing.T) {
+	content := testContent(300 << 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(content)
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	d := &Downloader{Client: srv.Client(), Connections: 4, ChunkSize: 64 << 10}
+	if _, err := d.Download(context.Background(), srv.URL, &out); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if !bytes.Equal(out.Bytes(), content) {
+		t.Fatalf("got %d bytes, want %d", out.Len(), len(content))
+	}
+}
+
+func TestDownload_RetriesFailedRange(t *testing.T) {
+	content := testContent(200 << 10)
+	var failed atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("Range"), "bytes=131072-") && failed.CompareAndSwap(false, true) {
+			http.Error(w, "busy", http.StatusInternalServerError)
+			return
+		}
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	d := &Downloader{Client: srv.Client(), Connections: 2, ChunkSize: 64 << 10, MaxRetries: 1}
+	if _, err := d.Download(context.Background(), srv.URL, &out); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if !failed.Load() || !bytes.Equal(out.Bytes(), content) {
+		t.Fatalf("failed = %v, content equal = %v", failed.Load(), bytes.Equal(out.Bytes(), content))
+	}
+}
+
+func TestDownload_FileChangedMidStream(t *testing.T) {
+	content := testContent(200 << 10)
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Every range after the first sees a new version of the file
+		if calls.Add(1) == 1 {
+			w.Header().Set("ETag", `"v1"`)
+		} else {
+			w.Header().Set("ETag", `"v2"`)
+		}
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	d := &Downloader{Client: srv.Client(), Connections: 2, ChunkSize: 64 << 10, MaxRetries: 3}
+	_, err := d.Download(context.Background(), srv.URL, &bytes.Buffer{})
+	if !errors.Is(err, ErrRangeChanged) {
+		t.Fatalf("Download error = %v, want ErrRangeChanged", err)
+	}
+}
+
+func TestDownload_SingleConnectionContinuesFromFirstRange(t *testing.T) {
+	content := testContent(150 << 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	d := &Downloader{Client: srv.Client(), Connections: 1, ChunkSize: 64 << 10}
+	if _, err := d.Download(context.Background(), srv.URL, &out); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if !bytes.Equal(out.Bytes(), content) {
+		t.Fatalf("got %d bytes, want %d", out.Len(), len(content))
+	}
+}
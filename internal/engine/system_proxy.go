@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// proxyEnvVars are the variables http.ProxyFromEnvironment reads a proxy from.
+var proxyEnvVars = []string{"HTTP_PROXY", "http_proxy", "HTTPS_PROXY", "https_proxy"}
+
+// SystemProxy routes requests through the proxy configured in the operating
+// system's network settings, for transports with no proxy_url. A proxy set
+// in the environment wins, as it did before system proxies were read. The
+// settings are detected on first use and again on Refresh.
+type SystemProxy struct {
+	mu       sync.Mutex
+	detected bool
+	settings utils.SystemProxySettings
+	detect   func() utils.SystemProxySettings
+}
+
+// DefaultSystemProxy is the global instance shared by all transports.
+var DefaultSystemProxy = &SystemProxy{detect: utils.DetectSystemProxy}
+
+// Proxy returns the proxy for req, for use as an http.Transport's Proxy.
+func (s *SystemProxy) Proxy(req *http.Request) (*url.URL, error) {
+	if proxyFromEnvironment() {
+		return http.ProxyFromEnvironment(req)
+	}
+	return s.Settings().ProxyFor(req.URL), nil
+}
+
+// Settings returns the detected system proxy settings.
+func (s *SystemProxy) Settings() utils.SystemProxySettings {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.detected {
+		s.settings = s.detect()
+		s.detected = true
+		utils.Debug("SystemProxy: detected %s", s.settings)
+	}
+	return s.settings
+}
+
+// Refresh detects the settings again, typically after the network changed,
+// and reports whether they differ from before. New connections use the new
+// proxy; open ones are left alone.
+func (s *SystemProxy) Refresh() (utils.SystemProxySettings, bool) {
+	settings := s.detect()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := s.detected && !settings.Equal(s.settings)
+	s.settings, s.detected = settings, true
+	if changed {
+		utils.Debug("SystemProxy: changed to %s", settings)
+	}
+	return settings, changed
+}
+
+func proxyFromEnvironment() bool {
+	for _, name := range proxyEnvVars {
+		if os.Getenv(name) != "" {
+			return true
+		}
+	}
+	return false
+}
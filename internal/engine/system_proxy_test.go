@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+func TestSystemProxy_UsesDetectedSettings(t *testing.T) {
+	for _, name := range proxyEnvVars {
+		t.Setenv(name, "")
+	}
+	detected := utils.SystemProxySettings{HTTP: "http://a:3128", HTTPS: "http://a:3128"}
+	calls := 0
+	s := &SystemProxy{detect: func() utils.SystemProxySettings {
+		calls++
+		return detected
+	}}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com/file", nil)
+	proxy, err := s.Proxy(req)
+	if err != nil || proxy == nil || proxy.Host != "a:3128" {
+		t.Fatalf("Proxy = %v, %v; want a:3128", proxy, err)
+	}
+	_, _ = s.Proxy(req)
+	if calls != 1 {
+		t.Errorf("detected %d times, want once until Refresh", calls)
+	}
+
+	if _, changed := s.Refresh(); changed {
+		t.Error("Refresh with the same settings reported a change")
+	}
+	detected = utils.SystemProxySettings{}
+	if _, changed := s.Refresh(); !changed {
+		t.Error("Refresh did not report the proxy going away")
+	}
+	if proxy, _ := s.Proxy(req); proxy != nil {
+		t.Errorf("Proxy = %v after the system proxy was removed", proxy)
+	}
+}
+
+func TestSystemProxy_EnvironmentWins(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "http://env:8080")
+	s := &SystemProxy{detect: func() utils.SystemProxySettings {
+		return utils.SystemProxySettings{HTTP: "http://a:3128", HTTPS: "http://a:3128"}
+	}}
+	if !proxyFromEnvironment() {
+		t.Fatal("HTTPS_PROXY not seen")
+	}
+	// ProxyFromEnvironment reads the environment once per process, so only
+	// check that the detected proxy is not used.
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com/file", nil)
+	if proxy, _ := s.Proxy(req); proxy != nil && proxy.Host == "a:3128" {
+		t.Error("system proxy used although HTTPS_PROXY is set")
+	}
+}
@@ -0,0 +1,247 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// WorkingFile is what the downloaders write a download's working file
+// through: the file itself, or a TeeFile that also writes its copies.
+type WorkingFile interface {
+	io.ReaderAt
+	io.WriterAt
+	io.Writer
+	Truncate(size int64) error
+	Sync() error
+	Close() error
+}
+
+// CopyPath is where the copy of destPath in dir ends up.
+func CopyPath(dir, destPath string) string {
+	return filepath.Join(dir, filepath.Base(destPath))
+}
+
+// CreateCopies creates the working file of each copy of destPath. A copy
+// only exists while its working file does, so a copy that failed once is
+// not resumed with a hole in it.
+func CreateCopies(destPath string, copies []string) error {
+	for _, dir := range copies {
+		if !filepath.IsAbs(dir) {
+			return fmt.Errorf("copy directory %q is not absolute", dir)
+		}
+		if filepath.Clean(dir) == filepath.Dir(destPath) {
+			return fmt.Errorf("copy directory %q is the download's own directory", dir)
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create copy directory: %w", err)
+		}
+		file, err := os.OpenFile(CopyPath(dir, destPath)+types.IncompleteSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to create copy: %w", err)
+		}
+		_ = file.Close()
+	}
+	return nil
+}
+
+// RemoveCopies deletes the working files of destPath's copies.
+func RemoveCopies(destPath string, copies []string) {
+	for _, dir := range copies {
+		_ = os.Remove(CopyPath(dir, destPath) + types.IncompleteSuffix)
+	}
+}
+
+// TruncateCopies empties the working files of destPath's copies, for a
+// download that starts over from the first byte.
+func TruncateCopies(destPath string, copies []string) {
+	for _, dir := range copies {
+		_ = os.Truncate(CopyPath(dir, destPath)+types.IncompleteSuffix, 0)
+	}
+}
+
+// OpenWorkingFile opens destPath's working file for reading and writing.
+// With copies it returns a TeeFile that writes each of them as well; a copy
+// whose working file is gone is left out.
+func OpenWorkingFile(destPath string, copies []string) (WorkingFile, error) {
+	primary, err := os.OpenFile(destPath+types.IncompleteSuffix, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(copies) == 0 {
+		return primary, nil
+	}
+
+	tee := &TeeFile{primary: primary}
+	for _, dir := range copies {
+		path := CopyPath(dir, destPath) + types.IncompleteSuffix
+		file, err := os.OpenFile(path, os.O_RDWR, 0)
+		if err != nil {
+			utils.Debug("Tee: leaving out copy %s: %v", path, err)
+			continue
+		}
+		tee.copies = append(tee.copies, &teeCopy{path: path, file: file})
+	}
+	return tee, nil
+}
+
+// TeeFile writes a working file and its copies at the same time. Reads come
+// from the working file alone. A copy that fails to write or sync is
+// dropped, with its working file removed, without failing the download.
+type TeeFile struct {
+	primary *os.File
+	copies  []*teeCopy
+}
+
+type teeCopy struct {
+	path   string
+	file   *os.File
+	failed atomic.Bool
+	once   sync.Once
+}
+
+// fail drops the copy after err. The first failure wins; later writes to it
+// are skipped.
+func (c *teeCopy) fail(op string, err error) {
+	c.once.Do(func() {
+		c.failed.Store(true)
+		utils.Debug("Tee: dropping copy %s after %s error: %v", c.path, op, err)
+		_ = c.file.Close()
+		_ = os.Remove(c.path)
+	})
+}
+
+func (t *TeeFile) ReadAt(p []byte, off int64) (int, error) {
+	return t.primary.ReadAt(p, off)
+}
+
+func (t *TeeFile) WriteAt(p []byte, off int64) (int, error) {
+	n, err := t.primary.WriteAt(p, off)
+	if err != nil {
+		return n, err
+	}
+	for _, c := range t.copies {
+		if c.failed.Load() {
+			continue
+		}
+		if _, err := c.file.WriteAt(p, off); err != nil {
+			c.fail("write", err)
+		}
+	}
+	return n, nil
+}
+
+// Write appends p to every file. The files start at the same offset when
+// opened, so sequential writes stay aligned.
+func (t *TeeFile) Write(p []byte) (int, error) {
+	n, err := t.primary.Write(p)
+	if err != nil {
+		return n, err
+	}
+	for _, c := range t.copies {
+		if c.failed.Load() {
+			continue
+		}
+		if _, err := c.file.Write(p); err != nil {
+			c.fail("write", err)
+		}
+	}
+	return n, nil
+}
+
+func (t *TeeFile) Truncate(size int64) error {
+	if err := t.primary.Truncate(size); err != nil {
+		return err
+	}
+	for _, c := range t.copies {
+		if c.failed.Load() {
+			continue
+		}
+		if err := c.file.Truncate(size); err != nil {
+			c.fail("truncate", err)
+		}
+	}
+	return nil
+}
+
+// Sync flushes each file on its own, so a slow network mount does not hold
+// back the local one.
+func (t *TeeFile) Sync() error {
+	var wg sync.WaitGroup
+	for _, c := range t.copies {
+		if c.failed.Load() {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.file.Sync(); err != nil {
+				c.fail("sync", err)
+			}
+		}()
+	}
+	err := t.primary.Sync()
+	wg.Wait()
+	return err
+}
+
+func (t *TeeFile) Close() error {
+	for _, c := range t.copies {
+		if !c.failed.Load() {
+			_ = c.file.Close()
+		}
+	}
+	return t.primary.Close()
+}
+
+// FinalizeCopies moves the finished copies of destPath into place once its
+// working file is complete, giving them its modification time. A copy that
+// is missing, is not the working file's size or would overwrite an existing
+// file is removed instead. It returns the paths of the copies in place.
+func FinalizeCopies(destPath string, copies []string) []string {
+	if len(copies) == 0 {
+		return nil
+	}
+	info, err := os.Stat(destPath + types.IncompleteSuffix)
+	if err != nil {
+		return nil
+	}
+
+	var done []string
+	for _, dir := range copies {
+		final := CopyPath(dir, destPath)
+		if err := finalizeCopy(final, info.Size(), info.ModTime()); err != nil {
+			utils.Debug("Tee: copy %s not completed: %v", final, err)
+			_ = os.Remove(final + types.IncompleteSuffix)
+			continue
+		}
+		done = append(done, final)
+	}
+	return done
+}
+
+func finalizeCopy(final string, size int64, modTime time.Time) error {
+	working := final + types.IncompleteSuffix
+	info, err := os.Stat(working)
+	if err != nil {
+		return err
+	}
+	if info.Size() != size {
+		return fmt.Errorf("copy has %d bytes, want %d", info.Size(), size)
+	}
+	if _, err := os.Stat(final); err == nil {
+		return fmt.Errorf("%w: %s", os.ErrExist, final)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	_ = os.Chtimes(working, modTime, modTime)
+	return os.Rename(working, final)
+}
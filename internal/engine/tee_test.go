@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func TestTeeFile_WritesAndFinalizesCopies(t *testing.T) {
+	root := t.TempDir()
+	destPath := filepath.Join(root, "local", "big.bin")
+	nas := filepath.Join(root, "nas")
+	if err := os.MkdirAll(filepath.Dir(destPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(destPath+types.IncompleteSuffix, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := CreateCopies(destPath, []string{nas}); err != nil {
+		t.Fatalf("CreateCopies: %v", err)
+	}
+
+	file, err := OpenWorkingFile(destPath, []string{nas})
+	if err != nil {
+		t.Fatalf("OpenWorkingFile: %v", err)
+	}
+	if err := file.Truncate(10); err != nil {
+		t.Fatal(err)
+	}
+	// Chunks land out of order, as the concurrent workers write them
+	if _, err := file.WriteAt([]byte("fghij"), 5); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteAt([]byte("abcde"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	done := FinalizeCopies(destPath, []string{nas})
+	want := filepath.Join(nas, "big.bin")
+	if len(done) != 1 || done[0] != want {
+		t.Fatalf("FinalizeCopies = %v, want [%s]", done, want)
+	}
+	got, err := os.ReadFile(want)
+	if err != nil || !bytes.Equal(got, []byte("abcdefghij")) {
+		t.Fatalf("copy = %q, %v", got, err)
+	}
+	if _, err := os.Stat(want + types.IncompleteSuffix); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("copy working file left behind: %v", err)
+	}
+}
+
+func TestTeeFile_FailedCopyIsDropped(t *testing.T) {
+	root := t.TempDir()
+	destPath := filepath.Join(root, "big.bin")
+	nas := filepath.Join(root, "nas")
+	if err := os.WriteFile(destPath+types.IncompleteSuffix, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := CreateCopies(destPath, []string{nas}); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := OpenWorkingFile(destPath, []string{nas})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tee := file.(*TeeFile)
+	// The mount goes away mid-download
+	_ = tee.copies[0].file.Close()
+	if _, err := file.Write([]byte("data")); err != nil {
+		t.Fatalf("Write failed for the download itself: %v", err)
+	}
+	if err := file.Sync(); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	_ = file.Close()
+
+	if _, err := os.Stat(filepath.Join(nas, "big.bin") + types.IncompleteSuffix); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("failed copy was not removed: %v", err)
+	}
+	if done := FinalizeCopies(destPath, []string{nas}); len(done) != 0 {
+		t.Fatalf("FinalizeCopies = %v, want none", done)
+	}
+	if got, _ := os.ReadFile(destPath + types.IncompleteSuffix); string(got) != "data" {
+		t.Fatalf("working file = %q", got)
+	}
+}
+
+func TestFinalizeCopies_KeepsExistingFile(t *testing.T) {
+	root := t.TempDir()
+	destPath := filepath.Join(root, "big.bin")
+	nas := filepath.Join(root, "nas")
+	if err := os.WriteFile(destPath+types.IncompleteSuffix, []byte("new"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := CreateCopies(destPath, []string{nas}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(nas, "big.bin")+types.IncompleteSuffix, []byte("new"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(nas, "big.bin"), []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if done := FinalizeCopies(destPath, []string{nas}); len(done) != 0 {
+		t.Fatalf("FinalizeCopies = %v, want none", done)
+	}
+	if got, _ := os.ReadFile(filepath.Join(nas, "big.bin")); string(got) != "old" {
+		t.Fatalf("existing file overwritten: %q", got)
+	}
+}
+
+func TestCreateCopies_RejectsOwnOrRelativeDirectory(t *testing.T) {
+	root := t.TempDir()
+	destPath := filepath.Join(root, "big.bin")
+	if err := CreateCopies(destPath, []string{root}); err == nil {
+		t.Error("CreateCopies accepted the download's own directory")
+	}
+	if err := CreateCopies(destPath, []string{"relative"}); err == nil {
+		t.Error("CreateCopies accepted a relative directory")
+	}
+}
@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+// ThrottleError reports a 429 or 503 response. Wait is the delay the server
+// asked for through Retry-After, or zero when it did not say.
+type ThrottleError struct {
+	StatusCode int
+	Wait       time.Duration
+}
+
+func (e *ThrottleError) Error() string {
+	if e.Wait > 0 {
+		return fmt.Sprintf("server busy (%d), retry after %v", e.StatusCode, e.Wait)
+	}
+	return fmt.Sprintf("server busy (%d)", e.StatusCode)
+}
+
+// Delay returns how long to back off before the given retry (starting at 0):
+// the server's Retry-After when it sent one, exponential backoff otherwise.
+func (e *ThrottleError) Delay(retry int) time.Duration {
+	if e.Wait > 0 {
+		return e.Wait
+	}
+	return min(time.Duration(1<<min(retry, 10))*types.RetryBaseDelay, types.MaxRetryAfter)
+}
+
+// CheckThrottle returns a ThrottleError and closes the body when resp is a
+// 429 or 503. Any other response is left untouched and nil is returned.
+func CheckThrottle(resp *http.Response) *ThrottleError {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return nil
+	}
+	_ = resp.Body.Close()
+	wait, _ := ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	return &ThrottleError{StatusCode: resp.StatusCode, Wait: wait}
+}
+
+// ParseRetryAfter reads a Retry-After value given either as delay seconds or
+// as an HTTP date. Delays are capped at types.MaxRetryAfter so a hostile
+// server cannot park a download indefinitely.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	var wait time.Duration
+	if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		wait = time.Duration(min(secs, int64(types.MaxRetryAfter/time.Second))) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		wait = max(at.Sub(now), 0)
+	} else {
+		return 0, false
+	}
+	return min(wait, types.MaxRetryAfter), true
+}
+
+// SleepContext waits for d or until ctx is done, returning ctx's error in the
+// latter case.
+func SleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{" 0 ", 0, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"86400", types.MaxRetryAfter, true},
+		{now.Add(24 * time.Hour).Format(http.TimeFormat), types.MaxRetryAfter, true},
+	}
+	for _, tt := range tests {
+		got, ok := ParseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseRetryAfter(%q) = (%v, %v), want (%v, %v)", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCheckThrottle(t *testing.T) {
+	newResp := func(status int, retryAfter string) *http.Response {
+		resp := &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}
+		if retryAfter != "" {
+			resp.Header.Set("Retry-After", retryAfter)
+		}
+		return resp
+	}
+
+	if throttle := CheckThrottle(newResp(http.StatusPartialContent, "5")); throttle != nil {
+		t.Errorf("206 should not be a throttle, got %v", throttle)
+	}
+
+	throttle := CheckThrottle(newResp(http.StatusServiceUnavailable, "5"))
+	if throttle == nil || throttle.StatusCode != http.StatusServiceUnavailable || throttle.Wait != 5*time.Second {
+		t.Fatalf("503 with Retry-After = %+v", throttle)
+	}
+	if got := throttle.Delay(3); got != 5*time.Second {
+		t.Errorf("Delay with Retry-After = %v, want the server's 5s", got)
+	}
+
+	throttle = CheckThrottle(newResp(http.StatusTooManyRequests, ""))
+	if throttle == nil || throttle.Wait != 0 {
+		t.Fatalf("429 without Retry-After = %+v", throttle)
+	}
+	if d0, d2 := throttle.Delay(0), throttle.Delay(2); d0 != types.RetryBaseDelay || d2 != 4*types.RetryBaseDelay {
+		t.Errorf("backoff delays = %v, %v", d0, d2)
+	}
+}
@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+// buildTLSConfig turns opts into a tls.Config for download transports. It
+// returns nil when opts leaves everything at the defaults.
+func buildTLSConfig(opts types.TLSOptions) (*tls.Config, error) {
+	if opts.IsZero() {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{InsecureSkipVerify: opts.Insecure}
+
+	if caFile := strings.TrimSpace(opts.CAFile); caFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		pemData, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA file %q: %w", caFile, err)
+		}
+		if !pool.AppendCertsFromPEM(pemData) {
+			return nil, fmt.Errorf("read CA file %q: no certificates found", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	certFile := strings.TrimSpace(opts.ClientCertFile)
+	keyFile := strings.TrimSpace(opts.ClientKeyFile)
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, fmt.Errorf("client certificate and key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if strings.TrimSpace(opts.Pins) != "" {
+		pins, err := types.ParseTLSPins(opts.Pins)
+		if err != nil {
+			return nil, err
+		}
+		// VerifyConnection rather than VerifyPeerCertificate: one transport
+		// serves many hosts and only the former sees the server name.
+		cfg.VerifyConnection = verifyPins(pins)
+	}
+
+	return cfg, nil
+}
+
+// verifyPins rejects a pinned host unless a certificate in its chain carries
+// one of the pinned public keys. Hosts without a rule are not affected.
+func verifyPins(pins types.TLSPins) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		want := pins.ForHost(cs.ServerName)
+		if len(want) == 0 {
+			return nil
+		}
+
+		// Prefer the chains the standard verifier built. With verification
+		// disabled nothing ties the other presented certificates to the
+		// leaf, since anyone can send a public one along with their own, so
+		// only the leaf's key counts.
+		var candidates []*x509.Certificate
+		for _, chain := range cs.VerifiedChains {
+			candidates = append(candidates, chain...)
+		}
+		if len(cs.VerifiedChains) == 0 && len(cs.PeerCertificates) > 0 {
+			candidates = cs.PeerCertificates[:1]
+		}
+		for _, cert := range candidates {
+			if slices.Contains(want, types.SPKIPin(cert)) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w for %s", types.ErrCertificatePin, cs.ServerName)
+	}
+}
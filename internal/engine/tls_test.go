@@ -0,0 +1,284 @@
+package engine
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+// writeSelfSignedCert writes a throwaway certificate and key to dir and
+// returns their paths along with the parsed certificate.
+func writeSelfSignedCert(t *testing.T, dir, name string) (string, string, *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	certPath := filepath.Join(dir, name+".crt")
+	keyPath := filepath.Join(dir, name+".key")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certPath, keyPath, cert
+}
+
+// writeServerCA writes the test server's certificate as a PEM CA bundle.
+func writeServerCA(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "server-ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func getWithTLS(t *testing.T, pool *NetworkPool, url string, opts types.TLSOptions) error {
+	t.Helper()
+	transport, err := pool.AcquireTransportWithTLS("", "", opts, 0)
+	if err != nil {
+		return err
+	}
+	defer pool.ReleaseTransport(transport)
+
+	resp, err := (&http.Client{Transport: transport}).Get(url)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+func TestTLSOptions_PrivateCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	pool := &NetworkPool{}
+
+	if err := getWithTLS(t, pool, server.URL, types.TLSOptions{}); err == nil {
+		t.Fatal("expected an untrusted certificate to fail with default TLS options")
+	}
+	if err := getWithTLS(t, pool, server.URL, types.TLSOptions{CAFile: writeServerCA(t, server)}); err != nil {
+		t.Fatalf("request with CA bundle failed: %v", err)
+	}
+	if err := getWithTLS(t, pool, server.URL, types.TLSOptions{Insecure: true}); err != nil {
+		t.Fatalf("insecure request failed: %v", err)
+	}
+}
+
+func TestTLSOptions_ClientCertificate(t *testing.T) {
+	certPath, keyPath, clientCert := writeSelfSignedCert(t, t.TempDir(), "client")
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	pool := &NetworkPool{}
+	caFile := writeServerCA(t, server)
+
+	if err := getWithTLS(t, pool, server.URL, types.TLSOptions{CAFile: caFile}); err == nil {
+		t.Fatal("expected the server to reject a connection without a client certificate")
+	}
+	opts := types.TLSOptions{CAFile: caFile, ClientCertFile: certPath, ClientKeyFile: keyPath}
+	if err := getWithTLS(t, pool, server.URL, opts); err != nil {
+		t.Fatalf("mutual TLS request failed: %v", err)
+	}
+}
+
+func TestBuildTLSConfig_Errors(t *testing.T) {
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "not.pem")
+	if err := os.WriteFile(notPEM, []byte("hello"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	certPath, _, _ := writeSelfSignedCert(t, dir, "lonely")
+
+	tests := []struct {
+		name string
+		opts types.TLSOptions
+	}{
+		{"missing CA file", types.TLSOptions{CAFile: filepath.Join(dir, "missing.pem")}},
+		{"CA file without certificates", types.TLSOptions{CAFile: notPEM}},
+		{"certificate without key", types.TLSOptions{ClientCertFile: certPath}},
+		{"key that does not match", types.TLSOptions{ClientCertFile: certPath, ClientKeyFile: notPEM}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := buildTLSConfig(tt.opts); err == nil {
+				t.Error("expected an error")
+			}
+			pool := &NetworkPool{}
+			if tr, err := pool.AcquireTransportWithTLS("", "", tt.opts, 0); err == nil || tr != nil {
+				t.Error("AcquireTransportWithTLS should fail without leasing a transport")
+			}
+			if len(pool.configMap) != 0 {
+				t.Error("failed TLS setup should not be cached in the pool")
+			}
+		})
+	}
+
+	if cfg, err := buildTLSConfig(types.TLSOptions{}); err != nil || cfg != nil {
+		t.Errorf("default options = (%v, %v), want (nil, nil)", cfg, err)
+	}
+}
+
+func TestNetworkPool_SeparatesTLSOptions(t *testing.T) {
+	pool := &NetworkPool{}
+
+	plain := pool.AcquireTransport("", "", 0)
+	insecure, err := pool.AcquireTransportWithTLS("", "", types.TLSOptions{Insecure: true}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.ReleaseTransport(plain)
+	defer pool.ReleaseTransport(insecure)
+
+	if plain == insecure {
+		t.Fatal("different TLS options must not share a transport")
+	}
+	if plain.TLSClientConfig != nil {
+		t.Error("default transport should not carry a TLS config")
+	}
+	if insecure.TLSClientConfig == nil || !insecure.TLSClientConfig.InsecureSkipVerify {
+		t.Error("insecure transport should skip verification")
+	}
+}
+
+func TestTLSOptions_Pins(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	pool := &NetworkPool{}
+
+	// The test certificate is valid for example.com; route that name to the
+	// local server so the handshake carries a server name to pin against.
+	get := func(opts types.TLSOptions) error {
+		transport, err := pool.AcquireTransportWithTLS("", "", opts, 0)
+		if err != nil {
+			return err
+		}
+		defer pool.ReleaseTransport(transport)
+		transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+		}
+		resp, err := (&http.Client{Transport: transport}).Get("https://example.com/")
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		return nil
+	}
+
+	goodPin := types.SPKIPin(server.Certificate())
+	_, _, other := writeSelfSignedCert(t, t.TempDir(), "other")
+	badPin := types.SPKIPin(other)
+	caFile := writeServerCA(t, server)
+
+	if err := get(types.TLSOptions{CAFile: caFile, Pins: "example.com=" + goodPin}); err != nil {
+		t.Fatalf("request with matching pin failed: %v", err)
+	}
+	if err := get(types.TLSOptions{CAFile: caFile, Pins: "example.com=" + badPin + ",example.com=" + goodPin}); err != nil {
+		t.Fatalf("backup pin should be accepted: %v", err)
+	}
+
+	err := get(types.TLSOptions{CAFile: caFile, Pins: "*.com=" + badPin})
+	if !errors.Is(err, types.ErrCertificatePin) {
+		t.Fatalf("mismatched pin error = %v, want ErrCertificatePin", err)
+	}
+	err = get(types.TLSOptions{Insecure: true, Pins: "example.com=" + badPin})
+	if !errors.Is(err, types.ErrCertificatePin) {
+		t.Fatalf("insecure mode must still enforce pins, got %v", err)
+	}
+
+	if err := get(types.TLSOptions{CAFile: caFile, Pins: "other.example.org=" + badPin}); err != nil {
+		t.Fatalf("pins for another host should not apply: %v", err)
+	}
+}
+
+func TestTLSOptions_PinsInsecureOnlyTrustLeaf(t *testing.T) {
+	real := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer real.Close()
+	pin := types.SPKIPin(real.Certificate())
+
+	// A forged leaf sent with the real, public certificate after it
+	certFile, keyFile, _ := writeSelfSignedCert(t, t.TempDir(), "forged")
+	forged, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged.Certificate = append(forged.Certificate, real.Certificate().Raw)
+	mitm := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	mitm.TLS = &tls.Config{Certificates: []tls.Certificate{forged}}
+	mitm.StartTLS()
+	defer mitm.Close()
+
+	get := func(server *httptest.Server) error {
+		// A pool of its own, so no connection to the other server is reused
+		pool := &NetworkPool{}
+		transport, err := pool.AcquireTransportWithTLS("", "", types.TLSOptions{Insecure: true, Pins: "example.com=" + pin}, 0)
+		if err != nil {
+			return err
+		}
+		defer pool.ReleaseTransport(transport)
+		transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+		}
+		resp, err := (&http.Client{Transport: transport}).Get("https://example.com/")
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		return nil
+	}
+
+	if err := get(real); err != nil {
+		t.Fatalf("pinned leaf rejected: %v", err)
+	}
+	if err := get(mitm); !errors.Is(err, types.ErrCertificatePin) {
+		t.Fatalf("forged leaf with the pinned certificate behind it: err = %v, want ErrCertificatePin", err)
+	}
+}
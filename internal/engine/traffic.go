@@ -0,0 +1,31 @@
+package engine
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// TrafficMeter counts the bytes downloads read from the network. It is a
+// Limiter that never waits, so it can sit last in a download's MultiLimiter
+// and see each read once the limiters before it have let it through.
+type TrafficMeter struct {
+	n atomic.Int64
+}
+
+func (m *TrafficMeter) WaitN(_ context.Context, n int64) error {
+	if n > 0 {
+		m.n.Add(n)
+	}
+	return nil
+}
+
+func (m *TrafficMeter) Refund(n int64) {
+	if n > 0 {
+		m.n.Add(-n)
+	}
+}
+
+// Take returns the bytes counted since the last Take.
+func (m *TrafficMeter) Take() int64 {
+	return m.n.Swap(0)
+}
@@ -0,0 +1,37 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxAliasLength bounds how long a download alias can be.
+const MaxAliasLength = 64
+
+// ValidateAlias checks a download alias such as "nightly-build". Aliases start
+// with a letter and use letters, digits, '-', '_' and '.'. Names made only of
+// hex digits and dashes are rejected because they read as download IDs. The
+// empty alias is valid and means none.
+func ValidateAlias(alias string) error {
+	if alias == "" {
+		return nil
+	}
+	if len(alias) > MaxAliasLength {
+		return fmt.Errorf("alias %q is longer than %d characters", alias, MaxAliasLength)
+	}
+	first := alias[0]
+	if (first < 'a' || first > 'z') && (first < 'A' || first > 'Z') {
+		return fmt.Errorf("alias %q must start with a letter", alias)
+	}
+	for _, r := range alias {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return fmt.Errorf("alias %q may only contain letters, digits, '-', '_' and '.'", alias)
+		}
+	}
+	if strings.Trim(strings.ToLower(alias), "0123456789abcdef-") == "" {
+		return fmt.Errorf("alias %q looks like a download ID", alias)
+	}
+	return nil
+}
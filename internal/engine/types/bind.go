@@ -0,0 +1,65 @@
+package types
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ValidateBindInterface rejects values that can be neither an IP nor an
+// interface name, alone or joined with +. Whether an interface exists is
+// only checked when a connection is made, since a VPN tunnel may come up
+// after Surge starts.
+func ValidateBindInterface(iface string) error {
+	if iface == "" {
+		return nil
+	}
+	for _, part := range strings.Split(iface, "+") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return fmt.Errorf("invalid interface list %q: want names joined with +, e.g. wlan0+eth0", iface)
+		}
+		if net.ParseIP(part) != nil {
+			continue
+		}
+		if strings.ContainsAny(part, " \t,=/") {
+			return fmt.Errorf("invalid interface %q: want an interface name or a local IP", part)
+		}
+	}
+	return nil
+}
+
+// SplitBindInterfaces returns the interfaces in iface, which joins several
+// with + to spread a download's connections across them, e.g. wlan0+eth0.
+// It returns nil for no binding.
+func SplitBindInterfaces(iface string) []string {
+	var out []string
+	for _, part := range strings.Split(iface, "+") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// BindRuntime returns r with BindInterface set to the interface a download
+// of rawurl connects from: iface when the download names one, else the
+// BindRules entry for its host, else the global setting.
+func BindRuntime(r *RuntimeConfig, iface, rawurl string) *RuntimeConfig {
+	var out RuntimeConfig
+	if r != nil {
+		out = *r
+	} else {
+		out = *DefaultRuntimeConfig()
+	}
+	if iface == "" && len(out.BindRules) > 0 {
+		if u, err := url.Parse(rawurl); err == nil {
+			iface, _ = out.BindRules.ForHost(u.Hostname())
+		}
+	}
+	if iface != "" {
+		out.BindInterface = iface
+	}
+	return &out
+}
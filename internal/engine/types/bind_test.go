@@ -0,0 +1,40 @@
+package types
+
+import "testing"
+
+func TestBindRuntime_Precedence(t *testing.T) {
+	base := &RuntimeConfig{BindInterface: "eth1", BindRules: HostRules[string]{"example.com": "tun0"}}
+
+	if got := BindRuntime(base, "", "https://other.org/a.iso").BindInterface; got != "eth1" {
+		t.Errorf("unmatched host = %q, want the global eth1", got)
+	}
+	if got := BindRuntime(base, "", "https://example.com/a.iso").BindInterface; got != "tun0" {
+		t.Errorf("matched host = %q, want the rule's tun0", got)
+	}
+	if got := BindRuntime(base, "wg0", "https://example.com/a.iso").BindInterface; got != "wg0" {
+		t.Errorf("per-download = %q, want wg0", got)
+	}
+	if base.BindInterface != "eth1" {
+		t.Error("BindRuntime changed the runtime it was given")
+	}
+	if got := BindRuntime(nil, "", "https://example.com/a.iso").BindInterface; got != "" {
+		t.Errorf("nil runtime = %q, want no binding", got)
+	}
+}
+
+func TestSplitBindInterfaces(t *testing.T) {
+	if got := SplitBindInterfaces(" wlan0 + eth0 "); len(got) != 2 || got[0] != "wlan0" || got[1] != "eth0" {
+		t.Errorf("SplitBindInterfaces = %q, want [wlan0 eth0]", got)
+	}
+	if got := SplitBindInterfaces(""); got != nil {
+		t.Errorf("SplitBindInterfaces(\"\") = %q, want nil", got)
+	}
+	if err := ValidateBindInterface("wlan0+10.0.0.2"); err != nil {
+		t.Errorf("ValidateBindInterface(wlan0+10.0.0.2) = %v", err)
+	}
+	for _, iface := range []string{"wlan0+", "+eth0", "wlan0++eth0"} {
+		if err := ValidateBindInterface(iface); err == nil {
+			t.Errorf("ValidateBindInterface(%q) succeeded, want an error", iface)
+		}
+	}
+}
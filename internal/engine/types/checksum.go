@@ -0,0 +1,44 @@
+package types
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// checksumHexLengths maps the algorithms a download can be checked against to
+// the length of their hex digest.
+var checksumHexLengths = map[string]int{
+	"md5":    32,
+	"sha1":   40,
+	"sha256": 64,
+	"sha512": 128,
+}
+
+// ParseChecksum splits an expected checksum of the form algorithm:hex, such
+// as sha256:9f86d0..., into its lower-cased parts. A bare hex digest is
+// accepted when its length identifies the algorithm.
+func ParseChecksum(s string) (algorithm, digest string, err error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	algorithm, digest, found := strings.Cut(s, ":")
+	if !found {
+		algorithm, digest = "", s
+		for name, n := range checksumHexLengths {
+			if len(digest) == n {
+				algorithm = name
+			}
+		}
+		if algorithm == "" {
+			return "", "", fmt.Errorf("checksum %q: give it as algorithm:hex, e.g. sha256:<hex>", s)
+		}
+	}
+
+	n, ok := checksumHexLengths[algorithm]
+	if !ok {
+		return "", "", fmt.Errorf("checksum algorithm %q is not supported (use md5, sha1, sha256 or sha512)", algorithm)
+	}
+	if _, err := hex.DecodeString(digest); err != nil || len(digest) != n {
+		return "", "", fmt.Errorf("checksum %q is not a %d-character hex %s digest", digest, n, algorithm)
+	}
+	return algorithm, digest, nil
+}
@@ -0,0 +1,32 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseChecksum(t *testing.T) {
+	sha256Hex := strings.Repeat("ab", 32)
+	tests := []struct {
+		in, algorithm, digest string
+	}{
+		{"sha256:" + sha256Hex, "sha256", sha256Hex},
+		{" SHA256:" + strings.ToUpper(sha256Hex) + " ", "sha256", sha256Hex},
+		{sha256Hex, "sha256", sha256Hex},
+		{strings.Repeat("0", 32), "md5", strings.Repeat("0", 32)},
+		{"sha1:" + strings.Repeat("f", 40), "sha1", strings.Repeat("f", 40)},
+		{"sha512:" + strings.Repeat("1", 128), "sha512", strings.Repeat("1", 128)},
+	}
+	for _, tt := range tests {
+		algorithm, digest, err := ParseChecksum(tt.in)
+		if err != nil || algorithm != tt.algorithm || digest != tt.digest {
+			t.Errorf("ParseChecksum(%q) = %q, %q, %v; want %q, %q", tt.in, algorithm, digest, err, tt.algorithm, tt.digest)
+		}
+	}
+
+	for _, in := range []string{"", "abc", "crc32:deadbeef", "sha256:" + strings.Repeat("zz", 32), "md5:" + sha256Hex} {
+		if _, _, err := ParseChecksum(in); err == nil {
+			t.Errorf("ParseChecksum(%q) should fail", in)
+		}
+	}
+}
@@ -21,8 +21,10 @@ const (
 	WorkerBatchSize     = 1 * MB
 	WorkerBatchInterval = 200 * time.Millisecond
 
-	PerDownloadMax = 32
-	DialHedgeCount = 4
+	PerDownloadMax   = 32
+	DialHedgeCount   = 4
+	MirrorHedgeCount = 0
+	MaxRedirects     = 10
 
 	DefaultMaxIdleConns          = 100
 	DefaultIdleConnTimeout       = 90 * time.Second
@@ -40,12 +42,58 @@ const (
 	MaxTaskRetries = 3
 	RetryBaseDelay = 200 * time.Millisecond
 
+	// MaxThrottleRetries is how many 429/503 responses a request absorbs
+	// before the failure counts against its normal retries.
+	MaxThrottleRetries = 8
+	// MaxRetryAfter caps the delay honored from a Retry-After header.
+	MaxRetryAfter = 5 * time.Minute
+	// ThrottleCooldown is how long a host keeps its reduced connection count
+	// after its Retry-After window ends.
+	ThrottleCooldown = 30 * time.Second
+
 	HealthCheckInterval = 1 * time.Second
 	SlowWorkerThreshold = 0.30
 	SlowWorkerGrace     = 5 * time.Second
 	StallTimeout        = 3 * time.Second
 	SpeedEMAAlpha       = 0.3
 
+	// SleepJumpThreshold is how far the wall clock must run ahead of the
+	// monotonic clock between two health checks for the machine to be taken
+	// as having slept in between.
+	SleepJumpThreshold = 10 * time.Second
+
+	// AuthFailureThreshold answers of 401 or 403 from one host within
+	// AuthFailureWindow pause every download from that host.
+	AuthFailureThreshold = 3
+	AuthFailureWindow    = 30 * time.Second
+
+	// ProxyFailureThreshold failed connections through the active proxy
+	// within ProxyFailureWindow move downloads on to the next proxy of the
+	// failover list. ProxyRetryPrimary is how long they stay on a fallback
+	// before the primary is tried again.
+	ProxyFailureThreshold = 3
+	ProxyFailureWindow    = time.Minute
+	ProxyRetryPrimary     = 5 * time.Minute
+
+	// PipelineReadAhead is how close to the end of its current range a worker
+	// gets before it issues the request for its next range.
+	PipelineReadAhead = 1 * MB
+
+	// EarlyRampSize is how much of the file the probe request fetches when
+	// early ramp is enabled; files up to this size finish with the probe.
+	EarlyRampSize = 256 * KB
+
+	// SmallFileThreshold is the size below which a fresh download skips
+	// chunking and preallocation and streams over a single connection.
+	SmallFileThreshold = 8 * MB
+
+	// FollowStableWindow is how long a followed download's remote size must
+	// stay unchanged before the download is considered finished.
+	FollowStableWindow = 30 * time.Second
+	// FollowPollInterval is the longest wait between size checks of a
+	// followed download.
+	FollowPollInterval = 5 * time.Second
+
 	ProgressChannelBuffer = 100
 )
 
@@ -62,7 +110,17 @@ type DownloadConfig struct {
 	Runtime    *RuntimeConfig
 	Mirrors    []string
 	Headers    map[string]string
+	Request    RequestOptions
+	S3         S3Object
 	Limiter    ByteLimiter
+	Budget     ConnectionGate
+
+	// TLS is the request's override of Runtime.TLS, kept apart from it so a
+	// pause saves only what the request asked for.
+	TLS TLSOptions
+
+	// Probe is what the lifecycle's probe left for a fresh download.
+	Probe ProbeHandoff
 
 	IsExplicitCategory bool
 	TotalSize          int64
@@ -76,20 +134,44 @@ type ByteLimiter interface {
 	WaitN(ctx context.Context, n int64) error
 }
 
+// ConnectionGate holds a download's connections within its share of a
+// budget shared with other downloads.
+type ConnectionGate interface {
+	Acquire(ctx context.Context) error
+	Release()
+}
+
 // RuntimeConfig carries network and downloader tuning knobs.
 // Fields used by the downloader getters fall into two groups:
 // zero means "use package default" for capacity-style settings such as
 // connections, chunk size, buffer size, and retries; zero is preserved for
 // opt-out settings where disabling a behavior is meaningful.
 type RuntimeConfig struct {
-	MaxConnectionsPerDownload   int
-	UserAgent                   string
-	ProxyURL                    string
-	CustomDNS                   string
+	MaxConnectionsPerDownload int
+	UserAgent                 string
+	ProxyURL                  string
+	// ProxyFallbacks are comma-separated proxies downloads fail over to,
+	// in order, when ProxyURL keeps failing.
+	ProxyFallbacks string
+	// IgnoreSystemProxy skips the proxy set in the Windows or macOS network
+	// settings when ProxyURL is empty.
+	IgnoreSystemProxy bool
+	CustomDNS         string
+	// BindInterface is the interface name or local IP downloads connect
+	// from, and BindRules picks one per host. BindRuntime resolves them for
+	// a single download.
+	BindInterface string
+	BindRules     HostRules[string]
+	// SourcePorts limits the local ports outbound connections use, in the
+	// form ParsePortRanges accepts; empty lets the system choose.
+	SourcePorts                 string
 	SequentialDownload          bool
 	MinChunkSize                int64
 	GlobalRateLimitBps          int64
 	DefaultDownloadRateLimitBps int64
+	// UploadRateLimitBps caps the bytes all connections send; zero is
+	// unlimited.
+	UploadRateLimitBps int64
 
 	WorkerBufferSize      int
 	MaxTaskRetries        int
@@ -98,6 +180,76 @@ type RuntimeConfig struct {
 	SlowWorkerGracePeriod time.Duration
 	StallTimeout          time.Duration
 	SpeedEmaAlpha         float64
+	PipelineRequests      bool
+	EarlyRamp             bool
+	SmallFileThreshold    int64
+	MirrorHedgeCount      int
+	FollowStableWindow    time.Duration
+
+	MaxRedirects            int
+	BlockCrossHostRedirects bool
+	StripAuthOnRedirect     bool
+
+	// ServerModTime sets a completed file's modification time to the
+	// server's Last-Modified
+	ServerModTime bool
+
+	// SignatureKeys are the comma-separated public key files signatures
+	// are trusted from. VerifySignatures looks for a signature next to
+	// every download, not only those that ask for one.
+	SignatureKeys    string
+	VerifySignatures bool
+
+	TLS TLSOptions
+
+	// ConnectTimeout and ResponseHeaderTimeout bound dialing a server and
+	// waiting for a response's headers; zero uses DialTimeout and
+	// DefaultResponseHeaderTimeout. MaxDuration fails a download that runs
+	// longer; zero lets it run as long as it takes.
+	ConnectTimeout        time.Duration
+	ResponseHeaderTimeout time.Duration
+	MaxDuration           time.Duration
+}
+
+// TLSOptions controls how download connections verify servers and identify
+// themselves. The zero value uses the system roots and no client certificate.
+type TLSOptions struct {
+	// CAFile is a PEM bundle trusted in addition to the system roots.
+	CAFile string `json:"ca_file,omitempty"`
+	// ClientCertFile and ClientKeyFile hold a PEM certificate and key
+	// presented to servers that require mutual TLS.
+	ClientCertFile string `json:"client_cert,omitempty"`
+	ClientKeyFile  string `json:"client_key,omitempty"`
+	// Insecure disables server certificate verification.
+	Insecure bool `json:"insecure,omitempty"`
+	// Pins lists SPKI pins per host in the form ParseTLSPins accepts. Pinned
+	// hosts must present a matching key even when Insecure is set.
+	Pins string `json:"pins,omitempty"`
+}
+
+// IsZero reports whether o leaves TLS at its defaults.
+func (o TLSOptions) IsZero() bool {
+	return o == TLSOptions{}
+}
+
+// Merge returns o with every field that override sets replaced.
+func (o TLSOptions) Merge(override TLSOptions) TLSOptions {
+	if override.CAFile != "" {
+		o.CAFile = override.CAFile
+	}
+	if override.ClientCertFile != "" {
+		o.ClientCertFile = override.ClientCertFile
+	}
+	if override.ClientKeyFile != "" {
+		o.ClientKeyFile = override.ClientKeyFile
+	}
+	if override.Insecure {
+		o.Insecure = true
+	}
+	if override.Pins != "" {
+		o.Pins = override.Pins
+	}
+	return o
 }
 
 const DefaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
@@ -144,6 +296,15 @@ func (r *RuntimeConfig) GetDialHedgeCount() int {
 	return r.DialHedgeCount
 }
 
+// GetMirrorHedgeCount returns how many mirrors a worker's first request is
+// raced across. Values below 2 disable the race.
+func (r *RuntimeConfig) GetMirrorHedgeCount() int {
+	if r == nil || r.MirrorHedgeCount < 0 {
+		return MirrorHedgeCount
+	}
+	return r.MirrorHedgeCount
+}
+
 func (r *RuntimeConfig) GetSlowWorkerThreshold() float64 {
 	if r == nil || r.SlowWorkerThreshold < 0 || r.SlowWorkerThreshold > 1 {
 		return SlowWorkerThreshold
@@ -165,6 +326,32 @@ func (r *RuntimeConfig) GetStallTimeout() time.Duration {
 	return r.StallTimeout
 }
 
+// GetConnectTimeout returns how long dialing a server may take.
+func (r *RuntimeConfig) GetConnectTimeout() time.Duration {
+	if r == nil || r.ConnectTimeout <= 0 {
+		return DialTimeout
+	}
+	return r.ConnectTimeout
+}
+
+// GetResponseHeaderTimeout returns how long a request may wait for the
+// headers of its response.
+func (r *RuntimeConfig) GetResponseHeaderTimeout() time.Duration {
+	if r == nil || r.ResponseHeaderTimeout <= 0 {
+		return DefaultResponseHeaderTimeout
+	}
+	return r.ResponseHeaderTimeout
+}
+
+// GetMaxDuration returns how long a download may run before it fails, or
+// zero when it may run as long as it takes.
+func (r *RuntimeConfig) GetMaxDuration() time.Duration {
+	if r == nil || r.MaxDuration <= 0 {
+		return 0
+	}
+	return r.MaxDuration
+}
+
 func (r *RuntimeConfig) GetSpeedEmaAlpha() float64 {
 	if r == nil || r.SpeedEmaAlpha < 0 || r.SpeedEmaAlpha > 1 {
 		return SpeedEMAAlpha
@@ -172,6 +359,64 @@ func (r *RuntimeConfig) GetSpeedEmaAlpha() float64 {
 	return r.SpeedEmaAlpha
 }
 
+// GetPipelineRequests reports whether workers should issue their next range
+// request while the current response is still draining.
+func (r *RuntimeConfig) GetPipelineRequests() bool {
+	return r != nil && r.PipelineRequests
+}
+
+// GetEarlyRamp reports whether the probe should double as the first data
+// request for the download.
+func (r *RuntimeConfig) GetEarlyRamp() bool {
+	return r != nil && r.EarlyRamp
+}
+
+// GetSmallFileThreshold returns the size below which downloads take the
+// single-connection fast path. Zero disables the fast path.
+func (r *RuntimeConfig) GetSmallFileThreshold() int64 {
+	if r == nil || r.SmallFileThreshold <= 0 {
+		return 0
+	}
+	return r.SmallFileThreshold
+}
+
+// GetFollowStableWindow returns how long a followed download waits without
+// growth before it finishes.
+func (r *RuntimeConfig) GetFollowStableWindow() time.Duration {
+	if r == nil || r.FollowStableWindow <= 0 {
+		return FollowStableWindow
+	}
+	return r.FollowStableWindow
+}
+
+// GetMaxRedirects returns how many redirects a request may follow.
+func (r *RuntimeConfig) GetMaxRedirects() int {
+	if r == nil || r.MaxRedirects <= 0 {
+		return MaxRedirects
+	}
+	return r.MaxRedirects
+}
+
+// GetBlockCrossHostRedirects reports whether redirects to a different host
+// should fail the request instead of being followed.
+func (r *RuntimeConfig) GetBlockCrossHostRedirects() bool {
+	return r != nil && r.BlockCrossHostRedirects
+}
+
+// GetStripAuthOnRedirect reports whether credential headers are withheld when
+// a redirect leaves the original host.
+func (r *RuntimeConfig) GetStripAuthOnRedirect() bool {
+	return r != nil && r.StripAuthOnRedirect
+}
+
+// GetTLSOptions returns the TLS settings for download connections.
+func (r *RuntimeConfig) GetTLSOptions() TLSOptions {
+	if r == nil {
+		return TLSOptions{}
+	}
+	return r.TLS
+}
+
 // DefaultRuntimeConfig returns a fully-populated runtime config for callers
 // that want engine defaults rather than relying on zero-value semantics.
 func DefaultRuntimeConfig() *RuntimeConfig {
@@ -191,5 +436,10 @@ func DefaultRuntimeConfig() *RuntimeConfig {
 		SlowWorkerGracePeriod:       SlowWorkerGrace,
 		StallTimeout:                StallTimeout,
 		SpeedEmaAlpha:               SpeedEMAAlpha,
+		SmallFileThreshold:          SmallFileThreshold,
+		MirrorHedgeCount:            MirrorHedgeCount,
+		FollowStableWindow:          FollowStableWindow,
+		MaxRedirects:                MaxRedirects,
+		ServerModTime:               true,
 	}
 }
@@ -280,3 +280,22 @@ func TestDownloadConfig_Fields(t *testing.T) {
 		t.Error("Runtime not set correctly")
 	}
 }
+
+func TestTLSOptions_Merge(t *testing.T) {
+	global := TLSOptions{CAFile: "/etc/corp-ca.pem", ClientCertFile: "/etc/me.crt", ClientKeyFile: "/etc/me.key"}
+
+	if got := global.Merge(TLSOptions{}); got != global {
+		t.Errorf("empty override changed options: %+v", got)
+	}
+
+	got := global.Merge(TLSOptions{CAFile: "/tmp/other-ca.pem", Insecure: true})
+	want := TLSOptions{CAFile: "/tmp/other-ca.pem", ClientCertFile: "/etc/me.crt", ClientKeyFile: "/etc/me.key", Insecure: true}
+	if got != want {
+		t.Errorf("Merge = %+v, want %+v", got, want)
+	}
+
+	var nilCfg *RuntimeConfig
+	if !nilCfg.GetTLSOptions().IsZero() {
+		t.Error("nil runtime config should use default TLS options")
+	}
+}
@@ -0,0 +1,121 @@
+package types
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ErrorCode sorts the error a download failed with into a kind clients can
+// act on or filter by, without parsing its message.
+type ErrorCode string
+
+const (
+	ErrorCodeDNS       ErrorCode = "dns"       // The host name did not resolve
+	ErrorCodeTLS       ErrorCode = "tls"       // Handshake or certificate failed
+	ErrorCodeHTTP4xx   ErrorCode = "http_4xx"  // The server refused the request
+	ErrorCodeHTTP5xx   ErrorCode = "http_5xx"  // The server failed to answer it
+	ErrorCodeNetwork   ErrorCode = "network"   // Connection refused, reset or timed out
+	ErrorCodeDisk      ErrorCode = "disk"      // Writing the file failed
+	ErrorCodeChecksum  ErrorCode = "checksum"  // The file failed a checksum or signature
+	ErrorCodeCancelled ErrorCode = "cancelled" // The download was stopped
+	ErrorCodeUnknown   ErrorCode = "unknown"
+)
+
+// HTTPStatusError is returned when a server answers with a status the
+// download cannot continue from.
+type HTTPStatusError struct {
+	StatusCode int
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+// statusPattern finds the status in errors that only kept their message,
+// such as those decoded from an older server's events.
+var statusPattern = regexp.MustCompile(`(?:status code|status): (\d{3})\b`)
+
+// ClassifyError returns the ErrorCode of err, or "" for a nil err. Errors
+// that lost their type on the way, such as those read back from JSON, are
+// classified by their message.
+func ClassifyError(err error) ErrorCode {
+	if err == nil {
+		return ""
+	}
+
+	var statusErr *HTTPStatusError
+	var dnsErr *net.DNSError
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidCert x509.CertificateInvalidError
+	var recordErr tls.RecordHeaderError
+	var alertErr tls.AlertError
+	var netErr net.Error
+	var pathErr *fs.PathError
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ErrorCodeCancelled
+	case errors.As(err, &statusErr):
+		return statusClass(statusErr.StatusCode)
+	case errors.As(err, &dnsErr):
+		return ErrorCodeDNS
+	case errors.Is(err, ErrCertificatePin), errors.As(err, &certErr), errors.As(err, &unknownAuthority),
+		errors.As(err, &hostnameErr), errors.As(err, &invalidCert), errors.As(err, &recordErr), errors.As(err, &alertErr):
+		return ErrorCodeTLS
+	case errors.Is(err, ErrChecksumMismatch), errors.Is(err, ErrExpectedChecksum),
+		errors.Is(err, ErrBadSignature), errors.Is(err, ErrUntrustedSignature):
+		return ErrorCodeChecksum
+	case errors.Is(err, syscall.ENOSPC), errors.Is(err, syscall.EDQUOT), errors.Is(err, syscall.EROFS), errors.As(err, &pathErr):
+		return ErrorCodeDisk
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET), errors.As(err, &netErr):
+		return ErrorCodeNetwork
+	}
+	return classifyMessage(err.Error())
+}
+
+func classifyMessage(msg string) ErrorCode {
+	lower := strings.ToLower(msg)
+	if m := statusPattern.FindStringSubmatch(lower); m != nil {
+		status, _ := strconv.Atoi(m[1])
+		if code := statusClass(status); code != ErrorCodeUnknown {
+			return code
+		}
+	}
+	switch {
+	case strings.Contains(lower, "no such host"):
+		return ErrorCodeDNS
+	case strings.Contains(lower, "x509:"), strings.Contains(lower, "tls:"), strings.Contains(lower, ErrCertificatePin.Error()):
+		return ErrorCodeTLS
+	case strings.Contains(lower, "checksum"), strings.Contains(lower, "signature"):
+		return ErrorCodeChecksum
+	case strings.Contains(lower, "no space left"), strings.Contains(lower, "disk quota"), strings.Contains(lower, "read-only file system"):
+		return ErrorCodeDisk
+	case strings.Contains(lower, "connection refused"), strings.Contains(lower, "connection reset"),
+		strings.Contains(lower, "timeout"):
+		return ErrorCodeNetwork
+	case strings.Contains(lower, "context canceled"):
+		return ErrorCodeCancelled
+	}
+	return ErrorCodeUnknown
+}
+
+func statusClass(status int) ErrorCode {
+	switch {
+	case status >= 400 && status < 500:
+		return ErrorCodeHTTP4xx
+	case status >= 500 && status < 600:
+		return ErrorCodeHTTP5xx
+	default:
+		return ErrorCodeUnknown
+	}
+}
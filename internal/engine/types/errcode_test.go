@@ -0,0 +1,38 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"syscall"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorCode
+	}{
+		{"nil", nil, ""},
+		{"not found", fmt.Errorf("worker: %w", &HTTPStatusError{StatusCode: 404}), ErrorCodeHTTP4xx},
+		{"bad gateway", &HTTPStatusError{StatusCode: 502}, ErrorCodeHTTP5xx},
+		{"dns", &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "example.invalid"}}, ErrorCodeDNS},
+		{"pin", fmt.Errorf("handshake: %w", ErrCertificatePin), ErrorCodeTLS},
+		{"checksum", ErrExpectedChecksum, ErrorCodeChecksum},
+		{"disk full", &fs.PathError{Op: "write", Path: "/tmp/x", Err: syscall.ENOSPC}, ErrorCodeDisk},
+		{"reset", &net.OpError{Op: "read", Err: syscall.ECONNRESET}, ErrorCodeNetwork},
+		{"paused", ErrUserPause, ErrorCodeCancelled},
+		{"message only", errors.New("unexpected status: 403"), ErrorCodeHTTP4xx},
+		{"tls message", errors.New("tls: failed to verify certificate: x509: certificate has expired"), ErrorCodeTLS},
+		{"other", errors.New("something odd"), ErrorCodeUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.want {
+				t.Fatalf("ClassifyError(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,12 @@
 package types
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/SurgeDM/Surge/internal/utils"
+)
 
 // Common errors
 var (
@@ -16,5 +22,77 @@ var (
 	ErrServiceUnavailable = errors.New("service unavailable")
 	ErrQueuedUpdate       = errors.New("cannot update URL for a queued download, please cancel or wait for it to start")
 	ErrActiveUpdate       = errors.New("download is currently active, please pause it before updating the URL")
-	ErrMaxRedirects       = errors.New("stopped after 10 redirects")
+	ErrActiveHeaderUpdate = errors.New("download is currently active, please pause it before updating its headers")
+	ErrMaxRedirects       = errors.New("stopped after too many redirects")
+	ErrCrossHostRedirect  = errors.New("redirect to another host is not allowed")
+	ErrCertificatePin     = errors.New("server certificate does not match the pinned key")
+	ErrURLExpired         = errors.New("presigned URL has expired")
+	ErrChecksumMismatch   = errors.New("downloaded file does not match the server's checksum")
+	ErrExpectedChecksum   = errors.New("downloaded file does not match the expected checksum")
+	ErrBadSignature       = errors.New("downloaded file does not match its signature")
+	ErrUntrustedSignature = errors.New("signature is not from a trusted key")
+	ErrSignatureNotFound  = errors.New("no signature found for the download")
+	ErrAliasTaken         = errors.New("alias is already used by an unfinished download")
+	ErrResumeMismatch     = errors.New("remote file changed since the download was paused, restart it from the beginning")
+	ErrMaxDuration        = errors.New("download ran longer than its max duration")
+	ErrNeedsConfirmation  = errors.New("download needs confirmation")
+	ErrPluginVeto         = errors.New("download refused by a plugin")
+	ErrNotResumable       = errors.New("download was sent with a request body that is not saved, add it again to restart it")
+)
+
+// Cancellation causes. A download's context is canceled with one of these so
+// that, through context.Cause, the engine can tell a stop whose progress
+// must be saved from one whose progress is thrown away. Each wraps
+// context.Canceled.
+var (
+	ErrUserPause    = fmt.Errorf("paused by user: %w", context.Canceled)
+	ErrUserDelete   = fmt.Errorf("deleted by user: %w", context.Canceled)
+	ErrShutdown     = fmt.Errorf("paused for shutdown: %w", context.Canceled)
+	ErrStallTimeout = fmt.Errorf("no data received within the stall timeout: %w", context.Canceled)
+	ErrSystemWake   = fmt.Errorf("connection reset after the system woke from sleep: %w", context.Canceled)
+	ErrAuthExpired  = fmt.Errorf("server keeps refusing the credentials: %w", context.Canceled)
+	// ErrUnexpectedHTML pauses a download whose server answered with a web
+	// page, such as a login page, where its URL names a binary file.
+	ErrUnexpectedHTML = fmt.Errorf("server sent a web page instead of the file: %w", context.Canceled)
+	// ErrQuotaReached pauses a download started once the month's traffic
+	// has used up monthly_quota.
+	ErrQuotaReached = fmt.Errorf("monthly quota reached: %w", context.Canceled)
 )
+
+// AuthExpiredError is the cause downloads from Host are paused with once it
+// has answered several requests with 401 or 403 in a short time, which
+// usually means the session in their headers has expired.
+type AuthExpiredError struct {
+	Host   string
+	Status int
+}
+
+func (e *AuthExpiredError) Error() string {
+	return fmt.Sprintf("%s keeps answering %d, the session has likely expired", e.Host, e.Status)
+}
+
+func (e *AuthExpiredError) Unwrap() error { return ErrAuthExpired }
+
+// LargeDownloadError rejects a download larger than the confirm_size_threshold
+// setting that was not sent confirmed.
+type LargeDownloadError struct {
+	Size      int64
+	Threshold int64
+}
+
+func (e *LargeDownloadError) Error() string {
+	return fmt.Sprintf("file is %s, over the confirm_size_threshold of %s; add it again confirmed to download it",
+		utils.ConvertBytesToHumanReadable(e.Size), utils.ConvertBytesToHumanReadable(e.Threshold))
+}
+
+func (e *LargeDownloadError) Unwrap() error { return ErrNeedsConfirmation }
+
+// IsPauseCause reports whether a download canceled with cause stops in a way
+// that keeps its progress for a later resume: a pause by the user, a
+// shutdown, a pause to wait for an expired URL or session to be refreshed,
+// one to confirm a web page is what was wanted, or one held back by
+// monthly_quota.
+func IsPauseCause(cause error) bool {
+	return errors.Is(cause, ErrUserPause) || errors.Is(cause, ErrShutdown) || errors.Is(cause, ErrURLExpired) ||
+		errors.Is(cause, ErrAuthExpired) || errors.Is(cause, ErrUnexpectedHTML) || errors.Is(cause, ErrQuotaReached)
+}
@@ -0,0 +1,44 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HostRules maps a host rule to the value used for hosts it covers. A rule
+// is either an exact host name or "*.example.com", which covers every
+// subdomain of example.com but not example.com itself.
+type HostRules[V any] map[string]V
+
+// ParseHostRule returns host lowercased as a rule, or an error when it holds
+// a * anywhere but a leading "*.".
+func ParseHostRule(host string) (string, error) {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if host == "" {
+		return "", fmt.Errorf("empty host")
+	}
+	if strings.Contains(strings.TrimPrefix(host, "*."), "*") {
+		return "", fmt.Errorf("invalid host %q: only a leading *. is allowed", host)
+	}
+	return host, nil
+}
+
+// ForHost returns the value of the rule that covers host. An exact rule wins
+// over a wildcard one, and a closer wildcard over a wider one.
+func (r HostRules[V]) ForHost(host string) (V, bool) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if v, ok := r[host]; ok {
+		return v, true
+	}
+	for parent := host; ; {
+		_, rest, ok := strings.Cut(parent, ".")
+		if !ok || rest == "" {
+			var zero V
+			return zero, false
+		}
+		if v, ok := r["*."+rest]; ok {
+			return v, true
+		}
+		parent = rest
+	}
+}
@@ -0,0 +1,36 @@
+package types
+
+import "testing"
+
+func TestParseHostRule(t *testing.T) {
+	if got, err := ParseHostRule(" *.Example.com "); err != nil || got != "*.example.com" {
+		t.Fatalf("ParseHostRule = (%q, %v), want *.example.com", got, err)
+	}
+	for _, bad := range []string{"", "a.*.com", "*example.com", "*.*.com"} {
+		if _, err := ParseHostRule(bad); err == nil {
+			t.Errorf("ParseHostRule(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestHostRules_ForHost(t *testing.T) {
+	rules := HostRules[string]{
+		"example.com":       "exact",
+		"*.example.com":     "wide",
+		"*.dl.example.com":  "close",
+		"other.example.net": "other",
+	}
+	for host, want := range map[string]string{
+		"Example.com.":          "exact",
+		"www.example.com":       "wide",
+		"eu.dl.example.com":     "close",
+		"dl.example.com":        "wide",
+		"example.net":           "",
+		"sub.other.example.net": "",
+	} {
+		got, ok := rules.ForHost(host)
+		if got != want || ok != (want != "") {
+			t.Errorf("ForHost(%q) = (%q, %v), want %q", host, got, ok, want)
+		}
+	}
+}
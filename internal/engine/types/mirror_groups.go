@@ -0,0 +1,77 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// MirrorGroups lists sets of base URLs that serve the same tree of files,
+// such as the mirrors of a Linux distribution.
+type MirrorGroups [][]string
+
+// ParseMirrorGroups parses groups separated by semicolons, each a
+// comma-separated list of at least two http(s) base URLs, e.g.
+// "https://deb.debian.org/debian, https://ftp.us.debian.org/debian".
+func ParseMirrorGroups(spec string) (MirrorGroups, error) {
+	var groups MirrorGroups
+	for _, rawGroup := range strings.Split(spec, ";") {
+		var group []string
+		for _, member := range strings.Split(rawGroup, ",") {
+			member = strings.TrimRight(strings.TrimSpace(member), "/")
+			if member == "" {
+				continue
+			}
+			u, err := url.Parse(member)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return nil, fmt.Errorf("invalid mirror %q: want an http(s) base URL", member)
+			}
+			if u.RawQuery != "" || u.Fragment != "" {
+				return nil, fmt.Errorf("invalid mirror %q: base URLs cannot have a query or fragment", member)
+			}
+			group = append(group, member)
+		}
+		switch len(group) {
+		case 0:
+			continue
+		case 1:
+			return nil, fmt.Errorf("mirror group %q needs at least two members", strings.TrimSpace(rawGroup))
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// Mirrors returns rawurl rewritten onto every other member of the group whose
+// base URL it falls under. When several members match, the longest wins. It
+// returns nil when rawurl is not in any group.
+func (g MirrorGroups) Mirrors(rawurl string) []string {
+	var group []string
+	var base string
+	for _, members := range g {
+		for _, member := range members {
+			if len(member) > len(base) && underBase(rawurl, member) {
+				group, base = members, member
+			}
+		}
+	}
+	if group == nil {
+		return nil
+	}
+
+	rest := rawurl[len(base):]
+	mirrors := make([]string, 0, len(group)-1)
+	for _, member := range group {
+		if member != base {
+			mirrors = append(mirrors, member+rest)
+		}
+	}
+	return mirrors
+}
+
+// underBase reports whether rawurl is base itself or a path below it, so
+// "https://example.com/debian" does not match ".../debian-security".
+func underBase(rawurl, base string) bool {
+	rest, ok := strings.CutPrefix(rawurl, base)
+	return ok && (rest == "" || rest[0] == '/' || rest[0] == '?')
+}
@@ -0,0 +1,59 @@
+package types
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseMirrorGroups(t *testing.T) {
+	groups, err := ParseMirrorGroups(" https://a.example/debian/ ,https://b.example/debian;; http://c.example/x, http://d.example ")
+	if err != nil {
+		t.Fatalf("ParseMirrorGroups: %v", err)
+	}
+	want := MirrorGroups{
+		{"https://a.example/debian", "https://b.example/debian"},
+		{"http://c.example/x", "http://d.example"},
+	}
+	if len(groups) != len(want) || !slices.Equal(groups[0], want[0]) || !slices.Equal(groups[1], want[1]) {
+		t.Errorf("groups = %v, want %v", groups, want)
+	}
+
+	if groups, err := ParseMirrorGroups(""); err != nil || len(groups) != 0 {
+		t.Errorf("empty spec = %v, %v", groups, err)
+	}
+	for _, spec := range []string{
+		"https://a.example/debian",                         // lone member
+		"ftp://a.example/debian, https://b.example/debian", // not http(s)
+		"https://a.example/?x=1, https://b.example/",       // query
+		"a.example/debian, b.example/debian",               // no scheme
+	} {
+		if _, err := ParseMirrorGroups(spec); err == nil {
+			t.Errorf("ParseMirrorGroups(%q) should fail", spec)
+		}
+	}
+}
+
+func TestMirrorGroups_Mirrors(t *testing.T) {
+	groups, err := ParseMirrorGroups("https://a.example/debian, https://b.example/pub/debian, https://c.example; https://a.example/debian/security, https://s.example/security")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := groups.Mirrors("https://a.example/debian/pool/hello.deb")
+	want := []string{"https://b.example/pub/debian/pool/hello.deb", "https://c.example/pool/hello.deb"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Mirrors = %v, want %v", got, want)
+	}
+
+	// The longest matching base wins.
+	got = groups.Mirrors("https://a.example/debian/security/x.deb")
+	if want := []string{"https://s.example/security/x.deb"}; !slices.Equal(got, want) {
+		t.Errorf("Mirrors = %v, want %v", got, want)
+	}
+
+	for _, rawurl := range []string{"https://a.example/debian-ports/x.deb", "https://z.example/debian/x.deb"} {
+		if got := groups.Mirrors(rawurl); got != nil {
+			t.Errorf("Mirrors(%q) = %v, want nil", rawurl, got)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package types
 
-import "sync/atomic"
+import (
+	"sync/atomic"
+	"time"
+)
 
 // Task represents a byte range to download.
 type Task struct {
@@ -30,6 +33,40 @@ type DownloadState struct {
 	FileHash     string `json:"file_hash,omitempty"`
 	RateLimit    int64  `json:"rate_limit,omitempty"`
 	RateLimitSet bool   `json:"rate_limit_set,omitempty"`
+
+	// FinalURL is where URL redirected to when the download last ran, so a
+	// resume can go straight to the resolved endpoint.
+	FinalURL string `json:"final_url,omitempty"`
+
+	// S3 keeps the object's part layout and checksum so a resume stays aligned
+	// to parts and can still verify the result.
+	S3 S3Object `json:"s3,omitzero"`
+
+	// Headers and Checksum are the request's custom headers and expected
+	// digest, so a resume after a restart authenticates and verifies the
+	// file the same way.
+	Headers  map[string]string `json:"headers,omitempty"`
+	Checksum string            `json:"checksum,omitempty"`
+
+	// Copies are the directories the file is also written to.
+	Copies []string `json:"copies,omitempty"`
+
+	// SignatureURL is the request's signature URL, so a resume still
+	// verifies the file.
+	SignatureURL string `json:"signature_url,omitempty"`
+
+	// Interface is the interface the request asked to connect from, so a
+	// resume does not fall back to another route.
+	Interface string `json:"interface,omitempty"`
+
+	// TLS is the request's override of the TLS settings, so a resume after a
+	// restart still trusts the same CA and presents the same client
+	// certificate.
+	TLS TLSOptions `json:"tls,omitzero"`
+
+	// Fetched marks a download paused while its finished file was being
+	// verified; a resume only verifies it again.
+	Fetched bool `json:"fetched,omitempty"`
 }
 
 // DownloadEntry is the durable record used for history and lifecycle recovery.
@@ -48,6 +85,23 @@ type DownloadEntry struct {
 	Mirrors      []string `json:"mirrors,omitempty"`
 	RateLimit    int64    `json:"rate_limit,omitempty"`
 	RateLimitSet bool     `json:"rate_limit_set,omitempty"`
+	Alias        string   `json:"alias,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	// Verified is set when the completed file matched a signature from a
+	// trusted key.
+	Verified bool `json:"verified,omitempty"`
+	// Checksum is the digest the file was checked against, as algorithm:hex.
+	Checksum string `json:"checksum,omitempty"`
+	// ResponseHeaders holds the notable headers the server answered the
+	// probe with.
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+	// Redirects are the URLs the probe was redirected through, in order,
+	// ending with the one the file came from.
+	Redirects []string `json:"redirects,omitempty"`
+	// Method is the request method of a download that is not a plain GET.
+	// Its body is not stored, so such a download cannot be resumed in a
+	// later session.
+	Method string `json:"method,omitempty"`
 }
 
 // MasterList holds all tracked downloads.
@@ -57,23 +111,48 @@ type MasterList struct {
 
 // DownloadStatus is the transient view returned to the TUI and API clients.
 type DownloadStatus struct {
-	ID           string  `json:"id"`
-	URL          string  `json:"url"`
-	Filename     string  `json:"filename"`
-	DestPath     string  `json:"dest_path,omitempty"`
-	TotalSize    int64   `json:"total_size"`
-	Downloaded   int64   `json:"downloaded"`
-	Progress     float64 `json:"progress"`
-	Speed        float64 `json:"speed"`
-	Status       string  `json:"status"`
-	Error        string  `json:"error,omitempty"`
-	ETA          int64   `json:"eta"`
-	Connections  int     `json:"connections"`
-	AddedAt      int64   `json:"added_at"`
-	TimeTaken    int64   `json:"time_taken"`
-	AvgSpeed     float64 `json:"avg_speed"`
-	RateLimit    int64   `json:"rate_limit,omitempty"`
-	RateLimitSet bool    `json:"rate_limit_set,omitempty"`
+	ID         string  `json:"id"`
+	URL        string  `json:"url"`
+	FinalURL   string  `json:"final_url,omitempty"`
+	Filename   string  `json:"filename"`
+	DestPath   string  `json:"dest_path,omitempty"`
+	TotalSize  int64   `json:"total_size"`
+	Downloaded int64   `json:"downloaded"`
+	Progress   float64 `json:"progress"`
+	Speed      float64 `json:"speed"`
+	Status     string  `json:"status"`
+	Phase      Phase   `json:"phase,omitempty"` // What a downloading entry is busy with
+	Error      string  `json:"error,omitempty"`
+	// ErrorCode is the kind of Error, for clients to act on or filter by.
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
+	// Attempts is how often the download has failed in a row.
+	Attempts int   `json:"attempts,omitempty"`
+	ETA      int64 `json:"eta"`
+	// FinishesAt is when the download is projected to finish at its current
+	// speed, as a Unix time, or zero when there is no telling.
+	FinishesAt   int64    `json:"finishes_at,omitempty"`
+	Connections  int      `json:"connections"`
+	AddedAt      int64    `json:"added_at"`
+	TimeTaken    int64    `json:"time_taken"`
+	AvgSpeed     float64  `json:"avg_speed"`
+	RateLimit    int64    `json:"rate_limit,omitempty"`
+	RateLimitSet bool     `json:"rate_limit_set,omitempty"`
+	Alias        string   `json:"alias,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	// ResponseHeaders holds the notable headers the server answered the
+	// probe with. /list only includes them when asked to be verbose.
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+}
+
+// SetETA fills in ETA and FinishesAt from the speed and what is left to
+// download, as of now.
+func (s *DownloadStatus) SetETA(now time.Time) {
+	remaining := s.TotalSize - s.Downloaded
+	if remaining <= 0 || s.Speed <= 0 {
+		return
+	}
+	s.ETA = int64(float64(remaining) / (s.Speed * float64(MB)))
+	s.FinishesAt = now.Add(time.Duration(s.ETA) * time.Second).Unix()
 }
 
 // CancelResult carries enough metadata for callers to emit lifecycle events
@@ -0,0 +1,65 @@
+package types
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// pinPrefix marks a pin as the base64 SHA-256 of a certificate's
+// SubjectPublicKeyInfo, the same form curl's --pinnedpubkey accepts.
+const pinPrefix = "sha256/"
+
+// TLSPins maps a host rule, as HostRules matches it, to the SPKI pins
+// accepted for it.
+type TLSPins map[string][]string
+
+// ParseTLSPins parses a comma-separated list of host=sha256/BASE64 entries.
+// Repeat a host to accept more than one key, e.g. to allow for rotation.
+func ParseTLSPins(spec string) (TLSPins, error) {
+	pins := TLSPins{}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		host, pin, ok := strings.Cut(entry, "=")
+		pin = strings.TrimSpace(pin)
+		if !ok || strings.TrimSpace(host) == "" || pin == "" {
+			return nil, fmt.Errorf("invalid pin %q: want host=%sBASE64", entry, pinPrefix)
+		}
+		host, err := ParseHostRule(host)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pin host: %w", err)
+		}
+		// IP literals send no server name during the handshake, so there is
+		// nothing to match a rule against.
+		if net.ParseIP(strings.Trim(host, "[]")) != nil {
+			return nil, fmt.Errorf("invalid pin host %q: pins apply to host names, not IP addresses", host)
+		}
+		hash, ok := strings.CutPrefix(pin, pinPrefix)
+		if !ok {
+			return nil, fmt.Errorf("invalid pin %q: must start with %s", pin, pinPrefix)
+		}
+		if raw, err := base64.StdEncoding.DecodeString(hash); err != nil || len(raw) != sha256.Size {
+			return nil, fmt.Errorf("invalid pin %q: not a base64 SHA-256 hash", pin)
+		}
+		pins[host] = append(pins[host], pin)
+	}
+	return pins, nil
+}
+
+// ForHost returns the pins that apply to host; nil means host is not pinned.
+func (p TLSPins) ForHost(host string) []string {
+	pins, _ := HostRules[[]string](p).ForHost(host)
+	return pins
+}
+
+// SPKIPin returns the pin for cert's public key.
+func SPKIPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return pinPrefix + base64.StdEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,74 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"slices"
+	"testing"
+)
+
+func testPin(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	return "sha256/" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestParseTLSPins(t *testing.T) {
+	a, b, c := testPin("a"), testPin("b"), testPin("c")
+	spec := " Example.com=" + a + ", example.com=" + b + ",*.corp.net=" + c + ","
+
+	pins, err := ParseTLSPins(spec)
+	if err != nil {
+		t.Fatalf("ParseTLSPins failed: %v", err)
+	}
+	if got := pins["example.com"]; !slices.Equal(got, []string{a, b}) {
+		t.Errorf("example.com pins = %v", got)
+	}
+	if got := pins["*.corp.net"]; !slices.Equal(got, []string{c}) {
+		t.Errorf("*.corp.net pins = %v", got)
+	}
+
+	if pins, err := ParseTLSPins(""); err != nil || len(pins) != 0 {
+		t.Errorf("empty spec = (%v, %v), want no pins", pins, err)
+	}
+}
+
+func TestParseTLSPins_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"example.com",
+		"=" + testPin("a"),
+		"example.com=" + testPin("a")[len("sha256/"):],
+		"example.com=sha256/not-base64!",
+		"example.com=sha256/" + base64.StdEncoding.EncodeToString([]byte("short")),
+		"a.*.example.com=" + testPin("a"),
+		"10.0.0.1=" + testPin("a"),
+	} {
+		if _, err := ParseTLSPins(spec); err == nil {
+			t.Errorf("ParseTLSPins(%q) should fail", spec)
+		}
+	}
+}
+
+func TestTLSPins_ForHost(t *testing.T) {
+	exact, wild := testPin("exact"), testPin("wild")
+	pins := TLSPins{
+		"files.corp.net": {exact},
+		"*.corp.net":     {wild},
+	}
+
+	tests := []struct {
+		host string
+		want []string
+	}{
+		{"files.corp.net", []string{exact}},
+		{"FILES.corp.net.", []string{exact}},
+		{"cdn.corp.net", []string{wild}},
+		{"a.b.corp.net", []string{wild}},
+		{"corp.net", nil},
+		{"example.com", nil},
+	}
+	for _, tt := range tests {
+		if got := pins.ForHost(tt.host); !slices.Equal(got, tt.want) {
+			t.Errorf("ForHost(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
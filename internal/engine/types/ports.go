@@ -0,0 +1,85 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PortRange is an inclusive range of TCP ports.
+type PortRange struct {
+	Lo, Hi int
+}
+
+// PortRanges is a set of port ranges, such as the local ports outbound
+// connections may use.
+type PortRanges []PortRange
+
+// ParsePortRanges parses a comma-separated list of ports and lo-hi ranges,
+// e.g. "40000-40999,50000".
+func ParsePortRanges(spec string) (PortRanges, error) {
+	var ranges PortRanges
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		loStr, hiStr, isRange := strings.Cut(entry, "-")
+		lo, err := parsePort(loStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port range %q: %w", entry, err)
+		}
+		hi := lo
+		if isRange {
+			if hi, err = parsePort(hiStr); err != nil {
+				return nil, fmt.Errorf("invalid port range %q: %w", entry, err)
+			}
+		}
+		if hi < lo {
+			return nil, fmt.Errorf("invalid port range %q: %d is below %d", entry, hi, lo)
+		}
+		ranges = append(ranges, PortRange{Lo: lo, Hi: hi})
+	}
+	return ranges, nil
+}
+
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("%q is not a port between 1 and 65535", strings.TrimSpace(s))
+	}
+	return port, nil
+}
+
+// Len returns how many ports r holds, counting overlaps more than once.
+func (r PortRanges) Len() int {
+	n := 0
+	for _, pr := range r {
+		n += pr.Hi - pr.Lo + 1
+	}
+	return n
+}
+
+// At returns the i-th port of r, counting through the ranges in order.
+func (r PortRanges) At(i int) int {
+	for _, pr := range r {
+		if size := pr.Hi - pr.Lo + 1; i >= size {
+			i -= size
+			continue
+		}
+		return pr.Lo + i
+	}
+	return 0
+}
+
+func (r PortRanges) String() string {
+	parts := make([]string, len(r))
+	for i, pr := range r {
+		if pr.Lo == pr.Hi {
+			parts[i] = strconv.Itoa(pr.Lo)
+		} else {
+			parts[i] = fmt.Sprintf("%d-%d", pr.Lo, pr.Hi)
+		}
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,22 @@
+package types
+
+import "testing"
+
+func TestParsePortRanges(t *testing.T) {
+	ranges, err := ParsePortRanges(" 40000-40009, 50000 ,")
+	if err != nil {
+		t.Fatalf("ParsePortRanges failed: %v", err)
+	}
+	if ranges.Len() != 11 || ranges.At(0) != 40000 || ranges.At(9) != 40009 || ranges.At(10) != 50000 {
+		t.Fatalf("ranges = %v (len %d), want 40000-40009 and 50000", ranges, ranges.Len())
+	}
+	if got := ranges.String(); got != "40000-40009,50000" {
+		t.Errorf("String() = %q", got)
+	}
+
+	for _, spec := range []string{"0", "65536", "40010-40000", "abc", "1-", "-5"} {
+		if _, err := ParsePortRanges(spec); err == nil {
+			t.Errorf("ParsePortRanges(%q) succeeded, want an error", spec)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package types
+
+import "time"
+
+// ProbeHandoff carries what the lifecycle's probe learned, and the per-download
+// options the request asked for, to the engine when the download starts.
+type ProbeHandoff struct {
+	// EarlyBytes is how many leading bytes of the working file an early-ramp
+	// probe already wrote.
+	EarlyBytes int64
+	// FinalURL is where the source URL redirected to during the probe, or
+	// empty when it did not redirect.
+	FinalURL string
+	// TLS is the request's override of the global TLS settings.
+	TLS TLSOptions
+	// Request is the method and body the download must be sent with.
+	Request RequestOptions
+	// S3 is the object's multipart layout and checksum, when served by S3.
+	S3 S3Object
+	// LastModified is the server's Last-Modified time for the file.
+	LastModified time.Time
+	// ContentType is the Content-Type the server answered the probe with.
+	ContentType string
+	// QuotaReached reports whether the month's traffic has used up
+	// monthly_quota, so the download should wait instead of starting.
+	QuotaReached func() bool
+}
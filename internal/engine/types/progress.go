@@ -9,20 +9,44 @@ import (
 	"github.com/SurgeDM/Surge/internal/utils"
 )
 
+// Phase is what a running download is busy with.
+type Phase string
+
+const (
+	// PhaseProbing is the time before any byte moves: probing mirrors and
+	// working out the file's size.
+	PhaseProbing Phase = "probing"
+	// PhaseDownloading is fetching the file, including following its growth.
+	PhaseDownloading Phase = "downloading"
+	// PhaseVerifying is checking the finished file against its checksum or
+	// signature.
+	PhaseVerifying Phase = "verifying"
+	// PhasePostProcessing is the work after the file checked out, such as
+	// setting its modification time and moving copies into place.
+	PhasePostProcessing Phase = "post-processing"
+	// PhaseWaitingForNetwork is a queued download added while the network
+	// was down, held until its server can be reached again.
+	PhaseWaitingForNetwork Phase = "waiting-for-network"
+)
+
 type ProgressState struct {
 	ID            string
 	Downloaded    atomic.Int64
 	TotalSize     int64
-	DestPath      string // Initial destination path
-	Filename      string // Initial filename
-	URL           string // Source URL
+	DestPath      string    // Initial destination path
+	Filename      string    // Initial filename
+	URL           string    // Source URL
+	FinalURL      string    // URL the source redirected to, if different
+	LastModified  time.Time // Server's Last-Modified for the file, if it sent one
 	StartTime     time.Time
 	ActiveWorkers atomic.Int32
 	Done          atomic.Bool
 	Error         atomic.Pointer[error]
 	Paused        atomic.Bool
-	Pausing       atomic.Bool // Intermediate state: Pause requested but workers not yet exited
-	cancelFunc    context.CancelFunc
+	Pausing       atomic.Bool  // Intermediate state: Pause requested but workers not yet exited
+	Checked       atomic.Int64 // Bytes of the finished file checked so far while verifying
+	phase         atomic.Value // Phase
+	cancelFunc    context.CancelCauseFunc
 
 	VerifiedProgress  atomic.Int64  // Verified bytes written to disk (for UI progress)
 	SessionStartBytes int64         // SessionStartBytes tracks how many bytes were already downloaded when the current session started
@@ -32,12 +56,20 @@ type ProgressState struct {
 
 	Mirrors []MirrorStatus
 
+	// ConnectionSpeeds holds each open connection's speed in bytes/sec,
+	// refreshed by the downloader's health monitor
+	ConnectionSpeeds []float64
+
+	// Links holds what each interface carried this session when the
+	// download is spread over several, refreshed with ConnectionSpeeds
+	Links []LinkStatus
+
 	ChunkBitmap     []byte
 	ChunkProgress   []int64
 	ActualChunkSize int64
 	BitmapWidth     int
 
-	mu sync.Mutex // Protects TotalSize, StartTime, SessionStartBytes, SavedElapsed, Mirrors
+	mu sync.Mutex // Protects TotalSize, StartTime, SessionStartBytes, SavedElapsed, Mirrors, ConnectionSpeeds, Links
 }
 
 type MirrorStatus struct {
@@ -46,6 +78,14 @@ type MirrorStatus struct {
 	Error  bool
 }
 
+// LinkStatus is one interface a download's connections are spread over.
+type LinkStatus struct {
+	Interface   string
+	Bytes       int64   // Read over it this session
+	Speed       float64 // Bytes/sec across its open connections
+	Connections int
+}
+
 func (ps *ProgressState) SetDestPath(path string) {
 	ps.mu.Lock()
 	defer ps.mu.Unlock()
@@ -82,6 +122,33 @@ func (ps *ProgressState) GetURL() string {
 	return ps.URL
 }
 
+func (ps *ProgressState) SetFinalURL(url string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.FinalURL = url
+}
+
+func (ps *ProgressState) GetFinalURL() string {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.FinalURL
+}
+
+// SetLastModified records the server's Last-Modified time for the file.
+func (ps *ProgressState) SetLastModified(t time.Time) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.LastModified = t
+}
+
+// GetLastModified returns the server's Last-Modified time, or the zero time
+// when it sent none.
+func (ps *ProgressState) GetLastModified() time.Time {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.LastModified
+}
+
 func (ps *ProgressState) SetRateLimit(rate int64, explicit bool) {
 	ps.mu.Lock()
 	defer ps.mu.Unlock()
@@ -166,16 +233,23 @@ func (ps *ProgressState) GetProgress() (downloaded int64, total int64, totalElap
 	return
 }
 
+// Pause marks the download paused by the user and cancels it.
 func (ps *ProgressState) Pause() {
+	ps.PauseFor(ErrUserPause)
+}
+
+// PauseFor marks the download paused and cancels it with cause, which
+// IsPauseCause should accept.
+func (ps *ProgressState) PauseFor(cause error) {
 	ps.Paused.Store(true)
 	ps.mu.Lock()
 	defer ps.mu.Unlock()
 	if ps.cancelFunc != nil {
-		ps.cancelFunc()
+		ps.cancelFunc(cause)
 	}
 }
 
-func (ps *ProgressState) SetCancelFunc(cancel context.CancelFunc) {
+func (ps *ProgressState) SetCancelFunc(cancel context.CancelCauseFunc) {
 	ps.mu.Lock()
 	defer ps.mu.Unlock()
 	ps.cancelFunc = cancel
@@ -197,6 +271,19 @@ func (ps *ProgressState) IsPausing() bool {
 	return ps.Pausing.Load()
 }
 
+func (ps *ProgressState) SetPhase(phase Phase) {
+	ps.phase.Store(phase)
+}
+
+// GetPhase returns the download's phase; one that has not set a phase yet is
+// probing.
+func (ps *ProgressState) GetPhase() Phase {
+	if phase, ok := ps.phase.Load().(Phase); ok {
+		return phase
+	}
+	return PhaseProbing
+}
+
 func (ps *ProgressState) SetSavedElapsed(d time.Duration) {
 	ps.mu.Lock()
 	defer ps.mu.Unlock()
@@ -250,6 +337,8 @@ func (ps *ProgressState) SessionReset() {
 	ps.Done.Store(false)
 	ps.Paused.Store(false)
 	ps.Pausing.Store(false)
+	ps.phase.Store(PhaseDownloading)
+	ps.Checked.Store(0)
 	ps.Error.Store(nil)
 
 	// Clear mirrors error status
@@ -291,6 +380,41 @@ func (ps *ProgressState) GetMirrors() []MirrorStatus {
 	return mirrors
 }
 
+func (ps *ProgressState) SetLinks(links []LinkStatus) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.Links = append(ps.Links[:0], links...)
+}
+
+func (ps *ProgressState) GetLinks() []LinkStatus {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if len(ps.Links) == 0 {
+		return nil
+	}
+	links := make([]LinkStatus, len(ps.Links))
+	copy(links, ps.Links)
+	return links
+}
+
+func (ps *ProgressState) SetConnectionSpeeds(speeds []float64) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.ConnectionSpeeds = append(ps.ConnectionSpeeds[:0], speeds...)
+}
+
+// GetConnectionSpeeds returns a copy of the per-connection speeds.
+func (ps *ProgressState) GetConnectionSpeeds() []float64 {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if len(ps.ConnectionSpeeds) == 0 {
+		return nil
+	}
+	speeds := make([]float64, len(ps.ConnectionSpeeds))
+	copy(speeds, ps.ConnectionSpeeds)
+	return speeds
+}
+
 // ChunkStatus represents the status of a visualization chunk
 type ChunkStatus int
 
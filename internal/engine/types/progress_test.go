@@ -2,6 +2,8 @@ package types
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 )
@@ -150,10 +152,26 @@ func TestProgressState_PauseResume(t *testing.T) {
 	}
 }
 
+func TestProgressState_Phase(t *testing.T) {
+	ps := NewProgressState("test", 100)
+	if got := ps.GetPhase(); got != PhaseProbing {
+		t.Errorf("initial phase = %q, want %q", got, PhaseProbing)
+	}
+	ps.SetPhase(PhaseVerifying)
+	if got := ps.GetPhase(); got != PhaseVerifying {
+		t.Errorf("phase = %q, want %q", got, PhaseVerifying)
+	}
+	// A fallback restart transfers from the beginning again
+	ps.SessionReset()
+	if got := ps.GetPhase(); got != PhaseDownloading {
+		t.Errorf("phase after SessionReset = %q, want %q", got, PhaseDownloading)
+	}
+}
+
 func TestProgressState_PauseWithCancelFunc(t *testing.T) {
 	ps := NewProgressState("test", 100)
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancelCause(context.Background())
 	ps.SetCancelFunc(cancel)
 
 	// Verify context is not cancelled
@@ -172,6 +190,31 @@ func TestProgressState_PauseWithCancelFunc(t *testing.T) {
 	default:
 		t.Error("Context should be cancelled after Pause()")
 	}
+	if cause := context.Cause(ctx); !errors.Is(cause, ErrUserPause) || !errors.Is(cause, context.Canceled) {
+		t.Errorf("cause = %v, want ErrUserPause wrapping context.Canceled", cause)
+	}
+}
+
+func TestIsPauseCause(t *testing.T) {
+	tests := []struct {
+		cause error
+		want  bool
+	}{
+		{ErrUserPause, true},
+		{ErrShutdown, true},
+		{fmt.Errorf("%w: refresh the link", ErrURLExpired), true},
+		{&AuthExpiredError{Host: "files.example.com", Status: 401}, true},
+		{ErrUnexpectedHTML, true},
+		{ErrUserDelete, false},
+		{ErrStallTimeout, false},
+		{context.Canceled, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsPauseCause(tt.cause); got != tt.want {
+			t.Errorf("IsPauseCause(%v) = %v, want %v", tt.cause, got, tt.want)
+		}
+	}
 }
 
 func TestProgressState_GetProgress(t *testing.T) {
@@ -351,3 +394,27 @@ func TestProgressState_SessionReset(t *testing.T) {
 		}
 	}
 }
+
+func TestProgressState_ConnectionSpeeds(t *testing.T) {
+	ps := NewProgressState("test-id", 1000)
+	if got := ps.GetConnectionSpeeds(); got != nil {
+		t.Fatalf("speeds = %v before any were set, want nil", got)
+	}
+
+	ps.SetConnectionSpeeds([]float64{100, 200})
+	got := ps.GetConnectionSpeeds()
+	if len(got) != 2 || got[0] != 100 || got[1] != 200 {
+		t.Fatalf("speeds = %v, want [100 200]", got)
+	}
+
+	// The getter hands out a copy
+	got[0] = 0
+	if ps.GetConnectionSpeeds()[0] != 100 {
+		t.Fatal("modifying the returned slice changed the state")
+	}
+
+	ps.SetConnectionSpeeds(nil)
+	if got := ps.GetConnectionSpeeds(); got != nil {
+		t.Fatalf("speeds = %v after clearing, want nil", got)
+	}
+}
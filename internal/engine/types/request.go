@@ -0,0 +1,174 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// Content types a request body can be sent as.
+const (
+	ContentTypeForm = "application/x-www-form-urlencoded"
+	ContentTypeJSON = "application/json"
+)
+
+// MaxRequestConnections is the most connections a request can ask for, the
+// same bound the max_connections_per_download setting has.
+const MaxRequestConnections = 64
+
+// RequestOptions describes how a download is requested when a plain GET is
+// not enough, such as an export endpoint that streams a file back in answer
+// to a POST, or a log that is still being written, how its transfer is
+// scheduled on this machine, and the alias and tags it can be found by. The
+// zero value is a GET without a body.
+type RequestOptions struct {
+	Method string `json:"method,omitempty"`
+	Body   string `json:"body,omitempty"`
+	// ContentType is sent with the body. When empty it is guessed from the
+	// body: JSON if it starts with { or [, form data otherwise.
+	ContentType string `json:"content_type,omitempty"`
+	// Follow keeps appending to the download while the remote file grows and
+	// finishes only once its size has stopped changing.
+	Follow bool `json:"follow,omitempty"`
+	// LowPriority runs the download with lowered disk and CPU priority, so a
+	// large background transfer does not slow the rest of the system down.
+	LowPriority bool `json:"low_priority,omitempty"`
+	// Alias is a name the CLI accepts wherever it takes a download ID.
+	Alias string `json:"alias,omitempty"`
+	// Tags are free-form labels for organizing downloads, such as "work".
+	Tags []string `json:"tags,omitempty"`
+	// Checksum is the digest the finished file must match, as algorithm:hex.
+	// A mismatch fails the download instead of completing it.
+	Checksum string `json:"checksum,omitempty"`
+	// Connections caps the connections this download opens, below the
+	// max_connections_per_download setting. Zero uses the setting.
+	Connections int `json:"connections,omitempty"`
+	// Copies are absolute directories the file is written to as well, as
+	// it downloads, such as a NAS mount next to the local disk. A copy that
+	// fails is dropped without failing the download.
+	Copies []string `json:"copies,omitempty"`
+	// SignatureURL is where the detached minisign or OpenPGP signature the
+	// finished file must verify against is fetched from, or SignatureAuto
+	// to look for one next to the file. Failing to verify fails the download.
+	SignatureURL string `json:"signature_url,omitempty"`
+	// Timeouts overrides the network timeouts for this download.
+	Timeouts Timeouts `json:"timeouts,omitzero"`
+	// AllowHTML saves a web page served for a URL that names a binary file
+	// instead of pausing the download to ask.
+	AllowHTML bool `json:"allow_html,omitempty"`
+	// Confirmed starts the download even when it is larger than the
+	// confirm_size_threshold setting.
+	Confirmed bool `json:"confirm,omitempty"`
+	// Interface is the interface name or local IP this download connects
+	// from, in place of the bind_interface setting and domain rules.
+	Interface string `json:"interface,omitempty"`
+}
+
+// SignatureAuto as a SignatureURL looks for the signature at the download's
+// URL with each of the usual signature extensions appended.
+const SignatureAuto = "auto"
+
+// IsZero reports whether o is a plain GET request.
+func (o RequestOptions) IsZero() bool {
+	return o.IsGet() && !o.Follow && !o.LowPriority && o.Checksum == "" && o.Connections == 0 && len(o.Copies) == 0 && o.SignatureURL == "" && o.Timeouts.IsZero() && !o.AllowHTML && o.Interface == ""
+}
+
+// IsGet reports whether o is a GET without a body, which can be probed and
+// split into range requests.
+func (o RequestOptions) IsGet() bool {
+	return o.EffectiveMethod() == http.MethodGet && o.Body == ""
+}
+
+// EffectiveMethod returns the upper-cased method, or GET when none is set.
+func (o RequestOptions) EffectiveMethod() string {
+	if m := strings.ToUpper(strings.TrimSpace(o.Method)); m != "" {
+		return m
+	}
+	return http.MethodGet
+}
+
+// EffectiveContentType returns the content type to send with the body, or
+// empty when there is no body.
+func (o RequestOptions) EffectiveContentType() string {
+	if o.Body == "" {
+		return ""
+	}
+	if o.ContentType != "" {
+		return o.ContentType
+	}
+	if body := strings.TrimSpace(o.Body); strings.HasPrefix(body, "{") || strings.HasPrefix(body, "[") {
+		return ContentTypeJSON
+	}
+	return ContentTypeForm
+}
+
+// Validate rejects methods that cannot return a file, bodies on GET,
+// following anything but a GET, malformed aliases, tags or checksums,
+// connection counts out of range, relative copy directories, signature
+// URLs that are not http(s), negative timeouts and malformed interfaces.
+func (o RequestOptions) Validate() error {
+	if err := ValidateAlias(o.Alias); err != nil {
+		return err
+	}
+	if _, err := NormalizeTags(o.Tags); err != nil {
+		return err
+	}
+	if o.Checksum != "" {
+		if _, _, err := ParseChecksum(o.Checksum); err != nil {
+			return err
+		}
+	}
+	if o.Connections < 0 || o.Connections > MaxRequestConnections {
+		return fmt.Errorf("connections must be between 1 and %d", MaxRequestConnections)
+	}
+	for _, dir := range o.Copies {
+		if !filepath.IsAbs(dir) {
+			return fmt.Errorf("copy directory %q is not absolute", dir)
+		}
+	}
+	if o.SignatureURL != "" && o.SignatureURL != SignatureAuto {
+		if u, err := url.Parse(o.SignatureURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("signature URL %q must be an http(s) URL or %q", o.SignatureURL, SignatureAuto)
+		}
+	}
+	if err := o.Timeouts.Validate(); err != nil {
+		return err
+	}
+	if err := ValidateBindInterface(o.Interface); err != nil {
+		return err
+	}
+	if o.Follow && !o.IsGet() {
+		return fmt.Errorf("only GET downloads can follow a growing file")
+	}
+	switch m := o.EffectiveMethod(); m {
+	case http.MethodGet:
+		if o.Body != "" {
+			return fmt.Errorf("a request body needs a method other than GET")
+		}
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return fmt.Errorf("unsupported request method %q", m)
+	}
+	return nil
+}
+
+// NewRequest builds the HTTP request for o. The body can be replayed, so the
+// client can follow 307 and 308 redirects with it.
+func (o RequestOptions) NewRequest(ctx context.Context, rawurl string) (*http.Request, error) {
+	var body io.Reader
+	if o.Body != "" {
+		body = strings.NewReader(o.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, o.EffectiveMethod(), rawurl, body)
+	if err != nil {
+		return nil, err
+	}
+	if ct := o.EffectiveContentType(); ct != "" {
+		req.Header.Set("Content-Type", ct)
+	}
+	return req, nil
+}
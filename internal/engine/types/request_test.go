@@ -0,0 +1,178 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequestOptions_Defaults(t *testing.T) {
+	if !(RequestOptions{}).IsZero() || !(RequestOptions{Method: "get"}).IsZero() {
+		t.Error("a GET without a body should be the zero request")
+	}
+	if (RequestOptions{Method: "post"}).IsZero() {
+		t.Error("a POST is not the zero request")
+	}
+	if got := (RequestOptions{Method: " post "}).EffectiveMethod(); got != http.MethodPost {
+		t.Errorf("EffectiveMethod = %q, want POST", got)
+	}
+
+	tests := []struct {
+		opts RequestOptions
+		want string
+	}{
+		{RequestOptions{Method: "POST"}, ""},
+		{RequestOptions{Method: "POST", Body: `  {"format":"csv"}`}, ContentTypeJSON},
+		{RequestOptions{Method: "POST", Body: `[1,2]`}, ContentTypeJSON},
+		{RequestOptions{Method: "POST", Body: "format=csv&all=1"}, ContentTypeForm},
+		{RequestOptions{Method: "POST", Body: "<q/>", ContentType: "application/xml"}, "application/xml"},
+	}
+	for _, tt := range tests {
+		if got := tt.opts.EffectiveContentType(); got != tt.want {
+			t.Errorf("EffectiveContentType(%+v) = %q, want %q", tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestRequestOptions_Validate(t *testing.T) {
+	valid := []RequestOptions{{}, {Method: "post", Body: "a=b"}, {Method: "PUT"}, {Method: "PATCH", Body: "{}"}}
+	for _, opts := range valid {
+		if err := opts.Validate(); err != nil {
+			t.Errorf("Validate(%+v) = %v", opts, err)
+		}
+	}
+	invalid := []RequestOptions{{Body: "a=b"}, {Method: "HEAD"}, {Method: "DELETE"}, {Method: "BREW"}}
+	for _, opts := range invalid {
+		if err := opts.Validate(); err == nil {
+			t.Errorf("Validate(%+v) should fail", opts)
+		}
+	}
+}
+
+func TestRequestOptions_NewRequest(t *testing.T) {
+	opts := RequestOptions{Method: "post", Body: `{"id":7}`}
+	req, err := opts.NewRequest(context.Background(), "https://example.com/export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != http.MethodPost || req.Header.Get("Content-Type") != ContentTypeJSON {
+		t.Errorf("request = %s with Content-Type %q", req.Method, req.Header.Get("Content-Type"))
+	}
+	// Redirects that keep the method need to resend the body.
+	if req.GetBody == nil {
+		t.Fatal("body should be replayable")
+	}
+	body, _ := req.GetBody()
+	if data, _ := io.ReadAll(body); string(data) != opts.Body {
+		t.Errorf("replayed body = %q", data)
+	}
+}
+
+func TestRequestOptions_Follow(t *testing.T) {
+	follow := RequestOptions{Follow: true}
+	if follow.IsZero() || !follow.IsGet() {
+		t.Errorf("a followed GET is not the zero request but is still a GET")
+	}
+	if err := follow.Validate(); err != nil {
+		t.Errorf("Validate = %v", err)
+	}
+	if err := (RequestOptions{Method: "POST", Follow: true}).Validate(); err == nil {
+		t.Error("following a POST should be rejected")
+	}
+}
+
+func TestRequestOptions_LowPriority(t *testing.T) {
+	low := RequestOptions{LowPriority: true}
+	if low.IsZero() || !low.IsGet() {
+		t.Errorf("a low-priority GET is not the zero request but is still a GET")
+	}
+}
+
+func TestRequestOptions_ChecksumAndConnections(t *testing.T) {
+	sum := "sha256:" + strings.Repeat("ab", 32)
+	for _, opts := range []RequestOptions{{Checksum: sum}, {Connections: 4}} {
+		if opts.IsZero() {
+			t.Errorf("%+v should not be the zero request", opts)
+		}
+		if err := opts.Validate(); err != nil {
+			t.Errorf("Validate(%+v) = %v", opts, err)
+		}
+	}
+	for _, opts := range []RequestOptions{{Checksum: "sha256:abc"}, {Connections: -1}, {Connections: MaxRequestConnections + 1}} {
+		if err := opts.Validate(); err == nil {
+			t.Errorf("Validate(%+v) should fail", opts)
+		}
+	}
+}
+
+func TestRequestOptions_Timeouts(t *testing.T) {
+	var opts RequestOptions
+	if err := json.Unmarshal([]byte(`{"timeouts":{"connect":"30s","max":"2h"}}`), &opts); err != nil {
+		t.Fatal(err)
+	}
+	want := Timeouts{Connect: Duration(30 * time.Second), Max: Duration(2 * time.Hour)}
+	if opts.Timeouts != want {
+		t.Errorf("Timeouts = %+v, want %+v", opts.Timeouts, want)
+	}
+	if opts.IsZero() {
+		t.Error("a request with timeouts should not be the zero request")
+	}
+	data, err := json.Marshal(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != `{"timeouts":{"connect":"30s","max":"2h0m0s"}}` {
+		t.Errorf("Marshal = %s", got)
+	}
+	if data, _ := json.Marshal(RequestOptions{}); string(data) != "{}" {
+		t.Errorf("zero timeouts should be left out, got %s", data)
+	}
+	if err := json.Unmarshal([]byte(`{"timeouts":{"stall":"soon"}}`), &opts); err == nil {
+		t.Error("expected an error for a malformed duration")
+	}
+	if err := (RequestOptions{Timeouts: Timeouts{Stall: Duration(-time.Second)}}).Validate(); err == nil {
+		t.Error("Validate should reject a negative timeout")
+	}
+}
+
+func TestTimeouts_Apply(t *testing.T) {
+	base := &RuntimeConfig{StallTimeout: 5 * time.Second, ConnectTimeout: 3 * time.Second}
+	got := Timeouts{ResponseHeader: Duration(time.Minute), Max: Duration(time.Hour)}.Apply(base)
+	if got == base {
+		t.Fatal("Apply should return a copy")
+	}
+	if got.GetConnectTimeout() != 3*time.Second || got.GetStallTimeout() != 5*time.Second {
+		t.Errorf("unset timeouts should keep the runtime's: connect %v, stall %v", got.GetConnectTimeout(), got.GetStallTimeout())
+	}
+	if got.GetResponseHeaderTimeout() != time.Minute || got.GetMaxDuration() != time.Hour {
+		t.Errorf("header %v, max %v, want 1m and 1h", got.GetResponseHeaderTimeout(), got.GetMaxDuration())
+	}
+	if base.ResponseHeaderTimeout != 0 || base.MaxDuration != 0 {
+		t.Error("Apply modified the runtime it was given")
+	}
+
+	var defaults *RuntimeConfig
+	if defaults.GetConnectTimeout() != DialTimeout || defaults.GetResponseHeaderTimeout() != DefaultResponseHeaderTimeout || defaults.GetMaxDuration() != 0 {
+		t.Error("a nil runtime should use the default timeouts")
+	}
+}
+
+func TestValidateAlias(t *testing.T) {
+	for _, alias := range []string{"", "nightly-build", "Backup_2026.10", "cafe-v2"} {
+		if err := ValidateAlias(alias); err != nil {
+			t.Errorf("ValidateAlias(%q) = %v", alias, err)
+		}
+	}
+	for _, alias := range []string{"1nightly", "-x", "has space", "a/b", "cafe", "deadbeef-0123", strings.Repeat("a", MaxAliasLength+1)} {
+		if err := ValidateAlias(alias); err == nil {
+			t.Errorf("ValidateAlias(%q) should fail", alias)
+		}
+	}
+	if err := (RequestOptions{Alias: "bad alias"}).Validate(); err == nil {
+		t.Error("Validate should check the alias")
+	}
+}
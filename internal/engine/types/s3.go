@@ -0,0 +1,206 @@
+package types
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// s3ChecksumAlgorithms lists the x-amz-checksum-* algorithms Surge can verify,
+// strongest first.
+var s3ChecksumAlgorithms = []string{"sha256", "sha1", "crc32c", "crc32"}
+
+// S3Object is what the probe learned about a file served by S3 or an
+// S3-compatible store. The zero value means nothing S3-specific is known.
+type S3Object struct {
+	// PartSize is the part size of a multipart upload. Chunks are cut on part
+	// boundaries so each range request maps onto whole stored parts.
+	PartSize int64 `json:"part_size,omitempty"`
+	// Checksum is the object's x-amz-checksum-* value, when the server sent one.
+	Checksum S3Checksum `json:"checksum,omitzero"`
+}
+
+// IsZero reports whether nothing S3-specific is known.
+func (o S3Object) IsZero() bool {
+	return o == S3Object{}
+}
+
+// S3Checksum is an x-amz-checksum-* header value. Multipart objects may carry
+// a composite checksum, the checksum of the part checksums, written as
+// "BASE64-N" for N parts.
+type S3Checksum struct {
+	Algorithm string `json:"algorithm,omitempty"`
+	Value     string `json:"value,omitempty"`
+}
+
+// IsZero reports whether no checksum is known.
+func (c S3Checksum) IsZero() bool {
+	return c.Value == ""
+}
+
+// Composite returns the base64 digest and the part count of a composite
+// checksum. Parts is zero for a checksum of the whole object.
+func (c S3Checksum) Composite() (digest string, parts int) {
+	digest, count, ok := strings.Cut(c.Value, "-")
+	if !ok {
+		return c.Value, 0
+	}
+	parts, err := strconv.Atoi(count)
+	if err != nil || parts < 1 {
+		return c.Value, 0
+	}
+	return digest, parts
+}
+
+// String encodes c as "algorithm:value" for storage.
+func (c S3Checksum) String() string {
+	if c.IsZero() {
+		return ""
+	}
+	return c.Algorithm + ":" + c.Value
+}
+
+// ParseS3Checksum decodes a value written by S3Checksum.String.
+func ParseS3Checksum(s string) S3Checksum {
+	algorithm, value, ok := strings.Cut(s, ":")
+	if !ok || value == "" {
+		return S3Checksum{}
+	}
+	return S3Checksum{Algorithm: algorithm, Value: value}
+}
+
+// IsS3Host reports whether host is an Amazon S3 endpoint, such as
+// bucket.s3.us-east-1.amazonaws.com or s3-accelerate.amazonaws.com.
+func IsS3Host(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	host = strings.TrimSuffix(host, ".cn")
+	rest, ok := strings.CutSuffix(host, ".amazonaws.com")
+	if !ok {
+		return false
+	}
+	for _, label := range strings.Split(rest, ".") {
+		if label == "s3" || strings.HasPrefix(label, "s3-") {
+			return true
+		}
+	}
+	return false
+}
+
+// IsS3Response reports whether a response from host came from S3 or an
+// S3-compatible store, which send S3's request headers.
+func IsS3Response(host string, h http.Header) bool {
+	return IsS3Host(host) || h.Get("X-Amz-Request-Id") != "" || h.Get("Server") == "AmazonS3"
+}
+
+// ParseS3Object reads the S3 metadata of a response for a file of size bytes.
+// It returns the zero value unless IsS3Response holds.
+func ParseS3Object(host string, h http.Header, size int64) S3Object {
+	if !IsS3Response(host, h) {
+		return S3Object{}
+	}
+
+	var obj S3Object
+	for _, algorithm := range s3ChecksumAlgorithms {
+		if v := strings.TrimSpace(h.Get("X-Amz-Checksum-" + algorithm)); v != "" {
+			obj.Checksum = S3Checksum{Algorithm: algorithm, Value: v}
+			break
+		}
+	}
+
+	parts := multipartETagParts(h.Get("ETag"))
+	if _, n := obj.Checksum.Composite(); n > 0 {
+		parts = n
+	}
+	obj.PartSize = MultipartPartSize(size, parts)
+	return obj
+}
+
+// multipartETagParts returns N from a multipart upload's "HASH-N" ETag, or 0.
+func multipartETagParts(etag string) int {
+	etag = strings.Trim(strings.TrimPrefix(strings.TrimSpace(etag), "W/"), `"`)
+	_, count, ok := strings.Cut(etag, "-")
+	if !ok {
+		return 0
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// commonPartSizes are the part sizes popular S3 clients upload with, most
+// common first.
+var commonPartSizes = []int64{8 * MB, 16 * MB, 5 * MB, 15 * MB, 64 * MB, 100 * MB, 128 * MB}
+
+// MultipartPartSize works out the part size of a size-byte object uploaded in
+// parts parts. It returns 0 when there is only one part or no whole-MiB part
+// size fits.
+func MultipartPartSize(size int64, parts int) int64 {
+	if candidates := MultipartPartSizes(size, parts); len(candidates) > 0 {
+		return candidates[0]
+	}
+	return 0
+}
+
+// MultipartPartSizes lists the part sizes that split size bytes into exactly
+// parts parts with a shorter last part, most likely first. Uploaders use whole
+// MiB sizes, so the common client defaults that fit come first, followed by
+// the smallest whole MiB that fits.
+func MultipartPartSizes(size int64, parts int) []int64 {
+	if size <= 0 || parts < 2 {
+		return nil
+	}
+	n := int64(parts)
+	fits := func(partSize int64) bool {
+		return (n-1)*partSize < size && n*partSize >= size
+	}
+
+	var candidates []int64
+	for _, partSize := range commonPartSizes {
+		if fits(partSize) {
+			candidates = append(candidates, partSize)
+		}
+	}
+	perPart := (size + n - 1) / n
+	if smallest := (perPart + MB - 1) / MB * MB; fits(smallest) && !slices.Contains(candidates, smallest) {
+		candidates = append(candidates, smallest)
+	}
+	return candidates
+}
+
+// PresignedExpiry returns when a presigned S3 URL stops being accepted, from
+// its SigV4 X-Amz-Date and X-Amz-Expires or its SigV2 Expires parameter.
+func PresignedExpiry(rawurl string) (time.Time, bool) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return time.Time{}, false
+	}
+	q := u.Query()
+	if date, expires := q.Get("X-Amz-Date"), q.Get("X-Amz-Expires"); date != "" && expires != "" {
+		signed, err := time.Parse("20060102T150405Z", date)
+		if err != nil {
+			return time.Time{}, false
+		}
+		seconds, err := strconv.ParseInt(expires, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return signed.Add(time.Duration(seconds) * time.Second), true
+	}
+	if q.Get("AWSAccessKeyId") != "" && q.Get("Expires") != "" {
+		unix, err := strconv.ParseInt(q.Get("Expires"), 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Unix(unix, 0), true
+	}
+	return time.Time{}, false
+}
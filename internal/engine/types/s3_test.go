@@ -0,0 +1,109 @@
+package types
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestIsS3Host(t *testing.T) {
+	for _, host := range []string{
+		"bucket.s3.amazonaws.com",
+		"bucket.s3.us-east-1.amazonaws.com",
+		"s3.eu-west-2.amazonaws.com:443",
+		"bucket.s3-accelerate.amazonaws.com",
+		"s3-us-west-2.amazonaws.com",
+		"bucket.s3.cn-north-1.amazonaws.com.cn",
+	} {
+		if !IsS3Host(host) {
+			t.Errorf("IsS3Host(%q) = false, want true", host)
+		}
+	}
+	for _, host := range []string{"", "example.com", "ec2.us-east-1.amazonaws.com", "s3.example.com", "amazonaws.com"} {
+		if IsS3Host(host) {
+			t.Errorf("IsS3Host(%q) = true, want false", host)
+		}
+	}
+}
+
+func TestMultipartPartSize(t *testing.T) {
+	tests := []struct {
+		size  int64
+		parts int
+		want  int64
+	}{
+		{20*MB + 5, 3, 8 * MB},  // 8 MiB and 7 MiB both fit; the client default wins
+		{100 * MB, 7, 16 * MB},  // 8 MiB parts would need 13 parts
+		{30*MB + 1, 3, 15 * MB}, // 15 MiB is common; 11 MiB is the smallest fit
+		{10 * MB, 1, 0},         // single-part uploads have no part layout
+		{10 * MB, 100, 0},       // no whole MiB fits 100 parts in 10 MiB
+		{0, 3, 0},
+	}
+	for _, tt := range tests {
+		if got := MultipartPartSize(tt.size, tt.parts); got != tt.want {
+			t.Errorf("MultipartPartSize(%d, %d) = %d, want %d", tt.size, tt.parts, got, tt.want)
+		}
+	}
+
+	if got, want := MultipartPartSizes(20*MB+5, 3), []int64{8 * MB, 7 * MB}; !slices.Equal(got, want) {
+		t.Errorf("MultipartPartSizes = %v, want %v", got, want)
+	}
+}
+
+func TestParseS3Object(t *testing.T) {
+	h := http.Header{}
+	h.Set("ETag", `"9b2cf535f27731c974343645a3985328-3"`)
+	h.Set("X-Amz-Checksum-Crc32", "AAAAAA==-3")
+
+	obj := ParseS3Object("bucket.s3.amazonaws.com", h, 20*MB+5)
+	if obj.PartSize != 8*MB {
+		t.Errorf("PartSize = %d, want %d", obj.PartSize, 8*MB)
+	}
+	if obj.Checksum != (S3Checksum{Algorithm: "crc32", Value: "AAAAAA==-3"}) {
+		t.Errorf("Checksum = %+v", obj.Checksum)
+	}
+	if digest, parts := obj.Checksum.Composite(); digest != "AAAAAA==" || parts != 3 {
+		t.Errorf("Composite() = %q, %d", digest, parts)
+	}
+
+	// S3-compatible stores are recognised by their response headers.
+	compat := http.Header{}
+	compat.Set("X-Amz-Request-Id", "tx0001")
+	compat.Set("X-Amz-Checksum-Sha256", "abc=")
+	if obj := ParseS3Object("minio.example.com:9000", compat, 10); obj.Checksum.Algorithm != "sha256" || obj.PartSize != 0 {
+		t.Errorf("S3-compatible object = %+v", obj)
+	}
+
+	if obj := ParseS3Object("example.com", h, 20*MB+5); !obj.IsZero() {
+		t.Errorf("non-S3 response parsed as %+v", obj)
+	}
+}
+
+func TestS3Checksum_RoundTrip(t *testing.T) {
+	sum := S3Checksum{Algorithm: "sha256", Value: "n4bQgYhMfWWaL+qgxVrQFaO/TxsrC4Is0V1sFbDwCgg=-2"}
+	if got := ParseS3Checksum(sum.String()); got != sum {
+		t.Errorf("round trip = %+v, want %+v", got, sum)
+	}
+	if (S3Checksum{}).String() != "" || !ParseS3Checksum("").IsZero() {
+		t.Error("empty checksum should encode as empty")
+	}
+}
+
+func TestPresignedExpiry(t *testing.T) {
+	v4 := "https://bucket.s3.amazonaws.com/f.bin?X-Amz-Algorithm=AWS4-HMAC-SHA256&X-Amz-Date=20260102T030405Z&X-Amz-Expires=3600&X-Amz-Signature=abc"
+	got, ok := PresignedExpiry(v4)
+	if want := time.Date(2026, 1, 2, 4, 4, 5, 0, time.UTC); !ok || !got.Equal(want) {
+		t.Errorf("SigV4 expiry = %v, %v; want %v", got, ok, want)
+	}
+
+	v2 := "https://bucket.s3.amazonaws.com/f.bin?AWSAccessKeyId=AKIA&Expires=1767225600&Signature=abc"
+	got, ok = PresignedExpiry(v2)
+	if !ok || got.Unix() != 1767225600 {
+		t.Errorf("SigV2 expiry = %v, %v", got, ok)
+	}
+
+	if _, ok := PresignedExpiry("https://example.com/f.bin?Expires=1767225600"); ok {
+		t.Error("unsigned URL should have no expiry")
+	}
+}
@@ -0,0 +1,56 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// MaxTagLength bounds how long a single download tag can be.
+const MaxTagLength = 32
+
+// NormalizeTags cleans up free-form tags such as "Work, linux": entries may
+// hold several comma-separated tags, which are trimmed, lower-cased, sorted
+// and de-duplicated. Tags use letters, digits, '-', '_' and '.'.
+func NormalizeTags(tags []string) ([]string, error) {
+	var out []string
+	for _, entry := range tags {
+		for _, tag := range strings.Split(entry, ",") {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if tag == "" {
+				continue
+			}
+			if len(tag) > MaxTagLength {
+				return nil, fmt.Errorf("tag %q is longer than %d characters", tag, MaxTagLength)
+			}
+			for _, r := range tag {
+				switch {
+				case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+				default:
+					return nil, fmt.Errorf("tag %q may only contain letters, digits, '-', '_' and '.'", tag)
+				}
+			}
+			out = append(out, tag)
+		}
+	}
+	slices.Sort(out)
+	return slices.Compact(out), nil
+}
+
+// HasTag reports whether tags holds tag, ignoring case.
+func HasTag(tags []string, tag string) bool {
+	return slices.Contains(tags, strings.ToLower(strings.TrimSpace(tag)))
+}
+
+// JoinTags encodes normalized tags for storage.
+func JoinTags(tags []string) string {
+	return strings.Join(tags, ",")
+}
+
+// SplitTags decodes tags written by JoinTags.
+func SplitTags(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
@@ -0,0 +1,29 @@
+package types
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	got, err := NormalizeTags([]string{" Work ", "linux,iso", "work", ""})
+	if err != nil {
+		t.Fatalf("NormalizeTags failed: %v", err)
+	}
+	if want := []string{"iso", "linux", "work"}; !slices.Equal(got, want) {
+		t.Fatalf("NormalizeTags = %v, want %v", got, want)
+	}
+	if !HasTag(got, "LINUX") || HasTag(got, "lin") {
+		t.Error("HasTag should match whole tags, ignoring case")
+	}
+	if got := SplitTags(JoinTags(got)); !slices.Equal(got, []string{"iso", "linux", "work"}) {
+		t.Errorf("SplitTags(JoinTags) = %v", got)
+	}
+
+	for _, bad := range []string{"two words", "a/b", strings.Repeat("x", MaxTagLength+1)} {
+		if _, err := NormalizeTags([]string{bad}); err == nil {
+			t.Errorf("NormalizeTags(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// Duration is a time.Duration written as a string such as "30s" in JSON.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Timeouts overrides the network timeouts of one download, for a server
+// that is slow to answer or a link that keeps dropping. Zero fields keep the
+// defaults.
+type Timeouts struct {
+	// Connect bounds dialing the server.
+	Connect Duration `json:"connect,omitempty"`
+	// ResponseHeader bounds waiting for the headers of a response once the
+	// request has been sent.
+	ResponseHeader Duration `json:"response_header,omitempty"`
+	// Stall restarts a connection that has received no data for this long,
+	// in place of the stall_timeout setting.
+	Stall Duration `json:"stall,omitempty"`
+	// Max fails the download once it has run this long, counted from when
+	// it starts or resumes.
+	Max Duration `json:"max,omitempty"`
+}
+
+// IsZero reports whether t keeps every default.
+func (t Timeouts) IsZero() bool {
+	return t == Timeouts{}
+}
+
+// Validate rejects negative timeouts.
+func (t Timeouts) Validate() error {
+	for _, f := range []struct {
+		name string
+		d    Duration
+	}{{"connect", t.Connect}, {"response header", t.ResponseHeader}, {"stall", t.Stall}, {"max", t.Max}} {
+		if f.d < 0 {
+			return fmt.Errorf("%s timeout must not be negative", f.name)
+		}
+	}
+	return nil
+}
+
+// Apply returns a copy of r with the timeouts t sets.
+func (t Timeouts) Apply(r *RuntimeConfig) *RuntimeConfig {
+	var out RuntimeConfig
+	if r != nil {
+		out = *r
+	} else {
+		out = *DefaultRuntimeConfig()
+	}
+	if t.Connect > 0 {
+		out.ConnectTimeout = time.Duration(t.Connect)
+	}
+	if t.ResponseHeader > 0 {
+		out.ResponseHeaderTimeout = time.Duration(t.ResponseHeader)
+	}
+	if t.Stall > 0 {
+		out.StallTimeout = time.Duration(t.Stall)
+	}
+	if t.Max > 0 {
+		out.MaxDuration = time.Duration(t.Max)
+	}
+	return &out
+}
@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"context"
+	"net"
+	"sync"
+)
+
+// uploadChunk is the most a shaped connection writes at once, so a large
+// request body goes out as an even stream instead of bursts of a second's
+// worth of bytes.
+const uploadChunk = 4 * 1024
+
+// DefaultUploadLimiter caps the bytes all of Surge's connections send,
+// alongside the download limiter for those they receive. It is unlimited
+// until SetUploadRateLimit is called.
+var DefaultUploadLimiter = NewRateLimiter(0, 0)
+
+// SetUploadRateLimit sets the total upload rate in bytes per second; zero
+// removes the cap. Open connections follow the new rate at once.
+func SetUploadRateLimit(rate int64) {
+	DefaultUploadLimiter.SetRate(rate, max(rate/4, uploadChunk))
+}
+
+// ShapeUpload returns conn with its writes paced by DefaultUploadLimiter.
+// Every connection NetworkPool dials is shaped; other protocols wrap their
+// own connections with it so a thin uplink is never saturated.
+func ShapeUpload(conn net.Conn) net.Conn {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &shapedConn{Conn: conn, limiter: DefaultUploadLimiter, ctx: ctx, cancel: cancel}
+}
+
+// shapedConn waits for upload tokens before each write. Closing it wakes a
+// write still waiting for them.
+type shapedConn struct {
+	net.Conn
+	limiter   *RateLimiter
+	ctx       context.Context
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+}
+
+func (c *shapedConn) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(len(p), uploadChunk)
+		if err := c.limiter.WaitN(c.ctx, int64(n)); err != nil {
+			return written, net.ErrClosed
+		}
+		m, err := c.Conn.Write(p[:n])
+		written += m
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+func (c *shapedConn) Close() error {
+	c.closeOnce.Do(c.cancel)
+	return c.Conn.Close()
+}
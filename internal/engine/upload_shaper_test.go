@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func newTestShapedConn(t *testing.T, limiter *RateLimiter) (*shapedConn, net.Conn) {
+	t.Helper()
+	client, server := net.Pipe()
+	t.Cleanup(func() { _ = server.Close() })
+	go func() { _, _ = io.Copy(io.Discard, server) }()
+	ctx, cancel := context.WithCancel(context.Background())
+	return &shapedConn{Conn: client, limiter: limiter, ctx: ctx, cancel: cancel}, server
+}
+
+func TestShapedConn_PacesWrites(t *testing.T) {
+	conn, _ := newTestShapedConn(t, NewRateLimiter(16*1024, uploadChunk))
+	defer func() { _ = conn.Close() }()
+
+	start := time.Now()
+	n, err := conn.Write(make([]byte, 12*1024))
+	if err != nil || n != 12*1024 {
+		t.Fatalf("Write = %d, %v", n, err)
+	}
+	// The first chunk is covered by the bucket; the other 8 KB take 0.5s
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("12 KB at 16 KB/s took %v, want at least 0.4s", elapsed)
+	}
+}
+
+func TestShapedConn_UnlimitedByDefault(t *testing.T) {
+	conn, _ := newTestShapedConn(t, NewRateLimiter(0, 0))
+	defer func() { _ = conn.Close() }()
+
+	start := time.Now()
+	if _, err := conn.Write(make([]byte, 1<<20)); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("unlimited write took %v", elapsed)
+	}
+}
+
+func TestShapedConn_CloseWakesWrite(t *testing.T) {
+	conn, _ := newTestShapedConn(t, NewRateLimiter(1, 1))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := conn.Write(make([]byte, uploadChunk))
+		done <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	_ = conn.Close()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, net.ErrClosed) {
+			t.Errorf("Write after Close = %v, want net.ErrClosed", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close did not wake the waiting write")
+	}
+}
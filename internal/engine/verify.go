@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// VerifyProgressInterval is the most often a VerifyProgress is called.
+const VerifyProgressInterval = 250 * time.Millisecond
+
+// VerifyProgress is told how far reading a finished file back for a
+// checksum or signature has got: read of total bytes.
+type VerifyProgress func(read, total int64)
+
+// verifyReader reads a finished file for verification. It stops with the
+// context's cause once ctx is done, so a pause or cancel does not wait for a
+// whole large file to be hashed.
+type verifyReader struct {
+	ctx      context.Context
+	file     *os.File
+	progress VerifyProgress
+	read     int64
+	total    int64
+	reported time.Time
+}
+
+func openVerify(ctx context.Context, path string, progress VerifyProgress) (*verifyReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	r := &verifyReader{ctx: ctx, file: f, progress: progress, total: info.Size()}
+	r.report(true)
+	return r, nil
+}
+
+func (r *verifyReader) Read(p []byte) (int, error) {
+	if r.ctx.Err() != nil {
+		return 0, context.Cause(r.ctx)
+	}
+	n, err := r.file.Read(p)
+	r.read += int64(n)
+	r.report(err != nil)
+	return n, err
+}
+
+func (r *verifyReader) report(force bool) {
+	if r.progress == nil {
+		return
+	}
+	if now := time.Now(); force || now.Sub(r.reported) >= VerifyProgressInterval {
+		r.reported = now
+		r.progress(r.read, r.total)
+	}
+}
+
+func (r *verifyReader) Close() error {
+	return r.file.Close()
+}
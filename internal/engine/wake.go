@@ -0,0 +1,31 @@
+package engine
+
+import "time"
+
+// monotonicStart anchors the monotonic readings WakeDetector compares.
+var monotonicStart = time.Now()
+
+// WakeDetector tells from successive observations whether the machine slept
+// in between. The wall clock keeps counting through a suspend while the
+// monotonic clock does not, so after a wake the wall clock has run ahead.
+// The zero value is ready to use.
+type WakeDetector struct {
+	wall time.Time
+	mono time.Duration
+}
+
+// Observe returns how long the machine slept since the last call, or zero.
+// The first call only takes a reading.
+func (w *WakeDetector) Observe() time.Duration {
+	now := time.Now()
+	return w.observe(now.Round(0), now.Sub(monotonicStart))
+}
+
+func (w *WakeDetector) observe(wall time.Time, mono time.Duration) time.Duration {
+	prevWall, prevMono := w.wall, w.mono
+	w.wall, w.mono = wall, mono
+	if prevWall.IsZero() {
+		return 0
+	}
+	return max(wall.Sub(prevWall)-(mono-prevMono), 0)
+}
@@ -0,0 +1,31 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWakeDetector(t *testing.T) {
+	var w WakeDetector
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	if got := w.observe(start, 0); got != 0 {
+		t.Errorf("first observation = %v, want 0", got)
+	}
+	// Both clocks move together while the machine is awake
+	if got := w.observe(start.Add(time.Second), time.Second); got != 0 {
+		t.Errorf("awake = %v, want 0", got)
+	}
+	// The wall clock ran on for an hour the monotonic clock did not see
+	if got := w.observe(start.Add(time.Hour+2*time.Second), 2*time.Second); got != time.Hour {
+		t.Errorf("after sleep = %v, want 1h", got)
+	}
+	// A wall clock set back is not a sleep
+	if got := w.observe(start, 3*time.Second); got != 0 {
+		t.Errorf("clock set back = %v, want 0", got)
+	}
+
+	if got := new(WakeDetector).Observe(); got != 0 {
+		t.Errorf("Observe on a fresh detector = %v, want 0", got)
+	}
+}
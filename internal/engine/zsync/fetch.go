@@ -0,0 +1,191 @@
+package zsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// maxRangeSize splits long runs of missing blocks so they can be fetched
+// over several connections.
+const maxRangeSize = 4 << 20
+
+// Client fetches control files and the blocks of a target no seed had.
+type Client struct {
+	HTTP        *http.Client
+	Headers     map[string]string
+	UserAgent   string
+	Connections int
+	MaxRetries  int
+	// Progress, when set, is called with the bytes fetched so far and the
+	// total to fetch, from several goroutines at once.
+	Progress func(fetched, total int64)
+}
+
+// Control fetches and parses the control file at rawurl.
+func (c *Client) Control(ctx context.Context, rawurl string) (*Control, error) {
+	resp, err := c.get(ctx, rawurl, "")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch control file: %w", &types.HTTPStatusError{StatusCode: resp.StatusCode})
+	}
+	return Parse(resp.Body)
+}
+
+// FetchMissing downloads the ranges of t no seed supplied from rawurl and
+// returns how many bytes it fetched.
+func (c *Client) FetchMissing(ctx context.Context, rawurl string, t *Target) (int64, error) {
+	var jobs []Range
+	var total int64
+	for _, r := range t.Missing() {
+		total += r.End - r.Start
+		for start := r.Start; start < r.End; start += maxRangeSize {
+			jobs = append(jobs, Range{start, min(start+maxRangeSize, r.End)})
+		}
+	}
+	if len(jobs) == 0 {
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	var fetched atomic.Int64
+	queue := make(chan Range)
+	var wg sync.WaitGroup
+	for range max(c.Connections, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range queue {
+				if err := c.fetchRange(ctx, rawurl, t.w, r, &fetched, total); err != nil {
+					cancel(fmt.Errorf("bytes %d-%d: %w", r.Start, r.End-1, err))
+					return
+				}
+			}
+		}()
+	}
+	for _, r := range jobs {
+		select {
+		case queue <- r:
+		case <-ctx.Done():
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	if err := context.Cause(ctx); err != nil {
+		return fetched.Load(), err
+	}
+	for _, r := range jobs {
+		t.Fill(r)
+	}
+	return fetched.Load(), nil
+}
+
+// fetchRange writes r of the file to w, retrying from the start of the range
+// on failure.
+func (c *Client) fetchRange(ctx context.Context, rawurl string, w io.WriterAt, r Range, fetched *atomic.Int64, total int64) error {
+	var lastErr error
+	for attempt := 0; attempt <= c.MaxRetries; attempt++ {
+		if attempt > 0 {
+			wait := time.Duration(attempt) * time.Second
+			var throttle *engine.ThrottleError
+			if errors.As(lastErr, &throttle) {
+				wait = throttle.Delay(attempt - 1)
+			}
+			if err := engine.SleepContext(ctx, wait); err != nil {
+				return err
+			}
+		}
+
+		n, err := c.tryRange(ctx, rawurl, w, r, fetched, total)
+		if err == nil {
+			return nil
+		}
+		// Bytes of a failed attempt are fetched again
+		fetched.Add(-n)
+		if ctx.Err() != nil || errors.Is(err, errNoRanges) {
+			return err
+		}
+		utils.Debug("Zsync: bytes %d-%d attempt %d failed: %v", r.Start, r.End-1, attempt+1, err)
+		lastErr = err
+	}
+	return lastErr
+}
+
+var errNoRanges = errors.New("server does not support range requests")
+
+func (c *Client) tryRange(ctx context.Context, rawurl string, w io.WriterAt, r Range, fetched *atomic.Int64, total int64) (int64, error) {
+	resp, err := c.get(ctx, rawurl, fmt.Sprintf("bytes=%d-%d", r.Start, r.End-1))
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if throttle := engine.CheckThrottle(resp); throttle != nil {
+		return 0, throttle
+	}
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		return 0, errNoRanges
+	default:
+		return 0, &types.HTTPStatusError{StatusCode: resp.StatusCode}
+	}
+	if cr, ok := utils.ParseContentRange(resp.Header.Get("Content-Range")); !ok || cr.Start != r.Start {
+		return 0, fmt.Errorf("unexpected range %q", resp.Header.Get("Content-Range"))
+	}
+
+	buf := make([]byte, 64<<10)
+	var n int64
+	for off := r.Start; off < r.End; {
+		m, err := io.ReadFull(resp.Body, buf[:min(int64(len(buf)), r.End-off)])
+		if m > 0 {
+			if _, werr := w.WriteAt(buf[:m], off); werr != nil {
+				return n, werr
+			}
+			off += int64(m)
+			n += int64(m)
+			done := fetched.Add(int64(m))
+			if c.Progress != nil {
+				c.Progress(done, total)
+			}
+		}
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func (c *Client) get(ctx context.Context, rawurl, byteRange string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawurl, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range c.Headers {
+		req.Header.Set(key, value)
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	if byteRange != "" {
+		req.Header.Set("Range", byteRange)
+	}
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
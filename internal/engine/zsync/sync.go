@@ -0,0 +1,105 @@
+package zsync
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+// Result is the outcome of a sync.
+type Result struct {
+	Reused  int64 // Bytes copied from seeds
+	Fetched int64 // Bytes downloaded
+}
+
+// Sync rebuilds the file ctl describes at path: blocks are taken from the
+// seed files, and from an older version already at path, and the rest is
+// fetched from the control file's URL, resolved against controlURL. The file
+// is assembled beside path and only moves over it once it matches the
+// control file's SHA-1.
+func (c *Client) Sync(ctx context.Context, controlURL string, ctl *Control, path string, seeds []string) (Result, error) {
+	var result Result
+	fileURL, err := ctl.ResolveURL(controlURL)
+	if err != nil {
+		return result, err
+	}
+	if _, err := os.Stat(path); err == nil && !slices.Contains(seeds, path) {
+		seeds = append(seeds, path)
+	}
+
+	working := path + types.IncompleteSuffix
+	f, err := os.OpenFile(working, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0o644)
+	if err != nil {
+		return result, err
+	}
+	err = c.rebuild(ctx, fileURL, ctl, f, seeds, &result)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(working, path)
+	}
+	if err != nil {
+		_ = os.Remove(working)
+		return result, err
+	}
+	if !ctl.MTime.IsZero() {
+		_ = os.Chtimes(path, ctl.MTime, ctl.MTime)
+	}
+	return result, nil
+}
+
+func (c *Client) rebuild(ctx context.Context, fileURL string, ctl *Control, f *os.File, seeds []string, result *Result) error {
+	t := ctl.NewTarget(f)
+	for _, seed := range seeds {
+		if t.Done() {
+			break
+		}
+		if err := addSeed(ctx, t, seed); err != nil {
+			return err
+		}
+	}
+	result.Reused = t.Reused()
+
+	fetched, err := c.FetchMissing(ctx, fileURL, t)
+	result.Fetched = fetched
+	if err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := ctl.VerifySHA1(f); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+func addSeed(ctx context.Context, t *Target, path string) error {
+	seed, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open seed: %w", err)
+	}
+	defer func() { _ = seed.Close() }()
+	if _, err := t.AddSeed(contextReader{ctx, seed}); err != nil {
+		return fmt.Errorf("failed to read seed %s: %w", path, err)
+	}
+	return nil
+}
+
+// contextReader stops a long seed scan once ctx is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,299 @@
+package zsync
+
+import (
+	"bytes"
+	"io"
+	"math/bits"
+
+	"golang.org/x/crypto/md4"
+)
+
+// seedBufferSize is how much of a seed is read at a time.
+const seedBufferSize = 1 << 20
+
+// Range is a byte range of the target, end exclusive.
+type Range struct {
+	Start, End int64
+}
+
+// Target is a file being rebuilt from a control file. Blocks found in seeds
+// are written to it as they are found; Missing lists what is left to fetch.
+type Target struct {
+	c       *Control
+	w       io.WriterAt
+	have    []bool
+	missing int
+	reused  int64
+
+	mask   uint32
+	filter []uint64         // Bloom-style bitset over keys, to skip most lookups
+	shift  uint             // Keys are hashed to 64 - shift bits of filter
+	index  map[uint64][]int // Key of a block, or of a block and the next, to blocks
+}
+
+// NewTarget starts rebuilding the file described by c into w.
+func (c *Control) NewTarget(w io.WriterAt) *Target {
+	n := c.Blocks()
+	t := &Target{c: c, w: w, have: make([]bool, n), missing: n, index: make(map[uint64][]int, n)}
+	t.mask = ^uint32(0) >> (32 - 8*c.rsumBytes)
+
+	size := min(max(bits.Len(uint(n))+3, 10), 26)
+	t.filter = make([]uint64, (1<<size)/64)
+	t.shift = uint(64 - size)
+	for i := range n {
+		key, ok := t.blockKey(i)
+		if !ok {
+			continue
+		}
+		t.index[key] = append(t.index[key], i)
+		h := t.hash(key)
+		t.filter[h/64] |= 1 << (h % 64)
+	}
+	return t
+}
+
+// blockKey is what a window must roll to for block i to be checked. With
+// seqMatches 2 the next block's rolling sum is part of it, as zsync
+// expects; the last block, having no next, is checked by itself in match.
+func (t *Target) blockKey(i int) (uint64, bool) {
+	if t.c.seqMatches == 1 {
+		return uint64(t.c.rsums[i]), true
+	}
+	if i+1 >= len(t.c.rsums) {
+		return 0, false
+	}
+	return uint64(t.c.rsums[i])<<32 | uint64(t.c.rsums[i+1]), true
+}
+
+func (t *Target) hash(key uint64) uint64 {
+	return (key * 0x9e3779b97f4a7c15) >> t.shift
+}
+
+// Reused is how many bytes of the target were found in seeds.
+func (t *Target) Reused() int64 {
+	return t.reused
+}
+
+// Done reports whether every block was found.
+func (t *Target) Done() bool {
+	return t.missing == 0
+}
+
+// Missing returns the ranges still to be fetched, adjacent blocks merged.
+func (t *Target) Missing() []Range {
+	var ranges []Range
+	bs := int64(t.c.Blocksize)
+	for i, have := range t.have {
+		if have {
+			continue
+		}
+		start, end := int64(i)*bs, min(int64(i+1)*bs, t.c.Length)
+		if n := len(ranges); n > 0 && ranges[n-1].End == start {
+			ranges[n-1].End = end
+		} else {
+			ranges = append(ranges, Range{start, end})
+		}
+	}
+	return ranges
+}
+
+// Fill records that r has been written to the target by other means.
+func (t *Target) Fill(r Range) {
+	bs := int64(t.c.Blocksize)
+	for i := r.Start / bs; i*bs < r.End; i++ {
+		if !t.have[i] {
+			t.have[i] = true
+			t.missing--
+		}
+	}
+}
+
+// AddSeed scans r for blocks of the target at any offset, copying each one
+// found, and returns how many bytes it supplied.
+func (t *Target) AddSeed(r io.Reader) (int64, error) {
+	bs := t.c.Blocksize
+	seq := t.c.seqMatches
+	before := t.reused
+	// The padding lets the last, partial block match, as zsync pads it with
+	// zeros when summing it.
+	s := &seedBuffer{r: io.MultiReader(r, bytes.NewReader(make([]byte, bs))), buf: make([]byte, max(seedBufferSize, 4*bs))}
+
+	var a1, b1, a2, b2 uint16
+	var paired bool
+	start := func() (bool, error) {
+		ok, err := s.fill(bs)
+		if !ok || err != nil {
+			return false, err
+		}
+		a1, b1 = rsum(s.window(0, bs))
+		paired = false
+		if seq == 2 {
+			if paired, err = s.fill(2 * bs); err != nil {
+				return false, err
+			}
+			if paired {
+				a2, b2 = rsum(s.window(bs, bs))
+			}
+		}
+		return true, nil
+	}
+	if ok, err := start(); !ok || err != nil {
+		return 0, err
+	}
+
+	for !t.Done() {
+		k1 := (uint32(a1)<<16 | uint32(b1)) & t.mask
+		k2 := (uint32(a2)<<16 | uint32(b2)) & t.mask
+		matched, err := t.match(s, k1, k2, paired)
+		if err != nil {
+			return t.reused - before, err
+		}
+		if matched {
+			s.p += bs
+			if ok, err := start(); !ok || err != nil {
+				return t.reused - before, err
+			}
+			continue
+		}
+
+		need := bs + 1
+		if paired {
+			need = 2*bs + 1
+		}
+		ok, err := s.fill(need)
+		if err != nil {
+			return t.reused - before, err
+		}
+		if !ok {
+			if !paired {
+				break
+			}
+			// Too near the end for a pair; only the last block can match
+			paired = false
+			continue
+		}
+		a1, b1 = roll(a1, b1, s.buf[s.p], s.buf[s.p+bs], bs)
+		if paired {
+			a2, b2 = roll(a2, b2, s.buf[s.p+bs], s.buf[s.p+2*bs], bs)
+		}
+		s.p++
+	}
+	return t.reused - before, nil
+}
+
+// match checks the window at the start of s against the blocks its keys
+// point to, writing it to each one it is, and reports whether any was.
+func (t *Target) match(s *seedBuffer, k1, k2 uint32, paired bool) (bool, error) {
+	bs := t.c.Blocksize
+	var candidates []int
+	if t.c.seqMatches == 1 || paired {
+		key := uint64(k1)
+		if t.c.seqMatches == 2 {
+			key = key<<32 | uint64(k2)
+		}
+		if h := t.hash(key); t.filter[h/64]&(1<<(h%64)) != 0 {
+			candidates = t.index[key]
+		}
+	}
+	if last := len(t.have) - 1; t.c.seqMatches == 2 && last >= 0 && !t.have[last] && k1 == t.c.rsums[last] {
+		candidates = append(candidates[:len(candidates):len(candidates)], last)
+	}
+
+	var sum, next []byte
+	matched := false
+	for _, i := range candidates {
+		if t.have[i] {
+			continue
+		}
+		if sum == nil {
+			sum = checksum(s.window(0, bs))
+		}
+		if !bytes.Equal(sum[:t.c.checksumBytes], t.checksum(i)) {
+			continue
+		}
+		if t.c.seqMatches == 2 && i+1 < len(t.have) {
+			if next == nil {
+				next = checksum(s.window(bs, bs))
+			}
+			if !bytes.Equal(next[:t.c.checksumBytes], t.checksum(i+1)) {
+				continue
+			}
+		}
+		off := int64(i) * int64(bs)
+		n := min(int64(bs), t.c.Length-off)
+		if _, err := t.w.WriteAt(s.window(0, int(n)), off); err != nil {
+			return false, err
+		}
+		t.have[i] = true
+		t.missing--
+		t.reused += n
+		matched = true
+	}
+	return matched, nil
+}
+
+func (t *Target) checksum(i int) []byte {
+	n := t.c.checksumBytes
+	return t.c.checksums[i*n : (i+1)*n]
+}
+
+// rsum is zsync's rolling checksum of a block.
+func rsum(block []byte) (a, b uint16) {
+	n := len(block)
+	for i, c := range block {
+		a += uint16(c)
+		b += uint16(n-i) * uint16(c)
+	}
+	return a, b
+}
+
+// roll moves the rolling checksum of a window of size bs one byte on,
+// dropping old and taking in next.
+func roll(a, b uint16, old, next byte, bs int) (uint16, uint16) {
+	a += uint16(next) - uint16(old)
+	b += a - uint16(uint32(old)*uint32(bs))
+	return a, b
+}
+
+// checksum is the strong checksum of a block, of which the control file
+// keeps a prefix.
+func checksum(block []byte) []byte {
+	h := md4.New()
+	_, _ = h.Write(block)
+	return h.Sum(nil)
+}
+
+// seedBuffer holds the part of a seed being scanned, from p on.
+type seedBuffer struct {
+	r   io.Reader
+	buf []byte
+	p   int
+	end int
+	eof bool
+}
+
+// fill makes n bytes from p available and reports whether the seed had
+// them.
+func (s *seedBuffer) fill(n int) (bool, error) {
+	if s.end-s.p >= n {
+		return true, nil
+	}
+	if s.p+n > len(s.buf) {
+		s.end = copy(s.buf, s.buf[s.p:s.end])
+		s.p = 0
+	}
+	for s.end-s.p < n && !s.eof {
+		m, err := s.r.Read(s.buf[s.end:])
+		s.end += m
+		if err == io.EOF {
+			s.eof = true
+		} else if err != nil {
+			return false, err
+		}
+	}
+	return s.end-s.p >= n, nil
+}
+
+func (s *seedBuffer) window(off, n int) []byte {
+	return s.buf[s.p+off : s.p+off+n]
+}
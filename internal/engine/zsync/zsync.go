@@ -0,0 +1,176 @@
+// Package zsync rebuilds a file from a .zsync control file: blocks already
+// present in local seed files, such as yesterday's image, are found with the
+// control file's rolling checksums and copied, and only the rest is fetched
+// over HTTP ranges.
+package zsync
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxBlocks bounds the block table read from a control file.
+const maxBlocks = 1 << 26
+
+var (
+	ErrInvalidControl = errors.New("invalid zsync control file")
+	ErrChecksum       = errors.New("rebuilt file does not match the control file's SHA-1")
+)
+
+// Control is a parsed .zsync control file.
+type Control struct {
+	Filename  string
+	MTime     time.Time // Zero when the control file has none
+	Blocksize int
+	Length    int64
+	URL       string // As written, possibly relative to the control file
+	SHA1      string // Lowercase hex
+
+	seqMatches    int // 1 or 2 consecutive blocks must match
+	rsumBytes     int
+	checksumBytes int
+	rsums         []uint32
+	checksums     []byte // checksumBytes per block
+}
+
+// Blocks is the number of blocks the file is split into.
+func (c *Control) Blocks() int {
+	return len(c.rsums)
+}
+
+// ResolveURL returns the URL of the file the control file describes,
+// resolved against base, the URL the control file came from.
+func (c *Control) ResolveURL(base string) (string, error) {
+	ref, err := url.Parse(c.URL)
+	if err != nil {
+		return "", fmt.Errorf("%w: bad URL %q", ErrInvalidControl, c.URL)
+	}
+	if ref.IsAbs() {
+		return ref.String(), nil
+	}
+	b, err := url.Parse(base)
+	if err != nil || !b.IsAbs() {
+		return "", fmt.Errorf("control file names the file by the relative URL %q, but was not fetched from a URL", c.URL)
+	}
+	return b.ResolveReference(ref).String(), nil
+}
+
+// Parse reads a control file: "Key: value" header lines, a blank line, then
+// the rolling and strong checksum of each block.
+func Parse(r io.Reader) (*Control, error) {
+	br := bufio.NewReader(r)
+	c := &Control{seqMatches: 1, rsumBytes: 4, checksumBytes: 16}
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("%w: headers end early", ErrInvalidControl)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: bad header %q", ErrInvalidControl, line)
+		}
+		if err := c.setHeader(strings.TrimSpace(key), strings.TrimSpace(value)); err != nil {
+			return nil, err
+		}
+	}
+	if c.Blocksize <= 0 || c.Length < 0 || c.URL == "" || c.SHA1 == "" {
+		return nil, fmt.Errorf("%w: Blocksize, Length, URL and SHA-1 are required", ErrInvalidControl)
+	}
+
+	blocks := (c.Length + int64(c.Blocksize) - 1) / int64(c.Blocksize)
+	if blocks > maxBlocks {
+		return nil, fmt.Errorf("%w: %d blocks", ErrInvalidControl, blocks)
+	}
+	c.rsums = make([]uint32, blocks)
+	c.checksums = make([]byte, blocks*int64(c.checksumBytes))
+	entry := make([]byte, c.rsumBytes+c.checksumBytes)
+	for i := range c.rsums {
+		if _, err := io.ReadFull(br, entry); err != nil {
+			return nil, fmt.Errorf("%w: block table ends at block %d of %d", ErrInvalidControl, i, blocks)
+		}
+		// The rolling sum is stored as its last rsumBytes big-endian bytes
+		var rsum [4]byte
+		copy(rsum[4-c.rsumBytes:], entry[:c.rsumBytes])
+		c.rsums[i] = binary.BigEndian.Uint32(rsum[:])
+		copy(c.checksums[i*c.checksumBytes:], entry[c.rsumBytes:])
+	}
+	return c, nil
+}
+
+func (c *Control) setHeader(key, value string) error {
+	var err error
+	switch strings.ToLower(key) {
+	case "zsync":
+		// Versions before 0.6 laid out blocks differently
+		major, rest, _ := strings.Cut(value, ".")
+		minor, _, _ := strings.Cut(rest, ".")
+		if n, _ := strconv.Atoi(minor); major == "0" && n < 6 {
+			return fmt.Errorf("%w: version %s is too old", ErrInvalidControl, value)
+		}
+	case "filename":
+		c.Filename = value
+	case "mtime":
+		c.MTime, _ = time.Parse(time.RFC1123Z, value)
+	case "blocksize":
+		c.Blocksize, err = strconv.Atoi(value)
+		if err == nil && (c.Blocksize <= 0 || c.Blocksize > 1<<24 || c.Blocksize&(c.Blocksize-1) != 0) {
+			err = errors.New("not a power of two")
+		}
+	case "length":
+		c.Length, err = strconv.ParseInt(value, 10, 64)
+	case "hash-lengths":
+		parts := strings.Split(value, ",")
+		if len(parts) != 3 {
+			return fmt.Errorf("%w: bad Hash-Lengths %q", ErrInvalidControl, value)
+		}
+		var n [3]int
+		for i, p := range parts {
+			if n[i], err = strconv.Atoi(strings.TrimSpace(p)); err != nil {
+				break
+			}
+		}
+		c.seqMatches, c.rsumBytes, c.checksumBytes = n[0], n[1], n[2]
+		if err == nil && (c.seqMatches < 1 || c.seqMatches > 2 || c.rsumBytes < 1 || c.rsumBytes > 4 || c.checksumBytes < 3 || c.checksumBytes > 16) {
+			err = errors.New("out of range")
+		}
+	case "url":
+		// The first URL wins; later ones are alternatives
+		if c.URL == "" {
+			c.URL = value
+		}
+	case "sha-1":
+		if _, err = hex.DecodeString(value); err == nil && len(value) != 2*sha1.Size {
+			err = errors.New("wrong length")
+		}
+		c.SHA1 = strings.ToLower(value)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: bad %s %q: %v", ErrInvalidControl, key, value, err)
+	}
+	return nil
+}
+
+// VerifySHA1 checks the rebuilt file read from r against the control file.
+func (c *Control) VerifySHA1(r io.Reader) error {
+	h := sha1.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != c.SHA1 {
+		return fmt.Errorf("%w: got %s", ErrChecksum, got)
+	}
+	return nil
+}
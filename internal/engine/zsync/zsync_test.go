@@ -0,0 +1,178 @@
+package zsync
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testContent(seed int64, size int) []byte {
+	data := make([]byte, size)
+	rand.New(rand.NewSource(seed)).Read(data)
+	return data
+}
+
+// makeControl writes a control file for data the way zsyncmake does.
+func makeControl(data []byte, blocksize, seqMatches, rsumBytes, checksumBytes int, fileURL string) []byte {
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "zsync: 0.6.2\nFilename: new.img\nMTime: Tue, 06 Oct 2026 10:00:00 +0000\nBlocksize: %d\nLength: %d\n", blocksize, len(data))
+	fmt.Fprintf(&out, "Hash-Lengths: %d,%d,%d\nURL: %s\nSHA-1: %x\n\n", seqMatches, rsumBytes, checksumBytes, fileURL, sha1.Sum(data))
+	for off := 0; off < len(data); off += blocksize {
+		block := make([]byte, blocksize)
+		copy(block, data[off:])
+		a, b := rsum(block)
+		var r [4]byte
+		binary.BigEndian.PutUint16(r[:2], a)
+		binary.BigEndian.PutUint16(r[2:], b)
+		out.Write(r[4-rsumBytes:])
+		out.Write(checksum(block)[:checksumBytes])
+	}
+	return out.Bytes()
+}
+
+func TestRoll_MatchesAFreshSum(t *testing.T) {
+	data := testContent(1, 5000)
+	const bs = 1024
+	a, b := rsum(data[:bs])
+	for i := 0; i+bs < len(data); i++ {
+		a, b = roll(a, b, data[i], data[i+bs], bs)
+		if wa, wb := rsum(data[i+1 : i+1+bs]); a != wa || b != wb {
+			t.Fatalf("offset %d: rolled (%d, %d), want (%d, %d)", i+1, a, b, wa, wb)
+		}
+	}
+}
+
+func TestSync_ReusesSeedBlocksAndFetchesTheRest(t *testing.T) {
+	for _, lengths := range [][3]int{{1, 4, 16}, {2, 2, 5}, {2, 3, 8}} {
+		t.Run(fmt.Sprintf("%d,%d,%d", lengths[0], lengths[1], lengths[2]), func(t *testing.T) {
+			const bs = 2048
+			// The new version has bytes inserted near the start, a changed
+			// middle, and a new partial block at the end.
+			old := testContent(1, 400*bs+100)
+			updated := append(append([]byte{}, old[:10*bs]...), testContent(2, 777)...)
+			updated = append(updated, old[10*bs:200*bs]...)
+			updated = append(updated, testContent(3, 5*bs)...)
+			updated = append(updated, old[205*bs:]...)
+			updated = append(updated, testContent(4, 1234)...)
+
+			var served atomic.Int64
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/new.img.zsync":
+					_, _ = w.Write(makeControl(updated, bs, lengths[0], lengths[1], lengths[2], "new.img"))
+				case "/new.img":
+					rec := httptest.NewRecorder()
+					http.ServeContent(rec, r, "new.img", time.Time{}, bytes.NewReader(updated))
+					served.Add(int64(rec.Body.Len()))
+					for k, v := range rec.Header() {
+						w.Header()[k] = v
+					}
+					w.WriteHeader(rec.Code)
+					_, _ = w.Write(rec.Body.Bytes())
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer srv.Close()
+
+			dir := t.TempDir()
+			seed := filepath.Join(dir, "old.img")
+			if err := os.WriteFile(seed, old, 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			c := &Client{HTTP: srv.Client(), Connections: 3}
+			controlURL := srv.URL + "/new.img.zsync"
+			ctl, err := c.Control(context.Background(), controlURL)
+			if err != nil {
+				t.Fatalf("Control: %v", err)
+			}
+			path := filepath.Join(dir, "new.img")
+			result, err := c.Sync(context.Background(), controlURL, ctl, path, []string{seed})
+			if err != nil {
+				t.Fatalf("Sync: %v", err)
+			}
+
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, updated) {
+				t.Fatalf("rebuilt %d bytes that differ from the %d of the new version", len(got), len(updated))
+			}
+			if result.Reused+result.Fetched != int64(len(updated)) || result.Fetched != served.Load() {
+				t.Errorf("result = %+v, served %d, want the two to add up to %d", result, served.Load(), len(updated))
+			}
+			if result.Fetched > 12*bs {
+				t.Errorf("fetched %d bytes, want only the changed blocks", result.Fetched)
+			}
+			if info, _ := os.Stat(path); !info.ModTime().Equal(ctl.MTime) {
+				t.Errorf("mtime = %v, want %v", info.ModTime(), ctl.MTime)
+			}
+		})
+	}
+}
+
+func TestSync_FailsOnAChecksumMismatch(t *testing.T) {
+	const bs = 1024
+	content := testContent(1, 10*bs)
+	control := makeControl(content, bs, 1, 4, 16, "file.img")
+	served := bytes.Clone(content)
+	served[5*bs] ^= 0xff
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "file.img", time.Time{}, bytes.NewReader(served))
+	}))
+	defer srv.Close()
+
+	ctl, err := Parse(bytes.NewReader(control))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "file.img")
+	c := &Client{HTTP: srv.Client()}
+	if _, err := c.Sync(context.Background(), srv.URL+"/file.img.zsync", ctl, path, nil); !errors.Is(err, ErrChecksum) {
+		t.Fatalf("Sync = %v, want ErrChecksum", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 0 {
+		t.Errorf("left %d files behind", len(entries))
+	}
+}
+
+func TestParse_RejectsBadControlFiles(t *testing.T) {
+	good := string(makeControl(testContent(1, 3000), 1024, 2, 2, 5, "http://example.com/a.img"))
+	for name, control := range map[string]string{
+		"truncated table": good[:len(good)-3],
+		"no blank line":   "zsync: 0.6.2\nBlocksize: 1024\n",
+		"odd blocksize":   strings.Replace(good, "Blocksize: 1024", "Blocksize: 1000", 1),
+		"no URL":          strings.Replace(good, "URL: http://example.com/a.img\n", "", 1),
+		"old version":     strings.Replace(good, "zsync: 0.6.2", "zsync: 0.5", 1),
+		"three in a row":  strings.Replace(good, "Hash-Lengths: 2,2,5", "Hash-Lengths: 3,2,5", 1),
+	} {
+		if _, err := Parse(strings.NewReader(control)); !errors.Is(err, ErrInvalidControl) {
+			t.Errorf("%s: Parse = %v, want ErrInvalidControl", name, err)
+		}
+	}
+
+	ctl, err := Parse(strings.NewReader(good))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctl.Blocks() != 3 || ctl.Length != 3000 || ctl.Filename != "new.img" {
+		t.Errorf("control = %+v", ctl)
+	}
+	if got, _ := ctl.ResolveURL("https://mirror.example/x/a.img.zsync"); got != "http://example.com/a.img" {
+		t.Errorf("ResolveURL = %q", got)
+	}
+}
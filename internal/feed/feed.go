@@ -0,0 +1,129 @@
+// Package feed reads RSS and Atom feeds and keeps the list of feeds the
+// daemon polls for new downloads.
+package feed
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxFeedSize caps how much of a feed document is read.
+const maxFeedSize = 10 << 20
+
+// ErrNotFeed is returned for documents that are neither RSS nor Atom.
+var ErrNotFeed = errors.New("not an RSS or Atom feed")
+
+// Item is one entry of a feed that points at something to download.
+type Item struct {
+	Key   string // Stable identity: the guid or Atom id, else the URL
+	Title string
+	URL   string // The enclosure when there is one, else the item's link
+}
+
+type rssItem struct {
+	Title     string `xml:"title"`
+	Link      string `xml:"link"`
+	GUID      string `xml:"guid"`
+	Enclosure struct {
+		URL string `xml:"url,attr"`
+	} `xml:"enclosure"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+type atomEntry struct {
+	Title string     `xml:"title"`
+	ID    string     `xml:"id"`
+	Links []atomLink `xml:"link"`
+}
+
+type document struct {
+	XMLName xml.Name
+	Channel struct {
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items   []rssItem   `xml:"item"` // RSS 1.0 keeps items beside the channel
+	Entries []atomEntry `xml:"entry"`
+}
+
+// Parse reads an RSS 0.9x/1.0/2.0 or Atom document and returns its items
+// in document order. Items without any link are dropped.
+func Parse(r io.Reader) ([]Item, error) {
+	var doc document
+	decoder := xml.NewDecoder(io.LimitReader(r, maxFeedSize))
+	decoder.Strict = false
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNotFeed, err)
+	}
+
+	var items []Item
+	switch strings.ToLower(doc.XMLName.Local) {
+	case "rss", "rdf":
+		for _, it := range append(doc.Channel.Items, doc.Items...) {
+			items = appendItem(items, strings.TrimSpace(it.GUID), it.Title, it.Enclosure.URL, it.Link)
+		}
+	case "feed":
+		for _, entry := range doc.Entries {
+			var enclosure, alternate string
+			for _, link := range entry.Links {
+				switch link.Rel {
+				case "enclosure":
+					if enclosure == "" {
+						enclosure = link.Href
+					}
+				case "", "alternate":
+					if alternate == "" {
+						alternate = link.Href
+					}
+				}
+			}
+			items = appendItem(items, strings.TrimSpace(entry.ID), entry.Title, enclosure, alternate)
+		}
+	default:
+		return nil, ErrNotFeed
+	}
+	return items, nil
+}
+
+func appendItem(items []Item, key, title, enclosure, link string) []Item {
+	url := strings.TrimSpace(enclosure)
+	if url == "" {
+		url = strings.TrimSpace(link)
+	}
+	if url == "" {
+		return items
+	}
+	if key == "" {
+		key = url
+	}
+	return append(items, Item{Key: key, Title: strings.TrimSpace(title), URL: url})
+}
+
+// Fetch downloads and parses the feed at url.
+func Fetch(ctx context.Context, client *http.Client, url string) ([]Item, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.8")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("feed returned %s", resp.Status)
+	}
+	return Parse(resp.Body)
+}
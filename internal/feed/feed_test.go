@@ -0,0 +1,145 @@
+package feed
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		doc  string
+		want []Item
+	}{
+		{
+			name: "rss 2.0 prefers the enclosure",
+			doc: `<?xml version="1.0"?><rss version="2.0"><channel><title>Releases</title>
+				<item><title>Distro 1.0</title><link>https://example.com/1.0</link><guid>rel-1.0</guid>
+				<enclosure url="https://example.com/distro-1.0.iso" type="application/octet-stream" length="1"/></item>
+				<item><title>Notes</title><link>https://example.com/notes</link></item>
+				<item><title>No link</title></item>
+			</channel></rss>`,
+			want: []Item{
+				{Key: "rel-1.0", Title: "Distro 1.0", URL: "https://example.com/distro-1.0.iso"},
+				{Key: "https://example.com/notes", Title: "Notes", URL: "https://example.com/notes"},
+			},
+		},
+		{
+			name: "rss 1.0",
+			doc: `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/">
+				<channel><title>Old</title></channel>
+				<item><title>One</title><link>https://example.com/one.zip</link></item>
+			</rdf:RDF>`,
+			want: []Item{{Key: "https://example.com/one.zip", Title: "One", URL: "https://example.com/one.zip"}},
+		},
+		{
+			name: "atom",
+			doc: `<feed xmlns="http://www.w3.org/2005/Atom"><title>Builds</title>
+				<entry><title>Build 7</title><id>tag:example.com,2026:7</id>
+					<link rel="alternate" href="https://example.com/builds/7"/>
+					<link rel="enclosure" href="https://example.com/builds/7.tar.gz"/></entry>
+				<entry><title>Build 6</title><id>tag:example.com,2026:6</id><link href="https://example.com/builds/6"/></entry>
+			</feed>`,
+			want: []Item{
+				{Key: "tag:example.com,2026:7", Title: "Build 7", URL: "https://example.com/builds/7.tar.gz"},
+				{Key: "tag:example.com,2026:6", Title: "Build 6", URL: "https://example.com/builds/6"},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := Parse(strings.NewReader(tt.doc))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if len(items) != len(tt.want) {
+				t.Fatalf("got %d items %+v, want %+v", len(items), items, tt.want)
+			}
+			for i := range items {
+				if items[i] != tt.want[i] {
+					t.Errorf("item %d = %+v, want %+v", i, items[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	for _, doc := range []string{"<html><body>hi</body></html>", "not xml at all"} {
+		if _, err := Parse(strings.NewReader(doc)); !errors.Is(err, ErrNotFeed) {
+			t.Errorf("Parse(%q) error = %v, want ErrNotFeed", doc, err)
+		}
+	}
+}
+
+func TestFeedFilters(t *testing.T) {
+	f := Feed{Include: `\.iso$`, Exclude: `(?i)beta`, Interval: "1h"}
+	for _, tt := range []struct {
+		item Item
+		want bool
+	}{
+		{Item{URL: "https://example.com/distro.iso"}, true},
+		{Item{URL: "https://example.com/distro.tar.gz"}, false},
+		{Item{URL: "https://example.com/distro-beta.iso"}, false},
+		{Item{URL: "https://example.com/distro.iso", Title: "Distro BETA"}, false},
+	} {
+		if got := f.Matches(tt.item); got != tt.want {
+			t.Errorf("Matches(%+v) = %v, want %v", tt.item, got, tt.want)
+		}
+	}
+
+	if err := (Feed{Include: "(", Interval: "1h"}).Validate(time.Minute); err == nil {
+		t.Error("Validate accepted a broken include pattern")
+	}
+	if err := (Feed{Interval: "30s"}).Validate(time.Minute); err == nil {
+		t.Error("Validate accepted an interval below the minimum")
+	}
+	if err := f.Validate(time.Minute); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+}
+
+func TestFeedDueAndSeen(t *testing.T) {
+	now := time.Now()
+	f := Feed{Interval: "1h"}
+	if !f.Due(now) {
+		t.Error("a feed that was never checked is not due")
+	}
+	f.LastChecked = now.Add(-30 * time.Minute)
+	if f.Due(now) {
+		t.Error("feed is due before its interval passed")
+	}
+	if !f.Due(now.Add(30 * time.Minute)) {
+		t.Error("feed is not due once its interval passed")
+	}
+
+	f.MarkSeen("a", "b", "a")
+	if len(f.Seen) != 2 || !f.HasSeen("a") || f.HasSeen("c") {
+		t.Errorf("Seen = %v", f.Seen)
+	}
+	for i := range maxSeen {
+		f.MarkSeen(time.Duration(i).String())
+	}
+	if len(f.Seen) != maxSeen || f.HasSeen("a") {
+		t.Errorf("MarkSeen kept %d keys (first %q), want the newest %d", len(f.Seen), f.Seen[0], maxSeen)
+	}
+}
+
+func TestLoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "feeds.json")
+	if feeds, err := Load(path); err != nil || feeds != nil {
+		t.Fatalf("Load on a missing file = %v, %v", feeds, err)
+	}
+
+	want := []Feed{{ID: "f1", URL: "https://example.com/rss", Interval: "2h", Seen: []string{"x"}}}
+	if err := Save(path, want); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(got) != 1 || got[0].URL != want[0].URL || got[0].Interval != "2h" || !got[0].HasSeen("x") {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,133 @@
+package feed
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// maxSeen is how many item keys a feed remembers. Feeds only list their
+// latest items, so older keys can be forgotten.
+const maxSeen = 2000
+
+// Feed is a feed the daemon polls, with the filters that pick which of its
+// items are queued.
+type Feed struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Include     string    `json:"include,omitempty"` // Regexp an item's URL or title must match
+	Exclude     string    `json:"exclude,omitempty"` // Regexp an item's URL or title must not match
+	Interval    string    `json:"interval"`          // Poll interval, a Go duration such as "1h"
+	OutputDir   string    `json:"output_dir,omitempty"`
+	LastChecked time.Time `json:"last_checked,omitzero"`
+	LastError   string    `json:"last_error,omitempty"`
+	Seen        []string  `json:"seen,omitempty"` // Keys of items already handled, oldest first
+}
+
+// Validate checks that the filters compile and the interval is at least
+// minInterval.
+func (f Feed) Validate(minInterval time.Duration) error {
+	if _, err := regexp.Compile(f.Include); err != nil {
+		return fmt.Errorf("invalid include pattern: %w", err)
+	}
+	if _, err := regexp.Compile(f.Exclude); err != nil {
+		return fmt.Errorf("invalid exclude pattern: %w", err)
+	}
+	interval, err := time.ParseDuration(f.Interval)
+	if err != nil {
+		return fmt.Errorf("invalid interval: %w", err)
+	}
+	if interval < minInterval {
+		return fmt.Errorf("interval must be at least %s", minInterval)
+	}
+	return nil
+}
+
+// Due reports whether the feed should be polled at now.
+func (f Feed) Due(now time.Time) bool {
+	interval, err := time.ParseDuration(f.Interval)
+	if err != nil {
+		return false
+	}
+	return f.LastChecked.IsZero() || !now.Before(f.LastChecked.Add(interval))
+}
+
+// Matches reports whether item passes the feed's filters. Each pattern is
+// tried against the item's URL and its title.
+func (f Feed) Matches(item Item) bool {
+	if f.Include != "" {
+		re, err := regexp.Compile(f.Include)
+		if err != nil || !(re.MatchString(item.URL) || re.MatchString(item.Title)) {
+			return false
+		}
+	}
+	if f.Exclude != "" {
+		re, err := regexp.Compile(f.Exclude)
+		if err != nil || re.MatchString(item.URL) || re.MatchString(item.Title) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasSeen reports whether the item with key was already handled.
+func (f Feed) HasSeen(key string) bool {
+	for _, seen := range f.Seen {
+		if seen == key {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkSeen records keys as handled, forgetting the oldest beyond maxSeen.
+func (f *Feed) MarkSeen(keys ...string) {
+	for _, key := range keys {
+		if !f.HasSeen(key) {
+			f.Seen = append(f.Seen, key)
+		}
+	}
+	if len(f.Seen) > maxSeen {
+		f.Seen = f.Seen[len(f.Seen)-maxSeen:]
+	}
+}
+
+// Load reads the feed list at path. A missing file means no feeds.
+func Load(path string) ([]Feed, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var feeds []Feed
+	if err := json.Unmarshal(data, &feeds); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+	}
+	return feeds, nil
+}
+
+// Save writes the feed list to path, replacing it in one rename so a crash
+// never leaves a half-written file.
+func Save(path string, feeds []Feed) error {
+	if feeds == nil {
+		feeds = []Feed{}
+	}
+	data, err := json.MarshalIndent(feeds, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,193 @@
+// Package ghrelease looks up GitHub release assets and the checksums
+// published alongside them.
+package ghrelease
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/SurgeDM/Surge/internal/version"
+)
+
+// DefaultAPIURL is the GitHub REST API.
+const DefaultAPIURL = "https://api.github.com"
+
+var (
+	ErrNotFound    = errors.New("release not found")
+	ErrRateLimited = errors.New("GitHub API rate limit exceeded, pass a token to raise it")
+)
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+	Size int64  `json:"size"`
+}
+
+// Release is a GitHub release and its assets.
+type Release struct {
+	TagName    string  `json:"tag_name"`
+	Name       string  `json:"name"`
+	Prerelease bool    `json:"prerelease"`
+	Assets     []Asset `json:"assets"`
+}
+
+// Client queries the GitHub API, authenticated when Token is set.
+type Client struct {
+	HTTP    *http.Client
+	BaseURL string // Defaults to DefaultAPIURL
+	Token   string
+}
+
+// ParseRepoRef splits "owner/repo" or "owner/repo@tag".
+func ParseRepoRef(ref string) (owner, repo, tag string, err error) {
+	name, tag, _ := strings.Cut(strings.TrimSpace(ref), "@")
+	owner, repo, ok := strings.Cut(name, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return "", "", "", fmt.Errorf("invalid repository %q: use owner/repo or owner/repo@tag", ref)
+	}
+	return owner, repo, tag, nil
+}
+
+// Release returns the release tagged tag, or the latest non-prerelease one
+// when tag is empty.
+func (c *Client) Release(ctx context.Context, owner, repo, tag string) (*Release, error) {
+	endpoint := fmt.Sprintf("/repos/%s/%s/releases/latest", url.PathEscape(owner), url.PathEscape(repo))
+	if tag != "" {
+		endpoint = fmt.Sprintf("/repos/%s/%s/releases/tags/%s", url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(tag))
+	}
+
+	resp, err := c.get(ctx, c.baseURL()+endpoint, "application/vnd.github+json")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("invalid release response: %w", err)
+	}
+	return &release, nil
+}
+
+// Match returns the assets whose names match any of the glob patterns, in
+// release order.
+func (r *Release) Match(patterns []string) ([]Asset, error) {
+	var matched []Asset
+	for _, a := range r.Assets {
+		for _, pattern := range patterns {
+			ok, err := path.Match(pattern, a.Name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid asset pattern %q: %w", pattern, err)
+			}
+			if ok {
+				matched = append(matched, a)
+				break
+			}
+		}
+	}
+	return matched, nil
+}
+
+// ChecksumAsset returns the file holding the checksum of asset: a
+// "<name>.sha256" beside it, else a combined checksums.txt or SHA256SUMS.
+func (r *Release) ChecksumAsset(asset Asset) (Asset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == asset.Name+".sha256" || a.Name == asset.Name+".sha256sum" {
+			return a, true
+		}
+	}
+	for _, a := range r.Assets {
+		lower := strings.ToLower(a.Name)
+		if strings.HasSuffix(lower, "checksums.txt") || strings.HasPrefix(lower, "sha256sums") {
+			return a, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Checksum downloads checksumFile and returns the digest it lists for name
+// as sha256:<hex>. A file holding a single bare digest is taken to be for
+// name.
+func (c *Client) Checksum(ctx context.Context, checksumFile Asset, name string) (string, error) {
+	resp, err := c.get(ctx, checksumFile.URL, "application/octet-stream")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if sum, err := version.ChecksumFor(body, name); err == nil {
+		return sum, nil
+	}
+	if fields := strings.Fields(string(body)); len(fields) == 1 && isHexDigest(fields[0]) {
+		return "sha256:" + strings.ToLower(fields[0]), nil
+	}
+	return "", fmt.Errorf("%s lists no checksum for %s", checksumFile.Name, name)
+}
+
+func (c *Client) get(ctx context.Context, rawURL, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("User-Agent", "Surge")
+	// The token is only sent to the API, not to wherever assets are hosted
+	isAPI := strings.HasPrefix(rawURL, c.baseURL())
+	if c.Token != "" && isAPI {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	client := c.HTTP
+	if client == nil {
+		client = &http.Client{Timeout: version.RequestTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return resp, nil
+	case resp.StatusCode == http.StatusNotFound && isAPI:
+		err = ErrNotFound
+	case (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests) &&
+		resp.Header.Get("X-RateLimit-Remaining") == "0":
+		err = ErrRateLimited
+	default:
+		err = fmt.Errorf("GitHub returned %s", resp.Status)
+	}
+	_ = resp.Body.Close()
+	return nil, err
+}
+
+func (c *Client) baseURL() string {
+	if c.BaseURL != "" {
+		return strings.TrimRight(c.BaseURL, "/")
+	}
+	return DefaultAPIURL
+}
+
+func isHexDigest(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
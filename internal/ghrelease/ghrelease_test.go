@@ -0,0 +1,130 @@
+package ghrelease
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const digest = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+func newTestServer(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+	var authorized []string
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	release := func(tag string) string {
+		return fmt.Sprintf(`{"tag_name": %q, "name": "Release", "assets": [
+			{"name": "tool_linux_amd64.tar.gz", "browser_download_url": "%[2]s/dl/tool_linux_amd64.tar.gz", "size": 5},
+			{"name": "tool_windows_amd64.zip", "browser_download_url": "%[2]s/dl/tool_windows_amd64.zip", "size": 5},
+			{"name": "checksums.txt", "browser_download_url": "%[2]s/dl/checksums.txt", "size": 100},
+			{"name": "tool_windows_amd64.zip.sha256", "browser_download_url": "%[2]s/dl/tool_windows_amd64.zip.sha256", "size": 64}
+		]}`, tag, server.URL)
+	}
+	mux.HandleFunc("/api/repos/acme/tool/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		authorized = append(authorized, r.URL.Path+" "+r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(release("v2.0.0")))
+	})
+	mux.HandleFunc("/api/repos/acme/tool/releases/tags/v1.0.0", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(release("v1.0.0")))
+	})
+	mux.HandleFunc("/api/repos/acme/limited/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.WriteHeader(http.StatusForbidden)
+	})
+	mux.HandleFunc("/dl/checksums.txt", func(w http.ResponseWriter, r *http.Request) {
+		authorized = append(authorized, r.URL.Path+" "+r.Header.Get("Authorization"))
+		_, _ = fmt.Fprintf(w, "%s  tool_linux_amd64.tar.gz\n%s *other.bin\n", digest, strings.Repeat("0", 64))
+	})
+	mux.HandleFunc("/dl/tool_windows_amd64.zip.sha256", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.ToUpper(digest) + "\n"))
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &authorized
+}
+
+func TestParseRepoRef(t *testing.T) {
+	for _, tt := range []struct{ ref, owner, repo, tag string }{
+		{"acme/tool", "acme", "tool", ""},
+		{" acme/tool@v1.2.0 ", "acme", "tool", "v1.2.0"},
+	} {
+		owner, repo, tag, err := ParseRepoRef(tt.ref)
+		if err != nil || owner != tt.owner || repo != tt.repo || tag != tt.tag {
+			t.Errorf("ParseRepoRef(%q) = %q, %q, %q, %v", tt.ref, owner, repo, tag, err)
+		}
+	}
+	for _, ref := range []string{"tool", "/tool", "acme/", "acme/tool/extra"} {
+		if _, _, _, err := ParseRepoRef(ref); err == nil {
+			t.Errorf("ParseRepoRef(%q) accepted an invalid reference", ref)
+		}
+	}
+}
+
+func TestClientRelease(t *testing.T) {
+	server, authorized := newTestServer(t)
+	client := &Client{BaseURL: server.URL + "/api", Token: "secret"}
+	ctx := context.Background()
+
+	latest, err := client.Release(ctx, "acme", "tool", "")
+	if err != nil || latest.TagName != "v2.0.0" || len(latest.Assets) != 4 {
+		t.Fatalf("latest = %+v, %v", latest, err)
+	}
+	if tagged, err := client.Release(ctx, "acme", "tool", "v1.0.0"); err != nil || tagged.TagName != "v1.0.0" {
+		t.Errorf("tagged = %+v, %v", tagged, err)
+	}
+	if _, err := client.Release(ctx, "acme", "missing", ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing repo error = %v, want ErrNotFound", err)
+	}
+	if _, err := client.Release(ctx, "acme", "limited", ""); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("rate limited error = %v, want ErrRateLimited", err)
+	}
+
+	linux, _ := latest.Match([]string{"*linux*"})
+	sumFile, ok := latest.ChecksumAsset(linux[0])
+	if !ok || sumFile.Name != "checksums.txt" {
+		t.Fatalf("ChecksumAsset(linux) = %+v, %v", sumFile, ok)
+	}
+	if sum, err := client.Checksum(ctx, sumFile, linux[0].Name); err != nil || sum != "sha256:"+digest {
+		t.Errorf("Checksum(linux) = %q, %v", sum, err)
+	}
+	if _, err := client.Checksum(ctx, sumFile, "unlisted.bin"); err == nil {
+		t.Error("Checksum found an unlisted file")
+	}
+
+	for _, call := range *authorized {
+		path, auth, _ := strings.Cut(call, " ")
+		if strings.HasPrefix(path, "/api/") != (auth == "Bearer secret") {
+			t.Errorf("request to %s sent Authorization %q; the token belongs to API requests only", path, auth)
+		}
+	}
+}
+
+func TestMatchAndPerAssetChecksum(t *testing.T) {
+	server, _ := newTestServer(t)
+	client := &Client{BaseURL: server.URL + "/api"}
+	release, err := client.Release(context.Background(), "acme", "tool", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matched, err := release.Match([]string{"*.zip", "*linux*"})
+	if err != nil || len(matched) != 2 || matched[0].Name != "tool_linux_amd64.tar.gz" {
+		t.Fatalf("Match = %+v, %v; want both archives in release order", matched, err)
+	}
+	if _, err := release.Match([]string{"["}); err == nil {
+		t.Error("Match accepted a malformed pattern")
+	}
+
+	sumFile, ok := release.ChecksumAsset(matched[1])
+	if !ok || sumFile.Name != "tool_windows_amd64.zip.sha256" {
+		t.Fatalf("ChecksumAsset(zip) = %+v; the asset's own .sha256 should win", sumFile)
+	}
+	if sum, err := client.Checksum(context.Background(), sumFile, matched[1].Name); err != nil || sum != "sha256:"+digest {
+		t.Errorf("Checksum(zip) = %q, %v", sum, err)
+	}
+}
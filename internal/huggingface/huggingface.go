@@ -0,0 +1,215 @@
+// Package huggingface lists the files of a Hugging Face Hub repository with
+// the metadata needed to download and verify them.
+package huggingface
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// DefaultEndpoint is the public Hugging Face Hub.
+const DefaultEndpoint = "https://huggingface.co"
+
+const requestTimeout = 30 * time.Second
+
+var (
+	ErrNotFound     = errors.New("repository or revision not found")
+	ErrUnauthorized = errors.New("repository is private or gated, pass a token")
+	ErrForbidden    = errors.New("access denied, request access to the repository on its page first")
+)
+
+// Repo names a repository and the revision to read.
+type Repo struct {
+	Kind     string // "model", "dataset" or "space"
+	ID       string // "owner/name"
+	Revision string // Branch, tag or commit; "main" when empty
+}
+
+// Name is the last part of the repository ID.
+func (r Repo) Name() string {
+	return path.Base(r.ID)
+}
+
+// ParseRepoRef reads "owner/name[@revision]", with an optional "datasets/"
+// or "spaces/" prefix for repositories that are not models.
+func ParseRepoRef(ref string) (Repo, error) {
+	ref = strings.TrimSpace(ref)
+	repo := Repo{Kind: "model", Revision: "main"}
+	if rest, ok := strings.CutPrefix(ref, "datasets/"); ok {
+		repo.Kind, ref = "dataset", rest
+	} else if rest, ok := strings.CutPrefix(ref, "spaces/"); ok {
+		repo.Kind, ref = "space", rest
+	}
+
+	id, revision, hasRevision := strings.Cut(ref, "@")
+	if hasRevision {
+		if revision == "" {
+			return Repo{}, fmt.Errorf("invalid repository %q: empty revision", ref)
+		}
+		repo.Revision = revision
+	}
+	owner, name, ok := strings.Cut(id, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return Repo{}, fmt.Errorf("invalid repository %q: use owner/name or owner/name@revision", ref)
+	}
+	repo.ID = id
+	return repo, nil
+}
+
+// File is one file of a repository snapshot.
+type File struct {
+	Path   string // Relative to the repository root, with forward slashes
+	Size   int64
+	SHA256 string // Hex digest for files stored in LFS; empty otherwise
+}
+
+// Snapshot is the file list of a repository at one commit.
+type Snapshot struct {
+	Commit string
+	Files  []File
+}
+
+// Client queries the Hub API, authenticated when Token is set.
+type Client struct {
+	HTTP     *http.Client
+	Endpoint string // Defaults to DefaultEndpoint
+	Token    string
+}
+
+// Snapshot resolves repo's revision to a commit and lists its files.
+func (c *Client) Snapshot(ctx context.Context, repo Repo) (*Snapshot, error) {
+	apiURL := fmt.Sprintf("%s/api/%ss/%s/revision/%s?blobs=true",
+		c.endpoint(), repo.Kind, repo.ID, url.PathEscape(repo.Revision))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	for key, value := range c.AuthHeaders() {
+		req.Header.Set(key, value)
+	}
+
+	client := c.HTTP
+	if client == nil {
+		client = &http.Client{Timeout: requestTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return nil, ErrUnauthorized
+	case http.StatusForbidden:
+		return nil, ErrForbidden
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, fmt.Errorf("Hugging Face returned %s", resp.Status)
+	}
+
+	var info struct {
+		SHA      string `json:"sha"`
+		Siblings []struct {
+			Name string `json:"rfilename"`
+			Size int64  `json:"size"`
+			LFS  *struct {
+				SHA256 string `json:"sha256"`
+				Size   int64  `json:"size"`
+			} `json:"lfs"`
+		} `json:"siblings"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("invalid repository response: %w", err)
+	}
+	if info.SHA == "" {
+		return nil, fmt.Errorf("repository response has no commit")
+	}
+
+	snapshot := &Snapshot{Commit: info.SHA}
+	for _, s := range info.Siblings {
+		f := File{Path: s.Name, Size: s.Size}
+		if s.LFS != nil {
+			f.SHA256 = strings.ToLower(s.LFS.SHA256)
+			if s.LFS.Size > 0 {
+				f.Size = s.LFS.Size
+			}
+		}
+		snapshot.Files = append(snapshot.Files, f)
+	}
+	return snapshot, nil
+}
+
+// FileURL is where file is downloaded from at commit. Pinning the commit
+// keeps every shard from the same snapshot even if the branch moves.
+func (c *Client) FileURL(repo Repo, commit, file string) string {
+	prefix := ""
+	switch repo.Kind {
+	case "dataset":
+		prefix = "datasets/"
+	case "space":
+		prefix = "spaces/"
+	}
+	segments := strings.Split(file, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return fmt.Sprintf("%s/%s%s/resolve/%s/%s", c.endpoint(), prefix, repo.ID, commit, strings.Join(segments, "/"))
+}
+
+// AuthHeaders are the headers downloads of the repository need.
+func (c *Client) AuthHeaders() map[string]string {
+	if c.Token == "" {
+		return nil
+	}
+	return map[string]string{"Authorization": "Bearer " + c.Token}
+}
+
+func (c *Client) endpoint() string {
+	if c.Endpoint != "" {
+		return strings.TrimRight(c.Endpoint, "/")
+	}
+	return DefaultEndpoint
+}
+
+// Filter keeps the files matching any include pattern (all files when there
+// are none) and no exclude pattern. Patterns are globs tried against both
+// the full path and the file name, so "*.safetensors" also finds files in
+// subdirectories.
+func Filter(files []File, include, exclude []string) ([]File, error) {
+	for _, pattern := range append(append([]string{}, include...), exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	var kept []File
+	for _, f := range files {
+		if (len(include) == 0 || matchAny(include, f.Path)) && !matchAny(exclude, f.Path) {
+			kept = append(kept, f)
+		}
+	}
+	return kept, nil
+}
+
+func matchAny(patterns []string, file string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, file); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(file)); ok {
+			return true
+		}
+	}
+	return false
+}
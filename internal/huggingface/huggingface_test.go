@@ -0,0 +1,120 @@
+package huggingface
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseRepoRef(t *testing.T) {
+	for _, tt := range []struct {
+		ref  string
+		want Repo
+	}{
+		{"acme/model", Repo{Kind: "model", ID: "acme/model", Revision: "main"}},
+		{"acme/model@v1.0", Repo{Kind: "model", ID: "acme/model", Revision: "v1.0"}},
+		{"datasets/acme/data", Repo{Kind: "dataset", ID: "acme/data", Revision: "main"}},
+		{"spaces/acme/demo@refs/pr/1", Repo{Kind: "space", ID: "acme/demo", Revision: "refs/pr/1"}},
+	} {
+		got, err := ParseRepoRef(tt.ref)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseRepoRef(%q) = %+v, %v; want %+v", tt.ref, got, err, tt.want)
+		}
+	}
+	for _, ref := range []string{"model", "acme/", "acme/model@", "acme/model/extra"} {
+		if _, err := ParseRepoRef(ref); err == nil {
+			t.Errorf("ParseRepoRef(%q) accepted an invalid reference", ref)
+		}
+	}
+}
+
+func TestClientSnapshot(t *testing.T) {
+	var gotAuth, gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth, gotPath = r.Header.Get("Authorization"), r.URL.RequestURI()
+		switch r.URL.Path {
+		case "/api/models/acme/model/revision/main":
+			_, _ = w.Write([]byte(`{"sha": "0123456789abcdef", "siblings": [
+				{"rfilename": "config.json", "size": 120},
+				{"rfilename": "model-00001-of-00002.safetensors", "size": 10, "lfs": {"sha256": "AB12", "size": 5000000000}},
+				{"rfilename": "onnx/model.onnx", "size": 10, "lfs": {"sha256": "cd34", "size": 42}}
+			]}`))
+		case "/api/models/acme/gated/revision/main":
+			w.WriteHeader(http.StatusUnauthorized)
+		case "/api/models/acme/requested/revision/main":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{Endpoint: server.URL, Token: "hf_secret"}
+	ctx := context.Background()
+
+	snapshot, err := client.Snapshot(ctx, Repo{Kind: "model", ID: "acme/model", Revision: "main"})
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if gotAuth != "Bearer hf_secret" || gotPath != "/api/models/acme/model/revision/main?blobs=true" {
+		t.Errorf("request = %s with Authorization %q", gotPath, gotAuth)
+	}
+	if snapshot.Commit != "0123456789abcdef" || len(snapshot.Files) != 3 {
+		t.Fatalf("snapshot = %+v", snapshot)
+	}
+	if f := snapshot.Files[1]; f.SHA256 != "ab12" || f.Size != 5000000000 {
+		t.Errorf("LFS file = %+v, want its LFS digest and size", f)
+	}
+	if f := snapshot.Files[0]; f.SHA256 != "" || f.Size != 120 {
+		t.Errorf("regular file = %+v", f)
+	}
+
+	for repo, want := range map[string]error{
+		"acme/gated":     ErrUnauthorized,
+		"acme/requested": ErrForbidden,
+		"acme/missing":   ErrNotFound,
+	} {
+		if _, err := client.Snapshot(ctx, Repo{Kind: "model", ID: repo, Revision: "main"}); !errors.Is(err, want) {
+			t.Errorf("Snapshot(%s) error = %v, want %v", repo, err, want)
+		}
+	}
+}
+
+func TestFileURL(t *testing.T) {
+	client := &Client{}
+	for _, tt := range []struct {
+		repo Repo
+		file string
+		want string
+	}{
+		{Repo{Kind: "model", ID: "acme/model"}, "onnx/model.onnx", "https://huggingface.co/acme/model/resolve/abc/onnx/model.onnx"},
+		{Repo{Kind: "dataset", ID: "acme/data"}, "data/train 1.parquet", "https://huggingface.co/datasets/acme/data/resolve/abc/data/train%201.parquet"},
+	} {
+		if got := client.FileURL(tt.repo, "abc", tt.file); got != tt.want {
+			t.Errorf("FileURL(%s) = %q, want %q", tt.file, got, tt.want)
+		}
+	}
+	if client.AuthHeaders() != nil {
+		t.Error("a client without a token sends auth headers")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	files := []File{{Path: "config.json"}, {Path: "model.safetensors"}, {Path: "original/consolidated.pth"}, {Path: "onnx/model.onnx"}}
+
+	got, err := Filter(files, []string{"*.safetensors", "*.json", "*.onnx"}, []string{"onnx/*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Path != "config.json" || got[1].Path != "model.safetensors" {
+		t.Errorf("Filter = %+v", got)
+	}
+	if got, _ := Filter(files, nil, []string{"original/*"}); len(got) != 3 {
+		t.Errorf("Filter without includes kept %d files, want 3", len(got))
+	}
+	if _, err := Filter(files, []string{"["}, nil); err == nil {
+		t.Error("Filter accepted a malformed pattern")
+	}
+}
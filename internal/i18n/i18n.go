@@ -0,0 +1,195 @@
+// Package i18n translates user-facing TUI and CLI text. Messages live in
+// locales/<code>.toml, grouped into tables, and are looked up by
+// "table.key" IDs such as "status.paused". English is the reference catalog
+// and the fallback for anything a translation is missing.
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Default is the locale used when no other is selected or detected.
+const Default = "en"
+
+// Auto selects the locale from the LC_ALL, LC_MESSAGES and LANG environment
+// variables.
+const Auto = "auto"
+
+//go:embed locales/*.toml
+var localeFS embed.FS
+
+type catalog struct {
+	name     string
+	messages map[string]string
+}
+
+var (
+	loadOnce sync.Once
+	catalogs map[string]*catalog
+	loadErrs []error
+
+	current atomic.Pointer[catalog]
+	locale  atomic.Value // string
+)
+
+func load() {
+	loadOnce.Do(func() {
+		catalogs = make(map[string]*catalog)
+		files, _ := localeFS.ReadDir("locales")
+		for _, f := range files {
+			code := strings.TrimSuffix(f.Name(), path.Ext(f.Name()))
+			c, err := parseCatalog(f.Name())
+			if err != nil {
+				loadErrs = append(loadErrs, fmt.Errorf("%s: %w", f.Name(), err))
+				continue
+			}
+			catalogs[code] = c
+		}
+	})
+}
+
+func parseCatalog(file string) (*catalog, error) {
+	data, err := localeFS.ReadFile("locales/" + file)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]any
+	if err := toml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	c := &catalog{messages: make(map[string]string)}
+	for table, v := range raw {
+		if table == "name" {
+			c.name, _ = v.(string)
+			continue
+		}
+		entries, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a table", table)
+		}
+		for key, msg := range entries {
+			s, ok := msg.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s.%s must be a string", table, key)
+			}
+			c.messages[table+"."+key] = s
+		}
+	}
+	return c, nil
+}
+
+// Locales returns the available locale codes, English first.
+func Locales() []string {
+	load()
+	codes := make([]string, 0, len(catalogs))
+	for code := range catalogs {
+		if code != Default {
+			codes = append(codes, code)
+		}
+	}
+	slices.Sort(codes)
+	return append([]string{Default}, codes...)
+}
+
+// Name returns the locale's name in its own language, e.g. "Deutsch", or
+// the code itself when it is unknown.
+func Name(code string) string {
+	load()
+	if c, ok := catalogs[code]; ok && c.name != "" {
+		return c.name
+	}
+	return code
+}
+
+// Supported reports whether code names an available locale.
+func Supported(code string) bool {
+	load()
+	_, ok := catalogs[code]
+	return ok
+}
+
+// SetLocale switches the language of T. The selection is a locale code or
+// Auto; anything unavailable falls back to English. It returns the locale
+// now in use.
+func SetLocale(selection string) string {
+	load()
+	code := normalize(selection)
+	if code == Auto || code == "" {
+		code = Detect()
+	}
+	c, ok := catalogs[code]
+	if !ok {
+		code = Default
+		c = catalogs[Default]
+	}
+	current.Store(c)
+	locale.Store(code)
+	return code
+}
+
+// Locale returns the locale T currently uses.
+func Locale() string {
+	if code, ok := locale.Load().(string); ok {
+		return code
+	}
+	return Default
+}
+
+// Detect returns the available locale named by the environment, or English.
+func Detect() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(env)
+		if value == "" {
+			continue
+		}
+		// The first variable that is set wins, even when it is "C"
+		if code := normalize(value); Supported(code) {
+			return code
+		}
+		return Default
+	}
+	return Default
+}
+
+// normalize turns "de_DE.UTF-8" or "es-MX" into "de" or "es".
+func normalize(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if i := strings.IndexAny(value, "_-.@"); i >= 0 {
+		value = value[:i]
+	}
+	return value
+}
+
+// T returns the message for id in the current locale, formatted with args
+// as by fmt.Sprintf when any are given. A message missing from the locale
+// comes from English, and an unknown id is returned as is.
+func T(id string, args ...any) string {
+	msg, ok := lookup(current.Load(), id)
+	if !ok {
+		load()
+		if msg, ok = lookup(catalogs[Default], id); !ok {
+			msg = id
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+func lookup(c *catalog, id string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	msg, ok := c.messages[id]
+	return msg, ok
+}
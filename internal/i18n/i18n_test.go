@@ -0,0 +1,126 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var verbPattern = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+func TestCatalogsMatchEnglish(t *testing.T) {
+	load()
+	require.Empty(t, loadErrs)
+	english := catalogs[Default]
+	require.NotNil(t, english)
+
+	for code, c := range catalogs {
+		assert.NotEmpty(t, c.name, "%s has no name", code)
+		for id, msg := range c.messages {
+			want, ok := english.messages[id]
+			if !assert.True(t, ok, "%s defines %s, which English does not", code, id) {
+				continue
+			}
+			assert.Equal(t, verbPattern.FindAllString(want, -1), verbPattern.FindAllString(msg, -1),
+				"%s: %s has different format verbs than English", code, id)
+		}
+		for id := range english.messages {
+			assert.Contains(t, c.messages, id, "%s is missing %s", code, id)
+		}
+	}
+}
+
+func TestSetLocaleAndFallback(t *testing.T) {
+	t.Cleanup(func() { SetLocale(Default) })
+
+	assert.Equal(t, "de", SetLocale("de"))
+	assert.Equal(t, "Pausiert", T("status.paused"))
+	assert.Equal(t, "3 Downloads hinzufügen?", T("modal.batch_message", 3))
+	assert.Equal(t, "no.such.message", T("no.such.message"))
+
+	assert.Equal(t, Default, SetLocale("xx"))
+	assert.Equal(t, "Paused", T("status.paused"))
+}
+
+func TestDetect(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "es_MX.UTF-8")
+	assert.Equal(t, "es", Detect())
+
+	t.Setenv("LC_MESSAGES", "de_AT")
+	assert.Equal(t, "de", Detect())
+
+	// LC_ALL wins even when it names a locale Surge does not have
+	t.Setenv("LC_ALL", "C")
+	assert.Equal(t, Default, Detect())
+}
+
+func TestLocales(t *testing.T) {
+	locales := Locales()
+	assert.Equal(t, Default, locales[0])
+	assert.True(t, slices.Contains(locales, "de"))
+	assert.True(t, slices.Contains(locales, "es"))
+	assert.Equal(t, "Español", Name("es"))
+}
+
+// TestMessageIDsExist checks that every i18n.T call with a literal ID refers
+// to a message in the English catalog.
+func TestMessageIDsExist(t *testing.T) {
+	load()
+	english := catalogs[Default]
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	require.NoError(t, err)
+
+	fset := token.NewFileSet()
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name := d.Name(); name == ".git" || name == "vendor" || name == "testdata" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "T" {
+				return true
+			}
+			if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "i18n" {
+				return true
+			}
+			lit, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			id, _ := strconv.Unquote(lit.Value)
+			assert.Contains(t, english.messages, id, "%s: unknown message ID", fset.Position(lit.Pos()))
+			return true
+		})
+		return nil
+	})
+	require.NoError(t, err)
+}
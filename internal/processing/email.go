@@ -0,0 +1,147 @@
+package processing
+
+import (
+	"bytes"
+	"fmt"
+	"net/mail"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/state"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+var sendEmail = utils.SendEmail
+
+// emailFields are the values available to the subject and body templates.
+type emailFields struct {
+	Filename string
+	Status   string
+	URL      string
+	DestPath string
+	Size     string
+	Duration string
+	AvgSpeed string
+	Error    string
+
+	total   int64
+	elapsed time.Duration
+}
+
+// StartEmailNotifier emails finished and failed downloads through the SMTP
+// server in the notification settings. Downloads below both the size and the
+// duration threshold are skipped, so only long-running ones are reported.
+func (mgr *LifecycleManager) StartEmailNotifier(ch <-chan interface{}) {
+	for msg := range ch {
+		var fields emailFields
+		switch m := msg.(type) {
+		case events.DownloadCompleteMsg:
+			fields = emailFields{Filename: m.Filename, Status: "completed", total: m.Total, elapsed: m.Elapsed}
+			fillFromEntry(&fields, m.DownloadID)
+		case events.DownloadErrorMsg:
+			fields = emailFields{Filename: m.Filename, Status: "failed", DestPath: m.DestPath}
+			if m.Err != nil {
+				fields.Error = m.Err.Error()
+			}
+			fillFromEntry(&fields, m.DownloadID)
+		default:
+			continue
+		}
+
+		settings := mgr.GetSettings()
+		if settings == nil {
+			continue
+		}
+		n := settings.Notifications
+		server := strings.TrimSpace(config.Resolve[string](n.SMTPServer))
+		from := strings.TrimSpace(config.Resolve[string](n.EmailFrom))
+		to, err := mail.ParseAddressList(config.Resolve[string](n.EmailTo))
+		if server == "" || from == "" || err != nil || len(to) == 0 {
+			continue
+		}
+		if !meetsEmailThreshold(fields, config.Resolve[time.Duration](n.EmailMinDuration), config.Resolve[int64](n.EmailMinSize)) {
+			continue
+		}
+
+		fields.Size = utils.ConvertBytesToHumanReadable(fields.total)
+		fields.Duration = fields.elapsed.Truncate(time.Second).String()
+		fields.AvgSpeed = "0 B"
+		if fields.elapsed.Seconds() > 0 {
+			fields.AvgSpeed = utils.ConvertBytesToHumanReadable(int64(float64(fields.total) / fields.elapsed.Seconds()))
+		}
+
+		subject, err := renderEmailTemplate(config.Resolve[string](n.EmailSubject), config.DefaultEmailSubject, fields)
+		if err != nil {
+			utils.Debug("Email: Failed to render subject: %v", err)
+			continue
+		}
+		body, err := renderEmailTemplate(config.Resolve[string](n.EmailBody), config.DefaultEmailBody, fields)
+		if err != nil {
+			utils.Debug("Email: Failed to render body: %v", err)
+			continue
+		}
+		// Header injection guard: a filename can carry anything.
+		subject = strings.Join(strings.Fields(subject), " ")
+
+		recipients := make([]string, len(to))
+		for i, addr := range to {
+			recipients[i] = addr.Address
+		}
+		if err := sendEmail(server, from, recipients, subject, body); err != nil {
+			utils.Debug("Email: Failed to send notification for %s: %v", fields.Filename, err)
+		}
+	}
+}
+
+// fillFromEntry completes fields from the persisted entry, which knows the
+// URL, destination and, for failures, how far the download got.
+func fillFromEntry(fields *emailFields, id string) {
+	entry, _ := state.GetDownload(id)
+	if entry != nil {
+		if fields.Filename == "" {
+			fields.Filename = entry.Filename
+		}
+		if fields.DestPath == "" {
+			fields.DestPath = entry.DestPath
+		}
+		fields.URL = entry.URL
+		if fields.total == 0 {
+			fields.total = entry.TotalSize
+		}
+		if fields.elapsed == 0 {
+			fields.elapsed = time.Duration(entry.TimeTaken) * time.Millisecond
+		}
+	}
+	if fields.Filename == "" {
+		fields.Filename = id
+	}
+}
+
+// meetsEmailThreshold reports whether a download is big or long enough to
+// email about. With no thresholds set, every download is.
+func meetsEmailThreshold(fields emailFields, minDuration time.Duration, minSize int64) bool {
+	if minDuration <= 0 && minSize <= 0 {
+		return true
+	}
+	return (minDuration > 0 && fields.elapsed >= minDuration) || (minSize > 0 && fields.total >= minSize)
+}
+
+func renderEmailTemplate(text, fallback string, fields emailFields) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		text = fallback
+	}
+	// Settings store the body on one line, so \n stands for a line break.
+	text = strings.ReplaceAll(text, `\n`, "\n")
+	tmpl, err := template.New("email").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, fields); err != nil {
+		return "", fmt.Errorf("executing template: %w", err)
+	}
+	return out.String(), nil
+}
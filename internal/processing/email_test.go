@@ -0,0 +1,66 @@
+package processing
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/events"
+)
+
+func TestStartEmailNotifier_SendsForDownloadsOverThreshold(t *testing.T) {
+	type sent struct {
+		to            []string
+		subject, body string
+	}
+	var mails []sent
+	origSend := sendEmail
+	t.Cleanup(func() { sendEmail = origSend })
+	sendEmail = func(server, from string, to []string, subject, body string) error {
+		mails = append(mails, sent{to, subject, body})
+		return nil
+	}
+
+	mgr := newLifecycleManagerForTest()
+	n := mgr.settings.Notifications
+	n.SMTPServer.Value = "smtp://mail.local"
+	n.EmailFrom.Value = "surge@example.com"
+	n.EmailTo.Value = "Me <me@example.com>, ops@example.com"
+	n.EmailMinDuration.Value = 10 * time.Minute
+	n.EmailMinSize.Value = int64(1 << 30)
+
+	ch := make(chan interface{}, 3)
+	ch <- events.DownloadCompleteMsg{DownloadID: "small", Filename: "small.txt", Total: 1 << 20, Elapsed: time.Second}
+	ch <- events.DownloadCompleteMsg{DownloadID: "long", Filename: "long.iso", Total: 100 << 20, Elapsed: 20 * time.Minute}
+	ch <- events.DownloadErrorMsg{DownloadID: "gone", Filename: "gone.bin", Err: errors.New("404")}
+	close(ch)
+	mgr.StartEmailNotifier(ch)
+
+	if len(mails) != 1 {
+		t.Fatalf("sent %d emails, want only the one for the long download", len(mails))
+	}
+	got := mails[0]
+	if strings.Join(got.to, ",") != "me@example.com,ops@example.com" {
+		t.Fatalf("recipients = %v", got.to)
+	}
+	if got.subject != "Surge: long.iso completed" {
+		t.Fatalf("subject = %q", got.subject)
+	}
+	if !strings.Contains(got.body, "long.iso completed after 20m0s.\nSize: 105 MB, average speed: 87 kB/s") {
+		t.Fatalf("body = %q", got.body)
+	}
+}
+
+func TestMeetsEmailThreshold(t *testing.T) {
+	big := emailFields{total: 2 << 30, elapsed: time.Second}
+	if !meetsEmailThreshold(big, 0, 0) {
+		t.Fatal("no thresholds should let every download through")
+	}
+	if !meetsEmailThreshold(big, time.Hour, 1<<30) {
+		t.Fatal("meeting the size threshold alone should be enough")
+	}
+	if meetsEmailThreshold(big, time.Hour, 0) {
+		t.Fatal("a short download should not meet a duration-only threshold")
+	}
+}
@@ -58,6 +58,11 @@ func finalizeCompletedFile(finalPath string) error {
 				_ = os.Remove(finalPath)
 				return fmt.Errorf("copy completed file: %w", err)
 			}
+			// A copy is stamped now; keep the working file's time, which
+			// may be the server's Last-Modified
+			if info, err := os.Stat(surgePath); err == nil {
+				_ = os.Chtimes(finalPath, info.ModTime(), info.ModTime())
+			}
 			if err := retryRemove(surgePath); err != nil {
 				return fmt.Errorf("remove copied working file: %w", err)
 			}
@@ -74,10 +79,21 @@ func finalizeCompletedFile(finalPath string) error {
 // StartEventWorker listens to engine events and handles database persistence
 // and file cleanup, ensuring the core engine remains stateless.
 func (mgr *LifecycleManager) StartEventWorker(ch <-chan interface{}) {
+	// Picks up how much of monthly_quota this month has already used
+	mgr.recordTraffic(time.Now(), true)
+	// Retries are resumed with nothing left to persist their outcome
+	defer mgr.stopRetries()
+
 	for msg := range ch {
 		switch m := msg.(type) {
 
 		case events.DownloadStartedMsg:
+			existing, _ := state.GetDownload(m.DownloadID)
+			// A small file's queued record already names its destination, and
+			// restarting it after a crash costs less than a write per file.
+			if m.SmallFile && existing != nil && existing.DestPath == m.DestPath {
+				break
+			}
 			// Persist the started record immediately so crash recovery and later lifecycle
 			// events have a stable destination record even before the first pause snapshot.
 			entry := types.DownloadEntry{
@@ -92,7 +108,7 @@ func (mgr *LifecycleManager) StartEventWorker(ch <-chan interface{}) {
 				RateLimit:    m.RateLimit,
 				RateLimitSet: m.RateLimitSet,
 			}
-			if existing, _ := state.GetDownload(m.DownloadID); existing != nil {
+			if existing != nil {
 				entry.Mirrors = append([]string(nil), existing.Mirrors...)
 				if existing.Downloaded > 0 {
 					entry.Downloaded = existing.Downloaded
@@ -106,6 +122,8 @@ func (mgr *LifecycleManager) StartEventWorker(ch <-chan interface{}) {
 			}
 
 		case events.DownloadPausedMsg:
+			mgr.finishing.Delete(m.DownloadID)
+			mgr.recordTraffic(time.Now(), true)
 			if m.State == nil {
 				existing, _ := state.GetDownload(m.DownloadID)
 				if existing == nil {
@@ -207,6 +225,8 @@ func (mgr *LifecycleManager) StartEventWorker(ch <-chan interface{}) {
 			}
 
 		case events.DownloadCompleteMsg:
+			mgr.finishing.Delete(m.DownloadID)
+			mgr.recordTraffic(time.Now(), true)
 			var avgSpeed float64
 			if m.Elapsed.Seconds() > 0 {
 				avgSpeed = float64(m.Total) / m.Elapsed.Seconds()
@@ -257,6 +277,7 @@ func (mgr *LifecycleManager) StartEventWorker(ch <-chan interface{}) {
 				if settings := mgr.GetSettings(); settings != nil && config.Resolve[bool](settings.General.DownloadCompleteNotification) {
 					notify(fmt.Sprintf("Download failed: %s", filename), msg)
 				}
+				mgr.finishSums(m.DownloadID, "", "")
 				break
 			}
 
@@ -274,12 +295,19 @@ func (mgr *LifecycleManager) StartEventWorker(ch <-chan interface{}) {
 				AvgSpeed:     avgSpeed,
 				RateLimit:    m.RateLimit,
 				RateLimitSet: m.RateLimitSet,
+				Verified:     m.Verified,
+				Checksum:     m.Checksum,
 			}); err != nil {
 				utils.Debug("Lifecycle: Failed to persist completed download: %v", err)
 			}
 			if err := state.DeleteTasks(m.DownloadID); err != nil {
 				utils.Debug("Lifecycle: Failed to delete completed tasks: %v", err)
 			}
+			if err := state.ClearFailures(m.DownloadID); err != nil {
+				utils.Debug("Lifecycle: Failed to clear failures: %v", err)
+			}
+			recordProvenance(destPath, Provenance{URL: url, Completed: time.Now(), Checksum: m.Checksum}, mgr.GetSettings())
+			mgr.finishSums(m.DownloadID, destPath, m.Checksum)
 			if settings := mgr.GetSettings(); settings != nil && config.Resolve[bool](settings.General.DownloadCompleteNotification) {
 
 				if filename == "" {
@@ -294,15 +322,21 @@ func (mgr *LifecycleManager) StartEventWorker(ch <-chan interface{}) {
 				if m.Elapsed.Seconds() <= 0 {
 					notify(title, "Download complete!")
 				} else {
-					notify(title, fmt.Sprintf("Download complete in %s (%.2f MB/s)", m.Elapsed.Truncate(time.Second), avgSpeed/float64(types.MB)))
+					notify(title, fmt.Sprintf("Download complete in %s (%s)", m.Elapsed.Truncate(time.Second), utils.FormatSpeed(avgSpeed)))
 				}
 			}
 
 		case events.DownloadErrorMsg:
+			mgr.finishSums(m.DownloadID, "", "")
+			mgr.finishing.Delete(m.DownloadID)
+			mgr.recordTraffic(time.Now(), true)
 			existing, _ := state.GetDownload(m.DownloadID)
 			destPath := m.DestPath
+			var failed events.DownloadFailedMsg
+			decided := false
 			if existing != nil {
 				existing.Status = "error"
+				failed, decided = mgr.recordFailure(existing, m.Err, m.ErrorCode(), time.Now())
 				if err := state.AddToMasterList(*existing); err != nil {
 					utils.Debug("Lifecycle: Failed to persist error state: %v", err)
 				}
@@ -315,7 +349,14 @@ func (mgr *LifecycleManager) StartEventWorker(ch <-chan interface{}) {
 					utils.Debug("Lifecycle: Failed to remove incomplete file after error: %v", err)
 				}
 			}
-			if settings := mgr.GetSettings(); settings != nil && config.Resolve[bool](settings.General.DownloadCompleteNotification) {
+			if decided {
+				if hooks := mgr.getEngineHooks(); hooks.PublishEvent != nil {
+					_ = hooks.PublishEvent(failed)
+				}
+			}
+			// A download about to be retried has not failed for good yet
+			retrying := decided && !failed.Quarantined()
+			if settings := mgr.GetSettings(); !retrying && settings != nil && config.Resolve[bool](settings.General.DownloadCompleteNotification) {
 
 				filename := m.Filename
 				if filename == "" && existing != nil {
@@ -334,6 +375,9 @@ func (mgr *LifecycleManager) StartEventWorker(ch <-chan interface{}) {
 			}
 
 		case events.DownloadRemovedMsg:
+			mgr.finishSums(m.DownloadID, "", "")
+			mgr.finishing.Delete(m.DownloadID)
+			utils.ForgetDownloadLog(m.DownloadID)
 			// Remove resume metadata before touching files so a deleted download does not
 			// come back during startup recovery.
 			if err := state.DeleteState(m.DownloadID); err != nil {
@@ -342,6 +386,9 @@ func (mgr *LifecycleManager) StartEventWorker(ch <-chan interface{}) {
 			if err := state.RemoveFromMasterList(m.DownloadID); err != nil {
 				utils.Debug("Lifecycle: Failed to remove from master list: %v", err)
 			}
+			if err := state.ClearFailures(m.DownloadID); err != nil {
+				utils.Debug("Lifecycle: Failed to clear failures: %v", err)
+			}
 
 			// Only incomplete working files should be removed here; completed files have
 			// already been promoted to their final name by the completion path.
@@ -352,25 +399,44 @@ func (mgr *LifecycleManager) StartEventWorker(ch <-chan interface{}) {
 			}
 
 		case events.DownloadQueuedMsg:
+			if m.WaitingForNetwork {
+				// Nothing is reserved on disk until the network is back
+				continue
+			}
 			// Queue persistence is what lets downloads survive shutdown before any worker
 			// has emitted a started event.
 			if err := state.AddToMasterList(types.DownloadEntry{
-				ID:           m.DownloadID,
-				URL:          m.URL,
-				URLHash:      state.URLHash(m.URL),
-				DestPath:     m.DestPath,
-				Filename:     m.Filename,
-				Mirrors:      append([]string(nil), m.Mirrors...),
-				Status:       "queued",
-				RateLimit:    m.RateLimit,
-				RateLimitSet: m.RateLimitSet,
+				ID:              m.DownloadID,
+				URL:             m.URL,
+				URLHash:         state.URLHash(m.URL),
+				DestPath:        m.DestPath,
+				Filename:        m.Filename,
+				Mirrors:         append([]string(nil), m.Mirrors...),
+				Status:          "queued",
+				RateLimit:       m.RateLimit,
+				RateLimitSet:    m.RateLimitSet,
+				Alias:           m.Alias,
+				Tags:            m.Tags,
+				ResponseHeaders: m.ResponseHeaders,
+				Redirects:       m.Redirects,
+				Method:          m.Method,
 			}); err != nil {
 				utils.Debug("Lifecycle: Failed to persist queued download: %v", err)
 			}
 
-		case events.BatchProgressMsg, events.ProgressMsg:
+		case events.BatchProgressMsg:
 			// Progress ticks are intentionally transient; persisting them would add
 			// SQLite churn without improving resume or history recovery.
+			now := time.Now()
+			for _, p := range m {
+				mgr.notifyFinishing(p, now)
+			}
+			mgr.recordTraffic(now, false)
+
+		case events.ProgressMsg:
+			now := time.Now()
+			mgr.notifyFinishing(m, now)
+			mgr.recordTraffic(now, false)
 		}
 	}
 }
@@ -333,3 +333,39 @@ func TestStartEventWorker_ErrorNotificationFallsBackToDownloadID(t *testing.T) {
 		t.Fatalf("notification msg = %q, want %q", calls[0].msg, "boom")
 	}
 }
+
+func TestStartEventWorker_NotifiesOnceWhenLongDownloadIsAlmostDone(t *testing.T) {
+	settingsDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", settingsDir)
+	settings := config.DefaultSettings()
+	settings.General.DownloadCompleteNotification.Value = true
+	if err := config.SaveSettings(settings); err != nil {
+		t.Fatalf("failed to save settings: %v", err)
+	}
+
+	origNotify := notify
+	t.Cleanup(func() { notify = origNotify })
+	var titles []string
+	notify = func(title, msg string) {
+		titles = append(titles, title)
+	}
+
+	mgr := NewLifecycleManager(nil, nil)
+	short := events.ProgressMsg{DownloadID: "download-7", Downloaded: 900, Total: 1000, Speed: 10, Elapsed: time.Minute}
+	almost := events.ProgressMsg{DownloadID: "download-7", Downloaded: 900, Total: 1000, Speed: 10, Elapsed: 20 * time.Minute}
+	far := events.ProgressMsg{DownloadID: "download-8", Downloaded: 0, Total: 1000, Speed: 1, Elapsed: 20 * time.Minute}
+	ch := make(chan interface{}, 3)
+	ch <- events.BatchProgressMsg{short, far}
+	ch <- events.BatchProgressMsg{almost, far}
+	ch <- events.BatchProgressMsg{almost}
+	close(ch)
+
+	mgr.StartEventWorker(ch)
+
+	if len(titles) != 1 {
+		t.Fatalf("notification calls = %d, want 1", len(titles))
+	}
+	if titles[0] != "Almost done: download-7" {
+		t.Fatalf("notification title = %q, want %q", titles[0], "Almost done: download-7")
+	}
+}
@@ -1,6 +1,7 @@
 package processing_test
 
 import (
+	"net/http"
 	"os"
 	"path/filepath"
 	"testing"
@@ -125,6 +126,72 @@ func TestStartEventWorker_PersistsQueuedMirrorsForResume(t *testing.T) {
 	}
 }
 
+func TestStartEventWorker_KeepsQueuedMethodAcrossStartedThenPaused(t *testing.T) {
+	tempDir := testutil.SetupStateDB(t)
+	finalPath := filepath.Join(tempDir, "export.csv")
+
+	mgr := processing.NewLifecycleManager(nil, nil)
+	ch := make(chan interface{}, 3)
+	ch <- events.DownloadQueuedMsg{
+		DownloadID: "download-post",
+		URL:        "https://example.com/export",
+		Filename:   "export.csv",
+		DestPath:   finalPath,
+		Method:     http.MethodPost,
+	}
+	ch <- events.DownloadStartedMsg{
+		DownloadID: "download-post",
+		URL:        "https://example.com/export",
+		Filename:   "export.csv",
+		DestPath:   finalPath,
+	}
+	ch <- events.DownloadPausedMsg{DownloadID: "download-post", Filename: "export.csv"}
+	close(ch)
+
+	mgr.StartEventWorker(ch)
+
+	entry, err := state.GetDownload("download-post")
+	if err != nil || entry == nil {
+		t.Fatalf("GetDownload = %v, %v", entry, err)
+	}
+	if entry.Method != http.MethodPost {
+		t.Fatalf("method = %q, want %q", entry.Method, http.MethodPost)
+	}
+}
+
+func TestStartEventWorker_SmallFileStartKeepsQueuedRecord(t *testing.T) {
+	tempDir := testutil.SetupStateDB(t)
+	finalPath := filepath.Join(tempDir, "notes.txt")
+
+	mgr := processing.NewLifecycleManager(nil, nil)
+	ch := make(chan interface{}, 2)
+	ch <- events.DownloadQueuedMsg{
+		DownloadID: "download-small",
+		URL:        "https://example.com/notes.txt",
+		Filename:   "notes.txt",
+		DestPath:   finalPath,
+	}
+	ch <- events.DownloadStartedMsg{
+		DownloadID: "download-small",
+		URL:        "https://example.com/notes.txt",
+		Filename:   "notes.txt",
+		Total:      1024,
+		DestPath:   finalPath,
+		SmallFile:  true,
+	}
+	close(ch)
+
+	mgr.StartEventWorker(ch)
+
+	entry, err := state.GetDownload("download-small")
+	if err != nil || entry == nil {
+		t.Fatalf("GetDownload = %v, %v", entry, err)
+	}
+	if entry.Status != "queued" {
+		t.Errorf("status = %q, want the queued record left as it was", entry.Status)
+	}
+}
+
 func TestStartEventWorker_PreservesQueuedMirrorsAcrossStartedThenError(t *testing.T) {
 	tempDir := testutil.SetupStateDB(t)
 	finalPath := filepath.Join(tempDir, "video.mp4")
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/SurgeDM/Surge/internal/config"
 	"github.com/SurgeDM/Surge/internal/engine/types"
@@ -156,6 +157,56 @@ func GetCategoryPath(filename, defaultDir string, settings *config.Settings) (st
 	return defaultDir, nil
 }
 
+// GetDomainPath returns the directory a domain folder rule gives the URL's
+// host, or "" when none applies.
+func GetDomainPath(rawURL string, settings *config.Settings) string {
+	if settings == nil {
+		return ""
+	}
+	dirs := settings.DomainDirs()
+	if len(dirs) == 0 {
+		return ""
+	}
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+	if dir, ok := dirs.ForHost(parsed.Hostname()); ok {
+		return utils.EnsureAbsPath(dir)
+	}
+	return ""
+}
+
+// expandDestination fills in a templated download directory. The category is
+// looked up even when routing is off, so {category} works in any directory.
+func expandDestination(destPath, rawURL, filename string, settings *config.Settings) (string, string) {
+	vars := config.PathTemplateVars{URL: rawURL, Filename: filename, Time: time.Now()}
+	if settings != nil {
+		if cat, _ := config.GetCategoryForFile(filename, settings.Categories.Categories); cat != nil {
+			vars.Category = cat.Name
+		}
+	}
+	dir, name := config.ExpandPathTemplate(destPath, vars)
+	return utils.EnsureAbsPath(dir), name
+}
+
+// applyRenameRules cleans up a name that came from the server or URL with
+// the configured rename rules.
+func applyRenameRules(rawURL, filename string, settings *config.Settings) string {
+	if settings == nil || filename == "" {
+		return filename
+	}
+	rules, err := config.ParseRenameRules(config.Resolve[string](settings.Categories.RenameRules))
+	if err != nil || len(rules) == 0 {
+		return filename
+	}
+	host := ""
+	if parsed, err := url.Parse(strings.TrimSpace(rawURL)); err == nil {
+		host = parsed.Hostname()
+	}
+	return config.ApplyRenameRules(rules, host, filename, time.Now())
+}
+
 // getBaseFilename keeps naming deterministic across retries by preferring the
 // most authoritative source available before uniqueness is applied.
 func getBaseFilename(url, candidate string, probe *ProbeResult) string {
@@ -174,15 +225,26 @@ func getBaseFilename(url, candidate string, probe *ProbeResult) string {
 // requests all land on the same final path before the engine starts downloading.
 func ResolveDestination(url, candidateFilename, defaultDir string, routeToCategory bool, settings *config.Settings, probe *ProbeResult, isNameActive func(string, string) bool) (string, string, error) {
 	filename := getBaseFilename(url, candidateFilename, probe)
+	// A name the user chose is kept as typed
+	if candidateFilename == "" {
+		filename = applyRenameRules(url, filename, settings)
+	}
 
 	destPath := defaultDir
-	if routeToCategory && settings != nil && config.Resolve[bool](settings.Categories.CategoryEnabled) && filename != "" {
-		var err error
-		destPath, err = GetCategoryPath(filename, defaultDir, settings)
-		if err != nil {
-			return "", "", err
+	if routeToCategory {
+		if dir := GetDomainPath(url, settings); dir != "" {
+			destPath = dir
+		} else if settings != nil && config.Resolve[bool](settings.Categories.CategoryEnabled) && filename != "" {
+			var err error
+			destPath, err = GetCategoryPath(filename, defaultDir, settings)
+			if err != nil {
+				return "", "", err
+			}
 		}
 	}
+	if config.IsPathTemplate(destPath) {
+		destPath, filename = expandDestination(destPath, url, filename, settings)
+	}
 
 	// Safety: Truncate early so GetUniqueFilename has room to append a suffix
 	filename = utils.TruncateFilename(filename)
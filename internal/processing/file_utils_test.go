@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/SurgeDM/Surge/internal/config"
 	"github.com/SurgeDM/Surge/internal/engine/types"
@@ -240,3 +241,69 @@ func TestResolveDestination_ErrorsWhenUniqueNameExhausted(t *testing.T) {
 		t.Fatal("expected unique-name exhaustion error")
 	}
 }
+
+func TestResolveDestination_Templates(t *testing.T) {
+	root := t.TempDir()
+	settings := config.DefaultSettings()
+	settings.Categories.CategoryEnabled.Value = false
+	settings.Categories.Categories = []config.Category{{Name: "Music", Pattern: `(?i)\.mp3$`, Path: root}}
+	now := time.Now()
+
+	dir, name, err := processing.ResolveDestination("https://www.Example.com/a/song.mp3", "", filepath.Join(root, "{category}", "{yyyy}"), false, settings, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(root, "Music", now.Format("2006")); dir != want || name != "song.mp3" {
+		t.Fatalf("got %s / %s, want %s / song.mp3", dir, name, want)
+	}
+
+	// {filename} in the last element names the file
+	dir, name, _ = processing.ResolveDestination("https://example.com/x.iso", "", filepath.Join(root, "{domain}", "{mm}-{filename}"), false, settings, nil, nil)
+	if want := filepath.Join(root, "example.com"); dir != want || name != now.Format("01")+"-x.iso" {
+		t.Fatalf("got %s / %s, want %s / %s-x.iso", dir, name, want, now.Format("01"))
+	}
+}
+
+func TestResolveDestination_DomainDirs(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	file := "[domains.\"*.example.org\"]\ndir = '" + filepath.Join(root, "mirrors", "{domain}") + "'\n"
+	if err := os.MkdirAll(filepath.Dir(config.GetConfigFilePath()), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(config.GetConfigFilePath(), []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	settings, err := config.LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings.Categories.CategoryEnabled.Value = true
+
+	// Domain folders apply to downloads on the default path, before categories
+	dir, _, _ := processing.ResolveDestination("https://dl.example.org/x.iso", "", root, true, settings, nil, nil)
+	if want := filepath.Join(root, "mirrors", "dl.example.org"); dir != want {
+		t.Fatalf("dir = %s, want %s", dir, want)
+	}
+	dir, _, _ = processing.ResolveDestination("https://dl.example.org/x.iso", "", root, false, settings, nil, nil)
+	if dir != root {
+		t.Fatalf("dir = %s, want the explicit %s", dir, root)
+	}
+}
+
+func TestResolveDestination_RenameRules(t *testing.T) {
+	settings := config.DefaultSettings()
+	settings.Categories.CategoryEnabled.Value = false
+	settings.Categories.RenameRules.Value = "s/%20/_/; lower"
+
+	_, name, _ := processing.ResolveDestination("https://example.com/", "", t.TempDir(), false, settings, &processing.ProbeResult{DetectedFilename: "Big%20File.ZIP"}, nil)
+	if name != "big_file.zip" {
+		t.Errorf("name = %q, want the probed name renamed", name)
+	}
+
+	// A name the user typed is left alone
+	_, name, _ = processing.ResolveDestination("https://example.com/", "Mine.ZIP", t.TempDir(), false, settings, nil, nil)
+	if name != "Mine.ZIP" {
+		t.Errorf("name = %q, want the chosen name kept", name)
+	}
+}
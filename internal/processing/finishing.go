@@ -0,0 +1,49 @@
+package processing
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/state"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+const (
+	// finishingNotifyAfter is how long a download must have been running
+	// before it earns an "almost done" notification; short ones just finish.
+	finishingNotifyAfter = 15 * time.Minute
+	// finishingNotifyWithin is how close to the end the notification fires.
+	finishingNotifyWithin = 2 * time.Minute
+)
+
+// notifyFinishing sends a one-off notification when a long download is
+// projected to finish soon, saying when.
+func (mgr *LifecycleManager) notifyFinishing(p events.ProgressMsg, now time.Time) {
+	if p.Phase != "" && p.Phase != types.PhaseDownloading {
+		return
+	}
+	if p.Elapsed < finishingNotifyAfter || p.Speed <= 0 || p.Total <= p.Downloaded {
+		return
+	}
+	left := time.Duration(float64(p.Total-p.Downloaded) / p.Speed * float64(time.Second))
+	if left > finishingNotifyWithin {
+		return
+	}
+	settings := mgr.GetSettings()
+	if settings == nil || !config.Resolve[bool](settings.General.DownloadCompleteNotification) {
+		return
+	}
+	if _, seen := mgr.finishing.LoadOrStore(p.DownloadID, struct{}{}); seen {
+		return
+	}
+
+	filename := p.DownloadID
+	if entry, _ := state.GetDownload(p.DownloadID); entry != nil && entry.Filename != "" {
+		filename = entry.Filename
+	}
+	notify(fmt.Sprintf("Almost done: %s", filename),
+		fmt.Sprintf("Finishes ~%s, about %s from now", utils.FormatFinishTime(now.Add(left), now), left.Round(time.Second)))
+}
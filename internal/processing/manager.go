@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"net"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -14,16 +16,20 @@ import (
 	"net/url"
 
 	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine"
 	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/state"
 	"github.com/SurgeDM/Surge/internal/engine/types"
 	"github.com/SurgeDM/Surge/internal/utils"
+	"github.com/SurgeDM/Surge/internal/webdav"
 )
 
 // AddDownloadFunc is the lifecycle's handoff into the engine-facing queue layer.
-type AddDownloadFunc func(string, string, string, []string, map[string]string, bool, int64, bool) (string, error)
+// The last argument is what the probe left for the engine.
+type AddDownloadFunc func(string, string, string, []string, map[string]string, bool, int64, bool, types.ProbeHandoff) (string, error)
 
 // AddDownloadWithIDFunc preserves caller-chosen ids when a remote/UI layer already owns them.
-type AddDownloadWithIDFunc func(string, string, string, []string, map[string]string, string, int64, bool) (string, error)
+type AddDownloadWithIDFunc func(string, string, string, []string, map[string]string, string, int64, bool, types.ProbeHandoff) (string, error)
 
 // IsNameActiveFunc lets routing treat in-flight downloads as filename conflicts within a directory.
 type IsNameActiveFunc func(dir, name string) bool
@@ -40,6 +46,18 @@ type LifecycleManager struct {
 	// probeSem caps the number of simultaneous server probes so adding a
 	// large batch of downloads does not flood the network with HEAD requests.
 	probeSem chan struct{}
+	// sums tracks the batches waiting to write a SHA256SUMS manifest.
+	sums sumsRegistry
+	// offline holds the downloads added while the network was down.
+	offline offlineQueue
+	// finishing records the downloads already sent an "almost done" notice.
+	finishing sync.Map
+	// traffic counts the bytes downloaded towards monthly_quota.
+	traffic trafficTracker
+	// quota is the month's usage that downloads check before they start.
+	quota quotaState
+	// retries holds the failed downloads waiting to be tried again.
+	retries retryScheduler
 }
 
 const (
@@ -57,6 +75,8 @@ var settingsRefreshTTL = time.Second
 
 var reserveWorkingFile = precreateWorkingFile
 
+var probeServer = ProbeServerWithProxy
+
 func precreateWorkingFile(destPath, filename string) error {
 	if err := os.MkdirAll(destPath, 0o755); err != nil {
 		return fmt.Errorf("failed to create destination directory: %w", err)
@@ -170,6 +190,8 @@ func (m *LifecycleManager) ApplySettings(s *config.Settings) {
 	m.settings = s
 	m.settingsRefreshedAt = time.Now()
 	m.settingsMu.Unlock()
+	// Plugins, mirrors or headers in the new settings may change what a probe answers
+	probeCache.forget()
 }
 
 // SaveSettings persists and applies a new routing snapshot for future enqueue calls.
@@ -190,6 +212,29 @@ type DownloadRequest struct {
 	Headers            map[string]string
 	IsExplicitCategory bool
 	SkipApproval       bool
+	// TLS overrides the global TLS settings for this download only.
+	TLS types.TLSOptions
+	// Request sets the method and body for endpoints that do not serve the
+	// file to a plain GET.
+	Request types.RequestOptions
+}
+
+// resolveWebDAV points a webdav:// or webdavs:// download at its HTTP URL,
+// which serves the file with ranges like any other server. Credentials in
+// the URL become its Authorization header unless it already has one.
+func resolveWebDAV(req *DownloadRequest) error {
+	httpURL, headers, err := webdav.HTTPURL(req.URL)
+	if err != nil {
+		return err
+	}
+	req.URL = httpURL
+	if len(headers) > 0 {
+		// The map can be shared with other requests of the same batch
+		merged := maps.Clone(headers)
+		maps.Copy(merged, req.Headers)
+		req.Headers = merged
+	}
+	return nil
 }
 
 // Enqueue probes and reserves a stable destination before dispatching to the queue layer.
@@ -199,7 +244,7 @@ func (mgr *LifecycleManager) Enqueue(ctx context.Context, req *DownloadRequest)
 	}
 
 	utils.Debug("Lifecycle: Enqueue %s (Filename: %s)", req.URL, req.Filename)
-	return mgr.enqueueResolved(ctx, req, func(finalPath, finalFilename string, probe *ProbeResult) (string, error) {
+	id, filename, err := mgr.enqueueResolved(ctx, req, func(finalPath, finalFilename string, probe *ProbeResult, handoff types.ProbeHandoff) (string, error) {
 		return mgr.addFunc(
 			req.URL,
 			finalPath,
@@ -209,8 +254,15 @@ func (mgr *LifecycleManager) Enqueue(ctx context.Context, req *DownloadRequest)
 			req.IsExplicitCategory,
 			probe.FileSize,
 			probe.SupportsRange,
+			handoff,
 		)
 	})
+	// Waiting needs an id chosen up front, which only addWithIDFunc keeps
+	if errors.Is(err, errWaitingForNetwork) && mgr.addWithIDFunc != nil {
+		id, filename = mgr.waitForNetwork(req, newWaitingID())
+		return id, filename, nil
+	}
+	return id, filename, err
 }
 
 // EnqueueWithID does the same lifecycle work as Enqueue while preserving a caller-owned id.
@@ -220,23 +272,34 @@ func (mgr *LifecycleManager) EnqueueWithID(ctx context.Context, req *DownloadReq
 	}
 
 	utils.Debug("Lifecycle: EnqueueWithID %s (%s)", req.URL, requestID)
-	return mgr.enqueueResolved(ctx, req, func(finalPath, finalFilename string, probe *ProbeResult) (string, error) {
+	id, filename, err := mgr.enqueueResolved(ctx, req, mgr.dispatchWithID(req, requestID))
+	if errors.Is(err, errWaitingForNetwork) {
+		id, filename = mgr.waitForNetwork(req, requestID)
+		return id, filename, nil
+	}
+	return id, filename, err
+}
+
+// dispatchWithID hands a resolved download to addWithIDFunc under id.
+func (mgr *LifecycleManager) dispatchWithID(req *DownloadRequest, id string) func(string, string, *ProbeResult, types.ProbeHandoff) (string, error) {
+	return func(finalPath, finalFilename string, probe *ProbeResult, handoff types.ProbeHandoff) (string, error) {
 		return mgr.addWithIDFunc(
 			req.URL,
 			finalPath,
 			finalFilename,
 			req.Mirrors,
 			req.Headers,
-			requestID,
+			id,
 			probe.FileSize,
 			probe.SupportsRange,
+			handoff,
 		)
-	})
+	}
 }
 
 // enqueueResolved prepares the final path and working file before handing the
 // download to the engine, so workers and lifecycle events agree on one stable destination.
-func (mgr *LifecycleManager) enqueueResolved(ctx context.Context, req *DownloadRequest, dispatch func(string, string, *ProbeResult) (string, error)) (string, string, error) {
+func (mgr *LifecycleManager) enqueueResolved(ctx context.Context, req *DownloadRequest, dispatch func(string, string, *ProbeResult, types.ProbeHandoff) (string, error)) (string, string, error) {
 	if req.URL == "" {
 		return "", "", types.ErrURLRequired
 	}
@@ -258,36 +321,16 @@ func (mgr *LifecycleManager) enqueueResolved(ctx context.Context, req *DownloadR
 		defer func() { mgr.probeSem <- struct{}{} }()
 	}
 
-	probe, probeErr := ProbeServerWithProxy(ctx, req.URL, req.Filename, req.Headers, settings.ToRuntimeConfig())
-	if probeErr != nil {
-		// Distinguish between terminal client errors (invalid scheme, etc) and
-		// server-side rejections or timeouts that we can optimistically ignore.
-		var urlErr *url.Error
-		var isTerminal bool
-		if errors.As(probeErr, &urlErr) {
-			var opErr *net.OpError
-			isTerminal = !errors.As(probeErr, &opErr) && // not a network-layer error
-				strings.Contains(urlErr.Error(), "unsupported protocol scheme")
-		}
-		isTerminal = isTerminal || errors.Is(probeErr, ErrProbeRequestCreation)
-
-		if isTerminal {
-			return "", "", probeErr
-		}
+	probe, _, _, err := mgr.probeRequest(ctx, req, settings)
+	if err != nil {
+		return "", "", err
+	}
+	if err := mgr.applyScriptHooks(req, probe); err != nil {
+		return "", "", err
+	}
 
-		utils.Debug("Lifecycle: Probe failed: %v - enqueueing with optimistic fallback metadata\n", probeErr)
-		// Probe failures are non-fatal for known server-side issues (403/405/500) or
-		// network timeouts: some servers reject or intermittently fail
-		// lightweight probe requests but still accept the actual download flow.
-		// Mark range support as "unknown, try it" by keeping size at zero and
-		// setting SupportsRange so the download path can attempt a concurrent
-		// bootstrap before falling back to single-stream mode.
-		probe = &ProbeResult{}
-		probe.SupportsRange = true
-		if req.Filename != "" {
-			probe.Filename = req.Filename
-			probe.DetectedFilename = req.Filename
-		}
+	if threshold := config.Resolve[int64](settings.General.ConfirmSizeThreshold); threshold > 0 && probe.FileSize > threshold && !req.Request.Confirmed {
+		return "", "", &types.LargeDownloadError{Size: probe.FileSize, Threshold: threshold}
 	}
 
 	isNameActive := mgr.buildIsNameActive()
@@ -319,9 +362,19 @@ func (mgr *LifecycleManager) enqueueResolved(ctx context.Context, req *DownloadR
 			return "", "", err
 		}
 
-		surgePath := filepath.Join(finalPath, finalFilename) + types.IncompleteSuffix
-		newID, err := dispatch(finalPath, finalFilename, probe)
+		destFile := filepath.Join(finalPath, finalFilename)
+		surgePath := destFile + types.IncompleteSuffix
+		if err := engine.CreateCopies(destFile, req.Request.Copies); err != nil {
+			engine.RemoveCopies(destFile, req.Request.Copies)
+			_ = os.Remove(surgePath)
+			return "", "", err
+		}
+		handoff := handOffProbe(destFile, probe, req.TLS, req.Request)
+		handoff.QuotaReached = mgr.QuotaReached
+
+		newID, err := dispatch(finalPath, finalFilename, probe, handoff)
 		if err != nil {
+			engine.RemoveCopies(destFile, req.Request.Copies)
 			_ = os.Remove(surgePath)
 			return "", "", err
 		}
@@ -331,6 +384,10 @@ func (mgr *LifecycleManager) enqueueResolved(ctx context.Context, req *DownloadR
 		// worker emits a started event.
 		hooks := mgr.getEngineHooks()
 		if hooks.PublishEvent != nil {
+			var method string
+			if !req.Request.IsGet() {
+				method = req.Request.EffectiveMethod()
+			}
 			var rateLimit int64
 			var rateLimitSet bool
 			if hooks.GetStatus != nil {
@@ -344,13 +401,18 @@ func (mgr *LifecycleManager) enqueueResolved(ctx context.Context, req *DownloadR
 				}
 			}
 			_ = hooks.PublishEvent(events.DownloadQueuedMsg{
-				DownloadID:   newID,
-				Filename:     finalFilename,
-				URL:          req.URL,
-				DestPath:     filepath.Join(finalPath, finalFilename),
-				Mirrors:      append([]string(nil), req.Mirrors...),
-				RateLimit:    rateLimit,
-				RateLimitSet: rateLimitSet,
+				DownloadID:      newID,
+				Filename:        finalFilename,
+				URL:             req.URL,
+				DestPath:        filepath.Join(finalPath, finalFilename),
+				Mirrors:         append([]string(nil), req.Mirrors...),
+				RateLimit:       rateLimit,
+				RateLimitSet:    rateLimitSet,
+				Alias:           req.Request.Alias,
+				Tags:            req.Request.Tags,
+				ResponseHeaders: probe.ResponseHeaders,
+				Redirects:       probe.Redirects,
+				Method:          method,
 			})
 		}
 
@@ -360,6 +422,119 @@ func (mgr *LifecycleManager) enqueueResolved(ctx context.Context, req *DownloadR
 	return "", "", fmt.Errorf("failed to reserve unique working file for %q after %d attempts", req.URL, maxWorkingFileReservationAttempts)
 }
 
+// probeRequest validates req, fills in its group mirrors and probes the
+// server the way an enqueue does. A probe failure the download can survive
+// is returned as probeErr alongside the fallback result it would start with.
+func (mgr *LifecycleManager) probeRequest(ctx context.Context, req *DownloadRequest, settings *config.Settings) (probe *ProbeResult, runCfg *types.RuntimeConfig, probeErr error, err error) {
+	if err := req.Request.Validate(); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := checkAliasAvailable(req.Request.Alias); err != nil {
+		return nil, nil, nil, err
+	}
+	req.Request.Tags, _ = types.NormalizeTags(req.Request.Tags)
+	if webdav.IsURL(req.URL) {
+		if err := resolveWebDAV(req); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	if err := applyURLPlugins(ctx, req); err != nil {
+		return nil, nil, nil, err
+	}
+
+	if req.Request.IsGet() {
+		req.Mirrors = withGroupMirrors(settings, req.URL, req.Mirrors)
+	}
+
+	runCfg = settings.ToRuntimeConfig()
+	runCfg.TLS = runCfg.TLS.Merge(req.TLS)
+	runCfg = req.Request.Timeouts.Apply(runCfg)
+	runCfg = types.BindRuntime(runCfg, req.Request.Interface, req.URL)
+
+	if req.Request.IsGet() {
+		probe, probeErr = probeServer(ctx, req.URL, req.Filename, req.Headers, runCfg)
+	} else {
+		// A POST may start an export or change server state, so it is not
+		// sent twice just to learn the size; the download finds out instead.
+		probe = &ProbeResult{Filename: req.Filename, DetectedFilename: req.Filename}
+	}
+	if probeErr == nil {
+		return probe, runCfg, nil, nil
+	}
+
+	// Distinguish between terminal client errors (invalid scheme, etc) and
+	// server-side rejections or timeouts that we can optimistically ignore.
+	var urlErr *url.Error
+	var isTerminal bool
+	if errors.As(probeErr, &urlErr) {
+		var opErr *net.OpError
+		isTerminal = !errors.As(probeErr, &opErr) && // not a network-layer error
+			strings.Contains(urlErr.Error(), "unsupported protocol scheme")
+	}
+	isTerminal = isTerminal || errors.Is(probeErr, ErrProbeRequestCreation) ||
+		errors.Is(probeErr, types.ErrCrossHostRedirect) || errors.Is(probeErr, types.ErrMaxRedirects) ||
+		errors.Is(probeErr, types.ErrCertificatePin)
+
+	if isTerminal {
+		return nil, nil, nil, probeErr
+	}
+	// With no network the download would only fail, so it waits instead
+	if isOffline(probeErr) {
+		return nil, nil, nil, fmt.Errorf("%w: %w", errWaitingForNetwork, probeErr)
+	}
+
+	utils.Debug("Lifecycle: Probe failed: %v - enqueueing with optimistic fallback metadata\n", probeErr)
+	// Probe failures are non-fatal for known server-side issues (403/405/500) or
+	// network timeouts: some servers reject or intermittently fail
+	// lightweight probe requests but still accept the actual download flow.
+	// Mark range support as "unknown, try it" by keeping size at zero and
+	// setting SupportsRange so the download path can attempt a concurrent
+	// bootstrap before falling back to single-stream mode.
+	probe = &ProbeResult{}
+	probe.SupportsRange = true
+	if req.Filename != "" {
+		probe.Filename = req.Filename
+		probe.DetectedFilename = req.Filename
+	}
+	return probe, runCfg, probeErr, nil
+}
+
+// checkAliasAvailable rejects an alias still held by an unfinished download.
+// A completed download gives its alias up to the next download that asks for it.
+func checkAliasAvailable(alias string) error {
+	if alias == "" {
+		return nil
+	}
+	existing, err := state.GetDownloadByAlias(alias)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.Status != "completed" {
+		return fmt.Errorf("%w: %q belongs to %s", types.ErrAliasTaken, alias, existing.ID)
+	}
+	return nil
+}
+
+// withGroupMirrors adds the rest of rawurl's mirror group to mirrors. The
+// engine checks every mirror before the download starts and drops dead ones.
+func withGroupMirrors(settings *config.Settings, rawurl string, mirrors []string) []string {
+	if settings == nil {
+		return mirrors
+	}
+	groups, err := types.ParseMirrorGroups(config.Resolve[string](settings.Network.MirrorGroups))
+	if err != nil {
+		utils.Debug("Lifecycle: ignoring invalid mirror groups: %v", err)
+		return mirrors
+	}
+	mirrors = slices.Clip(mirrors) // never append into the caller's array
+	for _, m := range groups.Mirrors(rawurl) {
+		if m != rawurl && !slices.Contains(mirrors, m) {
+			mirrors = append(mirrors, m)
+		}
+	}
+	return mirrors
+}
+
 // IsNameActive reports whether the configured active-download callback would
 // treat the given directory/name pair as an in-flight conflict.
 func (mgr *LifecycleManager) IsNameActive(dir, name string) bool {
@@ -8,6 +8,8 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -58,7 +60,7 @@ func TestLifecycleManager_Enqueue_PrecreatesWorkingFileBeforeDispatch(t *testing
 	expectedID := "enqueue-id"
 
 	mgr := newLifecycleManagerForTest()
-	mgr.addFunc = func(url, path, filename string, _ []string, _ map[string]string, explicit bool, totalSize int64, supportsRange bool) (string, error) {
+	mgr.addFunc = func(url, path, filename string, _ []string, _ map[string]string, explicit bool, totalSize int64, supportsRange bool, _ types.ProbeHandoff) (string, error) {
 		if url != server.URL {
 			t.Fatalf("url = %q, want %q", url, server.URL)
 		}
@@ -116,7 +118,7 @@ func TestLifecycleManager_EnqueueWithID_PrecreatesWorkingFileBeforeDispatch(t *t
 	expectedID := "request-id"
 
 	mgr := newLifecycleManagerForTest()
-	mgr.addWithIDFunc = func(url, path, filename string, _ []string, _ map[string]string, requestID string, totalSize int64, supportsRange bool) (string, error) {
+	mgr.addWithIDFunc = func(url, path, filename string, _ []string, _ map[string]string, requestID string, totalSize int64, supportsRange bool, _ types.ProbeHandoff) (string, error) {
 		if url != server.URL {
 			t.Fatalf("url = %q, want %q", url, server.URL)
 		}
@@ -175,7 +177,7 @@ func TestLifecycleManager_EnqueueWithID_ProbeFailureStillDispatches(t *testing.T
 	expectedID := "request-id"
 
 	mgr := newLifecycleManagerForTest()
-	mgr.addWithIDFunc = func(url, path, filename string, _ []string, _ map[string]string, requestID string, totalSize int64, supportsRange bool) (string, error) {
+	mgr.addWithIDFunc = func(url, path, filename string, _ []string, _ map[string]string, requestID string, totalSize int64, supportsRange bool, _ types.ProbeHandoff) (string, error) {
 		if url != server.URL {
 			t.Fatalf("url = %q, want %q", url, server.URL)
 		}
@@ -222,7 +224,7 @@ func TestLifecycleManager_Enqueue_RemovesWorkingFileOnDispatchError(t *testing.T
 	expectedErr := errors.New("dispatch failed")
 
 	mgr := newLifecycleManagerForTest()
-	mgr.addFunc = func(string, string, string, []string, map[string]string, bool, int64, bool) (string, error) {
+	mgr.addFunc = func(string, string, string, []string, map[string]string, bool, int64, bool, types.ProbeHandoff) (string, error) {
 		return "", expectedErr
 	}
 
@@ -271,7 +273,7 @@ func TestLifecycleManager_Enqueue_RetriesWhenWorkingFileReservationCollides(t *t
 
 	mgr := newLifecycleManagerForTest()
 	var dispatchedFilename string
-	mgr.addFunc = func(url, path, filename string, _ []string, _ map[string]string, explicit bool, totalSize int64, supportsRange bool) (string, error) {
+	mgr.addFunc = func(url, path, filename string, _ []string, _ map[string]string, explicit bool, totalSize int64, supportsRange bool, _ types.ProbeHandoff) (string, error) {
 		dispatchedFilename = filename
 		if path != tempDir {
 			t.Fatalf("path = %q, want %q", path, tempDir)
@@ -344,7 +346,7 @@ func TestLifecycleManager_EnqueueWithID_RetriesWhenWorkingFileReservationCollide
 
 	mgr := newLifecycleManagerForTest()
 	var dispatchedFilename string
-	mgr.addWithIDFunc = func(url, path, filename string, _ []string, _ map[string]string, gotRequestID string, totalSize int64, supportsRange bool) (string, error) {
+	mgr.addWithIDFunc = func(url, path, filename string, _ []string, _ map[string]string, gotRequestID string, totalSize int64, supportsRange bool, _ types.ProbeHandoff) (string, error) {
 		dispatchedFilename = filename
 		if path != tempDir {
 			t.Fatalf("path = %q, want %q", path, tempDir)
@@ -396,7 +398,7 @@ func TestLifecycleManager_EnqueueWithID_RemovesWorkingFileOnDispatchError(t *tes
 	expectedErr := errors.New("dispatch failed")
 
 	mgr := newLifecycleManagerForTest()
-	mgr.addWithIDFunc = func(string, string, string, []string, map[string]string, string, int64, bool) (string, error) {
+	mgr.addWithIDFunc = func(string, string, string, []string, map[string]string, string, int64, bool, types.ProbeHandoff) (string, error) {
 		return "", expectedErr
 	}
 
@@ -437,7 +439,7 @@ func TestLifecycleManager_Enqueue_FailsAfterReservationAttemptLimit(t *testing.T
 	}
 
 	mgr := newLifecycleManagerForTest()
-	mgr.addFunc = func(string, string, string, []string, map[string]string, bool, int64, bool) (string, error) {
+	mgr.addFunc = func(string, string, string, []string, map[string]string, bool, int64, bool, types.ProbeHandoff) (string, error) {
 		t.Fatal("dispatch should not run when reservation never succeeds")
 		return "", nil
 	}
@@ -523,7 +525,7 @@ func TestLifecycleManager_Enqueue_ContextCancellationDuringProbe(t *testing.T) {
 	defer server.Close()
 
 	mgr := newLifecycleManagerForTest()
-	mgr.addFunc = func(string, string, string, []string, map[string]string, bool, int64, bool) (string, error) {
+	mgr.addFunc = func(string, string, string, []string, map[string]string, bool, int64, bool, types.ProbeHandoff) (string, error) {
 		t.Fatal("dispatch should not run when probe fails")
 		return "", nil
 	}
@@ -565,7 +567,7 @@ func TestLifecycleManager_EnqueueWithID_FailsAfterReservationAttemptLimit(t *tes
 	}
 
 	mgr := newLifecycleManagerForTest()
-	mgr.addWithIDFunc = func(string, string, string, []string, map[string]string, string, int64, bool) (string, error) {
+	mgr.addWithIDFunc = func(string, string, string, []string, map[string]string, string, int64, bool, types.ProbeHandoff) (string, error) {
 		t.Fatal("dispatch should not run when reservation never succeeds")
 		return "", nil
 	}
@@ -624,7 +626,7 @@ func TestLifecycleManager_Enqueue_ContextCancellationBeforeReservation(t *testin
 	defer server.Close()
 
 	mgr := newLifecycleManagerForTest()
-	mgr.addFunc = func(string, string, string, []string, map[string]string, bool, int64, bool) (string, error) {
+	mgr.addFunc = func(string, string, string, []string, map[string]string, bool, int64, bool, types.ProbeHandoff) (string, error) {
 		t.Fatal("dispatch should not run when context is canceled before reservation")
 		return "", nil
 	}
@@ -772,6 +774,32 @@ func TestLifecycleManager_Resume_Completed(t *testing.T) {
 	}
 }
 
+func TestLifecycleManager_Resume_ColdPathRefusesRequestBody(t *testing.T) {
+	tempDir := testutil.SetupStateDB(t)
+
+	testutil.SeedMasterList(t, types.DownloadEntry{
+		ID:       "post-id",
+		URL:      "http://example.com/export",
+		URLHash:  state.URLHash("http://example.com/export"),
+		DestPath: filepath.Join(tempDir, "export.csv"),
+		Filename: "export.csv",
+		Status:   "paused",
+		Method:   http.MethodPost,
+	})
+
+	mgr := newLifecycleManagerForTest()
+	mgr.SetEngineHooks(EngineHooks{
+		ExtractPausedConfig: func(id string) *types.DownloadConfig { return nil },
+		AddConfig: func(cfg types.DownloadConfig) {
+			t.Errorf("AddConfig called for %s, want the resume refused", cfg.ID)
+		},
+	})
+
+	if err := mgr.Resume("post-id"); !errors.Is(err, types.ErrNotResumable) {
+		t.Fatalf("Resume error = %v, want ErrNotResumable", err)
+	}
+}
+
 func TestLifecycleManager_Resume_StillPausing(t *testing.T) {
 	var extraCalled bool
 	mgr := newLifecycleManagerForTest()
@@ -1072,7 +1100,7 @@ func TestLifecycleManager_ProbeSemaphore_LimitsInflight(t *testing.T) {
 	}()
 
 	mgr := newLifecycleManagerForTest()
-	mgr.addFunc = func(string, string, string, []string, map[string]string, bool, int64, bool) (string, error) {
+	mgr.addFunc = func(string, string, string, []string, map[string]string, bool, int64, bool, types.ProbeHandoff) (string, error) {
 		return "", fmt.Errorf("dispatch intentionally rejected for test")
 	}
 	settings := config.DefaultSettings()
@@ -1117,7 +1145,7 @@ func TestLifecycleManager_ProbeSemaphore_LimitsInflight(t *testing.T) {
 func TestLifecycleManager_ProbeSemaphore_CancelledContextAbortsWait(t *testing.T) {
 	// Build a manager and fill its semaphore completely so the next Enqueue blocks.
 	mgr := newLifecycleManagerForTest()
-	mgr.addFunc = func(string, string, string, []string, map[string]string, bool, int64, bool) (string, error) {
+	mgr.addFunc = func(string, string, string, []string, map[string]string, bool, int64, bool, types.ProbeHandoff) (string, error) {
 		t.Fatal("dispatch should not run when context is cancelled")
 		return "", nil
 	}
@@ -1155,3 +1183,111 @@ func TestLifecycleManager_ProbeSemaphore_CancelledContextAbortsWait(t *testing.T
 		t.Errorf("Enqueue took %v to abort - semaphore cancellation may be broken", elapsed)
 	}
 }
+
+func TestLifecycleManager_Enqueue_PostSkipsProbe(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	tempDir := t.TempDir()
+	request := types.RequestOptions{Method: http.MethodPost, Body: `{"format":"csv"}`}
+
+	mgr := newLifecycleManagerForTest()
+	mgr.addFunc = func(_, _, _ string, _ []string, _ map[string]string, _ bool, totalSize int64, supportsRange bool, handoff types.ProbeHandoff) (string, error) {
+		if supportsRange || totalSize != 0 {
+			t.Errorf("dispatch got size %d, range %v; a POST should leave both to the download", totalSize, supportsRange)
+		}
+		if !reflect.DeepEqual(handoff.Request, request) {
+			t.Errorf("handoff request = %+v, want %+v", handoff.Request, request)
+		}
+		return "post-id", nil
+	}
+
+	_, filename, err := mgr.Enqueue(context.Background(), &DownloadRequest{
+		URL:      server.URL + "/export",
+		Filename: "report.csv",
+		Path:     tempDir,
+		Request:  request,
+	})
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if filename != "report.csv" {
+		t.Errorf("filename = %q, want report.csv", filename)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("server saw %d requests before the download started, want 0", n)
+	}
+
+	_, _, err = mgr.Enqueue(context.Background(), &DownloadRequest{
+		URL:     server.URL + "/export",
+		Path:    tempDir,
+		Request: types.RequestOptions{Body: "a=b"},
+	})
+	if err == nil {
+		t.Error("a body without a method other than GET should be rejected")
+	}
+}
+
+func TestLifecycleManager_Enqueue_AddsMirrorGroup(t *testing.T) {
+	server := newProbeTestServer(t, 1234)
+	defer server.Close()
+	tempDir := t.TempDir()
+
+	mgr := newLifecycleManagerForTest()
+	mgr.settings.Network.MirrorGroups.Value = server.URL + "/debian, https://mirror-a.example/debian/; https://other.example/a, https://other.example/b"
+
+	var got []string
+	mgr.addFunc = func(_, _, _ string, mirrors []string, _ map[string]string, _ bool, _ int64, _ bool, _ types.ProbeHandoff) (string, error) {
+		got = mirrors
+		return "group-id", nil
+	}
+
+	if _, _, err := mgr.Enqueue(context.Background(), &DownloadRequest{
+		URL:     server.URL + "/debian/pool/hello.tar.gz",
+		Path:    tempDir,
+		Mirrors: []string{"https://extra.example/hello.tar.gz"},
+	}); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	want := []string{"https://extra.example/hello.tar.gz", "https://mirror-a.example/debian/pool/hello.tar.gz"}
+	if !slices.Equal(got, want) {
+		t.Errorf("mirrors = %v, want %v", got, want)
+	}
+}
+
+func TestLifecycleManager_Enqueue_LargeDownloadNeedsConfirmation(t *testing.T) {
+	server := newProbeTestServer(t, 4*types.MB)
+	defer server.Close()
+	tempDir := t.TempDir()
+
+	mgr := newLifecycleManagerForTest()
+	mgr.settings.General.ConfirmSizeThreshold.Value = int64(types.MB)
+
+	dispatched := 0
+	mgr.addFunc = func(_, _, _ string, _ []string, _ map[string]string, _ bool, _ int64, _ bool, _ types.ProbeHandoff) (string, error) {
+		dispatched++
+		return "large-id", nil
+	}
+
+	req := &DownloadRequest{URL: server.URL, Filename: "huge.iso", Path: tempDir}
+	_, _, err := mgr.Enqueue(context.Background(), req)
+	var large *types.LargeDownloadError
+	if !errors.As(err, &large) || large.Size != 4*types.MB || large.Threshold != types.MB {
+		t.Fatalf("Enqueue error = %v, want a LargeDownloadError for 4MB over 1MB", err)
+	}
+	if dispatched != 0 {
+		t.Fatal("a download needing confirmation should not be dispatched")
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "huge.iso") + types.IncompleteSuffix); !os.IsNotExist(err) {
+		t.Fatalf("expected no working file before confirmation, stat err = %v", err)
+	}
+
+	req.Request.Confirmed = true
+	if id, _, err := mgr.Enqueue(context.Background(), req); err != nil || id != "large-id" {
+		t.Fatalf("confirmed Enqueue = %q, %v; want it dispatched", id, err)
+	}
+}
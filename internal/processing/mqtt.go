@@ -0,0 +1,93 @@
+package processing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// mqttPublisher is the part of utils.MQTTClient the event publisher uses.
+type mqttPublisher interface {
+	Publish(topic string, payload []byte) error
+	Close() error
+}
+
+var dialMQTT = func(broker, clientID string) (mqttPublisher, error) {
+	client, err := utils.DialMQTT(broker, clientID)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// StartMQTTPublisher publishes lifecycle events to <prefix>/<event> on the
+// broker named in the notification settings, so home automation can react to
+// finished downloads without polling. Progress is not published. The broker
+// is connected on first use and redialed when the setting changes or the
+// connection drops.
+func (mgr *LifecycleManager) StartMQTTPublisher(ch <-chan interface{}) {
+	var client mqttPublisher
+	var connectedTo string
+	defer func() {
+		if client != nil {
+			_ = client.Close()
+		}
+	}()
+
+	for msg := range ch {
+		switch msg.(type) {
+		case events.DownloadQueuedMsg, events.DownloadStartedMsg, events.DownloadPausedMsg,
+			events.DownloadResumedMsg, events.DownloadCompleteMsg, events.DownloadErrorMsg,
+			events.DownloadRemovedMsg:
+		default:
+			continue
+		}
+
+		broker, prefix := "", ""
+		if settings := mgr.GetSettings(); settings != nil {
+			broker = strings.TrimSpace(config.Resolve[string](settings.Notifications.MQTTBroker))
+			prefix = strings.Trim(config.Resolve[string](settings.Notifications.MQTTTopic), "/ ")
+		}
+		if broker != connectedTo && client != nil {
+			_ = client.Close()
+			client = nil
+		}
+		connectedTo = broker
+		if broker == "" {
+			continue
+		}
+
+		eventType, _ := events.EventTypeForMessage(msg)
+		topic := eventType
+		if prefix != "" {
+			topic = prefix + "/" + eventType
+		}
+		payload, err := json.Marshal(msg)
+		if err != nil {
+			utils.Debug("MQTT: Failed to encode %s event: %v", eventType, err)
+			continue
+		}
+
+		// A broker may drop an idle connection, so a failed publish redials once.
+		for attempt := 0; attempt < 2; attempt++ {
+			if client == nil {
+				client, err = dialMQTT(broker, fmt.Sprintf("surge-%d", os.Getpid()))
+				if err != nil {
+					utils.Debug("MQTT: Failed to connect to broker: %v", err)
+					break
+				}
+			}
+			if err = client.Publish(topic, payload); err == nil {
+				break
+			}
+			utils.Debug("MQTT: Failed to publish to %s: %v", topic, err)
+			_ = client.Close()
+			client = nil
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package processing
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/SurgeDM/Surge/internal/engine/events"
+)
+
+type fakeMQTTClient struct {
+	topics []string
+	failed bool
+	closed bool
+}
+
+func (c *fakeMQTTClient) Publish(topic string, payload []byte) error {
+	if c.failed {
+		return errors.New("connection reset")
+	}
+	c.topics = append(c.topics, topic+" "+string(payload))
+	return nil
+}
+
+func (c *fakeMQTTClient) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestStartMQTTPublisher_PublishesLifecycleEvents(t *testing.T) {
+	var dialed []*fakeMQTTClient
+	origDial := dialMQTT
+	t.Cleanup(func() { dialMQTT = origDial })
+	dialMQTT = func(broker, clientID string) (mqttPublisher, error) {
+		client := &fakeMQTTClient{}
+		// The first connection has gone stale, as if the broker dropped it.
+		client.failed = len(dialed) == 0
+		dialed = append(dialed, client)
+		return client, nil
+	}
+
+	mgr := newLifecycleManagerForTest()
+	mgr.settings.Notifications.MQTTBroker.Value = "mqtt://broker.local"
+	mgr.settings.Notifications.MQTTTopic.Value = "home/surge/"
+
+	ch := make(chan interface{}, 3)
+	ch <- events.ProgressMsg{DownloadID: "a"}
+	ch <- events.DownloadCompleteMsg{DownloadID: "a", Filename: "a.iso"}
+	ch <- events.DownloadRemovedMsg{DownloadID: "a"}
+	close(ch)
+	mgr.StartMQTTPublisher(ch)
+
+	if len(dialed) != 2 || !dialed[0].closed {
+		t.Fatalf("dialed %d clients, want a redial after the stale one was closed", len(dialed))
+	}
+	got := dialed[1].topics
+	if len(got) != 2 || !strings.HasPrefix(got[0], `home/surge/complete {"DownloadID":"a","Filename":"a.iso"`) ||
+		!strings.HasPrefix(got[1], "home/surge/removed ") {
+		t.Fatalf("published %q", got)
+	}
+	if !dialed[1].closed {
+		t.Fatal("client should be closed when the event stream ends")
+	}
+}
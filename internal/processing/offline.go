@@ -0,0 +1,223 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"path"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// offlineCheckInterval is how often downloads waiting for the network check
+// whether their server can be reached again.
+var offlineCheckInterval = 15 * time.Second
+
+// errWaitingForNetwork marks an enqueue whose probe could not leave the
+// machine, so the download waits for the network instead of failing.
+var errWaitingForNetwork = errors.New("waiting for network")
+
+// reachable reports whether a connection to rawurl, or to the proxy in front
+// of it, can be opened. It is a variable so tests can stand in for the network.
+var reachable = dialReachable
+
+// waitingRequest is a download added while the network was down.
+type waitingRequest struct {
+	req      DownloadRequest
+	filename string
+}
+
+// offlineQueue holds the downloads waiting for the network. They live in
+// memory only: nothing is reserved on disk until they are probed.
+type offlineQueue struct {
+	mu      sync.Mutex
+	waiting map[string]*waitingRequest
+	running bool
+}
+
+// isOffline reports whether err means the request never reached the
+// network: no route, no interface up, or no resolver answering. A name the
+// resolver does not know is the server's problem, not the network's.
+func isOffline(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && !dnsErr.IsNotFound {
+		return true
+	}
+	return errors.Is(err, syscall.ENETUNREACH) || errors.Is(err, syscall.ENETDOWN)
+}
+
+// waitForNetwork holds req under id until its server can be reached, then
+// enqueues it. It returns the filename the download is shown under meanwhile.
+func (mgr *LifecycleManager) waitForNetwork(req *DownloadRequest, id string) (string, string) {
+	filename := req.Filename
+	if filename == "" {
+		if u, err := url.Parse(req.URL); err == nil {
+			filename = path.Base(u.Path)
+		}
+		if filename == "" || filename == "/" || filename == "." {
+			filename = req.URL
+		}
+	}
+
+	mgr.offline.mu.Lock()
+	if mgr.offline.waiting == nil {
+		mgr.offline.waiting = make(map[string]*waitingRequest)
+	}
+	mgr.offline.waiting[id] = &waitingRequest{req: *req, filename: filename}
+	start := !mgr.offline.running
+	mgr.offline.running = true
+	mgr.offline.mu.Unlock()
+
+	utils.Debug("Lifecycle: %s is waiting for the network", req.URL)
+	if hooks := mgr.getEngineHooks(); hooks.PublishEvent != nil {
+		_ = hooks.PublishEvent(events.DownloadQueuedMsg{
+			DownloadID:        id,
+			Filename:          filename,
+			URL:               req.URL,
+			DestPath:          req.Path,
+			Mirrors:           append([]string(nil), req.Mirrors...),
+			Alias:             req.Request.Alias,
+			Tags:              req.Request.Tags,
+			WaitingForNetwork: true,
+		})
+	}
+	if start {
+		go mgr.watchNetwork(offlineCheckInterval)
+	}
+	return id, filename
+}
+
+// watchNetwork checks the waiting downloads every interval and enqueues each
+// one whose server answers. It returns once none are left.
+func (mgr *LifecycleManager) watchNetwork(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		mgr.offline.mu.Lock()
+		if len(mgr.offline.waiting) == 0 {
+			mgr.offline.running = false
+			mgr.offline.mu.Unlock()
+			return
+		}
+		batch := make(map[string]*waitingRequest, len(mgr.offline.waiting))
+		for id, w := range mgr.offline.waiting {
+			batch[id] = w
+		}
+		mgr.offline.mu.Unlock()
+
+		proxyURL := mgr.GetSettings().ToRuntimeConfig().ProxyURL
+		for id, w := range batch {
+			if !reachable(w.req.URL, proxyURL) {
+				continue
+			}
+			mgr.offline.mu.Lock()
+			// Removed while the check ran
+			if mgr.offline.waiting[id] != w {
+				mgr.offline.mu.Unlock()
+				continue
+			}
+			delete(mgr.offline.waiting, id)
+			mgr.offline.mu.Unlock()
+			mgr.enqueueWaiting(id, w)
+		}
+	}
+}
+
+// enqueueWaiting probes and starts a download that was waiting for the
+// network, under the id it was given when it was added.
+func (mgr *LifecycleManager) enqueueWaiting(id string, w *waitingRequest) {
+	req := w.req
+	_, _, err := mgr.enqueueResolved(context.Background(), &req, mgr.dispatchWithID(&req, id))
+	if err == nil {
+		return
+	}
+	if errors.Is(err, errWaitingForNetwork) {
+		// The check got through but the probe did not: keep waiting quietly
+		mgr.offline.mu.Lock()
+		mgr.offline.waiting[id] = w
+		mgr.offline.mu.Unlock()
+		return
+	}
+	utils.Debug("Lifecycle: %s failed once the network was back: %v", req.URL, err)
+	if hooks := mgr.getEngineHooks(); hooks.PublishEvent != nil {
+		_ = hooks.PublishEvent(events.DownloadErrorMsg{
+			DownloadID: id,
+			Filename:   w.filename,
+			Err:        err,
+		})
+	}
+}
+
+// removeWaiting drops a download waiting for the network and returns it.
+func (mgr *LifecycleManager) removeWaiting(id string) (*waitingRequest, bool) {
+	mgr.offline.mu.Lock()
+	defer mgr.offline.mu.Unlock()
+	w, ok := mgr.offline.waiting[id]
+	delete(mgr.offline.waiting, id)
+	return w, ok
+}
+
+// Waiting lists the downloads waiting for the network, as queued downloads
+// in the waiting-for-network phase.
+func (mgr *LifecycleManager) Waiting() []types.DownloadStatus {
+	mgr.offline.mu.Lock()
+	defer mgr.offline.mu.Unlock()
+	statuses := make([]types.DownloadStatus, 0, len(mgr.offline.waiting))
+	for id, w := range mgr.offline.waiting {
+		statuses = append(statuses, types.DownloadStatus{
+			ID:       id,
+			URL:      w.req.URL,
+			Filename: w.filename,
+			DestPath: w.req.Path,
+			Status:   "queued",
+			Phase:    types.PhaseWaitingForNetwork,
+			Alias:    w.req.Request.Alias,
+			Tags:     w.req.Request.Tags,
+		})
+	}
+	return statuses
+}
+
+// newWaitingID picks the id a download added while offline keeps once it
+// reaches the engine.
+func newWaitingID() string {
+	return uuid.New().String()
+}
+
+// dialReachable opens and closes a connection to the host of rawurl, or to
+// proxyURL when one is set.
+func dialReachable(rawurl, proxyURL string) bool {
+	target := rawurl
+	if proxyURL != "" {
+		target = proxyURL
+	}
+	u, err := url.Parse(target)
+	if err != nil || u.Hostname() == "" {
+		return false
+	}
+	port := u.Port()
+	if port == "" {
+		switch u.Scheme {
+		case "http":
+			port = "80"
+		case "socks5", "socks5h":
+			port = "1080"
+		default:
+			port = "443"
+		}
+	}
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(u.Hostname(), port), types.DialTimeout)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
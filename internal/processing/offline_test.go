@@ -0,0 +1,149 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func offlineProbeError(rawurl string) error {
+	return &url.Error{Op: "Get", URL: rawurl, Err: &net.OpError{
+		Op:  "dial",
+		Net: "tcp",
+		Err: os.NewSyscallError("connect", syscall.ENETUNREACH),
+	}}
+}
+
+func TestIsOffline(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"network unreachable", offlineProbeError("https://example.com/f"), true},
+		{"network down", fmt.Errorf("probe: %w", syscall.ENETDOWN), true},
+		{"resolver unreachable", &net.DNSError{Err: "server misbehaving", Name: "example.com", IsTemporary: true}, true},
+		{"unknown host", &net.DNSError{Err: "no such host", Name: "nope.invalid", IsNotFound: true}, false},
+		{"connection refused", fmt.Errorf("probe: %w", syscall.ECONNREFUSED), false},
+		{"server error", errors.New("unexpected status code: 500"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isOffline(tt.err); got != tt.want {
+				t.Errorf("isOffline(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// offlineManager returns a manager whose probes fail as if offline until
+// online is set, and whose reachability check follows the same flag.
+func offlineManager(t *testing.T, online *atomic.Bool) (*LifecycleManager, chan string, func() []interface{}) {
+	t.Helper()
+	origProbe, origReachable, origInterval := probeServer, reachable, offlineCheckInterval
+	t.Cleanup(func() { probeServer, reachable, offlineCheckInterval = origProbe, origReachable, origInterval })
+	probeServer = func(_ context.Context, rawurl, filename string, _ map[string]string, _ *types.RuntimeConfig) (*ProbeResult, error) {
+		if !online.Load() {
+			return nil, offlineProbeError(rawurl)
+		}
+		return &ProbeResult{FileSize: 10, SupportsRange: true, Filename: filename}, nil
+	}
+	reachable = func(string, string) bool { return online.Load() }
+	offlineCheckInterval = 10 * time.Millisecond
+
+	dispatched := make(chan string, 1)
+	mgr := newLifecycleManagerForTest()
+	mgr.addFunc = func(string, string, string, []string, map[string]string, bool, int64, bool, types.ProbeHandoff) (string, error) {
+		t.Error("addFunc called for a download waiting for the network")
+		return "", nil
+	}
+	mgr.addWithIDFunc = func(_, _, _ string, _ []string, _ map[string]string, id string, _ int64, _ bool, _ types.ProbeHandoff) (string, error) {
+		dispatched <- id
+		return id, nil
+	}
+	var mu sync.Mutex
+	var published []interface{}
+	mgr.SetEngineHooks(EngineHooks{PublishEvent: func(msg interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+		published = append(published, msg)
+		return nil
+	}})
+	return mgr, dispatched, func() []interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]interface{}(nil), published...)
+	}
+}
+
+func TestLifecycleManager_EnqueueWaitsForNetwork(t *testing.T) {
+	var online atomic.Bool
+	mgr, dispatched, published := offlineManager(t, &online)
+	dir := t.TempDir()
+
+	id, filename, err := mgr.Enqueue(context.Background(), &DownloadRequest{URL: "https://example.com/files/data.bin", Path: dir})
+	if err != nil {
+		t.Fatalf("Enqueue while offline: %v", err)
+	}
+	if id == "" || filename != "data.bin" {
+		t.Fatalf("Enqueue = (%q, %q), want an id and data.bin", id, filename)
+	}
+	waiting := mgr.Waiting()
+	if len(waiting) != 1 || waiting[0].ID != id || waiting[0].Status != "queued" || waiting[0].Phase != types.PhaseWaitingForNetwork {
+		t.Fatalf("Waiting() = %+v, want %s queued and waiting for network", waiting, id)
+	}
+	if msgs := published(); len(msgs) != 1 || !msgs[0].(events.DownloadQueuedMsg).WaitingForNetwork {
+		t.Fatalf("published %+v, want one queued message waiting for network", msgs)
+	}
+	if _, err := os.Stat(dir + "/data.bin" + types.IncompleteSuffix); !os.IsNotExist(err) {
+		t.Fatalf("working file reserved while offline: %v", err)
+	}
+
+	online.Store(true)
+	select {
+	case got := <-dispatched:
+		if got != id {
+			t.Fatalf("dispatched %q, want the id handed out while offline %q", got, id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("download not started once the network was back")
+	}
+	if waiting := mgr.Waiting(); len(waiting) != 0 {
+		t.Fatalf("Waiting() = %+v after the network came back, want none", waiting)
+	}
+	msgs := published()
+	if last, ok := msgs[len(msgs)-1].(events.DownloadQueuedMsg); !ok || last.DownloadID != id || last.WaitingForNetwork {
+		t.Fatalf("last event = %+v, want %s queued for real", msgs[len(msgs)-1], id)
+	}
+}
+
+func TestLifecycleManager_CancelWaitingDownload(t *testing.T) {
+	var online atomic.Bool
+	mgr, _, published := offlineManager(t, &online)
+
+	id, _, err := mgr.EnqueueWithID(context.Background(), &DownloadRequest{URL: "https://example.com/a.iso", Path: t.TempDir()}, "waiting-id")
+	if err != nil || id != "waiting-id" {
+		t.Fatalf("EnqueueWithID = (%q, %v), want waiting-id", id, err)
+	}
+	if err := mgr.Cancel(id); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if waiting := mgr.Waiting(); len(waiting) != 0 {
+		t.Fatalf("Waiting() = %+v after cancel, want none", waiting)
+	}
+	msgs := published()
+	if removed, ok := msgs[len(msgs)-1].(events.DownloadRemovedMsg); !ok || removed.DownloadID != id || removed.Filename != "a.iso" {
+		t.Fatalf("last event = %+v, want a.iso removed", msgs[len(msgs)-1])
+	}
+}
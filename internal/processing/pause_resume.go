@@ -31,6 +31,8 @@ type EngineHooks struct {
 	UpdateURL func(id, newURL string) error
 	// PublishEvent sends an event into the service's broadcast channel.
 	PublishEvent func(msg interface{}) error
+	// TakeTraffic returns the bytes downloaded since it was last called.
+	TakeTraffic func() int64
 }
 
 // Pause pauses an active download.
@@ -124,6 +126,11 @@ func (mgr *LifecycleManager) Resume(id string) error {
 	if entry.Status == "completed" {
 		return types.ErrCompleted
 	}
+	// The request body is not saved, and sending the URL a GET instead
+	// would download something else.
+	if entry.Method != "" {
+		return types.ErrNotResumable
+	}
 
 	settings := mgr.GetSettings()
 
@@ -241,6 +248,14 @@ func (mgr *LifecycleManager) ResumeBatch(ids []string) []error {
 func (mgr *LifecycleManager) Cancel(id string) error {
 	hooks := mgr.getEngineHooks()
 
+	// A download waiting for the network has nothing in the pool or on disk yet
+	if w, ok := mgr.removeWaiting(id); ok {
+		if hooks.PublishEvent != nil {
+			_ = hooks.PublishEvent(events.DownloadRemovedMsg{DownloadID: id, Filename: w.filename})
+		}
+		return nil
+	}
+
 	var filename, destPath string
 	var completed bool
 	var found bool
@@ -369,6 +384,18 @@ func buildResumeConfig(id, outputPath string, entry *types.DownloadEntry, savedS
 	}
 	dmState.SetRateLimit(rateLimit, rateLimitSet)
 
+	var headers map[string]string
+	var request types.RequestOptions
+	var tlsOpts types.TLSOptions
+	if savedState != nil {
+		headers = savedState.Headers
+		tlsOpts = savedState.TLS
+		request.Checksum = savedState.Checksum
+		request.Copies = savedState.Copies
+		request.SignatureURL = savedState.SignatureURL
+		request.Interface = savedState.Interface
+	}
+
 	return types.DownloadConfig{
 		URL:           url,
 		OutputPath:    outputPath,
@@ -384,5 +411,8 @@ func buildResumeConfig(id, outputPath string, entry *types.DownloadEntry, savedS
 		Mirrors:       mirrorURLs,
 		RateLimitBps:  rateLimit,
 		RateLimitSet:  rateLimitSet,
+		Headers:       headers,
+		Request:       request,
+		TLS:           tlsOpts,
 	}
 }
@@ -0,0 +1,29 @@
+package processing
+
+import (
+	"testing"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func TestBuildResumeConfig_RestoresHeadersAndChecksum(t *testing.T) {
+	saved := &types.DownloadState{
+		ID:        "id-1",
+		URL:       "https://huggingface.co/acme/model/resolve/abc/model.safetensors",
+		DestPath:  "/tmp/model.safetensors",
+		Filename:  "model.safetensors",
+		TotalSize: 100,
+		Tasks:     []types.Task{{Offset: 50, Length: 50}},
+		Headers:   map[string]string{"Authorization": "Bearer hf_token"},
+		Checksum:  "sha256:abcd",
+	}
+
+	cfg := buildResumeConfig("id-1", "/tmp", nil, saved, config.DefaultSettings())
+	if cfg.Headers["Authorization"] != "Bearer hf_token" {
+		t.Errorf("Headers = %v, want the saved ones", cfg.Headers)
+	}
+	if cfg.Request.Checksum != "sha256:abcd" {
+		t.Errorf("Checksum = %q, want the saved one", cfg.Request.Checksum)
+	}
+}
@@ -0,0 +1,132 @@
+package processing
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine"
+	"github.com/SurgeDM/Surge/internal/engine/concurrent"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+// DownloadPlan is what adding a download would do, worked out without
+// adding it.
+type DownloadPlan struct {
+	URL           string   `json:"url"`
+	FinalURL      string   `json:"final_url,omitempty"`
+	Redirects     []string `json:"redirects,omitempty"`
+	Filename      string   `json:"filename"`
+	DestPath      string   `json:"dest_path"`
+	Size          int64    `json:"size"` // 0 when the server did not say
+	SupportsRange bool     `json:"supports_range"`
+	ContentType   string   `json:"content_type,omitempty"`
+	Mirrors       []string `json:"mirrors,omitempty"`
+	// Connections and Chunks are what the download would start with; the
+	// engine splits chunks further as connections free up.
+	Connections int   `json:"connections"`
+	Chunks      int   `json:"chunks"`
+	ChunkSize   int64 `json:"chunk_size,omitempty"`
+	// NeedsConfirmation is set when the download is over the size that asks
+	// for confirmation first.
+	NeedsConfirmation bool `json:"needs_confirmation,omitempty"`
+	// UnexpectedHTML is set when the server answers with a web page where a
+	// file was expected, which pauses the download.
+	UnexpectedHTML bool `json:"unexpected_html,omitempty"`
+	// ProbeError is why the probe failed when the download would still be
+	// tried without knowing its size.
+	ProbeError string `json:"probe_error,omitempty"`
+}
+
+// Plan probes req's server and works out where the download would be saved
+// and how it would be split, without reserving a file or adding it.
+func (mgr *LifecycleManager) Plan(ctx context.Context, req *DownloadRequest) (*DownloadPlan, error) {
+	if req.URL == "" {
+		return nil, types.ErrURLRequired
+	}
+	if req.Path == "" {
+		return nil, types.ErrDestRequired
+	}
+
+	settings := mgr.GetSettings()
+
+	if mgr.probeSem != nil {
+		select {
+		case <-mgr.probeSem:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("plan aborted before probe: %w", ctx.Err())
+		}
+		defer func() { mgr.probeSem <- struct{}{} }()
+	}
+
+	probe, runCfg, probeErr, err := mgr.probeRequest(ctx, req, settings)
+	if err != nil {
+		return nil, err
+	}
+	if err := mgr.applyScriptHooks(req, probe); err != nil {
+		return nil, err
+	}
+
+	destPath, filename, err := ResolveDestination(
+		req.URL,
+		req.Filename,
+		req.Path,
+		!req.IsExplicitCategory,
+		settings,
+		probe,
+		mgr.buildIsNameActive(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve destination: %w", err)
+	}
+
+	plan := &DownloadPlan{
+		URL:           req.URL,
+		FinalURL:      probe.FinalURL,
+		Redirects:     probe.Redirects,
+		Filename:      filename,
+		DestPath:      filepath.Join(destPath, filename),
+		Size:          probe.FileSize,
+		SupportsRange: probe.SupportsRange,
+		ContentType:   probe.ContentType,
+		Mirrors:       req.Mirrors,
+	}
+	if probeErr != nil {
+		plan.ProbeError = probeErr.Error()
+	}
+	if threshold := config.Resolve[int64](settings.General.ConfirmSizeThreshold); threshold > 0 && probe.FileSize > threshold && !req.Request.Confirmed {
+		plan.NeedsConfirmation = true
+	}
+	plan.UnexpectedHTML = !req.Request.AllowHTML && engine.UnexpectedHTML(req.URL, probe.ContentType)
+	plan.Connections, plan.Chunks, plan.ChunkSize = planChunks(runCfg, req.Request, probe)
+	return plan, nil
+}
+
+// planChunks mirrors the download manager's choice of downloader: small
+// files, servers without ranges and POSTs take one connection, anything else
+// is cut up the way the concurrent downloader starts it.
+func planChunks(runCfg *types.RuntimeConfig, opts types.RequestOptions, probe *ProbeResult) (int, int, int64) {
+	size := probe.FileSize
+	if size <= 0 {
+		return 1, 1, 0
+	}
+	fetchedByProbe := int64(len(probe.Head)) >= size
+	smallFile := size < runCfg.GetSmallFileThreshold()
+	if !probe.SupportsRange || !opts.IsGet() || fetchedByProbe || smallFile {
+		return 1, 1, size
+	}
+
+	if n := opts.Connections; n > 0 && n < runCfg.GetMaxConnectionsPerDownload() {
+		runtime := *runCfg
+		runtime.MaxConnectionsPerDownload = n
+		runCfg = &runtime
+	}
+	conns := concurrent.InitialConnections(runCfg, size)
+	chunkSize := concurrent.ChunkSize(runCfg, size, conns)
+	if partSize := probe.S3.PartSize; partSize > 0 {
+		// Matches the concurrent downloader's alignment to S3 parts
+		chunkSize = max((chunkSize+partSize/2)/partSize, 1) * partSize
+	}
+	return conns, int((size + chunkSize - 1) / chunkSize), chunkSize
+}
@@ -0,0 +1,69 @@
+package processing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func TestLifecycleManager_Plan_ReportsWithoutWriting(t *testing.T) {
+	server := newProbeTestServer(t, 100*types.MB)
+	defer server.Close()
+	tempDir := t.TempDir()
+
+	mgr := newLifecycleManagerForTest()
+	mgr.settings.General.ConfirmSizeThreshold.Value = int64(50 * types.MB)
+	mgr.addFunc = func(_, _, _ string, _ []string, _ map[string]string, _ bool, _ int64, _ bool, _ types.ProbeHandoff) (string, error) {
+		t.Fatal("a dry run should not dispatch the download")
+		return "", nil
+	}
+
+	req := &DownloadRequest{URL: server.URL, Filename: "disk.img", Path: tempDir}
+	plan, err := mgr.Plan(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if plan.DestPath != filepath.Join(tempDir, "disk.img") || plan.Size != 100*types.MB || !plan.SupportsRange {
+		t.Fatalf("plan = %+v, want disk.img in the temp dir, 100MB with ranges", plan)
+	}
+	if plan.Connections != 10 || plan.Chunks != 10 || plan.ChunkSize != 10*types.MB {
+		t.Fatalf("chunks = %d connections, %d x %d; want 10 connections, 10 x 10MB", plan.Connections, plan.Chunks, plan.ChunkSize)
+	}
+	if !plan.NeedsConfirmation {
+		t.Fatal("expected a download over confirm_size_threshold to need confirmation")
+	}
+
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("dry run wrote %d files, want none", len(entries))
+	}
+
+	req.Request.Connections = 4
+	plan, err = mgr.Plan(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Plan with --connections failed: %v", err)
+	}
+	if plan.Connections != 4 || plan.Chunks != 4 {
+		t.Fatalf("chunks = %d connections, %d chunks; want 4 and 4", plan.Connections, plan.Chunks)
+	}
+}
+
+func TestLifecycleManager_Plan_SmallFileTakesOneConnection(t *testing.T) {
+	server := newProbeTestServer(t, 4*types.KB)
+	defer server.Close()
+
+	mgr := newLifecycleManagerForTest()
+	plan, err := mgr.Plan(context.Background(), &DownloadRequest{URL: server.URL, Filename: "notes.txt", Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if plan.Connections != 1 || plan.Chunks != 1 || plan.ChunkSize != 4*types.KB {
+		t.Fatalf("plan = %d connections, %d x %d; want one 4KB chunk", plan.Connections, plan.Chunks, plan.ChunkSize)
+	}
+}
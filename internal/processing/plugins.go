@@ -0,0 +1,156 @@
+package processing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// pluginTimeout bounds how long one plugin may hold up a new download.
+const pluginTimeout = 10 * time.Second
+
+var pluginsDir = config.GetPluginsDir
+
+// pluginRequest is the JSON a URL plugin reads on stdin.
+type pluginRequest struct {
+	URL      string            `json:"url"`
+	Filename string            `json:"filename,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+}
+
+// pluginResponse is the JSON a URL plugin may write to stdout. Empty fields
+// leave the download as it was, so a plugin that does not handle a URL
+// writes nothing at all.
+type pluginResponse struct {
+	URL      string            `json:"url"`
+	Filename string            `json:"filename"`
+	Headers  map[string]string `json:"headers"`
+	// Veto refuses the download, giving the reason.
+	Veto string `json:"veto"`
+}
+
+// applyURLPlugins passes req through each executable in the plugins
+// directory in name order, before it is probed. A plugin can point the
+// download at another URL, as a resolver for a file host does, add headers
+// such as a session cookie, name the file, or refuse the download. A plugin
+// that fails or answers with something other than JSON fails the download
+// rather than letting it go ahead unresolved.
+func applyURLPlugins(ctx context.Context, req *DownloadRequest) error {
+	plugins, err := listPlugins(pluginsDir())
+	if err != nil {
+		return err
+	}
+	for _, plugin := range plugins {
+		resp, err := runPlugin(ctx, plugin, pluginRequest{URL: req.URL, Filename: req.Filename, Headers: req.Headers})
+		name := filepath.Base(plugin)
+		if err != nil {
+			return fmt.Errorf("plugin %s failed: %w", name, err)
+		}
+		if resp.Veto != "" {
+			return fmt.Errorf("%w: %s: %s", types.ErrPluginVeto, name, resp.Veto)
+		}
+		if resp.URL != "" && resp.URL != req.URL {
+			utils.Debug("Plugin %s: %s -> %s", name, req.URL, resp.URL)
+			req.URL = resp.URL
+		}
+		if resp.Filename != "" {
+			req.Filename = resp.Filename
+		}
+		if len(resp.Headers) > 0 {
+			// The map can be shared with other requests of the same batch
+			headers := maps.Clone(req.Headers)
+			if headers == nil {
+				headers = make(map[string]string)
+			}
+			for key, value := range resp.Headers {
+				// An empty value drops the header
+				if value == "" {
+					delete(headers, key)
+				} else {
+					headers[key] = value
+				}
+			}
+			req.Headers = headers
+		}
+	}
+	return nil
+}
+
+// listPlugins returns the executables in dir, sorted by name. A missing
+// directory has none.
+func listPlugins(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugins: %w", err)
+	}
+	var plugins []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !isExecutable(name, info.Mode()) {
+			continue
+		}
+		plugins = append(plugins, filepath.Join(dir, name))
+	}
+	sort.Strings(plugins)
+	return plugins, nil
+}
+
+func isExecutable(name string, mode os.FileMode) bool {
+	if runtime.GOOS == "windows" {
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".exe", ".bat", ".cmd", ".com":
+			return true
+		}
+		return false
+	}
+	return mode.IsRegular() && mode&0o111 != 0
+}
+
+func runPlugin(ctx context.Context, path string, req pluginRequest) (pluginResponse, error) {
+	var resp pluginResponse
+	input, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, pluginTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return resp, fmt.Errorf("%w: %s", err, msg)
+		}
+		return resp, err
+	}
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return resp, nil
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return resp, fmt.Errorf("invalid response: %w", err)
+	}
+	return resp, nil
+}
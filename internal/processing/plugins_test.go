@@ -0,0 +1,90 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func writePlugin(t *testing.T, dir, name, script string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func usePluginsDir(t *testing.T, dir string) {
+	orig := pluginsDir
+	pluginsDir = func() string { return dir }
+	t.Cleanup(func() { pluginsDir = orig })
+}
+
+func TestApplyURLPlugins_RewritesInNameOrderAndVetoes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts here")
+	}
+	server := newProbeTestServer(t, types.MB)
+	defer server.Close()
+
+	dir := t.TempDir()
+	usePluginsDir(t, dir)
+
+	writePlugin(t, dir, "10-resolve", `grep -q 'host.example/page' && printf '{"url":"`+server.URL+`/file.bin","headers":{"Cookie":"s=1","X-Drop":""}}'
+exit 0
+`)
+	writePlugin(t, dir, "20-block", `grep -q 'blocked.example' && printf '{"veto":"host is blocked"}'
+exit 0
+`)
+	// Not executable, so not a plugin
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("notes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	req := &DownloadRequest{URL: "https://host.example/page/123", Headers: map[string]string{"X-Drop": "1"}}
+	if err := applyURLPlugins(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if req.URL != server.URL+"/file.bin" || req.Headers["Cookie"] != "s=1" {
+		t.Fatalf("req = %+v, want the resolved URL and cookie", req)
+	}
+	if _, ok := req.Headers["X-Drop"]; ok {
+		t.Fatal("expected an empty header value to drop the header")
+	}
+
+	req = &DownloadRequest{URL: "https://blocked.example/a.iso"}
+	if err := applyURLPlugins(context.Background(), req); !errors.Is(err, types.ErrPluginVeto) {
+		t.Fatalf("err = %v, want a veto", err)
+	}
+
+	writePlugin(t, dir, "30-broken", "echo oops >&2; exit 3\n")
+	req = &DownloadRequest{URL: "https://other.example/a.iso"}
+	if err := applyURLPlugins(context.Background(), req); err == nil {
+		t.Fatal("expected a failing plugin to fail the download")
+	}
+}
+
+func TestLifecycleManager_Plan_UsesThePluginURL(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts here")
+	}
+	server := newProbeTestServer(t, types.MB)
+	defer server.Close()
+
+	dir := t.TempDir()
+	usePluginsDir(t, dir)
+	writePlugin(t, dir, "resolve", `printf '{"url":"`+server.URL+`","filename":"resolved.bin"}'`+"\n")
+
+	mgr := newLifecycleManagerForTest()
+	plan, err := mgr.Plan(context.Background(), &DownloadRequest{URL: "https://host.example/page", Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if plan.URL != server.URL || filepath.Base(plan.DestPath) != "resolved.bin" || plan.Size != types.MB {
+		t.Fatalf("plan = %+v, want the resolved URL and name", plan)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	neturl "net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -36,10 +37,66 @@ type ProbeResult struct {
 	Filename         string
 	DetectedFilename string
 	ContentType      string
+	// Head holds the first bytes of the file when the probe doubled as the
+	// first data request (early ramp). It is nil when the probe only asked
+	// for a single byte or the body could not be read completely.
+	Head []byte
+	// FinalURL is the URL the probe ended up at after following redirects.
+	// It is empty when the server did not redirect.
+	FinalURL string
+	// S3 holds the multipart layout and checksum of an object served by S3,
+	// or the zero value for other servers.
+	S3 types.S3Object
+	// LastModified is the server's Last-Modified time, or the zero time when
+	// it sent none.
+	LastModified time.Time
+	// ResponseHeaders holds the notable headers of the probe's response,
+	// kept to help tell why a server misbehaves.
+	ResponseHeaders map[string]string
+	// Redirects are the URLs the probe was redirected through, in order,
+	// ending with FinalURL; nil when it was not redirected.
+	Redirects []string
 }
 
-// probeHeadersContextKey is used to pass custom headers to the HTTP client's CheckRedirect function
-type probeHeadersContextKey struct{}
+// notableResponseHeaders are the response headers a probe keeps.
+var notableResponseHeaders = []string{
+	"Content-Type",
+	"Content-Disposition",
+	"Content-Encoding",
+	"Accept-Ranges",
+	"Server",
+	"Cache-Control",
+	"ETag",
+	"Last-Modified",
+	"Expires",
+	"Age",
+}
+
+// responseHeaders picks the notable headers out of h, or returns nil when
+// it has none of them.
+func responseHeaders(h http.Header) map[string]string {
+	var out map[string]string
+	for _, key := range notableResponseHeaders {
+		if v := h.Get(key); v != "" {
+			if out == nil {
+				out = make(map[string]string)
+			}
+			out[key] = v
+		}
+	}
+	return out
+}
+
+// redirectChain lists the URLs resp was redirected through after the one
+// first requested, oldest first.
+func redirectChain(resp *http.Response) []string {
+	var chain []string
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		chain = append(chain, req.URL.String())
+	}
+	slices.Reverse(chain)
+	return chain
+}
 
 func resolveRuntimeConfig() *types.RuntimeConfig {
 	settings, err := config.LoadSettings()
@@ -75,45 +132,33 @@ func getProbeHostLock(rawurl string) *sync.Mutex {
 // the effective proxy and want probe traffic to match the eventual download path
 // without re-reading settings from disk.
 func ProbeServerWithProxy(ctx context.Context, rawurl string, filenameHint string, headers map[string]string, runCfg *types.RuntimeConfig) (*ProbeResult, error) {
-	utils.Debug("Probing server: %s", rawurl)
-
-	// Embed custom headers in context so CheckRedirect can use them
-	if headers != nil {
-		ctx = context.WithValue(ctx, probeHeadersContextKey{}, headers)
+	cacheKey := probeCacheKey(rawurl, headers, runCfg)
+	if cached := probeCache.get(cacheKey, filenameHint); cached != nil {
+		utils.Debug("Probe cache hit: %s", rawurl)
+		return cached, nil
 	}
+	utils.Debug("Probing server: %s", rawurl)
 
 	var resp *http.Response
 
-	var proxyURL, customDNS string
-	if runCfg != nil {
-		proxyURL = runCfg.ProxyURL
-		customDNS = runCfg.CustomDNS
+	// With early ramp the probe asks for the first window of the file instead
+	// of a single byte, so small files finish without a second round trip.
+	rampSize := int64(1)
+	if runCfg.GetEarlyRamp() {
+		rampSize = types.EarlyRampSize
 	}
 
 	// Standardize on PoolMaxConnsPerHost for probes to match the eventual download path
-	transport := engine.DefaultNetworkPool.AcquireTransport(proxyURL, customDNS, types.PoolMaxConnsPerHost)
+	transport, err := engine.DefaultNetworkPool.AcquireTransportFor(runCfg, types.PoolMaxConnsPerHost)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrProbeRequestCreation, err)
+	}
 	defer engine.DefaultNetworkPool.ReleaseTransport(transport)
 
 	client := &http.Client{
 		Transport: transport,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			if len(via) >= 10 {
-				return fmt.Errorf("stopped after 10 redirects")
-			}
-			if len(via) > 0 {
-				copyProbeRedirectHeaders(req, via[0])
-			}
-
-			// Re-apply custom explicitly provided headers on cross-origin redirects
-			if customHeaders, ok := req.Context().Value(probeHeadersContextKey{}).(map[string]string); ok {
-				for k, v := range customHeaders {
-					if !strings.EqualFold(k, "Range") {
-						req.Header.Set(k, v)
-					}
-				}
-			}
-			return nil
-		},
+		// Probe with the same redirect rules the download will use
+		CheckRedirect: engine.RedirectPolicy(runCfg, headers),
 	}
 
 	// Sequentialize probes to the same host to prevent rate limiting (e.g., Google Drive)
@@ -121,8 +166,16 @@ func ProbeServerWithProxy(ctx context.Context, rawurl string, filenameHint strin
 	hostLock.Lock()
 	defer hostLock.Unlock()
 
-	var err error
+	// The probe this one queued behind may have been for the same URL
+	if cached := probeCache.get(cacheKey, filenameHint); cached != nil {
+		utils.Debug("Probe cache hit after waiting: %s", rawurl)
+		return cached, nil
+	}
+
 	var finalCancel context.CancelFunc
+	retryDelay := 1 * time.Second
+	// A download given longer timeouts for a slow server probes with them too
+	probeTimeout := max(types.ProbeTimeout, runCfg.GetConnectTimeout()+runCfg.GetResponseHeaderTimeout())
 
 	for attempt := range 3 {
 		if ctx.Err() != nil {
@@ -136,7 +189,7 @@ func ProbeServerWithProxy(ctx context.Context, rawurl string, filenameHint strin
 			select {
 			case <-ctx.Done():
 				err = fmt.Errorf("probe request aborted during retry: %w", ctx.Err())
-			case <-time.After(1 * time.Second):
+			case <-time.After(retryDelay):
 			}
 			if ctx.Err() != nil {
 				break
@@ -144,9 +197,9 @@ func ProbeServerWithProxy(ctx context.Context, rawurl string, filenameHint strin
 			utils.Debug("Retrying probe... attempt %d", attempt+1)
 		}
 
-		probeCtx, cancel := context.WithTimeout(ctx, types.ProbeTimeout)
+		probeCtx, cancel := context.WithTimeout(ctx, probeTimeout)
 
-		req, reqErr := newProbeRequest(probeCtx, rawurl, headers, true)
+		req, reqErr := newProbeRequest(probeCtx, rawurl, headers, rampSize)
 		if reqErr != nil {
 			cancel()
 			err = fmt.Errorf("%w: %w", ErrProbeRequestCreation, reqErr)
@@ -161,7 +214,7 @@ func ProbeServerWithProxy(ctx context.Context, rawurl string, filenameHint strin
 			utils.Debug("Probe got %d, retrying without Range header", resp.StatusCode)
 			_ = resp.Body.Close() // Close previous response
 
-			reqNoRange, reqNoRangeErr := newProbeRequest(probeCtx, rawurl, headers, false)
+			reqNoRange, reqNoRangeErr := newProbeRequest(probeCtx, rawurl, headers, 0)
 			if reqNoRangeErr != nil {
 				cancel()
 				err = fmt.Errorf("%w without range: %w", ErrProbeRequestCreation, reqNoRangeErr)
@@ -172,11 +225,27 @@ func ProbeServerWithProxy(ctx context.Context, rawurl string, filenameHint strin
 		}
 
 		if err == nil {
-			finalCancel = cancel
-			break
+			throttle := engine.CheckThrottle(resp)
+			if throttle == nil {
+				finalCancel = cancel
+				break
+			}
+			err = throttle
+			if throttle.Wait > probeTimeout {
+				// Too long to hold up the queue; the download waits it out instead
+				cancel()
+				break
+			}
+			retryDelay = max(throttle.Wait, 1*time.Second)
 		}
 
 		cancel()
+
+		// Redirect policy violations and pin mismatches will not change on retry
+		if errors.Is(err, types.ErrCrossHostRedirect) || errors.Is(err, types.ErrMaxRedirects) ||
+			errors.Is(err, types.ErrCertificatePin) {
+			break
+		}
 	}
 
 	if err != nil {
@@ -205,13 +274,8 @@ func ProbeServerWithProxy(ctx context.Context, rawurl string, filenameHint strin
 		result.SupportsRange = true
 		contentRange := resp.Header.Get("Content-Range")
 		utils.Debug("Content-Range header: %s", contentRange)
-		if contentRange != "" {
-			if idx := strings.LastIndex(contentRange, "/"); idx != -1 {
-				sizeStr := contentRange[idx+1:]
-				if sizeStr != "*" {
-					result.FileSize, _ = strconv.ParseInt(sizeStr, 10, 64)
-				}
-			}
+		if cr, ok := utils.ParseContentRange(contentRange); ok && cr.Total > 0 {
+			result.FileSize = cr.Total
 		}
 		utils.Debug("Range supported, file size: %d", result.FileSize)
 
@@ -224,10 +288,10 @@ func ProbeServerWithProxy(ctx context.Context, rawurl string, filenameHint strin
 		utils.Debug("Range NOT supported (got 200), file size: %d", result.FileSize)
 
 	default:
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, &types.HTTPStatusError{StatusCode: resp.StatusCode}
 	}
 
-	name, _, err := utils.DetermineFilename(rawurl, resp)
+	name, body, err := utils.DetermineFilename(rawurl, resp)
 	if err != nil {
 		utils.Debug("Error determining filename: %v", err)
 		name = "download.bin"
@@ -242,23 +306,112 @@ func ProbeServerWithProxy(ctx context.Context, rawurl string, filenameHint strin
 	}
 
 	result.ContentType = resp.Header.Get("Content-Type")
+	s3Host := ""
+	if resp.Request != nil && resp.Request.URL != nil {
+		if final := resp.Request.URL.String(); final != rawurl {
+			result.FinalURL = final
+		}
+		s3Host = resp.Request.URL.Host
+	}
+	result.S3 = types.ParseS3Object(s3Host, resp.Header, result.FileSize)
+	if result.S3.Checksum.IsZero() && types.IsS3Response(s3Host, resp.Header) {
+		// S3 only sends the whole-object checksum when asked for it, and
+		// never with a range
+		finalURL := rawurl
+		if result.FinalURL != "" {
+			finalURL = result.FinalURL
+		}
+		headCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+		result.S3.Checksum = headS3Checksum(headCtx, client, finalURL, headers)
+		cancel()
+		if _, parts := result.S3.Checksum.Composite(); parts > 0 && result.S3.PartSize == 0 {
+			result.S3.PartSize = types.MultipartPartSize(result.FileSize, parts)
+		}
+	}
+	result.LastModified = engine.LastModified(resp)
+	result.ResponseHeaders = responseHeaders(resp.Header)
+	result.Redirects = redirectChain(resp)
+	if !result.S3.IsZero() {
+		utils.Debug("S3 object: part size %d, checksum %s", result.S3.PartSize, result.S3.Checksum)
+	}
+
+	// DetermineFilename sniffs the first bytes, so read the head from the
+	// reader it hands back rather than the raw body.
+	if rampSize > 1 && body != nil {
+		result.Head = readProbeHead(resp, body, result.FileSize, rampSize)
+	}
 
 	utils.Debug("Probe complete - filename: %s, size: %d, range: %v",
 		result.Filename, result.FileSize, result.SupportsRange)
 
+	probeCache.put(cacheKey, result)
 	return result, nil
 }
 
-func newProbeRequest(ctx context.Context, rawurl string, headers map[string]string, includeRange bool) (*http.Request, error) {
+// readProbeHead reads the leading bytes of an early-ramp probe response. It
+// only returns data when the file size is known and the body delivered every
+// byte it promised, so callers can trust the slice as a verified prefix.
+func readProbeHead(resp *http.Response, body io.Reader, fileSize, rampSize int64) []byte {
+	if fileSize <= 0 {
+		return nil
+	}
+	want := rampSize
+	if fileSize < want {
+		want = fileSize
+	}
+	if resp.StatusCode == http.StatusPartialContent {
+		cr, ok := utils.ParseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || cr.Start != 0 {
+			return nil
+		}
+		if cr.End+1 < want {
+			want = cr.End + 1
+		}
+	}
+
+	head := make([]byte, want)
+	if _, err := io.ReadFull(body, head); err != nil {
+		utils.Debug("Early ramp: short probe body: %v", err)
+		return nil
+	}
+	return head
+}
+
+// newProbeRequest builds the probe GET. rangeSize is the number of leading
+// bytes to ask for; zero omits the Range header entirely.
+// headS3Checksum asks S3 for an object's checksum with a HEAD that has no
+// Range, which is the only request S3 sends a whole-object checksum for.
+// Any failure, such as a presigned URL only signed for GET, returns none.
+func headS3Checksum(ctx context.Context, client *http.Client, rawurl string, headers map[string]string) types.S3Checksum {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, rawurl, nil)
+	if err != nil {
+		return types.S3Checksum{}
+	}
+	applyProbeHeaders(req, headers, 0)
+	req.Header.Set("X-Amz-Checksum-Mode", "ENABLED")
+	resp, err := client.Do(req)
+	if err != nil {
+		utils.Debug("S3 checksum HEAD failed: %v", err)
+		return types.S3Checksum{}
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		utils.Debug("S3 checksum HEAD got %d", resp.StatusCode)
+		return types.S3Checksum{}
+	}
+	return types.ParseS3Object(req.URL.Host, resp.Header, resp.ContentLength).Checksum
+}
+
+func newProbeRequest(ctx context.Context, rawurl string, headers map[string]string, rangeSize int64) (*http.Request, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawurl, nil)
 	if err != nil {
 		return nil, err
 	}
-	applyProbeHeaders(req, headers, includeRange)
+	applyProbeHeaders(req, headers, rangeSize)
 	return req, nil
 }
 
-func applyProbeHeaders(req *http.Request, headers map[string]string, includeRange bool) {
+func applyProbeHeaders(req *http.Request, headers map[string]string, rangeSize int64) {
 	if req == nil {
 		return
 	}
@@ -270,44 +423,14 @@ func applyProbeHeaders(req *http.Request, headers map[string]string, includeRang
 		req.Header.Set(key, val)
 	}
 
-	if includeRange {
-		req.Header.Set("Range", "bytes=0-0")
+	if rangeSize > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", rangeSize-1))
 	}
 	if req.Header.Get("User-Agent") == "" {
 		req.Header.Set("User-Agent", ua)
 	}
 }
 
-func copyProbeRedirectHeaders(dst, src *http.Request) {
-	if dst == nil || src == nil {
-		return
-	}
-
-	if sameProbeRedirectOrigin(dst.URL, src.URL) {
-		for key, vals := range src.Header {
-			dst.Header[key] = append([]string(nil), vals...)
-		}
-		return
-	}
-
-	for key := range dst.Header {
-		delete(dst.Header, key)
-	}
-
-	for _, key := range []string{"Range", "User-Agent"} {
-		if vals := src.Header.Values(key); len(vals) > 0 {
-			dst.Header[key] = append([]string(nil), vals...)
-		}
-	}
-}
-
-func sameProbeRedirectOrigin(a, b *neturl.URL) bool {
-	if a == nil || b == nil {
-		return false
-	}
-	return strings.EqualFold(a.Scheme, b.Scheme) && strings.EqualFold(a.Host, b.Host)
-}
-
 // ProbeMirrors is the convenience wrapper for callers that need mirror probing
 // to honor the saved proxy setting but do not already hold a live settings snapshot.
 func ProbeMirrors(ctx context.Context, mirrors []string) (valid []string, errs map[string]error) {
@@ -0,0 +1,127 @@
+package processing
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+var (
+	// probeCacheTTL is how long a successful probe answers for its URL.
+	// Zero turns the cache off.
+	probeCacheTTL = 30 * time.Second
+	// probeCacheMaxEntries bounds the cache; expired entries go first, then
+	// arbitrary ones.
+	probeCacheMaxEntries = 1024
+)
+
+// probeCache remembers recent probe results, so adding the same URL again,
+// or many downloads whose mirrors share a path, does not send the server a
+// fresh probe for each one.
+var probeCache = &probeResultCache{entries: make(map[string]probeCacheEntry)}
+
+type probeResultCache struct {
+	mu      sync.Mutex
+	entries map[string]probeCacheEntry
+}
+
+type probeCacheEntry struct {
+	result  ProbeResult
+	expires time.Time
+}
+
+// probeCacheKey identifies a probe by its URL and the headers sent with it,
+// since cookies or credentials can change what the server answers, and by
+// the connection settings it ran under, so a probe that passed lenient TLS
+// or redirect checks never answers for a stricter download.
+func probeCacheKey(rawurl string, headers map[string]string, runCfg *types.RuntimeConfig) string {
+	var sb strings.Builder
+	sb.WriteString(rawurl)
+	for _, name := range slices.Sorted(maps.Keys(headers)) {
+		sb.WriteString("\x00")
+		sb.WriteString(strings.ToLower(name))
+		sb.WriteString(":")
+		sb.WriteString(headers[name])
+	}
+	if runCfg != nil {
+		fmt.Fprintf(&sb, "\x00%+v\x00%s\x00%s\x00%s\x00%d,%t,%t\x00%s,%s",
+			runCfg.TLS, runCfg.BindInterface, runCfg.ProxyURL, runCfg.UserAgent,
+			runCfg.MaxRedirects, runCfg.BlockCrossHostRedirects, runCfg.StripAuthOnRedirect,
+			runCfg.ConnectTimeout, runCfg.ResponseHeaderTimeout)
+	}
+	return sb.String()
+}
+
+// get returns a copy of the cached result for key with filenameHint applied,
+// or nil if there is none that is still fresh.
+func (c *probeResultCache) get(key, filenameHint string) *ProbeResult {
+	if probeCacheTTL <= 0 {
+		return nil
+	}
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok && !time.Now().Before(entry.expires) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	result := cloneProbeResult(&entry.result)
+	result.Filename = result.DetectedFilename
+	if filenameHint != "" {
+		result.Filename = filenameHint
+	}
+	return result
+}
+
+// put caches result for key. The head bytes of an early-ramp probe are left
+// out: a later download fetches its own, rather than trusting bytes read
+// for another.
+func (c *probeResultCache) put(key string, result *ProbeResult) {
+	if probeCacheTTL <= 0 || result == nil {
+		return
+	}
+	stored := cloneProbeResult(result)
+	stored.Head = nil
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if len(c.entries) >= probeCacheMaxEntries {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		for k := range c.entries {
+			if len(c.entries) < probeCacheMaxEntries {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = probeCacheEntry{result: *stored, expires: now.Add(probeCacheTTL)}
+}
+
+// forget drops every cached result.
+func (c *probeResultCache) forget() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
+
+func cloneProbeResult(r *ProbeResult) *ProbeResult {
+	clone := *r
+	clone.Head = slices.Clone(r.Head)
+	clone.ResponseHeaders = maps.Clone(r.ResponseHeaders)
+	clone.Redirects = slices.Clone(r.Redirects)
+	return &clone
+}
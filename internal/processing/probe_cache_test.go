@@ -0,0 +1,142 @@
+package processing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func newCountingProbeServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Range", "bytes 0-3/4")
+		w.Header().Set("Content-Disposition", `attachment; filename="data.bin"`)
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write([]byte("data"))
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(probeCache.forget)
+	return server, &hits
+}
+
+func TestProbeServer_CachesResultsByURL(t *testing.T) {
+	server, hits := newCountingProbeServer(t)
+	runCfg := &types.RuntimeConfig{EarlyRamp: true}
+
+	first, err := ProbeServerWithProxy(context.Background(), server.URL+"/a", "", nil, runCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Head) == 0 {
+		t.Fatal("early-ramp probe read no head")
+	}
+	second, err := ProbeServerWithProxy(context.Background(), server.URL+"/a", "renamed.bin", nil, runCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := hits.Load(); n != 1 {
+		t.Fatalf("server probed %d times, want once", n)
+	}
+	if second.FileSize != 4 || !second.SupportsRange || second.DetectedFilename != "data.bin" || second.Filename != "renamed.bin" {
+		t.Errorf("cached result = %+v", second)
+	}
+	if second.Head != nil {
+		t.Error("cached result kept the first probe's head bytes")
+	}
+
+	// Other headers or another path are probed afresh
+	if _, err := ProbeServerWithProxy(context.Background(), server.URL+"/a", "", map[string]string{"Cookie": "s=1"}, runCfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ProbeServerWithProxy(context.Background(), server.URL+"/b", "", nil, runCfg); err != nil {
+		t.Fatal(err)
+	}
+	if n := hits.Load(); n != 3 {
+		t.Fatalf("server probed %d times, want 3", n)
+	}
+}
+
+func TestProbeServer_ConcurrentProbesShareOneRequest(t *testing.T) {
+	server, hits := newCountingProbeServer(t)
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Go(func() {
+			if _, err := ProbeServerWithProxy(context.Background(), server.URL, "", nil, &types.RuntimeConfig{}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+	if n := hits.Load(); n != 1 {
+		t.Fatalf("server probed %d times, want once", n)
+	}
+}
+
+func TestProbeServer_CacheExpires(t *testing.T) {
+	server, hits := newCountingProbeServer(t)
+	old := probeCacheTTL
+	probeCacheTTL = 30 * time.Millisecond
+	t.Cleanup(func() { probeCacheTTL = old })
+
+	for range 2 {
+		if _, err := ProbeServerWithProxy(context.Background(), server.URL, "", nil, &types.RuntimeConfig{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	if _, err := ProbeServerWithProxy(context.Background(), server.URL, "", nil, &types.RuntimeConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := hits.Load(); n != 2 {
+		t.Fatalf("server probed %d times, want twice", n)
+	}
+}
+
+func TestProbeServer_StricterSettingsProbeAfresh(t *testing.T) {
+	server, hits := newCountingProbeServer(t)
+
+	lenient := &types.RuntimeConfig{TLS: types.TLSOptions{Insecure: true}}
+	if _, err := ProbeServerWithProxy(context.Background(), server.URL, "", nil, lenient); err != nil {
+		t.Fatal(err)
+	}
+	strict := []*types.RuntimeConfig{
+		{},
+		{BlockCrossHostRedirects: true},
+		{MaxRedirects: 1},
+		{BindInterface: "127.0.0.1"},
+	}
+	for _, runCfg := range strict {
+		if _, err := ProbeServerWithProxy(context.Background(), server.URL, "", nil, runCfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := hits.Load(); n != int32(1+len(strict)) {
+		t.Fatalf("server probed %d times, want %d", n, 1+len(strict))
+	}
+}
+
+func TestLifecycleManager_ApplySettingsForgetsProbes(t *testing.T) {
+	server, hits := newCountingProbeServer(t)
+	runCfg := &types.RuntimeConfig{}
+
+	if _, err := ProbeServerWithProxy(context.Background(), server.URL, "", nil, runCfg); err != nil {
+		t.Fatal(err)
+	}
+	newLifecycleManagerForTest().ApplySettings(nil)
+	if _, err := ProbeServerWithProxy(context.Background(), server.URL, "", nil, runCfg); err != nil {
+		t.Fatal(err)
+	}
+	if n := hits.Load(); n != 2 {
+		t.Fatalf("server probed %d times, want twice", n)
+	}
+}
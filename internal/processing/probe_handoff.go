@@ -0,0 +1,46 @@
+package processing
+
+import (
+	"fmt"
+
+	"github.com/SurgeDM/Surge/internal/engine"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// handOffProbe writes the probe's head bytes into the reserved working file and
+// returns what the engine can skip or reuse for destPath, along with the
+// request's TLS override and method.
+func handOffProbe(destPath string, probe *ProbeResult, tlsOverride types.TLSOptions, request types.RequestOptions) types.ProbeHandoff {
+	h := types.ProbeHandoff{FinalURL: probe.FinalURL, TLS: tlsOverride, Request: request, S3: probe.S3, LastModified: probe.LastModified, ContentType: probe.ContentType}
+	if err := storeEarlyBytes(destPath, probe.Head, request.Copies); err != nil {
+		// The engine simply fetches the prefix again.
+		utils.Debug("Lifecycle: %v", err)
+	} else {
+		h.EarlyBytes = int64(len(probe.Head))
+	}
+	return h
+}
+
+// storeEarlyBytes writes the probe's head bytes into the reserved working file
+// and those of its copies.
+func storeEarlyBytes(destPath string, head []byte, copies []string) error {
+	if len(head) == 0 {
+		return nil
+	}
+
+	file, err := engine.OpenWorkingFile(destPath, copies)
+	if err != nil {
+		return fmt.Errorf("failed to open working file for early bytes: %w", err)
+	}
+	if _, err := file.WriteAt(head, 0); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write early bytes: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close working file: %w", err)
+	}
+
+	utils.Debug("Early ramp: %d bytes already on disk for %s", len(head), destPath)
+	return nil
+}
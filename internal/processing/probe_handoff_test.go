@@ -0,0 +1,212 @@
+package processing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func earlyRampTestData(size int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i % 253)
+	}
+	return data
+}
+
+func TestProbeServerWithProxy_EarlyRampFetchesHead(t *testing.T) {
+	data := earlyRampTestData(4096)
+
+	var gotRange string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRange = r.Header.Get("Range")
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes 0-%d/%d", len(data)-1, len(data)))
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(data)))
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	result, err := ProbeServerWithProxy(context.Background(), server.URL+"/file.bin", "", nil, &types.RuntimeConfig{EarlyRamp: true})
+	if err != nil {
+		t.Fatalf("probe failed: %v", err)
+	}
+
+	wantRange := fmt.Sprintf("bytes=0-%d", types.EarlyRampSize-1)
+	if gotRange != wantRange {
+		t.Errorf("Range = %q, want %q", gotRange, wantRange)
+	}
+	if result.FileSize != int64(len(data)) {
+		t.Errorf("FileSize = %d, want %d", result.FileSize, len(data))
+	}
+	if !bytes.Equal(result.Head, data) {
+		t.Errorf("Head has %d bytes, want the whole %d byte file", len(result.Head), len(data))
+	}
+}
+
+func TestProbeServerWithProxy_EarlyRampFullResponse(t *testing.T) {
+	data := earlyRampTestData(2048)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(data)))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	result, err := ProbeServerWithProxy(context.Background(), server.URL+"/file.bin", "", nil, &types.RuntimeConfig{EarlyRamp: true})
+	if err != nil {
+		t.Fatalf("probe failed: %v", err)
+	}
+	if result.SupportsRange {
+		t.Error("expected no range support for a 200 response")
+	}
+	if !bytes.Equal(result.Head, data) {
+		t.Errorf("Head has %d bytes, want the whole %d byte file", len(result.Head), len(data))
+	}
+}
+
+func TestProbeServerWithProxy_NoHeadWithoutEarlyRamp(t *testing.T) {
+	server := newProbeTestServer(t, 1234)
+	defer server.Close()
+
+	result, err := ProbeServerWithProxy(context.Background(), server.URL, "", nil, &types.RuntimeConfig{})
+	if err != nil {
+		t.Fatalf("probe failed: %v", err)
+	}
+	if result.Head != nil {
+		t.Errorf("Head = %d bytes, want nil when early ramp is off", len(result.Head))
+	}
+}
+
+func TestReadProbeHead(t *testing.T) {
+	partial := func(contentRange string) *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusPartialContent,
+			Header:     http.Header{"Content-Range": []string{contentRange}},
+		}
+	}
+	data := earlyRampTestData(100)
+
+	tests := []struct {
+		name     string
+		resp     *http.Response
+		body     []byte
+		fileSize int64
+		want     int
+	}{
+		{"whole small file", partial("bytes 0-99/100"), data, 100, 100},
+		{"prefix of larger file", partial("bytes 0-49/1000"), data[:50], 1000, 50},
+		{"unknown size", partial("bytes 0-99/*"), data, 0, 0},
+		{"range not at start", partial("bytes 10-99/100"), data[10:], 100, 0},
+		{"truncated body", partial("bytes 0-99/100"), data[:40], 100, 0},
+		{"malformed range", partial("bytes garbage"), data, 100, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			head := readProbeHead(tt.resp, bytes.NewReader(tt.body), tt.fileSize, types.EarlyRampSize)
+			if len(head) != tt.want {
+				t.Fatalf("len(head) = %d, want %d", len(head), tt.want)
+			}
+			if tt.want > 0 && !bytes.Equal(head, data[:tt.want]) {
+				t.Fatal("head does not match the leading bytes")
+			}
+		})
+	}
+}
+
+func TestHandOffProbe_WritesWorkingFile(t *testing.T) {
+	destPath := filepath.Join(t.TempDir(), "file.bin")
+	if err := os.WriteFile(destPath+types.IncompleteSuffix, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	head := []byte("early bytes")
+	h := handOffProbe(destPath, &ProbeResult{Head: head, FinalURL: "https://cdn.example.com/file.bin"}, types.TLSOptions{}, types.RequestOptions{})
+
+	got, err := os.ReadFile(destPath + types.IncompleteSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, head) {
+		t.Errorf("working file = %q, want %q", got, head)
+	}
+
+	if h.EarlyBytes != int64(len(head)) {
+		t.Errorf("EarlyBytes = %d, want %d", h.EarlyBytes, len(head))
+	}
+	if h.FinalURL != "https://cdn.example.com/file.bin" {
+		t.Errorf("FinalURL = %q", h.FinalURL)
+	}
+}
+
+func TestHandOffProbe_MissingWorkingFileKeepsFinalURL(t *testing.T) {
+	destPath := filepath.Join(t.TempDir(), "missing.bin")
+	h := handOffProbe(destPath, &ProbeResult{Head: []byte("x"), FinalURL: "https://cdn.example.com/x"}, types.TLSOptions{}, types.RequestOptions{})
+
+	if h.EarlyBytes != 0 {
+		t.Errorf("EarlyBytes = %d, want 0 when the head could not be written", h.EarlyBytes)
+	}
+	if h.FinalURL != "https://cdn.example.com/x" {
+		t.Errorf("FinalURL = %q", h.FinalURL)
+	}
+}
+
+func TestHandOffProbe_NothingToHandOff(t *testing.T) {
+	destPath := filepath.Join(t.TempDir(), "plain.bin")
+	if h := handOffProbe(destPath, &ProbeResult{}, types.TLSOptions{}, types.RequestOptions{}); !reflect.DeepEqual(h, types.ProbeHandoff{}) {
+		t.Errorf("handoff = %+v, want zero value", h)
+	}
+}
+
+func TestHandOffProbe_CarriesTLSOverride(t *testing.T) {
+	destPath := filepath.Join(t.TempDir(), "internal.bin")
+	override := types.TLSOptions{Insecure: true}
+
+	if h := handOffProbe(destPath, &ProbeResult{}, override, types.RequestOptions{}); h.TLS != override {
+		t.Errorf("TLS = %+v, want %+v", h.TLS, override)
+	}
+}
+
+func TestHandOffProbe_CarriesContentType(t *testing.T) {
+	destPath := filepath.Join(t.TempDir(), "file.zip")
+
+	if h := handOffProbe(destPath, &ProbeResult{ContentType: "text/html; charset=utf-8"}, types.TLSOptions{}, types.RequestOptions{}); h.ContentType != "text/html; charset=utf-8" {
+		t.Errorf("ContentType = %q, want the probe's", h.ContentType)
+	}
+}
+
+func TestLifecycleManager_Enqueue_PassesEarlyBytesToDispatch(t *testing.T) {
+	data := earlyRampTestData(4096)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes 0-%d/%d", len(data)-1, len(data)))
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(data)))
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	mgr := newLifecycleManagerForTest()
+	mgr.settings.Network.EarlyRamp.Value = true
+	var got types.ProbeHandoff
+	mgr.addFunc = func(_, _, _ string, _ []string, _ map[string]string, _ bool, _ int64, _ bool, handoff types.ProbeHandoff) (string, error) {
+		got = handoff
+		return "early-id", nil
+	}
+
+	if _, _, err := mgr.Enqueue(context.Background(), &DownloadRequest{URL: server.URL + "/file.bin", Filename: "file.bin", Path: t.TempDir()}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if got.EarlyBytes != int64(len(data)) {
+		t.Errorf("dispatched EarlyBytes = %d, want %d", got.EarlyBytes, len(data))
+	}
+}
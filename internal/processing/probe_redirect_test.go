@@ -2,10 +2,13 @@ package processing_test
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
 
+	"github.com/SurgeDM/Surge/internal/engine/types"
 	"github.com/SurgeDM/Surge/internal/processing"
 )
 
@@ -165,3 +168,82 @@ func TestProbeServer_RetryWithoutRangeReusesHeaderSetup(t *testing.T) {
 		t.Fatalf("fileSize = %d, want 5", res.FileSize)
 	}
 }
+
+func TestProbeRedirect_ReportsFinalURL(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Range", "bytes 0-0/10")
+		w.WriteHeader(http.StatusPartialContent)
+	}))
+	defer target.Close()
+
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL+"/resolved.bin", http.StatusFound)
+	}))
+	defer redirect.Close()
+
+	res, err := processing.ProbeServer(context.Background(), redirect.URL+"/file.bin", "", nil)
+	if err != nil {
+		t.Fatalf("ProbeServer failed: %v", err)
+	}
+	if res.FinalURL != target.URL+"/resolved.bin" {
+		t.Errorf("FinalURL = %q, want %q", res.FinalURL, target.URL+"/resolved.bin")
+	}
+
+	direct, err := processing.ProbeServer(context.Background(), target.URL+"/resolved.bin", "", nil)
+	if err != nil {
+		t.Fatalf("ProbeServer failed: %v", err)
+	}
+	if direct.FinalURL != "" {
+		t.Errorf("FinalURL = %q, want empty without a redirect", direct.FinalURL)
+	}
+}
+
+func TestProbeRedirect_ReportsRedirectChain(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/file.bin", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/mirror/file.bin", http.StatusFound)
+	})
+	mux.HandleFunc("/mirror/file.bin", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/cdn/file.bin", http.StatusFound)
+	})
+	mux.HandleFunc("/cdn/file.bin", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Range", "bytes 0-0/10")
+		w.WriteHeader(http.StatusPartialContent)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	res, err := processing.ProbeServer(context.Background(), server.URL+"/file.bin", "", nil)
+	if err != nil {
+		t.Fatalf("ProbeServer failed: %v", err)
+	}
+	want := []string{server.URL + "/mirror/file.bin", server.URL + "/cdn/file.bin"}
+	if !slices.Equal(res.Redirects, want) {
+		t.Errorf("Redirects = %v, want %v", res.Redirects, want)
+	}
+
+	direct, err := processing.ProbeServer(context.Background(), server.URL+"/cdn/file.bin", "", nil)
+	if err != nil {
+		t.Fatalf("ProbeServer failed: %v", err)
+	}
+	if direct.Redirects != nil {
+		t.Errorf("Redirects = %v, want nil without a redirect", direct.Redirects)
+	}
+}
+
+func TestProbeRedirect_BlockedCrossHost(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPartialContent)
+	}))
+	defer target.Close()
+
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusFound)
+	}))
+	defer redirect.Close()
+
+	_, err := processing.ProbeServerWithProxy(context.Background(), redirect.URL, "", nil, &types.RuntimeConfig{BlockCrossHostRedirects: true})
+	if !errors.Is(err, types.ErrCrossHostRedirect) {
+		t.Fatalf("err = %v, want ErrCrossHostRedirect", err)
+	}
+}
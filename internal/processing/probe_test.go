@@ -124,3 +124,59 @@ func TestProbeServer_ReadsBodyBeforeContextCancel(t *testing.T) {
 		t.Errorf("Expected filename 'delayed.txt', got %q. The context might have been prematurely canceled.", result.Filename)
 	}
 }
+
+func TestProbeServer_KeepsNotableResponseHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Server", "nginx/1.25")
+		w.Header().Set("ETag", `"abc"`)
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Header().Set("Content-Range", "bytes 0-0/10")
+		w.WriteHeader(http.StatusPartialContent)
+	}))
+	defer server.Close()
+
+	result, err := processing.ProbeServerWithProxy(context.Background(), server.URL, "", nil, nil)
+	if err != nil {
+		t.Fatalf("ProbeServerWithProxy() failed: %v", err)
+	}
+	want := map[string]string{"Content-Type": "application/zip", "Server": "nginx/1.25", "ETag": `"abc"`}
+	for key, value := range want {
+		if got := result.ResponseHeaders[key]; got != value {
+			t.Errorf("ResponseHeaders[%q] = %q, want %q", key, got, value)
+		}
+	}
+	if _, ok := result.ResponseHeaders["Set-Cookie"]; ok {
+		t.Error("ResponseHeaders kept Set-Cookie")
+	}
+}
+
+func TestProbeServer_AsksS3ForTheChecksumWithoutRange(t *testing.T) {
+	var heads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Amz-Request-Id", "req-1")
+		if r.Method == http.MethodHead {
+			heads.Add(1)
+			if r.Header.Get("Range") == "" && r.Header.Get("X-Amz-Checksum-Mode") == "ENABLED" {
+				w.Header().Set("X-Amz-Checksum-Sha256", "n4bQgYhMfWWaL+qgxVrQFaO/TxsrC4Is0V1sFbDwCgg=")
+			}
+			w.Header().Set("Content-Length", "10")
+			return
+		}
+		w.Header().Set("Content-Range", "bytes 0-0/10")
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write([]byte("0"))
+	}))
+	defer server.Close()
+
+	result, err := processing.ProbeServerWithProxy(context.Background(), server.URL+"/s3-object.bin", "", nil, nil)
+	if err != nil {
+		t.Fatalf("ProbeServerWithProxy() failed: %v", err)
+	}
+	if heads.Load() != 1 {
+		t.Fatalf("HEAD requests = %d, want 1", heads.Load())
+	}
+	if got := result.S3.Checksum; got.Algorithm != "sha256" || got.Value != "n4bQgYhMfWWaL+qgxVrQFaO/TxsrC4Is0V1sFbDwCgg=" {
+		t.Errorf("S3 checksum = %+v, want the HEAD's sha256", got)
+	}
+}
@@ -0,0 +1,31 @@
+package processing
+
+import (
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// Provenance is where a completed file came from, written onto the file so
+// it survives outside Surge's history.
+type Provenance struct {
+	URL       string
+	Completed time.Time
+	Checksum  string // algorithm:hex, empty when none was verified
+}
+
+// recordProvenance writes p onto the file at path when the setting allows.
+// Filesystems without extended attributes are common, so failures are only
+// logged.
+func recordProvenance(path string, p Provenance, settings *config.Settings) {
+	if path == "" || p.URL == "" {
+		return
+	}
+	if settings != nil && !config.Resolve[bool](settings.General.FileProvenance) {
+		return
+	}
+	if err := writeProvenance(path, p); err != nil {
+		utils.Debug("Lifecycle: Failed to record origin of %s: %v", path, err)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !windows
+
+package processing
+
+// writeProvenance does nothing where Surge has no way to attach metadata.
+func writeProvenance(string, Provenance) error {
+	return nil
+}
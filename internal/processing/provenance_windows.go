@@ -0,0 +1,24 @@
+//go:build windows
+
+package processing
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// writeProvenance writes a Zone.Identifier stream like browsers do, marking
+// the file as coming from the internet, with the download date and checksum
+// as extra keys.
+func writeProvenance(path string, p Provenance) error {
+	var b strings.Builder
+	b.WriteString("[ZoneTransfer]\r\nZoneId=3\r\n")
+	fmt.Fprintf(&b, "HostUrl=%s\r\n", p.URL)
+	fmt.Fprintf(&b, "SurgeCompleted=%s\r\n", p.Completed.UTC().Format(time.RFC3339))
+	if p.Checksum != "" {
+		fmt.Fprintf(&b, "SurgeChecksum=%s\r\n", p.Checksum)
+	}
+	return os.WriteFile(path+":Zone.Identifier", []byte(b.String()), 0o644)
+}
@@ -0,0 +1,33 @@
+//go:build linux || darwin
+
+package processing
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Attribute names follow the freedesktop.org conventions, so file managers
+// and tools such as getfattr show where a file came from.
+const (
+	xattrOriginURL = "user.xdg.origin.url"
+	xattrCompleted = "user.surge.completed"
+	xattrChecksum  = "user.surge.checksum"
+)
+
+func writeProvenance(path string, p Provenance) error {
+	attrs := [][2]string{
+		{xattrOriginURL, p.URL},
+		{xattrCompleted, p.Completed.UTC().Format(time.RFC3339)},
+	}
+	if p.Checksum != "" {
+		attrs = append(attrs, [2]string{xattrChecksum, p.Checksum})
+	}
+	for _, a := range attrs {
+		if err := unix.Setxattr(path, a[0], []byte(a[1]), 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+//go:build linux || darwin
+
+package processing
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"golang.org/x/sys/unix"
+)
+
+func readXattr(t *testing.T, path, name string) (string, error) {
+	t.Helper()
+	buf := make([]byte, 512)
+	n, err := unix.Getxattr(path, name, buf)
+	if err != nil {
+		return "", err
+	}
+	return string(buf[:n]), nil
+}
+
+func TestRecordProvenance_Xattrs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.bin")
+	if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := unix.Setxattr(path, "user.surge.probe", []byte("1"), 0); errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EOPNOTSUPP) {
+		t.Skip("filesystem has no user extended attributes")
+	}
+
+	settings := config.DefaultSettings()
+	completed := time.Date(2026, 3, 7, 12, 0, 0, 0, time.UTC)
+	recordProvenance(path, Provenance{URL: "https://example.com/file.bin", Completed: completed, Checksum: "sha256:ab"}, settings)
+
+	for name, want := range map[string]string{
+		xattrOriginURL: "https://example.com/file.bin",
+		xattrCompleted: "2026-03-07T12:00:00Z",
+		xattrChecksum:  "sha256:ab",
+	} {
+		if got, err := readXattr(t, path, name); err != nil || got != want {
+			t.Errorf("%s = %q (%v), want %q", name, got, err, want)
+		}
+	}
+
+	// Turned off, nothing is written
+	other := filepath.Join(t.TempDir(), "other.bin")
+	if err := os.WriteFile(other, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	settings.General.FileProvenance.Value = false
+	recordProvenance(other, Provenance{URL: "https://example.com/other.bin", Completed: completed}, settings)
+	if _, err := readXattr(t, other, xattrOriginURL); err == nil {
+		t.Error("expected no origin attribute with file_provenance off")
+	}
+}
@@ -0,0 +1,138 @@
+package processing
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/state"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// trafficFlushInterval is how often the bytes counted while downloads run
+// are added to the month's total. Pauses, completions and errors add them
+// at once.
+var trafficFlushInterval = 30 * time.Second
+
+// trafficTracker holds the bytes counted since they were last added to the
+// month's total, and the quota warnings already sent.
+type trafficTracker struct {
+	mu        sync.Mutex
+	pending   int64
+	flushedAt time.Time
+	// warned is the highest percentage of the quota warned about in
+	// warnedMonth; 100 once the quota has been reached.
+	warnedMonth string
+	warned      int
+}
+
+// quotaState is the month's usage as last checked, which downloads read
+// before they start.
+type quotaState struct {
+	mu    sync.Mutex
+	month string
+	used  int64
+	limit int64
+}
+
+// QuotaReached reports whether this month's traffic has used up
+// monthly_quota, so a download starting now should wait for the next month.
+func (mgr *LifecycleManager) QuotaReached() bool {
+	q := &mgr.quota
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.limit > 0 && q.used >= q.limit && q.month == state.TrafficMonth(time.Now())
+}
+
+// recordTraffic collects the bytes downloaded since the last call and, every
+// trafficFlushInterval or when flush is set, adds them to the month's total
+// and the speed history and checks the total against monthly_quota.
+func (mgr *LifecycleManager) recordTraffic(now time.Time, flush bool) {
+	hooks := mgr.getEngineHooks()
+
+	t := &mgr.traffic
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if hooks.TakeTraffic != nil {
+		t.pending += hooks.TakeTraffic()
+	}
+	if !flush && now.Sub(t.flushedAt) < trafficFlushInterval {
+		return
+	}
+
+	month := state.TrafficMonth(now)
+	var used int64
+	var err error
+	if t.pending > 0 {
+		used, err = state.AddTraffic(month, t.pending)
+	} else {
+		used, err = state.GetTraffic(month)
+	}
+	if err != nil {
+		utils.Debug("Lifecycle: Failed to record traffic: %v", err)
+		return
+	}
+	if t.pending > 0 {
+		if err := state.AddSpeedSample(now, t.pending); err != nil {
+			utils.Debug("Lifecycle: Failed to record speed history: %v", err)
+		}
+	}
+	t.pending = 0
+	t.flushedAt = now
+	mgr.checkQuotaLocked(month, used)
+}
+
+// checkQuotaLocked shares the month's usage with QuotaReached and sends the
+// quota_warnings notifications it has crossed. The first check after start
+// only notes how far the month already is, so a restart does not repeat
+// warnings. Callers must hold t.mu.
+func (mgr *LifecycleManager) checkQuotaLocked(month string, used int64) {
+	var limit int64
+	var warnings []int
+	if settings := mgr.GetSettings(); settings != nil {
+		limit = config.Resolve[int64](settings.Network.MonthlyQuota)
+		warnings, _ = config.ParseQuotaWarnings(config.Resolve[string](settings.Network.QuotaWarnings))
+	}
+
+	q := &mgr.quota
+	q.mu.Lock()
+	q.month, q.used, q.limit = month, used, limit
+	q.mu.Unlock()
+
+	if limit <= 0 {
+		return
+	}
+	percent := int(min(used*100/limit, 100))
+	level := 0
+	for _, w := range append(warnings, 100) {
+		if percent >= w {
+			level = w
+		}
+	}
+
+	t := &mgr.traffic
+	first := t.warnedMonth == ""
+	if t.warnedMonth != month {
+		t.warnedMonth, t.warned = month, 0
+	}
+	if level <= t.warned {
+		return
+	}
+	t.warned = level
+	if first {
+		return
+	}
+
+	usage := fmt.Sprintf("%s of %s downloaded this month", utils.ConvertBytesToHumanReadable(used), utils.ConvertBytesToHumanReadable(limit))
+	title, message := fmt.Sprintf("Monthly quota at %d%%", level), usage
+	if level == 100 {
+		title = "Monthly quota reached"
+		message = usage + "; new downloads are paused until next month"
+	}
+	notify(title, message)
+	if hooks := mgr.getEngineHooks(); hooks.PublishEvent != nil {
+		_ = hooks.PublishEvent(events.SystemLogMsg{Message: title + ": " + message})
+	}
+}
@@ -0,0 +1,73 @@
+package processing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/state"
+	"github.com/SurgeDM/Surge/internal/testutil"
+)
+
+func TestRecordTraffic_WarnsAndReachesQuota(t *testing.T) {
+	testutil.SetupStateDB(t)
+
+	origNotify := notify
+	t.Cleanup(func() { notify = origNotify })
+	var titles []string
+	notify = func(title, _ string) { titles = append(titles, title) }
+
+	mgr := newLifecycleManagerForTest()
+	mgr.settings.Network.MonthlyQuota.Value = int64(1000)
+	mgr.settings.Network.QuotaWarnings.Value = "50,80"
+	var read int64
+	mgr.SetEngineHooks(EngineHooks{TakeTraffic: func() int64 {
+		n := read
+		read = 0
+		return n
+	}})
+
+	now := time.Now()
+	month := state.TrafficMonth(now)
+	if _, err := state.AddTraffic(month, 600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Already past 50% at start: noted, not announced again
+	mgr.recordTraffic(now, true)
+	if len(titles) != 0 || mgr.QuotaReached() {
+		t.Fatalf("titles = %v, reached = %v after start; want neither", titles, mgr.QuotaReached())
+	}
+
+	// Counted bytes wait for the flush interval
+	read = 250
+	mgr.recordTraffic(now.Add(time.Second), false)
+	if got, _ := state.GetTraffic(month); got != 600 {
+		t.Fatalf("traffic = %d before the flush interval, want 600", got)
+	}
+	mgr.recordTraffic(now.Add(trafficFlushInterval+time.Second), false)
+	if got, _ := state.GetTraffic(month); got != 850 {
+		t.Fatalf("traffic = %d after the flush interval, want 850", got)
+	}
+	if samples, _ := state.SpeedHistory(now.Add(-time.Hour)); len(samples) != 1 || samples[0].Bytes != 250 {
+		t.Fatalf("speed history = %v, want the flushed 250 bytes", samples)
+	}
+	if len(titles) != 1 || titles[0] != "Monthly quota at 80%" {
+		t.Fatalf("titles = %v, want the 80%% warning", titles)
+	}
+
+	read = 200
+	mgr.recordTraffic(now.Add(trafficFlushInterval+2*time.Second), true)
+	if !mgr.QuotaReached() {
+		t.Fatal("expected the quota to be reached at 1050 of 1000 bytes")
+	}
+	if len(titles) != 2 || titles[1] != "Monthly quota reached" {
+		t.Fatalf("titles = %v, want the quota reached notification", titles)
+	}
+
+	// Raising the quota lets downloads start again
+	mgr.settings.Network.MonthlyQuota.Value = int64(2000)
+	mgr.recordTraffic(now.Add(trafficFlushInterval+3*time.Second), true)
+	if mgr.QuotaReached() {
+		t.Fatal("expected a raised quota to no longer be reached")
+	}
+}
@@ -0,0 +1,149 @@
+package processing
+
+import (
+	"sync"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/state"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// retryBackoff is how long a failed download waits before its first retry.
+// Each further retry waits twice as long as the one before, up to
+// maxRetryBackoff. It is a variable so tests can shorten it.
+var retryBackoff = 30 * time.Second
+
+const maxRetryBackoff = 30 * time.Minute
+
+// defaultMaxDownloadRetries applies when no settings are loaded.
+const defaultMaxDownloadRetries = 3
+
+// retryScheduler holds the timers of downloads waiting to be retried, so a
+// download that fails again replaces its retry instead of adding one.
+type retryScheduler struct {
+	mu      sync.Mutex
+	timers  map[string]*time.Timer
+	stopped bool
+}
+
+// retryDelay returns how long to wait before retrying a download that has
+// failed attempts times.
+func retryDelay(attempts int) time.Duration {
+	delay := retryBackoff
+	for i := 1; i < attempts && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryBackoff)
+}
+
+func (mgr *LifecycleManager) maxDownloadRetries() int {
+	settings := mgr.GetSettings()
+	if settings == nil || settings.Performance.MaxDownloadRetries == nil {
+		return defaultMaxDownloadRetries
+	}
+	return config.Resolve[int](settings.Performance.MaxDownloadRetries)
+}
+
+// recordFailure counts a failure of entry and decides what happens next:
+// another attempt after a backoff, or, once max_download_retries is used
+// up, the Failed list. It sets entry's status to match and returns the
+// message telling clients, for the caller to publish once entry is saved.
+func (mgr *LifecycleManager) recordFailure(entry *types.DownloadEntry, cause error, code types.ErrorCode, now time.Time) (events.DownloadFailedMsg, bool) {
+	message := "download failed"
+	if cause != nil {
+		message = cause.Error()
+	}
+	attempts, err := state.RecordFailure(entry.ID, message, code, now)
+	if err != nil {
+		utils.Debug("Lifecycle: Failed to record failure of %s: %v", entry.ID, err)
+		return events.DownloadFailedMsg{}, false
+	}
+
+	msg := events.DownloadFailedMsg{
+		DownloadID: entry.ID,
+		Filename:   entry.Filename,
+		Attempts:   attempts,
+		Error:      message,
+		Code:       code,
+	}
+	if attempts > mgr.maxDownloadRetries() {
+		entry.Status = "failed"
+		utils.Debug("Lifecycle: %s failed %d times, giving up", entry.ID, attempts)
+		return msg, true
+	}
+	msg.RetryAt = now.Add(retryDelay(attempts))
+	mgr.scheduleRetry(entry.ID, msg.RetryAt)
+	return msg, true
+}
+
+// scheduleRetry resumes the errored download id at at, replacing any retry
+// already scheduled for it.
+func (mgr *LifecycleManager) scheduleRetry(id string, at time.Time) {
+	mgr.retries.mu.Lock()
+	defer mgr.retries.mu.Unlock()
+	if mgr.retries.stopped {
+		return
+	}
+	if mgr.retries.timers == nil {
+		mgr.retries.timers = make(map[string]*time.Timer)
+	}
+	if timer := mgr.retries.timers[id]; timer != nil {
+		timer.Stop()
+	}
+	mgr.retries.timers[id] = time.AfterFunc(time.Until(at), func() {
+		mgr.retries.mu.Lock()
+		stopped := mgr.retries.stopped
+		delete(mgr.retries.timers, id)
+		mgr.retries.mu.Unlock()
+		if !stopped {
+			mgr.retry(id)
+		}
+	})
+}
+
+// stopRetries cancels every scheduled retry, for shutdown. Failures stay
+// recorded, so RescheduleRetries picks them up in the next session.
+func (mgr *LifecycleManager) stopRetries() {
+	mgr.retries.mu.Lock()
+	defer mgr.retries.mu.Unlock()
+	mgr.retries.stopped = true
+	for id, timer := range mgr.retries.timers {
+		timer.Stop()
+		delete(mgr.retries.timers, id)
+	}
+}
+
+// retry resumes id if it is still waiting for a retry. One resumed by hand,
+// removed or given up on meanwhile is left alone.
+func (mgr *LifecycleManager) retry(id string) {
+	entry, err := state.GetDownload(id)
+	if err != nil || entry == nil || entry.Status != "error" {
+		return
+	}
+	utils.Debug("Lifecycle: Retrying %s", id)
+	if err := mgr.Resume(id); err != nil {
+		utils.Debug("Lifecycle: Failed to retry %s: %v", id, err)
+	}
+}
+
+// RescheduleRetries schedules the retries of downloads that failed in an
+// earlier session and still have retries left. Those already due run at once.
+func (mgr *LifecycleManager) RescheduleRetries() {
+	failures, err := state.Failures()
+	if err != nil {
+		utils.Debug("Lifecycle: Failed to load failures: %v", err)
+		return
+	}
+	for _, f := range failures {
+		if f.Attempts > mgr.maxDownloadRetries() {
+			continue
+		}
+		if entry, err := state.GetDownload(f.DownloadID); err != nil || entry == nil || entry.Status != "error" {
+			continue
+		}
+		mgr.scheduleRetry(f.DownloadID, f.FailedAt.Add(retryDelay(f.Attempts)))
+	}
+}
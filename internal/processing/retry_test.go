@@ -0,0 +1,56 @@
+package processing
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/testutil"
+)
+
+func TestRetryDelay_DoublesUpToMax(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 30 * time.Minute},
+		{20, 30 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(tt.attempts); got != tt.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestRecordFailure_QuarantinesAfterMaxRetries(t *testing.T) {
+	testutil.SetupStateDB(t)
+
+	mgr := newLifecycleManagerForTest()
+	mgr.settings.Performance.MaxDownloadRetries.Value = 2
+	t.Cleanup(mgr.stopRetries)
+
+	entry := &types.DownloadEntry{ID: "download-1", Filename: "video.mp4", Status: "error"}
+	now := time.Now()
+	for attempt := 1; attempt <= 2; attempt++ {
+		msg, ok := mgr.recordFailure(entry, errors.New("connection reset"), types.ErrorCodeNetwork, now)
+		if !ok || msg.Quarantined() || msg.Attempts != attempt {
+			t.Fatalf("failure %d = %+v, %v; want a retry", attempt, msg, ok)
+		}
+		if entry.Status != "error" {
+			t.Fatalf("status after failure %d = %q, want error", attempt, entry.Status)
+		}
+	}
+
+	msg, ok := mgr.recordFailure(entry, errors.New("404 Not Found"), types.ErrorCodeHTTP4xx, now)
+	if !ok || !msg.Quarantined() || msg.Attempts != 3 || msg.Error != "404 Not Found" || msg.Code != types.ErrorCodeHTTP4xx {
+		t.Fatalf("third failure = %+v, %v; want it quarantined", msg, ok)
+	}
+	if entry.Status != "failed" {
+		t.Fatalf("status = %q, want failed", entry.Status)
+	}
+}
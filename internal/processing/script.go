@@ -0,0 +1,288 @@
+package processing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/state"
+	"github.com/SurgeDM/Surge/internal/utils"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+)
+
+const (
+	// scriptTimeout bounds one hook call, including commands it runs.
+	scriptTimeout = 10 * time.Second
+	// scriptMaxSteps stops a hook stuck in a loop well before the timeout
+	// would.
+	scriptMaxSteps = 50_000_000
+)
+
+var scriptPath = config.GetScriptPath
+
+// loadedScript is hooks.star as last read, kept until the file changes.
+var loadedScript struct {
+	mu      sync.Mutex
+	path    string
+	modTime time.Time
+	size    int64
+	globals starlark.StringDict
+	err     error
+}
+
+// loadScript returns the globals of the hooks script at path, or nil when
+// there is none. A script is read again once it changes on disk, so edits
+// apply without a restart.
+func loadScript(path string) (starlark.StringDict, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hooks script: %w", err)
+	}
+
+	loadedScript.mu.Lock()
+	defer loadedScript.mu.Unlock()
+	if loadedScript.path == path && loadedScript.modTime.Equal(info.ModTime()) && loadedScript.size == info.Size() {
+		return loadedScript.globals, loadedScript.err
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hooks script: %w", err)
+	}
+	thread := newScriptThread("load", nil)
+	globals, err := starlark.ExecFileOptions(&syntax.FileOptions{}, thread, filepath.Base(path), src, scriptBuiltins)
+	if err != nil {
+		err = fmt.Errorf("hooks script: %w", err)
+		globals = nil
+	} else {
+		// Frozen globals can be shared by hooks running at the same time
+		globals.Freeze()
+	}
+	loadedScript.path, loadedScript.modTime, loadedScript.size = path, info.ModTime(), info.Size()
+	loadedScript.globals, loadedScript.err = globals, err
+	return globals, err
+}
+
+// callHook calls the hook called name of the script at path with a download
+// described by fields. It returns None when the script does not define the hook.
+func (mgr *LifecycleManager) callHook(path, name string, fields starlark.StringDict) (starlark.Value, error) {
+	globals, err := loadScript(path)
+	if err != nil || globals == nil {
+		return starlark.None, err
+	}
+	fn, ok := globals[name].(starlark.Callable)
+	if !ok {
+		return starlark.None, nil
+	}
+	thread := newScriptThread(name, mgr)
+	timer := time.AfterFunc(scriptTimeout, func() { thread.Cancel("timed out") })
+	defer timer.Stop()
+	result, err := starlark.Call(thread, fn, starlark.Tuple{starlarkstruct.FromStringDict(starlark.String("download"), fields)}, nil)
+	if err != nil {
+		return starlark.None, fmt.Errorf("hook %s: %w", name, err)
+	}
+	return result, nil
+}
+
+func newScriptThread(name string, mgr *LifecycleManager) *starlark.Thread {
+	thread := &starlark.Thread{
+		Name: name,
+		Print: func(thread *starlark.Thread, msg string) {
+			scriptLog(thread, msg)
+		},
+	}
+	thread.SetMaxExecutionSteps(scriptMaxSteps)
+	thread.SetLocal("mgr", mgr)
+	return thread
+}
+
+// scriptBuiltins are the functions hooks can call besides Starlark's own.
+var scriptBuiltins = starlark.StringDict{
+	"log": starlark.NewBuiltin("log", func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var msg string
+		if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &msg); err != nil {
+			return nil, err
+		}
+		scriptLog(thread, msg)
+		return starlark.None, nil
+	}),
+	"notify": starlark.NewBuiltin("notify", func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var title, message string
+		if err := starlark.UnpackArgs(b.Name(), args, kwargs, "title", &title, "message?", &message); err != nil {
+			return nil, err
+		}
+		notify(title, message)
+		return starlark.None, nil
+	}),
+	// run runs a command without a shell and returns its exit code.
+	"run": starlark.NewBuiltin("run", func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if len(args) == 0 || len(kwargs) > 0 {
+			return nil, fmt.Errorf("%s: want a command and its arguments", b.Name())
+		}
+		argv := make([]string, len(args))
+		for i, arg := range args {
+			s, ok := starlark.AsString(arg)
+			if !ok {
+				return nil, fmt.Errorf("%s: argument %d is not a string", b.Name(), i+1)
+			}
+			argv[i] = s
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), scriptTimeout)
+		defer cancel()
+		err := exec.CommandContext(ctx, argv[0], argv[1:]...).Run()
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return starlark.MakeInt(exitErr.ExitCode()), nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", b.Name(), err)
+		}
+		return starlark.MakeInt(0), nil
+	}),
+}
+
+// scriptLog shows msg in the log of the running instance.
+func scriptLog(thread *starlark.Thread, msg string) {
+	utils.Debug("Script %s: %s", thread.Name, msg)
+	mgr, _ := thread.Local("mgr").(*LifecycleManager)
+	if mgr == nil {
+		return
+	}
+	if hooks := mgr.getEngineHooks(); hooks.PublishEvent != nil {
+		_ = hooks.PublishEvent(events.SystemLogMsg{Message: msg})
+	}
+}
+
+// applyScriptHooks lets the rename and route hooks name a probed download and
+// pick its directory. Either returns None to leave it to Surge. A name from
+// rename is kept as given, as one typed by the user is; a directory from
+// route takes the place of category routing.
+func (mgr *LifecycleManager) applyScriptHooks(req *DownloadRequest, probe *ProbeResult) error {
+	fields := starlark.StringDict{
+		"url":          starlark.String(req.URL),
+		"filename":     starlark.String(getBaseFilename(req.URL, req.Filename, probe)),
+		"dir":          starlark.String(req.Path),
+		"size":         starlark.MakeInt64(probe.FileSize),
+		"content_type": starlark.String(probe.ContentType),
+		"tags":         stringList(req.Request.Tags),
+	}
+
+	path := scriptPath()
+	name, err := hookString(mgr.callHook(path, "rename", fields))
+	if err != nil {
+		return err
+	}
+	if name != "" {
+		if filepath.Base(name) != name || name == "." || name == ".." {
+			return fmt.Errorf("hook rename: %q is not a file name", name)
+		}
+		req.Filename = name
+		fields["filename"] = starlark.String(name)
+	}
+
+	dir, err := hookString(mgr.callHook(path, "route", fields))
+	if err != nil {
+		return err
+	}
+	if dir != "" {
+		dir = utils.ExpandHome(dir)
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(req.Path, dir)
+		}
+		req.Path = dir
+		req.IsExplicitCategory = true
+	}
+	return nil
+}
+
+func hookString(v starlark.Value, err error) (string, error) {
+	if err != nil || v == starlark.None {
+		return "", err
+	}
+	s, ok := starlark.AsString(v)
+	if !ok {
+		return "", fmt.Errorf("hook returned %s, want a string or None", v.Type())
+	}
+	return s, nil
+}
+
+func stringList(items []string) *starlark.List {
+	values := make([]starlark.Value, len(items))
+	for i, item := range items {
+		values[i] = starlark.String(item)
+	}
+	return starlark.NewList(values)
+}
+
+// StartScriptHooks calls the on_add, on_complete and on_error hooks of the
+// hooks script at path as downloads are queued, finish and fail, until ch is
+// closed. A hook that fails is logged and does not affect the download.
+func (mgr *LifecycleManager) StartScriptHooks(ch <-chan interface{}, path string) {
+	for msg := range ch {
+		var hook string
+		var fields starlark.StringDict
+		switch m := msg.(type) {
+		case events.DownloadQueuedMsg:
+			hook = "on_add"
+			fields = starlark.StringDict{
+				"id":       starlark.String(m.DownloadID),
+				"url":      starlark.String(m.URL),
+				"filename": starlark.String(m.Filename),
+				"path":     starlark.String(m.DestPath),
+				"tags":     stringList(m.Tags),
+			}
+		case events.DownloadCompleteMsg:
+			hook = "on_complete"
+			fields = starlark.StringDict{
+				"id":       starlark.String(m.DownloadID),
+				"filename": starlark.String(m.Filename),
+				"size":     starlark.MakeInt64(m.Total),
+				"elapsed":  starlark.Float(m.Elapsed.Seconds()),
+			}
+			fillScriptFields(fields, m.DownloadID)
+		case events.DownloadErrorMsg:
+			hook = "on_error"
+			fields = starlark.StringDict{
+				"id":       starlark.String(m.DownloadID),
+				"filename": starlark.String(m.Filename),
+				"path":     starlark.String(m.DestPath),
+				"error":    starlark.String(""),
+				"code":     starlark.String(string(m.ErrorCode())),
+			}
+			if m.Err != nil {
+				fields["error"] = starlark.String(m.Err.Error())
+			}
+			fillScriptFields(fields, m.DownloadID)
+		default:
+			continue
+		}
+		if _, err := mgr.callHook(path, hook, fields); err != nil {
+			utils.Debug("Script: %v", err)
+			if hooks := mgr.getEngineHooks(); hooks.PublishEvent != nil {
+				_ = hooks.PublishEvent(events.SystemLogMsg{Message: err.Error()})
+			}
+		}
+	}
+}
+
+// fillScriptFields adds the URL, and the path when fields lack it, from the
+// persisted entry.
+func fillScriptFields(fields starlark.StringDict, id string) {
+	url, path := "", ""
+	if entry, _ := state.GetDownload(id); entry != nil {
+		url, path = entry.URL, entry.DestPath
+	}
+	fields["url"] = starlark.String(url)
+	if p, _ := starlark.AsString(fields["path"]); p == "" {
+		fields["path"] = starlark.String(path)
+	}
+}
@@ -0,0 +1,111 @@
+package processing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func useScript(t *testing.T, src string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "hooks.star")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	orig := scriptPath
+	scriptPath = func() string { return path }
+	t.Cleanup(func() { scriptPath = orig })
+	return path
+}
+
+func TestLifecycleManager_Plan_UsesRenameAndRouteHooks(t *testing.T) {
+	server := newProbeTestServer(t, 2*types.MB)
+	defer server.Close()
+	useScript(t, `
+def rename(d):
+    if d.size > 1000000:
+        return "big-" + d.filename
+
+def route(d):
+    if d.filename.startswith("big-"):
+        return "large"
+`)
+
+	dir := t.TempDir()
+	mgr := newLifecycleManagerForTest()
+	plan, err := mgr.Plan(context.Background(), &DownloadRequest{URL: server.URL + "/disk.img", Path: dir})
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if want := filepath.Join(dir, "large", "big-disk.img"); plan.DestPath != want {
+		t.Fatalf("DestPath = %q, want %q", plan.DestPath, want)
+	}
+}
+
+func TestApplyScriptHooks_RejectsBadAnswers(t *testing.T) {
+	mgr := newLifecycleManagerForTest()
+	probe := &ProbeResult{Filename: "a.iso"}
+	for name, src := range map[string]string{
+		"path as a name": "def rename(d):\n    return '../a.iso'\n",
+		"not a string":   "def route(d):\n    return 42\n",
+		"runtime error":  "def route(d):\n    return d.missing\n",
+		"endless loop":   "def route(d):\n    for i in range(1000000000):\n        pass\n",
+		"syntax error":   "def route(d)\n",
+	} {
+		useScript(t, src)
+		req := &DownloadRequest{URL: "https://example.com/a.iso", Path: t.TempDir()}
+		if err := mgr.applyScriptHooks(req, probe); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	// A script without the hooks changes nothing
+	useScript(t, "def on_complete(d):\n    pass\n")
+	req := &DownloadRequest{URL: "https://example.com/a.iso", Path: "/downloads"}
+	if err := mgr.applyScriptHooks(req, probe); err != nil || req.Filename != "" || req.Path != "/downloads" || req.IsExplicitCategory {
+		t.Fatalf("req = %+v, err = %v; want it untouched", req, err)
+	}
+}
+
+func TestStartScriptHooks_CallsEventHooks(t *testing.T) {
+	path := useScript(t, `
+def on_complete(d):
+    log("done " + d.filename + " " + str(d.size))
+
+def on_error(d):
+    notify("Failed", d.filename + ": " + d.error)
+    fail("broken hook")
+`)
+	origNotify := notify
+	t.Cleanup(func() { notify = origNotify })
+	var notified []string
+	notify = func(title, msg string) { notified = append(notified, title+"|"+msg) }
+
+	var logged []string
+	mgr := newLifecycleManagerForTest()
+	mgr.SetEngineHooks(EngineHooks{PublishEvent: func(msg interface{}) error {
+		if m, ok := msg.(events.SystemLogMsg); ok {
+			logged = append(logged, m.Message)
+		}
+		return nil
+	}})
+
+	ch := make(chan interface{}, 2)
+	ch <- events.DownloadCompleteMsg{DownloadID: "id-1", Filename: "a.iso", Total: 42, Elapsed: time.Second}
+	ch <- events.DownloadErrorMsg{DownloadID: "id-2", Filename: "b.iso", Err: os.ErrPermission}
+	close(ch)
+	mgr.StartScriptHooks(ch, path)
+
+	if len(logged) != 2 || logged[0] != "done a.iso 42" || !strings.Contains(logged[1], "broken hook") {
+		t.Fatalf("logged = %q", logged)
+	}
+	if len(notified) != 1 || notified[0] != "Failed|b.iso: permission denied" {
+		t.Fatalf("notified = %q", notified)
+	}
+}
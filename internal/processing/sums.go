@@ -0,0 +1,167 @@
+package processing
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// SumsFileName is the checksum manifest written next to a finished batch,
+// in the format sha256sum -c reads.
+const SumsFileName = "SHA256SUMS"
+
+// sumsBatch is a batch waiting for its members to finish before its
+// manifest is written.
+type sumsBatch struct {
+	pending map[string]bool
+	files   map[string]string // final path -> sha256:<hex> when already verified, else ""
+	done    chan struct{}
+}
+
+// sumsRegistry maps the downloads of tracked batches to their batch.
+type sumsRegistry struct {
+	mu      sync.Mutex
+	batches map[string]*sumsBatch
+}
+
+// TrackSums writes a SHA256SUMS manifest into the directories of the
+// downloads ids once every one of them has completed, failed or been
+// removed. It must be called before they are enqueued, so that none can
+// finish unseen. The returned channel is closed once the manifests are
+// written, or right away when nothing completed.
+func (mgr *LifecycleManager) TrackSums(ids []string) <-chan struct{} {
+	batch := &sumsBatch{pending: make(map[string]bool), files: make(map[string]string), done: make(chan struct{})}
+	mgr.sums.mu.Lock()
+	defer mgr.sums.mu.Unlock()
+	if mgr.sums.batches == nil {
+		mgr.sums.batches = make(map[string]*sumsBatch)
+	}
+	for _, id := range ids {
+		batch.pending[id] = true
+		mgr.sums.batches[id] = batch
+	}
+	if len(ids) == 0 {
+		close(batch.done)
+	}
+	return batch.done
+}
+
+// UntrackSums leaves id out of its batch's manifest, for a download that
+// could not be enqueued.
+func (mgr *LifecycleManager) UntrackSums(id string) {
+	mgr.finishSums(id, "", "")
+}
+
+// finishSums records the outcome of id. A completed download passes its
+// final path and, when it was verified against one, its checksum.
+func (mgr *LifecycleManager) finishSums(id, finalPath, checksum string) {
+	mgr.sums.mu.Lock()
+	batch, ok := mgr.sums.batches[id]
+	if !ok {
+		mgr.sums.mu.Unlock()
+		return
+	}
+	delete(mgr.sums.batches, id)
+	delete(batch.pending, id)
+	if finalPath != "" {
+		batch.files[finalPath] = checksum
+	}
+	complete := len(batch.pending) == 0
+	mgr.sums.mu.Unlock()
+
+	if complete {
+		// Hashing what was not verified reads whole files, so it stays off
+		// the event worker.
+		go func() {
+			defer close(batch.done)
+			writeSumsManifests(batch.files)
+		}()
+	}
+}
+
+// writeSumsManifests writes one manifest per directory. Entries for files
+// outside the batch that an earlier manifest listed are kept.
+func writeSumsManifests(files map[string]string) {
+	byDir := make(map[string]map[string]string)
+	for path, checksum := range files {
+		digest, err := sha256Digest(path, checksum)
+		if err != nil {
+			utils.Debug("Lifecycle: leaving %s out of %s: %v", path, SumsFileName, err)
+			continue
+		}
+		dir := filepath.Dir(path)
+		if byDir[dir] == nil {
+			byDir[dir] = make(map[string]string)
+		}
+		byDir[dir][filepath.Base(path)] = digest
+	}
+	for dir, entries := range byDir {
+		if err := writeSumsFile(filepath.Join(dir, SumsFileName), entries); err != nil {
+			utils.Debug("Lifecycle: failed to write %s in %s: %v", SumsFileName, dir, err)
+		}
+	}
+}
+
+// sha256Digest reuses a SHA-256 the download was verified against, and
+// hashes the file otherwise.
+func sha256Digest(path, checksum string) (string, error) {
+	if algorithm, digest, err := types.ParseChecksum(checksum); err == nil && algorithm == "sha256" {
+		return digest, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func writeSumsFile(path string, entries map[string]string) error {
+	merged := make(map[string]string)
+	if data, err := os.ReadFile(path); err == nil {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			digest, name, ok := strings.Cut(scanner.Text(), " ")
+			if !ok {
+				continue
+			}
+			// "<hex>  name" for text mode, "<hex> *name" for binary
+			name = strings.TrimPrefix(strings.TrimPrefix(name, " "), "*")
+			merged[name] = digest
+		}
+	}
+	for name, digest := range entries {
+		merged[name] = digest
+	}
+
+	names := make([]string, 0, len(merged))
+	for name := range merged {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var buf bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&buf, "%s  %s\n", merged[name], name)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,71 @@
+package processing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func waitSums(t *testing.T, done <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("manifest was not written")
+	}
+}
+
+func TestTrackSums_WritesManifestOnceBatchFinishes(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.iso")
+	b := filepath.Join(dir, "b.iso")
+	for _, path := range []string{a, b} {
+		if err := os.WriteFile(path, []byte(filepath.Base(path)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// An earlier batch's entry stays; a stale one for a.iso is replaced
+	if err := os.WriteFile(filepath.Join(dir, SumsFileName), []byte("1111  old.iso\n2222 *a.iso\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr := newLifecycleManagerForTest()
+	done := mgr.TrackSums([]string{"id-a", "id-b", "id-c", "id-d"})
+	verified := "sha256:" + "ab" + strings.Repeat("cd", 31)
+	mgr.finishSums("id-a", a, verified)
+	mgr.finishSums("id-b", b, "")
+	mgr.finishSums("id-c", "", "") // failed
+	select {
+	case <-done:
+		t.Fatal("manifest written before the batch finished")
+	default:
+	}
+	mgr.UntrackSums("id-d")
+	waitSums(t, done)
+
+	sumB := sha256.Sum256([]byte("b.iso"))
+	want := "ab" + strings.Repeat("cd", 31) + "  a.iso\n" +
+		hex.EncodeToString(sumB[:]) + "  b.iso\n" +
+		"1111  old.iso\n"
+	got, err := os.ReadFile(filepath.Join(dir, SumsFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("manifest =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTrackSums_NothingCompleted(t *testing.T) {
+	mgr := newLifecycleManagerForTest()
+	done := mgr.TrackSums([]string{"only"})
+	mgr.finishSums("only", "", "")
+	waitSums(t, done)
+
+	// Downloads outside a batch are ignored
+	mgr.finishSums("other", filepath.Join(t.TempDir(), "x"), "")
+}
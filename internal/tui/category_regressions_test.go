@@ -2,6 +2,7 @@ package tui
 
 import (
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"charm.land/bubbles/v2/textinput"
@@ -22,7 +23,7 @@ func newCategoryTestModel(t *testing.T, settings *config.Settings) RootModel {
 		Service:  svc,
 		list:     NewDownloadList(80, 20),
 		keys:     config.DefaultKeyMap(),
-		inputs:   []textinput.Model{textinput.New(), textinput.New(), textinput.New(), textinput.New()},
+		inputs:   newInputModels(),
 	}
 }
 
@@ -38,7 +39,11 @@ func TestStartDownload_RoutesDefaultPathWithURLDerivedFilename(t *testing.T) {
 	}
 
 	m := newCategoryTestModel(t, settings)
-	m, _ = m.startDownload("https://example.com/screenshot.jpg", nil, nil, rootDir, true, "", "")
+	m, _ = m.startDownload(downloadStart{
+		URL:           "https://example.com/screenshot.jpg",
+		Path:          rootDir,
+		IsDefaultPath: true,
+	})
 
 	if len(m.downloads) != 1 {
 		t.Fatalf("expected 1 download, got %d", len(m.downloads))
@@ -199,3 +204,75 @@ func TestGetFilteredDownloads_AppliesCategoryFilter(t *testing.T) {
 		t.Fatalf("uncategorized filter returned %+v", filtered)
 	}
 }
+
+func TestUpdate_InputSubmit_SeveralURLsQueueEach(t *testing.T) {
+	rootDir := t.TempDir()
+	settings := config.DefaultSettings()
+	settings.General.DefaultDownloadDir.Value = rootDir
+
+	m := newCategoryTestModel(t, settings)
+	m.state = InputState
+	m.focusedInput = inputPriority
+	// A multi-line paste arrives with its lines joined by spaces
+	m.inputs[inputURL].SetValue("https://example.com/a.iso https://example.com/b.iso, https://mirror.example.com/b.iso")
+	m.inputs[inputPath].SetValue(rootDir)
+	m.inputs[inputChecksum].SetValue("sha256:" + strings.Repeat("ab", 32))
+	m.inputs[inputHeaders].SetValue("Authorization: Bearer t; Cookie: a=1; b=2")
+	m.inputs[inputConnections].SetValue("4")
+	m.inputs[inputPriority].SetValue("low")
+
+	updated, _ := m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	m2 := updated.(RootModel)
+
+	if m2.state != DashboardState || m2.inputError != "" {
+		t.Fatalf("state = %v, error = %q; want the form submitted", m2.state, m2.inputError)
+	}
+	if len(m2.downloads) != 2 {
+		t.Fatalf("expected 2 downloads, got %d", len(m2.downloads))
+	}
+	if m2.downloads[0].URL != "https://example.com/a.iso" || m2.downloads[1].URL != "https://example.com/b.iso" {
+		t.Errorf("downloads = %q, %q", m2.downloads[0].URL, m2.downloads[1].URL)
+	}
+}
+
+func TestUpdate_InputSubmit_InvalidOptionKeepsForm(t *testing.T) {
+	m := newCategoryTestModel(t, config.DefaultSettings())
+	m.state = InputState
+	m.focusedInput = inputFilename
+	m.inputs[inputURL].SetValue("https://example.com/a.iso")
+	m.inputs[inputConnections].SetValue("lots")
+
+	updated, _ := m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	m2 := updated.(RootModel)
+
+	if m2.state != InputState || m2.focusedInput != inputConnections || m2.inputError == "" {
+		t.Fatalf("state = %v, focus = %d, error = %q; want the connections field flagged", m2.state, m2.focusedInput, m2.inputError)
+	}
+	if len(m2.downloads) != 0 {
+		t.Fatalf("expected no downloads, got %d", len(m2.downloads))
+	}
+}
+
+func TestParseHeaderInput(t *testing.T) {
+	got, err := parseHeaderInput("authorization: Bearer t; Cookie: a=1; b=2;  Referer: https://example.com/ ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"Authorization": "Bearer t",
+		"Cookie":        "a=1; b=2",
+		"Referer":       "https://example.com/",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("headers = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+
+	if _, err := parseHeaderInput("no colon here"); err == nil {
+		t.Error("expected an error for a header without a name")
+	}
+}
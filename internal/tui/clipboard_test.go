@@ -0,0 +1,43 @@
+package tui
+
+import (
+	"testing"
+
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"github.com/SurgeDM/Surge/internal/config"
+)
+
+func TestCopyKeys_CopySelectedDownloadsURLAndPath(t *testing.T) {
+	orig := writeClipboard
+	t.Cleanup(func() { writeClipboard = orig })
+	var copied []string
+	writeClipboard = func(text string) error {
+		copied = append(copied, text)
+		return nil
+	}
+
+	m := RootModel{
+		state: DashboardState,
+		downloads: []*DownloadModel{{
+			ID:          "dl-1",
+			URL:         "https://example.com/a.iso",
+			Filename:    "a.iso",
+			Destination: "/downloads/a.iso",
+		}},
+		Settings:    config.DefaultSettings(),
+		keys:        config.DefaultKeyMap(),
+		list:        NewDownloadList(80, 20),
+		logViewport: viewport.New(viewport.WithWidth(60), viewport.WithHeight(5)),
+	}
+	m.UpdateListItems()
+
+	updated, _ := m.updateDashboard(tea.KeyPressMsg{Code: 'y', Text: "y"})
+	m = updated.(RootModel)
+	updated, _ = m.updateDashboard(tea.KeyPressMsg{Code: 'Y', Text: "Y"})
+	m = updated.(RootModel)
+
+	if len(copied) != 2 || copied[0] != "https://example.com/a.iso" || copied[1] != "/downloads/a.iso" {
+		t.Fatalf("copied = %q, want the URL then the path", copied)
+	}
+}
@@ -291,6 +291,7 @@ func StateError() color.Color       { return Red() }
 func StatePaused() color.Color      { return Orange() }
 func StateDownloading() color.Color { return Green() }
 func StateDone() color.Color        { return Magenta() }
+func StatePhase() color.Color       { return Cyan() }
 func StateVersion() color.Color     { return Blue() }
 
 // Progress Mappings
@@ -5,6 +5,9 @@ import (
 	"runtime"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/SurgeDM/Surge/internal/core"
+	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/utils"
 	"github.com/SurgeDM/Surge/internal/version"
 )
 
@@ -25,6 +28,20 @@ func shutdownCmd(service interface{ Shutdown() error }) tea.Cmd {
 	}
 }
 
+// declineRequestCmd tells the sender of request id that the TUI turned it
+// down, so a retry of it is prompted for again.
+func declineRequestCmd(service core.DownloadService, id string) tea.Cmd {
+	if service == nil || id == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		if err := service.Publish(events.DownloadRequestDeclinedMsg{ID: id}); err != nil {
+			utils.Debug("Failed to report declined request %s: %v", id, err)
+		}
+		return nil
+	}
+}
+
 // openWithSystem opens a file or URL with the system's default application
 func openWithSystem(path string) error {
 	var cmd *exec.Cmd
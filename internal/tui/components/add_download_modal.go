@@ -20,16 +20,28 @@ type AddDownloadModal struct {
 	ShowURL         bool
 	URL             string
 	BrowseHintIndex int
-	Help            help.Model
-	HelpKeys        help.KeyMap
-	BorderColor     color.Color
-	Width           int
-	Height          int
+	// RecentDirs are offered below the browse field while it is focused.
+	RecentDirs []string
+	// Error explains why the form was not accepted.
+	Error string
+	// Dense drops the blank line between inputs, for forms that would not
+	// fit the terminal otherwise.
+	Dense       bool
+	Help        help.Model
+	HelpKeys    help.KeyMap
+	BorderColor color.Color
+	Width       int
+	Height      int
 }
 
 // View renders the inner content (without border box).
 func (m AddDownloadModal) View() string {
-	labelStyle := lipgloss.NewStyle().Width(10).Foreground(colors.LightGray())
+	// Labels share one width, wide enough for the longest translation
+	labelWidth := 10
+	for _, label := range m.Labels {
+		labelWidth = max(labelWidth, lipgloss.Width(label)+1)
+	}
+	labelStyle := lipgloss.NewStyle().Width(labelWidth).Foreground(colors.LightGray())
 	hintBase := lipgloss.NewStyle().MarginLeft(1).Foreground(colors.LightGray())
 	content := []string{""}
 
@@ -45,8 +57,7 @@ func (m AddDownloadModal) View() string {
 
 	for i := 0; i < len(m.Inputs) && i < len(m.Labels); i++ {
 		// Calculate available width for inputs
-		// Accounts for: horizontal padding (4), label (10), and box borders (2)
-		const labelWidth = 10
+		// Accounts for: horizontal padding (4), label, and box borders (2)
 		horizontalPadding := lipgloss.NewStyle().Padding(0, 2).GetHorizontalFrameSize()
 		inputW := m.Width - BorderFrameWidth - horizontalPadding - labelWidth
 
@@ -67,9 +78,24 @@ func (m AddDownloadModal) View() string {
 			}
 			row = lipgloss.JoinHorizontal(lipgloss.Left, row, hintStyle.Render("[Tab] Browse"))
 		}
-		content = append(content, row, "")
+		content = append(content, row)
+		if m.BrowseHintIndex == i && m.FocusedInput == i && len(m.RecentDirs) > 0 {
+			recent := RecentDirsLine(m.RecentDirs, "alt+", inputW+13)
+			content = append(content, lipgloss.NewStyle().MarginLeft(labelWidth).Render(recent))
+		}
+		if !m.Dense {
+			content = append(content, "")
+		}
 	}
 
+	if m.Error != "" {
+		if m.Dense {
+			content = append(content, "")
+		}
+		content = append(content, lipgloss.NewStyle().Foreground(colors.StateError()).Render(m.Error), "")
+	} else if m.Dense {
+		content = append(content, "")
+	}
 	content = append(content, m.Help.View(m.HelpKeys))
 	return lipgloss.NewStyle().Padding(0, 2).Render(lipgloss.JoinVertical(lipgloss.Left, content...))
 }
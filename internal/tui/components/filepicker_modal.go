@@ -12,10 +12,12 @@ import (
 
 // FilePickerModal represents a styled file picker modal
 type FilePickerModal struct {
-	Title       string
-	Picker      *filepicker.Model
-	Help        help.Model
-	HelpKeys    help.KeyMap
+	Title    string
+	Picker   *filepicker.Model
+	Help     help.Model
+	HelpKeys help.KeyMap
+	// RecentDirs are listed under the current directory, picked by number.
+	RecentDirs  []string
 	BorderColor color.Color
 	Width       int
 	Height      int
@@ -38,14 +40,12 @@ func NewFilePickerModal(title string, picker *filepicker.Model, helpModel help.M
 func (m FilePickerModal) View() string {
 	pathStyle := lipgloss.NewStyle().Foreground(colors.LightGray())
 
-	content := lipgloss.JoinVertical(lipgloss.Left,
-		"",
-		pathStyle.Render(m.Picker.CurrentDirectory),
-		"",
-		m.Picker.View(),
-		"",
-		m.Help.View(m.HelpKeys),
-	)
+	lines := []string{"", pathStyle.Render(m.Picker.CurrentDirectory)}
+	if len(m.RecentDirs) > 0 {
+		lines = append(lines, RecentDirsLine(m.RecentDirs, "", m.Width-BorderFrameWidth-4))
+	}
+	lines = append(lines, "", m.Picker.View(), "", m.Help.View(m.HelpKeys))
+	content := lipgloss.JoinVertical(lipgloss.Left, lines...)
 
 	return lipgloss.NewStyle().Padding(0, 2).Render(content)
 }
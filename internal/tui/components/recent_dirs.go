@@ -0,0 +1,31 @@
+package components
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	"github.com/SurgeDM/Surge/internal/tui/colors"
+)
+
+// RecentDirsLine lists recent directories by their number key, e.g.
+// "1 Downloads  2 isos", cut to width. keyPrefix goes before each number,
+// such as "alt+".
+func RecentDirsLine(dirs []string, keyPrefix string, width int) string {
+	if len(dirs) == 0 || width <= 0 {
+		return ""
+	}
+	keyStyle := lipgloss.NewStyle().Foreground(colors.Pink())
+	nameStyle := lipgloss.NewStyle().Foreground(colors.LightGray())
+
+	parts := make([]string, 0, len(dirs))
+	for i, dir := range dirs {
+		name := filepath.Base(dir)
+		if name == "." || name == string(filepath.Separator) {
+			name = dir
+		}
+		parts = append(parts, keyStyle.Render(fmt.Sprintf("%s%d", keyPrefix, i+1))+" "+nameStyle.Render(name))
+	}
+	return lipgloss.NewStyle().MaxWidth(width).Render(strings.Join(parts, "  "))
+}
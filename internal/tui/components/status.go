@@ -4,6 +4,7 @@ import (
 	"image/color"
 	"sync"
 
+	"github.com/SurgeDM/Surge/internal/i18n"
 	"github.com/SurgeDM/Surge/internal/tui/colors"
 
 	"charm.land/lipgloss/v2"
@@ -20,18 +21,19 @@ const (
 	StatusError
 )
 
-// statusInfo holds the display properties for each status
+// statusInfo holds the display properties for each status. The label is a
+// message ID, translated when rendered.
 type statusInfo struct {
 	icon  string
 	label string
 }
 
 var statusMap = map[DownloadStatus]statusInfo{
-	StatusQueued:      {icon: "\u22ef", label: "Queued"},
-	StatusDownloading: {icon: "\u2b07", label: "Downloading"},
-	StatusPaused:      {icon: "\u23f8", label: "Paused"},
-	StatusComplete:    {icon: "\u2714", label: "Completed"},
-	StatusError:       {icon: "\u2716", label: "Error"},
+	StatusQueued:      {icon: "\u22ef", label: "status.queued"},
+	StatusDownloading: {icon: "\u2b07", label: "status.downloading"},
+	StatusPaused:      {icon: "\u23f8", label: "status.paused"},
+	StatusComplete:    {icon: "\u2714", label: "status.completed"},
+	StatusError:       {icon: "\u2716", label: "status.error"},
 }
 
 var (
@@ -55,7 +57,7 @@ func rebuildStatusCache() {
 	for status := StatusQueued; status <= StatusError; status++ {
 		info := statusMap[status]
 		style := lipgloss.NewStyle().Foreground(status.Color())
-		statusRenderCache[status][0] = style.Render(info.icon + " " + info.label)
+		statusRenderCache[status][0] = style.Render(info.icon + " " + i18n.T(info.label))
 		statusRenderCache[status][1] = style.Render(info.icon)
 	}
 	queuedSpinnerStyle = lipgloss.NewStyle().Foreground(StatusQueued.Color())
@@ -72,9 +74,9 @@ func (s DownloadStatus) Icon() string {
 // Label returns the status label
 func (s DownloadStatus) Label() string {
 	if info, ok := statusMap[s]; ok {
-		return info.label
+		return i18n.T(info.label)
 	}
-	return "Unknown"
+	return i18n.T("status.unknown")
 }
 
 // Color returns the status color
@@ -100,7 +102,7 @@ func (s DownloadStatus) Render() string {
 	if s >= StatusQueued && s <= StatusError {
 		return statusRenderCache[s][0]
 	}
-	return "Unknown"
+	return i18n.T("status.unknown")
 }
 
 // RenderWithSpinner returns the styled icon + label combination, conditionally substituting a dynamic spinner for the Queued state
@@ -9,6 +9,9 @@ import (
 const (
 	// === Timeouts and Intervals ===
 	TickInterval = 200 * time.Millisecond
+	// AccessibleFPS caps redraws in accessible mode so screen readers are not
+	// flooded with changes
+	AccessibleFPS = 1
 
 	// === Layout Ratios ===
 	ListWidthRatio         = 0.6  // Dashboard: List takes 60% width
@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+type logMockService struct {
+	mockService
+}
+
+func (s *logMockService) Logs(id string) ([]utils.LogLine, error) {
+	return []utils.LogLine{{Time: time.Now(), Message: "Worker 0 started for " + id}}, nil
+}
+
+func TestDownloadLog_ShowsSelectedDownloadsLog(t *testing.T) {
+	m := RootModel{
+		state:       DashboardState,
+		downloads:   []*DownloadModel{{ID: "dl-1", URL: "https://example.com/a.iso", Filename: "a.iso"}},
+		Service:     &logMockService{},
+		Settings:    config.DefaultSettings(),
+		keys:        config.DefaultKeyMap(),
+		list:        NewDownloadList(80, 20),
+		logViewport: viewport.New(viewport.WithWidth(60), viewport.WithHeight(5)),
+	}
+	m.UpdateListItems()
+	m.addLogEntry("activity entry")
+
+	updated, cmd := m.updateDashboard(tea.KeyPressMsg{Code: 'L', Text: "L"})
+	m = updated.(RootModel)
+	if cmd == nil {
+		t.Fatal("expected a command fetching the download's log")
+	}
+	updated, _ = m.updateEvents(cmd())
+	m = updated.(RootModel)
+	if m.downloadLog == nil || !m.logFocused {
+		t.Fatal("expected the download's log to be shown and focused")
+	}
+	if view := m.logViewport.View(); !strings.Contains(view, "Worker 0 started for dl-1") || strings.Contains(view, "activity entry") {
+		t.Fatalf("log pane = %q, want only the download's log", view)
+	}
+
+	// New activity does not replace the download's log
+	m.addLogEntry("another activity entry")
+	if strings.Contains(m.logViewport.View(), "another activity entry") {
+		t.Fatal("activity entry shown over the download's log")
+	}
+
+	updated, _ = m.updateDashboard(tea.KeyPressMsg{Code: tea.KeyEscape})
+	m = updated.(RootModel)
+	if m.downloadLog != nil || m.logFocused {
+		t.Fatal("expected esc to close the download's log")
+	}
+	if !strings.Contains(m.logViewport.View(), "another activity entry") {
+		t.Fatalf("log pane = %q, want the activity log back", m.logViewport.View())
+	}
+}
@@ -27,8 +27,11 @@ func (m RootModel) handleDownloadRequestMsg(msg events.DownloadRequestMsg, queue
 	if duplicate != nil && config.Resolve[bool](m.Settings.General.WarnOnDuplicate) {
 		utils.Debug("Duplicate download detected in TUI: %s", msg.URL)
 		m.pendingURL = msg.URL
+		m.pendingID = msg.ID
 		m.pendingMirrors = msg.Mirrors
 		m.pendingHeaders = msg.Headers
+		m.pendingTLS = msg.TLS
+		m.pendingRequest = msg.Request
 		m.pendingPath = path
 		m.pendingIsDefaultPath = isDefaultPath
 		m.pendingFilename = msg.Filename
@@ -39,8 +42,11 @@ func (m RootModel) handleDownloadRequestMsg(msg events.DownloadRequestMsg, queue
 
 	if m.Settings != nil && config.Resolve[bool](m.Settings.Extension.ExtensionPrompt) {
 		m.pendingURL = msg.URL
+		m.pendingID = msg.ID
 		m.pendingMirrors = msg.Mirrors
 		m.pendingHeaders = msg.Headers
+		m.pendingTLS = msg.TLS
+		m.pendingRequest = msg.Request
 		m.pendingPath = path
 		m.pendingIsDefaultPath = isDefaultPath
 		m.pendingFilename = msg.Filename
@@ -55,7 +61,17 @@ func (m RootModel) handleDownloadRequestMsg(msg events.DownloadRequestMsg, queue
 		return m, nil
 	}
 
-	return m.startDownload(msg.URL, msg.Mirrors, msg.Headers, path, isDefaultPath, msg.Filename, msg.ID)
+	return m.startDownload(downloadStart{
+		URL:           msg.URL,
+		Mirrors:       msg.Mirrors,
+		Headers:       msg.Headers,
+		TLS:           msg.TLS,
+		Request:       msg.Request,
+		Path:          path,
+		IsDefaultPath: isDefaultPath,
+		Filename:      msg.Filename,
+		ID:            msg.ID,
+	})
 }
 
 func (m RootModel) handleBatchDownloadRequestMsg(msg events.BatchDownloadRequestMsg, queueIfBusy bool) (tea.Model, tea.Cmd) {
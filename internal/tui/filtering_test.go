@@ -1,7 +1,10 @@
 package tui
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/SurgeDM/Surge/internal/engine/events"
 )
 
 func TestTabFiltering(t *testing.T) {
@@ -144,3 +147,51 @@ func TestComputeViewStatsConsistency(t *testing.T) {
 		t.Errorf("DownloadedCount (%d) does not match getFilteredDownloads (%d)", stats.DownloadedCount, len(m.getFilteredDownloads()))
 	}
 }
+
+func TestSearchSpansTabsAndRanks(t *testing.T) {
+	m := RootModel{
+		activeTab: TabActive,
+		downloads: []*DownloadModel{
+			{ID: "1", Filename: "notes.txt", URL: "https://example.com/ubuntu/notes.txt", done: true},
+			{ID: "2", Filename: "kubuntu-24.04.iso", URL: "https://example.com/kubuntu-24.04.iso", paused: true},
+			{ID: "3", Filename: "ubuntu.iso", URL: "https://example.com/ubuntu.iso", done: true},
+			{ID: "4", Filename: "debian.iso", URL: "https://example.com/debian.iso"},
+		},
+		searchQuery: "ubuntu",
+	}
+
+	filtered := m.getFilteredDownloads()
+	var ids []string
+	for _, d := range filtered {
+		ids = append(ids, d.ID)
+	}
+	if got, want := strings.Join(ids, ","), "3,2,1"; got != want {
+		t.Fatalf("search results = %s, want %s", got, want)
+	}
+
+	m.searchQuery = "iso paused"
+	filtered = m.getFilteredDownloads()
+	if len(filtered) != 1 || filtered[0].ID != "2" {
+		t.Fatalf("status term should narrow results, got %d results", len(filtered))
+	}
+}
+
+func TestSearchMatchesTagsAndFollowsTagEdits(t *testing.T) {
+	m := RootModel{
+		downloads: []*DownloadModel{
+			{ID: "1", Filename: "report.pdf", Tags: []string{"work"}},
+			{ID: "2", Filename: "movie.mkv"},
+		},
+		searchQuery: "work",
+		list:        NewDownloadList(80, 20),
+	}
+	if filtered := m.getFilteredDownloads(); len(filtered) != 1 || filtered[0].ID != "1" {
+		t.Fatalf("expected only the tagged download, got %d results", len(filtered))
+	}
+
+	updated, _ := m.Update(events.DownloadTaggedMsg{DownloadID: "2", Tags: []string{"work"}})
+	m = updated.(RootModel)
+	if filtered := m.getFilteredDownloads(); len(filtered) != 2 {
+		t.Fatalf("expected the retagged download to match, got %d results", len(filtered))
+	}
+}
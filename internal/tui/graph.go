@@ -160,11 +160,11 @@ func overlayStatsBox(graph string, stats *GraphStats, width, height int) string
 	speedMbps := float64(stats.DownloadSpeed) * 8 / 1000000.0
 	topMbps := float64(stats.DownloadTop) * 8 / 1000000.0
 
-	speedStr := "0 MB/s"
+	speedStr := utils.FormatSpeed(0)
 	if stats.DownloadSpeed > 0 {
 		speedStr = utils.FormatRateLimit(stats.DownloadSpeed)
 	}
-	topStr := "0 MB/s"
+	topStr := utils.FormatSpeed(0)
 	if stats.DownloadTop > 0 {
 		topStr = utils.FormatRateLimit(stats.DownloadTop)
 	}
@@ -28,6 +28,9 @@ func (m *RootModel) addLogEntry(msg string) {
 		m.logEntries = m.logEntries[len(m.logEntries)-100:]
 	}
 
+	if m.downloadLog != nil {
+		return
+	}
 	m.refreshLogViewportContent()
 	// Auto-scroll to bottom
 	m.logViewport.GotoBottom()
@@ -43,8 +46,13 @@ func (m *RootModel) refreshLogViewportContent() {
 	// Render each entry at the viewport width so the content fills the pane.
 	// TruncateTwoLines ensures long messages don't overflow the UI.
 
+	entries := m.logEntries
+	if m.downloadLog != nil {
+		entries = m.downloadLog.lines
+	}
+
 	var wrappedEntries []string
-	for _, entry := range m.logEntries {
+	for _, entry := range entries {
 		wrapped := utils.TruncateTwoLines(entry, width)
 		wrappedEntries = append(wrappedEntries, strings.Split(wrapped, "\n")...)
 	}
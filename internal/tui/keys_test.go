@@ -188,3 +188,7 @@ func TestDynamicKeyMapReloading(t *testing.T) {
 		t.Errorf("Expected dynamic reload to update ToggleHelp key to 'ctrl+x', got %v", toggleHelpKeys)
 	}
 }
+
+func TestOnboardingKeyMap_AllKeysInHelp(t *testing.T) {
+	testKeyMapInHelp(t, "Onboarding", Keys.Onboarding, nil)
+}
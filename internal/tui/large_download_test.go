@@ -0,0 +1,52 @@
+package tui
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/processing"
+)
+
+func TestLargeDownload_AsksBeforeQueueing(t *testing.T) {
+	for _, tt := range []struct {
+		key       rune
+		wantQueue bool
+	}{{'y', true}, {'n', false}} {
+		t.Run(string(tt.key), func(t *testing.T) {
+			m := RootModel{
+				state:     DashboardState,
+				downloads: []*DownloadModel{{ID: "pending-1", URL: "https://example.com/huge.iso", Filename: "huge.iso"}},
+				Service:   &mockService{},
+				Settings:  config.DefaultSettings(),
+				keys:      config.DefaultKeyMap(),
+				list:      NewDownloadList(80, 20),
+			}
+			m.UpdateListItems()
+
+			req := &processing.DownloadRequest{URL: "https://example.com/huge.iso", Filename: "huge.iso", Path: t.TempDir()}
+			updated, _ := m.updateEvents(enqueueErrorMsg{
+				tempID: "pending-1",
+				err:    &types.LargeDownloadError{Size: 300 * types.GB, Threshold: 100 * types.GB},
+				req:    req,
+			})
+			m = updated.(RootModel)
+			if m.state != LargeDownloadConfirmState || m.largeDownload == nil {
+				t.Fatalf("state = %v, want the large download prompt", m.state)
+			}
+			if len(m.downloads) != 0 {
+				t.Fatalf("downloads = %+v, want the optimistic row dropped while asking", m.downloads)
+			}
+
+			updated, _ = m.updateLargeDownloadConfirm(tea.KeyPressMsg{Code: tt.key, Text: string(tt.key)})
+			m = updated.(RootModel)
+			if m.state != DashboardState || m.largeDownload != nil {
+				t.Errorf("state = %v, want dashboard with nothing pending", m.state)
+			}
+			if queued := len(m.downloads) == 1 && m.downloads[0].URL == req.URL; queued != tt.wantQueue {
+				t.Errorf("downloads = %+v, want queued = %v", m.downloads, tt.wantQueue)
+			}
+		})
+	}
+}
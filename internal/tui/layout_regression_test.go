@@ -16,6 +16,7 @@ import (
 
 	"charm.land/bubbles/v2/list"
 	"charm.land/lipgloss/v2"
+	"github.com/SurgeDM/Surge/internal/config"
 	"github.com/SurgeDM/Surge/internal/engine/types"
 	"github.com/SurgeDM/Surge/internal/processing"
 	"github.com/SurgeDM/Surge/internal/tui/components"
@@ -259,32 +260,37 @@ func TestLayout_CategoryManagerHeightNeverExceeds(t *testing.T) {
 // ─────────────────────────────────────────────────────────────
 
 func TestLayout_DelegateRenderNeverExceedsWidth(t *testing.T) {
-	d := newDownloadDelegate()
+	delegates := map[string]downloadDelegate{
+		"detailed": newDownloadDelegate(),
+		"compact":  newDownloadDelegate().withLayout(config.ListLayoutCompact, config.ListColumnNames),
+	}
 
 	widths := []int{200, 120, 80, 60, 45, 30}
-	for _, w := range widths {
-		t.Run(fmt.Sprintf("width_%d", w), func(t *testing.T) {
-			m := list.New([]list.Item{}, d, w, 20)
-			di := DownloadItem{
-				download: &DownloadModel{
-					ID:       "dl-abc",
-					Filename: strings.Repeat("very-long-filename-", 12) + ".iso",
-					URL:      "https://example.com/" + strings.Repeat("a", 400),
-					Total:    1 << 30,
-					Speed:    5 * MB,
-				},
-				spinnerView: "⠋",
-			}
+	for name, d := range delegates {
+		for _, w := range widths {
+			t.Run(fmt.Sprintf("%s_width_%d", name, w), func(t *testing.T) {
+				m := list.New([]list.Item{}, d, w, 20)
+				di := DownloadItem{
+					download: &DownloadModel{
+						ID:       "dl-abc",
+						Filename: strings.Repeat("very-long-filename-", 12) + ".iso",
+						URL:      "https://example.com/" + strings.Repeat("a", 400),
+						Total:    1 << 30,
+						Speed:    5 * MB,
+					},
+					spinnerView: "⠋",
+				}
 
-			var buf bytes.Buffer
-			d.Render(&buf, m, 0, di)
-			rendered := stripANSI.ReplaceAllString(buf.String(), "")
-			for i, line := range strings.Split(rendered, "\n") {
-				if lw := lipgloss.Width(line); lw > w {
-					t.Errorf("line %d: width %d > max %d in rendered delegate at width %d", i, lw, w, w)
+				var buf bytes.Buffer
+				d.Render(&buf, m, 0, di)
+				rendered := stripANSI.ReplaceAllString(buf.String(), "")
+				for i, line := range strings.Split(rendered, "\n") {
+					if lw := lipgloss.Width(line); lw > w {
+						t.Errorf("line %d: width %d > max %d in rendered delegate at width %d", i, lw, w, w)
+					}
 				}
-			}
-		})
+			})
+		}
 	}
 }
 
@@ -696,3 +702,29 @@ func TestLayout_ChunkMapSpaceReclaimedForDetails(t *testing.T) {
 		})
 	}
 }
+
+// ─────────────────────────────────────────────────────────────
+// 14. Detail pane – fits at every size, with many connections
+// ─────────────────────────────────────────────────────────────
+
+func TestLayout_DetailPaneNeverExceedsTerminal(t *testing.T) {
+	for _, tc := range termSizes {
+		label := fmt.Sprintf("detail pane %dx%d", tc.width, tc.height)
+		m := InitialRootModel(1701, "test", nil, processing.NewLifecycleManager(nil, nil), false)
+		m.width, m.height = tc.width, tc.height
+		m.activeTab = TabActive
+		m.detailPane = true
+
+		dm := makeDownloadWithChunks(true)
+		dm.ConnectionSpeeds = make([]float64, 32)
+		for i := range dm.ConnectionSpeeds {
+			dm.ConnectionSpeeds[i] = float64(i+1) * MB
+		}
+		m.downloads = []*DownloadModel{dm}
+		m.UpdateListItems()
+
+		view := m.View()
+		assertNoLineExceedsWidth(t, label, view.Content, tc.width)
+		assertHeightNotExceeded(t, label, view.Content, tc.height)
+	}
+}
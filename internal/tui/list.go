@@ -3,7 +3,11 @@ package tui
 import (
 	"fmt"
 	"io"
+	"time"
 
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/i18n"
 	"github.com/SurgeDM/Surge/internal/tui/colors"
 	"github.com/SurgeDM/Surge/internal/tui/components"
 	"github.com/SurgeDM/Surge/internal/utils"
@@ -34,23 +38,29 @@ func (i DownloadItem) Description() string {
 	var styledStatus string
 	if d.pausing {
 		// Custom "Pausing..." style using existing colors
-		styledStatus = lipgloss.NewStyle().Foreground(colors.StatePaused()).Render(i.spinnerView + " Pausing...")
+		styledStatus = lipgloss.NewStyle().Foreground(colors.StatePaused()).Render(i.spinnerView + " " + i18n.T("status.pausing"))
 	} else if d.resuming {
-		styledStatus = lipgloss.NewStyle().Foreground(colors.StateDownloading()).Render(i.spinnerView + " Resuming...")
+		styledStatus = lipgloss.NewStyle().Foreground(colors.StateDownloading()).Render(i.spinnerView + " " + i18n.T("status.resuming"))
+	} else if label, ok := phaseLabel(d); ok {
+		styledStatus = lipgloss.NewStyle().Foreground(colors.StatePhase()).Render(i.spinnerView + " " + label)
 	} else {
 		status := components.DetermineStatus(d.done, d.paused, d.err != nil, d.Speed, d.Downloaded)
 		styledStatus = status.RenderWithSpinner(i.spinnerView)
 	}
 
-	// Build progress info
+	// Build progress info; while verifying it is how much was checked
+	done := d.Downloaded
+	if d.phase == types.PhaseVerifying {
+		done = d.Checked
+	}
 	pct := 0.0
 	if d.Total > 0 {
-		pct = float64(d.Downloaded) / float64(d.Total) * 100
+		pct = float64(done) / float64(d.Total) * 100
 	}
 
 	// Format: "⬇ Downloading • 45% • 2.5 MB/s • 50 MB / 100 MB"
 	sizeInfo := fmt.Sprintf("%s / %s",
-		utils.ConvertBytesToHumanReadable(d.Downloaded),
+		utils.ConvertBytesToHumanReadable(done),
 		utils.ConvertBytesToHumanReadable(d.Total))
 
 	speedInfo := ""
@@ -63,7 +73,12 @@ func (i DownloadItem) Description() string {
 		}
 	}
 
-	return fmt.Sprintf("%s \u2022 %.0f%%%s \u2022 %s", styledStatus, pct, speedInfo, sizeInfo)
+	finishInfo := ""
+	if at, ok := finishTime(d, time.Now()); ok {
+		finishInfo = " \u2022 " + i18n.T("list.finishes", at)
+	}
+
+	return fmt.Sprintf("%s \u2022 %.0f%%%s \u2022 %s%s", styledStatus, pct, speedInfo, sizeInfo, finishInfo)
 }
 
 func (i DownloadItem) FilterValue() string {
@@ -82,6 +97,11 @@ type downloadDelegate struct {
 	selDescStyle   lipgloss.Style
 	prefixNormal   string
 	prefixSelected string
+
+	// compact draws one line per download with the given columns
+	// (general.list_layout and general.list_columns)
+	compact bool
+	columns []string
 }
 
 type delegateKeyMap struct {
@@ -120,8 +140,26 @@ func newDownloadDelegate() downloadDelegate {
 	}
 }
 
-func (d downloadDelegate) Height() int  { return 2 }
-func (d downloadDelegate) Spacing() int { return 1 }
+// withLayout returns d set up for the given list layout and compact columns.
+func (d downloadDelegate) withLayout(layout string, columns []string) downloadDelegate {
+	d.compact = layout == config.ListLayoutCompact
+	d.columns = columns
+	return d
+}
+
+func (d downloadDelegate) Height() int {
+	if d.compact {
+		return 1
+	}
+	return 2
+}
+
+func (d downloadDelegate) Spacing() int {
+	if d.compact {
+		return 0
+	}
+	return 1
+}
 
 func (d downloadDelegate) Update(msg tea.Msg, m *list.Model) tea.Cmd {
 	return nil
@@ -158,6 +196,11 @@ func (d downloadDelegate) Render(w io.Writer, m list.Model, index int, listItem
 		availableWidth = 1
 	}
 
+	if d.compact {
+		_, _ = io.WriteString(w, prefix+d.compactLine(i, titleStyle, descStyle, availableWidth))
+		return
+	}
+
 	title := utils.TruncateMiddle(i.Title(), availableWidth)
 	description := utils.Truncate(i.Description(), availableWidth)
 
@@ -274,14 +317,7 @@ func (m *RootModel) UpdateListItems() {
 			// Find the download globally
 			for _, d := range m.downloads {
 				if d.ID == targetID {
-					var newTab int
-					if d.done {
-						newTab = TabDone
-					} else if !d.paused && !d.pausing && (d.Speed > 0 || d.Connections > 0 || d.resuming || d.started) {
-						newTab = TabActive
-					} else {
-						newTab = TabQueued
-					}
+					newTab := downloadTab(d)
 
 					// If it belongs to a different tab, switch to it (unless current tab is pinned)
 					if m.pinnedTab == -1 && newTab != -1 && newTab != m.activeTab {
@@ -304,6 +340,44 @@ func (m *RootModel) UpdateListItems() {
 	m.SelectedDownloadID = ""
 }
 
+// visibleRange returns the bounds of the list items on the page currently on
+// screen. Rows outside it are neither rendered nor animated, so large queues
+// cost no more per frame than a page of downloads.
+func (m *RootModel) visibleRange() (start, end int) {
+	return m.list.Paginator.GetSliceBounds(len(m.list.VisibleItems()))
+}
+
+// isVisible reports whether d is on the list page currently on screen.
+func (m *RootModel) isVisible(d *DownloadModel) bool {
+	items := m.list.VisibleItems()
+	start, end := m.visibleRange()
+	for _, item := range items[start:end] {
+		if di, ok := item.(DownloadItem); ok && di.download == d {
+			return true
+		}
+	}
+	return false
+}
+
+// refreshVisibleRows updates the spinner frame of the rows on screen in
+// place, without rebuilding the list.
+func (m *RootModel) refreshVisibleRows() {
+	if m.list.FilterState() != list.Unfiltered {
+		m.UpdateListItems()
+		return
+	}
+	items := m.list.Items()
+	start, end := m.visibleRange()
+	sv := m.spinner.View()
+	for i := start; i < end; i++ {
+		if di, ok := items[i].(DownloadItem); ok {
+			di.spinnerView = sv
+			items[i] = di
+		}
+	}
+	m.list.SetItems(items)
+}
+
 // GetSelectedDownload returns the currently selected download from the list
 func (m *RootModel) GetSelectedDownload() *DownloadModel {
 	if item := m.list.SelectedItem(); item != nil {
@@ -0,0 +1,131 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"charm.land/lipgloss/v2"
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/i18n"
+	"github.com/SurgeDM/Surge/internal/tui/colors"
+	"github.com/SurgeDM/Surge/internal/tui/components"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// compactColumnWidths are the fixed widths of the compact list columns, so
+// values line up from one row to the next.
+var compactColumnWidths = map[string]int{
+	"speed":       10,
+	"eta":         12,
+	"finish":      13,
+	"size":        9,
+	"connections": 7,
+}
+
+// Compact rows keep at least this much room for the filename and drop
+// columns from the right to make it.
+const (
+	compactMinNameWidth = 12
+	compactPercentWidth = 4
+)
+
+// compactLine renders a download as a single row: status icon, name,
+// percentage and the selected columns, fitted to width.
+func (d downloadDelegate) compactLine(i DownloadItem, titleStyle, descStyle lipgloss.Style, width int) string {
+	dl := i.download
+
+	var icon string
+	switch {
+	case dl.pausing:
+		icon = lipgloss.NewStyle().Foreground(colors.StatePaused()).Render(i.spinnerView)
+	case dl.resuming:
+		icon = lipgloss.NewStyle().Foreground(colors.StateDownloading()).Render(i.spinnerView)
+	case phaseBusy(dl):
+		icon = lipgloss.NewStyle().Foreground(colors.StatePhase()).Render(i.spinnerView)
+	default:
+		icon = components.DetermineStatus(dl.done, dl.paused, dl.err != nil, dl.Speed, dl.Downloaded).RenderIcon()
+	}
+
+	pct := 0
+	if dl.Total > 0 && dl.phase == types.PhaseVerifying {
+		pct = int(dl.Checked * 100 / dl.Total)
+	} else if dl.Total > 0 {
+		pct = int(dl.Downloaded * 100 / dl.Total)
+	}
+
+	// Columns go from the right until the name would get too narrow
+	fixed := lipgloss.Width(icon) + 1 + 1 + compactPercentWidth
+	columns := d.columns
+	for len(columns) > 0 {
+		need := fixed
+		for _, col := range columns {
+			need += 1 + compactColumnWidths[col]
+		}
+		if width-need >= compactMinNameWidth {
+			fixed = need
+			break
+		}
+		columns = columns[:len(columns)-1]
+	}
+	nameWidth := max(1, width-fixed)
+
+	cell := func(s string, w int) string {
+		return lipgloss.NewStyle().Width(w).MaxWidth(w).Align(lipgloss.Right).Render(utils.Truncate(s, w))
+	}
+
+	var b strings.Builder
+	b.WriteString(icon + " ")
+	b.WriteString(titleStyle.Width(nameWidth).MaxWidth(nameWidth).Render(utils.TruncateMiddle(i.Title(), nameWidth)))
+	b.WriteString(" ")
+	b.WriteString(descStyle.Render(cell(fmt.Sprintf("%d%%", pct), compactPercentWidth)))
+	for _, col := range columns {
+		b.WriteString(" ")
+		b.WriteString(descStyle.Render(cell(compactColumn(dl, col), compactColumnWidths[col])))
+	}
+	return b.String()
+}
+
+// compactColumn returns the value of one compact list column, or "-" when it
+// does not apply to the download's state.
+func compactColumn(d *DownloadModel, col string) string {
+	active := !d.done && !d.paused && !d.pausing && d.Speed > 0
+	switch col {
+	case "speed":
+		if active {
+			return utils.FormatSpeed(d.Speed)
+		}
+	case "eta":
+		if left, ok := timeLeft(d); ok {
+			return i18n.T("list.eta", formatDurationForUI(left))
+		}
+	case "finish":
+		if at, ok := finishTime(d, time.Now()); ok {
+			return "~" + at
+		}
+	case "size":
+		if d.Total > 0 {
+			return utils.ConvertBytesToHumanReadable(d.Total)
+		}
+	case "connections":
+		if !d.done && d.Connections > 0 {
+			return i18n.T("list.connections", d.Connections)
+		}
+	}
+	return "-"
+}
+
+// applyListLayout sets the download list delegate for the layout and columns
+// chosen in settings.
+func (m *RootModel) applyListLayout() {
+	if m.Settings == nil {
+		return
+	}
+	layout := config.Resolve[string](m.Settings.General.ListLayout)
+	columns, err := config.ParseListColumns(config.Resolve[string](m.Settings.General.ListColumns))
+	if err != nil {
+		columns, _ = config.ParseListColumns(m.Settings.General.ListColumns.DefaultValue.(string))
+	}
+	m.list.SetDelegate(newDownloadDelegate().withLayout(layout, columns))
+}
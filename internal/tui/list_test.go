@@ -2,11 +2,17 @@ package tui
 
 import (
 	"bytes"
+	"fmt"
 	"regexp"
 	"strings"
 	"testing"
 
 	"charm.land/bubbles/v2/list"
+	"charm.land/bubbles/v2/spinner"
+	tea "charm.land/bubbletea/v2"
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/processing"
 )
 
 var testAnsiEscapeRE = regexp.MustCompile(`\x1b\[[0-9;]*m`)
@@ -83,3 +89,211 @@ func BenchmarkDownloadDelegateRender(b *testing.B) {
 		d.Render(&buf, m, 0, di)
 	}
 }
+
+func TestDownloadDelegate_CompactRender(t *testing.T) {
+	d := newDownloadDelegate().withLayout(config.ListLayoutCompact, []string{"speed", "size", "connections"})
+	if d.Height() != 1 || d.Spacing() != 0 {
+		t.Fatalf("compact delegate height/spacing = %d/%d, want 1/0", d.Height(), d.Spacing())
+	}
+
+	di := DownloadItem{
+		download: &DownloadModel{
+			ID:          "1",
+			Filename:    "ubuntu-22.04.iso",
+			Total:       1000 * MB,
+			Downloaded:  250 * MB,
+			Speed:       2 * MB,
+			Connections: 8,
+		},
+	}
+
+	render := func(width int) string {
+		var buf bytes.Buffer
+		d.Render(&buf, list.New([]list.Item{}, d, width, 20), 1, di)
+		return testAnsiEscapeRE.ReplaceAllString(buf.String(), "")
+	}
+
+	line := render(120)
+	if strings.Contains(line, "\n") {
+		t.Fatalf("compact row spans several lines: %q", line)
+	}
+	for _, want := range []string{"ubuntu-22.04.iso", "25%", "2.1 MB/s", "1.0 GB", "8 conn"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("compact row %q does not contain %q", line, want)
+		}
+	}
+
+	// Narrow rows drop columns from the right before squeezing the name
+	narrow := render(44)
+	if strings.Contains(narrow, "8 conn") || !strings.Contains(narrow, "2.1 MB/s") {
+		t.Errorf("expected narrow row to keep speed and drop connections: %q", narrow)
+	}
+}
+
+func TestDashboard_ToggleLayoutPersists(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tmpDir)
+	t.Setenv("APPDATA", tmpDir)
+
+	m := RootModel{
+		state:     DashboardState,
+		keys:      config.DefaultKeyMap(),
+		Settings:  config.DefaultSettings(),
+		list:      NewDownloadList(80, 20),
+		pinnedTab: -1,
+	}
+	updated, _ := m.Update(tea.KeyPressMsg{Code: 'v', Text: "v"})
+	m = updated.(RootModel)
+
+	if got := config.Resolve[string](m.Settings.General.ListLayout); got != config.ListLayoutCompact {
+		t.Fatalf("list_layout = %q after toggle, want compact", got)
+	}
+	saved, err := config.LoadSettings()
+	if err != nil {
+		t.Fatalf("LoadSettings: %v", err)
+	}
+	if got := config.Resolve[string](saved.General.ListLayout); got != config.ListLayoutCompact {
+		t.Errorf("saved list_layout = %q, want compact", got)
+	}
+}
+
+func TestProgress_OnlyVisibleRowsAnimate(t *testing.T) {
+	m := RootModel{
+		state:     DashboardState,
+		keys:      config.DefaultKeyMap(),
+		Settings:  config.DefaultSettings(),
+		list:      NewDownloadList(120, 12),
+		pinnedTab: -1,
+		activeTab: TabActive,
+	}
+	for i := range 100 {
+		d := NewDownloadModel(fmt.Sprintf("id-%d", i), "https://example.com/file", fmt.Sprintf("file-%d.bin", i), 1000)
+		d.Speed = MB
+		d.started = true
+		m.downloads = append(m.downloads, d)
+	}
+	m.UpdateListItems()
+
+	if cmd := m.processProgressMsg(events.ProgressMsg{DownloadID: "id-0", Downloaded: 500, Total: 1000, Speed: MB}); cmd == nil {
+		t.Error("expected the on-screen row to animate its progress bar")
+	}
+	if cmd := m.processProgressMsg(events.ProgressMsg{DownloadID: "id-99", Downloaded: 500, Total: 1000, Speed: MB}); cmd != nil {
+		t.Error("expected no animation for a row off screen")
+	}
+	if got := m.FindDownloadByID("id-99").Downloaded; got != 500 {
+		t.Errorf("off-screen download progress = %d, want 500", got)
+	}
+}
+
+// newLargeQueueModel returns a dashboard with n downloads, all active or all
+// queued, for benchmarking how the TUI scales with the queue length.
+func newLargeQueueModel(b *testing.B, n int, active bool) RootModel {
+	b.Helper()
+	m := RootModel{
+		state:     DashboardState,
+		keys:      config.DefaultKeyMap(),
+		Settings:  config.DefaultSettings(),
+		list:      NewDownloadList(120, 40),
+		spinner:   spinner.New(),
+		pinnedTab: -1,
+		width:     160,
+		height:    50,
+	}
+	for i := range n {
+		d := NewDownloadModel(fmt.Sprintf("id-%d", i), "https://example.com/file", fmt.Sprintf("file-%d.bin", i), 1<<30)
+		if active {
+			d.Speed = MB
+			d.Connections = 4
+			d.started = true
+		}
+		m.downloads = append(m.downloads, d)
+	}
+	m.activeTab = TabActive
+	if !active {
+		m.activeTab = TabQueued
+	}
+	m.UpdateListItems()
+	return m
+}
+
+// queueSizes are the queue lengths the large-queue benchmarks run at. Only
+// rows on screen are rebuilt, animated and rendered, so the cost per frame
+// should barely grow between them.
+var queueSizes = []int{50, 500}
+
+func BenchmarkBatchProgress(b *testing.B) {
+	for _, n := range queueSizes {
+		b.Run(fmt.Sprintf("downloads=%d", n), func(b *testing.B) {
+			m := newLargeQueueModel(b, n, true)
+			batch := make(events.BatchProgressMsg, len(m.downloads))
+			for i, d := range m.downloads {
+				batch[i] = events.ProgressMsg{DownloadID: d.ID, Total: d.Total, Speed: MB, ActiveConnections: 4}
+			}
+
+			var model tea.Model = m
+			b.ReportAllocs()
+			for i := 0; b.Loop(); i++ {
+				for j := range batch {
+					batch[j].Downloaded = int64(i%1024) * MB
+				}
+				model, _ = model.Update(batch)
+			}
+		})
+	}
+}
+
+func BenchmarkSpinnerTick(b *testing.B) {
+	for _, n := range queueSizes {
+		b.Run(fmt.Sprintf("downloads=%d", n), func(b *testing.B) {
+			var model tea.Model = newLargeQueueModel(b, n, false)
+			b.ReportAllocs()
+			for b.Loop() {
+				model, _ = model.Update(model.(RootModel).spinner.Tick())
+			}
+		})
+	}
+}
+
+func BenchmarkDashboardView(b *testing.B) {
+	for _, n := range queueSizes {
+		b.Run(fmt.Sprintf("downloads=%d", n), func(b *testing.B) {
+			m := newLargeQueueModel(b, n, true)
+			b.ReportAllocs()
+			for b.Loop() {
+				_ = m.View()
+			}
+		})
+	}
+}
+
+func TestDashboard_DetailPaneFollowsSelection(t *testing.T) {
+	m := InitialRootModel(1701, "test", nil, processing.NewLifecycleManager(nil, nil), false)
+	m.width, m.height = 160, 50
+	m.activeTab = TabActive
+
+	first := makeDownloadWithChunks(false)
+	first.ConnectionSpeeds = []float64{2 * MB, 1 * MB}
+	second := NewDownloadModel("dl-second", "https://example.com/second.bin", "second.bin", 100*MB)
+	second.Speed = MB
+	m.downloads = []*DownloadModel{first, second}
+	m.UpdateListItems()
+
+	updated, _ := m.Update(tea.KeyPressMsg{Code: 'd', Text: "d"})
+	m = updated.(RootModel)
+	if !m.detailPane {
+		t.Fatal("expected d to open the detail pane")
+	}
+
+	view := testAnsiEscapeRE.ReplaceAllString(m.View().Content, "")
+	for _, want := range []string{"Connections (2)", "#1", "#2", "file.iso"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("detail pane missing %q", want)
+		}
+	}
+
+	m.list.Select(1)
+	view = testAnsiEscapeRE.ReplaceAllString(m.View().Content, "")
+	if !strings.Contains(view, "second.bin") || !strings.Contains(view, "No open connections") {
+		t.Error("expected the detail pane to follow the selection")
+	}
+}
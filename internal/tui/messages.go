@@ -1,6 +1,9 @@
 package tui
 
 import (
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/processing"
+	"github.com/SurgeDM/Surge/internal/utils"
 	"github.com/SurgeDM/Surge/internal/version"
 )
 
@@ -24,9 +27,36 @@ type enqueueSuccessMsg struct {
 	filename string
 }
 
+// downloadLogMsg carries the lines fetched for one download's log.
+type downloadLogMsg struct {
+	id    string
+	name  string
+	lines []utils.LogLine
+	err   error
+}
+
+// downloadLogView is a download's log as shown in the log pane.
+type downloadLogView struct {
+	id    string
+	name  string
+	lines []string
+}
+
 type enqueueErrorMsg struct {
 	tempID string
 	err    error
+	// req and requestID let a download that needs confirming be sent again.
+	req       *processing.DownloadRequest
+	requestID string
+}
+
+// largeDownloadRequest is an enqueue refused for being larger than the
+// confirm_size_threshold setting, kept to be sent again once confirmed.
+type largeDownloadRequest struct {
+	req       processing.DownloadRequest
+	requestID string
+	size      int64
+	threshold int64
 }
 
 type resumeResultMsg struct {
@@ -37,3 +67,7 @@ type resumeResultMsg struct {
 // startupConfigWarningMsg carries config validation warnings to display in the
 // activity log during Init, after the viewport is sized and ready.
 type startupConfigWarningMsg []string
+
+// downloadsReloadedMsg carries a fresh download list after the event stream
+// reported that missed events could not be replayed.
+type downloadsReloadedMsg []types.DownloadStatus
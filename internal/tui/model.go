@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -17,10 +18,12 @@ import (
 	"charm.land/bubbles/v2/viewport"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/colorprofile"
 
 	"github.com/SurgeDM/Surge/internal/config"
 	"github.com/SurgeDM/Surge/internal/core"
 	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/state"
 	"github.com/SurgeDM/Surge/internal/engine/types"
 	"github.com/SurgeDM/Surge/internal/processing"
 	"github.com/SurgeDM/Surge/internal/tui/colors"
@@ -35,7 +38,21 @@ func InitializeTUI() {
 	components.InitializeStatusCache()
 }
 
+// ProgramOptions returns the bubbletea options for m. Accessible mode redraws
+// at most AccessibleFPS times a second and without color, keeping bold and
+// reverse video for emphasis.
+func (m RootModel) ProgramOptions() []tea.ProgramOption {
+	if !m.Accessible {
+		return nil
+	}
+	return []tea.ProgramOption{
+		tea.WithFPS(AccessibleFPS),
+		tea.WithColorProfile(colorprofile.Ascii),
+	}
+}
+
 // IsTestMode is set by tests to avoid blocking calls to terminal interrogation
+// and writes to the user's state directory
 var IsTestMode bool
 
 type UIState int // Defines UIState as int to be used in rootModel
@@ -43,7 +60,6 @@ type UIState int // Defines UIState as int to be used in rootModel
 const (
 	DashboardState UIState = iota
 	InputState
-	DetailState
 	FilePickerState
 	DuplicateWarningState
 	SearchState
@@ -63,6 +79,11 @@ const (
 	CategoryResetConfirmState
 	SpeedLimitsState
 	PurgeConfirmState
+	ResumeMismatchState
+	OnboardingState
+	LargeDownloadConfirmState
+	QRCodeState
+	SpeedHistoryState
 )
 
 type FilePickerOrigin int
@@ -83,17 +104,20 @@ const (
 )
 
 type DownloadModel struct {
-	ID            string
-	URL           string
-	Filename      string
-	FilenameLower string
-	Destination   string // Full path to the destination file
-	Total         int64
-	Downloaded    int64
-	Speed         float64
-	Connections   int
-	RateLimit     int64 // Speed limit in bytes/sec
-	RateLimitSet  bool  // Whether RateLimit is an explicit per-download override
+	ID               string
+	URL              string
+	Filename         string
+	FilenameLower    string
+	Destination      string // Full path to the destination file
+	Total            int64
+	Downloaded       int64
+	Speed            float64
+	Connections      int
+	ConnectionSpeeds []float64 // Bytes/sec per open connection, for the detail pane
+	RateLimit        int64     // Speed limit in bytes/sec
+	RateLimitSet     bool      // Whether RateLimit is an explicit per-download override
+	Tags             []string
+	ResponseHeaders  map[string]string // Notable headers the server answered the probe with
 
 	StartTime time.Time
 	Elapsed   time.Duration
@@ -111,6 +135,9 @@ type DownloadModel struct {
 	paused   bool
 	pausing  bool // UI state: transitioning to pause
 	resuming bool // UI state: waiting for async resume
+
+	phase   types.Phase // What the engine last reported the download busy with
+	Checked int64       // Bytes of the finished file checked so far while verifying
 }
 
 type RootModel struct {
@@ -122,7 +149,22 @@ type RootModel struct {
 	pinnedTab     int // -1=None, 0=Queued, 1=Active, 2=Done
 	inputs        []textinput.Model
 	focusedInput  int
+	inputError    string   // Why the add-download form was not submitted
+	recentDirs    []string // Download directories picked recently, newest first
 	purgeTargetID string
+	// restartTargetID is the download whose resume no longer matched the
+	// remote file, offered for a restart from the beginning.
+	restartTargetID string
+	// largeDownload is the download waiting to be confirmed because its file
+	// is larger than the confirm_size_threshold setting.
+	largeDownload *largeDownloadRequest
+	// qrShares are what the QR code modal can show, and qrIndex the one it
+	// shows.
+	qrShares []qrShare
+	qrIndex  int
+	// speedDay is the last day of speed history shown by the speed history
+	// view, unlike SpeedHistory which only covers this session.
+	speedDay []state.SpeedSample
 	// Service Interface
 	// Core
 	Service      core.DownloadService
@@ -143,11 +185,14 @@ type RootModel struct {
 
 	// Duplicate detection
 	pendingURL           string // URL pending confirmation
+	pendingID            string // ID of the API request pending confirmation, if any
 	pendingPath          string // Path pending confirmation
 	pendingIsDefaultPath bool
 	pendingFilename      string   // Filename pending confirmation
 	pendingMirrors       []string // Mirrors pending confirmation
 	pendingHeaders       map[string]string
+	pendingTLS           types.TLSOptions
+	pendingRequest       types.RequestOptions
 	duplicateInfo        string // Info about the duplicate
 
 	// Graph Data
@@ -158,6 +203,9 @@ type RootModel struct {
 	logViewport viewport.Model // Scrollable log viewport
 	logEntries  []string       // Log entries for download events
 	logFocused  bool           // Whether the log viewport is focused
+	// downloadLog is the log of one download, shown in place of the
+	// activity log while set
+	downloadLog *downloadLogView
 
 	// Settings
 	Settings              *config.Settings // Application settings
@@ -171,6 +219,10 @@ type RootModel struct {
 	settingsError         string           // Current validation error in settings
 	ExtensionTokenCopied  bool             // Flash message for "Token Copied!"
 
+	// First-run setup wizard
+	onboardingStep  int
+	onboardingError string
+
 	// Speed Limits Modal
 	speedLimitsCursor    int
 	speedLimitsIsEditing bool
@@ -220,6 +272,17 @@ type RootModel struct {
 	ServerHost string
 	IsRemote   bool
 
+	// Accessible renders plain text for screen readers (general.accessible)
+	Accessible bool
+
+	// Pacer forwards service events, holding progress back while the
+	// terminal is unfocused (blurred) or too small for the dashboard
+	Pacer   *ProgressPacer
+	blurred bool
+
+	// detailPane swaps the right column for a live view of the selection
+	detailPane bool
+
 	// Update check
 	UpdateInfo     *version.UpdateInfo // Update information (nil if no update available)
 	CurrentVersion string              // Current version of Surge
@@ -232,6 +295,7 @@ type RootModel struct {
 	enqueueCtx       context.Context
 	cancelEnqueue    context.CancelFunc
 	shuttingDown     bool
+	draining         bool // drain mode: running downloads finish, queued ones wait
 	RestartRequested bool // Flag to signal process re-exec after TUI shutdown
 
 	ToggleServiceFunc func(bool) error
@@ -261,8 +325,10 @@ func NewDownloadModel(id string, url string, filename string, total int64) *Down
 
 func InitialRootModel(serverPort int, currentVersion string, service core.DownloadService, orchestrator *processing.LifecycleManager, noResume bool, currentCommit ...string) RootModel {
 	initialDarkBackground := true
+	var recentDirs []string
 	if !IsTestMode {
 		initialDarkBackground = lipgloss.HasDarkBackground(os.Stdin, os.Stdout)
+		recentDirs = config.LoadRecentDirs()
 	}
 	commitValue := "unknown"
 	if len(currentCommit) > 0 {
@@ -294,6 +360,26 @@ func InitialRootModel(serverPort int, currentVersion string, service core.Downlo
 	mirrorsInput.SetWidth(InputWidth)
 	mirrorsInput.Prompt = ""
 
+	checksumInput := textinput.New()
+	checksumInput.Placeholder = "sha256:... (optional)"
+	checksumInput.SetWidth(InputWidth)
+	checksumInput.Prompt = ""
+
+	headersInput := textinput.New()
+	headersInput.Placeholder = "Authorization: Bearer ...; Referer: ..."
+	headersInput.SetWidth(InputWidth)
+	headersInput.Prompt = ""
+
+	connectionsInput := textinput.New()
+	connectionsInput.Placeholder = "(from settings)"
+	connectionsInput.SetWidth(InputWidth)
+	connectionsInput.Prompt = ""
+
+	priorityInput := textinput.New()
+	priorityInput.Placeholder = "normal or low"
+	priorityInput.SetWidth(InputWidth)
+	priorityInput.Prompt = ""
+
 	pwd, _ := os.Getwd()
 
 	// Initialize file picker for directory selection - default to Downloads folder
@@ -366,7 +452,7 @@ func InitialRootModel(serverPort int, currentVersion string, service core.Downlo
 					dm.done = true
 					dm.started = true
 					dm.progress.SetPercent(1.0)
-				case "error":
+				case "error", "failed":
 					dm.done = true
 					dm.started = true
 				case "pausing":
@@ -381,12 +467,18 @@ func InitialRootModel(serverPort int, currentVersion string, service core.Downlo
 					}
 					dm.started = true
 				case "queued":
+					if s.Phase == types.PhaseWaitingForNetwork {
+						// Starts by itself once the network is back
+						dm.phase = s.Phase
+						break
+					}
 					// Always resume queued items
 					dm.resuming = true
 					dm.paused = true // Will update when resume event received
 					dm.started = false
 				case "downloading":
 					dm.started = true
+					dm.phase = s.Phase
 				}
 
 				if s.TotalSize > 0 {
@@ -402,6 +494,8 @@ func InitialRootModel(serverPort int, currentVersion string, service core.Downlo
 				}
 				dm.RateLimit = s.RateLimit
 				dm.RateLimitSet = s.RateLimitSet
+				dm.Tags = s.Tags
+				dm.ResponseHeaders = s.ResponseHeaders
 
 				downloads = append(downloads, dm)
 			}
@@ -467,7 +561,7 @@ func InitialRootModel(serverPort int, currentVersion string, service core.Downlo
 	m := RootModel{
 		downloads:             downloads,
 		pinnedTab:             -1,
-		inputs:                []textinput.Model{urlInput, mirrorsInput, pathInput, filenameInput},
+		inputs:                []textinput.Model{urlInput, mirrorsInput, pathInput, filenameInput, checksumInput, headersInput, connectionsInput, priorityInput},
 		state:                 DashboardState,
 		filepicker:            fp,
 		help:                  helpModel,
@@ -486,11 +580,14 @@ func InitialRootModel(serverPort int, currentVersion string, service core.Downlo
 		SettingsInput:         settingsInput,
 		searchInput:           searchInput,
 		urlUpdateInput:        urlUpdateInput,
+		recentDirs:            recentDirs,
 		catMgrInputs:          [4]textinput.Model{catNameInput, catDescInput, catPatternInput, catPathInput},
 		keys:                  keys,
 		lastKeyMapModTime:     keyMapModTime,
 		lastConfigCheckTime:   time.Now(),
 		ServerPort:            serverPort,
+		Accessible:            config.Resolve[bool](settings.General.Accessible),
+		Pacer:                 NewProgressPacer(),
 		CurrentVersion:        currentVersion,
 		CurrentCommit:         commitValue,
 		InitialDarkBackground: initialDarkBackground,
@@ -499,6 +596,10 @@ func InitialRootModel(serverPort int, currentVersion string, service core.Downlo
 		spinner:               s,
 	}
 
+	if drainer, ok := service.(interface{ Draining() bool }); ok {
+		m.draining = drainer.Draining()
+	}
+
 	InitAuthToken() // Cache auth token for TUI to avoid per-frame disk I/O
 
 	m.refreshThemeCaches()
@@ -585,55 +686,104 @@ func (m *RootModel) FindDownloadByID(id string) *DownloadModel {
 	return nil
 }
 
-// Helper to get downloads for the current tab
+// downloadTab returns the dashboard tab a download is listed under.
+func downloadTab(d *DownloadModel) int {
+	switch {
+	case d.done:
+		return TabDone
+	case !d.paused && !d.pausing && (d.Speed > 0 || d.Connections > 0 || d.resuming || d.started):
+		return TabActive
+	default:
+		return TabQueued
+	}
+}
+
+// Helper to get downloads for the current tab. While a search query is set,
+// matches come from every tab, best match first.
 func (m RootModel) getFilteredDownloads() []*DownloadModel {
-	var filtered []*DownloadModel
-	searchLower := strings.ToLower(m.searchQuery)
+	if m.searchQuery != "" {
+		return m.searchDownloads()
+	}
 
+	var filtered []*DownloadModel
 	for _, d := range m.downloads {
-		// Apply tab filter first
-		switch m.activeTab {
-		case TabQueued:
-			// Queued includes paused downloads and anything not currently active or done
-			if d.done || (!d.paused && !d.pausing && (d.Speed > 0 || d.Connections > 0 || d.resuming || d.started)) {
-				continue
-			}
-		case TabActive:
-			// Active excludes paused downloads and anything without current activity
-			if d.done || d.paused || d.pausing || (d.Speed == 0 && d.Connections == 0 && !d.resuming && !d.started) {
-				continue
-			}
-		case TabDone:
-			if !d.done {
-				continue
-			}
+		if downloadTab(d) != m.activeTab {
+			continue
 		}
 
 		// Apply dashboard category filter.
-		if m.categoryFilter != "" && m.Settings != nil && config.Resolve[bool](m.Settings.Categories.CategoryEnabled) {
+		if m.categoryFilter != "" && m.categoriesEnabled() {
 			if !m.matchesCategoryFilter(d) {
 				continue
 			}
 		}
 
-		// Apply search filter if query is set
-		if m.searchQuery != "" {
-			if !strings.Contains(d.FilenameLower, searchLower) {
+		filtered = append(filtered, d)
+	}
+	return filtered
+}
+
+// searchDownloads matches the search query against the URL, filename,
+// category, status and tags of active and finished downloads alike, ranked the
+// same way as the /search API.
+func (m RootModel) searchDownloads() []*DownloadModel {
+	type match struct {
+		d     *DownloadModel
+		score int
+	}
+	var matches []match
+	for _, d := range m.downloads {
+		category := ""
+		if m.categoriesEnabled() {
+			if m.categoryFilter != "" && !m.matchesCategoryFilter(d) {
 				continue
 			}
+			category = m.categoryOf(d)
 		}
+		score := core.SearchScore(m.searchQuery, core.SearchFields{
+			ID:       d.ID,
+			Filename: d.Filename,
+			URL:      d.URL,
+			Category: category,
+			Status:   components.DetermineStatus(d.done, d.paused, d.err != nil, d.Speed, d.Downloaded).Label(),
+			Tags:     d.Tags,
+		})
+		if score > 0 {
+			matches = append(matches, match{d: d, score: score})
+		}
+	}
 
-		filtered = append(filtered, d)
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return !matches[i].d.done && matches[j].d.done
+	})
+	filtered := make([]*DownloadModel, len(matches))
+	for i, mt := range matches {
+		filtered[i] = mt.d
 	}
 	return filtered
 }
 
+func (m RootModel) categoriesEnabled() bool {
+	return m.Settings != nil && config.Resolve[bool](m.Settings.Categories.CategoryEnabled)
+}
+
 func (m RootModel) matchesCategoryFilter(d *DownloadModel) bool {
 	filter := m.categoryFilter
 	if filter == "" {
 		return true
 	}
+	if filter == "Uncategorized" {
+		return m.categoryOf(d) == ""
+	}
+	return m.categoryOf(d) == filter
+}
 
+// categoryOf returns the name of the category d falls into, or empty when it
+// matches none.
+func (m RootModel) categoryOf(d *DownloadModel) string {
 	filename := strings.TrimSpace(d.Filename)
 	if filename == "" || filename == "Queued" {
 		if d.Destination != "" {
@@ -647,11 +797,10 @@ func (m RootModel) matchesCategoryFilter(d *DownloadModel) bool {
 	}
 
 	cat, err := config.GetCategoryForFile(filename, m.Settings.Categories.Categories)
-	if filter == "Uncategorized" {
-		return err != nil || cat == nil
+	if err != nil || cat == nil {
+		return ""
 	}
-
-	return err == nil && cat != nil && cat.Name == filter
+	return cat.Name
 }
 
 // newFilepicker creates a fresh filepicker instance with consistent settings.
@@ -706,6 +855,7 @@ func (m *RootModel) refreshThemeCaches() {
 	m.help.Styles.FullKey = lipgloss.NewStyle().Foreground(colors.Pink())
 	m.help.Styles.FullDesc = lipgloss.NewStyle().Foreground(colors.LightGray())
 	applyListTheme(&m.list)
+	m.applyListLayout()
 	applyFilepickerTheme(&m.filepicker)
 	m.logoCache = ""
 	// Rebuild progress bar colors for all existing downloads so the gradient
@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"github.com/SurgeDM/Surge/internal/config"
+)
+
+func newOnboardingTestModel(t *testing.T) RootModel {
+	t.Helper()
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tmpDir)
+	t.Setenv("APPDATA", tmpDir)
+	if !config.IsFirstRun() {
+		t.Fatal("expected a fresh config dir to be a first run")
+	}
+
+	m := RootModel{
+		keys:          config.DefaultKeyMap(),
+		Settings:      config.DefaultSettings(),
+		SettingsInput: textinput.New(),
+	}
+	return m.WithOnboarding()
+}
+
+func TestOnboarding_WalkthroughSavesSettings(t *testing.T) {
+	m := newOnboardingTestModel(t)
+	enter := tea.KeyPressMsg{Code: tea.KeyEnter}
+	right := tea.KeyPressMsg{Code: tea.KeyRight}
+	press := func(msg tea.KeyPressMsg) {
+		t.Helper()
+		updated, _ := m.Update(msg)
+		m = updated.(RootModel)
+	}
+
+	dir := filepath.Join(t.TempDir(), "new", "downloads")
+	m.SettingsInput.SetValue(dir)
+	press(enter)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Fatalf("download dir was not created: %v", err)
+	}
+
+	// An out-of-range answer keeps the wizard on the same step
+	m.SettingsInput.SetValue("500")
+	press(enter)
+	if m.onboardingStep != 1 || m.onboardingError == "" {
+		t.Fatalf("expected validation error on step 1, got step %d error %q", m.onboardingStep, m.onboardingError)
+	}
+	m.SettingsInput.SetValue("8")
+	press(enter)
+
+	press(right) // theme: system -> light
+	press(enter)
+	press(right) // clipboard monitor off
+	press(enter)
+	press(right) // API server off
+	press(enter)
+
+	if m.state != DashboardState {
+		t.Fatalf("expected dashboard after setup, got state %v", m.state)
+	}
+	if config.IsFirstRun() {
+		t.Fatal("setup did not write settings")
+	}
+
+	saved, err := config.LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := config.Resolve[string](saved.General.DefaultDownloadDir); got != dir {
+		t.Errorf("download dir = %q, want %q", got, dir)
+	}
+	if got := config.Resolve[int](saved.Network.MaxConnectionsPerDownload); got != 8 {
+		t.Errorf("max connections = %d, want 8", got)
+	}
+	if got := config.Resolve[int](saved.General.Theme); got != config.ThemeLight {
+		t.Errorf("theme = %d, want light", got)
+	}
+	if config.Resolve[bool](saved.General.ClipboardMonitor) {
+		t.Error("clipboard monitor should be off")
+	}
+	if config.Resolve[bool](saved.Extension.APIServer) {
+		t.Error("API server should be off")
+	}
+}
+
+func TestOnboarding_SkipSavesDefaults(t *testing.T) {
+	m := newOnboardingTestModel(t)
+	m.width, m.height = 120, 40
+	if !strings.Contains(m.viewOnboarding(), "Setup 1/") {
+		t.Fatal("expected the wizard to render its first step")
+	}
+
+	updated, _ := m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	m = updated.(RootModel)
+
+	if m.state != DashboardState {
+		t.Fatalf("expected dashboard after skipping, got state %v", m.state)
+	}
+	if config.IsFirstRun() {
+		t.Fatal("skipping setup should still save settings so it is not shown again")
+	}
+}
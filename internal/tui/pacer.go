@@ -0,0 +1,154 @@
+package tui
+
+import (
+	"sync/atomic"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/SurgeDM/Surge/internal/engine/events"
+)
+
+// Progress pacing. Every message makes the program redraw, so progress is
+// held back and handed over in batches: downloads that are transferring at
+// most every ActivePollInterval, the rest at most every IdlePollInterval.
+// While the terminal is unfocused or too small to show the dashboard, all
+// downloads use IdlePollInterval.
+const (
+	ActivePollInterval = 250 * time.Millisecond
+	IdlePollInterval   = 2 * time.Second
+)
+
+// ProgressPacer forwards the service event stream to the program, coalescing
+// progress per download. State changes are passed on at once, after any
+// progress still held, so each download's updates stay in order.
+type ProgressPacer struct {
+	active time.Duration
+	idle   time.Duration
+
+	slow atomic.Bool
+	wake chan struct{}
+}
+
+// NewProgressPacer returns a pacer using ActivePollInterval and
+// IdlePollInterval.
+func NewProgressPacer() *ProgressPacer {
+	return &ProgressPacer{
+		active: ActivePollInterval,
+		idle:   IdlePollInterval,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// SetIdle switches every download to the idle interval, for when nobody is
+// looking at the dashboard. Leaving idle hands over held progress at once.
+func (p *ProgressPacer) SetIdle(idle bool) {
+	if p == nil || p.slow.Swap(idle) == idle || idle {
+		return
+	}
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Idle reports whether the pacer is holding all progress to the idle interval.
+func (p *ProgressPacer) Idle() bool {
+	return p != nil && p.slow.Load()
+}
+
+// Forward reads stream until it is closed and passes its messages to send.
+func (p *ProgressPacer) Forward(stream <-chan any, send func(tea.Msg)) {
+	pending := make(map[string]events.ProgressMsg)
+	var order []string
+	lastSent := make(map[string]time.Time)
+
+	hold := func(msg events.ProgressMsg) {
+		if _, ok := pending[msg.DownloadID]; !ok {
+			order = append(order, msg.DownloadID)
+		}
+		pending[msg.DownloadID] = msg
+	}
+
+	// flush sends the held progress that is due, or all of it when all is set
+	flush := func(now time.Time, all bool) {
+		var batch events.BatchProgressMsg
+		kept := order[:0]
+		for _, id := range order {
+			msg := pending[id]
+			interval := p.idle
+			if msg.Speed > 0 && !p.slow.Load() {
+				interval = p.active
+			}
+			if !all && now.Sub(lastSent[id]) < interval {
+				kept = append(kept, id)
+				continue
+			}
+			batch = append(batch, msg)
+			delete(pending, id)
+			lastSent[id] = now
+		}
+		order = kept
+		if len(batch) > 0 {
+			send(batch)
+		}
+	}
+
+	// The ticker only runs while progress is held, so an idle queue costs
+	// nothing
+	var ticker *time.Ticker
+	var tick <-chan time.Time
+	defer func() {
+		if ticker != nil {
+			ticker.Stop()
+		}
+	}()
+
+	for {
+		select {
+		case msg, ok := <-stream:
+			if !ok {
+				flush(time.Now(), true)
+				return
+			}
+			switch msg := msg.(type) {
+			case events.ProgressMsg:
+				hold(msg)
+			case events.BatchProgressMsg:
+				for _, m := range msg {
+					hold(m)
+				}
+			default:
+				flush(time.Now(), true)
+				send(msg)
+			}
+		case now := <-tick:
+			flush(now, false)
+		case <-p.wake:
+			flush(time.Now(), true)
+		}
+
+		switch {
+		case len(order) > 0 && ticker == nil:
+			ticker = time.NewTicker(p.active)
+			tick = ticker.C
+		case len(order) == 0 && ticker != nil:
+			ticker.Stop()
+			ticker, tick = nil, nil
+		}
+	}
+}
+
+// updatePacing slows progress down while the terminal is unfocused or too
+// small to draw the dashboard, and restarts the spinner once it is back.
+func (m *RootModel) updatePacing() tea.Cmd {
+	if m.Pacer == nil {
+		return nil
+	}
+	idle := m.blurred || (m.width > 0 && (m.width < MinTermWidth || m.height < MinTermHeight))
+	wasIdle := m.Pacer.Idle()
+	m.Pacer.SetIdle(idle)
+	if wasIdle && !idle {
+		return m.spinner.Tick
+	}
+	return nil
+}
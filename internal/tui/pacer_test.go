@@ -0,0 +1,134 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/SurgeDM/Surge/internal/engine/events"
+)
+
+func startPacer(t *testing.T, p *ProgressPacer) (chan any, <-chan tea.Msg) {
+	t.Helper()
+	stream := make(chan any)
+	out := make(chan tea.Msg, 16)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.Forward(stream, func(msg tea.Msg) { out <- msg })
+	}()
+	t.Cleanup(func() {
+		close(stream)
+		<-done
+	})
+	return stream, out
+}
+
+func receive(t *testing.T, out <-chan tea.Msg, within time.Duration) tea.Msg {
+	t.Helper()
+	select {
+	case msg := <-out:
+		return msg
+	case <-time.After(within):
+		t.Fatalf("nothing forwarded within %v", within)
+		return nil
+	}
+}
+
+func TestProgressPacer_CoalescesProgressPerDownload(t *testing.T) {
+	p := NewProgressPacer()
+	p.active = 20 * time.Millisecond
+	stream, out := startPacer(t, p)
+
+	stream <- events.ProgressMsg{DownloadID: "a", Downloaded: 1, Speed: 1}
+	stream <- events.BatchProgressMsg{
+		{DownloadID: "b", Downloaded: 5, Speed: 1},
+		{DownloadID: "a", Downloaded: 2, Speed: 1},
+	}
+
+	batch, ok := receive(t, out, time.Second).(events.BatchProgressMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("got %#v, want one batch for a and b", batch)
+	}
+	if batch[0].DownloadID != "a" || batch[0].Downloaded != 2 || batch[1].DownloadID != "b" {
+		t.Fatalf("batch = %+v, want newest progress for a, then b", batch)
+	}
+}
+
+func TestProgressPacer_StateChangeFollowsHeldProgress(t *testing.T) {
+	p := NewProgressPacer()
+	p.active = time.Hour
+	stream, out := startPacer(t, p)
+
+	stream <- events.ProgressMsg{DownloadID: "a", Downloaded: 7, Speed: 1}
+	stream <- events.DownloadCompleteMsg{DownloadID: "a"}
+
+	if batch, ok := receive(t, out, time.Second).(events.BatchProgressMsg); !ok || batch[0].Downloaded != 7 {
+		t.Fatalf("expected the held progress first, got %#v", batch)
+	}
+	if _, ok := receive(t, out, time.Second).(events.DownloadCompleteMsg); !ok {
+		t.Fatal("expected the completion after the held progress")
+	}
+}
+
+func TestProgressPacer_IdleHoldsProgressUntilRefocused(t *testing.T) {
+	p := NewProgressPacer()
+	p.active = 10 * time.Millisecond
+	p.idle = time.Hour
+	stream, out := startPacer(t, p)
+
+	// A download's first progress goes out at the next tick
+	stream <- events.ProgressMsg{DownloadID: "queued"}
+	stream <- events.ProgressMsg{DownloadID: "a", Downloaded: 1, Speed: 1}
+	receive(t, out, time.Second)
+
+	// After that, anything not transferring waits for the idle interval, and
+	// while idle so does everything else
+	stream <- events.ProgressMsg{DownloadID: "queued"}
+	p.SetIdle(true)
+	stream <- events.ProgressMsg{DownloadID: "a", Downloaded: 3, Speed: 1}
+
+	select {
+	case msg := <-out:
+		t.Fatalf("forwarded %#v while idle", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Refocusing hands over everything held, possibly split across a tick
+	p.SetIdle(false)
+	seen := map[string]int64{}
+	for len(seen) < 2 {
+		batch, ok := receive(t, out, time.Second).(events.BatchProgressMsg)
+		if !ok {
+			t.Fatalf("expected held progress on refocus, got %#v", batch)
+		}
+		for _, msg := range batch {
+			seen[msg.DownloadID] = msg.Downloaded
+		}
+	}
+	if seen["a"] != 3 {
+		t.Errorf("a = %d after refocus, want the newest progress (3)", seen["a"])
+	}
+}
+
+func TestUpdatePacing_BlurAndSmallTerminal(t *testing.T) {
+	m := RootModel{Pacer: NewProgressPacer(), width: 160, height: 50}
+
+	updated, _ := m.Update(tea.BlurMsg{})
+	m = updated.(RootModel)
+	if !m.Pacer.Idle() {
+		t.Fatal("expected pacing to go idle when the terminal loses focus")
+	}
+
+	updated, cmd := m.Update(tea.FocusMsg{})
+	m = updated.(RootModel)
+	if m.Pacer.Idle() || cmd == nil {
+		t.Fatal("expected focus to resume pacing and restart the spinner")
+	}
+
+	m.width, m.height = MinTermWidth-1, MinTermHeight
+	m.updatePacing()
+	if !m.Pacer.Idle() {
+		t.Fatal("expected pacing to go idle when the terminal is too small")
+	}
+}
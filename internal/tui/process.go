@@ -10,6 +10,7 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"github.com/SurgeDM/Surge/internal/config"
 	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/types"
 	"github.com/SurgeDM/Surge/internal/processing"
 	"github.com/SurgeDM/Surge/internal/utils"
 )
@@ -20,15 +21,21 @@ func (m *RootModel) processProgressMsg(msg events.ProgressMsg) tea.Cmd {
 		return nil
 	}
 
+	prevTab := downloadTab(d)
 	prevDownloaded := d.Downloaded
 	d.Downloaded = msg.Downloaded
 	d.Total = msg.Total
 	d.Speed = msg.Speed
 	d.Elapsed = msg.Elapsed
 	d.Connections = msg.ActiveConnections
+	d.ConnectionSpeeds = msg.ConnectionSpeeds
+	d.phase = msg.Phase
+	if msg.Phase == types.PhaseVerifying {
+		d.Checked = msg.Checked
+	}
 
 	// Keep "Resuming..." visible until we observe actual transfer.
-	if d.resuming && (d.Speed > 0 || d.Downloaded > prevDownloaded) {
+	if d.resuming && (d.Speed > 0 || d.Downloaded > prevDownloaded || msg.Phase == types.PhaseVerifying || msg.Phase == types.PhasePostProcessing) {
 		d.resuming = false
 	}
 
@@ -47,9 +54,13 @@ func (m *RootModel) processProgressMsg(msg events.ProgressMsg) tea.Cmd {
 		}
 	}
 
+	// Only rows on screen animate; the rest catch up when scrolled to
 	var cmd tea.Cmd
-	if d.Total > 0 {
+	if d.Total > 0 && m.isVisible(d) {
 		percentage := float64(d.Downloaded) / float64(d.Total)
+		if d.phase == types.PhaseVerifying {
+			percentage = float64(d.Checked) / float64(d.Total)
+		}
 		cmd = d.progress.SetPercent(percentage)
 	}
 
@@ -73,12 +84,35 @@ func (m *RootModel) processProgressMsg(msg events.ProgressMsg) tea.Cmd {
 		m.lastSpeedHistoryUpdate = time.Now()
 	}
 
-	m.UpdateListItems()
+	// Rows hold the download itself, so the list only needs rebuilding when
+	// the download moves to another tab
+	if downloadTab(d) != prevTab {
+		m.UpdateListItems()
+	}
 	return cmd
 }
 
+// downloadStart holds what startDownload needs to add a download.
+type downloadStart struct {
+	URL     string
+	Mirrors []string
+	Headers map[string]string
+	TLS     types.TLSOptions
+	Request types.RequestOptions
+	// Path is the directory the download is saved to. IsDefaultPath is set
+	// when it is the default download directory, which lets category rules
+	// pick another one.
+	Path          string
+	IsDefaultPath bool
+	// Filename and ID are optional; empty ones are chosen when the download
+	// is probed.
+	Filename string
+	ID       string
+}
+
 // startDownload initiates a new download
-func (m RootModel) startDownload(url string, mirrors []string, headers map[string]string, path string, isDefaultPath bool, filename, id string) (RootModel, tea.Cmd) {
+func (m RootModel) startDownload(opts downloadStart) (RootModel, tea.Cmd) {
+	url, mirrors, headers, path, isDefaultPath := opts.URL, opts.Mirrors, opts.Headers, opts.Path, opts.IsDefaultPath
 	if m.Service == nil {
 		m.addLogEntry(LogStyleError.Render("\u2716 Service unavailable"))
 		return m, nil
@@ -87,8 +121,8 @@ func (m RootModel) startDownload(url string, mirrors []string, headers map[strin
 	// Enforce absolute path
 	path = utils.EnsureAbsPath(path)
 
-	candidateFilename := strings.TrimSpace(filename)
-	requestID := strings.TrimSpace(id)
+	candidateFilename := strings.TrimSpace(opts.Filename)
+	requestID := strings.TrimSpace(opts.ID)
 
 	resolvedPath := path
 	resolvedFilename := candidateFilename
@@ -114,6 +148,8 @@ func (m RootModel) startDownload(url string, mirrors []string, headers map[strin
 		Headers:            headers,
 		IsExplicitCategory: !isDefaultPath,
 		SkipApproval:       true,
+		TLS:                opts.TLS,
+		Request:            opts.Request,
 	}
 
 	optimisticID := requestID
@@ -134,6 +170,10 @@ func (m RootModel) startDownload(url string, mirrors []string, headers map[strin
 	} else {
 		newDownload.Destination = resolvedPath
 	}
+	if !isDefaultPath {
+		m.rememberDir(path)
+	}
+
 	m.downloads = append(m.downloads, newDownload)
 	m.SelectedDownloadID = optimisticID
 	if m.pinnedTab == -1 {
@@ -197,7 +237,7 @@ func (m RootModel) startDownload(url string, mirrors []string, headers map[strin
 			newID, finalFilename, err = m.Orchestrator.Enqueue(ctx, req)
 		}
 		if err != nil {
-			return enqueueErrorMsg{tempID: optimisticID, err: err}
+			return enqueueErrorMsg{tempID: optimisticID, err: err, req: req, requestID: requestID}
 		}
 
 		// Use the server-resolved filename if available
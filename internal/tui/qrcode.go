@@ -0,0 +1,164 @@
+package tui
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/SurgeDM/Surge/internal/i18n"
+	"github.com/SurgeDM/Surge/internal/tui/colors"
+	"rsc.io/qr"
+)
+
+// qrQuietZone is the light border, in modules, scanners need around a code.
+const qrQuietZone = 2
+
+// renderQRCode draws text as a QR code with half-block characters, two
+// modules per line, so it keeps its square shape in a terminal. Light
+// modules are drawn and dark ones left blank, which scans on the usual
+// dark background.
+func renderQRCode(text string) (string, error) {
+	code, err := qr.Encode(text, qr.L)
+	if err != nil {
+		return "", err
+	}
+	light := func(x, y int) bool {
+		x, y = x-qrQuietZone, y-qrQuietZone
+		if x < 0 || y < 0 || x >= code.Size || y >= code.Size {
+			return true
+		}
+		return !code.Black(x, y)
+	}
+
+	size := code.Size + 2*qrQuietZone
+	var sb strings.Builder
+	for y := 0; y < size; y += 2 {
+		for x := 0; x < size; x++ {
+			top, bottom := light(x, y), y+1 < size && light(x, y+1)
+			switch {
+			case top && bottom:
+				sb.WriteString("\u2588")
+			case top:
+				sb.WriteString("\u2580")
+			case bottom:
+				sb.WriteString("\u2584")
+			default:
+				sb.WriteString(" ")
+			}
+		}
+		if y+2 < size {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String(), nil
+}
+
+// serverShareURL is the address of the API this TUI serves or is connected
+// to, or "" when there is none. The token a client needs rides in the
+// fragment, which is never sent to the server or written to its logs.
+func (m RootModel) serverShareURL() string {
+	if m.ServerPort == 0 {
+		return ""
+	}
+	host := m.ServerHost
+	if host == "" || host == "localhost" || net.ParseIP(host).IsLoopback() {
+		// The server listens on every interface, and a phone cannot reach
+		// this machine's loopback address
+		host = "127.0.0.1"
+		if ip := lanAddress(); ip != "" {
+			host = ip
+		}
+	}
+	addr := fmt.Sprintf("http://%s:%d", host, m.ServerPort)
+	if token := GetAuthToken(); token != "" {
+		addr += "/#token=" + token
+	}
+	return addr
+}
+
+// lanAddress returns the address this machine reaches the network from, or
+// "" without one. Dialing UDP sends nothing; it only picks the route. It is a
+// variable so tests do not depend on the network.
+var lanAddress = func() string {
+	conn, err := net.Dial("udp", "192.0.2.1:9")
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = conn.Close() }()
+	if addr, ok := conn.LocalAddr().(*net.UDPAddr); ok {
+		return addr.IP.String()
+	}
+	return ""
+}
+
+// qrShare is one thing the QR code modal can show.
+type qrShare struct {
+	label string
+	text  string
+}
+
+// qrSharesFor returns what the QR code modal offers for d: its URL first,
+// then the API address, leaving out whichever there is none of.
+func (m RootModel) qrSharesFor(d *DownloadModel) []qrShare {
+	var shares []qrShare
+	if d != nil && d.URL != "" {
+		name := d.Filename
+		if name == "" {
+			name = d.URL
+		}
+		shares = append(shares, qrShare{label: i18n.T("modal.qr_download", name), text: d.URL})
+	}
+	if addr := m.serverShareURL(); addr != "" {
+		shares = append(shares, qrShare{label: i18n.T("modal.qr_server"), text: addr})
+	}
+	return shares
+}
+
+func (m RootModel) updateQRCode(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.String() == "tab":
+		m.qrIndex = (m.qrIndex + 1) % len(m.qrShares)
+	case msg.String() == "esc", key.Matches(msg, m.keys.Dashboard.QRCode):
+		m.state = DashboardState
+		m.qrShares, m.qrIndex = nil, 0
+	}
+	return m, nil
+}
+
+func (m RootModel) viewQRCode() string {
+	share := m.qrShares[m.qrIndex]
+	dimStyle := lipgloss.NewStyle().Foreground(colors.Gray())
+
+	code, err := renderQRCode(share.text)
+	if err != nil {
+		code = LogStyleError.Render("\u2716 " + err.Error())
+	}
+	codeWidth, codeHeight := lipgloss.Width(code), lipgloss.Height(code)
+	w := max(codeWidth, 40) + 4
+	h := codeHeight + 6
+	if w > m.width || h > m.height {
+		code = i18n.T("modal.qr_too_small")
+		w, h = GetDynamicModalDimensions(m.width, m.height, 40, 8, 60, 8)
+	}
+
+	inner := w - 4
+	label := share.label
+	if lipgloss.Width(label) > inner {
+		label = string([]rune(label)[:max(inner-3, 0)]) + "..."
+	}
+	hint := i18n.T("modal.qr_hint")
+	if len(m.qrShares) == 1 {
+		hint = i18n.T("modal.qr_hint_close")
+	}
+	content := lipgloss.JoinVertical(lipgloss.Center,
+		code,
+		"",
+		label,
+		dimStyle.Render(hint),
+	)
+	content = lipgloss.PlaceHorizontal(inner, lipgloss.Center, content)
+	return renderBtopBox(PaneTitleStyle.Render(" "+i18n.T("modal.qr_title")+" "), "", content, w, h, colors.Cyan())
+}
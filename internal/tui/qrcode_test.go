@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"github.com/SurgeDM/Surge/internal/config"
+	"rsc.io/qr"
+)
+
+func TestRenderQRCode_TwoModulesPerLine(t *testing.T) {
+	text := "https://example.com/a.iso"
+	code, err := qr.Encode(text, qr.L)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := renderQRCode(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	size := code.Size + 2*qrQuietZone
+	lines := strings.Split(out, "\n")
+	if len(lines) != (size+1)/2 {
+		t.Fatalf("lines = %d, want %d", len(lines), (size+1)/2)
+	}
+	for i, line := range lines {
+		if n := len([]rune(line)); n != size {
+			t.Fatalf("line %d is %d wide, want %d", i, n, size)
+		}
+	}
+	// The quiet zone is light all around
+	if strings.Trim(lines[0], "\u2588") != "" {
+		t.Fatalf("first line = %q, want the quiet zone", lines[0])
+	}
+}
+
+func TestQRCodeModal_SwitchesBetweenDownloadAndServer(t *testing.T) {
+	m := RootModel{
+		state:       DashboardState,
+		downloads:   []*DownloadModel{{ID: "dl-1", URL: "https://example.com/a.iso", Filename: "a.iso"}},
+		Settings:    config.DefaultSettings(),
+		keys:        config.DefaultKeyMap(),
+		list:        NewDownloadList(80, 20),
+		logViewport: viewport.New(viewport.WithWidth(60), viewport.WithHeight(5)),
+		ServerPort:  1700,
+		width:       120,
+		height:      60,
+	}
+	m.UpdateListItems()
+	origLAN := lanAddress
+	t.Cleanup(func() { lanAddress = origLAN })
+	lanAddress = func() string { return "192.168.1.20" }
+
+	updated, _ := m.updateDashboard(tea.KeyPressMsg{Code: 'Q', Text: "Q"})
+	m = updated.(RootModel)
+	if m.state != QRCodeState || len(m.qrShares) != 2 || m.qrShares[0].text != "https://example.com/a.iso" {
+		t.Fatalf("state = %v, shares = %+v; want the download's URL first", m.state, m.qrShares)
+	}
+	if !strings.Contains(m.viewQRCode(), "a.iso") {
+		t.Fatal("expected the modal to name the download")
+	}
+
+	updated, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	m = updated.(RootModel)
+	if got := m.qrShares[m.qrIndex].text; !strings.HasPrefix(got, "http://192.168.1.20:1700") {
+		t.Fatalf("after tab the code shows %q, want the API address", got)
+	}
+
+	updated, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	m = updated.(RootModel)
+	if m.state != DashboardState {
+		t.Fatalf("state = %v after esc, want the dashboard", m.state)
+	}
+}
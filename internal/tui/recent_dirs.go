@@ -0,0 +1,41 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// rememberDir puts dir at the front of the recent directories offered in the
+// add form and directory picker.
+func (m *RootModel) rememberDir(dir string) {
+	updated := config.AddRecentDir(m.recentDirs, dir)
+	if slices.Equal(updated, m.recentDirs) {
+		return
+	}
+	m.recentDirs = updated
+	if IsTestMode {
+		return
+	}
+	if err := config.SaveRecentDirs(updated); err != nil {
+		utils.Debug("Failed to save recent directories: %v", err)
+	}
+}
+
+// recentDirForKey returns the recent directory picked by a number key such
+// as "3" or "alt+3".
+func (m RootModel) recentDirForKey(msg tea.KeyPressMsg) (string, bool) {
+	s := msg.String()
+	s = strings.TrimPrefix(s, "alt+")
+	if len(s) != 1 || s[0] < '1' || s[0] > '9' {
+		return "", false
+	}
+	i := int(s[0] - '1')
+	if i >= len(m.recentDirs) {
+		return "", false
+	}
+	return m.recentDirs[i], true
+}
@@ -65,7 +65,13 @@ func TestResume_RespectsOriginalPath_WhenDefaultChanges(t *testing.T) {
 	testFilename := "file.zip"
 
 	// Start download with relative path "."
-	m, _ = m.startDownload(testURL, nil, nil, ".", true, testFilename, "id-1")
+	m, _ = m.startDownload(downloadStart{
+		URL:           testURL,
+		Path:          ".",
+		IsDefaultPath: true,
+		Filename:      testFilename,
+		ID:            "id-1",
+	})
 
 	// 4. Verify Immediate State
 	if len(m.downloads) != 1 {
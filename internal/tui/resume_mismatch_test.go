@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"fmt"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+)
+
+func TestResumeMismatch_OffersRestart(t *testing.T) {
+	dm := &DownloadModel{ID: "dl-1", URL: "https://example.com/file.iso", Filename: "file.iso", Destination: "/tmp/downloads/file.iso"}
+	svc := &mockService{}
+
+	m := RootModel{
+		state:     DashboardState,
+		downloads: []*DownloadModel{dm},
+		Service:   svc,
+		Settings:  config.DefaultSettings(),
+		keys:      config.DefaultKeyMap(),
+		list:      NewDownloadList(80, 20),
+	}
+	m.UpdateListItems()
+
+	updated, _ := m.updateEvents(events.DownloadErrorMsg{
+		DownloadID: "dl-1",
+		Filename:   "file.iso",
+		Err:        fmt.Errorf("%w: bytes 0-99 differ", types.ErrResumeMismatch),
+	})
+	m = updated.(RootModel)
+	if m.state != ResumeMismatchState || m.restartTargetID != "dl-1" {
+		t.Fatalf("state = %v, target = %q, want the restart prompt for dl-1", m.state, m.restartTargetID)
+	}
+
+	updated, _ = m.updateResumeMismatch(tea.KeyPressMsg{Code: 'y', Text: "y"})
+	m = updated.(RootModel)
+	if m.state != DashboardState {
+		t.Errorf("state = %v, want dashboard after confirming", m.state)
+	}
+	if svc.deletedID != "dl-1" {
+		t.Errorf("deleted %q, want the errored download removed", svc.deletedID)
+	}
+	if m.FindDownloadByID("dl-1") != nil {
+		t.Error("errored download is still listed")
+	}
+	if len(m.downloads) != 1 || m.downloads[0].URL != dm.URL || m.downloads[0].Filename != "file.iso" {
+		t.Fatalf("downloads = %+v, want the URL queued again", m.downloads)
+	}
+}
+
+func TestResumeMismatch_OtherErrorsDoNotPrompt(t *testing.T) {
+	m := RootModel{
+		state:     DashboardState,
+		downloads: []*DownloadModel{{ID: "dl-1", Filename: "file.iso"}},
+		keys:      config.DefaultKeyMap(),
+		list:      NewDownloadList(80, 20),
+	}
+
+	updated, _ := m.updateEvents(events.DownloadErrorMsg{DownloadID: "dl-1", Err: types.ErrChecksumMismatch})
+	if got := updated.(RootModel).state; got != DashboardState {
+		t.Errorf("state = %v, want dashboard for an unrelated error", got)
+	}
+}
@@ -36,17 +36,23 @@ func TestRestartRequirementDetection(t *testing.T) {
 		t.Error("checkRestartRequirement() should be false when only non-restart settings changed")
 	}
 
-	// 5. Change restart-required setting (e.g. MaxConcurrentDownloads)
-	m.Settings.Network.MaxConcurrentDownloads.Value = config.Resolve[int](m.Settings.Network.MaxConcurrentDownloads) + 1
+	// 5. Change restart-required setting (e.g. AutoResume)
+	m.Settings.General.AutoResume.Value = !config.Resolve[bool](m.Settings.General.AutoResume)
 	if !m.checkRestartRequirement() {
 		t.Error("checkRestartRequirement() should be true when restart-required setting changed")
 	}
 
 	// 6. Reverting should make it false again
-	m.Settings.Network.MaxConcurrentDownloads.Value = config.Resolve[int](m.Settings.Network.MaxConcurrentDownloads) - 1
+	m.Settings.General.AutoResume.Value = !config.Resolve[bool](m.Settings.General.AutoResume)
 	if m.checkRestartRequirement() {
 		t.Error("checkRestartRequirement() should be false when settings are reverted to baseline")
 	}
+
+	// 7. Concurrency caps apply live
+	m.Settings.Network.MaxConcurrentDownloads.Value = config.Resolve[int](m.Settings.Network.MaxConcurrentDownloads) + 1
+	if m.checkRestartRequirement() {
+		t.Error("checkRestartRequirement() should be false when only the concurrency cap changed")
+	}
 }
 
 func TestDefensiveSnapshotting(t *testing.T) {
@@ -0,0 +1,114 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/SurgeDM/Surge/internal/engine/state"
+	"github.com/SurgeDM/Surge/internal/i18n"
+	"github.com/SurgeDM/Surge/internal/tui/colors"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// speedHistoryMsg carries the long-term speed history fetched from the service.
+type speedHistoryMsg struct {
+	samples []state.SpeedSample
+	err     error
+}
+
+func (m RootModel) updateSpeedHistory(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "esc" || key.Matches(msg, m.keys.Dashboard.SpeedHistory) {
+		m.state = DashboardState
+		m.speedDay = nil
+	}
+	return m, nil
+}
+
+// speedHistoryColumns spreads samples over width columns covering the window
+// that ends at now, each the average speed over its share of the window.
+// Time nothing was downloaded in counts as zero.
+func speedHistoryColumns(samples []state.SpeedSample, width int, now time.Time) []float64 {
+	cols := make([]float64, width)
+	if width < 1 {
+		return cols
+	}
+	window := int64(state.SpeedHistoryWindow.Seconds())
+	from := now.Unix() - window
+	for _, s := range samples {
+		offset := s.Start - from
+		if offset < 0 || offset >= window {
+			continue
+		}
+		cols[offset*int64(width)/window] += float64(s.Bytes)
+	}
+	colSeconds := float64(window) / float64(width)
+	for i := range cols {
+		cols[i] /= colSeconds
+	}
+	return cols
+}
+
+func (m RootModel) viewSpeedHistory() string {
+	w, h := GetDynamicModalDimensions(m.width, m.height, 50, 14, 110, 22)
+	inner := w - 4
+	dimStyle := lipgloss.NewStyle().Foreground(colors.Gray())
+	title := PaneTitleStyle.Render(" " + i18n.T("modal.speed_history_title") + " ")
+
+	if len(m.speedDay) == 0 {
+		content := lipgloss.Place(inner, h-4, lipgloss.Center, lipgloss.Center,
+			lipgloss.JoinVertical(lipgloss.Center, i18n.T("modal.speed_history_empty"), "", dimStyle.Render(i18n.T("modal.speed_history_hint"))))
+		return renderBtopBox(title, "", content, w, h, colors.Cyan())
+	}
+
+	var peak float64
+	var total int64
+	for _, s := range m.speedDay {
+		peak = max(peak, s.Speed())
+		total += s.Bytes
+	}
+	avg := float64(total) / (float64(len(m.speedDay)) * state.SpeedBucket.Seconds())
+
+	axisWidth := 11
+	graphWidth := max(inner-axisWidth, 10)
+	graphHeight := max(h-8, 3)
+	now := time.Now()
+	scale := peak * GraphHeadroom
+	graph := renderMultiLineGraph(speedHistoryColumns(m.speedDay, graphWidth, now), graphWidth, graphHeight, scale, nil)
+
+	axisStyle := lipgloss.NewStyle().Width(axisWidth).Foreground(colors.Cyan()).Align(lipgloss.Right)
+	axis := make([]string, graphHeight)
+	for i := range axis {
+		axis[i] = axisStyle.Render("")
+	}
+	axis[0] = axisStyle.Render(utils.FormatSpeed(scale))
+	axis[graphHeight/2] = axisStyle.Render(utils.FormatSpeed(scale / 2))
+	axis[graphHeight-1] = axisStyle.Render(utils.FormatSpeed(0))
+	graphWithAxis := lipgloss.JoinHorizontal(lipgloss.Top, graph, strings.Join(axis, "\n"))
+
+	// Clock times under the start, middle and end of the graph
+	start := now.Add(-state.SpeedHistoryWindow).Format("15:04")
+	mid := now.Add(-state.SpeedHistoryWindow / 2).Format("15:04")
+	end := now.Format("15:04")
+	gap := max(graphWidth-len(start)-len(mid)-len(end), 2)
+	timeAxis := dimStyle.Render(start + strings.Repeat(" ", gap/2) + mid + strings.Repeat(" ", gap-gap/2) + end)
+
+	summary := fmt.Sprintf("%s  %s  %s",
+		i18n.T("modal.speed_history_peak", utils.FormatSpeed(peak)),
+		i18n.T("modal.speed_history_avg", utils.FormatSpeed(avg)),
+		i18n.T("modal.speed_history_total", utils.ConvertBytesToHumanReadable(total)),
+	)
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		summary,
+		"",
+		graphWithAxis,
+		timeAxis,
+		"",
+		dimStyle.Render(i18n.T("modal.speed_history_hint")),
+	)
+	return renderBtopBox(title, "", content, w, h, colors.Cyan())
+}
@@ -0,0 +1,88 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/state"
+)
+
+type speedHistoryMockService struct {
+	mockService
+	samples []state.SpeedSample
+}
+
+func (s *speedHistoryMockService) SpeedHistory() ([]state.SpeedSample, error) {
+	return s.samples, nil
+}
+
+func TestSpeedHistoryColumns_AveragesOverTheDay(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	from := now.Add(-state.SpeedHistoryWindow)
+	samples := []state.SpeedSample{
+		{Start: from.Add(-time.Hour).Unix(), Bytes: 1 << 30}, // Too old
+		{Start: from.Unix(), Bytes: 6000},
+		{Start: from.Add(time.Minute).Unix(), Bytes: 6000},
+		{Start: now.Add(-time.Minute).Unix(), Bytes: 12000},
+	}
+
+	cols := speedHistoryColumns(samples, 24, now)
+	if len(cols) != 24 {
+		t.Fatalf("columns = %d, want 24", len(cols))
+	}
+	hour := time.Hour.Seconds()
+	if want := 12000 / hour; cols[0] != want {
+		t.Fatalf("first column = %v, want %v", cols[0], want)
+	}
+	if want := 12000 / hour; cols[23] != want {
+		t.Fatalf("last column = %v, want %v", cols[23], want)
+	}
+	for i := 1; i < 23; i++ {
+		if cols[i] != 0 {
+			t.Fatalf("column %d = %v, want 0 for an idle hour", i, cols[i])
+		}
+	}
+}
+
+func TestSpeedHistoryView_OpensFromDashboard(t *testing.T) {
+	now := time.Now()
+	m := RootModel{
+		state: DashboardState,
+		Service: &speedHistoryMockService{
+			samples: []state.SpeedSample{
+				{Start: now.Add(-2 * time.Hour).Truncate(time.Minute).Unix(), Bytes: 60 << 20},
+				{Start: now.Add(-time.Hour).Truncate(time.Minute).Unix(), Bytes: 30 << 20},
+			},
+		},
+		Settings:    config.DefaultSettings(),
+		keys:        config.DefaultKeyMap(),
+		list:        NewDownloadList(80, 20),
+		logViewport: viewport.New(viewport.WithWidth(60), viewport.WithHeight(5)),
+		width:       120,
+		height:      40,
+	}
+	m.UpdateListItems()
+
+	_, cmd := m.updateDashboard(tea.KeyPressMsg{Code: 'S', Text: "S"})
+	if cmd == nil {
+		t.Fatal("expected S to fetch the speed history")
+	}
+	updated, _ := m.Update(cmd())
+	m = updated.(RootModel)
+	if m.state != SpeedHistoryState || len(m.speedDay) != 2 {
+		t.Fatalf("state = %v with %d samples, want the speed history view", m.state, len(m.speedDay))
+	}
+	if view := m.viewSpeedHistory(); !strings.Contains(view, "Peak 1.0 MB/s") {
+		t.Fatalf("expected the peak minute's speed in the view:\n%s", view)
+	}
+
+	updated, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	m = updated.(RootModel)
+	if m.state != DashboardState || m.speedDay != nil {
+		t.Fatalf("state = %v after esc, want the dashboard", m.state)
+	}
+}
@@ -38,6 +38,10 @@ func (m RootModel) updatePaste(msg tea.PasteMsg) (tea.Model, tea.Cmd) {
 			return m, cmd
 		}
 		return m, nil
+	case OnboardingState:
+		var cmd tea.Cmd
+		m.SettingsInput, cmd = m.SettingsInput.Update(msg)
+		return m, cmd
 	case SettingsState:
 		if m.SettingsIsEditing {
 			var cmd tea.Cmd
@@ -149,7 +153,15 @@ func (m RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		_, fpHeight := GetDynamicModalDimensions(m.width, m.height, 60, 10, 90, 20)
 		m.filepicker.SetHeight(fpHeight - pickerChromeHeight)
 
-		return m, nil
+		return m, m.updatePacing()
+
+	case tea.FocusMsg:
+		m.blurred = false
+		return m, m.updatePacing()
+
+	case tea.BlurMsg:
+		m.blurred = true
+		return m, m.updatePacing()
 
 	case notificationTickMsg:
 		// Notification tick is still used but logs don't expire
@@ -162,7 +174,10 @@ func (m RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case UpdateCheckResultMsg:
 		if msg.Info != nil && msg.Info.UpdateAvailable {
 			m.UpdateInfo = msg.Info
-			m.state = UpdateAvailableState
+			// The setup wizard shows the update once it is done
+			if m.state != OnboardingState {
+				m.state = UpdateAvailableState
+			}
 		}
 		return m, nil
 
@@ -178,9 +193,15 @@ func (m RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Handle filepicker messages for all message types when in FilePickerState
 	default:
 		var cmds []tea.Cmd
-		for _, d := range m.downloads {
-			newProgress, cmd := d.progress.Update(msg)
-			d.progress = newProgress
+		items := m.list.VisibleItems()
+		start, end := m.visibleRange()
+		for _, item := range items[start:end] {
+			di, ok := item.(DownloadItem)
+			if !ok {
+				continue
+			}
+			newProgress, cmd := di.download.progress.Update(msg)
+			di.download.progress = newProgress
 			if cmd != nil {
 				cmds = append(cmds, cmd)
 			}
@@ -216,12 +237,6 @@ func (m RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case DashboardState:
 			return m.updateDashboard(msg)
 
-		case DetailState:
-			if msg.String() == "esc" || msg.String() == "q" || msg.String() == "enter" {
-				m.state = DashboardState
-				return m, nil
-			}
-
 		case InputState:
 			return m.updateInput(msg)
 
@@ -249,6 +264,9 @@ func (m RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case SpeedLimitsState:
 			return m.updateSpeedLimits(msg)
 
+		case OnboardingState:
+			return m.updateOnboarding(msg)
+
 		case SettingsState:
 			return m.updateSettings(msg)
 
@@ -283,10 +301,20 @@ func (m RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case PurgeConfirmState:
 			return m.updatePurgeConfirm(msg)
 
+		case ResumeMismatchState:
+			return m.updateResumeMismatch(msg)
+
+		case LargeDownloadConfirmState:
+			return m.updateLargeDownloadConfirm(msg)
+
+		case QRCodeState:
+			return m.updateQRCode(msg)
+
+		case SpeedHistoryState:
+			return m.updateSpeedHistory(msg)
+
 		default:
 			return m, nil
 		}
 	}
-
-	return m, nil
 }
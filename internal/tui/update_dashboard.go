@@ -2,12 +2,14 @@ package tui
 
 import (
 	"errors"
+	"fmt"
 
 	"charm.land/bubbles/v2/key"
 	"charm.land/bubbles/v2/list"
 	tea "charm.land/bubbletea/v2"
 	"github.com/SurgeDM/Surge/internal/clipboard"
 	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/state"
 	"github.com/SurgeDM/Surge/internal/engine/types"
 	"github.com/SurgeDM/Surge/internal/utils"
 )
@@ -106,12 +108,15 @@ func (m RootModel) updateDashboard(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		if defaultDir == "" {
 			defaultDir = "."
 		}
-		m.inputs[2].SetValue(defaultDir)
-		m.inputs[2].Blur()
-		m.inputs[3].SetValue("")
-		m.inputs[3].Blur()
-		m.inputs[1].SetValue("") // Clear mirrors
-		m.inputs[1].Blur()
+		// Everything but the path starts empty
+		for i := range m.inputs {
+			m.inputs[i].SetValue("")
+			if i != inputURL {
+				m.inputs[i].Blur()
+			}
+		}
+		m.inputs[inputPath].SetValue(defaultDir)
+		m.inputError = ""
 
 		url := ""
 		if config.Resolve[bool](m.Settings.General.ClipboardMonitor) {
@@ -221,6 +226,35 @@ func (m RootModel) updateDashboard(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// Pause everything, or resume what a previous P paused
+	if key.Matches(msg, m.keys.Dashboard.PauseAll) {
+		pauser, ok := m.Service.(interface {
+			PauseAll() ([]string, error)
+			ResumeAll() ([]string, error)
+		})
+		if !ok {
+			m.addLogEntry(LogStyleError.Render("\u2716 Service unavailable"))
+			return m, nil
+		}
+		if m.anyDownloadRunning() {
+			ids, err := pauser.PauseAll()
+			if err != nil {
+				m.addLogEntry(LogStyleError.Render("\u2716 Pause all failed: " + err.Error()))
+			}
+			m.markDownloads(ids, func(d *DownloadModel) { d.resuming, d.pausing = false, true })
+			m.addLogEntry(LogStylePaused.Render(fmt.Sprintf("\u23f8 Paused %d download(s); press P to resume them", len(ids))))
+		} else {
+			ids, err := pauser.ResumeAll()
+			if err != nil {
+				m.addLogEntry(LogStyleError.Render("\u2716 Resume all failed: " + err.Error()))
+			}
+			m.markDownloads(ids, func(d *DownloadModel) { d.paused, d.resuming = false, true })
+			m.addLogEntry(LogStyleStarted.Render(fmt.Sprintf("\u25b6 Resumed %d download(s)", len(ids))))
+		}
+		m.UpdateListItems()
+		return m, nil
+	}
+
 	// Open file
 	if key.Matches(msg, m.keys.Dashboard.OpenFile) {
 		if d := m.GetSelectedDownload(); d != nil {
@@ -250,6 +284,42 @@ func (m RootModel) updateDashboard(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// Copy the URL or final path of the selected download
+	if key.Matches(msg, m.keys.Dashboard.CopyURL) {
+		if d := m.GetSelectedDownload(); d != nil && d.URL != "" {
+			m.copyToClipboard("URL", d.URL)
+		}
+		return m, nil
+	}
+	if key.Matches(msg, m.keys.Dashboard.CopyPath) {
+		if d := m.GetSelectedDownload(); d != nil && d.Destination != "" {
+			m.copyToClipboard("path", d.Destination)
+		}
+		return m, nil
+	}
+
+	if key.Matches(msg, m.keys.Dashboard.QRCode) {
+		if shares := m.qrSharesFor(m.GetSelectedDownload()); len(shares) > 0 {
+			m.qrShares, m.qrIndex = shares, 0
+			m.state = QRCodeState
+		}
+		return m, nil
+	}
+
+	if key.Matches(msg, m.keys.Dashboard.SpeedHistory) {
+		history, ok := m.Service.(interface {
+			SpeedHistory() ([]state.SpeedSample, error)
+		})
+		if !ok {
+			m.addLogEntry(LogStyleError.Render("\u2716 Speed history is not available"))
+			return m, nil
+		}
+		return m, func() tea.Msg {
+			samples, err := history.SpeedHistory()
+			return speedHistoryMsg{samples: samples, err: err}
+		}
+	}
+
 	// Refresh URL
 	if key.Matches(msg, m.keys.Dashboard.Refresh) {
 		if d := m.GetSelectedDownload(); d != nil {
@@ -358,6 +428,22 @@ func (m RootModel) updateDashboard(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	if key.Matches(msg, m.keys.Dashboard.ToggleLayout) {
+		layout := otherListLayout(config.Resolve[string](m.Settings.General.ListLayout))
+		m.Settings.General.ListLayout.Value = layout
+		m.applyListLayout()
+		if err := m.persistSettings(); err != nil {
+			m.addLogEntry(LogStyleError.Render("\u2716 Failed to save list layout: " + err.Error()))
+		}
+		m.addLogEntry(LogStyleStarted.Render("\u25a4 Layout: " + layout))
+		return m, nil
+	}
+
+	if key.Matches(msg, m.keys.Dashboard.DetailPane) {
+		m.detailPane = !m.detailPane
+		return m, nil
+	}
+
 	if key.Matches(msg, m.keys.Dashboard.BatchImport) {
 		m.state = BatchFilePickerState
 		m.filepicker = newFilepicker(m.PWD)
@@ -366,9 +452,37 @@ func (m RootModel) updateDashboard(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		return m, m.filepicker.Init()
 	}
 
+	if key.Matches(msg, m.keys.Dashboard.DownloadLog) {
+		d := m.GetSelectedDownload()
+		if d == nil {
+			return m, nil
+		}
+		logger, ok := m.Service.(interface {
+			Logs(id string) ([]utils.LogLine, error)
+		})
+		if !ok {
+			m.addLogEntry(LogStyleError.Render("\u2716 Download logs are not available"))
+			return m, nil
+		}
+		id, name := d.ID, d.Filename
+		if name == "" {
+			name = d.URL
+		}
+		return m, func() tea.Msg {
+			lines, err := logger.Logs(id)
+			return downloadLogMsg{id: id, name: name, lines: lines, err: err}
+		}
+	}
+
 	if m.logFocused {
 		if key.Matches(msg, m.keys.Dashboard.LogClose) {
 			m.logFocused = false
+			// Closing a download's log brings the activity log back
+			if m.downloadLog != nil {
+				m.downloadLog = nil
+				m.refreshLogViewportContent()
+				m.logViewport.GotoBottom()
+			}
 			return m, nil
 		}
 		if key.Matches(msg, m.keys.Dashboard.LogDown) {
@@ -400,3 +514,39 @@ func (m RootModel) updateDashboard(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	m.list, cmd = m.list.Update(msg)
 	return m, cmd
 }
+
+// anyDownloadRunning reports whether a download is running or queued, so P
+// pauses everything instead of resuming what it paused before.
+func (m *RootModel) anyDownloadRunning() bool {
+	for _, d := range m.downloads {
+		if !d.done && !d.paused && !d.pausing && d.err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// markDownloads applies mark to the downloads with the given IDs.
+func (m *RootModel) markDownloads(ids []string, mark func(*DownloadModel)) {
+	for _, id := range ids {
+		for _, d := range m.downloads {
+			if d.ID == id {
+				mark(d)
+				break
+			}
+		}
+	}
+}
+
+// writeClipboard is replaced in tests, which have no clipboard to write to.
+var writeClipboard = clipboard.Write
+
+// copyToClipboard copies value, the URL or path of a download, and notes
+// it in the activity log.
+func (m *RootModel) copyToClipboard(what, value string) {
+	if err := writeClipboard(value); err != nil {
+		m.addLogEntry(LogStyleError.Render(fmt.Sprintf("\u2716 Could not copy %s: %v", what, err)))
+		return
+	}
+	m.addLogEntry(LogStyleStarted.Render(fmt.Sprintf("\u2398 Copied %s: %s", what, value)))
+}
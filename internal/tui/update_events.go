@@ -1,15 +1,18 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"charm.land/bubbles/v2/spinner"
 	tea "charm.land/bubbletea/v2"
-	"github.com/SurgeDM/Surge/internal/config"
 	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/types"
 	"github.com/SurgeDM/Surge/internal/tui/components"
+	"github.com/SurgeDM/Surge/internal/utils"
 )
 
 func (m RootModel) updateEvents(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -17,18 +20,23 @@ func (m RootModel) updateEvents(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 
 	case spinner.TickMsg:
+		// No animation in accessible mode, where each frame would be re-read,
+		// or while nobody is looking; updatePacing restarts it
+		if m.Accessible || m.Pacer.Idle() {
+			return m, nil
+		}
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
 
 		needsSpinner := false
 		for _, d := range m.downloads {
-			if d.pausing || d.resuming || components.DetermineStatus(d.done, d.paused, d.err != nil, d.Speed, d.Downloaded) == components.StatusQueued {
+			if d.pausing || d.resuming || phaseBusy(d) || components.DetermineStatus(d.done, d.paused, d.err != nil, d.Speed, d.Downloaded) == components.StatusQueued {
 				needsSpinner = true
 				break
 			}
 		}
 		if needsSpinner {
-			m.UpdateListItems()
+			m.refreshVisibleRows()
 			return m, cmd
 		}
 		return m, nil
@@ -78,7 +86,43 @@ func (m RootModel) updateEvents(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.UpdateListItems()
 		return m, nil
 
+	case downloadLogMsg:
+		if msg.err != nil {
+			m.addLogEntry(LogStyleError.Render(fmt.Sprintf("\u2716 Failed to load log of %s: %v", msg.name, msg.err)))
+			return m, nil
+		}
+		view := &downloadLogView{id: msg.id, name: msg.name, lines: make([]string, 0, len(msg.lines))}
+		for _, line := range msg.lines {
+			view.lines = append(view.lines, fmt.Sprintf("[%s] %s", line.Time.Format("15:04:05"), line.Message))
+		}
+		m.downloadLog = view
+		m.logFocused = true
+		m.refreshLogViewportContent()
+		m.logViewport.GotoBottom()
+		return m, nil
+
+	case speedHistoryMsg:
+		if msg.err != nil {
+			m.addLogEntry(LogStyleError.Render(fmt.Sprintf("\u2716 Failed to load speed history: %v", msg.err)))
+			return m, nil
+		}
+		if m.state == DashboardState {
+			m.speedDay = msg.samples
+			m.state = SpeedHistoryState
+		}
+		return m, nil
+
 	case enqueueErrorMsg:
+		// Too large to start unasked: ask instead of failing it
+		var large *types.LargeDownloadError
+		if errors.As(msg.err, &large) && msg.req != nil && m.state == DashboardState {
+			m.removeDownloadByID(msg.tempID)
+			m.UpdateListItems()
+			m.largeDownload = &largeDownloadRequest{req: *msg.req, requestID: msg.requestID, size: large.Size, threshold: large.Threshold}
+			m.quitConfirmFocused = 0
+			m.state = LargeDownloadConfirmState
+			return m, nil
+		}
 		if msg.tempID != "" {
 			if d := m.FindDownloadByID(msg.tempID); d != nil {
 				d.err = msg.err
@@ -167,11 +211,35 @@ func (m RootModel) updateEvents(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Only update UI once per batch
 		return m, tea.Batch(cmds...)
 
+	case events.DownloadVerifyingMsg:
+		d := m.FindDownloadByID(msg.DownloadID)
+		if d == nil || d.done || d.paused {
+			return m, nil
+		}
+		wasVerifying := d.phase == types.PhaseVerifying
+		d.phase = types.PhaseVerifying
+		d.resuming = false
+		d.Checked = msg.Checked
+		d.Speed = 0
+		d.Connections = 0
+		var cmds []tea.Cmd
+		if msg.Total > 0 {
+			cmds = append(cmds, d.progress.SetPercent(float64(msg.Checked)/float64(msg.Total)))
+		}
+		if !wasVerifying {
+			m.UpdateListItems()
+			cmds = append(cmds, m.spinner.Tick)
+		} else {
+			m.refreshVisibleRows()
+		}
+		return m, tea.Batch(cmds...)
+
 	case events.DownloadCompleteMsg:
 
 		var cmds []tea.Cmd
 
 		if d := m.FindDownloadByID(msg.DownloadID); d != nil {
+			d.phase = ""
 			if !d.done {
 				d.Total = msg.Total
 				d.Downloaded = d.Total
@@ -186,7 +254,7 @@ func (m RootModel) updateEvents(msg tea.Msg) (tea.Model, tea.Cmd) {
 				} else if msg.Elapsed.Seconds() > 0 {
 					speed = float64(d.Total) / msg.Elapsed.Seconds()
 				}
-				m.addLogEntry(LogStyleComplete.Render(fmt.Sprintf("\u2714 Done: %s (%.2f MB/s)", d.Filename, speed/float64(config.MB))))
+				m.addLogEntry(LogStyleComplete.Render(fmt.Sprintf("\u2714 Done: %s (%s)", d.Filename, utils.FormatSpeed(speed))))
 			}
 		}
 		m.UpdateListItems()
@@ -197,6 +265,7 @@ func (m RootModel) updateEvents(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if d := m.FindDownloadByID(msg.DownloadID); d != nil {
 			d.err = msg.Err
 			d.done = true
+			d.phase = ""
 			m.addLogEntry(LogStyleError.Render("\u2716 Error: " + d.Filename))
 			found = true
 		}
@@ -207,14 +276,30 @@ func (m RootModel) updateEvents(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.downloads = append(m.downloads, newDownload)
 			m.addLogEntry(LogStyleError.Render("\u2716 Error: " + msg.Filename))
 		}
+		// The partial no longer matches the remote file; offer to start over
+		// rather than leave the user to work out why it failed.
+		if errors.Is(msg.Err, types.ErrResumeMismatch) && m.state == DashboardState {
+			m.restartTargetID = msg.DownloadID
+			m.quitConfirmFocused = 0
+			m.state = ResumeMismatchState
+		}
 		m.UpdateListItems()
 		return m, nil
 
+	case events.DownloadFailedMsg:
+		if msg.Quarantined() {
+			m.addLogEntry(LogStyleError.Render(fmt.Sprintf("\u2716 Failed for good after %d attempts: %s", msg.Attempts, msg.Filename)))
+		} else {
+			m.addLogEntry(LogStylePaused.Render(fmt.Sprintf("\u21bb Retrying %s in %s", msg.Filename, time.Until(msg.RetryAt).Round(time.Second))))
+		}
+		return m, nil
+
 	case events.DownloadPausedMsg:
 		if d := m.FindDownloadByID(msg.DownloadID); d != nil {
 			d.paused = true
 			d.pausing = false
 			d.resuming = false
+			d.phase = ""
 			d.Downloaded = msg.Downloaded
 			d.RateLimit = msg.RateLimit
 			d.RateLimitSet = msg.RateLimitSet
@@ -240,6 +325,19 @@ func (m RootModel) updateEvents(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if d := m.FindDownloadByID(msg.DownloadID); d != nil {
 			d.RateLimit = msg.RateLimit
 			d.RateLimitSet = msg.RateLimitSet
+			d.Tags = msg.Tags
+			if msg.ResponseHeaders != nil {
+				d.ResponseHeaders = msg.ResponseHeaders
+			}
+			if msg.WaitingForNetwork {
+				d.phase = types.PhaseWaitingForNetwork
+			} else if d.phase == types.PhaseWaitingForNetwork {
+				// Probed now the network is back
+				d.phase = ""
+				d.Filename = msg.Filename
+				d.FilenameLower = strings.ToLower(msg.Filename)
+				d.Destination = msg.DestPath
+			}
 			found = true
 		}
 		if !found {
@@ -248,6 +346,11 @@ func (m RootModel) updateEvents(msg tea.Msg) (tea.Model, tea.Cmd) {
 			newDownload.Destination = msg.DestPath
 			newDownload.RateLimit = msg.RateLimit
 			newDownload.RateLimitSet = msg.RateLimitSet
+			newDownload.Tags = msg.Tags
+			newDownload.ResponseHeaders = msg.ResponseHeaders
+			if msg.WaitingForNetwork {
+				newDownload.phase = types.PhaseWaitingForNetwork
+			}
 			m.downloads = append(m.downloads, newDownload)
 			m.SelectedDownloadID = msg.DownloadID
 			m.UpdateListItems()
@@ -255,6 +358,15 @@ func (m RootModel) updateEvents(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case events.DownloadTaggedMsg:
+		if d := m.FindDownloadByID(msg.DownloadID); d != nil {
+			d.Tags = msg.Tags
+			if m.searchQuery != "" {
+				m.UpdateListItems()
+			}
+		}
+		return m, nil
+
 	case events.DownloadRemovedMsg:
 		if m.removeDownloadByID(msg.DownloadID) {
 			if msg.Filename != "" {
@@ -264,12 +376,39 @@ func (m RootModel) updateEvents(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case events.ResyncMsg:
+		m.addLogEntry(LogStyleStarted.Render("\u2139 Missed events while reconnecting; reloading downloads"))
+		if m.Service == nil {
+			return m, nil
+		}
+		service := m.Service
+		return m, func() tea.Msg {
+			statuses, err := service.List()
+			if err != nil {
+				return events.SystemLogMsg{Message: "Failed to reload downloads: " + err.Error()}
+			}
+			return downloadsReloadedMsg(statuses)
+		}
+
+	case downloadsReloadedMsg:
+		m.reconcileDownloads(msg)
+		return m, nil
+
 	case events.SystemLogMsg:
 		if msg.Message != "" {
 			m.addLogEntry(LogStyleStarted.Render("\u2139 " + msg.Message))
 		}
 		return m, nil
 
+	case events.DrainModeMsg:
+		m.draining = msg.Draining
+		if msg.Draining {
+			m.addLogEntry(LogStylePaused.Render("\u23f8 Draining: queued downloads wait until drain mode is off"))
+		} else {
+			m.addLogEntry(LogStyleStarted.Render("\u25b6 Drain mode off"))
+		}
+		return m, nil
+
 	case startupConfigWarningMsg:
 		for _, w := range msg {
 			if w != "" {
@@ -281,3 +420,47 @@ func (m RootModel) updateEvents(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	return m, nil
 }
+
+// reconcileDownloads brings the list in line with statuses from the service:
+// downloads it no longer knows are dropped, new ones are added, and the state
+// of the rest is corrected.
+func (m *RootModel) reconcileDownloads(statuses []types.DownloadStatus) {
+	known := make(map[string]bool, len(statuses))
+	for _, s := range statuses {
+		known[s.ID] = true
+		d := m.FindDownloadByID(s.ID)
+		if d == nil {
+			d = NewDownloadModel(s.ID, s.URL, s.Filename, s.TotalSize)
+			d.Destination = s.DestPath
+			m.downloads = append(m.downloads, d)
+		}
+		d.Total = s.TotalSize
+		d.Downloaded = s.Downloaded
+		d.Tags = s.Tags
+		if s.ResponseHeaders != nil {
+			d.ResponseHeaders = s.ResponseHeaders
+		}
+		d.pausing, d.resuming = false, false
+
+		switch s.Status {
+		case "completed":
+			d.done, d.paused, d.started = true, false, true
+		case "error", "failed":
+			d.done, d.started = true, true
+			if d.err == nil {
+				d.err = errors.New(s.Error)
+			}
+		case "paused":
+			d.paused, d.started = true, true
+		case "downloading":
+			d.paused, d.started = false, true
+			d.phase = s.Phase
+		case "queued":
+			d.phase = s.Phase
+		}
+	}
+	m.downloads = slices.DeleteFunc(m.downloads, func(d *DownloadModel) bool {
+		return !known[d.ID]
+	})
+	m.UpdateListItems()
+}
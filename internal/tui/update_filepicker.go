@@ -76,6 +76,14 @@ func (m RootModel) updateFilePicker(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		return m.handleFilePickerSelection(m.filepicker.CurrentDirectory)
 	}
 
+	// Number keys pick one of the recent download directories
+	if m.filepickerOrigin != FilePickerOriginTheme && key.Matches(msg, m.keys.FilePicker.RecentDir) {
+		if dir, ok := m.recentDirForKey(msg); ok {
+			return m.handleFilePickerSelection(dir)
+		}
+		return m, nil
+	}
+
 	// Pass key to filepicker
 	var cmd tea.Cmd
 	m.filepicker, cmd = m.filepicker.Update(msg)
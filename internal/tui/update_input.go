@@ -1,11 +1,16 @@
 package tui
 
 import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
 	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/types"
 	"github.com/SurgeDM/Surge/internal/utils"
 )
 
@@ -21,14 +26,29 @@ func (m *RootModel) blurAllInputs() {
 	}
 }
 
+// Fields of the add-download form, in the order of m.inputs. The extension
+// prompt reuses the path and filename fields.
+const (
+	inputURL = iota
+	inputMirrors
+	inputPath
+	inputFilename
+	inputChecksum
+	inputHeaders
+	inputConnections
+	inputPriority
+
+	addFormInputs
+)
+
 func (m RootModel) updateInput(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	if key.Matches(msg, m.keys.Input.Esc) {
 		m.state = DashboardState
 		return m, nil
 	}
 
-	if key.Matches(msg, m.keys.Input.Tab) && m.focusedInput == 2 {
-		originalPath := m.inputs[2].Value()
+	if key.Matches(msg, m.keys.Input.Tab) && m.focusedInput == inputPath {
+		originalPath := m.inputs[inputPath].Value()
 		browseDir := strings.TrimSpace(originalPath)
 		if browseDir == "" {
 			browseDir = config.Resolve[string](m.Settings.General.DefaultDownloadDir)
@@ -39,18 +59,28 @@ func (m RootModel) updateInput(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		return m, m.openDirectoryPicker(FilePickerOriginAdd, originalPath, browseDir, false, true)
 	}
 
+	if key.Matches(msg, m.keys.Input.RecentDir) {
+		if dir, ok := m.recentDirForKey(msg); ok {
+			m.inputs[inputPath].SetValue(dir)
+			m.focusInput(inputPath)
+		}
+		return m, nil
+	}
+
 	if key.Matches(msg, m.keys.Input.Up) && m.focusedInput > 0 {
 		m.focusInput(m.focusedInput - 1)
 		return m, nil
 	}
 
-	if key.Matches(msg, m.keys.Input.Down) && m.focusedInput < 3 {
+	if key.Matches(msg, m.keys.Input.Down) && m.focusedInput < len(m.inputs)-1 {
 		m.focusInput(m.focusedInput + 1)
 		return m, nil
 	}
 
+	// Enter steps through the basic fields and submits from the filename on;
+	// the optional fields below it are reached with the down key
 	if key.Matches(msg, m.keys.Input.Enter) {
-		if m.focusedInput < 3 {
+		if m.focusedInput < inputFilename {
 			m.focusInput(m.focusedInput + 1)
 			return m, nil
 		}
@@ -63,44 +93,83 @@ func (m RootModel) updateInput(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m RootModel) submitInputForm() (tea.Model, tea.Cmd) {
-	inputVal := m.inputs[0].Value()
-	if inputVal == "" {
+	entries := parseURLEntries(m.inputs[inputURL].Value())
+	if len(entries) == 0 {
 		m.blurAllInputs()
-		m.focusedInput = 0
-		m.inputs[0].Focus()
+		m.focusedInput = inputURL
+		m.inputs[inputURL].Focus()
 		return m, nil
 	}
 
-	url, mirrors := parseURLInput(inputVal)
-
-	// Append mirrors from dedicated mirror input
-	if mirrorsVal := m.inputs[1].Value(); mirrorsVal != "" {
-		for _, part := range strings.Split(mirrorsVal, ",") {
-			if cleaned := strings.TrimSpace(part); cleaned != "" {
-				mirrors = append(mirrors, cleaned)
-			}
-		}
-	}
-
-	if url == "" {
-		m.focusedInput = 0
-		m.inputs[0].Focus()
+	request, headers, field, err := m.formRequestOptions()
+	if err != nil {
+		m.inputError = err.Error()
+		m.focusInput(field)
 		return m, nil
 	}
+	m.inputError = ""
 
-	pathInput := strings.TrimSpace(m.inputs[2].Value())
+	pathInput := strings.TrimSpace(m.inputs[inputPath].Value())
 	path := pathInput
 	isDefaultPath := m.isDefaultDownloadPath(path)
 	if path == "" {
 		isDefaultPath = true
 		path = m.defaultDownloadPath()
 	}
-	filename := m.inputs[3].Value()
+	filename := m.inputs[inputFilename].Value()
+
+	clearForm := func() {
+		m.state = DashboardState
+		for i := range m.inputs {
+			if i != inputPath { // Keep path for next download
+				m.inputs[i].SetValue("")
+			}
+		}
+		m.inputs[inputPath].SetValue(path)
+	}
+
+	// Several URLs each become their own download. Mirrors and a filename
+	// would only fit one of them, so they are not used.
+	if len(entries) > 1 {
+		clearForm()
+		var cmds []tea.Cmd
+		for _, e := range entries {
+			if d := m.checkForDuplicate(e.url); d != nil && config.Resolve[bool](m.Settings.General.WarnOnDuplicate) {
+				m.addLogEntry(LogStyleError.Render("\u2716 Skipped duplicate: " + e.url))
+				continue
+			}
+			var cmd tea.Cmd
+			m, cmd = m.startDownload(downloadStart{
+				URL:           e.url,
+				Mirrors:       e.mirrors,
+				Headers:       headers,
+				Request:       request,
+				Path:          path,
+				IsDefaultPath: isDefaultPath,
+			})
+			cmds = append(cmds, cmd)
+		}
+		return m, tea.Batch(cmds...)
+	}
+
+	url, mirrors := entries[0].url, entries[0].mirrors
+
+	// Append mirrors from dedicated mirror input
+	if mirrorsVal := m.inputs[inputMirrors].Value(); mirrorsVal != "" {
+		for _, part := range strings.Split(mirrorsVal, ",") {
+			if cleaned := strings.TrimSpace(part); cleaned != "" {
+				mirrors = append(mirrors, cleaned)
+			}
+		}
+	}
 
 	if d := m.checkForDuplicate(url); d != nil {
 		m.pendingURL = url
+		m.pendingID = ""
 		m.pendingMirrors = mirrors
-		m.pendingHeaders = nil
+		m.pendingHeaders = headers
+		m.pendingTLS = types.TLSOptions{}
+		m.pendingRequest = request
 		m.pendingPath = path
 		m.pendingIsDefaultPath = isDefaultPath
 		m.pendingFilename = filename
@@ -109,15 +178,76 @@ func (m RootModel) submitInputForm() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	m.state = DashboardState
-	m.inputs[0].SetValue("")
-	m.inputs[1].SetValue("")
-	m.inputs[2].SetValue(path) // Keep path for next download
-	m.inputs[3].SetValue("")
+	clearForm()
+	return m.startDownload(downloadStart{
+		URL:           url,
+		Mirrors:       mirrors,
+		Headers:       headers,
+		Request:       request,
+		Path:          path,
+		IsDefaultPath: isDefaultPath,
+		Filename:      filename,
+	})
+}
+
+// formRequestOptions reads the checksum, headers, connections and priority
+// fields. On error it also returns the field to send the user back to.
+func (m RootModel) formRequestOptions() (types.RequestOptions, map[string]string, int, error) {
+	var request types.RequestOptions
+
+	request.Checksum = strings.TrimSpace(m.inputs[inputChecksum].Value())
+	if request.Checksum != "" {
+		if _, _, err := types.ParseChecksum(request.Checksum); err != nil {
+			return request, nil, inputChecksum, err
+		}
+	}
+
+	headers, err := parseHeaderInput(m.inputs[inputHeaders].Value())
+	if err != nil {
+		return request, nil, inputHeaders, err
+	}
+
+	if raw := strings.TrimSpace(m.inputs[inputConnections].Value()); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > types.MaxRequestConnections {
+			return request, nil, inputConnections, fmt.Errorf("connections must be a number from 1 to %d", types.MaxRequestConnections)
+		}
+		request.Connections = n
+	}
+
+	switch strings.ToLower(strings.TrimSpace(m.inputs[inputPriority].Value())) {
+	case "", "normal":
+	case "low":
+		request.LowPriority = true
+	default:
+		return request, nil, inputPriority, fmt.Errorf("priority must be normal or low")
+	}
 
-	return m.startDownload(url, mirrors, nil, path, isDefaultPath, filename, "")
+	return request, headers, 0, nil
 }
 
+// urlEntry is one download from the URL field: its URL and any mirrors given
+// after it.
+type urlEntry struct {
+	url     string
+	mirrors []string
+}
+
+// parseURLEntries splits the URL field into downloads. Pasted lines arrive
+// joined by spaces, and URLs cannot contain spaces, so whitespace separates
+// downloads, while commas still add mirrors to the URL before them.
+func parseURLEntries(input string) []urlEntry {
+	var entries []urlEntry
+	for _, field := range strings.Fields(commaSpaceRE.ReplaceAllString(input, ",")) {
+		if url, mirrors := parseURLInput(field); url != "" {
+			entries = append(entries, urlEntry{url: url, mirrors: mirrors})
+		}
+	}
+	return entries
+}
+
+var commaSpaceRE = regexp.MustCompile(`\s*,\s*`)
+
 // parseURLInput splits a comma-separated URL string into a primary URL and mirrors.
 func parseURLInput(input string) (url string, mirrors []string) {
 	for _, part := range strings.Split(input, ",") {
@@ -134,6 +264,35 @@ func parseURLInput(input string) (url string, mirrors []string) {
 	return
 }
 
+// parseHeaderInput reads headers given as "Name: value; Other: value". A
+// part without a "Name:" prefix continues the previous value, so a cookie
+// such as "Cookie: a=1; b=2" stays whole.
+func parseHeaderInput(input string) (map[string]string, error) {
+	var headers map[string]string
+	var last string
+	for _, part := range strings.Split(input, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t=") {
+			if last == "" {
+				return nil, fmt.Errorf("header %q: expected Name: value", part)
+			}
+			headers[last] += "; " + part
+			continue
+		}
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		last = http.CanonicalHeaderKey(name)
+		headers[last] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
 func (m RootModel) updateExtensionConfirmation(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	if key.Matches(msg, m.keys.Extension.Browse) && m.focusedInput == 2 {
 		originalPath := m.inputs[2].Value()
@@ -172,7 +331,17 @@ func (m RootModel) updateExtensionConfirmation(msg tea.KeyPressMsg) (tea.Model,
 		}
 
 		m.state = DashboardState
-		updated, cmd := m.startDownload(m.pendingURL, m.pendingMirrors, m.pendingHeaders, m.pendingPath, m.pendingIsDefaultPath, m.pendingFilename, "")
+		updated, cmd := m.startDownload(downloadStart{
+			URL:           m.pendingURL,
+			Mirrors:       m.pendingMirrors,
+			Headers:       m.pendingHeaders,
+			TLS:           m.pendingTLS,
+			Request:       m.pendingRequest,
+			Path:          m.pendingPath,
+			IsDefaultPath: m.pendingIsDefaultPath,
+			Filename:      m.pendingFilename,
+			ID:            m.pendingID,
+		})
 		nextModel, nextCmd := updated.showNextPendingRequest()
 		return nextModel, tea.Batch(cmd, nextCmd)
 	}
@@ -181,7 +350,9 @@ func (m RootModel) updateExtensionConfirmation(msg tea.KeyPressMsg) (tea.Model,
 		m.filepickerOrigin = FilePickerOriginNone
 		m.blurAllInputs()
 		m.state = DashboardState
-		return m.showNextPendingRequest()
+		declined := declineRequestCmd(m.Service, m.pendingID)
+		nextModel, nextCmd := m.showNextPendingRequest()
+		return nextModel, tea.Batch(declined, nextCmd)
 	}
 
 	var cmd tea.Cmd
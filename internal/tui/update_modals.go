@@ -3,6 +3,7 @@ package tui
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -165,14 +166,26 @@ func (m RootModel) updateDuplicateWarning(msg tea.KeyPressMsg) (tea.Model, tea.C
 	if key.Matches(msg, m.keys.Duplicate.Continue) {
 		// Continue anyway - startDownload handles unique filename generation
 		m.state = DashboardState
-		updated, cmd := m.startDownload(m.pendingURL, m.pendingMirrors, m.pendingHeaders, m.pendingPath, m.pendingIsDefaultPath, m.pendingFilename, "")
+		updated, cmd := m.startDownload(downloadStart{
+			URL:           m.pendingURL,
+			Mirrors:       m.pendingMirrors,
+			Headers:       m.pendingHeaders,
+			TLS:           m.pendingTLS,
+			Request:       m.pendingRequest,
+			Path:          m.pendingPath,
+			IsDefaultPath: m.pendingIsDefaultPath,
+			Filename:      m.pendingFilename,
+			ID:            m.pendingID,
+		})
 		nextModel, nextCmd := updated.showNextPendingRequest()
 		return nextModel, tea.Batch(cmd, nextCmd)
 	}
 	if key.Matches(msg, m.keys.Duplicate.Cancel) {
 		// Cancel - don't add
 		m.state = DashboardState
-		return m.showNextPendingRequest()
+		declined := declineRequestCmd(m.Service, m.pendingID)
+		nextModel, nextCmd := m.showNextPendingRequest()
+		return nextModel, tea.Batch(declined, nextCmd)
 	}
 	if key.Matches(msg, m.keys.Duplicate.Focus) {
 		// Focus existing download - find it and select in list
@@ -183,7 +196,9 @@ func (m RootModel) updateDuplicateWarning(msg tea.KeyPressMsg) (tea.Model, tea.C
 			}
 		}
 		m.state = DashboardState
-		return m.showNextPendingRequest()
+		declined := declineRequestCmd(m.Service, m.pendingID)
+		nextModel, nextCmd := m.showNextPendingRequest()
+		return nextModel, tea.Batch(declined, nextCmd)
 	}
 	return m, nil
 }
@@ -251,7 +266,17 @@ func (m RootModel) updateBatchConfirm(msg tea.KeyPressMsg) (tea.Model, tea.Cmd)
 				continue
 			}
 			var cmd tea.Cmd
-			m, cmd = m.startDownload(request.URL, request.Mirrors, request.Headers, requestPath, isDefaultPath, request.Filename, request.ID)
+			m, cmd = m.startDownload(downloadStart{
+				URL:           request.URL,
+				Mirrors:       request.Mirrors,
+				Headers:       request.Headers,
+				TLS:           request.TLS,
+				Request:       request.Request,
+				Path:          requestPath,
+				IsDefaultPath: isDefaultPath,
+				Filename:      request.Filename,
+				ID:            request.ID,
+			})
 			if cmd != nil {
 				batchCmds = append(batchCmds, cmd)
 			}
@@ -264,7 +289,7 @@ func (m RootModel) updateBatchConfirm(msg tea.KeyPressMsg) (tea.Model, tea.Cmd)
 				continue
 			}
 			var cmd tea.Cmd
-			m, cmd = m.startDownload(url, nil, nil, path, true, "", "")
+			m, cmd = m.startDownload(downloadStart{URL: url, Path: path, IsDefaultPath: true})
 			if cmd != nil {
 				batchCmds = append(batchCmds, cmd)
 			}
@@ -658,3 +683,69 @@ func (m RootModel) updatePurgeConfirm(msg tea.KeyPressMsg) (tea.Model, tea.Cmd)
 
 	return m, nil
 }
+
+func (m RootModel) updateLargeDownloadConfirm(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	m, decision, handled := m.handleYesNoSelection(msg)
+	if !handled || decision == yesNoNone {
+		return m, nil
+	}
+
+	pending := m.largeDownload
+	m.largeDownload = nil
+	m.quitConfirmFocused = 0
+	m.state = DashboardState
+
+	if decision != yesNoYes || pending == nil {
+		return m, nil
+	}
+
+	req := pending.req
+	req.Request.Confirmed = true
+	return m.startDownload(downloadStart{
+		URL:           req.URL,
+		Mirrors:       req.Mirrors,
+		Headers:       req.Headers,
+		TLS:           req.TLS,
+		Request:       req.Request,
+		Path:          req.Path,
+		IsDefaultPath: !req.IsExplicitCategory,
+		Filename:      req.Filename,
+		ID:            pending.requestID,
+	})
+}
+
+func (m RootModel) updateResumeMismatch(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	m, decision, handled := m.handleYesNoSelection(msg)
+	if !handled || decision == yesNoNone {
+		return m, nil
+	}
+
+	targetID := m.restartTargetID
+	m.restartTargetID = ""
+	m.quitConfirmFocused = 0
+	m.state = DashboardState
+
+	if decision != yesNoYes {
+		return m, nil
+	}
+
+	d := m.FindDownloadByID(targetID)
+	if d == nil || d.URL == "" || d.Destination == "" {
+		return m, nil
+	}
+	if m.Service == nil {
+		m.addLogEntry(LogStyleError.Render("\u2716 Service unavailable"))
+		return m, nil
+	}
+
+	// The errored entry goes first so its cleanup does not race the new one
+	// for the same file name.
+	url, dir, filename := d.URL, filepath.Dir(d.Destination), d.Filename
+	if err := m.Service.Delete(targetID); err != nil && !errors.Is(err, types.ErrNotFound) {
+		m.addLogEntry(LogStyleError.Render("\u2716 Restart failed: " + err.Error()))
+		return m, nil
+	}
+	m.removeDownloadByID(targetID)
+	m.addLogEntry(LogStyleStarted.Render("\u21bb Restarting: " + filename))
+	return m.startDownload(downloadStart{URL: url, Path: dir, Filename: filename})
+}
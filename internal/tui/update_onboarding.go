@@ -0,0 +1,161 @@
+package tui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/i18n"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// onboardingStep is one question of the first-run setup wizard, answered by
+// editing the named setting.
+type onboardingStep struct {
+	Category string
+	Key      string
+	Question string
+	Hint     string
+}
+
+// onboardingSteps name their question and hint by message ID.
+var onboardingSteps = []onboardingStep{
+	{"General", "default_download_dir", "setup.download_dir", "setup.download_dir_hint"},
+	{"Network", "max_connections_per_host", "setup.connections", "setup.connections_hint"},
+	{"General", "theme", "setup.theme", "setup.theme_hint"},
+	{"General", "clipboard_monitor", "setup.clipboard", "setup.clipboard_hint"},
+	{"Extension", "api_server", "setup.api_server", "setup.api_server_hint"},
+}
+
+// WithOnboarding opens the setup wizard, for the first time Surge runs.
+func (m RootModel) WithOnboarding() RootModel {
+	m.state = OnboardingState
+	m.enterOnboardingStep(0)
+	return m
+}
+
+func (m *RootModel) enterOnboardingStep(step int) {
+	m.onboardingStep = step
+	m.onboardingError = ""
+	m.SettingsInput.Blur()
+
+	s := onboardingSteps[step]
+	setting := m.Settings.FindSetting(s.Category, s.Key)
+	if setting == nil || !onboardingStepIsText(setting) {
+		return
+	}
+	value := formatSettingValueForEdit(setting.Value, setting.Type, s.Key, false)
+	if s.Key == "default_download_dir" && value == "" {
+		value = config.GetDownloadsDir()
+	}
+	m.SettingsInput.SetValue(value)
+	m.SettingsInput.CursorEnd()
+	m.SettingsInput.Focus()
+}
+
+func onboardingStepIsText(setting *config.Setting) bool {
+	return setting.Key != "theme" && setting.Type != "bool"
+}
+
+func (m RootModel) updateOnboarding(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	s := onboardingSteps[m.onboardingStep]
+	setting := m.Settings.FindSetting(s.Category, s.Key)
+	if setting == nil {
+		return m.finishOnboarding(false)
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Onboarding.Skip):
+		return m.finishOnboarding(false)
+
+	case key.Matches(msg, m.keys.Onboarding.Next):
+		if onboardingStepIsText(setting) {
+			if err := m.applyOnboardingInput(s, m.SettingsInput.Value()); err != nil {
+				m.onboardingError = err.Error()
+				return m, nil
+			}
+		}
+		if m.onboardingStep == len(onboardingSteps)-1 {
+			return m.finishOnboarding(true)
+		}
+		m.enterOnboardingStep(m.onboardingStep + 1)
+		return m, nil
+
+	case key.Matches(msg, m.keys.Onboarding.Back):
+		if m.onboardingStep > 0 {
+			m.enterOnboardingStep(m.onboardingStep - 1)
+		}
+		return m, nil
+	}
+
+	if onboardingStepIsText(setting) {
+		var cmd tea.Cmd
+		m.SettingsInput, cmd = m.SettingsInput.Update(msg)
+		return m, cmd
+	}
+
+	if key.Matches(msg, m.keys.Onboarding.Change) {
+		m.onboardingError = ""
+		var err error
+		if s.Key == "theme" {
+			step := 1
+			if msg.String() == "left" {
+				step = 2
+			}
+			next := (config.Resolve[int](setting) + step) % 3
+			err = m.setSettingValue(s.Category, s.Key, strconv.Itoa(next))
+		} else {
+			err = m.setSettingValue(s.Category, s.Key, "")
+		}
+		if err != nil {
+			m.onboardingError = err.Error()
+		}
+	}
+	return m, nil
+}
+
+// applyOnboardingInput stores a typed answer. The download folder is
+// created first so that choosing a new folder does not fail validation.
+func (m *RootModel) applyOnboardingInput(s onboardingStep, value string) error {
+	value = strings.TrimSpace(value)
+	if s.Key == "default_download_dir" && value != "" {
+		if rest, ok := strings.CutPrefix(value, "~"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				value = filepath.Join(home, rest)
+			}
+		}
+		value = utils.EnsureAbsPath(value)
+		if err := os.MkdirAll(value, 0o755); err != nil {
+			return errors.New(i18n.T("setup.create_failed", value, err))
+		}
+	}
+	return m.setSettingValue(s.Category, s.Key, value)
+}
+
+// finishOnboarding saves the answers, or the defaults when the wizard was
+// skipped, so it is not shown again.
+func (m RootModel) finishOnboarding(completed bool) (tea.Model, tea.Cmd) {
+	m.SettingsInput.Blur()
+	m.SettingsInput.SetValue("")
+	m.onboardingError = ""
+	m.state = DashboardState
+	if m.UpdateInfo != nil {
+		m.state = UpdateAvailableState
+	}
+
+	if err := m.persistSettings(); err != nil {
+		m.addLogEntry(LogStyleError.Render("\u2716 " + i18n.T("setup.save_failed", err.Error())))
+		return m, nil
+	}
+	if completed {
+		m.addLogEntry(LogStyleComplete.Render("\u2714 " + i18n.T("setup.saved")))
+	} else {
+		m.addLogEntry(LogStyleComplete.Render("\u2714 " + i18n.T("setup.skipped")))
+	}
+	return m, nil
+}
@@ -3,12 +3,14 @@ package tui
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
 	"github.com/SurgeDM/Surge/internal/clipboard"
 	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/i18n"
 	"github.com/SurgeDM/Surge/internal/utils"
 )
 
@@ -213,6 +215,28 @@ func (m RootModel) updateSettings(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		// Profile cycles through auto, none and the profiles in config.toml
+		if settingKey == "profile" {
+			m.Settings.General.Profile.Value = nextProfileChoice(config.Resolve[string](m.Settings.General.Profile))
+			return m, nil
+		}
+
+		// Language cycles through auto and the available locales
+		if settingKey == "language" {
+			m.Settings.General.Language.Value = nextLanguageChoice(config.Resolve[string](m.Settings.General.Language))
+			return m, nil
+		}
+
+		if settingKey == "list_layout" {
+			m.Settings.General.ListLayout.Value = otherListLayout(config.Resolve[string](m.Settings.General.ListLayout))
+			return m, nil
+		}
+
+		if settingKey == "byte_units" {
+			m.Settings.General.ByteUnits.Value = otherByteUnits(config.Resolve[string](m.Settings.General.ByteUnits))
+			return m, nil
+		}
+
 		// Toggle bool or enter edit mode for other types
 		typ := m.getCurrentSettingType()
 
@@ -286,3 +310,46 @@ func (m RootModel) updateSettings(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 
 	return m, nil
 }
+
+// nextProfileChoice returns the profile selection after current in the
+// cycle auto, none, then each profile defined in config.toml.
+func nextProfileChoice(current string) string {
+	choices := []string{config.ProfileAuto, "none"}
+	profiles, _ := config.LoadProfiles()
+	for _, p := range profiles {
+		choices = append(choices, p.Name)
+	}
+	if current == "" {
+		current = "none"
+	}
+	for i, choice := range choices {
+		if strings.EqualFold(choice, current) {
+			return choices[(i+1)%len(choices)]
+		}
+	}
+	return choices[0]
+}
+
+func nextLanguageChoice(current string) string {
+	choices := append([]string{i18n.Auto}, i18n.Locales()...)
+	for i, choice := range choices {
+		if choice == current {
+			return choices[(i+1)%len(choices)]
+		}
+	}
+	return choices[0]
+}
+
+func otherListLayout(current string) string {
+	if current == config.ListLayoutCompact {
+		return config.ListLayoutDetailed
+	}
+	return config.ListLayoutCompact
+}
+
+func otherByteUnits(current string) string {
+	if current == config.ByteUnitsIEC {
+		return config.ByteUnitsSI
+	}
+	return config.ByteUnitsIEC
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
@@ -23,8 +24,9 @@ import (
 var errTest = errors.New("test error")
 
 func newInputModels() []textinput.Model {
-	inputs := []textinput.Model{textinput.New(), textinput.New(), textinput.New(), textinput.New()}
+	inputs := make([]textinput.Model, addFormInputs)
 	for i := range inputs {
+		inputs[i] = textinput.New()
 		inputs[i].Prompt = ""
 	}
 	return inputs
@@ -499,7 +501,7 @@ func TestUpdate_DownloadRequestMsg(t *testing.T) {
 		Service:     svc,
 		logViewport: viewport.New(viewport.WithWidth(40), viewport.WithHeight(5)),
 		list:        NewDownloadList(40, 10),
-		inputs:      []textinput.Model{textinput.New(), textinput.New(), textinput.New(), textinput.New()},
+		inputs:      newInputModels(),
 	}
 
 	// 1. Test Extension Prompt Enabled
@@ -568,7 +570,7 @@ func TestUpdate_DownloadRequestMsg_QueuesWhileConfirmationActive(t *testing.T) {
 		Settings:    config.DefaultSettings(),
 		logViewport: viewport.New(viewport.WithWidth(40), viewport.WithHeight(5)),
 		list:        NewDownloadList(40, 10),
-		inputs:      []textinput.Model{textinput.New(), textinput.New(), textinput.New(), textinput.New()},
+		inputs:      newInputModels(),
 		keys:        config.DefaultKeyMap(),
 	}
 	m.Settings.Extension.ExtensionPrompt.Value = true
@@ -609,12 +611,57 @@ func TestUpdate_DownloadRequestMsg_QueuesWhileConfirmationActive(t *testing.T) {
 	}
 }
 
+type publishRecordingService struct {
+	mockService
+	published []interface{}
+}
+
+func (s *publishRecordingService) Publish(msg interface{}) error {
+	s.published = append(s.published, msg)
+	return nil
+}
+
+func TestUpdate_DownloadRequestMsg_CancelReportsDecline(t *testing.T) {
+	svc := &publishRecordingService{}
+	m := RootModel{
+		Settings:    config.DefaultSettings(),
+		Service:     svc,
+		logViewport: viewport.New(viewport.WithWidth(40), viewport.WithHeight(5)),
+		list:        NewDownloadList(40, 10),
+		inputs:      newInputModels(),
+		keys:        config.DefaultKeyMap(),
+	}
+	m.Settings.Extension.ExtensionPrompt.Value = true
+
+	updated, _ := m.Update(events.DownloadRequestMsg{ID: "req-1", URL: "https://example.com/a.zip", Path: "/tmp/downloads"})
+	root := updated.(RootModel)
+	_, cmd := root.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if cmd == nil {
+		t.Fatal("cancelling returned no command to report the decline")
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, c := range batch {
+			if c != nil {
+				c()
+			}
+		}
+	}
+
+	if len(svc.published) != 1 {
+		t.Fatalf("published %d messages, want 1", len(svc.published))
+	}
+	if got, ok := svc.published[0].(events.DownloadRequestDeclinedMsg); !ok || got.ID != "req-1" {
+		t.Errorf("published %#v, want a decline of req-1", svc.published[0])
+	}
+}
+
 func TestUpdate_BatchDownloadRequestMsg_QueuesWhileConfirmationActive(t *testing.T) {
 	m := RootModel{
 		Settings:    config.DefaultSettings(),
 		logViewport: viewport.New(viewport.WithWidth(40), viewport.WithHeight(5)),
 		list:        NewDownloadList(40, 10),
-		inputs:      []textinput.Model{textinput.New(), textinput.New(), textinput.New(), textinput.New()},
+		inputs:      newInputModels(),
 		keys:        config.DefaultKeyMap(),
 	}
 	m.Settings.Extension.ExtensionPrompt.Value = true
@@ -673,11 +720,16 @@ func TestStartDownload_UsesProvidedIDWhenServiceSupportsIt(t *testing.T) {
 		Service:  svc,
 		list:     NewDownloadList(80, 20),
 		keys:     config.DefaultKeyMap(),
-		inputs:   []textinput.Model{textinput.New(), textinput.New(), textinput.New(), textinput.New()},
+		inputs:   newInputModels(),
 	}
 
 	requestID := "request-id-123"
-	updated, _ := m.startDownload("https://example.com/file.bin", nil, nil, t.TempDir(), false, "file.bin", requestID)
+	updated, _ := m.startDownload(downloadStart{
+		URL:      "https://example.com/file.bin",
+		Path:     t.TempDir(),
+		Filename: "file.bin",
+		ID:       requestID,
+	})
 
 	if len(updated.downloads) != 1 {
 		t.Fatalf("expected 1 queued download, got %d", len(updated.downloads))
@@ -697,7 +749,7 @@ func TestStartDownload_UsesModelEnqueueContext(t *testing.T) {
 	cancel()
 
 	orchestrator := processing.NewLifecycleManager(
-		func(string, string, string, []string, map[string]string, bool, int64, bool) (string, error) {
+		func(string, string, string, []string, map[string]string, bool, int64, bool, types.ProbeHandoff) (string, error) {
 			t.Fatal("enqueue dispatch should not run after context cancellation")
 			return "", nil
 		},
@@ -714,7 +766,11 @@ func TestStartDownload_UsesModelEnqueueContext(t *testing.T) {
 		logViewport:   viewport.New(viewport.WithWidth(40), viewport.WithHeight(5)),
 	}
 
-	updated, cmd := m.startDownload("https://example.com/file.bin", nil, nil, t.TempDir(), false, "file.bin", "")
+	updated, cmd := m.startDownload(downloadStart{
+		URL:      "https://example.com/file.bin",
+		Path:     t.TempDir(),
+		Filename: "file.bin",
+	})
 	if cmd == nil {
 		t.Fatal("expected enqueue command")
 	}
@@ -739,7 +795,7 @@ func TestStartDownload_GuessesFilenameOptimisticallyWhenProvidedOrInferred(t *te
 	})
 
 	orchestrator := processing.NewLifecycleManager(
-		func(string, string, string, []string, map[string]string, bool, int64, bool) (string, error) {
+		func(string, string, string, []string, map[string]string, bool, int64, bool, types.ProbeHandoff) (string, error) {
 			return "real-id", nil
 		},
 		nil,
@@ -754,7 +810,11 @@ func TestStartDownload_GuessesFilenameOptimisticallyWhenProvidedOrInferred(t *te
 		logViewport:  viewport.New(viewport.WithWidth(40), viewport.WithHeight(5)),
 	}
 
-	updated, _ := m.startDownload("https://example.com/100MB.bin", nil, nil, targetDir, true, "", "")
+	updated, _ := m.startDownload(downloadStart{
+		URL:           "https://example.com/100MB.bin",
+		Path:          targetDir,
+		IsDefaultPath: true,
+	})
 
 	if len(updated.downloads) != 1 {
 		t.Fatalf("expected 1 optimistic queued download, got %d", len(updated.downloads))
@@ -775,7 +835,7 @@ func TestStartDownload_UsesGenericQueuedNameForExplicitFilenameUntilLifecycleCon
 	})
 
 	orchestrator := processing.NewLifecycleManager(
-		func(string, string, string, []string, map[string]string, bool, int64, bool) (string, error) {
+		func(string, string, string, []string, map[string]string, bool, int64, bool, types.ProbeHandoff) (string, error) {
 			return "real-id", nil
 		},
 		nil,
@@ -790,7 +850,11 @@ func TestStartDownload_UsesGenericQueuedNameForExplicitFilenameUntilLifecycleCon
 		logViewport:  viewport.New(viewport.WithWidth(40), viewport.WithHeight(5)),
 	}
 
-	updated, _ := m.startDownload("https://example.com/archive.zip", nil, nil, targetDir, false, "archive.zip", "")
+	updated, _ := m.startDownload(downloadStart{
+		URL:      "https://example.com/archive.zip",
+		Path:     targetDir,
+		Filename: "archive.zip",
+	})
 
 	if len(updated.downloads) != 1 {
 		t.Fatalf("expected 1 optimistic queued download, got %d", len(updated.downloads))
@@ -1057,7 +1121,7 @@ func TestWithEnqueueContext_OverridesStartDownloadContext(t *testing.T) {
 	cancel()
 
 	orchestrator := processing.NewLifecycleManager(
-		func(string, string, string, []string, map[string]string, bool, int64, bool) (string, error) {
+		func(string, string, string, []string, map[string]string, bool, int64, bool, types.ProbeHandoff) (string, error) {
 			t.Fatal("enqueue dispatch should not run after shared context cancellation")
 			return "", nil
 		},
@@ -1067,7 +1131,11 @@ func TestWithEnqueueContext_OverridesStartDownloadContext(t *testing.T) {
 	m := InitialRootModel(1700, "test-version", svc, orchestrator, false)
 	m = m.WithEnqueueContext(ctx, func() {})
 
-	_, cmd := m.startDownload("https://example.com/file.bin", nil, nil, t.TempDir(), false, "file.bin", "")
+	_, cmd := m.startDownload(downloadStart{
+		URL:      "https://example.com/file.bin",
+		Path:     t.TempDir(),
+		Filename: "file.bin",
+	})
 	if cmd == nil {
 		t.Fatal("expected enqueue command")
 	}
@@ -1219,6 +1287,55 @@ func TestUpdate_FilePickerUseDirReturnsToAddInput(t *testing.T) {
 	}
 }
 
+func TestUpdate_RecentDirKeys(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+
+	m := RootModel{
+		state:        InputState,
+		focusedInput: inputURL,
+		inputs:       newInputModels(),
+		keys:         config.DefaultKeyMap(),
+		Settings:     config.DefaultSettings(),
+		filepicker:   newFilepicker(first),
+		recentDirs:   []string{first, second},
+	}
+
+	updated, _ := m.Update(tea.KeyPressMsg{Code: '2', Mod: tea.ModAlt})
+	m2 := unwrapRootModel(t, updated)
+	if got := m2.inputs[inputPath].Value(); got != second {
+		t.Fatalf("path after alt+2 = %q, want %q", got, second)
+	}
+	if m2.focusedInput != inputPath {
+		t.Fatalf("focusedInput after alt+2 = %d, want the path field", m2.focusedInput)
+	}
+
+	// A number without a remembered directory changes nothing
+	updated, _ = m2.Update(tea.KeyPressMsg{Code: '9', Mod: tea.ModAlt})
+	if got := unwrapRootModel(t, updated).inputs[inputPath].Value(); got != second {
+		t.Fatalf("path after alt+9 = %q, want it unchanged", got)
+	}
+
+	m2.state = FilePickerState
+	m2.filepickerOrigin = FilePickerOriginAdd
+	updated, _ = m2.Update(tea.KeyPressMsg{Code: '1', Text: "1"})
+	m3 := unwrapRootModel(t, updated)
+	if m3.state != InputState || m3.inputs[inputPath].Value() != first {
+		t.Fatalf("state = %v, path = %q after 1 in the picker; want %q in the form", m3.state, m3.inputs[inputPath].Value(), first)
+	}
+}
+
+func TestRememberDir_MovesToFront(t *testing.T) {
+	a, b := t.TempDir(), t.TempDir()
+	m := RootModel{}
+
+	m.rememberDir(a)
+	m.rememberDir(b)
+	m.rememberDir(a)
+	if !slices.Equal(m.recentDirs, []string{a, b}) {
+		t.Fatalf("recentDirs = %v, want [%s %s]", m.recentDirs, a, b)
+	}
+}
+
 func TestNewFilepickerEscIsCancelOnly(t *testing.T) {
 	fp := newFilepicker(t.TempDir())
 	esc := tea.KeyPressMsg{Code: tea.KeyEscape}
@@ -1426,7 +1543,7 @@ func TestUpdate_UnlistedStatePasteIsIgnored(t *testing.T) {
 	urlInput.SetValue("https://example.com/original")
 
 	m := RootModel{
-		state:          DetailState,
+		state:          HelpModalState,
 		urlUpdateInput: urlInput,
 		searchActive:   true,
 	}
@@ -1434,10 +1551,60 @@ func TestUpdate_UnlistedStatePasteIsIgnored(t *testing.T) {
 	updated, _ := m.Update(tea.PasteMsg{Content: "https://example.com/new"})
 	m2 := updated.(RootModel)
 
-	if m2.state != DetailState {
+	if m2.state != HelpModalState {
 		t.Fatalf("state changed on ignored paste: got %v", m2.state)
 	}
 	if got := m2.urlUpdateInput.Value(); got != "https://example.com/original" {
 		t.Fatalf("expected unlisted state paste to be ignored, got %q", got)
 	}
 }
+
+// globalPauseService pauses the downloads it is told are running and
+// resumes exactly those.
+type globalPauseService struct {
+	mockService
+	running []string
+	paused  []string
+}
+
+func (s *globalPauseService) PauseAll() ([]string, error) {
+	s.paused, s.running = s.running, nil
+	return s.paused, nil
+}
+
+func (s *globalPauseService) ResumeAll() ([]string, error) {
+	s.running, s.paused = s.paused, nil
+	return s.running, nil
+}
+
+func TestUpdateDashboard_PauseAllToggles(t *testing.T) {
+	active := &DownloadModel{ID: "active", Filename: "active.zip"}
+	own := &DownloadModel{ID: "own", Filename: "own.zip", paused: true}
+	svc := &globalPauseService{running: []string{"active"}}
+
+	m := RootModel{
+		state:     DashboardState,
+		downloads: []*DownloadModel{active, own},
+		Service:   svc,
+		keys:      config.DefaultKeyMap(),
+		list:      NewDownloadList(80, 20),
+	}
+	m.UpdateListItems()
+
+	updated, _ := m.updateDashboard(tea.KeyPressMsg{Code: 'P', Text: "P"})
+	m = updated.(RootModel)
+	if !active.pausing || len(svc.paused) != 1 {
+		t.Fatalf("P with a running download should pause all: pausing=%v paused=%v", active.pausing, svc.paused)
+	}
+
+	// The engine confirms the pause
+	active.pausing, active.paused = false, true
+
+	_, _ = m.updateDashboard(tea.KeyPressMsg{Code: 'P', Text: "P"})
+	if !active.resuming || active.paused {
+		t.Fatalf("P with nothing running should resume what it paused: %+v", active)
+	}
+	if own.resuming || !own.paused {
+		t.Fatal("a download paused on its own was resumed")
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/i18n"
+	"github.com/SurgeDM/Surge/internal/processing"
 	"github.com/SurgeDM/Surge/internal/tui/colors"
 	"github.com/SurgeDM/Surge/internal/tui/components"
 	"github.com/SurgeDM/Surge/internal/utils"
@@ -48,8 +51,56 @@ func formatDurationForUI(d time.Duration) string {
 	return fmt.Sprintf("%d:%02d", mins, secs)
 }
 
+// phaseLabel names what a running download is busy with when that is not
+// moving bytes, such as checking its finished file. ok is false while it
+// transfers or is not running.
+func phaseLabel(d *DownloadModel) (label string, ok bool) {
+	if d.done || d.paused || d.pausing {
+		return "", false
+	}
+	switch d.phase {
+	case types.PhaseProbing:
+		return i18n.T("status.probing"), true
+	case types.PhaseVerifying:
+		return i18n.T("status.verifying"), true
+	case types.PhasePostProcessing:
+		return i18n.T("status.post_processing"), true
+	case types.PhaseWaitingForNetwork:
+		return i18n.T("status.waiting_network"), true
+	default:
+		return "", false
+	}
+}
+
+func phaseBusy(d *DownloadModel) bool {
+	_, ok := phaseLabel(d)
+	return ok
+}
+
+// timeLeft estimates how long an active download has to go at its current
+// speed. ok is false when the download is not transferring or has no size.
+func timeLeft(d *DownloadModel) (left time.Duration, ok bool) {
+	if d.done || d.paused || d.pausing || d.Speed <= 0 || d.Total <= d.Downloaded {
+		return 0, false
+	}
+	return time.Duration(float64(d.Total-d.Downloaded)/d.Speed) * time.Second, true
+}
+
+// finishTime returns when an active download is projected to finish at its
+// current speed, formatted for now. ok is false as for timeLeft.
+func finishTime(d *DownloadModel, now time.Time) (at string, ok bool) {
+	left, ok := timeLeft(d)
+	if !ok {
+		return "", false
+	}
+	return utils.FormatFinishTime(now.Add(left), now), true
+}
+
 // renderModalWithOverlay renders a modal centered on screen with a dark overlay effect
 func (m RootModel) renderModalWithOverlay(modal string) string {
+	if m.Accessible {
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal)
+	}
 	// Place modal centered with dark gray background fill for overlay effect
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal,
 		lipgloss.WithWhitespaceChars(" "), // Changed from "░" to avoid terminal rendering glitches
@@ -58,27 +109,31 @@ func (m RootModel) renderModalWithOverlay(modal string) string {
 }
 
 func (m RootModel) wrapView(content string) tea.View {
+	if m.Accessible {
+		content = plainText(content)
+	}
 	v := tea.NewView(content)
 	v.AltScreen = true
+	v.ReportFocus = true
 	return v
 }
 
 func (m RootModel) View() tea.View {
 	if m.width == 0 {
-		return m.wrapView("Loading...")
+		return m.wrapView(i18n.T("common.loading"))
 	}
 
 	// Terminal too small to render any meaningful layout
 	if m.width < MinTermWidth || m.height < MinTermHeight {
-		msg := lipgloss.NewStyle().Foreground(colors.Cyan()).Render(fmt.Sprintf("Terminal too small (min: %d\u00D7%d)", MinTermWidth, MinTermHeight))
+		msg := lipgloss.NewStyle().Foreground(colors.Cyan()).Render(i18n.T("common.too_small", MinTermWidth, MinTermHeight))
 		return m.wrapView(lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, msg))
 	}
 
 	if m.shuttingDown {
 		modal := components.ConfirmationModal{
-			Title:       "Shutting Down",
-			Message:     "Pausing downloads and saving resume state...",
-			Detail:      "Please wait",
+			Title:       i18n.T("modal.shutting_down_title"),
+			Message:     i18n.T("modal.shutting_down"),
+			Detail:      i18n.T("modal.please_wait"),
 			Keys:        components.NoKeys{},
 			Help:        m.help,
 			BorderColor: colors.Cyan(),
@@ -92,20 +147,28 @@ func (m RootModel) View() tea.View {
 	// These overlays sit on top of the dashboard or replace it
 
 	if m.state == InputState {
+		inputKeys := m.keys.Input
+		inputKeys.RecentDir.SetEnabled(len(m.recentDirs) > 0)
 		modal := components.AddDownloadModal{
-			Title:           "Add Download",
-			Inputs:          []textinput.Model{m.inputs[0], m.inputs[1], m.inputs[2], m.inputs[3]},
-			Labels:          []string{"URL:", "Mirrors:", "Path:", "Filename:"},
+			Title:           i18n.T("modal.add_title"),
+			Inputs:          m.inputs,
+			Labels:          []string{i18n.T("modal.label_url"), i18n.T("modal.label_mirrors"), i18n.T("modal.label_path"), i18n.T("modal.label_filename"), i18n.T("modal.label_checksum"), i18n.T("modal.label_headers"), i18n.T("modal.label_connections"), i18n.T("modal.label_priority")},
 			FocusedInput:    m.focusedInput,
-			BrowseHintIndex: 2,
+			BrowseHintIndex: inputPath,
+			RecentDirs:      m.recentDirs,
+			Error:           m.inputError,
 			Help:            m.help,
-			HelpKeys:        m.keys.Input,
+			HelpKeys:        inputKeys,
 			BorderColor:     colors.Pink(),
 		}
 		// Resolve dynamic dimensions
 		w, _ := GetDynamicModalDimensions(m.width, m.height, 46, 8, 80, 0)
 		modal.Width = w
 		h := lipgloss.Height(modal.View()) + BoxStyle.GetVerticalFrameSize()
+		if h > m.height-2 {
+			modal.Dense = true
+			h = lipgloss.Height(modal.View()) + BoxStyle.GetVerticalFrameSize()
+		}
 		_, modal.Height = GetDynamicModalDimensions(m.width, m.height, 46, 8, w, h)
 
 		box := modal.RenderWithBtopBox(renderBtopBox, PaneTitleStyle)
@@ -115,13 +178,18 @@ func (m RootModel) View() tea.View {
 	if m.state == FilePickerState {
 		// Create a local copy to avoid modifying model during view (though View takes value receiver m)
 		fp := m.filepicker
+		pickerKeys := m.keys.FilePicker
+		pickerKeys.RecentDir.SetEnabled(m.filepickerOrigin != FilePickerOriginTheme && len(m.recentDirs) > 0)
 		picker := components.NewFilePickerModal(
-			" Select Directory ",
+			" "+i18n.T("modal.select_directory")+" ",
 			&fp,
 			m.help,
-			m.keys.FilePicker,
+			pickerKeys,
 			colors.Pink(),
 		)
+		if pickerKeys.RecentDir.Enabled() {
+			picker.RecentDirs = m.recentDirs
+		}
 		// Resolve dynamic dimensions
 		w, h := GetDynamicModalDimensions(m.width, m.height, 60, 10, 90, 20)
 		picker.Width = w
@@ -139,6 +207,10 @@ func (m RootModel) View() tea.View {
 		return m.wrapView(m.renderModalWithOverlay(m.viewSpeedLimits()))
 	}
 
+	if m.state == OnboardingState {
+		return m.wrapView(m.renderModalWithOverlay(m.viewOnboarding()))
+	}
+
 	if m.state == CategoryManagerState {
 		return m.wrapView(m.viewCategoryManager())
 	}
@@ -176,9 +248,9 @@ func (m RootModel) View() tea.View {
 			focused = 1
 		}
 		modal := components.AddDownloadModal{
-			Title:           "Extension Download",
+			Title:           i18n.T("modal.extension_title"),
 			Inputs:          extInputs,
-			Labels:          []string{"Path:", "Filename:"},
+			Labels:          []string{i18n.T("modal.label_path"), i18n.T("modal.label_filename")},
 			FocusedInput:    focused,
 			ShowURL:         true,
 			URL:             m.pendingURL,
@@ -199,11 +271,13 @@ func (m RootModel) View() tea.View {
 
 	if m.state == BatchFilePickerState {
 		fp := m.filepicker
+		pickerKeys := m.keys.FilePicker
+		pickerKeys.RecentDir.SetEnabled(false)
 		picker := components.NewFilePickerModal(
-			" Select URL File (.txt) ",
+			" "+i18n.T("modal.select_url_file")+" ",
 			&fp,
 			m.help,
-			m.keys.FilePicker,
+			pickerKeys,
 			colors.Cyan(),
 		)
 		// Resolve dynamic dimensions
@@ -220,13 +294,13 @@ func (m RootModel) View() tea.View {
 		if len(m.pendingBatchRequests) > 0 {
 			urlCount = len(m.pendingBatchRequests)
 		}
-		batchDetail := fmt.Sprintf("Path: %s", m.inputs[2].View())
+		batchDetail := i18n.T("modal.batch_path", m.inputs[2].View())
 		if m.batchFilePath != "" && m.batchFilePath != strings.TrimSpace(m.inputs[2].Value()) {
-			batchDetail = fmt.Sprintf("Source: %s\nPath: %s", m.batchFilePath, m.inputs[2].View())
+			batchDetail = i18n.T("modal.batch_source", m.batchFilePath) + "\n" + batchDetail
 		}
 		modal := components.ConfirmationModal{
-			Title:       "Batch Import",
-			Message:     fmt.Sprintf("Add %d downloads?", urlCount),
+			Title:       i18n.T("modal.batch_title"),
+			Message:     i18n.T("modal.batch_message", urlCount),
 			Detail:      batchDetail,
 			Keys:        m.keys.BatchConfirm,
 			Help:        m.help,
@@ -314,11 +388,27 @@ func (m RootModel) View() tea.View {
 		return m.wrapView(m.renderModalWithOverlay(m.viewPurgeConfirm()))
 	}
 
+	if m.state == ResumeMismatchState {
+		return m.wrapView(m.renderModalWithOverlay(m.viewResumeMismatch()))
+	}
+
+	if m.state == QRCodeState && len(m.qrShares) > 0 {
+		return m.wrapView(m.renderModalWithOverlay(m.viewQRCode()))
+	}
+
+	if m.state == SpeedHistoryState {
+		return m.wrapView(m.renderModalWithOverlay(m.viewSpeedHistory()))
+	}
+
+	if m.state == LargeDownloadConfirmState && m.largeDownload != nil {
+		return m.wrapView(m.renderModalWithOverlay(m.viewLargeDownloadConfirm()))
+	}
+
 	if m.state == UpdateAvailableState && m.UpdateInfo != nil {
 		modal := components.ConfirmationModal{
-			Title:       "\u2b06 Update Available",
-			Message:     fmt.Sprintf("A new version of Surge is available: %s", m.UpdateInfo.LatestVersion),
-			Detail:      fmt.Sprintf("Current: %s", m.UpdateInfo.CurrentVersion),
+			Title:       "\u2b06 " + i18n.T("modal.update_title"),
+			Message:     i18n.T("modal.update_message", m.UpdateInfo.LatestVersion),
+			Detail:      i18n.T("modal.update_current", m.UpdateInfo.CurrentVersion),
 			Keys:        m.keys.Update,
 			Help:        m.help,
 			BorderColor: colors.Cyan(),
@@ -334,14 +424,16 @@ func (m RootModel) View() tea.View {
 	}
 
 	if m.state == URLUpdateState {
+		inputKeys := m.keys.Input
+		inputKeys.RecentDir.SetEnabled(false)
 		modal := components.AddDownloadModal{
-			Title:           "Refresh URL",
+			Title:           i18n.T("modal.refresh_title"),
 			Inputs:          []textinput.Model{m.urlUpdateInput},
-			Labels:          []string{"New URL:"},
+			Labels:          []string{i18n.T("modal.label_new_url")},
 			FocusedInput:    0,
 			BrowseHintIndex: -1, // No browse hint needed
 			Help:            m.help,
-			HelpKeys:        m.keys.Input,
+			HelpKeys:        inputKeys,
 			BorderColor:     colors.Pink(),
 		}
 		// Resolve dynamic dimensions
@@ -357,7 +449,7 @@ func (m RootModel) View() tea.View {
 	if m.state == HelpModalState {
 		w, h := GetDynamicModalDimensions(m.width, m.height, 40, 10, PopupWidth, 22)
 		modal := components.HelpModal{
-			Title:       "Keyboard Shortcuts",
+			Title:       i18n.T("modal.help_title"),
 			HelpKeys:    m.keys.Dashboard,
 			Help:        m.help,
 			BorderColor: colors.Cyan(),
@@ -368,13 +460,17 @@ func (m RootModel) View() tea.View {
 		return m.wrapView(m.renderModalWithOverlay(box))
 	}
 
+	if m.Accessible {
+		return m.wrapView(m.viewAccessible())
+	}
+
 	// === MAIN DASHBOARD LAYOUT ===
 	layout := CalculateDashboardLayout(m.width, m.height)
 
 	// Footer - keybindings on left, speed/limit/version on bottom-right
 	helpText := lipgloss.NewStyle().PaddingLeft(2).Render(m.help.View(m.keys.Dashboard))
 
-	// --- Right-side footer: speed ｜ limit ｜ version ---
+	// --- Right-side footer: [draining ｜] speed ｜ limit ｜ version ---
 	dimSep := lipgloss.NewStyle().Foreground(colors.Gray()).Render(" \uff5c ")
 
 	// Global speed indicator
@@ -407,13 +503,12 @@ func (m RootModel) View() tea.View {
 	versionBlue := colors.ThemeColor("#005cc5", "#58a6ff")
 	versionChunk := lipgloss.NewStyle().Foreground(versionBlue).Render(fmt.Sprintf("v%s", m.CurrentVersion))
 
-	rightFooter := lipgloss.NewStyle().PaddingRight(2).Render(lipgloss.JoinHorizontal(lipgloss.Center,
-		speedChunk,
-		dimSep,
-		limitChunk,
-		dimSep,
-		versionChunk,
-	))
+	footerChunks := []string{speedChunk, dimSep, limitChunk, dimSep, versionChunk}
+	if m.draining {
+		drainChunk := lipgloss.NewStyle().Foreground(colors.StatePaused()).Bold(true).Render("DRAINING")
+		footerChunks = append([]string{drainChunk, dimSep}, footerChunks...)
+	}
+	rightFooter := lipgloss.NewStyle().PaddingRight(2).Render(lipgloss.JoinHorizontal(lipgloss.Center, footerChunks...))
 
 	// Hide help text at very narrow widths - right footer is more important
 	var footerContent string
@@ -454,7 +549,7 @@ func (m RootModel) View() tea.View {
 	if selected != nil {
 		detailContent = renderFocusedDetails(selected, detailWidth-components.BorderFrameWidth, m.spinner.View())
 	} else {
-		detailContent = renderEmptyMessage(detailWidth-components.BorderFrameWidth, layout.DetailHeight-components.BorderFrameHeight, "No download selected")
+		detailContent = renderEmptyMessage(detailWidth-components.BorderFrameWidth, layout.DetailHeight-components.BorderFrameHeight, i18n.T("empty.no_selection"))
 	}
 
 	// Render Components
@@ -495,6 +590,16 @@ func (m RootModel) View() tea.View {
 		graphBox := m.renderGraphBox(layout.RightWidth, layout.GraphHeight, stats)
 		detailBox := m.renderDetailsBox(layout.RightWidth, layout.DetailHeight, detailContent)
 
+		// The detail pane takes the place of the details and chunk map
+		if m.detailPane {
+			paneHeight := layout.AvailableHeight
+			if layout.GraphHeight >= layout.MinGraphHeight {
+				paneHeight -= layout.GraphHeight
+			}
+			detailBox = m.renderDetailPane(layout.RightWidth, paneHeight, selected)
+			showActualChunkMap = false
+		}
+
 		var rightParts []string
 		if layout.GraphHeight >= layout.MinGraphHeight {
 			rightParts = append(rightParts, graphBox)
@@ -593,14 +698,14 @@ func renderFocusedDetails(d *DownloadModel, w int, spinnerView string) string {
 
 	fileInfoContent := lipgloss.JoinVertical(lipgloss.Left,
 		lipgloss.JoinHorizontal(lipgloss.Top, StatsLabelStyle.Render("URL: "), StatsValueStyle.Width(valueWidth).MaxWidth(valueWidth).Render(utils.TruncateTwoLines(d.URL, valueWidth))),
-		lipgloss.JoinHorizontal(lipgloss.Top, StatsLabelStyle.Render("File: "), StatsValueStyle.Width(valueWidth).MaxWidth(valueWidth).Render(utils.TruncateTwoLines(displayFilename, valueWidth))),
-		lipgloss.JoinHorizontal(lipgloss.Top, StatsLabelStyle.Render("Path: "), StatsValueStyle.Width(valueWidth).MaxWidth(valueWidth).Render(utils.TruncateTwoLines(displayPath, valueWidth))),
+		lipgloss.JoinHorizontal(lipgloss.Top, StatsLabelStyle.Render(i18n.T("details.file")+" "), StatsValueStyle.Width(valueWidth).MaxWidth(valueWidth).Render(utils.TruncateTwoLines(displayFilename, valueWidth))),
+		lipgloss.JoinHorizontal(lipgloss.Top, StatsLabelStyle.Render(i18n.T("details.path")+" "), StatsValueStyle.Width(valueWidth).MaxWidth(valueWidth).Render(utils.TruncateTwoLines(displayPath, valueWidth))),
 		lipgloss.JoinHorizontal(lipgloss.Top, StatsLabelStyle.Render("ID:   "), lipgloss.NewStyle().Foreground(colors.LightGray()).Width(valueWidth).MaxWidth(valueWidth).Render(utils.WrapText(d.ID, valueWidth))),
 	)
 	fileSection := sectionStyle.Render(fileInfoContent)
 
 	// --- 3. Progress Section ---
-	labelStr := "Progress: "
+	labelStr := i18n.T("details.progress") + " "
 	progLabelStyle := lipgloss.NewStyle().Foreground(colors.Cyan())
 
 	var progContent string
@@ -660,33 +765,33 @@ func renderFocusedDetails(d *DownloadModel, w int, spinnerView string) string {
 	if d.done {
 		if elapsed.Seconds() >= 1 {
 			avgSpeed := float64(d.Total) / float64(int(elapsed.Seconds()))
-			speedStr = fmt.Sprintf("%s (Avg)", utils.FormatSpeed(avgSpeed))
+			speedStr = i18n.T("details.average", utils.FormatSpeed(avgSpeed))
 		} else if d.Speed > 0 {
-			speedStr = fmt.Sprintf("%s (Avg)", utils.FormatSpeed(d.Speed))
+			speedStr = i18n.T("details.average", utils.FormatSpeed(d.Speed))
 		} else if elapsed.Seconds() > 0 {
 			avgSpeed := float64(d.Total) / elapsed.Seconds()
-			speedStr = fmt.Sprintf("%s (Avg)", utils.FormatSpeed(avgSpeed))
+			speedStr = i18n.T("details.average", utils.FormatSpeed(avgSpeed))
 		} else {
 			speedStr = "N/A"
 		}
-		etaStr = "Done"
+		etaStr = i18n.T("details.done")
 	} else if d.resuming {
-		speedStr = "Resuming..."
+		speedStr = i18n.T("status.resuming")
 		etaStr = "..."
 	} else if d.paused || d.Speed == 0 {
-		speedStr = "Paused"
+		speedStr = i18n.T("details.paused")
 		if d.RateLimitSet && d.RateLimit > 0 {
-			speedStr += fmt.Sprintf(" (Limit: %s)", utils.FormatRateLimit(d.RateLimit))
+			speedStr += " " + i18n.T("details.limit", utils.FormatRateLimit(d.RateLimit))
 		} else if d.RateLimitSet {
-			speedStr += " (Limit: \u221E)"
+			speedStr += " " + i18n.T("details.limit", "\u221E")
 		}
 		etaStr = "\u221e"
 	} else {
 		speedStr = utils.FormatSpeed(d.Speed)
 		if d.RateLimitSet && d.RateLimit > 0 {
-			speedStr += fmt.Sprintf(" (Limit: %s)", utils.FormatRateLimit(d.RateLimit))
+			speedStr += " " + i18n.T("details.limit", utils.FormatRateLimit(d.RateLimit))
 		} else if d.RateLimitSet {
-			speedStr += " (Limit: \u221E)"
+			speedStr += " " + i18n.T("details.limit", "\u221E")
 		}
 		if d.Total > 0 {
 			remaining := d.Total - d.Downloaded
@@ -703,7 +808,8 @@ func renderFocusedDetails(d *DownloadModel, w int, spinnerView string) string {
 					etaDuration = time.Duration(etaAlpha*float64(etaDuration) + (1-etaAlpha)*float64(d.lastETA))
 				}
 				d.lastETA = etaDuration
-				etaStr = formatDurationForUI(etaDuration)
+				now := time.Now()
+				etaStr = i18n.T("details.eta_finishes", formatDurationForUI(etaDuration), utils.FormatFinishTime(now.Add(etaDuration), now))
 			}
 		} else {
 			etaStr = "\u221e"
@@ -714,9 +820,17 @@ func renderFocusedDetails(d *DownloadModel, w int, spinnerView string) string {
 
 	// Stats Layout
 	colWidth := (contentWidth - (components.BorderFrameWidth * 2)) / 2
+	// Labels share one width, wide enough for the longest translation
+	statLabels := map[string]string{}
+	statLabelWidth := 7
+	for _, id := range []string{"size", "speed", "conns", "time", "eta"} {
+		statLabels[id] = i18n.T("details." + id)
+		statLabelWidth = max(statLabelWidth, lipgloss.Width(statLabels[id])+1)
+	}
+	statLabelStyle := StatsLabelStyle.Width(statLabelWidth)
 	leftColItems := []string{
-		lipgloss.JoinHorizontal(lipgloss.Left, StatsLabelStyle.Width(7).Render("Size:"), StatsValueStyle.Render(sizeStr)),
-		lipgloss.JoinHorizontal(lipgloss.Left, StatsLabelStyle.Width(7).Render("Speed:"), StatsValueStyle.Render(speedStr)),
+		lipgloss.JoinHorizontal(lipgloss.Left, statLabelStyle.Render(statLabels["size"]), StatsValueStyle.Render(sizeStr)),
+		lipgloss.JoinHorizontal(lipgloss.Left, statLabelStyle.Render(statLabels["speed"]), StatsValueStyle.Render(speedStr)),
 	}
 	isActive := !d.done && !d.paused && !d.pausing && d.Speed > 0
 	if isActive {
@@ -725,12 +839,12 @@ func renderFocusedDetails(d *DownloadModel, w int, spinnerView string) string {
 			conns = 1 // Single-connection download (range requests not supported)
 		}
 		connStr := fmt.Sprintf("%d", conns)
-		leftColItems = append(leftColItems, lipgloss.JoinHorizontal(lipgloss.Left, StatsLabelStyle.Width(7).Render("Conns:"), StatsValueStyle.Render(connStr)))
+		leftColItems = append(leftColItems, lipgloss.JoinHorizontal(lipgloss.Left, statLabelStyle.Render(statLabels["conns"]), StatsValueStyle.Render(connStr)))
 	}
 	leftCol := lipgloss.JoinVertical(lipgloss.Left, leftColItems...)
 	rightCol := lipgloss.JoinVertical(lipgloss.Left,
-		lipgloss.JoinHorizontal(lipgloss.Left, StatsLabelStyle.Width(7).Render("Time:"), StatsValueStyle.Render(timeStr)),
-		lipgloss.JoinHorizontal(lipgloss.Left, StatsLabelStyle.Width(7).Render("ETA:"), StatsValueStyle.Render(etaStr)),
+		lipgloss.JoinHorizontal(lipgloss.Left, statLabelStyle.Render(statLabels["time"]), StatsValueStyle.Render(timeStr)),
+		lipgloss.JoinHorizontal(lipgloss.Left, statLabelStyle.Render(statLabels["eta"]), StatsValueStyle.Render(etaStr)),
 	)
 
 	statsContent := lipgloss.JoinHorizontal(lipgloss.Top,
@@ -754,17 +868,29 @@ func renderFocusedDetails(d *DownloadModel, w int, spinnerView string) string {
 			}
 		}
 		// More prominent Mirrors display
-		mirrorLabel := StatsLabelStyle.Render("Mirrors")
-		mirrorStats := lipgloss.NewStyle().Foreground(colors.LightGray()).Render(fmt.Sprintf("%d Active / %d Total (%d Errors)", activeCount, total, errorCount))
+		mirrorLabel := StatsLabelStyle.Render(i18n.T("details.mirrors"))
+		mirrorStats := lipgloss.NewStyle().Foreground(colors.LightGray()).Render(i18n.T("details.mirror_stats", activeCount, total, errorCount))
 
 		mirrorSection = sectionStyle.Render(lipgloss.JoinVertical(lipgloss.Left, mirrorLabel, mirrorStats))
 	}
 
-	// --- 6. Error Section ---
+	// --- 6. Interfaces Section ---
+	var linkSection string
+	if d.state != nil {
+		if links := d.state.GetLinks(); len(links) > 0 {
+			lines := []string{StatsLabelStyle.Render(i18n.T("details.links"))}
+			for _, l := range links {
+				lines = append(lines, lipgloss.NewStyle().Foreground(colors.LightGray()).Render(formatLinkStats(l)))
+			}
+			linkSection = sectionStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+		}
+	}
+
+	// --- 7. Error Section ---
 	var errorSection string
 	if d.err != nil {
 		errorSection = sectionStyle.
-			Render(lipgloss.NewStyle().Foreground(colors.StateError()).Render("Error: " + d.err.Error()))
+			Render(lipgloss.NewStyle().Foreground(colors.StateError()).Render(i18n.T("details.error", d.err.Error())))
 	}
 
 	// Combine with Dividers
@@ -783,6 +909,11 @@ func renderFocusedDetails(d *DownloadModel, w int, spinnerView string) string {
 		parts = append(parts, mirrorSection)
 	}
 
+	if linkSection != "" {
+		parts = append(parts, divider)
+		parts = append(parts, linkSection)
+	}
+
 	if errorSection != "" {
 		parts = append(parts, divider)
 		parts = append(parts, errorSection)
@@ -797,10 +928,13 @@ func renderFocusedDetails(d *DownloadModel, w int, spinnerView string) string {
 
 func getDownloadStatus(d *DownloadModel, spinnerView string) string {
 	if d.pausing {
-		return lipgloss.NewStyle().Foreground(colors.StatePaused()).Render(spinnerView + " Pausing...")
+		return lipgloss.NewStyle().Foreground(colors.StatePaused()).Render(spinnerView + " " + i18n.T("status.pausing"))
 	}
 	if d.resuming {
-		return lipgloss.NewStyle().Foreground(colors.StateDownloading()).Render(spinnerView + " Resuming...")
+		return lipgloss.NewStyle().Foreground(colors.StateDownloading()).Render(spinnerView + " " + i18n.T("status.resuming"))
+	}
+	if label, ok := phaseLabel(d); ok {
+		return lipgloss.NewStyle().Foreground(colors.StatePhase()).Render(spinnerView + " " + label)
 	}
 	status := components.DetermineStatus(d.done, d.paused, d.err != nil, d.Speed, d.Downloaded)
 	return status.RenderWithSpinner(spinnerView)
@@ -824,7 +958,7 @@ func (m RootModel) ComputeViewStats() ViewStats {
 	for _, d := range m.downloads {
 		if d.done {
 			stats.DownloadedCount++
-		} else if !d.paused && !d.pausing && (d.Speed > 0 || d.Connections > 0 || d.resuming) {
+		} else if !d.paused && !d.pausing && (d.Speed > 0 || d.Connections > 0 || d.resuming || phaseBusy(d)) {
 			stats.ActiveCount++
 		} else {
 			stats.QueuedCount++
@@ -836,9 +970,9 @@ func (m RootModel) ComputeViewStats() ViewStats {
 
 func (m RootModel) renderTabs(activeTab, activeCount, queuedCount, doneCount int) string {
 	tabs := []components.Tab{
-		{Label: "Queued", Count: queuedCount, Pinned: m.pinnedTab == TabQueued},
-		{Label: "Active", Count: activeCount, Pinned: m.pinnedTab == TabActive},
-		{Label: "Done", Count: doneCount, Pinned: m.pinnedTab == TabDone},
+		{Label: i18n.T("tabs.queued"), Count: queuedCount, Pinned: m.pinnedTab == TabQueued},
+		{Label: i18n.T("tabs.active"), Count: activeCount, Pinned: m.pinnedTab == TabActive},
+		{Label: i18n.T("tabs.done"), Count: doneCount, Pinned: m.pinnedTab == TabDone},
 	}
 	return components.RenderTabBar(tabs, activeTab, ActiveTabStyle, TabStyle)
 }
@@ -888,14 +1022,14 @@ func (m RootModel) viewQuitConfirm() string {
 	stats := m.ComputeViewStats()
 	detail := ""
 	if stats.ActiveCount > 0 {
-		detail = fmt.Sprintf("%d active download(s) will be paused", stats.ActiveCount)
+		detail = i18n.T("modal.quit_active", stats.ActiveCount)
 	}
 
 	helpStyle := lipgloss.NewStyle().Foreground(colors.Gray()).Width(innerWidth).Align(lipgloss.Center)
 	helpText := helpStyle.Render(m.help.View(m.keys.QuitConfirm))
 
 	var lines []string
-	lines = append(lines, messageStyle.Render("Are you sure you want to quit?"))
+	lines = append(lines, messageStyle.Render(i18n.T("modal.quit_message")))
 	if detail != "" {
 		lines = append(lines, detailStyle.Render(detail))
 	}
@@ -921,7 +1055,7 @@ func (m RootModel) viewQuitConfirm() string {
 	}
 
 	content := lipgloss.JoinVertical(lipgloss.Left, lines...)
-	return renderBtopBox(PaneTitleStyle.Render(" Quit Surge "), "", content, w, h, colors.Pink())
+	return renderBtopBox(PaneTitleStyle.Render(" "+i18n.T("modal.quit_title")+" "), "", content, w, h, colors.Pink())
 }
 
 func (m RootModel) viewRestartConfirm() string {
@@ -970,8 +1104,8 @@ func (m RootModel) viewRestartConfirm() string {
 	helpText := helpStyle.Render(m.help.View(m.keys.QuitConfirm))
 
 	var lines []string
-	lines = append(lines, messageStyle.Render("Settings saved!"))
-	lines = append(lines, detailStyle.Render("Restart now to take effect?"))
+	lines = append(lines, messageStyle.Render(i18n.T("modal.restart_saved")))
+	lines = append(lines, detailStyle.Render(i18n.T("modal.restart_message")))
 	lines = append(lines, "")
 	lines = append(lines, "")
 	lines = append(lines, centeredButtons)
@@ -993,7 +1127,65 @@ func (m RootModel) viewRestartConfirm() string {
 	}
 
 	content := lipgloss.JoinVertical(lipgloss.Left, lines...)
-	return renderBtopBox(PaneTitleStyle.Render(" Restart Required "), "", content, w, h, colors.Orange())
+	return renderBtopBox(PaneTitleStyle.Render(" "+i18n.T("modal.restart_title")+" "), "", content, w, h, colors.Orange())
+}
+
+func (m RootModel) viewResumeMismatch() string {
+	filename := ""
+	if d := m.FindDownloadByID(m.restartTargetID); d != nil {
+		filename = d.Filename
+	}
+	if len(filename) > 30 {
+		filename = filename[:27] + "..."
+	}
+
+	modal := components.ConfirmationModal{
+		Title:            i18n.T("modal.resume_mismatch_title"),
+		Message:          i18n.T("modal.resume_mismatch_message"),
+		Detail:           i18n.T("modal.resume_mismatch_detail", filename),
+		Keys:             m.keys.QuitConfirm,
+		Help:             m.help,
+		BorderColor:      colors.Orange(),
+		ShowYesNoButtons: true,
+		YesNoFocused:     m.quitConfirmFocused,
+		YesLabel:         "Yes",
+		NoLabel:          "No",
+	}
+
+	w, h := GetDynamicModalDimensions(m.width, m.height, 46, 8, 60, 12)
+	modal.Width = w
+	modal.Height = h
+
+	return modal.RenderWithBtopBox(renderBtopBox, PaneTitleStyle)
+}
+
+func (m RootModel) viewLargeDownloadConfirm() string {
+	name := m.largeDownload.req.Filename
+	if name == "" {
+		name = processing.InferFilenameFromURL(m.largeDownload.req.URL)
+	}
+	if len(name) > 30 {
+		name = name[:27] + "..."
+	}
+
+	modal := components.ConfirmationModal{
+		Title:            i18n.T("modal.large_download_title"),
+		Message:          i18n.T("modal.large_download_message"),
+		Detail:           i18n.T("modal.large_download_detail", name, utils.ConvertBytesToHumanReadable(m.largeDownload.size), utils.ConvertBytesToHumanReadable(m.largeDownload.threshold)),
+		Keys:             m.keys.QuitConfirm,
+		Help:             m.help,
+		BorderColor:      colors.Orange(),
+		ShowYesNoButtons: true,
+		YesNoFocused:     m.quitConfirmFocused,
+		YesLabel:         "Yes",
+		NoLabel:          "No",
+	}
+
+	w, h := GetDynamicModalDimensions(m.width, m.height, 46, 8, 60, 12)
+	modal.Width = w
+	modal.Height = h
+
+	return modal.RenderWithBtopBox(renderBtopBox, PaneTitleStyle)
 }
 
 func (m RootModel) viewPurgeConfirm() string {
@@ -1003,15 +1195,15 @@ func (m RootModel) viewPurgeConfirm() string {
 	}
 
 	if filename == "" {
-		filename = "this download"
+		filename = i18n.T("modal.purge_this_download")
 	} else if len(filename) > 30 {
 		filename = filename[:27] + "..."
 	}
 
 	modal := components.ConfirmationModal{
-		Title:            "Purge Download",
-		Message:          "Permanently delete this download?",
-		Detail:           fmt.Sprintf("File: %s\nThis will also remove the downloaded file(s) from disk.", filename),
+		Title:            i18n.T("modal.purge_title"),
+		Message:          i18n.T("modal.purge_message"),
+		Detail:           i18n.T("modal.purge_detail", filename),
 		Keys:             m.keys.QuitConfirm, // QuitConfirm works as a general yes/no
 		Help:             m.help,
 		BorderColor:      colors.Red(),
@@ -1074,8 +1266,8 @@ func (m RootModel) viewCategoryResetConfirm() string {
 	helpText := helpStyle.Render(m.help.View(m.keys.QuitConfirm))
 
 	var lines []string
-	lines = append(lines, messageStyle.Render("Reset all categories to defaults?"))
-	lines = append(lines, detailStyle.Render("This will overwrite your custom rules."))
+	lines = append(lines, messageStyle.Render(i18n.T("modal.category_reset_message")))
+	lines = append(lines, detailStyle.Render(i18n.T("modal.category_reset_detail")))
 	lines = append(lines, "")
 	lines = append(lines, "")
 	lines = append(lines, centeredButtons)
@@ -1097,7 +1289,7 @@ func (m RootModel) viewCategoryResetConfirm() string {
 	}
 
 	content := lipgloss.JoinVertical(lipgloss.Left, lines...)
-	return renderBtopBox(PaneTitleStyle.Render(" Category Reset "), "", content, w, h, colors.Orange())
+	return renderBtopBox(PaneTitleStyle.Render(" "+i18n.T("modal.category_reset_title")+" "), "", content, w, h, colors.Orange())
 }
 
 // renderBtopBox creates a btop-style box with title embedded in the top border
@@ -0,0 +1,170 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"charm.land/lipgloss/v2"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/i18n"
+	"github.com/SurgeDM/Surge/internal/tui/components"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// accessibleLogLines is how much of the activity log the accessible
+// dashboard shows.
+const accessibleLogLines = 3
+
+// viewAccessible renders the dashboard as plain, labelled lines for screen
+// readers and braille displays, in place of the boxed layout.
+func (m RootModel) viewAccessible() string {
+	host := m.ServerHost
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	serverAddr := fmt.Sprintf("%s:%d", host, m.ServerPort)
+	server := i18n.T("header.serving", serverAddr)
+	if m.IsRemote {
+		server = i18n.T("header.connected", serverAddr)
+	} else if m.ServerPort == 0 {
+		server = i18n.T("header.local")
+	}
+
+	stats := m.ComputeViewStats()
+	filtered := m.getFilteredDownloads()
+	tabs := []string{i18n.T("tabs.queued"), i18n.T("tabs.active"), i18n.T("tabs.done")}
+	tab := tabs[0]
+	if m.activeTab >= 0 && m.activeTab < len(tabs) {
+		tab = tabs[m.activeTab]
+	}
+
+	top := []string{
+		i18n.T("accessible.title", m.CurrentVersion, server),
+		i18n.T("accessible.summary", utils.FormatSpeed(float64(m.calcTotalSpeedBps())), stats.ActiveCount, stats.QueuedCount, stats.DownloadedCount),
+		i18n.T("accessible.tab", tab, len(filtered)),
+	}
+	if m.draining {
+		top = append(top, i18n.T("accessible.draining"))
+	}
+
+	var bottom []string
+	selected := m.GetSelectedDownload()
+	if selected != nil {
+		bottom = append(bottom, i18n.T("accessible.selected", accessibleName(selected)))
+		bottom = append(bottom, i18n.T("accessible.url", selected.URL))
+		if selected.Destination != "" {
+			bottom = append(bottom, i18n.T("accessible.saved_to", selected.Destination))
+		}
+		if selected.err != nil {
+			bottom = append(bottom, i18n.T("details.error", selected.err.Error()))
+		}
+	}
+	if n := len(m.logEntries); n > 0 {
+		if len(bottom) > 0 {
+			bottom = append(bottom, "")
+		}
+		bottom = append(bottom, i18n.T("accessible.activity"))
+		for _, entry := range m.logEntries[max(0, n-accessibleLogLines):] {
+			bottom = append(bottom, "  "+entry)
+		}
+	}
+	bottom = append(bottom, "", m.help.View(m.keys.Dashboard))
+
+	rowStyle := lipgloss.NewStyle().MaxWidth(m.width)
+	wrap := lipgloss.NewStyle().Width(m.width)
+	header := wrap.Render(strings.Join(top, "\n"))
+	footer := wrap.Render(strings.Join(bottom, "\n"))
+
+	// The list gets whatever height the other lines leave, at least one row
+	rows := max(1, m.height-lipgloss.Height(header)-lipgloss.Height(footer)-2)
+	var list []string
+	if len(filtered) == 0 {
+		list = append(list, i18n.T("empty.no_downloads"))
+	} else {
+		cursor := 0
+		for i, d := range filtered {
+			if d == selected {
+				cursor = i
+			}
+		}
+		start := max(0, min(cursor-rows/2, len(filtered)-rows))
+		for i := start; i < len(filtered) && i < start+rows; i++ {
+			marker := "  "
+			if i == cursor {
+				marker = "> "
+			}
+			line := fmt.Sprintf("%s%d. %s", marker, i+1, accessibleDownloadLine(filtered[i]))
+			if i == cursor {
+				line = lipgloss.NewStyle().Bold(true).Render(line)
+			}
+			list = append(list, rowStyle.Render(line))
+		}
+	}
+
+	return strings.Join([]string{header, "", strings.Join(list, "\n"), "", footer}, "\n")
+}
+
+func accessibleName(d *DownloadModel) string {
+	if d.Filename == "" || d.Filename == "Queued" {
+		return d.URL
+	}
+	return d.Filename
+}
+
+// accessibleDownloadLine describes a download in words: name, status,
+// percentage, size, speed and time left.
+func accessibleDownloadLine(d *DownloadModel) string {
+	var status string
+	switch {
+	case d.pausing:
+		status = i18n.T("status.pausing")
+	case d.resuming:
+		status = i18n.T("status.resuming")
+	case phaseBusy(d):
+		status, _ = phaseLabel(d)
+	default:
+		status = components.DetermineStatus(d.done, d.paused, d.err != nil, d.Speed, d.Downloaded).Label()
+	}
+
+	parts := []string{accessibleName(d), status}
+	if d.Total > 0 && d.phase == types.PhaseVerifying {
+		parts = append(parts, i18n.T("accessible.percent", int(d.Checked*100/d.Total)))
+	} else if d.Total > 0 {
+		parts = append(parts,
+			i18n.T("accessible.percent", int(d.Downloaded*100/d.Total)),
+			i18n.T("accessible.size", utils.ConvertBytesToHumanReadable(d.Downloaded), utils.ConvertBytesToHumanReadable(d.Total)),
+		)
+	}
+	if !d.done && !d.paused && d.Speed > 0 {
+		parts = append(parts, utils.FormatSpeed(d.Speed))
+		if left, ok := timeLeft(d); ok {
+			parts = append(parts, i18n.T("accessible.left", formatDurationForUI(left)))
+		}
+		if at, ok := finishTime(d, time.Now()); ok {
+			parts = append(parts, i18n.T("list.finishes", at))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// plainText makes rendered output readable by screen readers and braille
+// displays: box and braille drawing become spaces, block elements '#', and
+// pictographic icons are dropped.
+func plainText(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case r >= 0x2500 && r <= 0x257F, r >= 0x2800 && r <= 0x28FF:
+			b.WriteByte(' ')
+		case r >= 0x2580 && r <= 0x259F:
+			b.WriteByte('#')
+		case unicode.Is(unicode.So, r), unicode.Is(unicode.Variation_Selector, r):
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
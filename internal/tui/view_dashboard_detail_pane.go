@@ -0,0 +1,236 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"charm.land/lipgloss/v2"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/i18n"
+	"github.com/SurgeDM/Surge/internal/tui/colors"
+	"github.com/SurgeDM/Surge/internal/tui/components"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// The detail pane's chunk map gets whatever height is left, within these
+// bounds; below the minimum it is left out.
+const (
+	detailPaneMinChunkRows = 3
+	detailPaneMaxChunkRows = 12
+)
+
+// renderDetailPane returns the live detail pane for the selected download as
+// a btop box: metadata, a speed bar per open connection and a chunk map
+// sized to the space that is left.
+func (m RootModel) renderDetailPane(width, height int, d *DownloadModel) string {
+	innerW := max(1, width-components.BorderFrameWidth)
+	innerH := max(1, height-components.BorderFrameHeight)
+	title := PaneTitleStyle.Render(" " + i18n.T("pane.details") + " ")
+
+	if d == nil {
+		content := renderEmptyMessage(innerW, innerH, i18n.T("empty.no_selection"))
+		return renderBtopBox("", title, content, width, height, colors.Gray())
+	}
+
+	// Content is inset by one column on each side
+	w := max(1, innerW-2)
+	divider := lipgloss.NewStyle().Foreground(colors.Gray()).Render(strings.Repeat("\u2500", w))
+
+	lines := detailPaneMetadata(d, w, m.spinner.View())
+	lines = append(lines, divider)
+
+	// Response headers are for debugging, so they get a third of what is left
+	if rows := (innerH - len(lines)) / 3; len(d.ResponseHeaders) > 0 && rows >= 2 {
+		lines = append(lines, StatsLabelStyle.UnsetWidth().Render(i18n.T("detail_pane.response_headers")))
+		lines = append(lines, detailPaneHeaders(d.ResponseHeaders, w, rows-1)...)
+		lines = append(lines, divider)
+	}
+
+	// Connections take at most half of what the metadata leaves, so the
+	// chunk map still gets room
+	speeds := d.ConnectionSpeeds
+	lines = append(lines, StatsLabelStyle.UnsetWidth().Render(i18n.T("detail_pane.connections", len(speeds))))
+	if len(speeds) == 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(colors.Gray()).Render(i18n.T("detail_pane.no_connections")))
+	} else {
+		rows := max(1, (innerH-len(lines))/2)
+		lines = append(lines, detailPaneConnections(speeds, w, rows)...)
+	}
+
+	var bitmap []byte
+	var bitmapWidth int
+	var totalSize, chunkSize int64
+	var chunkProgress []int64
+	if d.state != nil && !d.done {
+		bitmap, bitmapWidth, totalSize, chunkSize, chunkProgress = d.state.GetBitmap()
+	}
+	if rows := min(innerH-len(lines)-2, detailPaneMaxChunkRows); len(bitmap) > 0 && bitmapWidth > 0 && rows >= detailPaneMinChunkRows {
+		lines = append(lines, divider, StatsLabelStyle.UnsetWidth().Render(i18n.T("pane.chunk_map")))
+		chunkMap := components.NewChunkMapModel(bitmap, bitmapWidth, w, rows, d.paused, totalSize, chunkSize, chunkProgress)
+		lines = append(lines, strings.Split(chunkMap.View(), "\n")...)
+	}
+
+	lineStyle := lipgloss.NewStyle().MaxWidth(w)
+	for i, line := range lines {
+		lines[i] = " " + lineStyle.Render(line)
+	}
+	return renderBtopBox("", title, strings.Join(lines, "\n"), width, height, colors.Gray())
+}
+
+// detailPaneMetadata returns one labelled line per fact about the download,
+// each cut to width.
+func detailPaneMetadata(d *DownloadModel, width int, spinnerView string) []string {
+	type row struct{ label, value string }
+
+	name := d.Filename
+	if name == "" || name == "Queued" {
+		name = d.URL
+	}
+	size := utils.ConvertBytesToHumanReadable(d.Downloaded)
+	if d.Total > 0 {
+		size = fmt.Sprintf("%s / %s (%d%%)", size, utils.ConvertBytesToHumanReadable(d.Total), d.Downloaded*100/d.Total)
+	}
+	speed, eta := "-", "-"
+	if left, ok := timeLeft(d); ok {
+		speed = utils.FormatSpeed(d.Speed)
+		now := time.Now()
+		eta = i18n.T("details.eta_finishes", formatDurationForUI(left), utils.FormatFinishTime(now.Add(left), now))
+	} else if d.done {
+		eta = i18n.T("details.done")
+	}
+	if d.RateLimitSet {
+		limit := "\u221e"
+		if d.RateLimit > 0 {
+			limit = utils.FormatRateLimit(d.RateLimit)
+		}
+		speed += " " + i18n.T("details.limit", limit)
+	}
+	elapsed := d.Elapsed
+	if elapsed == 0 && !d.done && !d.StartTime.IsZero() {
+		elapsed = time.Since(d.StartTime)
+	}
+
+	rows := []row{
+		{i18n.T("detail_pane.status"), getDownloadStatus(d, spinnerView)},
+		{i18n.T("details.file"), name},
+		{i18n.T("detail_pane.url"), d.URL},
+		{i18n.T("details.path"), d.Destination},
+		{i18n.T("details.size"), size},
+		{i18n.T("details.speed"), speed},
+		{i18n.T("details.eta"), eta},
+		{i18n.T("details.time"), formatDurationForUI(elapsed)},
+	}
+	if len(d.Tags) > 0 {
+		rows = append(rows, row{i18n.T("detail_pane.tags"), strings.Join(d.Tags, ", ")})
+	}
+	if d.state != nil {
+		if mirrors := d.state.GetMirrors(); len(mirrors) > 0 {
+			active, failed := 0, 0
+			for _, mirror := range mirrors {
+				if mirror.Active {
+					active++
+				}
+				if mirror.Error {
+					failed++
+				}
+			}
+			rows = append(rows, row{i18n.T("details.mirrors") + ":", i18n.T("details.mirror_stats", active, len(mirrors), failed)})
+		}
+		for i, l := range d.state.GetLinks() {
+			label := ""
+			if i == 0 {
+				label = i18n.T("details.links") + ":"
+			}
+			rows = append(rows, row{label, formatLinkStats(l)})
+		}
+	}
+
+	// Labels share one width, wide enough for the longest translation
+	labelWidth := 0
+	for _, r := range rows {
+		labelWidth = max(labelWidth, lipgloss.Width(r.label)+1)
+	}
+	labelStyle := StatsLabelStyle.Width(labelWidth)
+	valueWidth := max(1, width-labelWidth)
+
+	lines := make([]string, 0, len(rows)+1)
+	for i, r := range rows {
+		// The status comes styled with its icon
+		value := r.value
+		if i > 0 {
+			value = StatsValueStyle.Render(utils.TruncateMiddle(value, valueWidth))
+		}
+		lines = append(lines, labelStyle.Render(r.label)+value)
+	}
+	if d.err != nil {
+		lines = append(lines, lipgloss.NewStyle().Foreground(colors.StateError()).Render(utils.Truncate(i18n.T("details.error", d.err.Error()), width)))
+	}
+	return lines
+}
+
+// detailPaneHeaders lists headers as "Name: value" lines sorted by name,
+// using at most rows lines.
+func detailPaneHeaders(headers map[string]string, width, rows int) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	shown := names
+	if len(names) > rows {
+		shown = names[:max(0, rows-1)]
+	}
+	nameStyle := lipgloss.NewStyle().Foreground(colors.Gray())
+	lines := make([]string, 0, len(shown)+1)
+	for _, name := range shown {
+		lines = append(lines, nameStyle.Render(name+":")+" "+utils.Truncate(headers[name], max(1, width-len(name)-2)))
+	}
+	if len(shown) < len(names) {
+		lines = append(lines, nameStyle.Render(i18n.T("detail_pane.more", len(names)-len(shown))))
+	}
+	return lines
+}
+
+// detailPaneConnections draws a bar per connection scaled to the fastest
+// one, using at most rows lines.
+func detailPaneConnections(speeds []float64, width, rows int) []string {
+	fastest := 0.0
+	for _, s := range speeds {
+		fastest = max(fastest, s)
+	}
+
+	shown := speeds
+	if len(speeds) > rows {
+		shown = speeds[:max(0, rows-1)]
+	}
+
+	numWidth := len(fmt.Sprint(len(speeds))) + 1
+	const speedWidth = 11
+	barWidth := max(1, width-numWidth-speedWidth-2)
+	filled := lipgloss.NewStyle().Foreground(colors.StateDownloading())
+	empty := lipgloss.NewStyle().Foreground(colors.Gray())
+
+	lines := make([]string, 0, len(shown)+1)
+	for i, s := range shown {
+		n := 0
+		if fastest > 0 {
+			n = int(s / fastest * float64(barWidth))
+		}
+		bar := filled.Render(strings.Repeat("\u2588", n)) + empty.Render(strings.Repeat("\u2591", barWidth-n))
+		num := fmt.Sprintf("%*s", numWidth, fmt.Sprintf("#%d", i+1))
+		lines = append(lines, fmt.Sprintf("%s %s %*s", num, bar, speedWidth, utils.FormatSpeed(s)))
+	}
+	if len(shown) < len(speeds) {
+		lines = append(lines, lipgloss.NewStyle().Foreground(colors.Gray()).Render(i18n.T("detail_pane.more", len(speeds)-len(shown))))
+	}
+	return lines
+}
+
+// formatLinkStats describes what one interface of a download spread over
+// several has carried.
+func formatLinkStats(l types.LinkStatus) string {
+	return i18n.T("details.link_stats", l.Interface, utils.ConvertBytesToHumanReadable(l.Bytes), utils.FormatSpeed(l.Speed), l.Connections)
+}
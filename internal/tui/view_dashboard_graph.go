@@ -6,6 +6,7 @@ import (
 
 	"charm.land/lipgloss/v2"
 
+	"github.com/SurgeDM/Surge/internal/i18n"
 	"github.com/SurgeDM/Surge/internal/tui/colors"
 	"github.com/SurgeDM/Surge/internal/tui/components"
 	"github.com/SurgeDM/Surge/internal/utils"
@@ -80,7 +81,7 @@ func (m *RootModel) renderGraphBox(width, height int, stats ViewStats) string {
 	buildAxisLines := func(h int, axisStyle lipgloss.Style) []string {
 		label := func(v float64) string {
 			if v <= 0 {
-				return "0 MB/s"
+				return utils.FormatSpeed(0)
 			}
 			return utils.FormatRateLimit(int64(v))
 		}
@@ -152,11 +153,11 @@ func (m *RootModel) renderGraphBox(width, height int, stats ViewStats) string {
 		labelStyleStats := lipgloss.NewStyle().Foreground(colors.LightGray())
 		dimStyle := lipgloss.NewStyle().Foreground(colors.Gray())
 
-		speedStr := "0 MB/s"
+		speedStr := utils.FormatSpeed(0)
 		if currentSpeed > 0 {
 			speedStr = utils.FormatRateLimit(int64(currentSpeed))
 		}
-		topStr := "0 MB/s"
+		topStr := utils.FormatSpeed(0)
 		if topSpeedBps > 0 {
 			topStr = utils.FormatRateLimit(int64(topSpeedBps))
 		}
@@ -165,10 +166,10 @@ func (m *RootModel) renderGraphBox(width, height int, stats ViewStats) string {
 			fmt.Sprintf("%s %s", valueStyle.Render("\u25bc"), valueStyle.Render(speedStr)),
 			dimStyle.Render(fmt.Sprintf("  (%.0f Mbps)", speedMbps)),
 			"",
-			fmt.Sprintf("%s %s", labelStyleStats.Render("Top:"), valueStyle.Render(topStr)),
+			fmt.Sprintf("%s %s", labelStyleStats.Render(i18n.T("graph.top")), valueStyle.Render(topStr)),
 			dimStyle.Render(fmt.Sprintf("  (%.0f Mbps)", topMbps)),
 			"",
-			fmt.Sprintf("%s %s", labelStyleStats.Render("Total:"), valueStyle.Render(utils.ConvertBytesToHumanReadable(stats.TotalDownloaded))),
+			fmt.Sprintf("%s %s", labelStyleStats.Render(i18n.T("graph.total")), valueStyle.Render(utils.ConvertBytesToHumanReadable(stats.TotalDownloaded))),
 		)
 
 		statsBoxStyle := lipgloss.NewStyle().
@@ -193,5 +194,5 @@ func (m *RootModel) renderGraphBox(width, height int, stats ViewStats) string {
 	}
 
 	innerContent := lipgloss.JoinVertical(lipgloss.Left, "", graphWithAxis, "")
-	return renderBtopBox(PaneTitleStyle.Render(" Network Activity "), "", innerContent, width, height, colors.Cyan())
+	return renderBtopBox(PaneTitleStyle.Render(" "+i18n.T("pane.network_activity")+" "), "", innerContent, width, height, colors.Cyan())
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"charm.land/lipgloss/v2"
+	"github.com/SurgeDM/Surge/internal/i18n"
 	"github.com/SurgeDM/Surge/internal/tui/colors"
 	"github.com/SurgeDM/Surge/internal/tui/components"
 )
@@ -47,11 +48,11 @@ func (m *RootModel) renderHeaderBox(width, height int) string {
 			statusLine = lipgloss.NewStyle().Foreground(colors.Cyan()).Bold(true).Render(" " + serverAddr)
 		}
 	} else if m.IsRemote {
-		statusLine = lipgloss.NewStyle().Foreground(colors.Cyan()).Bold(true).Render(" Connected to " + serverAddr)
+		statusLine = lipgloss.NewStyle().Foreground(colors.Cyan()).Bold(true).Render(" " + i18n.T("header.connected", serverAddr))
 	} else if m.ServerPort == 0 {
-		statusLine = lipgloss.NewStyle().Foreground(colors.Gray()).Render(" Local mode")
+		statusLine = lipgloss.NewStyle().Foreground(colors.Gray()).Render(" " + i18n.T("header.local"))
 	} else {
-		statusLine = lipgloss.NewStyle().Foreground(colors.Cyan()).Bold(true).Render(" Serving at " + serverAddr)
+		statusLine = lipgloss.NewStyle().Foreground(colors.Cyan()).Bold(true).Render(" " + i18n.T("header.serving", serverAddr))
 	}
 
 	statusPrefix := greenDot
@@ -89,5 +90,5 @@ func (m *RootModel) renderHeaderBox(width, height int) string {
 		innerContent = lipgloss.JoinVertical(lipgloss.Center, logoBox, serverPortContent)
 	}
 
-	return renderBtopBox("", PaneTitleStyle.Render(" Server "), innerContent, width, height, colors.Gray())
+	return renderBtopBox("", PaneTitleStyle.Render(" "+i18n.T("pane.server")+" "), innerContent, width, height, colors.Gray())
 }
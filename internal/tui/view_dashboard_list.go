@@ -3,6 +3,7 @@ package tui
 import (
 	"charm.land/lipgloss/v2"
 
+	"github.com/SurgeDM/Surge/internal/i18n"
 	"github.com/SurgeDM/Surge/internal/tui/colors"
 	"github.com/SurgeDM/Surge/internal/tui/components"
 )
@@ -62,9 +63,9 @@ func (m *RootModel) renderDownloadsBox(width, height int, stats ViewStats) strin
 	var listContent string
 	if len(m.list.Items()) == 0 {
 		if m.searchQuery != "" {
-			listContent = renderEmptyMessage(listContentWidth, listContentHeight, "No matching downloads")
+			listContent = renderEmptyMessage(listContentWidth, listContentHeight, i18n.T("empty.no_matching"))
 		} else {
-			listContent = renderEmptyMessage(listContentWidth, listContentHeight, "No downloads yet")
+			listContent = renderEmptyMessage(listContentWidth, listContentHeight, i18n.T("empty.no_downloads"))
 		}
 	} else {
 		listContent = m.list.View()
@@ -79,7 +80,7 @@ func (m *RootModel) renderDownloadsBox(width, height int, stats ViewStats) strin
 		downloadsBorderColor = colors.Gray()
 	}
 
-	rightTitle := PaneTitleStyle.Render(" Downloads ")
+	rightTitle := PaneTitleStyle.Render(" " + i18n.T("pane.downloads") + " ")
 
 	return renderBtopBox(leftTitle, rightTitle, innerContent, width, height, downloadsBorderColor)
 }
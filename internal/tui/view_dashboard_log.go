@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"github.com/SurgeDM/Surge/internal/i18n"
 	"github.com/SurgeDM/Surge/internal/tui/colors"
 	"github.com/SurgeDM/Surge/internal/tui/components"
 )
@@ -11,9 +12,14 @@ func (m *RootModel) renderLogBox(width, height int) string {
 		return ""
 	}
 
+	entries, title, empty := m.logEntries, i18n.T("pane.activity_log"), i18n.T("empty.activity_log")
+	if m.downloadLog != nil {
+		entries, title, empty = m.downloadLog.lines, i18n.T("pane.download_log", m.downloadLog.name), i18n.T("empty.download_log")
+	}
+
 	var innerContent string
-	if len(m.logEntries) == 0 {
-		innerContent = renderEmptyMessage(width-components.BorderFrameWidth, height-components.BorderFrameHeight, "Activity log is empty")
+	if len(entries) == 0 {
+		innerContent = renderEmptyMessage(width-components.BorderFrameWidth, height-components.BorderFrameHeight, empty)
 	} else {
 		innerContent = m.logViewport.View()
 	}
@@ -23,5 +29,5 @@ func (m *RootModel) renderLogBox(width, height int) string {
 		logBorderColor = colors.Pink()
 	}
 
-	return renderBtopBox(PaneTitleStyle.Render(" Activity Log "), "", innerContent, width, height, logBorderColor)
+	return renderBtopBox(PaneTitleStyle.Render(" "+title+" "), "", innerContent, width, height, logBorderColor)
 }
@@ -0,0 +1,48 @@
+package tui
+
+import (
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/i18n"
+	"github.com/SurgeDM/Surge/internal/tui/colors"
+	"github.com/SurgeDM/Surge/internal/tui/components"
+)
+
+func (m RootModel) viewOnboarding() string {
+	w, h := GetDynamicModalDimensions(m.width, m.height, 50, 14, 76, 16)
+
+	s := onboardingSteps[m.onboardingStep]
+	setting := m.Settings.FindSetting(s.Category, s.Key)
+	if setting == nil {
+		return ""
+	}
+
+	item := components.ListInputItem{Label: i18n.T(s.Question)}
+	switch {
+	case onboardingStepIsText(setting):
+		item.IsEditing = true
+	case setting.Type == "bool":
+		item.Value = "< " + i18n.T("setup.no") + " >"
+		if config.Resolve[bool](setting) {
+			item.Value = "< " + i18n.T("setup.yes") + " >"
+		}
+	default:
+		item.Value = formatSettingValueForEdit(setting.Value, setting.Type, s.Key, true)
+	}
+
+	input := m.SettingsInput
+	input.SetWidth(max(w-14, 10))
+
+	modal := components.ListInputModal{
+		Title:       i18n.T("setup.title", m.onboardingStep+1, len(onboardingSteps)),
+		Subtitle:    i18n.T(s.Hint),
+		Items:       []components.ListInputItem{item},
+		Input:       input,
+		Help:        m.help,
+		HelpKeys:    m.keys.Onboarding,
+		BorderColor: colors.Magenta(),
+		Width:       w,
+		Height:      h,
+		Error:       m.onboardingError,
+	}
+	return modal.RenderWithBtopBox(renderBtopBox, PaneTitleStyle)
+}
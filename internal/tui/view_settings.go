@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/i18n"
 	"github.com/SurgeDM/Surge/internal/tui/colors"
 	"github.com/SurgeDM/Surge/internal/tui/components"
 	"github.com/SurgeDM/Surge/internal/utils"
@@ -681,7 +682,7 @@ func (m *RootModel) setSettingValue(category, key, value string) error {
 		}
 	case "int64":
 		// Handle KB/MB scaling gracefully if specified
-		if key == "min_chunk_size" {
+		if key == "min_chunk_size" || key == "small_file_threshold" || key == "email_min_size" || key == "confirm_size_threshold" || key == "monthly_quota" {
 			v, err := strconv.ParseFloat(value, 64)
 			if err != nil {
 				return fmt.Errorf("invalid number")
@@ -724,6 +725,10 @@ func (m *RootModel) persistSettings() error {
 	if err := config.SaveSettings(m.Settings); err != nil {
 		return err
 	}
+	// A different profile changes other settings too, so load them back
+	if loaded, err := config.LoadSettings(); err == nil && loaded.ActiveProfile() != m.Settings.ActiveProfile() {
+		m.Settings = loaded
+	}
 	if reloader, ok := m.Service.(interface{ ReloadSettings() error }); ok {
 		if err := reloader.ReloadSettings(); err != nil {
 			return err
@@ -732,6 +737,8 @@ func (m *RootModel) persistSettings() error {
 	if m.Orchestrator != nil {
 		m.Orchestrator.ApplySettings(m.Settings)
 	}
+	m.applyListLayout()
+	config.ApplyByteUnits(m.Settings)
 	return nil
 }
 
@@ -790,12 +797,16 @@ func (m RootModel) getSettingsCount() int {
 func (m RootModel) getSettingUnit() string {
 	key := m.getCurrentSettingKey()
 	switch key {
-	case "min_chunk_size":
+	case "min_chunk_size", "small_file_threshold", "email_min_size", "confirm_size_threshold", "monthly_quota":
 		return " MB"
 	case "worker_buffer_size":
 		return " KB"
 	case "dial_hedge_count":
 		return " conns"
+	case "mirror_hedge_count":
+		return " mirrors"
+	case "max_redirects":
+		return " redirects"
 	case "max_task_retries":
 		return " retries"
 	case "slow_worker_grace_period", "stall_timeout":
@@ -812,7 +823,7 @@ func (m RootModel) getSettingUnit() string {
 // formatSettingValueForEdit returns a plain value without units for editing
 func formatSettingValueForEdit(value interface{}, typ, key string, truncate bool) string {
 	switch key {
-	case "min_chunk_size":
+	case "min_chunk_size", "small_file_threshold", "email_min_size", "confirm_size_threshold", "monthly_quota":
 		if v, ok := asFloat64(value); ok {
 			mb := v / float64(config.MB)
 			return fmt.Sprintf("%.1f", mb)
@@ -849,6 +860,30 @@ func formatSettingValueForEdit(value interface{}, typ, key string, truncate bool
 		}
 	}
 
+	if key == "profile" {
+		if v, ok := value.(string); ok {
+			if v == "" {
+				v = "none"
+			}
+			return "< " + v + " >"
+		}
+	}
+
+	if key == "language" {
+		if v, ok := value.(string); ok {
+			if v != i18n.Auto {
+				v = i18n.Name(v)
+			}
+			return "< " + v + " >"
+		}
+	}
+
+	if key == "list_layout" || key == "byte_units" {
+		if v, ok := value.(string); ok {
+			return "< " + v + " >"
+		}
+	}
+
 	if key == "theme" {
 		if v, ok := asFloat64(value); ok {
 			switch int(v) {
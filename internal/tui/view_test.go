@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/types"
 	"github.com/SurgeDM/Surge/internal/processing"
 
 	tea "charm.land/bubbletea/v2"
@@ -116,6 +118,30 @@ func TestGetDownloadStatus(t *testing.T) {
 			},
 			expected: "⠋ Queued",
 		},
+		{
+			name: "Verifying Phase",
+			model: &DownloadModel{
+				started: true,
+				phase:   types.PhaseVerifying,
+			},
+			expected: "⠋ Verifying...",
+		},
+		{
+			name: "Post-processing Phase",
+			model: &DownloadModel{
+				started: true,
+				phase:   types.PhasePostProcessing,
+			},
+			expected: "⠋ Finishing...",
+		},
+		{
+			name: "Paused While Verifying",
+			model: &DownloadModel{
+				paused: true,
+				phase:  types.PhaseVerifying,
+			},
+			expected: "Paused",
+		},
 	}
 
 	for _, tt := range tests {
@@ -649,6 +675,27 @@ func TestFooter_VersionShown(t *testing.T) {
 	}
 }
 
+func TestFooter_ShowsDraining(t *testing.T) {
+	InitializeTUI()
+	m := InitialRootModel(1701, "1.0.0", nil, processing.NewLifecycleManager(nil, nil), false)
+	m.width = 120
+	m.height = 35
+
+	if last := footerLine(m); strings.Contains(last, "DRAINING") {
+		t.Fatalf("footer shows DRAINING before drain mode, got: %q", last)
+	}
+	updated, _ := m.Update(events.DrainModeMsg{Draining: true})
+	m = updated.(RootModel)
+	if last := footerLine(m); !strings.Contains(last, "DRAINING") {
+		t.Errorf("footer should show DRAINING, got: %q", last)
+	}
+	updated, _ = m.Update(events.DrainModeMsg{Draining: false})
+	m = updated.(RootModel)
+	if last := footerLine(m); strings.Contains(last, "DRAINING") {
+		t.Errorf("footer still shows DRAINING after drain mode ended, got: %q", last)
+	}
+}
+
 func TestFooter_IdleSpeedShowsZero(t *testing.T) {
 	InitializeTUI()
 	// No active downloads \u2192 speed should render as "0 B/s"
@@ -770,3 +817,47 @@ func TestFooter_NoLineOverflowAtVariousSizes(t *testing.T) {
 		}
 	}
 }
+
+func TestView_AccessibleDashboardIsPlainText(t *testing.T) {
+	m := InitialRootModel(1701, "test-version", nil, processing.NewLifecycleManager(nil, nil), false)
+	m.Accessible = true
+	m.width = 80
+	m.height = 24
+	m.activeTab = TabActive
+	m.downloads = []*DownloadModel{
+		{ID: "a", Filename: "file.zip", Total: 1000, Downloaded: 450, Speed: 100, started: true},
+	}
+	m.addLogEntry(LogStyleStarted.Render("\u2b07 Started: file.zip"))
+
+	plain := ansiEscapeRE.ReplaceAllString(m.View().Content, "")
+	if !strings.Contains(plain, "file.zip, Downloading, 45 percent") {
+		t.Fatalf("expected a labelled download line, got:\n%s", plain)
+	}
+	if !strings.Contains(plain, "Serving at 127.0.0.1:1701") {
+		t.Fatalf("expected the server status, got:\n%s", plain)
+	}
+	for _, r := range plain {
+		if (r >= 0x2500 && r <= 0x259F) || r == '\u2b07' {
+			t.Fatalf("accessible view contains drawing glyph %q:\n%s", r, plain)
+		}
+	}
+	if lines := strings.Split(strings.TrimRight(plain, "\n"), "\n"); len(lines) > m.height {
+		t.Fatalf("accessible view has %d lines, more than the %d-line terminal", len(lines), m.height)
+	}
+}
+
+func TestView_AccessibleModalHasNoBoxDrawing(t *testing.T) {
+	m := InitialRootModel(1701, "test-version", nil, processing.NewLifecycleManager(nil, nil), false)
+	m.Accessible = true
+	m.state = QuitConfirmState
+	m.width = 80
+	m.height = 24
+
+	plain := ansiEscapeRE.ReplaceAllString(m.View().Content, "")
+	if !strings.Contains(plain, "Are you sure you want to quit?") {
+		t.Fatalf("expected the quit question, got:\n%s", plain)
+	}
+	if strings.ContainsAny(plain, "\u2500\u2502\u256d\u256e\u256f\u2570") {
+		t.Fatalf("accessible modal still has box drawing:\n%s", plain)
+	}
+}
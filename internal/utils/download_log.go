@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// downloadLogLines is how many lines are kept per download; older ones are
+// dropped first.
+const downloadLogLines = 500
+
+// LogLine is one line of a download's log.
+type LogLine struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+var downloadLogs = struct {
+	mu    sync.Mutex
+	lines map[string][]LogLine
+}{lines: make(map[string][]LogLine)}
+
+// DebugFor writes a message about the download id to the debug log, tagged
+// with its ID, and keeps it in the download's own log. The download's log
+// is kept even when debug logging is off, so one download can be looked into
+// without the global log.
+func DebugFor(id string, format string, args ...any) {
+	if id == "" {
+		Debug(format, args...)
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+
+	downloadLogs.mu.Lock()
+	lines := append(downloadLogs.lines[id], LogLine{Time: time.Now(), Message: msg})
+	if len(lines) > downloadLogLines {
+		lines = append([]LogLine(nil), lines[len(lines)-downloadLogLines:]...)
+	}
+	downloadLogs.lines[id] = lines
+	downloadLogs.mu.Unlock()
+
+	Debug("[%s] %s", shortID(id), msg)
+}
+
+// DownloadLog returns the lines logged for the download id, oldest first.
+func DownloadLog(id string) []LogLine {
+	downloadLogs.mu.Lock()
+	defer downloadLogs.mu.Unlock()
+	return append([]LogLine(nil), downloadLogs.lines[id]...)
+}
+
+// ForgetDownloadLog drops the log of the download id.
+func ForgetDownloadLog(id string) {
+	downloadLogs.mu.Lock()
+	delete(downloadLogs.lines, id)
+	downloadLogs.mu.Unlock()
+}
+
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
@@ -0,0 +1,54 @@
+package utils_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+func TestDebugFor_KeepsLinesPerDownload(t *testing.T) {
+	t.Cleanup(func() {
+		utils.ForgetDownloadLog("download-a")
+		utils.ForgetDownloadLog("download-b")
+	})
+
+	utils.DebugFor("download-a", "worker %d started", 1)
+	utils.DebugFor("download-b", "other download")
+	utils.DebugFor("download-a", "worker %d finished", 1)
+
+	lines := utils.DownloadLog("download-a")
+	if len(lines) != 2 {
+		t.Fatalf("lines = %d, want 2", len(lines))
+	}
+	if lines[0].Message != "worker 1 started" || lines[1].Message != "worker 1 finished" {
+		t.Fatalf("messages = %q, %q", lines[0].Message, lines[1].Message)
+	}
+	if lines[0].Time.IsZero() {
+		t.Fatal("line time is zero")
+	}
+
+	utils.ForgetDownloadLog("download-a")
+	if got := utils.DownloadLog("download-a"); len(got) != 0 {
+		t.Fatalf("lines after forget = %d, want 0", len(got))
+	}
+	if got := utils.DownloadLog("download-b"); len(got) != 1 {
+		t.Fatalf("other download lines = %d, want 1", len(got))
+	}
+}
+
+func TestDebugFor_DropsOldestLines(t *testing.T) {
+	t.Cleanup(func() { utils.ForgetDownloadLog("download-c") })
+
+	for i := range 600 {
+		utils.DebugFor("download-c", "line %d", i)
+	}
+
+	lines := utils.DownloadLog("download-c")
+	if len(lines) != 500 {
+		t.Fatalf("lines = %d, want 500", len(lines))
+	}
+	if want := fmt.Sprintf("line %d", 100); lines[0].Message != want {
+		t.Fatalf("oldest line = %q, want %q", lines[0].Message, want)
+	}
+}
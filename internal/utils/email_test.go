@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildEmailMessage(t *testing.T) {
+	date := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	msg := string(buildEmailMessage("surge@example.com", []string{"a@example.com", "b@example.com"}, "Fertig: Übung.iso", "line one\nline two", date))
+
+	for _, want := range []string{
+		"From: surge@example.com\r\n",
+		"To: a@example.com, b@example.com\r\n",
+		"Subject: =?utf-8?q?Fertig:_=C3=9Cbung.iso?=\r\n",
+		"Date: Fri, 02 Jan 2026 03:04:05 +0000\r\n",
+		"\r\n\r\nline one\r\nline two\r\n",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message is missing %q:\n%s", want, msg)
+		}
+	}
+}
+
+func TestParseSMTPServer(t *testing.T) {
+	for _, raw := range []string{"smtp://mail.local", "smtps://u:p@mail.local:465"} {
+		if _, err := ParseSMTPServer(raw); err != nil {
+			t.Errorf("ParseSMTPServer(%q) returned error: %v", raw, err)
+		}
+	}
+	for _, raw := range []string{"mail.local:25", "smtp://"} {
+		if _, err := ParseSMTPServer(raw); err == nil {
+			t.Errorf("ParseSMTPServer(%q) should fail", raw)
+		}
+	}
+}
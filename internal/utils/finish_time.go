@@ -0,0 +1,20 @@
+package utils
+
+import "time"
+
+// FormatFinishTime formats at, a projected completion time, as briefly as it
+// reads unambiguously from now: the clock time when it is today, with the
+// weekday within the coming week, and with the date beyond that.
+func FormatFinishTime(at, now time.Time) string {
+	at = at.In(now.Location())
+	y1, m1, d1 := at.Date()
+	y2, m2, d2 := now.Date()
+	switch {
+	case y1 == y2 && m1 == m2 && d1 == d2:
+		return at.Format("15:04")
+	case at.Sub(now) < 6*24*time.Hour:
+		return at.Format("Mon 15:04")
+	default:
+		return at.Format("Jan 2 15:04")
+	}
+}
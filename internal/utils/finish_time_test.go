@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatFinishTime(t *testing.T) {
+	now := time.Date(2024, 3, 4, 22, 10, 0, 0, time.UTC) // a Monday
+	tests := []struct {
+		left time.Duration
+		want string
+	}{
+		{90 * time.Minute, "23:40"},
+		{5 * time.Hour, "Tue 03:10"},
+		{3 * 24 * time.Hour, "Thu 22:10"},
+		{10 * 24 * time.Hour, "Mar 14 22:10"},
+	}
+	for _, tt := range tests {
+		if got := FormatFinishTime(now.Add(tt.left), now); got != tt.want {
+			t.Errorf("FormatFinishTime(now+%v) = %q, want %q", tt.left, got, tt.want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -31,3 +32,69 @@ func CopyRedirectHeaders(dst, src *http.Request) {
 		}
 	}
 }
+
+// MergeHeaders returns base with the headers in update set over it. A header
+// updated to an empty value is removed. Names are compared canonically.
+func MergeHeaders(base, update map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(update))
+	for key, val := range base {
+		merged[http.CanonicalHeaderKey(key)] = val
+	}
+	for key, val := range update {
+		key = http.CanonicalHeaderKey(key)
+		if val == "" {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = val
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// ContentRange is a parsed "bytes start-end/total" Content-Range value.
+// Start and End are -1 in the "bytes */total" form sent with a 416, and
+// Total is -1 when the server sent "*" for it.
+type ContentRange struct {
+	Start, End, Total int64
+}
+
+// ParseContentRange parses a Content-Range response header, reporting false
+// when it is missing or malformed.
+func ParseContentRange(value string) (ContentRange, bool) {
+	cr := ContentRange{Start: -1, End: -1, Total: -1}
+	spec, ok := strings.CutPrefix(strings.TrimSpace(value), "bytes ")
+	if !ok {
+		return cr, false
+	}
+	span, total, ok := strings.Cut(strings.TrimSpace(spec), "/")
+	if !ok {
+		return cr, false
+	}
+	if total = strings.TrimSpace(total); total != "*" {
+		n, err := strconv.ParseInt(total, 10, 64)
+		if err != nil || n < 0 {
+			return cr, false
+		}
+		cr.Total = n
+	}
+	if span == "*" {
+		return cr, cr.Total >= 0
+	}
+	startStr, endStr, ok := strings.Cut(span, "-")
+	if !ok {
+		return cr, false
+	}
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 {
+		return cr, false
+	}
+	end, err := strconv.ParseInt(endStr, 10, 64)
+	if err != nil || end < start || (cr.Total >= 0 && end >= cr.Total) {
+		return cr, false
+	}
+	cr.Start, cr.End = start, end
+	return cr, true
+}
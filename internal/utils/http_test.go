@@ -0,0 +1,27 @@
+package utils
+
+import "testing"
+
+func TestParseContentRange(t *testing.T) {
+	tests := []struct {
+		value string
+		want  ContentRange
+		ok    bool
+	}{
+		{"bytes 0-499/1234", ContentRange{0, 499, 1234}, true},
+		{" bytes 500-999/* ", ContentRange{500, 999, -1}, true},
+		{"bytes */1234", ContentRange{-1, -1, 1234}, true},
+		{"bytes */*", ContentRange{-1, -1, -1}, false},
+		{"bytes 10-5/100", ContentRange{-1, -1, 100}, false},
+		{"bytes 0-100/100", ContentRange{-1, -1, 100}, false},
+		{"bytes 0-99", ContentRange{-1, -1, -1}, false},
+		{"items 0-99/100", ContentRange{-1, -1, -1}, false},
+		{"", ContentRange{-1, -1, -1}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseContentRange(tt.value)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("ParseContentRange(%q) = %+v, %v; want %+v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
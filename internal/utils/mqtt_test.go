@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"testing"
+)
+
+// readMQTTPacket reads one packet from a client and returns its header byte
+// and body.
+func readMQTTPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7F) * multiplier
+		multiplier *= 128
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	body := make([]byte, length)
+	_, err = io.ReadFull(r, body)
+	return header, body, err
+}
+
+func TestMQTTClient_ConnectAndPublish(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	type packet struct {
+		header byte
+		body   []byte
+	}
+	received := make(chan packet, 3)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		r := bufio.NewReader(conn)
+		for i := range 3 {
+			header, body, err := readMQTTPacket(r)
+			if err != nil {
+				return
+			}
+			received <- packet{header, body}
+			if i == 0 {
+				_, _ = conn.Write([]byte{0x20, 2, 0, 0})
+			}
+		}
+	}()
+
+	client, err := DialMQTT("mqtt://user:secret@"+ln.Addr().String(), "surge-test")
+	if err != nil {
+		t.Fatalf("DialMQTT returned error: %v", err)
+	}
+	if err := client.Publish("surge/complete", []byte(`{"ok":true}`)); err != nil {
+		t.Fatalf("Publish returned error: %v", err)
+	}
+	_ = client.Close()
+
+	connect := <-received
+	if connect.header != 0x10 || string(connect.body[2:6]) != "MQTT" || connect.body[7] != 0xC2 {
+		t.Fatalf("CONNECT = %x %q, want MQTT 3.1.1 with credentials", connect.header, connect.body)
+	}
+	publish := <-received
+	if want := "\x00\x0esurge/complete{\"ok\":true}"; publish.header != 0x30 || string(publish.body) != want {
+		t.Fatalf("PUBLISH = %x %q, want %q", publish.header, publish.body, want)
+	}
+	if disconnect := <-received; disconnect.header != 0xE0 {
+		t.Fatalf("last packet header = %x, want DISCONNECT", disconnect.header)
+	}
+}
+
+func TestMQTTPacket_LongRemainingLength(t *testing.T) {
+	packet := mqttPacket(0x30, make([]byte, 321))
+	if packet[1] != 0xC1 || packet[2] != 0x02 {
+		t.Fatalf("remaining length bytes = %x %x, want c1 02", packet[1], packet[2])
+	}
+}
+
+func TestParseMQTTBroker(t *testing.T) {
+	for _, raw := range []string{"mqtt://broker.local", "mqtts://u:p@broker.local:8883"} {
+		if _, err := ParseMQTTBroker(raw); err != nil {
+			t.Errorf("ParseMQTTBroker(%q) returned error: %v", raw, err)
+		}
+	}
+	for _, raw := range []string{"http://broker.local", "mqtt://"} {
+		if _, err := ParseMQTTBroker(raw); err == nil {
+			t.Errorf("ParseMQTTBroker(%q) should fail", raw)
+		}
+	}
+}
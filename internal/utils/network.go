@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// ActiveInterfaces returns the names of network interfaces that are up and
+// have an address, loopback excluded.
+func ActiveInterfaces() []string {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		if addrs, err := iface.Addrs(); err != nil || len(addrs) == 0 {
+			continue
+		}
+		names = append(names, iface.Name)
+	}
+	return names
+}
+
+// NetworkFingerprint summarizes the interfaces that are up and their
+// addresses, so comparing two fingerprints tells whether the machine moved
+// to a different network.
+func NetworkFingerprint() string {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil || len(addrs) == 0 {
+			continue
+		}
+		b.WriteString(iface.Name)
+		for _, a := range addrs {
+			b.WriteString(" " + a.String())
+		}
+		b.WriteString(";")
+	}
+	return b.String()
+}
+
+// WiFiSSIDs returns the SSIDs of the Wi-Fi networks this machine is connected
+// to, using the platform's own tools. It returns nil when there is no Wi-Fi
+// or the tools are missing.
+func WiFiSSIDs() []string {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	switch runtime.GOOS {
+	case "linux":
+		if out, err := exec.CommandContext(ctx, "nmcli", "-t", "-f", "active,ssid", "dev", "wifi").Output(); err == nil {
+			return parseNmcliSSIDs(out)
+		}
+		if out, err := exec.CommandContext(ctx, "iwgetid", "-r").Output(); err == nil {
+			return nonEmptyLines(out)
+		}
+	case "darwin":
+		if out, err := exec.CommandContext(ctx, "networksetup", "-getairportnetwork", "en0").Output(); err == nil {
+			if _, ssid, ok := strings.Cut(string(out), "Current Wi-Fi Network:"); ok {
+				return nonEmptyLines([]byte(ssid))
+			}
+		}
+	case "windows":
+		if out, err := exec.CommandContext(ctx, "netsh", "wlan", "show", "interfaces").Output(); err == nil {
+			return parseNetshSSIDs(out)
+		}
+	}
+	return nil
+}
+
+// parseNmcliSSIDs reads `nmcli -t -f active,ssid dev wifi` output, where the
+// connected network is marked "yes".
+func parseNmcliSSIDs(out []byte) []string {
+	var ssids []string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		active, ssid, ok := strings.Cut(scanner.Text(), ":")
+		if ok && active == "yes" && ssid != "" {
+			ssids = append(ssids, strings.ReplaceAll(ssid, `\:`, ":"))
+		}
+	}
+	return ssids
+}
+
+// parseNetshSSIDs reads the "SSID : name" lines of `netsh wlan show
+// interfaces`, skipping the BSSID lines.
+func parseNetshSSIDs(out []byte) []string {
+	var ssids []string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if ok && strings.TrimSpace(key) == "SSID" {
+			if ssid := strings.TrimSpace(value); ssid != "" {
+				ssids = append(ssids, ssid)
+			}
+		}
+	}
+	return ssids
+}
+
+func nonEmptyLines(out []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,15 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSSIDs(t *testing.T) {
+	nmcli := "no:Neighbour\nyes:Home\\:5G\nno:\n"
+	assert.Equal(t, []string{"Home:5G"}, parseNmcliSSIDs([]byte(nmcli)))
+
+	netsh := "    Name                   : Wi-Fi\r\n    SSID                   : Office\r\n    BSSID                  : aa:bb:cc:dd:ee:ff\r\n"
+	assert.Equal(t, []string{"Office"}, parseNetshSSIDs([]byte(netsh)))
+}
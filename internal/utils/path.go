@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"os"
 	"path/filepath"
 	"strings"
 )
@@ -12,12 +13,25 @@ func EnsureAbsPath(path string) string {
 	if path == "" {
 		path = "."
 	}
+	path = ExpandHome(path)
 	if abs, err := filepath.Abs(path); err == nil {
 		return abs
 	}
 	return path
 }
 
+// ExpandHome replaces a leading "~" with the user's home directory.
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
 // IsWindowsAbsPath reports whether p looks like a Windows absolute path even
 // when running on a non-Windows host (for example inside Docker on Linux).
 func IsWindowsAbsPath(p string) bool {
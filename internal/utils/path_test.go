@@ -11,6 +11,10 @@ func TestEnsureAbsPath(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	tests := []struct {
 		name string
@@ -37,6 +41,11 @@ func TestEnsureAbsPath(t *testing.T) {
 			in:   wd,
 			want: wd,
 		},
+		{
+			name: "home",
+			in:   "~/foo",
+			want: filepath.Join(home, "foo"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,31 @@
+//go:build linux
+
+package utils
+
+import (
+	"runtime"
+	"syscall"
+)
+
+const (
+	ioprioWhoProcess      = 1
+	ioprioClassBestEffort = 2
+	ioprioClassShift      = 13
+	ioprioLowest          = 7
+	backgroundNice        = 10
+)
+
+// LowerThreadPriority drops the disk and CPU priority of the calling
+// goroutine's thread, like `ionice -c2 -n7 nice -n10`. The goroutine stays
+// locked to the thread so the lower priority never leaks to other goroutines;
+// the thread exits with it, so only call this from a goroutine that ends with
+// the low-priority work.
+func LowerThreadPriority() error {
+	runtime.LockOSThread()
+	tid := syscall.Gettid()
+	prio := ioprioClassBestEffort<<ioprioClassShift | ioprioLowest
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid), uintptr(prio)); errno != 0 {
+		return errno
+	}
+	return syscall.Setpriority(syscall.PRIO_PROCESS, tid, backgroundNice)
+}
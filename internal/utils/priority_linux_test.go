@@ -0,0 +1,46 @@
+//go:build linux
+
+package utils
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestLowerThreadPriority(t *testing.T) {
+	type result struct {
+		ioprio, nice int
+		err          error
+	}
+	done := make(chan result, 1)
+	go func() {
+		if err := LowerThreadPriority(); err != nil {
+			done <- result{err: err}
+			return
+		}
+		tid := syscall.Gettid()
+		ioprio, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_GET, ioprioWhoProcess, uintptr(tid), 0)
+		if errno != 0 {
+			done <- result{err: errno}
+			return
+		}
+		// The raw syscall returns 20 - nice.
+		prio, _, errno := syscall.Syscall(syscall.SYS_GETPRIORITY, syscall.PRIO_PROCESS, uintptr(tid), 0)
+		if errno != 0 {
+			done <- result{err: errno}
+			return
+		}
+		done <- result{ioprio: int(ioprio), nice: 20 - int(prio)}
+	}()
+
+	r := <-done
+	if r.err != nil {
+		t.Skipf("cannot change thread priority here: %v", r.err)
+	}
+	if want := ioprioClassBestEffort<<ioprioClassShift | ioprioLowest; r.ioprio != want {
+		t.Errorf("ioprio = %#x, want %#x", r.ioprio, want)
+	}
+	if r.nice != backgroundNice {
+		t.Errorf("nice = %d, want %d", r.nice, backgroundNice)
+	}
+}
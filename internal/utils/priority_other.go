@@ -0,0 +1,11 @@
+//go:build !linux && !windows
+
+package utils
+
+import "errors"
+
+// LowerThreadPriority is not supported on this platform; low-priority
+// downloads run at normal priority.
+func LowerThreadPriority() error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,32 @@
+//go:build windows
+
+package utils
+
+import (
+	"runtime"
+	"syscall"
+)
+
+// threadModeBackgroundBegin lowers a thread's scheduling, disk and memory
+// priority together.
+const threadModeBackgroundBegin = 0x00010000
+
+var (
+	kernel32              = syscall.NewLazyDLL("kernel32.dll")
+	procGetCurrentThread  = kernel32.NewProc("GetCurrentThread")
+	procSetThreadPriority = kernel32.NewProc("SetThreadPriority")
+)
+
+// LowerThreadPriority puts the calling goroutine's thread into background
+// mode, which lowers its disk I/O and CPU priority. The goroutine stays
+// locked to the thread so the lower priority never leaks to other goroutines;
+// the thread exits with it, so only call this from a goroutine that ends with
+// the low-priority work.
+func LowerThreadPriority() error {
+	runtime.LockOSThread()
+	thread, _, _ := procGetCurrentThread.Call()
+	if ok, _, err := procSetThreadPriority.Call(thread, threadModeBackgroundBegin); ok == 0 {
+		return err
+	}
+	return nil
+}
@@ -1,10 +1,22 @@
 package utils
 
 import (
+	"sync/atomic"
+
 	"github.com/dustin/go-humanize"
 )
 
-// ConvertBytesToHumanReadable converts a given number of bytes into a human-readable format (e.g., KB, MB, GB).
+// iecUnits switches sizes from SI units (kB, MB, powers of 1000) to IEC
+// units (KiB, MiB, powers of 1024), following the byte_units setting.
+var iecUnits atomic.Bool
+
+// SetIECUnits makes ConvertBytesToHumanReadable, and every size and speed
+// formatted with it, use IEC units instead of SI ones.
+func SetIECUnits(iec bool) {
+	iecUnits.Store(iec)
+}
+
+// ConvertBytesToHumanReadable converts a given number of bytes into a human-readable format (e.g., kB, MB, GB).
 func ConvertBytesToHumanReadable(bytes int64) string {
 	if bytes < 0 {
 		return humanize.Bytes(0)
@@ -12,6 +24,9 @@ func ConvertBytesToHumanReadable(bytes int64) string {
 	if bytes == 0 {
 		return "0 B"
 	}
+	if iecUnits.Load() {
+		return humanize.IBytes(uint64(bytes))
+	}
 	// go-humanize uses SI standards (kB, MB) but format uses standard text
 	return humanize.Bytes(uint64(bytes))
 }
@@ -27,6 +27,29 @@ func TestConvertBytesToHumanReadable(t *testing.T) {
 	}
 }
 
+func TestConvertBytesToHumanReadable_IEC(t *testing.T) {
+	SetIECUnits(true)
+	t.Cleanup(func() { SetIECUnits(false) })
+
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{500, "500 B"},
+		{1024, "1.0 KiB"},
+		{1048576, "1.0 MiB"},
+		{1500000, "1.4 MiB"},
+	}
+	for _, tt := range tests {
+		if got := ConvertBytesToHumanReadable(tt.bytes); got != tt.want {
+			t.Errorf("ConvertBytesToHumanReadable(%d) = %q, want %q", tt.bytes, got, tt.want)
+		}
+	}
+	if got := FormatSpeed(2048); got != "2.0 KiB/s" {
+		t.Errorf("FormatSpeed(2048) = %q, want %q", got, "2.0 KiB/s")
+	}
+}
+
 func BenchmarkConvertBytesToHumanReadable(b *testing.B) {
 	sizes := []int64{0, 512, 1024, 1500000, 1024 * 1024 * 1024}
 	b.ResetTimer()
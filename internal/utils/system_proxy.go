@@ -0,0 +1,271 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"net/url"
+	"os/exec"
+	"path"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// SystemProxySettings is the proxy set in the operating system's network
+// settings, as opposed to the HTTP_PROXY family of environment variables.
+// The zero value means no proxy.
+type SystemProxySettings struct {
+	HTTP   string   // proxy URL for http:// requests
+	HTTPS  string   // proxy URL for https:// requests
+	Bypass []string // hosts, *.domain patterns and CIDRs that go direct
+}
+
+// IsZero reports whether no proxy is configured.
+func (s SystemProxySettings) IsZero() bool {
+	return s.HTTP == "" && s.HTTPS == ""
+}
+
+// String describes the settings for log messages.
+func (s SystemProxySettings) String() string {
+	switch {
+	case s.IsZero():
+		return "none"
+	case s.HTTP == s.HTTPS || s.HTTPS == "":
+		return s.HTTP
+	case s.HTTP == "":
+		return s.HTTPS
+	}
+	return s.HTTP + " (http), " + s.HTTPS + " (https)"
+}
+
+// Equal reports whether s and o route every request the same way.
+func (s SystemProxySettings) Equal(o SystemProxySettings) bool {
+	if s.HTTP != o.HTTP || s.HTTPS != o.HTTPS || len(s.Bypass) != len(o.Bypass) {
+		return false
+	}
+	for i := range s.Bypass {
+		if s.Bypass[i] != o.Bypass[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ProxyFor returns the proxy a request for u goes through, or nil to connect
+// directly. Loopback hosts always go direct, as with ProxyFromEnvironment.
+func (s SystemProxySettings) ProxyFor(u *url.URL) *url.URL {
+	raw := s.HTTP
+	if u.Scheme == "https" {
+		raw = s.HTTPS
+	}
+	if raw == "" || s.bypasses(u.Hostname()) {
+		return nil
+	}
+	proxy, err := url.Parse(raw)
+	if err != nil {
+		return nil
+	}
+	return proxy
+}
+
+func (s SystemProxySettings) bypasses(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip != nil && ip.IsLoopback() {
+		return true
+	}
+	for _, rule := range s.Bypass {
+		rule = strings.ToLower(strings.TrimSpace(rule))
+		switch {
+		case rule == "":
+		case rule == "<local>":
+			// Windows' name for plain host names without a domain
+			if ip == nil && !strings.Contains(host, ".") {
+				return true
+			}
+		case strings.Contains(rule, "/"):
+			if _, cidr, err := net.ParseCIDR(rule); err == nil && ip != nil && cidr.Contains(ip) {
+				return true
+			}
+		case strings.Contains(rule, "*"):
+			if ok, _ := path.Match(rule, host); ok {
+				return true
+			}
+		case host == strings.TrimPrefix(rule, "."), strings.HasSuffix(host, "."+strings.TrimPrefix(rule, ".")):
+			return true
+		}
+	}
+	return false
+}
+
+// DetectSystemProxy reads the proxy configured in the network settings of
+// Windows or macOS, using the platform's own tools. On Windows the user's
+// Internet Options win over the machine-wide WinHTTP proxy. Proxy
+// auto-config (PAC) scripts are not evaluated. Elsewhere, or when the tools
+// fail, it returns the zero value.
+func DetectSystemProxy() SystemProxySettings {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	switch runtime.GOOS {
+	case "darwin":
+		if out, err := exec.CommandContext(ctx, "scutil", "--proxy").Output(); err == nil {
+			return parseScutilProxy(out)
+		}
+	case "windows":
+		if out, err := exec.CommandContext(ctx, "reg", "query",
+			`HKCU\Software\Microsoft\Windows\CurrentVersion\Internet Settings`).Output(); err == nil {
+			if s := parseInternetSettings(out); !s.IsZero() {
+				return s
+			}
+		}
+		if out, err := exec.CommandContext(ctx, "netsh", "winhttp", "show", "proxy").Output(); err == nil {
+			return parseNetshWinHTTPProxy(out)
+		}
+	}
+	return SystemProxySettings{}
+}
+
+// parseScutilProxy reads `scutil --proxy` output, a dictionary of
+// "Key : value" lines with ExceptionsList as a nested array.
+func parseScutilProxy(out []byte) SystemProxySettings {
+	values := map[string]string{}
+	var s SystemProxySettings
+	inExceptions := false
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if inExceptions {
+			if line == "}" {
+				inExceptions = false
+			} else if _, host, ok := strings.Cut(line, " : "); ok {
+				s.Bypass = append(s.Bypass, strings.TrimSpace(host))
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(line, " : ")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if key == "ExceptionsList" {
+			inExceptions = strings.HasSuffix(value, "{")
+			continue
+		}
+		values[key] = value
+	}
+
+	proxy := func(prefix, scheme string) string {
+		if values[prefix+"Enable"] != "1" || values[prefix+"Proxy"] == "" {
+			return ""
+		}
+		host := values[prefix+"Proxy"]
+		if port := values[prefix+"Port"]; port != "" {
+			host = net.JoinHostPort(host, port)
+		}
+		return scheme + "://" + host
+	}
+	socks := proxy("SOCKS", "socks5")
+	s.HTTP = firstNonEmpty(proxy("HTTP", "http"), socks)
+	s.HTTPS = firstNonEmpty(proxy("HTTPS", "http"), socks)
+	if s.IsZero() {
+		s.Bypass = nil
+	}
+	return s
+}
+
+// parseInternetSettings reads `reg query` output for the Internet Settings
+// key, where Internet Options stores ProxyEnable, ProxyServer and
+// ProxyOverride.
+func parseInternetSettings(out []byte) SystemProxySettings {
+	values := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 3 && strings.HasPrefix(fields[1], "REG_") {
+			values[fields[0]] = strings.Join(fields[2:], " ")
+		}
+	}
+	if values["ProxyEnable"] != "0x1" {
+		return SystemProxySettings{}
+	}
+	return parseWindowsProxy(values["ProxyServer"], values["ProxyOverride"])
+}
+
+// parseNetshWinHTTPProxy reads `netsh winhttp show proxy` output, which
+// says "Direct access" when no proxy is set.
+func parseNetshWinHTTPProxy(out []byte) SystemProxySettings {
+	var server, bypass string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "Proxy Server(s)":
+			server = value
+		case "Bypass List":
+			if value != "(none)" {
+				bypass = value
+			}
+		}
+	}
+	return parseWindowsProxy(server, bypass)
+}
+
+// parseWindowsProxy reads a Windows proxy server list, either one
+// host:port for every scheme or entries like "http=host:port;https=..."
+// and a semicolon-separated bypass list.
+func parseWindowsProxy(server, bypass string) SystemProxySettings {
+	var s SystemProxySettings
+	var socks string
+	for _, entry := range strings.FieldsFunc(server, func(r rune) bool { return r == ';' || r == ' ' }) {
+		scheme, host, ok := strings.Cut(entry, "=")
+		if !ok {
+			s.HTTP, s.HTTPS = withScheme(entry, "http"), withScheme(entry, "http")
+			continue
+		}
+		switch strings.ToLower(scheme) {
+		case "http":
+			s.HTTP = withScheme(host, "http")
+		case "https":
+			s.HTTPS = withScheme(host, "http")
+		case "socks":
+			socks = withScheme(host, "socks5")
+		}
+	}
+	s.HTTP = firstNonEmpty(s.HTTP, socks)
+	s.HTTPS = firstNonEmpty(s.HTTPS, socks)
+	if s.IsZero() {
+		return s
+	}
+	for _, rule := range strings.FieldsFunc(bypass, func(r rune) bool { return r == ';' || r == ',' }) {
+		if rule = strings.TrimSpace(rule); rule != "" {
+			s.Bypass = append(s.Bypass, rule)
+		}
+	}
+	return s
+}
+
+func withScheme(host, scheme string) string {
+	if host == "" || strings.Contains(host, "://") {
+		return host
+	}
+	return scheme + "://" + host
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScutilProxy(t *testing.T) {
+	out := `<dictionary> {
+  ExceptionsList : <array> {
+    0 : *.local
+    1 : 169.254.0.0/16
+  }
+  FTPPassive : 1
+  HTTPEnable : 1
+  HTTPPort : 3128
+  HTTPProxy : proxy.corp
+  HTTPSEnable : 1
+  HTTPSPort : 3129
+  HTTPSProxy : proxy.corp
+  SOCKSEnable : 0
+}
+`
+	assert.Equal(t, SystemProxySettings{
+		HTTP:   "http://proxy.corp:3128",
+		HTTPS:  "http://proxy.corp:3129",
+		Bypass: []string{"*.local", "169.254.0.0/16"},
+	}, parseScutilProxy([]byte(out)))
+
+	disabled := "<dictionary> {\n  HTTPEnable : 0\n  HTTPProxy : proxy.corp\n  SOCKSEnable : 1\n  SOCKSProxy : socks.corp\n  SOCKSPort : 1080\n}\n"
+	assert.Equal(t, SystemProxySettings{
+		HTTP:  "socks5://socks.corp:1080",
+		HTTPS: "socks5://socks.corp:1080",
+	}, parseScutilProxy([]byte(disabled)))
+
+	assert.True(t, parseScutilProxy([]byte("<dictionary> {\n  FTPPassive : 1\n}\n")).IsZero())
+}
+
+func TestParseWindowsProxy(t *testing.T) {
+	reg := "\r\nHKEY_CURRENT_USER\\Software\\Microsoft\\Windows\\CurrentVersion\\Internet Settings\r\n" +
+		"    ProxyEnable    REG_DWORD    0x1\r\n" +
+		"    ProxyServer    REG_SZ    proxy.corp:8080\r\n" +
+		"    ProxyOverride    REG_SZ    <local>;*.corp.example\r\n"
+	assert.Equal(t, SystemProxySettings{
+		HTTP:   "http://proxy.corp:8080",
+		HTTPS:  "http://proxy.corp:8080",
+		Bypass: []string{"<local>", "*.corp.example"},
+	}, parseInternetSettings([]byte(reg)))
+
+	off := "    ProxyEnable    REG_DWORD    0x0\r\n    ProxyServer    REG_SZ    proxy.corp:8080\r\n"
+	assert.True(t, parseInternetSettings([]byte(off)).IsZero())
+
+	assert.Equal(t, SystemProxySettings{
+		HTTP:  "http://web:80",
+		HTTPS: "socks5://socks:1080",
+	}, parseWindowsProxy("http=web:80;socks=socks:1080", ""))
+
+	netsh := "\r\nCurrent WinHTTP proxy settings:\r\n\r\n    Proxy Server(s) :  proxy.corp:8080\r\n    Bypass List     :  (none)\r\n"
+	assert.Equal(t, SystemProxySettings{
+		HTTP:  "http://proxy.corp:8080",
+		HTTPS: "http://proxy.corp:8080",
+	}, parseNetshWinHTTPProxy([]byte(netsh)))
+
+	direct := "\r\nCurrent WinHTTP proxy settings:\r\n\r\n    Direct access (no proxy server).\r\n"
+	assert.True(t, parseNetshWinHTTPProxy([]byte(direct)).IsZero())
+}
+
+func TestSystemProxySettings_ProxyFor(t *testing.T) {
+	s := SystemProxySettings{
+		HTTP:   "http://web:3128",
+		HTTPS:  "http://tls:3128",
+		Bypass: []string{"<local>", "*.corp.example", "example.org", "10.0.0.0/8"},
+	}
+	tests := map[string]string{
+		"http://files.example.com/a":     "http://web:3128",
+		"https://files.example.com/a":    "http://tls:3128",
+		"https://intranet/a":             "",
+		"https://git.corp.example/a":     "",
+		"https://cdn.example.org/a":      "",
+		"https://example.org/a":          "",
+		"http://10.1.2.3/a":              "",
+		"http://localhost:8080/a":        "",
+		"http://127.0.0.1:8080/a":        "",
+		"https://notexample.org/a":       "http://tls:3128",
+		"https://corp.example.evil.com/": "http://tls:3128",
+	}
+	for raw, want := range tests {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := ""
+		if proxy := s.ProxyFor(u); proxy != nil {
+			got = proxy.String()
+		}
+		assert.Equal(t, want, got, raw)
+	}
+}
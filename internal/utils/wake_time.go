@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// wakeTimeLayouts are the absolute times ParseWakeTime accepts, read in
+// now's location unless they carry an offset.
+var wakeTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02T15:04"}
+
+// ParseWakeTime reads when a snoozed download should resume: a duration
+// from now such as "2h30m", a clock time such as "23:30" meaning its next
+// occurrence, or a date and time such as "2026-03-01 02:00".
+func ParseWakeTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if d, err := time.ParseDuration(value); err == nil {
+		if d <= 0 {
+			return time.Time{}, fmt.Errorf("duration must be positive: %s", value)
+		}
+		return now.Add(d), nil
+	}
+	if clock, err := time.ParseInLocation("15:04", value, now.Location()); err == nil {
+		at := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+		if !at.After(now) {
+			at = at.AddDate(0, 0, 1)
+		}
+		return at, nil
+	}
+	for _, layout := range wakeTimeLayouts {
+		if at, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			if !at.After(now) {
+				return time.Time{}, fmt.Errorf("time is in the past: %s", value)
+			}
+			return at, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use a duration like 2h, a clock time like 23:30 or a date like 2026-03-01 02:00", value)
+}
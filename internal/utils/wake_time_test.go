@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWakeTime(t *testing.T) {
+	now := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+	tests := map[string]time.Time{
+		"2h30m":                now.Add(150 * time.Minute),
+		"23:30":                time.Date(2026, 3, 1, 23, 30, 0, 0, time.UTC),
+		"06:00":                time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC),
+		"18:00":                time.Date(2026, 3, 2, 18, 0, 0, 0, time.UTC),
+		"2026-03-05 02:00":     time.Date(2026, 3, 5, 2, 0, 0, 0, time.UTC),
+		"2026-03-05T02:00:00Z": time.Date(2026, 3, 5, 2, 0, 0, 0, time.UTC),
+	}
+	for value, want := range tests {
+		got, err := ParseWakeTime(value, now)
+		if err != nil {
+			t.Errorf("ParseWakeTime(%q): %v", value, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("ParseWakeTime(%q) = %v, want %v", value, got, want)
+		}
+	}
+
+	for _, value := range []string{"", "tonight", "-1h", "0s", "2026-02-01 02:00", "25:00"} {
+		if _, err := ParseWakeTime(value, now); err == nil {
+			t.Errorf("ParseWakeTime(%q) succeeded, want an error", value)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package version
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"aead.dev/minisign"
+)
+
+// Release channels for self-update. Stable follows the latest release;
+// nightly also takes pre-releases, whichever was published last.
+const (
+	ChannelStable  = "stable"
+	ChannelNightly = "nightly"
+)
+
+// GitHubReleasesURL lists releases, newest first, including pre-releases.
+const GitHubReleasesURL = "https://api.github.com/repos/SurgeDM/Surge/releases"
+
+// ReleaseAsset is a file attached to a release.
+type ReleaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Asset returns the release's file called name.
+func (r *GitHubRelease) Asset(name string) (ReleaseAsset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return ReleaseAsset{}, false
+}
+
+// ChecksumsAsset returns the release's checksums file.
+func (r *GitHubRelease) ChecksumsAsset() (ReleaseAsset, bool) {
+	return r.assetWithSuffix("checksums.txt")
+}
+
+// ChecksumsSignatureAsset returns the minisign signature of the release's
+// checksums file.
+func (r *GitHubRelease) ChecksumsSignatureAsset() (ReleaseAsset, bool) {
+	return r.assetWithSuffix("checksums.txt.minisig")
+}
+
+func (r *GitHubRelease) assetWithSuffix(suffix string) (ReleaseAsset, bool) {
+	for _, a := range r.Assets {
+		if strings.HasSuffix(a.Name, suffix) {
+			return a, true
+		}
+	}
+	return ReleaseAsset{}, false
+}
+
+// FetchRelease returns the newest release on channel. Unlike CheckForUpdate
+// it reports failures, since the caller asked for the release explicitly.
+func FetchRelease(channel string) (*GitHubRelease, error) {
+	switch channel {
+	case ChannelStable:
+		var release GitHubRelease
+		if err := getJSON(GitHubAPIURL, &release); err != nil {
+			return nil, err
+		}
+		return &release, nil
+	case ChannelNightly:
+		var releases []GitHubRelease
+		if err := getJSON(GitHubReleasesURL+"?per_page=20", &releases); err != nil {
+			return nil, err
+		}
+		for i := range releases {
+			if !releases[i].Draft {
+				return &releases[i], nil
+			}
+		}
+		return nil, fmt.Errorf("no releases found")
+	default:
+		return nil, fmt.Errorf("unknown channel %q: use %s or %s", channel, ChannelStable, ChannelNightly)
+	}
+}
+
+// IsUpdate reports whether release should replace the running version. On
+// the stable channel only a higher version counts; nightly builds are taken
+// whenever the tag differs.
+func IsUpdate(currentVersion string, release *GitHubRelease, channel string) bool {
+	latest, current := normalizeVersion(release.TagName), normalizeVersion(currentVersion)
+	if channel == ChannelNightly || current == "dev" || current == "" {
+		return latest != current
+	}
+	return isNewerVersion(latest, current)
+}
+
+// ArchiveName returns the release archive built for goos/goarch.
+func ArchiveName(tag, goos, goarch string) string {
+	ext := "tar.gz"
+	if goos == "windows" {
+		ext = "zip"
+	}
+	return fmt.Sprintf("surge_%s_%s_%s.%s", normalizeVersion(tag), goos, goarch, ext)
+}
+
+// ReleaseKey is the minisign public key every release's checksums file is
+// signed with. Release builds set it with -ldflags -X; a build without it
+// cannot verify an update, and so does not install one.
+var ReleaseKey string
+
+// VerifyChecksums checks a release's checksums file against its minisign
+// signature, which must be made with ReleaseKey.
+func VerifyChecksums(checksums, signature []byte) error {
+	if strings.TrimSpace(ReleaseKey) == "" {
+		return fmt.Errorf("this build has no release key to verify updates with; update it the way it was installed")
+	}
+	var key minisign.PublicKey
+	if err := key.UnmarshalText([]byte(strings.TrimSpace(ReleaseKey))); err != nil {
+		return fmt.Errorf("invalid release key: %w", err)
+	}
+	if !minisign.Verify(key, checksums, signature) {
+		return fmt.Errorf("checksums file is not signed with the release key")
+	}
+	return nil
+}
+
+// ChecksumFor finds name in a sha256sum-style checksums file.
+func ChecksumFor(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name && len(fields[0]) == 64 {
+			return "sha256:" + strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("no checksum listed for %s", name)
+}
+
+// InstallFromArchive extracts the surge binary from a release archive and
+// puts it in place of exe. The new binary is written next to exe and renamed
+// over it, so exe is never left half-written.
+func InstallFromArchive(archive, exe string) error {
+	binary := "surge"
+	if runtime.GOOS == "windows" {
+		binary = "surge.exe"
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".surge-update-*")
+	if err != nil {
+		return fmt.Errorf("cannot write next to %s: %w", exe, err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if strings.HasSuffix(strings.ToLower(archive), ".zip") {
+		err = extractZip(archive, binary, tmp)
+	} else {
+		err = extractTarGz(archive, binary, tmp)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0o755); err != nil {
+		return err
+	}
+
+	// A running executable cannot be replaced on Windows, but it can be
+	// renamed out of the way.
+	if runtime.GOOS == "windows" {
+		old := exe + ".old"
+		_ = os.Remove(old)
+		if err := os.Rename(exe, old); err != nil {
+			return err
+		}
+		if err := os.Rename(tmpPath, exe); err != nil {
+			_ = os.Rename(old, exe)
+			return err
+		}
+		return nil
+	}
+	return os.Rename(tmpPath, exe)
+}
+
+func extractTarGz(archive, binary string, dst io.Writer) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("invalid archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return fmt.Errorf("archive does not contain %s", binary)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid archive: %w", err)
+		}
+		if hdr.Typeflag == tar.TypeReg && filepath.Base(hdr.Name) == binary {
+			_, err := io.Copy(dst, tr)
+			return err
+		}
+	}
+}
+
+func extractZip(archive, binary string, dst io.Writer) error {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return fmt.Errorf("invalid archive: %w", err)
+	}
+	defer func() { _ = zr.Close() }()
+	for _, file := range zr.File {
+		if file.FileInfo().Mode().IsRegular() && filepath.Base(file.Name) == binary {
+			rc, err := file.Open()
+			if err != nil {
+				return err
+			}
+			defer func() { _ = rc.Close() }()
+			_, err = io.Copy(dst, rc)
+			return err
+		}
+	}
+	return fmt.Errorf("archive does not contain %s", binary)
+}
+
+func getJSON(url string, v any) error {
+	client := &http.Client{Timeout: RequestTimeout}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "Surge-Updater")
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach GitHub: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GitHub API returned %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid GitHub API response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package version
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"aead.dev/minisign"
+)
+
+func TestChecksumFor(t *testing.T) {
+	sums := []byte("0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef  surge_1.2.0_linux_amd64.tar.gz\n" +
+		"FEDCBA9876543210FEDCBA9876543210FEDCBA9876543210FEDCBA9876543210 *surge_1.2.0_windows_amd64.zip\n")
+
+	got, err := ChecksumFor(sums, "surge_1.2.0_linux_amd64.tar.gz")
+	if err != nil || got != "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef" {
+		t.Errorf("ChecksumFor(linux) = %q, %v", got, err)
+	}
+	got, err = ChecksumFor(sums, "surge_1.2.0_windows_amd64.zip")
+	if err != nil || got != "sha256:fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210" {
+		t.Errorf("ChecksumFor(windows) = %q, %v", got, err)
+	}
+	if _, err := ChecksumFor(sums, "surge_1.2.0_darwin_arm64.tar.gz"); err == nil {
+		t.Error("ChecksumFor found an entry that is not listed")
+	}
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	pub, priv, err := minisign.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := pub.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(prev string) { ReleaseKey = prev }(ReleaseKey)
+
+	sums := []byte("0123  surge_1.2.0_linux_amd64.tar.gz\n")
+	sig := minisign.Sign(priv, sums)
+
+	ReleaseKey = ""
+	if err := VerifyChecksums(sums, sig); err == nil {
+		t.Error("VerifyChecksums passed without a release key")
+	}
+	ReleaseKey = string(key)
+	if err := VerifyChecksums(sums, sig); err != nil {
+		t.Errorf("VerifyChecksums(signed) = %v", err)
+	}
+	if err := VerifyChecksums(append(sums, "abcd  surge_1.2.0_linux_arm64.tar.gz\n"...), sig); err == nil {
+		t.Error("VerifyChecksums passed a checksums file that was changed after signing")
+	}
+	_, other, err := minisign.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyChecksums(sums, minisign.Sign(other, sums)); err == nil {
+		t.Error("VerifyChecksums passed a signature from another key")
+	}
+}
+
+func TestArchiveName(t *testing.T) {
+	if got := ArchiveName("v1.2.0", "linux", "amd64"); got != "surge_1.2.0_linux_amd64.tar.gz" {
+		t.Errorf("ArchiveName(linux) = %q", got)
+	}
+	if got := ArchiveName("v1.2.0", "windows", "arm64"); got != "surge_1.2.0_windows_arm64.zip" {
+		t.Errorf("ArchiveName(windows) = %q", got)
+	}
+}
+
+func TestIsUpdate(t *testing.T) {
+	tests := []struct {
+		current, tag, channel string
+		want                  bool
+	}{
+		{"1.2.0", "v1.3.0", ChannelStable, true},
+		{"1.3.0", "v1.3.0", ChannelStable, false},
+		{"1.4.0", "v1.3.0", ChannelStable, false},
+		{"1.3.0", "v1.3.0-nightly.5", ChannelNightly, true},
+		{"1.3.0-nightly.5", "v1.3.0-nightly.5", ChannelNightly, false},
+		{"dev", "v1.3.0", ChannelStable, true},
+	}
+	for _, tt := range tests {
+		if got := IsUpdate(tt.current, &GitHubRelease{TagName: tt.tag}, tt.channel); got != tt.want {
+			t.Errorf("IsUpdate(%q, %q, %s) = %v, want %v", tt.current, tt.tag, tt.channel, got, tt.want)
+		}
+	}
+}
+
+func TestInstallFromArchive(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("release archives for windows are zip files")
+	}
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "surge_1.3.0_linux_amd64.tar.gz")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, body := range map[string]string{"README.md": "readme", "surge": "new binary"} {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(body)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+	_ = f.Close()
+
+	exe := filepath.Join(dir, "bin", "surge")
+	if err := os.MkdirAll(filepath.Dir(exe), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(exe, []byte("old binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := InstallFromArchive(archive, exe); err != nil {
+		t.Fatalf("InstallFromArchive() error = %v", err)
+	}
+	got, err := os.ReadFile(exe)
+	if err != nil || string(got) != "new binary" {
+		t.Fatalf("installed binary = %q, %v", got, err)
+	}
+	if info, _ := os.Stat(exe); info.Mode().Perm()&0o100 == 0 {
+		t.Errorf("installed binary mode = %v, want executable", info.Mode())
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(exe)); len(entries) != 1 {
+		t.Errorf("install left %d files next to the binary, want 1", len(entries))
+	}
+}
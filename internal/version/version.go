@@ -28,8 +28,11 @@ type UpdateInfo struct {
 
 // GitHubRelease represents the relevant fields from the GitHub API response
 type GitHubRelease struct {
-	TagName string `json:"tag_name"`
-	HTMLURL string `json:"html_url"`
+	TagName    string         `json:"tag_name"`
+	HTMLURL    string         `json:"html_url"`
+	Draft      bool           `json:"draft"`
+	Prerelease bool           `json:"prerelease"`
+	Assets     []ReleaseAsset `json:"assets"`
 }
 
 // CheckForUpdate checks if a newer version of Surge is available on GitHub.
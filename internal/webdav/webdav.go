@@ -0,0 +1,228 @@
+// Package webdav reads webdav:// and webdavs:// sources: a file is fetched
+// over plain HTTP(S), with ranges, and a directory is listed with PROPFIND so
+// it can be mirrored.
+package webdav
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+const requestTimeout = 30 * time.Second
+
+// maxEntries stops a listing that keeps growing, such as a server that
+// loops through links back into the tree.
+const maxEntries = 100_000
+
+var (
+	ErrNotFound     = errors.New("WebDAV path not found")
+	ErrUnauthorized = errors.New("WebDAV server refused the credentials")
+)
+
+// IsURL reports whether raw is a webdav:// or webdavs:// URL.
+func IsURL(raw string) bool {
+	scheme, _, ok := strings.Cut(strings.TrimSpace(raw), "://")
+	if !ok {
+		return false
+	}
+	scheme = strings.ToLower(scheme)
+	return scheme == "webdav" || scheme == "webdavs"
+}
+
+// IsDirectory reports whether raw names a WebDAV directory, which, as in
+// WebDAV itself, is written with a trailing slash.
+func IsDirectory(raw string) bool {
+	return IsURL(raw) && strings.HasSuffix(strings.TrimSpace(raw), "/")
+}
+
+// HTTPURL turns a webdav:// URL into the http:// one it is fetched from, and
+// webdavs:// into https://. Credentials in the URL come back as a basic auth
+// header rather than staying in the URL, where they would end up in logs.
+func HTTPURL(raw string) (string, map[string]string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", nil, err
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "webdav":
+		u.Scheme = "http"
+	case "webdavs":
+		u.Scheme = "https"
+	default:
+		return "", nil, fmt.Errorf("%q is not a WebDAV URL", raw)
+	}
+	if u.Host == "" {
+		return "", nil, fmt.Errorf("WebDAV URL %q has no host", raw)
+	}
+	var headers map[string]string
+	if u.User != nil {
+		password, _ := u.User.Password()
+		auth := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + password))
+		headers = map[string]string{"Authorization": "Basic " + auth}
+		u.User = nil
+	}
+	return u.String(), headers, nil
+}
+
+// File is one file found under a listed directory.
+type File struct {
+	Path string // Relative to the listed directory, with forward slashes
+	URL  string // http:// or https:// URL to download it from
+	Size int64
+}
+
+// Client lists WebDAV directories, sending Headers with every request.
+type Client struct {
+	HTTP    *http.Client
+	Headers map[string]string
+}
+
+// Walk lists every file under the directory at dirURL, an http:// or
+// https:// URL, going into subdirectories one level at a time, since many
+// servers refuse a listing of unlimited depth. Entries outside the
+// directory are ignored.
+func (c *Client) Walk(ctx context.Context, dirURL string) ([]File, error) {
+	root, err := url.Parse(dirURL)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(root.Path, "/") {
+		root.Path += "/"
+		root.RawPath = ""
+	}
+
+	var files []File
+	seen := map[string]bool{root.Path: true}
+	queue := []*url.URL{root}
+	for len(queue) > 0 {
+		dir := queue[0]
+		queue = queue[1:]
+		entries, err := c.propfind(ctx, dir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", dir.Path, err)
+		}
+		for _, e := range entries {
+			target := dir.ResolveReference(&url.URL{Path: e.path})
+			if target.Host != root.Host || !strings.HasPrefix(target.Path, root.Path) || seen[target.Path] {
+				continue
+			}
+			seen[target.Path] = true
+			if len(seen) > maxEntries {
+				return nil, fmt.Errorf("directory has more than %d entries", maxEntries)
+			}
+			rel := strings.TrimSuffix(strings.TrimPrefix(target.Path, root.Path), "/")
+			if rel == "" || !safePath(rel) {
+				continue
+			}
+			if e.collection {
+				if !strings.HasSuffix(target.Path, "/") {
+					target.Path += "/"
+				}
+				queue = append(queue, target)
+				continue
+			}
+			files = append(files, File{Path: rel, URL: target.String(), Size: e.size})
+		}
+	}
+	return files, nil
+}
+
+// safePath rejects relative paths that would leave the mirror's directory.
+func safePath(rel string) bool {
+	for _, segment := range strings.Split(rel, "/") {
+		if segment == "" || segment == "." || segment == ".." || strings.ContainsAny(segment, `\`) {
+			return false
+		}
+	}
+	return path.Clean(rel) == rel
+}
+
+type entry struct {
+	path       string // Unescaped
+	collection bool
+	size       int64
+}
+
+const propfindBody = `<?xml version="1.0" encoding="utf-8"?>
+<propfind xmlns="DAV:"><prop><resourcetype/><getcontentlength/></prop></propfind>`
+
+func (c *Client) propfind(ctx context.Context, dir *url.URL) ([]entry, error) {
+	req, err := http.NewRequestWithContext(ctx, "PROPFIND", dir.String(), strings.NewReader(propfindBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Depth", "1")
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	for key, value := range c.Headers {
+		req.Header.Set(key, value)
+	}
+
+	client := c.HTTP
+	if client == nil {
+		client = &http.Client{Timeout: requestTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusMultiStatus:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, ErrUnauthorized
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, fmt.Errorf("WebDAV server returned %s", resp.Status)
+	}
+
+	var ms struct {
+		Responses []struct {
+			Href     string `xml:"DAV: href"`
+			Propstat []struct {
+				Status string `xml:"DAV: status"`
+				Prop   struct {
+					ResourceType struct {
+						Collection *struct{} `xml:"DAV: collection"`
+					} `xml:"DAV: resourcetype"`
+					ContentLength int64 `xml:"DAV: getcontentlength"`
+				} `xml:"DAV: prop"`
+			} `xml:"DAV: propstat"`
+		} `xml:"DAV: response"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("invalid PROPFIND response: %w", err)
+	}
+
+	var entries []entry
+	for _, r := range ms.Responses {
+		href, err := url.Parse(strings.TrimSpace(r.Href))
+		if err != nil {
+			continue
+		}
+		// Only the path is kept, so a full URL can not point at another host
+		e := entry{path: href.Path}
+		for _, ps := range r.Propstat {
+			if ps.Status != "" && !strings.Contains(ps.Status, " 200 ") {
+				continue
+			}
+			if ps.Prop.ResourceType.Collection != nil {
+				e.collection = true
+			}
+			if ps.Prop.ContentLength > 0 {
+				e.size = ps.Prop.ContentLength
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
@@ -0,0 +1,149 @@
+package surge
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrRemoved is returned by Download.Wait for a download that was
+	// deleted before it finished.
+	ErrRemoved = errors.New("download was removed")
+	// ErrEngineClosed is returned by Download.Wait for a download that was
+	// still running when its Engine was closed. It can be resumed by ID
+	// with a later Engine.
+	ErrEngineClosed = errors.New("engine was closed")
+)
+
+// Progress is how far a download has got.
+type Progress struct {
+	Downloaded int64
+	Total      int64   // Zero while unknown
+	Speed      float64 // Bytes per second
+}
+
+// Download is a handle on a download started with Engine.Start.
+type Download struct {
+	ID string
+
+	done     chan struct{}
+	mu       sync.Mutex
+	err      error
+	progress Progress
+}
+
+// Start queues req like Add and returns a handle that follows the download
+// until it finishes. The download lives as long as ctx: once ctx is done it
+// is cancelled, its partial file removed, and Wait returns ctx.Err().
+func (e *Engine) Start(ctx context.Context, req Request) (*Download, error) {
+	// Subscribe first so that no event about the download is missed
+	stream, stop, err := e.Events(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	id, err := e.Add(ctx, req)
+	if err != nil {
+		stop()
+		return nil, err
+	}
+	d := &Download{ID: id, done: make(chan struct{})}
+	go d.follow(ctx, e, stream, stop)
+	return d, nil
+}
+
+// Done is closed when the download has finished, one way or another.
+func (d *Download) Done() <-chan struct{} {
+	return d.done
+}
+
+// Wait blocks until the download has finished and returns why it stopped:
+// nil once the file is in place.
+func (d *Download) Wait() error {
+	<-d.done
+	return d.Err()
+}
+
+// Err returns nil while the download runs and, once Done is closed, the same
+// as Wait.
+func (d *Download) Err() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.err
+}
+
+// Progress returns the latest progress reported for the download.
+func (d *Download) Progress() Progress {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.progress
+}
+
+// follow tracks the download's events until it finishes, cancelling it
+// when ctx is done.
+func (d *Download) follow(ctx context.Context, e *Engine, stream <-chan Event, stop func()) {
+	defer stop()
+	var cancelled error
+	ctxDone := ctx.Done()
+	for {
+		select {
+		case <-ctxDone:
+			// Wait for the removal to be reported, so the partial file is
+			// gone by the time Wait returns
+			cancelled, ctxDone = ctx.Err(), nil
+			if err := e.Delete(d.ID); err != nil {
+				d.finish(cancelled)
+				return
+			}
+		case ev, ok := <-stream:
+			if !ok {
+				d.finish(ErrEngineClosed)
+				return
+			}
+			switch ev := ev.(type) {
+			case BatchProgressEvent:
+				for _, p := range ev {
+					if p.DownloadID == d.ID {
+						d.setProgress(Progress{Downloaded: p.Downloaded, Total: p.Total, Speed: p.Speed})
+					}
+				}
+			case ProgressEvent:
+				if ev.DownloadID == d.ID {
+					d.setProgress(Progress{Downloaded: ev.Downloaded, Total: ev.Total, Speed: ev.Speed})
+				}
+			case CompleteEvent:
+				if ev.DownloadID == d.ID {
+					d.setProgress(Progress{Downloaded: ev.Total, Total: ev.Total})
+					d.finish(nil)
+					return
+				}
+			case ErrorEvent:
+				if ev.DownloadID == d.ID {
+					d.finish(ev.Err)
+					return
+				}
+			case RemovedEvent:
+				if ev.DownloadID == d.ID {
+					if cancelled == nil {
+						cancelled = ErrRemoved
+					}
+					d.finish(cancelled)
+					return
+				}
+			}
+		}
+	}
+}
+
+func (d *Download) setProgress(p Progress) {
+	d.mu.Lock()
+	d.progress = p
+	d.mu.Unlock()
+}
+
+func (d *Download) finish(err error) {
+	d.mu.Lock()
+	d.err = err
+	d.mu.Unlock()
+	close(d.done)
+}
@@ -0,0 +1,87 @@
+package surge
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/testutil"
+)
+
+func newTestEngine(t *testing.T) *Engine {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("XDG_STATE_HOME", home)
+	t.Setenv("XDG_RUNTIME_DIR", home)
+
+	engine, err := New(Options{StateDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = engine.Close() })
+	return engine
+}
+
+func TestDownload_WaitReturnsOnceComplete(t *testing.T) {
+	server := testutil.NewMockServerT(t, testutil.WithFileSize(128*1024))
+	defer server.Close()
+	engine := newTestEngine(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	d, err := engine.Start(ctx, Request{URL: server.URL(), Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Wait(); err != nil {
+		t.Fatalf("Wait() = %v, want nil", err)
+	}
+	if p := d.Progress(); p.Downloaded != 128*1024 || p.Total != 128*1024 {
+		t.Fatalf("Progress() = %+v, want the whole file", p)
+	}
+}
+
+func TestDownload_CancelledWithItsContext(t *testing.T) {
+	server := testutil.NewMockServerT(t, testutil.WithFileSize(8*1024*1024), testutil.WithByteLatency(time.Microsecond))
+	defer server.Close()
+	engine := newTestEngine(t)
+
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d, err := engine.Start(ctx, Request{URL: server.URL(), Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.After(10 * time.Second)
+	for d.Progress().Downloaded == 0 {
+		select {
+		case <-deadline:
+			t.Fatal("download made no progress")
+		case <-d.Done():
+			t.Fatalf("download ended early: %v", d.Err())
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+	cancel()
+
+	select {
+	case <-d.Done():
+	case <-time.After(10 * time.Second):
+		t.Fatal("download did not stop after its context was cancelled")
+	}
+	if err := d.Err(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Err() = %v, want context.Canceled", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("expected the partial file to be removed, found %d entries", len(entries))
+	}
+	if _, err := engine.Status(d.ID); err == nil {
+		t.Fatal("expected the cancelled download to be gone")
+	}
+}
@@ -0,0 +1,43 @@
+package surge
+
+import "github.com/SurgeDM/Surge/internal/engine/events"
+
+// Event is one of the event types below, as sent by Engine.Events. Types not
+// listed here may be added later and should be ignored.
+type Event = any
+
+type (
+	// QueuedEvent is sent when a download has been probed and queued.
+	QueuedEvent = events.DownloadQueuedMsg
+	// StartedEvent is sent when a download starts transferring.
+	StartedEvent = events.DownloadStartedMsg
+	// ProgressEvent is the progress of one running download.
+	ProgressEvent = events.ProgressMsg
+	// BatchProgressEvent carries a ProgressEvent for every running download.
+	BatchProgressEvent = events.BatchProgressMsg
+	// VerifyingEvent reports checking a finished file against its checksum
+	// or signature.
+	VerifyingEvent = events.DownloadVerifyingMsg
+	// CompleteEvent is sent when a download has finished and its file is in
+	// place.
+	CompleteEvent = events.DownloadCompleteMsg
+	// ErrorEvent is sent when a download fails.
+	ErrorEvent = events.DownloadErrorMsg
+	// FailedEvent follows an ErrorEvent and says whether the download will
+	// be retried.
+	FailedEvent = events.DownloadFailedMsg
+	// PausedEvent is sent when a download is paused.
+	PausedEvent = events.DownloadPausedMsg
+	// ResumedEvent is sent when a paused download is resumed.
+	ResumedEvent = events.DownloadResumedMsg
+	// RemovedEvent is sent when a download is deleted.
+	RemovedEvent = events.DownloadRemovedMsg
+	// LogEvent carries a message about the engine rather than one download.
+	LogEvent = events.SystemLogMsg
+)
+
+// DownloadID returns the ID of the download ev is about, or "" for events
+// that are not about one download, such as a BatchProgressEvent.
+func DownloadID(ev Event) string {
+	return events.DownloadIDForMessage(ev)
+}
@@ -0,0 +1,364 @@
+// Package surge embeds the Surge download engine in other Go programs.
+//
+// An Engine runs downloads in the calling process, with the same probing,
+// multi-connection transfers, resume and persistence as the surge command,
+// and reports on them through Events:
+//
+//	engine, err := surge.New(surge.Options{})
+//	if err != nil {
+//		return err
+//	}
+//	defer engine.Close()
+//
+//	events, stop, err := engine.Events(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	defer stop()
+//
+//	id, err := engine.Add(ctx, surge.Request{URL: url, Dir: dir})
+//	...
+//	for ev := range events {
+//		switch ev := ev.(type) {
+//		case surge.ProgressEvent:
+//			fmt.Println(ev.DownloadID, ev.Downloaded, ev.Total)
+//		case surge.CompleteEvent:
+//			...
+//		}
+//	}
+//
+// To tie a download to a context instead, Start returns a handle that is
+// waited on, and cancels the download when the context is done:
+//
+//	d, err := engine.Start(ctx, surge.Request{URL: url, Dir: dir})
+//	if err != nil {
+//		return err
+//	}
+//	return d.Wait()
+//
+// Settings are read from Surge's settings file, like the surge command reads
+// them, and downloads are kept in a state database, so paused ones can be
+// resumed by a later Engine.
+package surge
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/config"
+	"github.com/SurgeDM/Surge/internal/core"
+	"github.com/SurgeDM/Surge/internal/download"
+	"github.com/SurgeDM/Surge/internal/engine/events"
+	"github.com/SurgeDM/Surge/internal/engine/state"
+	"github.com/SurgeDM/Surge/internal/engine/types"
+	"github.com/SurgeDM/Surge/internal/processing"
+	"github.com/SurgeDM/Surge/internal/utils"
+)
+
+// Service is the interface to a download engine, local or a remote daemon's.
+// Engine.Service returns the one an Engine runs.
+type Service = core.DownloadService
+
+// Status is the state of one download as Service.List and Engine.Status
+// report it.
+type Status = types.DownloadStatus
+
+// Entry is a download as recorded in the state database.
+type Entry = types.DownloadEntry
+
+// DownloadConfig is everything the engine needs to run one download.
+type DownloadConfig = types.DownloadConfig
+
+// RequestOptions are the optional parts of a Request: alias, tags,
+// checksum, connection cap, HTTP method and body.
+type RequestOptions = types.RequestOptions
+
+// TLSOptions overrides the TLS settings for one download.
+type TLSOptions = types.TLSOptions
+
+// Options configure an Engine.
+type Options struct {
+	// StateDir holds the state database. Empty uses Surge's own state
+	// directory, sharing the download list with the surge command; only
+	// one of them may run at a time then.
+	StateDir string
+	// MaxConcurrentDownloads caps how many downloads transfer at once.
+	// Zero uses the max_concurrent_downloads setting.
+	MaxConcurrentDownloads int
+}
+
+// Request is a download to add to an Engine.
+type Request struct {
+	URL string
+	// Dir is the directory to save into. Empty uses the default download
+	// directory setting, with category routing applied.
+	Dir string
+	// Filename overrides the name the server suggests.
+	Filename string
+	// Mirrors are other URLs serving the same file.
+	Mirrors []string
+	Headers map[string]string
+	TLS     TLSOptions
+	Options RequestOptions
+}
+
+// Engine runs downloads in the calling process. Only one Engine should be
+// open at a time, since they share the process-wide state database.
+type Engine struct {
+	service    *core.LocalDownloadService
+	lifecycle  *processing.LifecycleManager
+	stopWorker func()
+	closeOnce  sync.Once
+	closeErr   error
+
+	subsMu sync.Mutex
+	subs   []*subscriber
+}
+
+// subscriber is one caller of Events.
+type subscriber struct {
+	in   chan Event
+	done chan struct{}
+	once sync.Once
+}
+
+// handled is sent to the lifecycle worker after each event. The worker
+// ignores it, so once it has been taken the worker is done with the event
+// before it, and a completed download has been moved into place.
+type handled struct{}
+
+// New starts an Engine.
+func New(opts Options) (*Engine, error) {
+	stateDir := opts.StateDir
+	if stateDir == "" {
+		if err := config.EnsureDirs(); err != nil {
+			return nil, fmt.Errorf("failed to create surge directories: %w", err)
+		}
+		stateDir = config.GetStateDir()
+	} else if err := os.MkdirAll(stateDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	state.Configure(filepath.Join(stateDir, "surge.db"))
+
+	settings, err := config.LoadSettings()
+	if err != nil {
+		settings = config.DefaultSettings()
+	}
+	config.ApplyByteUnits(settings)
+	maxDownloads := opts.MaxConcurrentDownloads
+	if maxDownloads <= 0 {
+		maxDownloads = config.Resolve[int](settings.Network.MaxConcurrentDownloads)
+	}
+
+	progressCh := make(chan any, 100)
+	pool := download.NewWorkerPool(progressCh, maxDownloads)
+	service := core.NewLocalDownloadServiceWithInput(pool, progressCh)
+	lifecycle := processing.NewLifecycleManager(service.AddProbed, service.AddProbedWithID)
+
+	lifecycle.SetEngineHooks(processing.EngineHooks{
+		Pause:               pool.Pause,
+		ExtractPausedConfig: pool.ExtractPausedConfig,
+		GetStatus:           pool.GetStatus,
+		AddConfig:           pool.Add,
+		Cancel:              pool.Cancel,
+		UpdateURL:           pool.UpdateURL,
+		PublishEvent:        service.Publish,
+		TakeTraffic:         pool.TakeTraffic,
+	})
+	service.SetLifecycleHooks(core.LifecycleHooks{
+		Pause:       lifecycle.Pause,
+		Resume:      lifecycle.Resume,
+		ResumeBatch: lifecycle.ResumeBatch,
+		Cancel:      lifecycle.Cancel,
+		UpdateURL:   lifecycle.UpdateURL,
+		Waiting:     lifecycle.Waiting,
+	})
+
+	// The lifecycle manager persists what the engine reports, and callers
+	// of Events only hear of an event once it has done so.
+	stream, cleanup, err := service.StreamEvents(context.Background())
+	if err != nil {
+		_ = service.Shutdown()
+		return nil, fmt.Errorf("error starting event stream: %w", err)
+	}
+	e := &Engine{service: service, lifecycle: lifecycle}
+	workerStream := make(chan interface{})
+	workerDone := make(chan struct{})
+	go func() {
+		defer close(workerDone)
+		lifecycle.StartEventWorker(workerStream)
+	}()
+	go e.forward(stream, workerStream)
+	e.stopWorker = func() {
+		cleanup()
+		<-workerDone
+	}
+	return e, nil
+}
+
+// forward hands each event of stream to the lifecycle worker and then to
+// the callers of Events. Like the service, it drops progress for a caller
+// that is not keeping up and gives up on other events after a second.
+func (e *Engine) forward(stream <-chan interface{}, worker chan<- interface{}) {
+	defer close(worker)
+	for msg := range stream {
+		worker <- msg
+		worker <- handled{}
+
+		e.subsMu.Lock()
+		subs := slices.Clone(e.subs)
+		e.subsMu.Unlock()
+		isProgress := false
+		switch msg.(type) {
+		case events.ProgressMsg, events.BatchProgressMsg:
+			isProgress = true
+		}
+		for _, sub := range subs {
+			if isProgress {
+				select {
+				case sub.in <- msg:
+				case <-sub.done:
+				default:
+				}
+				continue
+			}
+			select {
+			case sub.in <- msg:
+			case <-sub.done:
+			case <-time.After(time.Second):
+			}
+		}
+	}
+
+	e.subsMu.Lock()
+	for _, sub := range e.subs {
+		sub.stop()
+	}
+	e.subs = nil
+	e.subsMu.Unlock()
+}
+
+func (s *subscriber) stop() {
+	s.once.Do(func() { close(s.done) })
+}
+
+// Add probes req.URL and queues the download, returning its ID. ctx bounds
+// the probe, not the download; Start ties the download to a context.
+func (e *Engine) Add(ctx context.Context, req Request) (string, error) {
+	dir := req.Dir
+	if dir == "" {
+		dir = config.Resolve[string](e.lifecycle.GetSettings().General.DefaultDownloadDir)
+	}
+	if dir == "" {
+		dir = "."
+	}
+	id, _, err := e.lifecycle.Enqueue(ctx, &processing.DownloadRequest{
+		URL:                req.URL,
+		Filename:           req.Filename,
+		Path:               utils.EnsureAbsPath(dir),
+		Mirrors:            req.Mirrors,
+		Headers:            req.Headers,
+		IsExplicitCategory: req.Dir != "",
+		SkipApproval:       true,
+		TLS:                req.TLS,
+		Request:            req.Options,
+	})
+	return id, err
+}
+
+// Events streams what happens to downloads, as the event types of this
+// package, until ctx is done, stop is called or the Engine is closed.
+// Progress is reported as one BatchProgressEvent per interval for all
+// running downloads, and dropped when the caller falls behind.
+func (e *Engine) Events(ctx context.Context) (<-chan Event, func(), error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	sub := &subscriber{in: make(chan Event), done: make(chan struct{})}
+	e.subsMu.Lock()
+	e.subs = append(e.subs, sub)
+	e.subsMu.Unlock()
+
+	out := make(chan Event, 100)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-sub.done:
+				return
+			case <-ctx.Done():
+				return
+			case msg := <-sub.in:
+				select {
+				case out <- msg:
+				case <-sub.done:
+					return
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	stop := func() {
+		sub.stop()
+		e.subsMu.Lock()
+		e.subs = slices.DeleteFunc(e.subs, func(s *subscriber) bool { return s == sub })
+		e.subsMu.Unlock()
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			stop()
+		case <-sub.done:
+		}
+	}()
+	return out, stop, nil
+}
+
+// Status returns the state of download id.
+func (e *Engine) Status(id string) (*Status, error) {
+	return e.service.GetStatus(id)
+}
+
+// List returns the state of every download.
+func (e *Engine) List() ([]Status, error) {
+	return e.service.List()
+}
+
+// Pause pauses download id, keeping what it downloaded so far.
+func (e *Engine) Pause(id string) error {
+	return e.service.Pause(id)
+}
+
+// Resume continues a paused download.
+func (e *Engine) Resume(id string) error {
+	return e.service.Resume(id)
+}
+
+// Delete cancels download id and removes it from the list, leaving its
+// file on disk.
+func (e *Engine) Delete(id string) error {
+	return e.service.Delete(id)
+}
+
+// Service returns the Service the Engine runs, for the operations it does
+// not wrap itself.
+func (e *Engine) Service() Service {
+	return e.service
+}
+
+// Close pauses the running downloads, so a later Engine can resume them,
+// and stops the Engine.
+func (e *Engine) Close() error {
+	e.closeOnce.Do(func() {
+		e.closeErr = e.service.Shutdown()
+		e.stopWorker()
+	})
+	return e.closeErr
+}
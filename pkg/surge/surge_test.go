@@ -0,0 +1,61 @@
+package surge
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SurgeDM/Surge/internal/testutil"
+)
+
+func TestEngine_DownloadsAndReportsEvents(t *testing.T) {
+	server := testutil.NewMockServerT(t, testutil.WithFileSize(256*1024), testutil.WithFilename("lib.bin"))
+	defer server.Close()
+	engine := newTestEngine(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	stream, stop, err := engine.Events(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	dir := t.TempDir()
+	id, err := engine.Add(ctx, Request{URL: server.URL(), Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var queued bool
+	for {
+		select {
+		case ev := <-stream:
+			if DownloadID(ev) != id {
+				continue
+			}
+			switch ev := ev.(type) {
+			case QueuedEvent:
+				queued = true
+			case ErrorEvent:
+				t.Fatalf("download failed: %v", ev.Err)
+			case CompleteEvent:
+				if !queued {
+					t.Fatal("expected a QueuedEvent before the CompleteEvent")
+				}
+				info, err := os.Stat(filepath.Join(dir, ev.Filename))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if info.Size() != 256*1024 {
+					t.Fatalf("file is %d bytes, want %d", info.Size(), 256*1024)
+				}
+				return
+			}
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for the download to complete")
+		}
+	}
+}